@@ -8,23 +8,32 @@ import (
 	"time"
 
 	"github.com/kyma-project/kyma/components/application-operator/pkg/client/clientset/versioned"
+	"github.com/kyma-project/kyma/components/application-operator/pkg/client/informers/externalversions"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	csrfClient "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/client"
 	csrfStrategy "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/strategy"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/externalapi"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/invalidation"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/serviceapi"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/specification"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 )
 
+// informerResyncPeriod disables resyncs, as cached proxies are invalidated only when the watched resources change
+const informerResyncPeriod = 0
+
 func main() {
 	formatter := &log.TextFormatter{
 		FullTimestamp: true,
@@ -36,6 +45,10 @@ func main() {
 	options := parseArgs()
 	log.Infof("Options: %s", options)
 
+	if err := getResiliencePolicy(options.resilienceOptions).Validate(); err != nil {
+		log.Fatalf("Invalid resilience policy: %s", err.Error())
+	}
+
 	k8sConfig, err := restclient.InClusterConfig()
 	if err != nil {
 		log.Fatalf("Error reading in cluster config: %s", err.Error())
@@ -50,15 +63,24 @@ func main() {
 		k8sConfig,
 		coreClientset,
 		options.namespace,
+		options.proxyTimeout,
 	)
 	if err != nil {
 		log.Errorf("Unable to create ServiceDefinitionService: '%s'", err.Error())
 		os.Exit(1)
 	}
 
-	internalHandler := newInternalHandler(serviceDefinitionService, options)
-	internalHandlerForCompass := newInternalHandlerForCompass(serviceDefinitionService, options)
-	externalHandler := externalapi.NewHandler()
+	proxyCache := proxy.NewCache(options.proxyCacheTTL)
+	stopCh := make(chan struct{})
+	if err := startCacheInvalidation(k8sConfig, coreClientset, options.namespace, proxyCache, stopCh); err != nil {
+		log.Errorf("Unable to start proxy cache invalidation: '%s'", err.Error())
+		os.Exit(1)
+	}
+
+	resilienceRegistry := resilience.NewRegistry()
+	internalHandler := newInternalHandler(serviceDefinitionService, options, resilienceRegistry, proxyCache)
+	internalHandlerForCompass := newInternalHandlerForCompass(serviceDefinitionService, options, resilienceRegistry, proxyCache)
+	externalHandler := externalapi.NewHandler(resilienceRegistry)
 
 	if options.requestLogging {
 		internalHandler = httptools.RequestLogger("Internal handler: ", internalHandler)
@@ -108,27 +130,51 @@ func main() {
 	wg.Wait()
 }
 
-func newInternalHandler(serviceDefinitionService metadata.ServiceDefinitionService, options *options) http.Handler {
+func newInternalHandler(serviceDefinitionService metadata.ServiceDefinitionService, options *options, resilienceRegistry *resilience.Registry, proxyCache proxy.Cache) http.Handler {
 	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout)
 	csrfCl := newCSRFClient(options.proxyTimeout)
 	csrfTokenStrategyFactory := csrfStrategy.NewTokenStrategyFactory(csrfCl)
 
-	return proxy.New(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, getProxyConfig(options))
+	return proxy.New(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, getProxyConfig(options, resilienceRegistry, proxyCache))
 }
 
-func newInternalHandlerForCompass(serviceDefinitionService metadata.ServiceDefinitionService, options *options) http.Handler {
+func newInternalHandlerForCompass(serviceDefinitionService metadata.ServiceDefinitionService, options *options, resilienceRegistry *resilience.Registry, proxyCache proxy.Cache) http.Handler {
 	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout)
 	csrfCl := newCSRFClient(options.proxyTimeout)
 	csrfTokenStrategyFactory := csrfStrategy.NewTokenStrategyFactory(csrfCl)
 
-	return proxy.NewForCompass(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, getProxyConfig(options))
+	return proxy.NewForCompass(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, getProxyConfig(options, resilienceRegistry, proxyCache))
 }
 
-func getProxyConfig(options *options) proxy.Config {
+func getProxyConfig(options *options, resilienceRegistry *resilience.Registry, proxyCache proxy.Cache) proxy.Config {
 	return proxy.Config{
-		SkipVerify:    options.skipVerify,
-		ProxyTimeout:  options.proxyTimeout,
-		ProxyCacheTTL: options.proxyCacheTTL,
+		SkipVerify:         options.skipVerify,
+		ProxyTimeout:       options.proxyTimeout,
+		ProxyCacheTTL:      options.proxyCacheTTL,
+		ProxyCache:         proxyCache,
+		ResiliencePolicy:   getResiliencePolicy(options.resilienceOptions),
+		ResilienceRegistry: resilienceRegistry,
+		OpenAPIMaxBodySize: options.openAPIMaxBodySize,
+	}
+}
+
+func getResiliencePolicy(options resilienceOptions) resilience.Policy {
+	return resilience.Policy{
+		Retry: resilience.RetryPolicy{
+			MaxRetries:     options.retryMaxRetries,
+			InitialBackoff: resilience.Duration(options.retryInitialBackoff),
+			MaxBackoff:     resilience.Duration(options.retryMaxBackoff),
+			AttemptTimeout: resilience.Duration(options.retryAttemptTimeout),
+			MaxBodySize:    options.retryMaxBodySize,
+		},
+		CircuitBreaker: resilience.CircuitBreakerPolicy{
+			FailureThreshold: options.circuitBreakerFailureThreshold,
+			OpenDuration:     resilience.Duration(options.circuitBreakerOpenDuration),
+			HalfOpenRequests: options.circuitBreakerHalfOpenRequests,
+		},
+		Bulkhead: resilience.BulkheadPolicy{
+			MaxConcurrentRequests: options.bulkheadMaxConcurrentRequests,
+		},
 	}
 }
 
@@ -138,7 +184,7 @@ func newAuthenticationStrategyFactory(oauthClientTimeout int) authorization.Stra
 	})
 }
 
-func newServiceDefinitionService(k8sConfig *restclient.Config, coreClientset kubernetes.Interface, namespace string) (metadata.ServiceDefinitionService, error) {
+func newServiceDefinitionService(k8sConfig *restclient.Config, coreClientset kubernetes.Interface, namespace string, proxyTimeout int) (metadata.ServiceDefinitionService, error) {
 	applicationServiceRepository, apperror := newApplicationRepository(k8sConfig)
 	if apperror != nil {
 		return nil, apperror
@@ -146,7 +192,9 @@ func newServiceDefinitionService(k8sConfig *restclient.Config, coreClientset kub
 
 	secretsRepository := newSecretsRepository(coreClientset, namespace)
 
-	serviceAPIService := serviceapi.NewService(secretsRepository)
+	specificationRepository := specification.NewRepository(proxyTimeout)
+
+	serviceAPIService := serviceapi.NewService(secretsRepository, specificationRepository)
 
 	return metadata.NewServiceDefinitionService(serviceAPIService, applicationServiceRepository), nil
 }
@@ -168,6 +216,30 @@ func newSecretsRepository(coreClientset kubernetes.Interface, namespace string)
 	return secrets.NewRepository(sei)
 }
 
+// startCacheInvalidation watches the Applications and the Secrets in the namespace of the Application Gateway
+// to invalidate the cached proxies of the APIs when they change
+func startCacheInvalidation(k8sConfig *restclient.Config, coreClientset kubernetes.Interface, namespace string, proxyCache proxy.Cache, stopCh <-chan struct{}) error {
+	applicationClientset, err := versioned.NewForConfig(k8sConfig)
+	if err != nil {
+		return apperrors.Internal("failed to create k8s application client, %s", err)
+	}
+
+	applicationInformerFactory := externalversions.NewSharedInformerFactory(applicationClientset, informerResyncPeriod)
+	secretInformerFactory := informers.NewSharedInformerFactoryWithOptions(coreClientset, informerResyncPeriod, informers.WithNamespace(namespace))
+
+	applicationInformer := applicationInformerFactory.Applicationconnector().V1alpha1().Applications()
+	secretInformer := secretInformerFactory.Core().V1().Secrets()
+	invalidation.RegisterHandlers(proxyCache, applicationInformer, secretInformer)
+
+	applicationInformerFactory.Start(stopCh)
+	secretInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, applicationInformer.Informer().HasSynced, secretInformer.Informer().HasSynced) {
+		return apperrors.Internal("failed to sync informer caches")
+	}
+
+	return nil
+}
+
 func newCSRFClient(timeout int) csrf.Client {
 	cache := csrfClient.NewTokenCache()
 	return csrfClient.New(timeout, cache)
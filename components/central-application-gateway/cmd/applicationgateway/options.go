@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"time"
 )
 
 type options struct {
@@ -16,6 +17,20 @@ type options struct {
 	proxyTimeout              int
 	requestLogging            bool
 	proxyCacheTTL             int
+	openAPIMaxBodySize        int64
+	resilienceOptions
+}
+
+type resilienceOptions struct {
+	retryMaxRetries                int
+	retryInitialBackoff            time.Duration
+	retryMaxBackoff                time.Duration
+	retryAttemptTimeout            time.Duration
+	retryMaxBodySize               int64
+	circuitBreakerFailureThreshold int
+	circuitBreakerOpenDuration     time.Duration
+	circuitBreakerHalfOpenRequests int
+	bulkheadMaxConcurrentRequests  int
 }
 
 func parseArgs() *options {
@@ -29,6 +44,16 @@ func parseArgs() *options {
 	proxyTimeout := flag.Int("proxyTimeout", 10, "Timeout for proxy call.")
 	requestLogging := flag.Bool("requestLogging", false, "Flag for logging incoming requests.")
 	proxyCacheTTL := flag.Int("proxyCacheTTL", 120, "TTL, in seconds, for proxy cache of Remote API information")
+	openAPIMaxBodySize := flag.Int64("openAPIMaxBodySize", 1<<20, "Maximum size in bytes of a JSON request body validated against the OpenAPI specification of an API, larger bodies are sent without validation.")
+	retryMaxRetries := flag.Int("retryMaxRetries", 0, "Default number of retries of failed idempotent proxy calls, 0 disables retries unless the resilience policy of an API enables them.")
+	retryInitialBackoff := flag.Duration("retryInitialBackoff", 100*time.Millisecond, "Default backoff before the first retry of a proxy call, doubled with every retry.")
+	retryMaxBackoff := flag.Duration("retryMaxBackoff", 2*time.Second, "Default maximum backoff between retries of a proxy call.")
+	retryAttemptTimeout := flag.Duration("retryAttemptTimeout", 0, "Default timeout of a single attempt of a retried proxy call, 0 means only the proxy timeout applies.")
+	retryMaxBodySize := flag.Int64("retryMaxBodySize", 1<<20, "Default maximum size in bytes of a request body buffered for retries, proxy calls with larger bodies are not retried.")
+	circuitBreakerFailureThreshold := flag.Int("circuitBreakerFailureThreshold", 10, "Default number of consecutive failed proxy calls opening the circuit breaker of an API, 0 disables circuit breakers.")
+	circuitBreakerOpenDuration := flag.Duration("circuitBreakerOpenDuration", 30*time.Second, "Default duration for which an open circuit breaker rejects proxy calls.")
+	circuitBreakerHalfOpenRequests := flag.Int("circuitBreakerHalfOpenRequests", 1, "Default number of successful probe calls closing a half-open circuit breaker.")
+	bulkheadMaxConcurrentRequests := flag.Int("bulkheadMaxConcurrentRequests", 0, "Default maximum number of concurrent proxy calls to an API, 0 means no limit.")
 
 	flag.Parse()
 
@@ -43,12 +68,31 @@ func parseArgs() *options {
 		proxyTimeout:              *proxyTimeout,
 		requestLogging:            *requestLogging,
 		proxyCacheTTL:             *proxyCacheTTL,
+		openAPIMaxBodySize:        *openAPIMaxBodySize,
+		resilienceOptions: resilienceOptions{
+			retryMaxRetries:                *retryMaxRetries,
+			retryInitialBackoff:            *retryInitialBackoff,
+			retryMaxBackoff:                *retryMaxBackoff,
+			retryAttemptTimeout:            *retryAttemptTimeout,
+			retryMaxBodySize:               *retryMaxBodySize,
+			circuitBreakerFailureThreshold: *circuitBreakerFailureThreshold,
+			circuitBreakerOpenDuration:     *circuitBreakerOpenDuration,
+			circuitBreakerHalfOpenRequests: *circuitBreakerHalfOpenRequests,
+			bulkheadMaxConcurrentRequests:  *bulkheadMaxConcurrentRequests,
+		},
 	}
 }
 
 func (o *options) String() string {
 	return fmt.Sprintf("--disableLegacyConnectivity=%t --externalAPIPort=%d --proxyPort=%d --proxyPortCompass=%d --namespace=%s --requestTimeout=%d --skipVerify=%v --proxyTimeout=%d"+
-		" --requestLogging=%t --proxyCacheTTL=%d",
+		" --requestLogging=%t --proxyCacheTTL=%d --openAPIMaxBodySize=%d %s",
 		o.disableLegacyConnectivity, o.externalAPIPort, o.proxyPort, o.proxyPortCompass, o.namespace, o.requestTimeout, o.skipVerify, o.proxyTimeout,
-		o.requestLogging, o.proxyCacheTTL)
+		o.requestLogging, o.proxyCacheTTL, o.openAPIMaxBodySize, o.resilienceOptions)
+}
+
+func (o resilienceOptions) String() string {
+	return fmt.Sprintf("--retryMaxRetries=%d --retryInitialBackoff=%s --retryMaxBackoff=%s --retryAttemptTimeout=%s --retryMaxBodySize=%d"+
+		" --circuitBreakerFailureThreshold=%d --circuitBreakerOpenDuration=%s --circuitBreakerHalfOpenRequests=%d --bulkheadMaxConcurrentRequests=%d",
+		o.retryMaxRetries, o.retryInitialBackoff, o.retryMaxBackoff, o.retryAttemptTimeout, o.retryMaxBodySize,
+		o.circuitBreakerFailureThreshold, o.circuitBreakerOpenDuration, o.circuitBreakerHalfOpenRequests, o.bulkheadMaxConcurrentRequests)
 }
@@ -3,14 +3,20 @@ module github.com/kyma-project/kyma/components/central-application-gateway
 go 1.14
 
 require (
+	github.com/beevik/etree v1.1.0
+	github.com/getkin/kin-openapi v0.61.0
 	github.com/gorilla/mux v1.8.0
 	github.com/kyma-project/kyma/components/application-operator v0.0.0-20210624133846-3e1e71e9f682
 	github.com/patrickmn/go-cache v2.1.0+incompatible
+	github.com/prometheus/client_golang v1.11.0
+	github.com/russellhaering/goxmldsig v1.1.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
+	gopkg.in/square/go-jose.v2 v2.5.1
 	k8s.io/api v0.21.2
 	k8s.io/apimachinery v0.21.2
 	k8s.io/client-go v0.21.2
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
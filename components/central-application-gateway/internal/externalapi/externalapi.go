@@ -4,12 +4,14 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func NewHandler() http.Handler {
+func NewHandler(circuitBreakers CircuitBreakers) http.Handler {
 	router := mux.NewRouter()
 
-	router.Path("/v1/health").Handler(NewHealthCheckHandler()).Methods(http.MethodGet)
+	router.Path("/v1/health").Handler(NewHealthCheckHandler(circuitBreakers)).Methods(http.MethodGet)
+	router.Path("/metrics").Handler(promhttp.Handler()).Methods(http.MethodGet)
 
 	router.NotFoundHandler = NewErrorHandler(404, "Requested resource could not be found.")
 	router.MethodNotAllowedHandler = NewErrorHandler(405, "Method not allowed.")
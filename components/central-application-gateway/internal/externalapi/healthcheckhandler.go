@@ -1,12 +1,35 @@
 package externalapi
 
 import (
+	"encoding/json"
 	"net/http"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 )
 
+// CircuitBreakers provides the states of the circuit breakers of the target APIs
+type CircuitBreakers interface {
+	CircuitBreakers() []resilience.CircuitBreakerStatus
+}
+
+// HealthResponse is the body of the health endpoint. Open circuit breakers are reported, but do not make
+// the gateway unhealthy, as they protect the gateway from failing target APIs.
+type HealthResponse struct {
+	Status          string                            `json:"status"`
+	CircuitBreakers []resilience.CircuitBreakerStatus `json:"circuitBreakers"`
+}
+
 // NewHealthCheckHandler creates handler for performing health check
-func NewHealthCheckHandler() http.Handler {
+func NewHealthCheckHandler(circuitBreakers CircuitBreakers) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		response := HealthResponse{
+			Status:          "ok",
+			CircuitBreakers: circuitBreakers.CircuitBreakers(),
+		}
+
+		w.Header().Set(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationJson)
 		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
 	})
 }
@@ -1,10 +1,13 @@
 package externalapi
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,7 +19,7 @@ func TestHealthCheckHandler_HandleRequest(t *testing.T) {
 		require.NoError(t, err)
 		rr := httptest.NewRecorder()
 
-		handler := NewHealthCheckHandler()
+		handler := NewHealthCheckHandler(resilience.NewRegistry())
 
 		// when
 		handler.ServeHTTP(rr, req)
@@ -24,4 +27,31 @@ func TestHealthCheckHandler_HandleRequest(t *testing.T) {
 		// then
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
+
+	t.Run("should report the states of the circuit breakers", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/health", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		registry := resilience.NewRegistry()
+		registry.Target(model.APIIdentifier{Application: "app", Service: "service", Entry: "entry"},
+			resilience.Policy{CircuitBreaker: resilience.CircuitBreakerPolicy{FailureThreshold: 1}})
+		registry.Target(model.APIIdentifier{Application: "app", Service: "no-breaker"}, resilience.Policy{})
+
+		handler := NewHealthCheckHandler(registry)
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response HealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "ok", response.Status)
+		assert.Equal(t, []resilience.CircuitBreakerStatus{
+			{Application: "app", Service: "service", Entry: "entry", State: "closed"},
+		}, response.CircuitBreakers)
+	})
 }
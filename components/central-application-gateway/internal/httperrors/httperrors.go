@@ -23,6 +23,10 @@ func errorCodeToHttpStatus(code int) int {
 		return http.StatusBadRequest
 	case apperrors.CodeUpstreamServerCallFailed:
 		return http.StatusBadGateway
+	case apperrors.CodeServiceUnavailable:
+		return http.StatusServiceUnavailable
+	case apperrors.CodeUnauthorized:
+		return http.StatusUnauthorized
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,119 @@
+// Package invalidation invalidates the cached proxies of the APIs whose Application or Secrets changed, so that
+// they are created again with the current target URL and credentials instead of waiting for the cache TTL
+package invalidation
+
+import (
+	"reflect"
+
+	"github.com/kyma-project/kyma/components/application-operator/pkg/apis/applicationconnector/v1alpha1"
+	appinformers "github.com/kyma-project/kyma/components/application-operator/pkg/client/informers/externalversions/applicationconnector/v1alpha1"
+	applisters "github.com/kyma-project/kyma/components/application-operator/pkg/client/listers/applicationconnector/v1alpha1"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	k8scache "k8s.io/client-go/tools/cache"
+)
+
+// Cache is the cache of the proxies of the APIs
+type Cache interface {
+	// Invalidate removes the proxy of the API from the cache, it returns false if the proxy was not cached
+	Invalidate(appName, serviceName, apiName string) bool
+}
+
+type invalidator struct {
+	cache             Cache
+	applicationLister applisters.ApplicationLister
+}
+
+// RegisterHandlers registers the handlers of the Application and Secret events invalidating the cached proxies
+// of the affected APIs. The informer of the Secrets must be limited to the namespace of the Application Gateway.
+func RegisterHandlers(cache Cache, applicationInformer appinformers.ApplicationInformer, secretInformer coreinformers.SecretInformer) {
+	i := &invalidator{
+		cache:             cache,
+		applicationLister: applicationInformer.Lister(),
+	}
+
+	applicationInformer.Informer().AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if app, ok := toApplication(obj); ok {
+				i.applicationChanged(nil, app)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldApp, oldOk := toApplication(oldObj)
+			newApp, newOk := toApplication(newObj)
+			if oldOk && newOk {
+				i.applicationChanged(oldApp, newApp)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if app, ok := toApplication(obj); ok {
+				i.applicationChanged(app, nil)
+			}
+		},
+	})
+
+	secretInformer.Informer().AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret, oldOk := toSecret(oldObj)
+			newSecret, newOk := toSecret(newObj)
+			if oldOk && newOk && !reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+				i.secretChanged(newSecret.Name)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if secret, ok := toSecret(obj); ok {
+				i.secretChanged(secret.Name)
+			}
+		},
+	})
+}
+
+func (i *invalidator) applicationChanged(old, new *v1alpha1.Application) {
+	i.invalidate(applications.ChangedAPIs(old, new), "Application changed")
+}
+
+func (i *invalidator) secretChanged(secretName string) {
+	apps, err := i.applicationLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("failed to list Applications using secret '%s': %s", secretName, err.Error())
+		return
+	}
+
+	for _, app := range apps {
+		i.invalidate(applications.APIsUsingSecret(app, secretName), "secret '"+secretName+"' changed")
+	}
+}
+
+func (i *invalidator) invalidate(apis []model.APIIdentifier, reason string) {
+	for _, api := range apis {
+		if i.cache.Invalidate(api.Application, api.Service, api.Entry) {
+			log.Infof("Invalidated cached proxy of API '%s/%s/%s': %s", api.Application, api.Service, api.Entry, reason)
+		}
+	}
+}
+
+func toApplication(obj interface{}) (*v1alpha1.Application, bool) {
+	if tombstone, ok := obj.(k8scache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	app, ok := obj.(*v1alpha1.Application)
+	if !ok {
+		log.Errorf("unexpected object of type %T in Application event", obj)
+	}
+	return app, ok
+}
+
+func toSecret(obj interface{}) (*v1.Secret, bool) {
+	if tombstone, ok := obj.(k8scache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		log.Errorf("unexpected object of type %T in Secret event", obj)
+	}
+	return secret, ok
+}
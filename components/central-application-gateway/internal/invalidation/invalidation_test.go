@@ -0,0 +1,201 @@
+package invalidation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/components/application-operator/pkg/apis/applicationconnector/v1alpha1"
+	appfake "github.com/kyma-project/kyma/components/application-operator/pkg/client/clientset/versioned/fake"
+	"github.com/kyma-project/kyma/components/application-operator/pkg/client/informers/externalversions"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8scache "k8s.io/client-go/tools/cache"
+)
+
+const (
+	namespace  = "kyma-integration"
+	secretName = "credentials"
+	waitTime   = 5 * time.Second
+	tick       = 10 * time.Millisecond
+)
+
+var (
+	serviceAPI = model.APIIdentifier{Application: "app", Service: "service"}
+	entryAPI   = model.APIIdentifier{Application: "app", Service: "service", Entry: "entry"}
+)
+
+func TestRegisterHandlers(t *testing.T) {
+
+	t.Run("should invalidate APIs of changed Application", func(t *testing.T) {
+		// given
+		cache := &cacheStub{}
+		app := createApplication()
+		appClient, _, stop := startInformers(t, cache, app)
+		defer stop()
+
+		// when
+		updated := app.DeepCopy()
+		updated.Spec.Services[0].Entries[0].TargetUrl = "https://other.example.com"
+		_, err := appClient.ApplicationconnectorV1alpha1().Applications().Update(context.Background(), updated, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		// then
+		assert.Eventually(t, func() bool {
+			return len(cache.invalidatedAPIs()) == 2
+		}, waitTime, tick)
+		assert.ElementsMatch(t, []model.APIIdentifier{serviceAPI, entryAPI}, cache.invalidatedAPIs())
+	})
+
+	t.Run("should invalidate APIs using secret only when its data changed", func(t *testing.T) {
+		// given
+		cache := &cacheStub{}
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+			Data:       map[string][]byte{"clientSecret": []byte("secret")},
+		}
+		_, coreClient, stop := startInformers(t, cache, createApplication(), secret)
+		defer stop()
+
+		// when
+		labeled := secret.DeepCopy()
+		labeled.Labels = map[string]string{"label": "value"}
+		_, err := coreClient.CoreV1().Secrets(namespace).Update(context.Background(), labeled, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		rotated := labeled.DeepCopy()
+		rotated.Data["clientSecret"] = []byte("rotated")
+		_, err = coreClient.CoreV1().Secrets(namespace).Update(context.Background(), rotated, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		// then
+		assert.Eventually(t, func() bool {
+			return len(cache.invalidatedAPIs()) >= 2
+		}, waitTime, tick)
+		assert.ElementsMatch(t, []model.APIIdentifier{serviceAPI, entryAPI}, cache.invalidatedAPIs())
+	})
+
+	t.Run("should invalidate APIs using deleted secret", func(t *testing.T) {
+		// given
+		cache := &cacheStub{}
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}}
+		_, coreClient, stop := startInformers(t, cache, createApplication(), secret)
+		defer stop()
+
+		// when
+		err := coreClient.CoreV1().Secrets(namespace).Delete(context.Background(), secretName, metav1.DeleteOptions{})
+		require.NoError(t, err)
+
+		// then
+		assert.Eventually(t, func() bool {
+			return len(cache.invalidatedAPIs()) == 2
+		}, waitTime, tick)
+		assert.ElementsMatch(t, []model.APIIdentifier{serviceAPI, entryAPI}, cache.invalidatedAPIs())
+	})
+
+	t.Run("should not invalidate APIs using other secret", func(t *testing.T) {
+		// given
+		cache := &cacheStub{}
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace}}
+		_, coreClient, stop := startInformers(t, cache, createApplication(), secret)
+		defer stop()
+
+		// when
+		err := coreClient.CoreV1().Secrets(namespace).Delete(context.Background(), "other", metav1.DeleteOptions{})
+		require.NoError(t, err)
+
+		// then
+		assert.Never(t, func() bool {
+			return len(cache.invalidatedAPIs()) > 0
+		}, 100*time.Millisecond, tick)
+	})
+}
+
+// cacheStub records the invalidated APIs
+type cacheStub struct {
+	mutex       sync.Mutex
+	invalidated []model.APIIdentifier
+}
+
+func (c *cacheStub) Invalidate(appName, serviceName, apiName string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.invalidated = append(c.invalidated, model.APIIdentifier{Application: appName, Service: serviceName, Entry: apiName})
+	return true
+}
+
+func (c *cacheStub) invalidatedAPIs() []model.APIIdentifier {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]model.APIIdentifier{}, c.invalidated...)
+}
+
+func (c *cacheStub) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.invalidated = nil
+}
+
+func startInformers(t *testing.T, cache *cacheStub, app *v1alpha1.Application, secrets ...*v1.Secret) (*appfake.Clientset, *fake.Clientset, func()) {
+	appClient := appfake.NewSimpleClientset(app)
+	coreClient := fake.NewSimpleClientset()
+	for _, secret := range secrets {
+		_, err := coreClient.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	appInformerFactory := externalversions.NewSharedInformerFactory(appClient, 0)
+	secretInformerFactory := informers.NewSharedInformerFactoryWithOptions(coreClient, 0, informers.WithNamespace(namespace))
+
+	applicationInformer := appInformerFactory.Applicationconnector().V1alpha1().Applications()
+	secretInformer := secretInformerFactory.Core().V1().Secrets()
+
+	RegisterHandlers(cache, applicationInformer, secretInformer)
+
+	stopCh := make(chan struct{})
+	appInformerFactory.Start(stopCh)
+	secretInformerFactory.Start(stopCh)
+	require.True(t, k8scache.WaitForCacheSync(stopCh, applicationInformer.Informer().HasSynced, secretInformer.Informer().HasSynced))
+
+	// the APIs of the existing Application are invalidated when it is added
+	require.Eventually(t, func() bool {
+		return len(cache.invalidatedAPIs()) == 2
+	}, waitTime, tick)
+	cache.reset()
+
+	return appClient, coreClient, func() { close(stopCh) }
+}
+
+func createApplication() *v1alpha1.Application {
+	return &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Spec: v1alpha1.ApplicationSpec{
+			Services: []v1alpha1.Service{
+				{
+					DisplayName: "Service",
+					Entries: []v1alpha1.Entry{
+						{
+							Type:      "API",
+							Name:      "Entry",
+							TargetUrl: "https://www.example.com",
+							Credentials: v1alpha1.Credentials{
+								Type:              "OAuth",
+								SecretName:        secretName,
+								AuthenticationUrl: "https://www.example.com/token",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package applications
+
+import (
+	"reflect"
+
+	"github.com/kyma-project/kyma/components/application-operator/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/kyma-project/kyma/components/application-operator/pkg/normalization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+// ChangedAPIs returns the identifiers of the APIs whose definition differs between the old and the new version of
+// the Application, both in the legacy mode and in the Compass mode. The old version is nil for created Applications
+// and the new version is nil for deleted ones.
+func ChangedAPIs(old, new *v1alpha1.Application) []model.APIIdentifier {
+	oldAPIs := apiDefinitions(old)
+	newAPIs := apiDefinitions(new)
+
+	changed := make([]model.APIIdentifier, 0)
+	for id, oldAPI := range oldAPIs {
+		if newAPI, found := newAPIs[id]; !found || !reflect.DeepEqual(oldAPI, newAPI) {
+			changed = append(changed, id)
+		}
+	}
+	for id := range newAPIs {
+		if _, found := oldAPIs[id]; !found {
+			changed = append(changed, id)
+		}
+	}
+
+	return changed
+}
+
+// APIsUsingSecret returns the identifiers of the APIs of the Application reading their credentials or request
+// parameters from the secret
+func APIsUsingSecret(app *v1alpha1.Application, secretName string) []model.APIIdentifier {
+	apis := make([]model.APIIdentifier, 0)
+	for id, api := range apiDefinitions(app) {
+		usesCredentials := api.Credentials != nil && api.Credentials.SecretName == secretName
+		if usesCredentials || api.RequestParametersSecretName == secretName {
+			apis = append(apis, id)
+		}
+	}
+
+	return apis
+}
+
+// apiDefinitions returns the APIs of the Application keyed by the identifiers they are resolved with by
+// GetByServiceName (without entry) and GetByEntryName
+func apiDefinitions(app *v1alpha1.Application) map[model.APIIdentifier]*ServiceAPI {
+	apis := map[model.APIIdentifier]*ServiceAPI{}
+	if app == nil {
+		return apis
+	}
+
+	for _, service := range app.Spec.Services {
+		serviceName := normalization.NormalizeName(service.DisplayName)
+		for _, entry := range service.Entries {
+			if entry.Type != specAPIType {
+				continue
+			}
+
+			settings, err := getAPISettings(app, service, entry)
+			if err != nil {
+				// invalid annotations break all APIs of the Application, so they change whenever the annotations change
+				settings = apiSettings{
+					resiliencePolicy: []byte(app.Annotations[ResiliencePolicyAnnotation]),
+					openAPIMode:      app.Annotations[OpenAPIModeAnnotation],
+				}
+			}
+			api := convertAPI(entry, settings)
+
+			// the first matching entry is used as in the repository
+			for _, id := range []model.APIIdentifier{
+				{Application: app.Name, Service: serviceName},
+				{Application: app.Name, Service: serviceName, Entry: normalization.NormalizeName(entry.Name)},
+			} {
+				if _, found := apis[id]; !found {
+					apis[id] = api
+				}
+			}
+		}
+	}
+
+	return apis
+}
@@ -0,0 +1,137 @@
+package applications_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedAPIs(t *testing.T) {
+
+	service1APIs := []model.APIIdentifier{
+		{Application: "production", Service: "service-1"},
+		{Application: "production", Service: "service-1", Entry: "service-entry-1"},
+	}
+	// the entry of the second service has no name, so it is resolved with the same identifier in both modes
+	allAPIs := append([]model.APIIdentifier{{Application: "production", Service: "products-api"}}, service1APIs...)
+
+	t.Run("should return no APIs if Application did not change", func(t *testing.T) {
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), createApplication("production"))
+
+		// then
+		assert.Empty(t, changed)
+	})
+
+	t.Run("should return APIs of changed entry", func(t *testing.T) {
+		// given
+		updated := createApplication("production")
+		updated.Spec.Services[0].Entries[0].TargetUrl = "https://192.168.1.4"
+
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), updated)
+
+		// then
+		assert.ElementsMatch(t, service1APIs, changed)
+	})
+
+	t.Run("should return APIs of entry with changed credentials secret", func(t *testing.T) {
+		// given
+		updated := createApplication("production")
+		updated.Spec.Services[0].Entries[0].Credentials.SecretName = "OtherSecretName"
+
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), updated)
+
+		// then
+		assert.ElementsMatch(t, service1APIs, changed)
+	})
+
+	t.Run("should return APIs of entry with changed resilience policy", func(t *testing.T) {
+		// given
+		updated := createApplication("production")
+		updated.Annotations = map[string]string{applications.ResiliencePolicyAnnotation: `{"service-1": {"retry": {"maxRetries": 5}}}`}
+
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), updated)
+
+		// then
+		assert.ElementsMatch(t, service1APIs, changed)
+	})
+
+	t.Run("should return APIs of entry with changed OpenAPI mode", func(t *testing.T) {
+		// given
+		updated := createApplication("production")
+		updated.Annotations = map[string]string{applications.OpenAPIModeAnnotation: `{"service-1/service-entry-1": "sandbox"}`}
+
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), updated)
+
+		// then
+		assert.ElementsMatch(t, service1APIs, changed)
+	})
+
+	t.Run("should return all APIs if resilience policy annotation became invalid", func(t *testing.T) {
+		// given
+		updated := createApplication("production")
+		updated.Annotations = map[string]string{applications.ResiliencePolicyAnnotation: "invalid"}
+
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), updated)
+
+		// then
+		assert.ElementsMatch(t, allAPIs, changed)
+	})
+
+	t.Run("should return APIs of removed service", func(t *testing.T) {
+		// given
+		updated := createApplication("production")
+		updated.Spec.Services = updated.Spec.Services[1:]
+
+		// when
+		changed := applications.ChangedAPIs(createApplication("production"), updated)
+
+		// then
+		assert.ElementsMatch(t, service1APIs, changed)
+	})
+
+	t.Run("should return all APIs of created and deleted Application", func(t *testing.T) {
+		// when
+		created := applications.ChangedAPIs(nil, createApplication("production"))
+		deleted := applications.ChangedAPIs(createApplication("production"), nil)
+
+		// then
+		assert.ElementsMatch(t, allAPIs, created)
+		assert.ElementsMatch(t, allAPIs, deleted)
+	})
+}
+
+func TestAPIsUsingSecret(t *testing.T) {
+
+	t.Run("should return APIs using secret for credentials or request parameters", func(t *testing.T) {
+		// given
+		app := createApplication("production")
+		app.Spec.Services[0].Entries[0].Credentials.SecretName = "CredentialsSecretName"
+		app.Spec.Services[1].Entries[0].RequestParametersSecretName = "CredentialsSecretName"
+
+		// when
+		apis := applications.APIsUsingSecret(app, "CredentialsSecretName")
+
+		// then
+		assert.ElementsMatch(t, []model.APIIdentifier{
+			{Application: "production", Service: "service-1"},
+			{Application: "production", Service: "service-1", Entry: "service-entry-1"},
+			{Application: "production", Service: "products-api"},
+		}, apis)
+	})
+
+	t.Run("should return no APIs if secret is not used", func(t *testing.T) {
+		// when
+		apis := applications.APIsUsingSecret(createApplication("production"), "OtherSecretName")
+
+		// then
+		assert.Empty(t, apis)
+	})
+}
@@ -3,6 +3,7 @@ package applications
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/kyma-project/kyma/components/application-operator/pkg/apis/applicationconnector/v1alpha1"
@@ -16,6 +17,15 @@ import (
 const (
 	specAPIType    = "API"
 	specEventsType = "Events"
+
+	// ResiliencePolicyAnnotation holds the resilience policies of the APIs of an Application as a JSON object.
+	// A policy is keyed by the service name, or by the service and entry name joined with a slash,
+	// with the names normalized as in the gateway URLs. The policy of an entry takes precedence over the policy of its service.
+	ResiliencePolicyAnnotation = "gateway.applicationconnector.kyma-project.io/resilience-policy"
+
+	// OpenAPIModeAnnotation holds the modes of handling the calls of the APIs of an Application based on their OpenAPI
+	// specifications as a JSON object, keyed as the resilience policies.
+	OpenAPIModeAnnotation = "gateway.applicationconnector.kyma-project.io/openapi-mode"
 )
 
 // Manager contains operations for managing Application CRD
@@ -41,6 +51,12 @@ type ServiceAPI struct {
 	TargetURL                   string
 	Credentials                 *Credentials
 	RequestParametersSecretName string
+	// ResiliencePolicy is the JSON document overriding the default resilience policy of the gateway for the API
+	ResiliencePolicy []byte
+	// SpecificationURL is the URL of the specification of the API
+	SpecificationURL string
+	// OpenAPIMode is the mode of handling the calls of the API based on its OpenAPI specification, empty if the calls are only proxied
+	OpenAPIMode string
 }
 
 type predicateFunc func(service v1alpha1.Service, entry v1alpha1.Entry) bool
@@ -104,7 +120,11 @@ func (r *repository) get(appName string, predicate func(service v1alpha1.Service
 	for _, service := range app.Spec.Services {
 		for _, entry := range service.Entries {
 			if predicate(service, entry) {
-				return convert(service, entry)
+				settings, err := getAPISettings(app, service, entry)
+				if err != nil {
+					return Service{}, err
+				}
+				return convert(service, entry, settings)
 			}
 		}
 	}
@@ -129,13 +149,58 @@ func (r *repository) getApplication(appName string) (*v1alpha1.Application, appe
 	return app, nil
 }
 
-func convert(service v1alpha1.Service, entry v1alpha1.Entry) (Service, apperrors.AppError) {
-	api := &ServiceAPI{
-		TargetURL:                   entry.TargetUrl,
-		Credentials:                 convertCredentialsFromK8sType(entry.Credentials),
-		RequestParametersSecretName: entry.RequestParametersSecretName,
+// apiSettings are the settings of an API held in the annotations of its Application
+type apiSettings struct {
+	resiliencePolicy []byte
+	openAPIMode      string
+}
+
+func getAPISettings(app *v1alpha1.Application, service v1alpha1.Service, entry v1alpha1.Entry) (apiSettings, apperrors.AppError) {
+	resiliencePolicy, err := getAnnotationValue(app, ResiliencePolicyAnnotation, service, entry)
+	if err != nil {
+		return apiSettings{}, err
 	}
 
+	openAPIMode := ""
+	if value, err := getAnnotationValue(app, OpenAPIModeAnnotation, service, entry); err != nil {
+		return apiSettings{}, err
+	} else if value != nil {
+		if err := json.Unmarshal(value, &openAPIMode); err != nil {
+			message := fmt.Sprintf("failed to decode OpenAPI mode in annotation %s of Application '%s': %s", OpenAPIModeAnnotation, app.Name, err.Error())
+			log.Error(message)
+			return apiSettings{}, apperrors.Internal(message)
+		}
+	}
+
+	return apiSettings{
+		resiliencePolicy: resiliencePolicy,
+		openAPIMode:      openAPIMode,
+	}, nil
+}
+
+// getAnnotationValue returns the value for the API from the annotation holding a JSON object keyed by the service name,
+// or by the service and entry name joined with a slash
+func getAnnotationValue(app *v1alpha1.Application, annotationName string, service v1alpha1.Service, entry v1alpha1.Entry) (json.RawMessage, apperrors.AppError) {
+	annotation, found := app.Annotations[annotationName]
+	if !found {
+		return nil, nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(annotation), &values); err != nil {
+		message := fmt.Sprintf("failed to decode annotation %s of Application '%s': %s", annotationName, app.Name, err.Error())
+		log.Error(message)
+		return nil, apperrors.Internal(message)
+	}
+
+	serviceName := normalization.NormalizeName(service.DisplayName)
+	if value, found := values[serviceName+"/"+normalization.NormalizeName(entry.Name)]; found {
+		return value, nil
+	}
+	return values[serviceName], nil
+}
+
+func convert(service v1alpha1.Service, entry v1alpha1.Entry, settings apiSettings) (Service, apperrors.AppError) {
 	return Service{
 		ID:                  service.ID,
 		Name:                service.Name,
@@ -143,10 +208,21 @@ func convert(service v1alpha1.Service, entry v1alpha1.Entry) (Service, apperrors
 		LongDescription:     service.LongDescription,
 		ProviderDisplayName: service.ProviderDisplayName,
 		Tags:                service.Tags,
-		API:                 api,
+		API:                 convertAPI(entry, settings),
 	}, nil
 }
 
+func convertAPI(entry v1alpha1.Entry, settings apiSettings) *ServiceAPI {
+	return &ServiceAPI{
+		TargetURL:                   entry.TargetUrl,
+		Credentials:                 convertCredentialsFromK8sType(entry.Credentials),
+		RequestParametersSecretName: entry.RequestParametersSecretName,
+		ResiliencePolicy:            settings.resiliencePolicy,
+		SpecificationURL:            entry.SpecificationUrl,
+		OpenAPIMode:                 settings.openAPIMode,
+	}
+}
+
 func convertCredentialsFromK8sType(credentials v1alpha1.Credentials) *Credentials {
 	emptyCredentials := v1alpha1.Credentials{}
 	if credentials == emptyCredentials {
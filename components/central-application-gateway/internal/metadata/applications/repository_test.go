@@ -74,6 +74,124 @@ func TestGetServices(t *testing.T) {
 		})
 	}
 
+	t.Run("should get resilience policy of service or entry", func(t *testing.T) {
+		for _, testCase := range []struct {
+			description      string
+			annotation       string
+			expectedPolicy   string
+			getByServiceName bool
+		}{
+			{
+				description:    "should get policy of entry",
+				annotation:     `{"service-1": {"retry": {"maxRetries": 1}}, "service-1/service-entry-1": {"retry": {"maxRetries": 2}}}`,
+				expectedPolicy: `{"retry": {"maxRetries": 2}}`,
+			},
+			{
+				description:    "should get policy of service if entry has no policy",
+				annotation:     `{"service-1": {"retry": {"maxRetries": 1}}}`,
+				expectedPolicy: `{"retry": {"maxRetries": 1}}`,
+			},
+			{
+				description:      "should get policy of service by service name",
+				annotation:       `{"service-1": {"retry": {"maxRetries": 1}}}`,
+				expectedPolicy:   `{"retry": {"maxRetries": 1}}`,
+				getByServiceName: true,
+			},
+			{
+				description: "should get no policy if service has no policy",
+				annotation:  `{"products-api": {"retry": {"maxRetries": 1}}}`,
+			},
+		} {
+			t.Run(testCase.description, func(t *testing.T) {
+				// given
+				application := createApplication("production")
+				application.Annotations = map[string]string{applications.ResiliencePolicyAnnotation: testCase.annotation}
+
+				managerMock := &mocks.Manager{}
+				managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
+					Return(application, nil)
+
+				repository := applications.NewServiceRepository(managerMock)
+
+				// when
+				var service applications.Service
+				var err apperrors.AppError
+				if testCase.getByServiceName {
+					service, err = repository.GetByServiceName("production", "service-1")
+				} else {
+					service, err = repository.GetByEntryName("production", "service-1", "service-entry-1")
+				}
+
+				// then
+				require.NoError(t, err)
+				if testCase.expectedPolicy == "" {
+					assert.Empty(t, service.API.ResiliencePolicy)
+				} else {
+					assert.JSONEq(t, testCase.expectedPolicy, string(service.API.ResiliencePolicy))
+				}
+			})
+		}
+	})
+
+	t.Run("should return internal error if resilience policy annotation is invalid", func(t *testing.T) {
+		// given
+		application := createApplication("production")
+		application.Annotations = map[string]string{applications.ResiliencePolicyAnnotation: "{"}
+
+		managerMock := &mocks.Manager{}
+		managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
+			Return(application, nil)
+
+		repository := applications.NewServiceRepository(managerMock)
+
+		// when
+		_, err := repository.GetByServiceName("production", "service-1")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+	})
+
+	t.Run("should get OpenAPI mode and specification URL of entry", func(t *testing.T) {
+		// given
+		application := createApplication("production")
+		application.Annotations = map[string]string{applications.OpenAPIModeAnnotation: `{"service-1": "validation", "service-1/service-entry-1": "sandbox"}`}
+		application.Spec.Services[0].Entries[0].SpecificationUrl = "https://www.example.com/api-docs"
+
+		managerMock := &mocks.Manager{}
+		managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
+			Return(application, nil)
+
+		repository := applications.NewServiceRepository(managerMock)
+
+		// when
+		service, err := repository.GetByEntryName("production", "service-1", "service-entry-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "sandbox", service.API.OpenAPIMode)
+		assert.Equal(t, "https://www.example.com/api-docs", service.API.SpecificationURL)
+	})
+
+	t.Run("should return internal error if OpenAPI mode is not a string", func(t *testing.T) {
+		// given
+		application := createApplication("production")
+		application.Annotations = map[string]string{applications.OpenAPIModeAnnotation: `{"service-1": {"mode": "sandbox"}}`}
+
+		managerMock := &mocks.Manager{}
+		managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
+			Return(application, nil)
+
+		repository := applications.NewServiceRepository(managerMock)
+
+		// when
+		_, err := repository.GetByServiceName("production", "service-1")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+	})
+
 	for _, testCase := range []testcase{
 		{
 			description: "should return not found error if service doesn't exist",
@@ -30,6 +30,10 @@ type API struct {
 	Spec []byte
 	// RequestParameters will be used with request send by the Application Gateway
 	RequestParameters *authorization.RequestParameters
+	// ResiliencePolicy contains the JSON document overriding the default resilience policy for the API
+	ResiliencePolicy []byte
+	// OpenAPIMode is the mode of handling the calls of the API based on its OpenAPI specification held in Spec
+	OpenAPIMode string
 }
 
 // Events contains specification for events.
@@ -8,6 +8,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/specification"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 )
 
@@ -22,6 +23,16 @@ const (
 	PrivateKeyKey      = "key"
 	CertificateKey     = "crt"
 	CommonNameKey      = "commonName"
+	KeyIDKey           = "keyId"
+	IssuerKey          = "issuer"
+	SubjectKey         = "subject"
+	AudienceKey        = "audience"
+	ScopeKey           = "scope"
+
+	TypeOAuthPrivateKeyJWT = "OAuthPrivateKeyJWT"
+	TypeOAuthMTLS          = "OAuthMTLS"
+	TypeOAuthJWTBearer     = "OAuthJWTBearer"
+	TypeOAuthSAMLBearer    = "OAuthSAMLBearer"
 
 	HeadersKey         = "headers"
 	QueryParametersKey = "queryParameters"
@@ -34,19 +45,23 @@ type Service interface {
 }
 
 type defaultService struct {
-	secretsRepository secrets.Repository
+	secretsRepository       secrets.Repository
+	specificationRepository specification.Repository
 }
 
-func NewService(secretsRepository secrets.Repository) Service {
+func NewService(secretsRepository secrets.Repository, specificationRepository specification.Repository) Service {
 
 	return defaultService{
-		secretsRepository: secretsRepository,
+		secretsRepository:       secretsRepository,
+		specificationRepository: specificationRepository,
 	}
 }
 
 func (sas defaultService) Read(applicationAPI *applications.ServiceAPI) (*model.API, apperrors.AppError) {
 	api := &model.API{
-		TargetUrl: applicationAPI.TargetURL,
+		TargetUrl:        applicationAPI.TargetURL,
+		ResiliencePolicy: applicationAPI.ResiliencePolicy,
+		OpenAPIMode:      applicationAPI.OpenAPIMode,
 	}
 
 	if applicationAPI.Credentials != nil {
@@ -77,6 +92,20 @@ func (sas defaultService) Read(applicationAPI *applications.ServiceAPI) (*model.
 		api.RequestParameters = requestParameters
 	}
 
+	// the specification is only needed to validate or answer the calls of the API
+	if applicationAPI.OpenAPIMode != "" {
+		if applicationAPI.SpecificationURL == "" {
+			return nil, apperrors.Internal("API with OpenAPI mode '%s' has no specification URL", applicationAPI.OpenAPIMode)
+		}
+
+		spec, err := sas.specificationRepository.Get(applicationAPI.SpecificationURL)
+		if err != nil {
+			return nil, err
+		}
+
+		api.Spec = spec
+	}
+
 	return api, nil
 }
 
@@ -93,6 +122,12 @@ func (sas defaultService) readCredentials(secret map[string][]byte, applicationA
 		credentials = &authorization.Credentials{
 			OAuth: oAuthCredentials,
 		}
+	} else if isOAuthFlow(credentialsType) {
+		oAuthCredentials, err := getOAuthFlowCredentials(credentialsType, secret, applicationAPI.Credentials.URL)
+		if err != nil {
+			return nil, err
+		}
+		credentials = oAuthCredentials
 	} else if credentialsType == TypeBasic {
 		credentials = &authorization.Credentials{
 			BasicAuth: getBasicAuthCredentials(secret),
@@ -158,6 +193,79 @@ func getOAuthCredentials(secret map[string][]byte, url string) (*authorization.O
 	}, nil
 }
 
+func isOAuthFlow(credentialsType string) bool {
+	switch credentialsType {
+	case TypeOAuthPrivateKeyJWT, TypeOAuthMTLS, TypeOAuthJWTBearer, TypeOAuthSAMLBearer:
+		return true
+	default:
+		return false
+	}
+}
+
+// getOAuthFlowCredentials reads the credentials of the OAuth flows signing assertions or authenticating with
+// the key pair stored in the secret, e.g. the one generated for the CertificateGen credentials
+func getOAuthFlowCredentials(credentialsType string, secret map[string][]byte, url string) (*authorization.Credentials, apperrors.AppError) {
+	requestParameters, err := getRequestParameters(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch credentialsType {
+	case TypeOAuthPrivateKeyJWT:
+		return &authorization.Credentials{
+			OAuthPrivateKeyJWT: &authorization.OAuthPrivateKeyJWT{
+				URL:               url,
+				ClientID:          string(secret[ClientIDKey]),
+				PrivateKey:        secret[PrivateKeyKey],
+				KeyID:             string(secret[KeyIDKey]),
+				Scope:             string(secret[ScopeKey]),
+				RequestParameters: requestParameters,
+			},
+		}, nil
+	case TypeOAuthMTLS:
+		return &authorization.Credentials{
+			OAuthMTLS: &authorization.OAuthMTLS{
+				URL:               url,
+				ClientID:          string(secret[ClientIDKey]),
+				Certificate:       secret[CertificateKey],
+				PrivateKey:        secret[PrivateKeyKey],
+				Scope:             string(secret[ScopeKey]),
+				RequestParameters: requestParameters,
+			},
+		}, nil
+	case TypeOAuthJWTBearer:
+		return &authorization.Credentials{
+			OAuthJWTBearer: &authorization.OAuthJWTBearer{
+				URL:               url,
+				ClientID:          string(secret[ClientIDKey]),
+				ClientSecret:      string(secret[ClientSecretKey]),
+				PrivateKey:        secret[PrivateKeyKey],
+				KeyID:             string(secret[KeyIDKey]),
+				Issuer:            string(secret[IssuerKey]),
+				Subject:           string(secret[SubjectKey]),
+				Audience:          string(secret[AudienceKey]),
+				Scope:             string(secret[ScopeKey]),
+				RequestParameters: requestParameters,
+			},
+		}, nil
+	default:
+		return &authorization.Credentials{
+			OAuthSAMLBearer: &authorization.OAuthSAMLBearer{
+				URL:               url,
+				ClientID:          string(secret[ClientIDKey]),
+				ClientSecret:      string(secret[ClientSecretKey]),
+				Certificate:       secret[CertificateKey],
+				PrivateKey:        secret[PrivateKeyKey],
+				Issuer:            string(secret[IssuerKey]),
+				Subject:           string(secret[SubjectKey]),
+				Audience:          string(secret[AudienceKey]),
+				Scope:             string(secret[ScopeKey]),
+				RequestParameters: requestParameters,
+			},
+		}, nil
+	}
+}
+
 func getBasicAuthCredentials(secret map[string][]byte) *authorization.BasicAuth {
 	return &authorization.BasicAuth{
 		Username: string(secret[UsernameKey]),
@@ -8,6 +8,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
 
 	secretsmocks "github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets/mocks"
+	specificationmocks "github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/specification/mocks"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
@@ -64,6 +65,17 @@ func TestDefaultService_Read(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "api with resilience policy",
+			applicationAPI: &applications.ServiceAPI{
+				TargetURL:        targetUrl,
+				ResiliencePolicy: []byte(`{"retry": {"maxRetries": 0}}`),
+			},
+			resultingAPI: &model.API{
+				TargetUrl:        targetUrl,
+				ResiliencePolicy: []byte(`{"retry": {"maxRetries": 0}}`),
+			},
+		},
 		{
 			description: "api with basic auth credentials",
 			applicationAPI: &applications.ServiceAPI{
@@ -114,6 +126,68 @@ func TestDefaultService_Read(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "api with oauth mtls credentials",
+			applicationAPI: &applications.ServiceAPI{
+				TargetURL: targetUrl,
+				Credentials: &applications.Credentials{
+					Type:       TypeOAuthMTLS,
+					SecretName: secretName,
+					URL:        oauthUrl,
+				},
+			},
+			credentialsSecret: map[string][]byte{
+				ClientIDKey:    []byte(clientId),
+				CertificateKey: certificate,
+				PrivateKeyKey:  privateKey,
+				ScopeKey:       []byte("read"),
+			},
+			resultingAPI: &model.API{
+				TargetUrl: targetUrl,
+				Credentials: &authorization.Credentials{
+					OAuthMTLS: &authorization.OAuthMTLS{
+						URL:         oauthUrl,
+						ClientID:    clientId,
+						Certificate: certificate,
+						PrivateKey:  privateKey,
+						Scope:       "read",
+					},
+				},
+			},
+		},
+		{
+			description: "api with oauth saml bearer credentials",
+			applicationAPI: &applications.ServiceAPI{
+				TargetURL: targetUrl,
+				Credentials: &applications.Credentials{
+					Type:       TypeOAuthSAMLBearer,
+					SecretName: secretName,
+					URL:        oauthUrl,
+				},
+			},
+			credentialsSecret: map[string][]byte{
+				ClientIDKey:     []byte(clientId),
+				ClientSecretKey: []byte(clientSecret),
+				CertificateKey:  certificate,
+				PrivateKeyKey:   privateKey,
+				SubjectKey:      []byte("user@example.com"),
+				AudienceKey:     []byte("audience"),
+			},
+			resultingAPI: &model.API{
+				TargetUrl: targetUrl,
+				Credentials: &authorization.Credentials{
+					OAuthSAMLBearer: &authorization.OAuthSAMLBearer{
+						URL:          oauthUrl,
+						ClientID:     clientId,
+						ClientSecret: clientSecret,
+						Certificate:  certificate,
+						PrivateKey:   privateKey,
+						Subject:      "user@example.com",
+						Audience:     "audience",
+					},
+				},
+			},
+		},
 		{
 			description: "api without credentials",
 			applicationAPI: &applications.ServiceAPI{
@@ -199,7 +273,7 @@ func TestDefaultService_Read(t *testing.T) {
 				secretsRepository.On("Get", test.requestParamsSecretName).Return(test.requestParamsSecret, nil)
 			}
 
-			service := NewService(secretsRepository)
+			service := NewService(secretsRepository, new(specificationmocks.Repository))
 
 			// when
 			api, err := service.Read(test.applicationAPI)
@@ -225,7 +299,7 @@ func TestDefaultService_Read(t *testing.T) {
 		secretsRepository.On("Get", "credentialsSecret-name").
 			Return(nil, apperrors.Internal("credentialsSecret error"))
 
-		service := NewService(secretsRepository)
+		service := NewService(secretsRepository, new(specificationmocks.Repository))
 
 		// when
 		api, err := service.Read(applicationServiceAPI)
@@ -249,7 +323,7 @@ func TestDefaultService_Read(t *testing.T) {
 		secretsRepository.On("Get", secretName).
 			Return(nil, apperrors.Internal("request params error"))
 
-		service := NewService(secretsRepository)
+		service := NewService(secretsRepository, new(specificationmocks.Repository))
 
 		// when
 		api, err := service.Read(applicationServiceAPI)
@@ -261,4 +335,49 @@ func TestDefaultService_Read(t *testing.T) {
 
 		secretsRepository.AssertExpectations(t)
 	})
+
+	t.Run("should read specification of API with OpenAPI mode", func(t *testing.T) {
+		// given
+		applicationServiceAPI := &applications.ServiceAPI{
+			TargetURL:        targetUrl,
+			SpecificationURL: "http://target.com/api-docs",
+			OpenAPIMode:      "validation",
+		}
+
+		specificationRepository := new(specificationmocks.Repository)
+		specificationRepository.On("Get", "http://target.com/api-docs").Return([]byte(`{"openapi": "3.0.0"}`), nil)
+
+		service := NewService(new(secretsmocks.Repository), specificationRepository)
+
+		// when
+		api, err := service.Read(applicationServiceAPI)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, &model.API{
+			TargetUrl:   targetUrl,
+			Spec:        []byte(`{"openapi": "3.0.0"}`),
+			OpenAPIMode: "validation",
+		}, api)
+
+		specificationRepository.AssertExpectations(t)
+	})
+
+	t.Run("should return error when API with OpenAPI mode has no specification URL", func(t *testing.T) {
+		// given
+		applicationServiceAPI := &applications.ServiceAPI{
+			TargetURL:   targetUrl,
+			OpenAPIMode: "sandbox",
+		}
+
+		service := NewService(new(secretsmocks.Repository), new(specificationmocks.Repository))
+
+		// when
+		api, err := service.Read(applicationServiceAPI)
+
+		// then
+		assert.Error(t, err)
+		assert.Nil(t, api)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+	})
 }
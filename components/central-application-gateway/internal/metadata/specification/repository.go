@@ -0,0 +1,49 @@
+// Package specification contains components for fetching the specifications of the APIs
+package specification
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository contains operations for fetching the specifications of the APIs
+//go:generate mockery --name=Repository
+type Repository interface {
+	Get(url string) ([]byte, apperrors.AppError)
+}
+
+type repository struct {
+	httpClient *http.Client
+}
+
+// NewRepository creates a new specification repository fetching specifications with the given timeout in seconds
+func NewRepository(timeout int) Repository {
+	return &repository{
+		httpClient: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}
+}
+
+func (r *repository) Get(url string) ([]byte, apperrors.AppError) {
+	response, err := r.httpClient.Get(url)
+	if err != nil {
+		log.Errorf("failed to fetch specification from '%s': %s", url, err.Error())
+		return nil, apperrors.UpstreamServerCallFailed("failed to fetch specification from '%s', %s", url, err.Error())
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		log.Errorf("incorrect response code '%d' while fetching specification from '%s'", response.StatusCode, url)
+		return nil, apperrors.UpstreamServerCallFailed("incorrect response code '%d' while fetching specification from '%s'", response.StatusCode, url)
+	}
+
+	spec, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, apperrors.Internal("failed to read specification from '%s', %s", url, err.Error())
+	}
+
+	return spec, nil
+}
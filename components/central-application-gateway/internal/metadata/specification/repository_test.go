@@ -0,0 +1,48 @@
+package specification
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Get(t *testing.T) {
+	t.Run("should get specification", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"openapi": "3.0.0"}`))
+		}))
+		defer server.Close()
+
+		repository := NewRepository(10)
+
+		// when
+		spec, err := repository.Get(server.URL)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `{"openapi": "3.0.0"}`, string(spec))
+	})
+
+	t.Run("should return an error in case of incorrect response code", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		repository := NewRepository(10)
+
+		// when
+		spec, err := repository.Get(server.URL)
+
+		// then
+		require.Error(t, err)
+		assert.Nil(t, spec)
+		assert.Equal(t, apperrors.CodeUpstreamServerCallFailed, err.Code())
+	})
+}
@@ -0,0 +1,204 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+const (
+	// maxExampleDepth limits the nesting of the examples generated from recursive schemas
+	maxExampleDepth = 8
+	jsonMediaType   = "application/json"
+)
+
+// WriteExampleResponse answers the request with the first successful response of the matching operation, using
+// the example of the response or an example generated from its schema
+func (h *Handler) WriteExampleResponse(w http.ResponseWriter, r *http.Request) apperrors.AppError {
+	route, _, _, err := h.findRoute(r)
+	if err != nil {
+		return err
+	}
+
+	status, response := exampleResponse(route.Operation.Responses)
+	if response == nil {
+		return apperrors.NotFound("no successful response of %s %s defined in API specification", r.Method, route.Path)
+	}
+
+	mediaType, content := responseContent(response.Content, r.Header.Get("Accept"))
+	if content == nil {
+		w.WriteHeader(status)
+		return nil
+	}
+
+	body, marshalErr := exampleBody(mediaType, mediaTypeExample(content))
+	if marshalErr != nil {
+		return apperrors.Internal("failed to encode example response: %s", marshalErr.Error())
+	}
+
+	w.Header().Set(httpconsts.HeaderContentType, mediaType)
+	w.WriteHeader(status)
+	w.Write(body)
+
+	return nil
+}
+
+// exampleResponse returns the successful response with the lowest status or the default response
+func exampleResponse(responses openapi3.Responses) (int, *openapi3.Response) {
+	statuses := make([]int, 0, len(responses))
+	for key := range responses {
+		status, err := strconv.Atoi(key)
+		if err == nil && status >= 200 && status < 300 {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Ints(statuses)
+
+	if len(statuses) > 0 {
+		return statuses[0], responses.Get(statuses[0]).Value
+	}
+	for _, key := range []string{"2XX", "default"} {
+		if response, found := responses[key]; found && response.Value != nil {
+			return http.StatusOK, response.Value
+		}
+	}
+
+	return 0, nil
+}
+
+// responseContent returns the content of the first accepted media type, the JSON content or the first content
+func responseContent(content openapi3.Content, accept string) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+
+	for _, accepted := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if value, found := content[mediaType]; found {
+			return mediaType, value
+		}
+	}
+
+	if value, found := content[jsonMediaType]; found {
+		return jsonMediaType, value
+	}
+
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	return mediaTypes[0], content[mediaTypes[0]]
+}
+
+func mediaTypeExample(content *openapi3.MediaType) interface{} {
+	if content.Example != nil {
+		return content.Example
+	}
+
+	names := make([]string, 0, len(content.Examples))
+	for name := range content.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if example := content.Examples[name]; example.Value != nil && example.Value.Value != nil {
+			return example.Value.Value
+		}
+	}
+
+	return schemaExample(content.Schema, 0)
+}
+
+// schemaExample returns the example or the default value of the schema, or generates an example from its type
+func schemaExample(schemaRef *openapi3.SchemaRef, depth int) interface{} {
+	if schemaRef == nil || schemaRef.Value == nil || depth > maxExampleDepth {
+		return nil
+	}
+	schema := schemaRef.Value
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		example := map[string]interface{}{}
+		for _, part := range schema.AllOf {
+			if properties, ok := schemaExample(part, depth+1).(map[string]interface{}); ok {
+				for name, value := range properties {
+					example[name] = value
+				}
+			}
+		}
+		return example
+	case len(schema.OneOf) > 0:
+		return schemaExample(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return schemaExample(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.Type {
+	case "array":
+		return []interface{}{schemaExample(schema.Items, depth+1)}
+	case "string":
+		return stringExample(schema.Format)
+	case "integer", "number":
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 0
+	case "boolean":
+		return true
+	case "object", "":
+		if len(schema.Properties) == 0 && schema.Type == "" {
+			return nil
+		}
+		example := map[string]interface{}{}
+		for name, property := range schema.Properties {
+			example[name] = schemaExample(property, depth+1)
+		}
+		return example
+	default:
+		return nil
+	}
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date":
+		return "2021-01-01"
+	case "date-time":
+		return "2021-01-01T00:00:00Z"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "email":
+		return "user@example.com"
+	case "uri":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+// exampleBody encodes the example in JSON unless it is a string and the media type is not JSON
+func exampleBody(mediaType string, example interface{}) ([]byte, error) {
+	if s, ok := example.(string); ok && !isJSON(mediaType) {
+		return []byte(s), nil
+	}
+
+	return json.Marshal(example)
+}
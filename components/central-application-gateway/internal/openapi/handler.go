@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ModeValidation rejects the calls which do not match the specification before they are sent to the target API
+	ModeValidation = "validation"
+	// ModeSandbox validates the calls and answers them with the examples of the specification instead of the target API
+	ModeSandbox = "sandbox"
+)
+
+func init() {
+	// OpenAPI allows any format, specifications must not be rejected for formats unknown to the validator
+	openapi3.SchemaFormatValidationDisabled = true
+}
+
+// Handler validates the calls of an API and answers them in the sandbox mode based on the OpenAPI specification of the API
+type Handler struct {
+	mode   string
+	router routers.Router
+	// basePaths are the paths of the servers of the specification, which the paths of the calls may start with
+	basePaths []string
+	// maxBodySize is the maximum size in bytes of a JSON body read to validate it, larger bodies are not validated
+	maxBodySize int64
+}
+
+// NewHandler creates a handler for the mode from the OpenAPI 3 or Swagger 2.0 specification in JSON or YAML, which
+// validates JSON request bodies up to the maximum body size
+func NewHandler(mode string, spec []byte, maxBodySize int64) (*Handler, error) {
+	if mode != ModeValidation && mode != ModeSandbox {
+		return nil, fmt.Errorf("unknown OpenAPI mode '%s', expected '%s' or '%s'", mode, ModeValidation, ModeSandbox)
+	}
+
+	doc, err := loadSpecification(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API specification: %s", err.Error())
+	}
+
+	// the calls are matched by their paths only, as the gateway and not the servers of the specification is called
+	basePaths := serverBasePaths(doc.Servers)
+	doc.Servers = nil
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API specification: %s", err.Error())
+	}
+
+	return &Handler{
+		mode:        mode,
+		router:      router,
+		basePaths:   basePaths,
+		maxBodySize: maxBodySize,
+	}, nil
+}
+
+// Sandbox returns true if the calls are answered by the handler instead of the target API
+func (h *Handler) Sandbox() bool {
+	return h.mode == ModeSandbox
+}
+
+// ValidateRequest checks the path, method, parameters and JSON body of the request against the specification.
+// Bodies which are not JSON or larger than the maximum body size are passed without validating them. The body of the
+// request can still be read afterwards.
+func (h *Handler) ValidateRequest(r *http.Request) apperrors.AppError {
+	body, validateBody, err := h.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	route, pathParams, routeRequest, err := h.findRoute(r)
+	if err != nil {
+		return err
+	}
+	routeRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    routeRequest,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			// only JSON bodies are validated, other content is passed as it is
+			ExcludeRequestBody: !validateBody,
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	if validationErr := openapi3filter.ValidateRequest(r.Context(), input); validationErr != nil {
+		return apperrors.WrongInput("request does not match API specification: %s", validationErr.Error())
+	}
+
+	return nil
+}
+
+// findRoute returns the operation of the specification matching the request along with the copy of the request used
+// to match it, trying the path of the request as it is and without the base paths of the servers
+func (h *Handler) findRoute(r *http.Request) (*routers.Route, map[string]string, *http.Request, apperrors.AppError) {
+	path := r.URL.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	paths := []string{path}
+	for _, basePath := range h.basePaths {
+		if strings.HasPrefix(path, basePath+"/") {
+			paths = append(paths, strings.TrimPrefix(path, basePath))
+		}
+	}
+
+	var findErr error
+	for _, p := range paths {
+		routeRequest := r.Clone(r.Context())
+		routeRequest.URL.Path = p
+		routeRequest.URL.RawPath = ""
+
+		route, pathParams, err := h.router.FindRoute(routeRequest)
+		if err == nil {
+			return route, pathParams, routeRequest, nil
+		}
+		findErr = err
+	}
+
+	return nil, nil, nil, apperrors.WrongInput("%s %s is not defined in API specification: %s", r.Method, path, findErr.Error())
+}
+
+func loadSpecification(spec []byte) (*openapi3.T, error) {
+	jsonSpec, err := yaml.YAMLToJSON(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var version struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := json.Unmarshal(jsonSpec, &version); err != nil {
+		return nil, err
+	}
+
+	loader := openapi3.NewLoader()
+	if version.Swagger == "" {
+		return loader.LoadFromData(jsonSpec)
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(jsonSpec, &doc2); err != nil {
+		return nil, err
+	}
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, err
+	}
+	// the base path is only converted to a server along with the host
+	if doc2.Host == "" && doc2.BasePath != "" {
+		doc.AddServer(&openapi3.Server{URL: doc2.BasePath})
+	}
+	if err := loader.ResolveRefsIn(doc, nil); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func serverBasePaths(servers openapi3.Servers) []string {
+	basePaths := make([]string, 0, len(servers))
+	for _, server := range servers {
+		// server URLs with variables cannot be matched without resolving them
+		if strings.Contains(server.URL, "{") {
+			continue
+		}
+		serverURL, err := url.Parse(server.URL)
+		if err != nil {
+			continue
+		}
+		basePath := strings.TrimSuffix(serverURL.Path, "/")
+		if basePath != "" {
+			basePaths = append(basePaths, basePath)
+		}
+	}
+
+	return basePaths
+}
+
+// readBody reads the JSON body of the request up to the maximum body size and returns whether it is validated. Other
+// bodies are left unread, and the part of a larger body of unknown length read already is sent before the rest.
+func (h *Handler) readBody(r *http.Request) ([]byte, bool, apperrors.AppError) {
+	// an empty body is validated regardless of its content type, e.g. when the specification requires one
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil, true, nil
+	}
+	if !isJSON(r.Header.Get(httpconsts.HeaderContentType)) || r.ContentLength > h.maxBodySize {
+		return nil, false, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.maxBodySize+1))
+	if err != nil {
+		return nil, false, apperrors.Internal("failed to read request body: %s", err.Error())
+	}
+	if int64(len(body)) > h.maxBodySize {
+		r.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+		return nil, false, nil
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, true, nil
+}
+
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == jsonMediaType || strings.HasSuffix(mediaType, "+json")
+}
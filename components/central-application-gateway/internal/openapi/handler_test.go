@@ -0,0 +1,356 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMaxBodySize is the maximum size of the JSON bodies validated in the tests
+const testMaxBodySize = 1 << 10
+
+const openAPISpec = `
+openapi: 3.0.0
+info:
+  title: Orders
+  version: "1.0"
+servers:
+  - url: https://orders.example.com/api/v1
+paths:
+  /orders:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              example:
+                id: order-1
+                quantity: 3
+  /orders/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: expand
+          in: query
+          schema:
+            type: boolean
+      responses:
+        "404":
+          description: not found
+        "200":
+          description: order
+          content:
+            application/xml:
+              example: <order/>
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+    delete:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Order:
+      type: object
+      required: [quantity]
+      properties:
+        id:
+          type: string
+          format: uuid
+        quantity:
+          type: integer
+          minimum: 1
+        status:
+          type: string
+          enum: [open, closed]
+        items:
+          type: array
+          items:
+            type: string
+`
+
+const swaggerSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "Products", "version": "1.0"},
+  "basePath": "/v2",
+  "paths": {
+    "/products": {
+      "post": {
+        "consumes": ["application/json"],
+        "parameters": [{"in": "body", "name": "product", "required": true, "schema": {"$ref": "#/definitions/Product"}}],
+        "responses": {"200": {"description": "product", "schema": {"$ref": "#/definitions/Product"}}}
+      }
+    }
+  },
+  "definitions": {
+    "Product": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {"name": {"type": "string", "example": "Shoe"}}
+    }
+  }
+}`
+
+func TestNewHandler(t *testing.T) {
+	t.Run("should create handler from OpenAPI 3 and Swagger 2.0 specification", func(t *testing.T) {
+		for _, spec := range []string{openAPISpec, swaggerSpec} {
+			// when
+			handler, err := NewHandler(ModeValidation, []byte(spec), testMaxBodySize)
+
+			// then
+			require.NoError(t, err)
+			assert.False(t, handler.Sandbox())
+		}
+	})
+
+	t.Run("should fail for unknown mode", func(t *testing.T) {
+		// when
+		_, err := NewHandler("mock", []byte(openAPISpec), testMaxBodySize)
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for invalid specification", func(t *testing.T) {
+		// when
+		_, err := NewHandler(ModeSandbox, []byte("not a specification"), testMaxBodySize)
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestHandler_ValidateRequest(t *testing.T) {
+	handler, err := NewHandler(ModeValidation, []byte(openAPISpec), testMaxBodySize)
+	require.NoError(t, err)
+
+	t.Run("should accept valid requests", func(t *testing.T) {
+		for _, request := range []*http.Request{
+			jsonRequest(t, http.MethodPost, "/orders", `{"quantity": 2}`),
+			jsonRequest(t, http.MethodPost, "/api/v1/orders", `{"quantity": 2, "status": "open"}`),
+			jsonRequest(t, http.MethodGet, "/orders/order-1?expand=true", ""),
+			jsonRequest(t, http.MethodDelete, "orders/order-1", ""),
+		} {
+			// when
+			err := handler.ValidateRequest(request)
+
+			// then
+			assert.NoError(t, err, "%s %s", request.Method, request.URL)
+		}
+	})
+
+	t.Run("should reject invalid requests", func(t *testing.T) {
+		for _, request := range []*http.Request{
+			jsonRequest(t, http.MethodGet, "/customers", ""),
+			jsonRequest(t, http.MethodPut, "/orders/order-1", ""),
+			jsonRequest(t, http.MethodGet, "/orders/order-1?expand=maybe", ""),
+			jsonRequest(t, http.MethodPost, "/orders", ""),
+			jsonRequest(t, http.MethodPost, "/orders", `{"quantity": 0}`),
+			jsonRequest(t, http.MethodPost, "/orders", `{"status": "open"}`),
+			jsonRequest(t, http.MethodPost, "/orders", `{"quantity": 1, "status": "lost"}`),
+		} {
+			// when
+			err := handler.ValidateRequest(request)
+
+			// then
+			require.Error(t, err, "%s %s", request.Method, request.URL)
+			assert.Equal(t, apperrors.CodeWrongInput, err.Code())
+		}
+	})
+
+	t.Run("should keep body of request", func(t *testing.T) {
+		// given
+		request := jsonRequest(t, http.MethodPost, "/orders", `{"quantity": 2}`)
+
+		// when
+		err := handler.ValidateRequest(request)
+
+		// then
+		require.NoError(t, err)
+		body, readErr := ioutil.ReadAll(request.Body)
+		require.NoError(t, readErr)
+		assert.Equal(t, `{"quantity": 2}`, string(body))
+		assert.Equal(t, "/orders", request.URL.Path)
+	})
+
+	t.Run("should not validate body which is not JSON", func(t *testing.T) {
+		// given
+		request := jsonRequest(t, http.MethodPost, "/orders", "quantity=0")
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		// when
+		err := handler.ValidateRequest(request)
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not validate body larger than maximum size", func(t *testing.T) {
+		// given
+		largeBody := `{"quantity": 0, "note": "` + strings.Repeat("a", testMaxBodySize) + `"}`
+		request := jsonRequest(t, http.MethodPost, "/orders", largeBody)
+
+		// when
+		err := handler.ValidateRequest(request)
+
+		// then
+		require.NoError(t, err)
+		body, readErr := ioutil.ReadAll(request.Body)
+		require.NoError(t, readErr)
+		assert.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("should not validate body of unknown length larger than maximum size", func(t *testing.T) {
+		// given
+		largeBody := `{"quantity": 0, "note": "` + strings.Repeat("a", testMaxBodySize) + `"}`
+		request := jsonRequest(t, http.MethodPost, "/orders", largeBody)
+		request.ContentLength = -1
+
+		// when
+		err := handler.ValidateRequest(request)
+
+		// then
+		require.NoError(t, err)
+		body, readErr := ioutil.ReadAll(request.Body)
+		require.NoError(t, readErr)
+		assert.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("should validate body of unknown length within maximum size", func(t *testing.T) {
+		// given
+		request := jsonRequest(t, http.MethodPost, "/orders", `{"quantity": 0}`)
+		request.ContentLength = -1
+
+		// when
+		err := handler.ValidateRequest(request)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeWrongInput, err.Code())
+	})
+
+	t.Run("should validate body against Swagger 2.0 specification", func(t *testing.T) {
+		// given
+		swaggerHandler, err := NewHandler(ModeValidation, []byte(swaggerSpec), testMaxBodySize)
+		require.NoError(t, err)
+
+		// when
+		validErr := swaggerHandler.ValidateRequest(jsonRequest(t, http.MethodPost, "/v2/products", `{"name": "Boot"}`))
+		invalidErr := swaggerHandler.ValidateRequest(jsonRequest(t, http.MethodPost, "/products", `{"name": 1}`))
+
+		// then
+		assert.NoError(t, validErr)
+		require.Error(t, invalidErr)
+		assert.Equal(t, apperrors.CodeWrongInput, invalidErr.Code())
+	})
+}
+
+func TestHandler_WriteExampleResponse(t *testing.T) {
+	handler, err := NewHandler(ModeSandbox, []byte(openAPISpec), testMaxBodySize)
+	require.NoError(t, err)
+	require.True(t, handler.Sandbox())
+
+	t.Run("should respond with example of response", func(t *testing.T) {
+		// given
+		recorder := httptest.NewRecorder()
+
+		// when
+		err := handler.WriteExampleResponse(recorder, jsonRequest(t, http.MethodPost, "/orders", `{"quantity": 3}`))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"id": "order-1", "quantity": 3}`, recorder.Body.String())
+	})
+
+	t.Run("should respond with example generated from schema of successful response", func(t *testing.T) {
+		// given
+		recorder := httptest.NewRecorder()
+
+		// when
+		err := handler.WriteExampleResponse(recorder, jsonRequest(t, http.MethodGet, "/api/v1/orders/order-1", ""))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var order map[string]interface{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &order))
+		assert.Equal(t, map[string]interface{}{
+			"id":       "00000000-0000-0000-0000-000000000000",
+			"quantity": float64(1),
+			"status":   "open",
+			"items":    []interface{}{"string"},
+		}, order)
+	})
+
+	t.Run("should respond with example of accepted media type", func(t *testing.T) {
+		// given
+		recorder := httptest.NewRecorder()
+		request := jsonRequest(t, http.MethodGet, "/orders/order-1", "")
+		request.Header.Set("Accept", "text/html, application/xml;q=0.9")
+
+		// when
+		err := handler.WriteExampleResponse(recorder, request)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "<order/>", recorder.Body.String())
+	})
+
+	t.Run("should respond without body if response has no content", func(t *testing.T) {
+		// given
+		recorder := httptest.NewRecorder()
+
+		// when
+		err := handler.WriteExampleResponse(recorder, jsonRequest(t, http.MethodDelete, "/orders/order-1", ""))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Empty(t, recorder.Body.String())
+	})
+}
+
+func jsonRequest(t *testing.T, method, path, body string) *http.Request {
+	request, err := http.NewRequest(method, "http://gateway.example.com", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	// the gateway passes the path relative to the target URL, which does not always start with a slash
+	pathURL, err := url.Parse(path)
+	require.NoError(t, err)
+	request.URL.Path = pathURL.Path
+	request.URL.RawQuery = pathURL.RawQuery
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	return request
+}
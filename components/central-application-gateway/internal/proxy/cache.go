@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/openapi"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
@@ -19,6 +20,8 @@ type CacheEntry struct {
 	Proxy                 *httputil.ReverseProxy
 	AuthorizationStrategy *authorizationStrategyWrapper
 	CSRFTokenStrategy     csrf.TokenStrategy
+	// OpenAPIHandler validates or answers the calls based on the specification of the API, it is nil if no OpenAPI mode is set
+	OpenAPIHandler *openapi.Handler
 }
 
 type authorizationStrategyWrapper struct {
@@ -40,7 +43,9 @@ type Cache interface {
 	// Get returns entry from the cache
 	Get(appName, serviceName, apiName string) (*CacheEntry, bool)
 	// Put adds entry to the cache
-	Put(appName, serviceName, apiName string, reverseProxy *httputil.ReverseProxy, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate) *CacheEntry
+	Put(appName, serviceName, apiName string, reverseProxy *httputil.ReverseProxy, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate, openAPIHandler *openapi.Handler) *CacheEntry
+	// Invalidate removes entry from the cache along with the tokens cached by its strategies, it returns false if the entry was not cached
+	Invalidate(appName, serviceName, apiName string) bool
 }
 
 type cache struct {
@@ -58,16 +63,34 @@ func (p *cache) Get(appName, serviceName, apiName string) (*CacheEntry, bool) {
 	key := appName + serviceName + apiName
 	proxy, found := p.proxyCache.Get(key)
 	if !found {
+		cacheMissesTotal.WithLabelValues(appName, serviceName, apiName).Inc()
 		return nil, false
 	}
 
+	cacheHitsTotal.WithLabelValues(appName, serviceName, apiName).Inc()
 	return proxy.(*CacheEntry), found
 }
 
-func (p *cache) Put(appName, serviceName, apiName string, reverseProxy *httputil.ReverseProxy, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate) *CacheEntry {
+func (p *cache) Put(appName, serviceName, apiName string, reverseProxy *httputil.ReverseProxy, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate, openAPIHandler *openapi.Handler) *CacheEntry {
 	key := appName + serviceName + apiName
-	proxy := &CacheEntry{Proxy: reverseProxy, AuthorizationStrategy: &authorizationStrategyWrapper{authorizationStrategy, reverseProxy, clientCertificate}, CSRFTokenStrategy: csrfTokenStrategy}
+	proxy := &CacheEntry{Proxy: reverseProxy, AuthorizationStrategy: &authorizationStrategyWrapper{authorizationStrategy, reverseProxy, clientCertificate}, CSRFTokenStrategy: csrfTokenStrategy, OpenAPIHandler: openAPIHandler}
 	p.proxyCache.Set(key, proxy, gocache.DefaultExpiration)
 
 	return proxy
 }
+
+func (p *cache) Invalidate(appName, serviceName, apiName string) bool {
+	key := appName + serviceName + apiName
+	proxy, found := p.proxyCache.Get(key)
+	if !found {
+		return false
+	}
+	p.proxyCache.Delete(key)
+
+	cacheEntry := proxy.(*CacheEntry)
+	cacheEntry.AuthorizationStrategy.Invalidate()
+	cacheEntry.CSRFTokenStrategy.Invalidate()
+	cacheInvalidationsTotal.WithLabelValues(appName, serviceName, apiName).Inc()
+
+	return true
+}
@@ -37,7 +37,7 @@ func TestCache(t *testing.T) {
 		url := net.FormatURL("http", "www.example.com", 8080, "")
 		proxy := httputil.NewSingleHostReverseProxy(url)
 
-		cacheEntry := cache.Put("app1", "service1", "api1", proxy, authorizationStrategyMock, csrfTokenStrategy, clientCertificate)
+		cacheEntry := cache.Put("app1", "service1", "api1", proxy, authorizationStrategyMock, csrfTokenStrategy, clientCertificate, nil)
 
 		// then
 		require.NotNil(t, cacheEntry)
@@ -55,4 +55,42 @@ func TestCache(t *testing.T) {
 		assert.Equal(t, authorizationStrategyMock, cacheEntry.AuthorizationStrategy.actualStrategy)
 		assert.Equal(t, csrfTokenStrategy, cacheEntry.CSRFTokenStrategy)
 	})
+
+	t.Run("should invalidate cache entry and its strategies", func(t *testing.T) {
+		// given
+		cache := NewCache(60)
+
+		authorizationStrategyMock := &mocks.Strategy{}
+		authorizationStrategyMock.On("Invalidate").Once()
+		csrfTokenStrategy := &csrfmocks.TokenStrategy{}
+		csrfTokenStrategy.On("Invalidate").Once()
+		clientCertificate := clientcert.NewClientCertificate(nil)
+		url := net.FormatURL("http", "www.example.com", 8080, "")
+		proxy := httputil.NewSingleHostReverseProxy(url)
+
+		cache.Put("app1", "service1", "api1", proxy, authorizationStrategyMock, csrfTokenStrategy, clientCertificate, nil)
+
+		// when
+		invalidated := cache.Invalidate("app1", "service1", "api1")
+
+		// then
+		assert.True(t, invalidated)
+		authorizationStrategyMock.AssertExpectations(t)
+		csrfTokenStrategy.AssertExpectations(t)
+
+		cacheEntry, found := cache.Get("app1", "service1", "api1")
+		assert.Nil(t, cacheEntry)
+		assert.False(t, found)
+	})
+
+	t.Run("should return false when invalidating entry which is not cached", func(t *testing.T) {
+		// given
+		cache := NewCache(60)
+
+		// when
+		invalidated := cache.Invalidate("app1", "service1", "api1")
+
+		// then
+		assert.False(t, invalidated)
+	})
 }
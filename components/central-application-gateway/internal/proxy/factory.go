@@ -7,6 +7,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 )
@@ -44,13 +45,16 @@ func New(
 	}
 
 	return &proxy{
-		cache:                        NewCache(config.ProxyCacheTTL),
+		cache:                        newCache(config),
 		skipVerify:                   config.SkipVerify,
 		proxyTimeout:                 config.ProxyTimeout,
 		authorizationStrategyFactory: authorizationStrategyFactory,
 		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
 		extractPathFunc:              pathExtractor,
 		apiExtractor:                 apiExtractor,
+		resiliencePolicy:             config.ResiliencePolicy,
+		resilienceRegistry:           newResilienceRegistry(config),
+		openAPIMaxBodySize:           config.OpenAPIMaxBodySize,
 	}
 }
 
@@ -83,16 +87,33 @@ func NewForCompass(
 	}
 
 	return &proxy{
-		cache:                        NewCache(config.ProxyCacheTTL),
+		cache:                        newCache(config),
 		skipVerify:                   config.SkipVerify,
 		proxyTimeout:                 config.ProxyTimeout,
 		authorizationStrategyFactory: authorizationStrategyFactory,
 		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
 		extractPathFunc:              extractFunc,
 		apiExtractor:                 apiExtractor,
+		resiliencePolicy:             config.ResiliencePolicy,
+		resilienceRegistry:           newResilienceRegistry(config),
+		openAPIMaxBodySize:           config.OpenAPIMaxBodySize,
 	}
 }
 
+func newCache(config Config) Cache {
+	if config.ProxyCache != nil {
+		return config.ProxyCache
+	}
+	return NewCache(config.ProxyCacheTTL)
+}
+
+func newResilienceRegistry(config Config) *resilience.Registry {
+	if config.ResilienceRegistry != nil {
+		return config.ResilienceRegistry
+	}
+	return resilience.NewRegistry()
+}
+
 type apiExtractor struct {
 	serviceDefService metadata.ServiceDefinitionService
 }
@@ -0,0 +1,24 @@
+package proxy
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "central_application_gateway_proxy_cache_hits_total",
+		Help: "Number of requests served with the cached proxy of the target API",
+	}, []string{"application", "service", "entry"})
+	cacheMissesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "central_application_gateway_proxy_cache_misses_total",
+		Help: "Number of requests for which the proxy of the target API was not cached",
+	}, []string{"application", "service", "entry"})
+	cacheInvalidationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "central_application_gateway_proxy_cache_invalidations_total",
+		Help: "Number of cached proxies removed because the Application or the Secrets of the target API changed",
+	}, []string{"application", "service", "entry"})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHitsTotal, cacheMissesTotal, cacheInvalidationsTotal)
+}
@@ -12,11 +12,14 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/httperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/openapi"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig"
+	log "github.com/sirupsen/logrus"
 )
 
 type proxy struct {
@@ -27,6 +30,9 @@ type proxy struct {
 	csrfTokenStrategyFactory     csrf.TokenStrategyFactory
 	extractPathFunc              pathExtractorFunc
 	apiExtractor                 APIExtractor
+	resiliencePolicy             resilience.Policy
+	resilienceRegistry           *resilience.Registry
+	openAPIMaxBodySize           int64
 }
 
 //go:generate mockery --name=APIExtractor
@@ -40,6 +46,15 @@ type Config struct {
 	ProxyTimeout  int
 	Application   string
 	ProxyCacheTTL int
+	// ProxyCache caches the proxies of the APIs, a new cache with the ProxyCacheTTL is created if it is not set
+	ProxyCache Cache
+	// ResiliencePolicy is the default resilience policy, which can be overridden for each API
+	ResiliencePolicy resilience.Policy
+	// ResilienceRegistry keeps the circuit breakers and bulkheads of the APIs, a new registry is created if it is not set
+	ResilienceRegistry *resilience.Registry
+	// OpenAPIMaxBodySize is the maximum size in bytes of a JSON request body validated against the specification of an
+	// API, larger bodies are sent without validating them
+	OpenAPIMaxBodySize int64
 }
 
 func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -57,6 +72,17 @@ func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if cacheEntry.OpenAPIHandler != nil {
+		answered, err := serveFromSpecification(w, r, cacheEntry.OpenAPIHandler)
+		if err != nil {
+			handleErrors(w, err)
+			return
+		}
+		if answered {
+			return
+		}
+	}
+
 	newRequest, cancel := p.setRequestTimeout(r)
 	defer cancel()
 
@@ -92,15 +118,50 @@ func (p *proxy) createCacheEntry(apiIdentifier model.APIIdentifier) (*CacheEntry
 	if err != nil {
 		return nil, err
 	}
+	resiliencePolicy, policyErr := p.resiliencePolicy.Override(serviceAPI.ResiliencePolicy)
+	if policyErr != nil {
+		log.Errorf("invalid resilience policy of service '%s': %s", apiIdentifier.Service, policyErr.Error())
+		return nil, apperrors.Internal("invalid resilience policy of service '%s': %s", apiIdentifier.Service, policyErr.Error())
+	}
+	resilienceTarget := p.resilienceRegistry.Target(apiIdentifier, resiliencePolicy)
+
+	var openAPIHandler *openapi.Handler
+	if serviceAPI.OpenAPIMode != "" {
+		var handlerErr error
+		openAPIHandler, handlerErr = openapi.NewHandler(serviceAPI.OpenAPIMode, serviceAPI.Spec, p.openAPIMaxBodySize)
+		if handlerErr != nil {
+			log.Errorf("failed to handle OpenAPI mode of service '%s': %s", apiIdentifier.Service, handlerErr.Error())
+			return nil, apperrors.Internal("failed to handle OpenAPI mode of service '%s': %s", apiIdentifier.Service, handlerErr.Error())
+		}
+	}
+
 	clientCertificate := clientcert.NewClientCertificate(nil)
 	authorizationStrategy := p.newAuthorizationStrategy(serviceAPI.Credentials)
 	csrfTokenStrategy := p.newCSRFTokenStrategy(authorizationStrategy, serviceAPI.Credentials)
-	proxy, err := makeProxy(serviceAPI.TargetUrl, serviceAPI.RequestParameters, apiIdentifier.Service, p.skipVerify, authorizationStrategy, csrfTokenStrategy, clientCertificate, p.proxyTimeout)
+	proxy, err := makeProxy(serviceAPI.TargetUrl, serviceAPI.RequestParameters, apiIdentifier.Service, p.skipVerify, authorizationStrategy, csrfTokenStrategy, clientCertificate, p.proxyTimeout, resilienceTarget)
 	if err != nil {
 		return nil, err
 	}
 
-	return p.cache.Put(apiIdentifier.Application, apiIdentifier.Service, apiIdentifier.Entry, proxy, authorizationStrategy, csrfTokenStrategy, clientCertificate), nil
+	return p.cache.Put(apiIdentifier.Application, apiIdentifier.Service, apiIdentifier.Entry, proxy, authorizationStrategy, csrfTokenStrategy, clientCertificate, openAPIHandler), nil
+}
+
+// serveFromSpecification rejects the request if it does not match the specification of the API and answers it in the
+// sandbox mode, it returns true if the request must not be sent to the target API
+func serveFromSpecification(w http.ResponseWriter, r *http.Request, openAPIHandler *openapi.Handler) (bool, apperrors.AppError) {
+	if err := openAPIHandler.ValidateRequest(r); err != nil {
+		return false, err
+	}
+
+	if !openAPIHandler.Sandbox() {
+		return false, nil
+	}
+
+	if err := openAPIHandler.WriteExampleResponse(w, r); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (p *proxy) newAuthorizationStrategy(credentials *authorization.Credentials) authorization.Strategy {
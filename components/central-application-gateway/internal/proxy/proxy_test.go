@@ -3,9 +3,11 @@ package proxy
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
@@ -13,6 +15,7 @@ import (
 	csrfMock "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/mocks"
 	metadatamodel "github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
 	proxyMocks "github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	authMock "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
@@ -417,6 +420,213 @@ func TestProxy(t *testing.T) {
 	})
 }
 
+func TestProxyResilience(t *testing.T) {
+	apiIdentifier := metadatamodel.APIIdentifier{
+		Application: "app",
+		Service:     "service",
+		Entry:       "entry",
+	}
+	fakePathExtractor := func(path string) (metadatamodel.APIIdentifier, string, apperrors.AppError) {
+		return apiIdentifier, path, nil
+	}
+
+	newAuthStrategyMocks := func() (*authMock.StrategyFactory, *authMock.Strategy) {
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.AnythingOfType("*http.Request"), mock.AnythingOfType("SetClientCertificateFunc")).
+			Return(nil)
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+		return authStrategyFactoryMock, authStrategyMock
+	}
+
+	t.Run("should retry and then reject calls with Service Unavailable when the circuit breaker is open", func(t *testing.T) {
+		// given
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		authStrategyFactoryMock, authStrategyMock := newAuthStrategyMocks()
+		csrfFactoryMock, _ := mockCSRFStrategy(authStrategyMock, func(*mock.Call) {})
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:        ts.URL,
+			ResiliencePolicy: []byte(`{"retry": {"maxRetries": 1}, "circuitBreaker": {"failureThreshold": 2, "openDuration": "1h"}}`),
+		}, nil).Once()
+
+		proxyConfig := createProxyConfig(10)
+		proxyConfig.ResiliencePolicy = resilience.Policy{Retry: resilience.RetryPolicy{MaxRetries: 5}}
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfFactoryMock, fakePathExtractor, proxyConfig)
+
+		// when
+		firstResponse := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		handler.ServeHTTP(firstResponse, req)
+
+		secondResponse := httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/orders/123", nil)
+		handler.ServeHTTP(secondResponse, req)
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, firstResponse.Code)
+		assert.Equal(t, http.StatusServiceUnavailable, secondResponse.Code)
+		assert.Contains(t, secondResponse.Body.String(), resilience.ErrCircuitOpen.Error())
+		assert.Equal(t, 2, calls)
+		apiExtractorMock.AssertExpectations(t)
+	})
+
+	t.Run("should fail with Internal Server Error when resilience policy is invalid", func(t *testing.T) {
+		// given
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:        "http://localhost",
+			ResiliencePolicy: []byte(`{"retry": {"maxRetries": -1}}`),
+		}, nil).Once()
+
+		handler := newProxyForTest(apiExtractorMock, &authMock.StrategyFactory{}, &csrfMock.TokenStrategyFactory{}, fakePathExtractor, createProxyConfig(10))
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/orders/123", nil)
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		apiExtractorMock.AssertExpectations(t)
+	})
+}
+
+func TestProxyOpenAPI(t *testing.T) {
+	apiIdentifier := metadatamodel.APIIdentifier{
+		Application: "app",
+		Service:     "service",
+		Entry:       "entry",
+	}
+	fakePathExtractor := func(path string) (metadatamodel.APIIdentifier, string, apperrors.AppError) {
+		return apiIdentifier, path, nil
+	}
+	spec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Orders", "version": "1.0"},
+		"paths": {
+			"/orders": {
+				"post": {
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {
+						"type": "object", "required": ["quantity"], "properties": {"quantity": {"type": "integer"}}
+					}}}},
+					"responses": {"201": {"description": "created", "content": {"application/json": {"example": {"id": "order-1"}}}}}
+				}
+			}
+		}
+	}`)
+
+	newProxyForSpec := func(targetURL, mode string, spec []byte) http.Handler {
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.AnythingOfType("*http.Request"), mock.AnythingOfType("SetClientCertificateFunc")).
+			Return(nil)
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+		csrfFactoryMock, _ := mockCSRFStrategy(authStrategyMock, func(*mock.Call) {})
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:   targetURL,
+			Spec:        spec,
+			OpenAPIMode: mode,
+		}, nil)
+
+		return newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfFactoryMock, fakePathExtractor, createProxyConfig(10))
+	}
+
+	t.Run("should pass valid calls to target API and reject invalid calls with Bad Request", func(t *testing.T) {
+		// given
+		calls := 0
+		ts := NewTestServer(func(req *http.Request) {
+			calls++
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"quantity": 1}`, string(body))
+		})
+		defer ts.Close()
+
+		handler := newProxyForSpec(ts.URL, "validation", spec)
+
+		// when
+		validResponse := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{"quantity": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(validResponse, req)
+
+		invalidResponse := httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{"quantity": "one"}`))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(invalidResponse, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, validResponse.Code)
+		assert.Equal(t, http.StatusBadRequest, invalidResponse.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should pass calls with bodies larger than maximum size to target API without validating them", func(t *testing.T) {
+		// given
+		largeBody := `{"quantity": "one", "note": "` + strings.Repeat("a", 2<<10) + `"}`
+		ts := NewTestServer(func(req *http.Request) {
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, largeBody, string(body))
+		})
+		defer ts.Close()
+
+		handler := newProxyForSpec(ts.URL, "validation", spec)
+
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(largeBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should answer calls with examples of specification in sandbox mode", func(t *testing.T) {
+		// given
+		handler := newProxyForSpec("http://unreachable.local", "sandbox", spec)
+
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{"quantity": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"id": "order-1"}`, rr.Body.String())
+	})
+
+	t.Run("should fail with Internal Server Error when specification is invalid", func(t *testing.T) {
+		// given
+		handler := newProxyForSpec("http://localhost", "validation", []byte("invalid"))
+
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
 func assertCookie(t *testing.T, r *http.Request, name, value string) {
 	cookie, err := r.Cookie(name)
 	require.NoError(t, err)
@@ -448,6 +658,9 @@ func newProxyForTest(
 		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
 		extractPathFunc:              pathExtractorFunc,
 		apiExtractor:                 apiExtractor,
+		resiliencePolicy:             proxyConfig.ResiliencePolicy,
+		resilienceRegistry:           newResilienceRegistry(proxyConfig),
+		openAPIMaxBodySize:           proxyConfig.OpenAPIMaxBodySize,
 	}
 }
 
@@ -473,6 +686,8 @@ func createProxyConfig(proxyTimeout int) Config {
 		ProxyTimeout:  proxyTimeout,
 		Application:   "test",
 		ProxyCacheTTL: proxyTimeout,
+		// bodies up to 1KiB are validated against the specifications of the APIs
+		OpenAPIMaxBodySize: 1 << 10,
 	}
 }
 
@@ -65,10 +65,28 @@ func (p *RetryableRoundTripper) retry(req *http.Request, retryBody io.ReadCloser
 
 func (p *RetryableRoundTripper) prepareRequest(req *http.Request) (*http.Request, context.CancelFunc) {
 	req.RequestURI = ""
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(valuesContext{req.Context()}, time.Duration(p.timeout)*time.Second)
 	return req.WithContext(ctx), cancel
 }
 
+// valuesContext keeps the values of the context of the original request, e.g. the token of the user the authorization
+// strategy propagates, without its deadline and cancellation
+type valuesContext struct {
+	context.Context
+}
+
+func (valuesContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (valuesContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (valuesContext) Err() error {
+	return nil
+}
+
 func (p *RetryableRoundTripper) addAuthorization(r *http.Request) error {
 	authorizationStrategy := p.authorizationStrategy
 	authorizationStrategy.Invalidate()
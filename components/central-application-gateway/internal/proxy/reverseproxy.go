@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/resilience"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
@@ -15,10 +16,11 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func makeProxy(targetURL string, requestParameters *authorization.RequestParameters, serviceName string, skipVerify bool, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate, timeout int) (*httputil.ReverseProxy, apperrors.AppError) {
+func makeProxy(targetURL string, requestParameters *authorization.RequestParameters, serviceName string, skipVerify bool, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate, timeout int, resilienceTarget *resilience.Target) (*httputil.ReverseProxy, apperrors.AppError) {
 	roundTripper := httptools.NewRoundTripper(httptools.WithTLSSkipVerify(skipVerify), httptools.WithGetClientCertificate(clientCertificate.GetClientCertificate))
 	retryableRoundTripper := NewRetryableRoundTripper(roundTripper, authorizationStrategy, csrfTokenStrategy, clientCertificate, timeout)
-	return newProxy(targetURL, requestParameters, serviceName, retryableRoundTripper)
+	resilientRoundTripper := resilience.NewRoundTripper(retryableRoundTripper, resilienceTarget)
+	return newProxy(targetURL, requestParameters, serviceName, resilientRoundTripper)
 }
 
 func newProxy(targetURL string, requestParameters *authorization.RequestParameters, serviceName string, transport http.RoundTripper) (*httputil.ReverseProxy, apperrors.AppError) {
@@ -55,7 +57,19 @@ func newProxy(targetURL string, requestParameters *authorization.RequestParamete
 
 		log.Infof("Modified request url : '%s', schema : '%s', path : '%s'", req.URL.String(), req.URL.Scheme, req.URL.Path)
 	}
-	return &httputil.ReverseProxy{Director: director, Transport: transport}, nil
+	return &httputil.ReverseProxy{Director: director, Transport: transport, ErrorHandler: handleProxyError}, nil
+}
+
+// handleProxyError responds with 503 to requests rejected by the resilience policy and with 502 to other failed calls
+func handleProxyError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == resilience.ErrCircuitOpen || err == resilience.ErrBulkheadFull {
+		log.Warnf("Request to '%s' rejected: %s", r.URL.String(), err.Error())
+		handleErrors(w, apperrors.ServiceUnavailable("%s", err.Error()))
+		return
+	}
+
+	log.Errorf("Proxy call to '%s' failed: %s", r.URL.String(), err.Error())
+	w.WriteHeader(http.StatusBadGateway)
 }
 
 func joinPaths(a, b string) string {
@@ -0,0 +1,35 @@
+package resilience
+
+import "errors"
+
+// ErrBulkheadFull is returned for requests rejected because the maximum number of concurrent requests is reached
+var ErrBulkheadFull = errors.New("maximum number of concurrent requests reached")
+
+// Bulkhead limits the number of concurrent requests to a target API, so that a slow API cannot take up all connections
+// and goroutines of the gateway
+type Bulkhead struct {
+	slots chan struct{}
+}
+
+// NewBulkhead creates a bulkhead, a bulkhead without a maximum number of concurrent requests lets all requests through
+func NewBulkhead(policy BulkheadPolicy) *Bulkhead {
+	if policy.MaxConcurrentRequests == 0 {
+		return &Bulkhead{}
+	}
+	return &Bulkhead{slots: make(chan struct{}, policy.MaxConcurrentRequests)}
+}
+
+// Acquire returns ErrBulkheadFull if the maximum number of concurrent requests is reached.
+// Otherwise the returned function must be called when the request is done.
+func (b *Bulkhead) Acquire() (func(), error) {
+	if b.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return func() { <-b.slots }, nil
+	default:
+		return nil, ErrBulkheadFull
+	}
+}
@@ -0,0 +1,159 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed lets all requests through
+	StateClosed State = iota
+	// StateHalfOpen lets a limited number of probe requests through
+	StateHalfOpen
+	// StateOpen rejects all requests
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// Outcome is the outcome of a request let through by a circuit breaker
+type Outcome int
+
+const (
+	Success Outcome = iota
+	Failure
+	// Canceled is the outcome of requests canceled by the caller, which do not count as a success or a failure
+	Canceled
+)
+
+// ErrCircuitOpen is returned for requests rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker counts the consecutive failures of the calls to a target API
+type CircuitBreaker struct {
+	mutex  sync.Mutex
+	policy CircuitBreakerPolicy
+	now    func() time.Time
+	// onStateChange is called with the new state, with the mutex held
+	onStateChange func(State)
+
+	state State
+	// generation changes with every state change, so that outcomes of requests let through in a previous state are ignored
+	generation int
+	failures   int
+	openedAt   time.Time
+	// probes is the number of probe requests let through in the half-open state
+	probes int
+	// probeSuccesses is the number of probe requests which succeeded in the half-open state
+	probeSuccesses int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker, a breaker with a failure threshold of 0 never opens
+func NewCircuitBreaker(policy CircuitBreakerPolicy) *CircuitBreaker {
+	if policy.HalfOpenRequests == 0 {
+		policy.HalfOpenRequests = 1
+	}
+	return &CircuitBreaker{
+		policy:        policy,
+		now:           time.Now,
+		onStateChange: func(State) {},
+	}
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() State {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.halfOpenIfExpired()
+	return cb.state
+}
+
+// Allow returns ErrCircuitOpen if the request must be rejected. Otherwise the outcome of the request
+// must be reported with the returned function.
+func (cb *CircuitBreaker) Allow() (func(Outcome), error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.halfOpenIfExpired()
+	switch cb.state {
+	case StateOpen:
+		return nil, ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.probes >= cb.policy.HalfOpenRequests {
+			return nil, ErrCircuitOpen
+		}
+		cb.probes++
+	}
+
+	generation := cb.generation
+	return func(outcome Outcome) {
+		cb.record(generation, outcome)
+	}, nil
+}
+
+func (cb *CircuitBreaker) record(generation int, outcome Outcome) {
+	if cb.policy.FailureThreshold == 0 {
+		return
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+	switch {
+	case outcome == Canceled && cb.state == StateHalfOpen:
+		// another probe request is let through instead
+		cb.probes--
+	case outcome == Canceled:
+	case cb.state == StateClosed && outcome == Success:
+		cb.failures = 0
+	case cb.state == StateClosed:
+		cb.failures++
+		if cb.failures >= cb.policy.FailureThreshold {
+			cb.open()
+		}
+	case cb.state == StateHalfOpen && outcome == Failure:
+		cb.open()
+	case cb.state == StateHalfOpen:
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.policy.HalfOpenRequests {
+			cb.setState(StateClosed)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) open() {
+	cb.openedAt = cb.now()
+	cb.setState(StateOpen)
+}
+
+func (cb *CircuitBreaker) halfOpenIfExpired() {
+	if cb.state == StateOpen && !cb.now().Before(cb.openedAt.Add(time.Duration(cb.policy.OpenDuration))) {
+		cb.setState(StateHalfOpen)
+	}
+}
+
+func (cb *CircuitBreaker) setState(state State) {
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.probes = 0
+	cb.probeSuccesses = 0
+	cb.onStateChange(state)
+}
@@ -0,0 +1,129 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	newBreaker := func(now *time.Time) *CircuitBreaker {
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 2, OpenDuration: Duration(time.Minute), HalfOpenRequests: 2})
+		breaker.now = func() time.Time { return *now }
+		return breaker
+	}
+	call := func(t *testing.T, breaker *CircuitBreaker, outcome Outcome) {
+		done, err := breaker.Allow()
+		require.NoError(t, err)
+		done(outcome)
+	}
+
+	t.Run("should open after consecutive failures", func(t *testing.T) {
+		// given
+		now := time.Now()
+		breaker := newBreaker(&now)
+
+		// when
+		call(t, breaker, Failure)
+		call(t, breaker, Success)
+		call(t, breaker, Failure)
+		assert.Equal(t, StateClosed, breaker.State())
+		call(t, breaker, Failure)
+
+		// then
+		assert.Equal(t, StateOpen, breaker.State())
+		_, err := breaker.Allow()
+		assert.Equal(t, ErrCircuitOpen, err)
+	})
+
+	t.Run("should let a limited number of probes through when half-open and close when they succeed", func(t *testing.T) {
+		// given
+		now := time.Now()
+		breaker := newBreaker(&now)
+		call(t, breaker, Failure)
+		call(t, breaker, Failure)
+
+		// when
+		now = now.Add(time.Minute)
+
+		// then
+		assert.Equal(t, StateHalfOpen, breaker.State())
+		firstProbe, err := breaker.Allow()
+		require.NoError(t, err)
+		secondProbe, err := breaker.Allow()
+		require.NoError(t, err)
+		_, err = breaker.Allow()
+		assert.Equal(t, ErrCircuitOpen, err)
+
+		firstProbe(Success)
+		assert.Equal(t, StateHalfOpen, breaker.State())
+		secondProbe(Success)
+		assert.Equal(t, StateClosed, breaker.State())
+	})
+
+	t.Run("should open again when a probe fails", func(t *testing.T) {
+		// given
+		now := time.Now()
+		breaker := newBreaker(&now)
+		call(t, breaker, Failure)
+		call(t, breaker, Failure)
+		now = now.Add(time.Minute)
+
+		// when
+		call(t, breaker, Failure)
+
+		// then
+		assert.Equal(t, StateOpen, breaker.State())
+	})
+
+	t.Run("should let another probe through when a probe is canceled", func(t *testing.T) {
+		// given
+		now := time.Now()
+		breaker := newBreaker(&now)
+		call(t, breaker, Failure)
+		call(t, breaker, Failure)
+		now = now.Add(time.Minute)
+
+		// when
+		call(t, breaker, Canceled)
+		call(t, breaker, Canceled)
+
+		// then
+		call(t, breaker, Success)
+		call(t, breaker, Success)
+		assert.Equal(t, StateClosed, breaker.State())
+	})
+
+	t.Run("should ignore outcomes of requests let through before the state changed", func(t *testing.T) {
+		// given
+		now := time.Now()
+		breaker := newBreaker(&now)
+		slowRequest, err := breaker.Allow()
+		require.NoError(t, err)
+		call(t, breaker, Failure)
+		call(t, breaker, Failure)
+		now = now.Add(time.Minute)
+		require.Equal(t, StateHalfOpen, breaker.State())
+
+		// when
+		slowRequest(Failure)
+
+		// then
+		assert.Equal(t, StateHalfOpen, breaker.State())
+	})
+
+	t.Run("should never open without failure threshold", func(t *testing.T) {
+		// given
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{})
+
+		// when
+		for i := 0; i < 100; i++ {
+			call(t, breaker, Failure)
+		}
+
+		// then
+		assert.Equal(t, StateClosed, breaker.State())
+	})
+}
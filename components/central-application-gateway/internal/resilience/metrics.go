@@ -0,0 +1,29 @@
+package resilience
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	rejectionCircuitOpen = "circuit_open"
+	rejectionBulkhead    = "bulkhead_full"
+)
+
+var (
+	circuitBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "central_application_gateway_circuit_breaker_state",
+		Help: "State of the circuit breaker of a target API: 0 closed, 1 half-open, 2 open",
+	}, []string{"application", "service", "entry"})
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "central_application_gateway_retries_total",
+		Help: "Number of retried calls to target APIs",
+	}, []string{"application", "service", "entry"})
+	rejectedRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "central_application_gateway_rejected_requests_total",
+		Help: "Number of requests rejected without calling the target API, by an open circuit breaker or a full bulkhead",
+	}, []string{"application", "service", "entry", "reason"})
+)
+
+func init() {
+	prometheus.MustRegister(circuitBreakerState, retriesTotal, rejectedRequestsTotal)
+}
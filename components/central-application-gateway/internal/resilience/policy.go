@@ -0,0 +1,105 @@
+// Package resilience contains the policies the Application Gateway applies to the calls to the target APIs:
+// retries with backoff, circuit breakers and bulkheads
+package resilience
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Policy is the resilience policy of a target API
+type Policy struct {
+	Retry          RetryPolicy          `json:"retry"`
+	CircuitBreaker CircuitBreakerPolicy `json:"circuitBreaker"`
+	Bulkhead       BulkheadPolicy       `json:"bulkhead"`
+}
+
+// RetryPolicy configures the retries of idempotent requests which failed with a connection error, a timeout
+// or a 502, 503 or 504 status. The backoff before a retry is a random duration up to the exponentially growing backoff.
+// All attempts together are limited by the proxy timeout.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retries
+	MaxRetries     int      `json:"maxRetries"`
+	InitialBackoff Duration `json:"initialBackoff"`
+	MaxBackoff     Duration `json:"maxBackoff"`
+	// AttemptTimeout is the timeout of a single attempt of a retried request, 0 means no timeout besides the proxy timeout
+	AttemptTimeout Duration `json:"attemptTimeout"`
+	// MaxBodySize is the maximum size in bytes of a request body buffered to replay it, requests with larger bodies are not retried
+	MaxBodySize int64 `json:"maxBodySize"`
+}
+
+// CircuitBreakerPolicy configures the circuit breaker of a target API. The breaker opens after the given number
+// of consecutive failures and rejects requests for the open duration. Then it lets the given number of probe requests
+// through and closes if all of them succeed.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures opening the breaker, 0 disables the breaker
+	FailureThreshold int      `json:"failureThreshold"`
+	OpenDuration     Duration `json:"openDuration"`
+	HalfOpenRequests int      `json:"halfOpenRequests"`
+}
+
+// BulkheadPolicy limits the number of concurrent requests to a target API
+type BulkheadPolicy struct {
+	// MaxConcurrentRequests is the maximum number of requests in progress, 0 means no limit
+	MaxConcurrentRequests int `json:"maxConcurrentRequests"`
+}
+
+// Override returns the policy with the fields set in the given JSON document replaced
+func (p Policy) Override(document []byte) (Policy, error) {
+	if len(document) == 0 {
+		return p, nil
+	}
+
+	if err := json.Unmarshal(document, &p); err != nil {
+		return Policy{}, fmt.Errorf("failed to decode resilience policy: %s", err)
+	}
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return p, nil
+}
+
+// Validate checks that the policy has no negative values
+func (p Policy) Validate() error {
+	switch {
+	case p.Retry.MaxRetries < 0:
+		return fmt.Errorf("retry.maxRetries must not be negative")
+	case p.Retry.InitialBackoff < 0 || p.Retry.MaxBackoff < 0:
+		return fmt.Errorf("retry backoff must not be negative")
+	case p.Retry.AttemptTimeout < 0:
+		return fmt.Errorf("retry.attemptTimeout must not be negative")
+	case p.Retry.MaxBodySize < 0:
+		return fmt.Errorf("retry.maxBodySize must not be negative")
+	case p.CircuitBreaker.FailureThreshold < 0:
+		return fmt.Errorf("circuitBreaker.failureThreshold must not be negative")
+	case p.CircuitBreaker.OpenDuration < 0:
+		return fmt.Errorf("circuitBreaker.openDuration must not be negative")
+	case p.CircuitBreaker.HalfOpenRequests < 0:
+		return fmt.Errorf("circuitBreaker.halfOpenRequests must not be negative")
+	case p.Bulkhead.MaxConcurrentRequests < 0:
+		return fmt.Errorf("bulkhead.maxConcurrentRequests must not be negative")
+	}
+	return nil
+}
+
+// Duration is a time.Duration written in JSON as a string, e.g. "100ms" or "30s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string, e.g. \"100ms\"")
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
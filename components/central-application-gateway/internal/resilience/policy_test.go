@@ -0,0 +1,53 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Override(t *testing.T) {
+	defaultPolicy := Policy{
+		Retry:          RetryPolicy{MaxRetries: 2, InitialBackoff: Duration(100 * time.Millisecond), MaxBackoff: Duration(2 * time.Second)},
+		CircuitBreaker: CircuitBreakerPolicy{FailureThreshold: 10, OpenDuration: Duration(30 * time.Second), HalfOpenRequests: 1},
+	}
+
+	t.Run("should return the default policy without overrides", func(t *testing.T) {
+		// when
+		policy, err := defaultPolicy.Override(nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, defaultPolicy, policy)
+	})
+
+	t.Run("should override the fields set in the document", func(t *testing.T) {
+		// given
+		document := []byte(`{"retry": {"maxRetries": 0}, "circuitBreaker": {"openDuration": "1m"}, "bulkhead": {"maxConcurrentRequests": 5}}`)
+
+		// when
+		policy, err := defaultPolicy.Override(document)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Policy{
+			Retry:          RetryPolicy{MaxRetries: 0, InitialBackoff: Duration(100 * time.Millisecond), MaxBackoff: Duration(2 * time.Second)},
+			CircuitBreaker: CircuitBreakerPolicy{FailureThreshold: 10, OpenDuration: Duration(time.Minute), HalfOpenRequests: 1},
+			Bulkhead:       BulkheadPolicy{MaxConcurrentRequests: 5},
+		}, policy)
+	})
+
+	t.Run("should return error for invalid documents", func(t *testing.T) {
+		for _, document := range []string{
+			`{"retry": {"maxRetries": "many"}}`,
+			`{"retry": {"initialBackoff": 100}}`,
+			`{"circuitBreaker": {"openDuration": "soon"}}`,
+			`{"bulkhead": {"maxConcurrentRequests": -1}}`,
+		} {
+			_, err := defaultPolicy.Override([]byte(document))
+			assert.Error(t, err, document)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package resilience
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+// Target holds the resilience policy of a target API along with its circuit breaker and bulkhead
+type Target struct {
+	id       model.APIIdentifier
+	policy   Policy
+	breaker  *CircuitBreaker
+	bulkhead *Bulkhead
+}
+
+func newTarget(id model.APIIdentifier, policy Policy) *Target {
+	breaker := NewCircuitBreaker(policy.CircuitBreaker)
+	stateGauge := circuitBreakerState.WithLabelValues(id.Application, id.Service, id.Entry)
+	breaker.onStateChange = func(state State) {
+		stateGauge.Set(float64(state))
+	}
+	stateGauge.Set(float64(StateClosed))
+
+	return &Target{
+		id:       id,
+		policy:   policy,
+		breaker:  breaker,
+		bulkhead: NewBulkhead(policy.Bulkhead),
+	}
+}
+
+// CircuitBreakerStatus is the state of the circuit breaker of a target API
+type CircuitBreakerStatus struct {
+	Application string `json:"application"`
+	Service     string `json:"service"`
+	Entry       string `json:"entry,omitempty"`
+	State       string `json:"state"`
+}
+
+// Registry keeps the circuit breakers and bulkheads of the target APIs. They outlive the cached proxies,
+// so that the state of a breaker is not lost when the proxy of its API is recreated.
+type Registry struct {
+	mutex   sync.Mutex
+	targets map[model.APIIdentifier]*Target
+}
+
+func NewRegistry() *Registry {
+	return &Registry{targets: map[model.APIIdentifier]*Target{}}
+}
+
+// Target returns the target of the API. If the policy of the API changed, the target is replaced
+// by one with a closed circuit breaker.
+func (r *Registry) Target(id model.APIIdentifier, policy Policy) *Target {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	target, found := r.targets[id]
+	if found && target.policy == policy {
+		return target
+	}
+
+	target = newTarget(id, policy)
+	r.targets[id] = target
+	return target
+}
+
+// CircuitBreakers returns the states of the circuit breakers of the target APIs, sorted by application, service and entry
+func (r *Registry) CircuitBreakers() []CircuitBreakerStatus {
+	r.mutex.Lock()
+	targets := make([]*Target, 0, len(r.targets))
+	for _, target := range r.targets {
+		if target.policy.CircuitBreaker.FailureThreshold > 0 {
+			targets = append(targets, target)
+		}
+	}
+	r.mutex.Unlock()
+
+	statuses := make([]CircuitBreakerStatus, 0, len(targets))
+	for _, target := range targets {
+		statuses = append(statuses, CircuitBreakerStatus{
+			Application: target.id.Application,
+			Service:     target.id.Service,
+			Entry:       target.id.Entry,
+			State:       target.breaker.State().String(),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Application != b.Application {
+			return a.Application < b.Application
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Entry < b.Entry
+	})
+	return statuses
+}
@@ -0,0 +1,242 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxDiscardedBodySize is the maximum size of the body of a failed response read before retrying,
+// reading the body allows reusing the connection
+const maxDiscardedBodySize = 4096
+
+type roundTripper struct {
+	roundTripper http.RoundTripper
+	target       *Target
+}
+
+// NewRoundTripper applies the bulkhead, the circuit breaker and the retry policy of the target to the requests
+func NewRoundTripper(rt http.RoundTripper, target *Target) http.RoundTripper {
+	return &roundTripper{
+		roundTripper: rt,
+		target:       target,
+	}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := rt.target.id
+
+	release, err := rt.target.bulkhead.Acquire()
+	if err != nil {
+		rejectedRequestsTotal.WithLabelValues(id.Application, id.Service, id.Entry, rejectionBulkhead).Inc()
+		return nil, err
+	}
+
+	resp, cancel, err := rt.roundTripWithRetries(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return releaseOnClose(resp, func() {
+		cancel()
+		release()
+	}), nil
+}
+
+func (rt *roundTripper) roundTripWithRetries(req *http.Request) (*http.Response, context.CancelFunc, error) {
+	id := rt.target.id
+	policy := rt.target.policy.Retry
+
+	maxRetries := policy.MaxRetries
+	if !isIdempotent(req.Method) {
+		maxRetries = 0
+	}
+	getBody, replayable, err := replayableBody(req, maxRetries, policy.MaxBodySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !replayable {
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		done, err := rt.target.breaker.Allow()
+		if err != nil {
+			rejectedRequestsTotal.WithLabelValues(id.Application, id.Service, id.Entry, rejectionCircuitOpen).Inc()
+			return nil, nil, err
+		}
+
+		attemptReq, cancel := newAttempt(req, getBody, policy.AttemptTimeout)
+		resp, err := rt.roundTripper.RoundTrip(attemptReq)
+
+		outcome := outcomeOf(req, resp, err)
+		done(outcome)
+		if outcome != Failure || attempt >= maxRetries || req.Context().Err() != nil {
+			if err != nil {
+				cancel()
+				return nil, nil, err
+			}
+			return resp, cancel, nil
+		}
+
+		log.Warnf("Call %d to service '%s' failed, retrying: %s", attempt+1, id.Service, failureReason(resp, err))
+		discard(resp)
+		cancel()
+		if err := sleep(req.Context(), backoff(policy, attempt)); err != nil {
+			return nil, nil, err
+		}
+		retriesTotal.WithLabelValues(id.Application, id.Service, id.Entry).Inc()
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// replayableBody reads the body of a request which may be retried, so that it can be sent again. Bodies larger than
+// maxBodySize are not buffered and false is returned, the request is then sent once with its body streamed.
+func replayableBody(req *http.Request, maxRetries int, maxBodySize int64) (func() io.ReadCloser, bool, error) {
+	if maxRetries == 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil, true, nil
+	}
+	if req.ContentLength > maxBodySize {
+		return nil, false, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > maxBodySize {
+		// the body of unknown length is too large, the part read already is sent before the rest
+		req.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+		return nil, false, nil
+	}
+	req.Body.Close()
+	return func() io.ReadCloser {
+		return ioutil.NopCloser(bytes.NewReader(body))
+	}, true, nil
+}
+
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func newAttempt(req *http.Request, getBody func() io.ReadCloser, attemptTimeout Duration) (*http.Request, context.CancelFunc) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if attemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(attemptTimeout))
+	}
+
+	attemptReq := req.WithContext(ctx)
+	if getBody != nil {
+		attemptReq.Body = getBody()
+	}
+	return attemptReq, cancel
+}
+
+// outcomeOf classifies connection errors, timeouts and 502, 503 and 504 responses as failures
+func outcomeOf(req *http.Request, resp *http.Response, err error) Outcome {
+	if err != nil {
+		if req.Context().Err() == context.Canceled {
+			return Canceled
+		}
+		return Failure
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Failure
+	default:
+		return Success
+	}
+}
+
+func failureReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+func discard(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxDiscardedBodySize))
+	resp.Body.Close()
+}
+
+// backoff returns a random duration up to the initial backoff doubled with every attempt and limited by the maximum backoff
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	maxBackoff := time.Duration(policy.MaxBackoff)
+	if maxBackoff == 0 {
+		maxBackoff = math.MaxInt64
+	}
+
+	limit := time.Duration(policy.InitialBackoff)
+	for i := 0; i < attempt && limit < maxBackoff; i++ {
+		if limit > maxBackoff/2 {
+			limit = maxBackoff
+		} else {
+			limit *= 2
+		}
+	}
+	if limit > maxBackoff {
+		limit = maxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// releaseOnClose calls release when the body of the response is closed. Bodies of upgraded connections
+// are not wrapped, as the reverse proxy needs to write to them.
+func releaseOnClose(resp *http.Response, release func()) *http.Response {
+	if resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+		release()
+		return resp
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,273 @@
+package resilience
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripper(t *testing.T) {
+	retryPolicy := RetryPolicy{MaxRetries: 2, InitialBackoff: Duration(time.Millisecond), MaxBackoff: Duration(5 * time.Millisecond), MaxBodySize: 8}
+
+	// newServer responds with the given statuses in order and with 200 afterwards
+	newServer := func(statuses ...int) (*httptest.Server, *[]string) {
+		var mutex sync.Mutex
+		var bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			bodies = append(bodies, string(body))
+			if len(bodies) <= len(statuses) {
+				w.WriteHeader(statuses[len(bodies)-1])
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		return server, &bodies
+	}
+	newRoundTripper := func(policy Policy) http.RoundTripper {
+		target := newTarget(model.APIIdentifier{Application: "app", Service: "service"}, policy)
+		return NewRoundTripper(http.DefaultTransport, target)
+	}
+	roundTrip := func(t *testing.T, rt http.RoundTripper, method, url, body string) (*http.Response, error) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	t.Run("should retry idempotent requests and replay the body", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusServiceUnavailable, http.StatusBadGateway)
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodPut, server.URL, "body")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"body", "body", "body"}, *bodies)
+	})
+
+	t.Run("should not retry requests with bodies larger than the maximum body size", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusServiceUnavailable)
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodPut, server.URL, "large body")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, []string{"large body"}, *bodies)
+	})
+
+	t.Run("should send the whole body of unknown length larger than the maximum body size once", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusServiceUnavailable)
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		req, err := http.NewRequest(http.MethodPut, server.URL, ioutil.NopCloser(strings.NewReader("large body")))
+		require.NoError(t, err)
+		require.Equal(t, int64(0), req.ContentLength)
+
+		// when
+		resp, err := rt.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, []string{"large body"}, *bodies)
+	})
+
+	t.Run("should return the last response when retries are exhausted", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout)
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodGet, server.URL, "")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+		assert.Len(t, *bodies, 3)
+	})
+
+	t.Run("should not retry requests which are not idempotent", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusServiceUnavailable)
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodPost, server.URL, "body")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, *bodies, 1)
+	})
+
+	t.Run("should not retry other errors", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusInternalServerError)
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodGet, server.URL, "")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Len(t, *bodies, 1)
+	})
+
+	t.Run("should retry closed connections", func(t *testing.T) {
+		// given
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				conn, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+				conn.Close()
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		rt := newRoundTripper(Policy{Retry: retryPolicy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodGet, server.URL, "")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should retry attempts which timed out", func(t *testing.T) {
+		// given
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				<-r.Context().Done()
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		policy := retryPolicy
+		policy.AttemptTimeout = Duration(50 * time.Millisecond)
+		rt := newRoundTripper(Policy{Retry: policy})
+
+		// when
+		resp, err := roundTrip(t, rt, http.MethodGet, server.URL, "")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should stop retrying when the request context is done", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		defer server.Close()
+		policy := retryPolicy
+		policy.InitialBackoff = Duration(time.Hour)
+		policy.MaxBackoff = Duration(time.Hour)
+		rt := newRoundTripper(Policy{Retry: policy})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		// when
+		_, err = rt.RoundTrip(req)
+
+		// then
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Len(t, *bodies, 1)
+	})
+
+	t.Run("should reject requests when the circuit breaker is open", func(t *testing.T) {
+		// given
+		server, bodies := newServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		defer server.Close()
+		rt := newRoundTripper(Policy{
+			Retry:          RetryPolicy{MaxRetries: 5},
+			CircuitBreaker: CircuitBreakerPolicy{FailureThreshold: 2, OpenDuration: Duration(time.Hour)},
+		})
+
+		// when
+		_, err := roundTrip(t, rt, http.MethodGet, server.URL, "")
+		assert.Equal(t, ErrCircuitOpen, err)
+		_, err = roundTrip(t, rt, http.MethodGet, server.URL, "")
+
+		// then
+		assert.Equal(t, ErrCircuitOpen, err)
+		assert.Len(t, *bodies, 2)
+	})
+
+	t.Run("should reject requests when the bulkhead is full until the response body is closed", func(t *testing.T) {
+		// given
+		server, _ := newServer()
+		defer server.Close()
+		rt := newRoundTripper(Policy{Bulkhead: BulkheadPolicy{MaxConcurrentRequests: 1}})
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+
+		// when
+		_, err = roundTrip(t, rt, http.MethodGet, server.URL, "")
+		assert.Equal(t, ErrBulkheadFull, err)
+		resp.Body.Close()
+		_, err = roundTrip(t, rt, http.MethodGet, server.URL, "")
+
+		// then
+		assert.NoError(t, err)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: Duration(100 * time.Millisecond), MaxBackoff: Duration(time.Second)}
+
+	for attempt, limit := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for i := 0; i < 20; i++ {
+			duration := backoff(policy, attempt)
+			assert.True(t, duration >= 0 && duration <= limit, "backoff %s of attempt %d exceeds %s", duration, attempt, limit)
+		}
+	}
+	assert.Equal(t, time.Duration(0), backoff(RetryPolicy{}, 3))
+	assert.True(t, backoff(RetryPolicy{InitialBackoff: Duration(time.Second), MaxBackoff: Duration(time.Minute)}, 100) <= time.Minute)
+	assert.True(t, backoff(RetryPolicy{InitialBackoff: Duration(time.Second)}, 100) >= 0)
+}
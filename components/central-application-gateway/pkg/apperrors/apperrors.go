@@ -8,6 +8,8 @@ const (
 	CodeAlreadyExists            = 3
 	CodeWrongInput               = 4
 	CodeUpstreamServerCallFailed = 5
+	CodeServiceUnavailable       = 6
+	CodeUnauthorized             = 7
 )
 
 type AppError interface {
@@ -44,6 +46,14 @@ func UpstreamServerCallFailed(format string, a ...interface{}) AppError {
 	return errorf(CodeUpstreamServerCallFailed, format, a...)
 }
 
+func ServiceUnavailable(format string, a ...interface{}) AppError {
+	return errorf(CodeServiceUnavailable, format, a...)
+}
+
+func Unauthorized(format string, a ...interface{}) AppError {
+	return errorf(CodeUnauthorized, format, a...)
+}
+
 func (ae appError) Code() int {
 	return ae.code
 }
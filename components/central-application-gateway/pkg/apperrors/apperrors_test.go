@@ -14,6 +14,8 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, CodeAlreadyExists, AlreadyExists("error").Code())
 		assert.Equal(t, CodeWrongInput, WrongInput("error").Code())
 		assert.Equal(t, CodeUpstreamServerCallFailed, UpstreamServerCallFailed("error").Code())
+		assert.Equal(t, CodeServiceUnavailable, ServiceUnavailable("error").Code())
+		assert.Equal(t, CodeUnauthorized, Unauthorized("error").Code())
 	})
 
 	t.Run("should create error with simple message", func(t *testing.T) {
@@ -22,6 +24,8 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, "error", AlreadyExists("error").Error())
 		assert.Equal(t, "error", WrongInput("error").Error())
 		assert.Equal(t, "error", UpstreamServerCallFailed("error").Error())
+		assert.Equal(t, "error", ServiceUnavailable("error").Error())
+		assert.Equal(t, "error", Unauthorized("error").Error())
 	})
 
 	t.Run("should create error with formatted message", func(t *testing.T) {
@@ -30,5 +34,7 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, "code: 1, error: bug", AlreadyExists("code: %d, error: %s", 1, "bug").Error())
 		assert.Equal(t, "code: 1, error: bug", WrongInput("code: %d, error: %s", 1, "bug").Error())
 		assert.Equal(t, "code: 1, error: bug", UpstreamServerCallFailed("code: %d, error: %s", 1, "bug").Error())
+		assert.Equal(t, "code: 1, error: bug", ServiceUnavailable("code: %d, error: %s", 1, "bug").Error())
+		assert.Equal(t, "code: 1, error: bug", Unauthorized("code: %d, error: %s", 1, "bug").Error())
 	})
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/tokencache"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/principal"
 )
 
 //go:generate mockery --name=Strategy
@@ -27,12 +28,15 @@ type StrategyFactory interface {
 type OAuthClient interface {
 	// GetToken obtains OAuth token
 	GetToken(clientID string, clientSecret string, authURL string, headers, queryParameters *map[string][]string) (string, apperrors.AppError)
+	// GetTokenForFlow obtains OAuth token with the given flow
+	GetTokenForFlow(flow oauth.Flow) (string, apperrors.AppError)
 	// InvalidateTokenCache resets internal token cache
 	InvalidateTokenCache(clientID string)
 }
 
 type authorizationStrategyFactory struct {
 	oauthClient OAuthClient
+	keySets     *principal.KeySets
 }
 
 // Create creates strategy for credentials provided
@@ -41,6 +45,14 @@ func (asf authorizationStrategyFactory) Create(c *Credentials) Strategy {
 
 	if c != nil && c.OAuth != nil {
 		strategy = newOAuthStrategy(asf.oauthClient, c.OAuth.ClientID, c.OAuth.ClientSecret, c.OAuth.URL, c.OAuth.RequestParameters)
+	} else if c != nil && c.OAuthPrivateKeyJWT != nil {
+		strategy = newPrivateKeyJWTStrategy(asf.oauthClient, *c.OAuthPrivateKeyJWT)
+	} else if c != nil && c.OAuthMTLS != nil {
+		strategy = newOAuthMTLSStrategy(asf.oauthClient, *c.OAuthMTLS)
+	} else if c != nil && c.OAuthJWTBearer != nil {
+		strategy = newJWTBearerStrategy(asf.oauthClient, *c.OAuthJWTBearer)
+	} else if c != nil && c.OAuthSAMLBearer != nil {
+		strategy = newSAMLBearerStrategy(asf.oauthClient, *c.OAuthSAMLBearer)
 	} else if c != nil && c.BasicAuth != nil {
 		strategy = newBasicAuthStrategy(c.BasicAuth.Username, c.BasicAuth.Password)
 	} else if c != nil && c.CertificateGen != nil {
@@ -49,6 +61,10 @@ func (asf authorizationStrategyFactory) Create(c *Credentials) Strategy {
 		strategy = newNoAuthStrategy()
 	}
 
+	if c != nil && c.PrincipalPropagation != nil {
+		strategy = newPrincipalPropagationStrategy(asf.oauthClient, asf.keySets, *c.PrincipalPropagation, strategy)
+	}
+
 	return newExternalTokenStrategy(strategy)
 }
 
@@ -62,5 +78,7 @@ func NewStrategyFactory(config FactoryConfiguration) StrategyFactory {
 	cache := tokencache.NewTokenCache()
 	oauthClient := oauth.NewOauthClient(config.OAuthClientTimeout, cache)
 
-	return authorizationStrategyFactory{oauthClient: oauthClient}
+	keySets := principal.NewKeySets(config.OAuthClientTimeout)
+
+	return authorizationStrategyFactory{oauthClient: oauthClient, keySets: keySets}
 }
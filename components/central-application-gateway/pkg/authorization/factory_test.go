@@ -7,6 +7,7 @@ import (
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 	oauthMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 	"github.com/stretchr/testify/assert"
@@ -145,6 +146,63 @@ func TestStrategyFactory(t *testing.T) {
 		assert.Equal(t, "Bearer external", authHeader)
 	})
 
+	t.Run("should create strategies for OAuth flows", func(t *testing.T) {
+		testCases := []struct {
+			description  string
+			credentials  *Credentials
+			expectedFlow oauth.Flow
+		}{
+			{
+				description:  "private key JWT",
+				credentials:  &Credentials{OAuthPrivateKeyJWT: &OAuthPrivateKeyJWT{ClientID: "clientId", URL: "www.example.com/token", PrivateKey: privateKey}},
+				expectedFlow: oauth.PrivateKeyJWTFlow{ClientID: "clientId", URL: "www.example.com/token", PrivateKey: privateKey},
+			},
+			{
+				description:  "mTLS",
+				credentials:  &Credentials{OAuthMTLS: &OAuthMTLS{ClientID: "clientId", URL: "www.example.com/token", Certificate: certificate, PrivateKey: privateKey}},
+				expectedFlow: oauth.MTLSFlow{ClientID: "clientId", URL: "www.example.com/token", Certificate: certificate, PrivateKey: privateKey},
+			},
+			{
+				description:  "JWT bearer",
+				credentials:  &Credentials{OAuthJWTBearer: &OAuthJWTBearer{ClientID: "clientId", URL: "www.example.com/token", PrivateKey: privateKey, Subject: "user"}},
+				expectedFlow: oauth.JWTBearerFlow{ClientID: "clientId", URL: "www.example.com/token", PrivateKey: privateKey, Subject: "user"},
+			},
+			{
+				description:  "SAML bearer",
+				credentials:  &Credentials{OAuthSAMLBearer: &OAuthSAMLBearer{ClientID: "clientId", URL: "www.example.com/token", Certificate: certificate, PrivateKey: privateKey, Subject: "user"}},
+				expectedFlow: oauth.SAMLBearerFlow{ClientID: "clientId", URL: "www.example.com/token", Certificate: certificate, PrivateKey: privateKey, Subject: "user"},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				// given
+				oauthClientMock := &oauthMocks.Client{}
+				oauthClientMock.On("GetTokenForFlow", testCase.expectedFlow).Return("token", nil)
+
+				factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+
+				// when
+				strategy := factory.Create(testCase.credentials)
+
+				// then
+				require.NotNil(t, strategy)
+
+				// given
+				request, err := http.NewRequest("GET", "www.example.com", nil)
+				require.NoError(t, err)
+
+				// when
+				err = strategy.AddAuthorization(request, nil)
+
+				// then
+				assert.Nil(t, err)
+				assert.Equal(t, "Bearer token", request.Header.Get(httpconsts.HeaderAuthorization))
+				oauthClientMock.AssertExpectations(t)
+			})
+		}
+	})
+
 	t.Run("should create certificate gen strategy", func(t *testing.T) {
 		// given
 		oauthClientMock := &oauthMocks.Client{}
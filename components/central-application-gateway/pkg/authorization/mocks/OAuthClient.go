@@ -6,6 +6,8 @@ import (
 	apperrors "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 
 	mock "github.com/stretchr/testify/mock"
+
+	oauth "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 )
 
 // OAuthClient is an autogenerated mock type for the OAuthClient type
@@ -36,6 +38,29 @@ func (_m *OAuthClient) GetToken(clientID string, clientSecret string, authURL st
 	return r0, r1
 }
 
+// GetTokenForFlow provides a mock function with given fields: flow
+func (_m *OAuthClient) GetTokenForFlow(flow oauth.Flow) (string, apperrors.AppError) {
+	ret := _m.Called(flow)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(oauth.Flow) string); ok {
+		r0 = rf(flow)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(oauth.Flow) apperrors.AppError); ok {
+		r1 = rf(flow)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // InvalidateTokenCache provides a mock function with given fields: clientID
 func (_m *OAuthClient) InvalidateTokenCache(clientID string) {
 	_m.Called(clientID)
@@ -8,6 +8,16 @@ type Credentials struct {
 	BasicAuth *BasicAuth
 	// CertificateGen is CertificateGen configuration.
 	CertificateGen *CertificateGen
+	// OAuthPrivateKeyJWT is OAuth configuration with the client authenticated with a signed JWT.
+	OAuthPrivateKeyJWT *OAuthPrivateKeyJWT
+	// OAuthMTLS is OAuth configuration with the client authenticated with a client certificate.
+	OAuthMTLS *OAuthMTLS
+	// OAuthJWTBearer is OAuth JWT bearer grant configuration.
+	OAuthJWTBearer *OAuthJWTBearer
+	// OAuthSAMLBearer is OAuth SAML 2.0 bearer grant configuration.
+	OAuthSAMLBearer *OAuthSAMLBearer
+	// PrincipalPropagation (optional) is configuration of propagating the identity of the calling user.
+	PrincipalPropagation *PrincipalPropagation
 	// CSRFTokenEndpointURL (optional) to fetch CSRF token
 	// Deprecated: This field is only used for old implementation of fetching credentials from Application and Secrets. It is not used by authorization package.
 	// It should be removed when it is no longer supported
@@ -34,6 +44,140 @@ type OAuth struct {
 	RequestParameters *RequestParameters
 }
 
+// OAuthPrivateKeyJWT contains details of OAuth client credentials configuration with the client authenticated
+// with a JWT signed with its private key (private_key_jwt)
+type OAuthPrivateKeyJWT struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// PrivateKey in PEM format signing the client assertion
+	PrivateKey []byte
+	// KeyID (optional) identifying the key in the kid header of the client assertion
+	KeyID string
+	// Scope (optional) of the requested token
+	Scope string
+	// RequestParameters will be used with request send by the Application Gateway.
+	RequestParameters *RequestParameters
+}
+
+// OAuthMTLS contains details of OAuth client credentials configuration with the client authenticated
+// with a client certificate (tls_client_auth)
+type OAuthMTLS struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// Certificate of the client, e.g. generated by Application Registry
+	Certificate []byte
+	// PrivateKey of the client, e.g. generated by Application Registry
+	PrivateKey []byte
+	// Scope (optional) of the requested token
+	Scope string
+	// RequestParameters will be used with request send by the Application Gateway.
+	RequestParameters *RequestParameters
+}
+
+// OAuthJWTBearer contains details of OAuth JWT bearer grant configuration (RFC 7523)
+type OAuthJWTBearer struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// ClientSecret (optional) to use for authorization.
+	ClientSecret string
+	// PrivateKey in PEM format signing the assertion
+	PrivateKey []byte
+	// KeyID (optional) identifying the key in the kid header of the assertion
+	KeyID string
+	// Issuer (optional) of the assertion, ClientID by default
+	Issuer string
+	// Subject of the assertion
+	Subject string
+	// Audience (optional) of the assertion, URL by default
+	Audience string
+	// Scope (optional) of the requested token
+	Scope string
+	// RequestParameters will be used with request send by the Application Gateway.
+	RequestParameters *RequestParameters
+}
+
+// OAuthSAMLBearer contains details of OAuth SAML 2.0 bearer grant configuration (RFC 7522)
+type OAuthSAMLBearer struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// ClientSecret (optional) to use for authorization.
+	ClientSecret string
+	// Certificate signing the assertion
+	Certificate []byte
+	// PrivateKey signing the assertion, it must be an RSA key
+	PrivateKey []byte
+	// Issuer (optional) of the assertion, ClientID by default
+	Issuer string
+	// Subject is the name ID of the user in the assertion
+	Subject string
+	// Audience (optional) of the assertion, URL by default
+	Audience string
+	// Scope (optional) of the requested token
+	Scope string
+	// RequestParameters will be used with request send by the Application Gateway.
+	RequestParameters *RequestParameters
+}
+
+// PrincipalPropagation contains details of propagating the identity of the user calling the Application Gateway
+// to the target API. Exactly one of TokenExchange and SignedHeaders must be set.
+type PrincipalPropagation struct {
+	// TokenHeader is the header holding the JWT of the user, Authorization by default
+	TokenHeader string
+	// TokenExchange exchanges the JWT of the user for a token of the target API, replacing the other credentials
+	TokenExchange *TokenExchange
+	// SignedHeaders passes claims of the JWT of the user in headers, in addition to the other credentials
+	SignedHeaders *SignedHeaders
+}
+
+// TokenExchange contains details of OAuth token exchange configuration (RFC 8693)
+type TokenExchange struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// ClientSecret (optional) to use for authorization.
+	ClientSecret string
+	// SubjectTokenType (optional) is the type of the JWT of the user, urn:ietf:params:oauth:token-type:jwt by default
+	SubjectTokenType string
+	// RequestedTokenType (optional) is the type of the requested token
+	RequestedTokenType string
+	// Audience (optional) of the requested token
+	Audience string
+	// Resource (optional) the requested token is used for
+	Resource string
+	// Scope (optional) of the requested token
+	Scope string
+	// RequestParameters will be used with request send by the Application Gateway.
+	RequestParameters *RequestParameters
+}
+
+// SignedHeaders contains details of passing claims of the JWT of the user in headers. The Application Gateway
+// verifies the JWT of the user and signs the passed claims with its key in a JWT sent in the assertion header.
+type SignedHeaders struct {
+	// JWKSURL is the URL of the key set the JWT of the user is verified with
+	JWKSURL string
+	// Issuer (optional) expected in the JWT of the user
+	Issuer string
+	// Audience (optional) expected in the JWT of the user
+	Audience string
+	// Claims maps the names of the passed claims to the names of the headers they are passed in
+	Claims map[string]string
+	// AssertionHeader is the header holding the JWT with the passed claims, X-Principal-Assertion by default
+	AssertionHeader string
+	// PrivateKey in PEM format signing the JWT with the passed claims
+	PrivateKey []byte
+	// KeyID (optional) identifying the key in the kid header of the JWT with the passed claims
+	KeyID string
+}
+
 // CertificateGen details of CertificateGen configuration
 type CertificateGen struct {
 	// CommonName of the certificate
@@ -0,0 +1,188 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	samlAssertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlNameIDUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	samlBearerConfirmation = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	samlAuthnContextClass  = "urn:oasis:names:tc:SAML:2.0:ac:classes:PreviousSession"
+)
+
+func newJWTClaims(issuer, subject, audience string) jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:    issuer,
+		Subject:   subject,
+		Audience:  jwt.Audience{audience},
+		ID:        randomID(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Expiry:    jwt.NewNumericDate(now.Add(assertionLifetime)),
+	}
+}
+
+// SignJWT signs the claims with an RSA or an EC private key in PEM format, claims are structs or maps
+// which are merged into the claims set of the JWT
+func SignJWT(privateKey []byte, keyID string, claims ...interface{}) (string, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	algorithm, err := signatureAlgorithm(key)
+	if err != nil {
+		return "", err
+	}
+
+	options := (&jose.SignerOptions{}).WithType("JWT")
+	if keyID != "" {
+		options = options.WithHeader("kid", keyID)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, options)
+	if err != nil {
+		return "", err
+	}
+
+	builder := jwt.Signed(signer)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+	return builder.CompactSerialize()
+}
+
+func parsePrivateKey(privateKey []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("private key is not in PEM format")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %s", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
+
+func signatureAlgorithm(key crypto.Signer) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+	}
+	return "", errors.New("private key must be an RSA or an EC key on the P-256, P-384 or P-521 curve")
+}
+
+type samlAssertionParameters struct {
+	issuer    string
+	subject   string
+	audience  string
+	recipient string
+}
+
+// newSAMLAssertion creates a SAML 2.0 bearer assertion with an enveloped signature made with the RSA key pair in PEM format
+func newSAMLAssertion(certificate, privateKey []byte, parameters samlAssertionParameters) ([]byte, error) {
+	keyPair, err := tls.X509KeyPair(certificate, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key pair: %s", err)
+	}
+	rsaKey, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("SAML assertions can only be signed with an RSA key")
+	}
+
+	now := time.Now().UTC()
+	notOnOrAfter := now.Add(assertionLifetime).Format(time.RFC3339)
+
+	assertion := etree.NewElement("saml2:Assertion")
+	assertion.CreateAttr("xmlns:saml2", samlAssertionNamespace)
+	assertion.CreateAttr("ID", "_"+randomID())
+	assertion.CreateAttr("IssueInstant", now.Format(time.RFC3339))
+	assertion.CreateAttr("Version", "2.0")
+
+	assertion.CreateElement("saml2:Issuer").SetText(parameters.issuer)
+
+	subject := assertion.CreateElement("saml2:Subject")
+	nameID := subject.CreateElement("saml2:NameID")
+	nameID.CreateAttr("Format", samlNameIDUnspecified)
+	nameID.SetText(parameters.subject)
+	confirmation := subject.CreateElement("saml2:SubjectConfirmation")
+	confirmation.CreateAttr("Method", samlBearerConfirmation)
+	confirmationData := confirmation.CreateElement("saml2:SubjectConfirmationData")
+	confirmationData.CreateAttr("NotOnOrAfter", notOnOrAfter)
+	confirmationData.CreateAttr("Recipient", parameters.recipient)
+
+	conditions := assertion.CreateElement("saml2:Conditions")
+	conditions.CreateAttr("NotBefore", now.Format(time.RFC3339))
+	conditions.CreateAttr("NotOnOrAfter", notOnOrAfter)
+	conditions.CreateElement("saml2:AudienceRestriction").CreateElement("saml2:Audience").SetText(parameters.audience)
+
+	authnStatement := assertion.CreateElement("saml2:AuthnStatement")
+	authnStatement.CreateAttr("AuthnInstant", now.Format(time.RFC3339))
+	authnStatement.CreateElement("saml2:AuthnContext").CreateElement("saml2:AuthnContextClassRef").SetText(samlAuthnContextClass)
+
+	signingContext := dsig.NewDefaultSigningContext(keyStore{key: rsaKey, certificate: keyPair.Certificate[0]})
+	signingContext.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signed, err := signingContext.SignEnveloped(assertion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign SAML assertion: %s", err)
+	}
+
+	// the schema requires the signature right after the issuer, moving it does not change the digest of the enveloped signature
+	signature := signed.RemoveChildAt(len(signed.Child) - 1)
+	signed.InsertChildAt(1, signature)
+
+	document := etree.NewDocument()
+	document.SetRoot(signed)
+	return document.WriteToBytes()
+}
+
+type keyStore struct {
+	key         *rsa.PrivateKey
+	certificate []byte
+}
+
+func (ks keyStore) GetKeyPair() (*rsa.PrivateKey, []byte, error) {
+	return ks.key, ks.certificate, nil
+}
+
+func randomID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
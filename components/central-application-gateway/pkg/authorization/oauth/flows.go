@@ -0,0 +1,329 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+const (
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeJWTBearer         = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	grantTypeSAML2Bearer       = "urn:ietf:params:oauth:grant-type:saml2-bearer"
+	grantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	// TokenTypeJWT is the type of the subject tokens exchanged by default
+	TokenTypeJWT = "urn:ietf:params:oauth:token-type:jwt"
+
+	clientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// assertionLifetime is the validity of the JWT and SAML assertions sent to the token endpoint
+	assertionLifetime = 5 * time.Minute
+)
+
+// TokenRequest holds the parameters of a request to the token endpoint of an OAuth server
+type TokenRequest struct {
+	URL string
+	// Form holds the grant and the client authentication parameters sent in the body
+	Form url.Values
+	// BasicAuth authenticates the client with ClientID and ClientSecret in the Authorization header
+	BasicAuth    bool
+	ClientID     string
+	ClientSecret string
+	// Certificate (optional) authenticates the client with mutual TLS
+	Certificate     *tls.Certificate
+	Headers         *map[string][]string
+	QueryParameters *map[string][]string
+	// MaxExpiresIn (optional) limits the time in seconds the token is cached, e.g. to the lifetime of an exchanged token
+	MaxExpiresIn int
+}
+
+// Flow is an OAuth flow obtaining access tokens from a token endpoint
+type Flow interface {
+	// CacheKey identifies the tokens obtained with the flow in the token cache
+	CacheKey() string
+	// TokenRequest creates the request for a new token
+	TokenRequest() (*TokenRequest, apperrors.AppError)
+}
+
+// PrivateKeyJWTFlow is the client credentials grant with the client authenticated with a JWT signed
+// with its private key (private_key_jwt)
+type PrivateKeyJWTFlow struct {
+	URL      string
+	ClientID string
+	// PrivateKey in PEM format signing the client assertion
+	PrivateKey []byte
+	// KeyID (optional) is sent in the kid header of the client assertion
+	KeyID           string
+	Scope           string
+	Headers         *map[string][]string
+	QueryParameters *map[string][]string
+}
+
+func (f PrivateKeyJWTFlow) CacheKey() string {
+	return cacheKey("private_key_jwt", f.URL, f.ClientID, f.KeyID, f.Scope, f.Headers, f.QueryParameters)
+}
+
+func (f PrivateKeyJWTFlow) TokenRequest() (*TokenRequest, apperrors.AppError) {
+	assertion, err := SignJWT(f.PrivateKey, f.KeyID, newJWTClaims(f.ClientID, f.ClientID, f.URL))
+	if err != nil {
+		return nil, apperrors.Internal("failed to create client assertion: %s", err.Error())
+	}
+
+	form := url.Values{}
+	form.Add("grant_type", grantTypeClientCredentials)
+	form.Add("client_id", f.ClientID)
+	form.Add("client_assertion_type", clientAssertionTypeJWTBearer)
+	form.Add("client_assertion", assertion)
+	addScope(form, f.Scope)
+
+	return &TokenRequest{
+		URL:             f.URL,
+		Form:            form,
+		Headers:         f.Headers,
+		QueryParameters: f.QueryParameters,
+	}, nil
+}
+
+// MTLSFlow is the client credentials grant with the client authenticated with a client certificate (tls_client_auth)
+type MTLSFlow struct {
+	URL      string
+	ClientID string
+	// Certificate and PrivateKey in PEM format
+	Certificate     []byte
+	PrivateKey      []byte
+	Scope           string
+	Headers         *map[string][]string
+	QueryParameters *map[string][]string
+}
+
+func (f MTLSFlow) CacheKey() string {
+	return cacheKey("tls_client_auth", f.URL, f.ClientID, fingerprint(f.Certificate), f.Scope, f.Headers, f.QueryParameters)
+}
+
+func (f MTLSFlow) TokenRequest() (*TokenRequest, apperrors.AppError) {
+	certificate, err := tls.X509KeyPair(f.Certificate, f.PrivateKey)
+	if err != nil {
+		return nil, apperrors.Internal("failed to prepare client certificate: %s", err.Error())
+	}
+
+	form := url.Values{}
+	form.Add("grant_type", grantTypeClientCredentials)
+	form.Add("client_id", f.ClientID)
+	addScope(form, f.Scope)
+
+	return &TokenRequest{
+		URL:             f.URL,
+		Form:            form,
+		Certificate:     &certificate,
+		Headers:         f.Headers,
+		QueryParameters: f.QueryParameters,
+	}, nil
+}
+
+// JWTBearerFlow is the JWT bearer grant (RFC 7523) with a JWT assertion signed with the private key of the client
+type JWTBearerFlow struct {
+	URL      string
+	ClientID string
+	// ClientSecret (optional) authenticates the client with basic authentication
+	ClientSecret string
+	// PrivateKey in PEM format signing the assertion
+	PrivateKey []byte
+	// KeyID (optional) is sent in the kid header of the assertion
+	KeyID string
+	// Issuer of the assertion, ClientID by default
+	Issuer string
+	// Subject of the assertion, the user or the client the token is requested for
+	Subject string
+	// Audience of the assertion, URL by default
+	Audience        string
+	Scope           string
+	Headers         *map[string][]string
+	QueryParameters *map[string][]string
+}
+
+func (f JWTBearerFlow) CacheKey() string {
+	return cacheKey(grantTypeJWTBearer, f.URL, f.ClientID, f.KeyID, f.Issuer, f.Subject, f.Audience, f.Scope, f.Headers, f.QueryParameters)
+}
+
+func (f JWTBearerFlow) TokenRequest() (*TokenRequest, apperrors.AppError) {
+	claims := newJWTClaims(valueOrDefault(f.Issuer, f.ClientID), f.Subject, valueOrDefault(f.Audience, f.URL))
+	assertion, err := SignJWT(f.PrivateKey, f.KeyID, claims)
+	if err != nil {
+		return nil, apperrors.Internal("failed to create JWT assertion: %s", err.Error())
+	}
+
+	form := url.Values{}
+	form.Add("grant_type", grantTypeJWTBearer)
+	form.Add("assertion", assertion)
+	form.Add("client_id", f.ClientID)
+	addScope(form, f.Scope)
+
+	return &TokenRequest{
+		URL:             f.URL,
+		Form:            form,
+		BasicAuth:       f.ClientSecret != "",
+		ClientID:        f.ClientID,
+		ClientSecret:    f.ClientSecret,
+		Headers:         f.Headers,
+		QueryParameters: f.QueryParameters,
+	}, nil
+}
+
+// SAMLBearerFlow is the SAML 2.0 bearer grant (RFC 7522) with a SAML assertion signed with the key pair of the client
+type SAMLBearerFlow struct {
+	URL      string
+	ClientID string
+	// ClientSecret (optional) authenticates the client with basic authentication
+	ClientSecret string
+	// Certificate and PrivateKey in PEM format signing the assertion, the key must be an RSA key
+	Certificate []byte
+	PrivateKey  []byte
+	// Issuer of the assertion, ClientID by default
+	Issuer string
+	// Subject is the name ID of the user the token is requested for
+	Subject string
+	// Audience of the assertion, URL by default
+	Audience        string
+	Scope           string
+	Headers         *map[string][]string
+	QueryParameters *map[string][]string
+}
+
+func (f SAMLBearerFlow) CacheKey() string {
+	return cacheKey(grantTypeSAML2Bearer, f.URL, f.ClientID, fingerprint(f.Certificate), f.Issuer, f.Subject, f.Audience, f.Scope, f.Headers, f.QueryParameters)
+}
+
+func (f SAMLBearerFlow) TokenRequest() (*TokenRequest, apperrors.AppError) {
+	assertion, err := newSAMLAssertion(f.Certificate, f.PrivateKey, samlAssertionParameters{
+		issuer:    valueOrDefault(f.Issuer, f.ClientID),
+		subject:   f.Subject,
+		audience:  valueOrDefault(f.Audience, f.URL),
+		recipient: f.URL,
+	})
+	if err != nil {
+		return nil, apperrors.Internal("failed to create SAML assertion: %s", err.Error())
+	}
+
+	form := url.Values{}
+	form.Add("grant_type", grantTypeSAML2Bearer)
+	form.Add("assertion", base64.RawURLEncoding.EncodeToString(assertion))
+	form.Add("client_id", f.ClientID)
+	addScope(form, f.Scope)
+
+	return &TokenRequest{
+		URL:             f.URL,
+		Form:            form,
+		BasicAuth:       f.ClientSecret != "",
+		ClientID:        f.ClientID,
+		ClientSecret:    f.ClientSecret,
+		Headers:         f.Headers,
+		QueryParameters: f.QueryParameters,
+	}, nil
+}
+
+// TokenExchangeFlow is the token exchange grant (RFC 8693) exchanging the token of a user for a token of the target API
+type TokenExchangeFlow struct {
+	URL      string
+	ClientID string
+	// ClientSecret (optional) authenticates the client with basic authentication
+	ClientSecret string
+	// SubjectToken is the token of the user
+	SubjectToken string
+	// SubjectTokenType is the type of the token of the user, TokenTypeJWT by default
+	SubjectTokenType string
+	// SubjectTokenExpiry (optional) limits the time the exchanged token is cached
+	SubjectTokenExpiry time.Time
+	// RequestedTokenType (optional) is the type of the requested token
+	RequestedTokenType string
+	Audience           string
+	Resource           string
+	Scope              string
+	Headers            *map[string][]string
+	QueryParameters    *map[string][]string
+}
+
+// CacheKey identifies the tokens of a user by the hash of the subject token
+func (f TokenExchangeFlow) CacheKey() string {
+	return cacheKey(grantTypeTokenExchange, f.URL, f.ClientID, fingerprint([]byte(f.SubjectToken)), f.SubjectTokenType, f.RequestedTokenType,
+		f.Audience, f.Resource, f.Scope, f.Headers, f.QueryParameters)
+}
+
+func (f TokenExchangeFlow) TokenRequest() (*TokenRequest, apperrors.AppError) {
+	maxExpiresIn := 0
+	if !f.SubjectTokenExpiry.IsZero() {
+		maxExpiresIn = int(time.Until(f.SubjectTokenExpiry).Seconds())
+		if maxExpiresIn <= 0 {
+			return nil, apperrors.Unauthorized("token of the user expired")
+		}
+	}
+
+	form := url.Values{}
+	form.Add("grant_type", grantTypeTokenExchange)
+	form.Add("subject_token", f.SubjectToken)
+	form.Add("subject_token_type", valueOrDefault(f.SubjectTokenType, TokenTypeJWT))
+	if f.RequestedTokenType != "" {
+		form.Add("requested_token_type", f.RequestedTokenType)
+	}
+	if f.Audience != "" {
+		form.Add("audience", f.Audience)
+	}
+	if f.Resource != "" {
+		form.Add("resource", f.Resource)
+	}
+	form.Add("client_id", f.ClientID)
+	addScope(form, f.Scope)
+
+	return &TokenRequest{
+		URL:             f.URL,
+		Form:            form,
+		BasicAuth:       f.ClientSecret != "",
+		ClientID:        f.ClientID,
+		ClientSecret:    f.ClientSecret,
+		Headers:         f.Headers,
+		QueryParameters: f.QueryParameters,
+		MaxExpiresIn:    maxExpiresIn,
+	}, nil
+}
+
+// cacheKey identifies tokens by the flow and all its parameters, so that flows of the same client
+// do not share tokens
+func cacheKey(flow string, parameters ...interface{}) string {
+	values := make([]string, 0, len(parameters))
+	for _, parameter := range parameters {
+		switch p := parameter.(type) {
+		case *map[string][]string:
+			if p == nil {
+				values = append(values, "")
+			} else {
+				values = append(values, fmt.Sprint(*p))
+			}
+		default:
+			values = append(values, fmt.Sprint(p))
+		}
+	}
+	return fmt.Sprintf("%s:%x", flow, sha256.Sum256([]byte(strings.Join(values, "\n"))))
+}
+
+func fingerprint(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func addScope(form url.Values, scope string) {
+	if scope != "" {
+		form.Add("scope", scope)
+	}
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,419 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/tokencache/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
+)
+
+func TestOauthClient_GetTokenForFlow(t *testing.T) {
+	t.Run("should get token from cache if present", func(t *testing.T) {
+		// given
+		flow := PrivateKeyJWTFlow{URL: "http://token.com", ClientID: "testID"}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("123456789", true)
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fetch token with private key JWT client authentication", func(t *testing.T) {
+		// given
+		ts := newTokenServer(t, func(r *http.Request) {
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "testID", r.PostForm.Get("client_id"))
+			assert.Equal(t, "read write", r.PostForm.Get("scope"))
+			assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.PostForm.Get("client_assertion_type"))
+			assert.Empty(t, r.Header.Get("Authorization"))
+
+			claims := verifyJWT(t, r.PostForm.Get("client_assertion"), "key-1")
+			assert.Equal(t, "testID", claims.Issuer)
+			assert.Equal(t, "testID", claims.Subject)
+			assert.Equal(t, jwt.Audience{"http://" + r.Host + "/token"}, claims.Audience)
+			assert.NotEmpty(t, claims.ID)
+		})
+		defer ts.Close()
+
+		flow := PrivateKeyJWTFlow{
+			URL:        ts.URL + "/token",
+			ClientID:   "testID",
+			PrivateKey: []byte(testconsts.PrivateKey),
+			KeyID:      "key-1",
+			Scope:      "read write",
+		}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+		tokenCache.On("Add", flow.CacheKey(), "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fetch token with mTLS client authentication", func(t *testing.T) {
+		// given
+		ts := httptest.NewUnstartedServer(tokenHandler(t, func(r *http.Request) {
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "testID", r.PostForm.Get("client_id"))
+			require.Len(t, r.TLS.PeerCertificates, 1)
+			assert.Equal(t, "test", r.TLS.PeerCertificates[0].Subject.CommonName)
+		}))
+		ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		ts.StartTLS()
+		defer ts.Close()
+
+		flow := MTLSFlow{
+			URL:         ts.URL,
+			ClientID:    "testID",
+			Certificate: []byte(testconsts.Certificate),
+			PrivateKey:  []byte(testconsts.PrivateKey),
+		}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+		tokenCache.On("Add", flow.CacheKey(), "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fetch token with JWT bearer grant", func(t *testing.T) {
+		// given
+		ts := newTokenServer(t, func(r *http.Request) {
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "testID", r.PostForm.Get("client_id"))
+			checkBasicAuth(t, r, "testID", "testSecret")
+
+			claims := verifyJWT(t, r.PostForm.Get("assertion"), "")
+			assert.Equal(t, "issuer", claims.Issuer)
+			assert.Equal(t, "user@example.com", claims.Subject)
+			assert.Equal(t, jwt.Audience{"audience"}, claims.Audience)
+		})
+		defer ts.Close()
+
+		flow := JWTBearerFlow{
+			URL:          ts.URL,
+			ClientID:     "testID",
+			ClientSecret: "testSecret",
+			PrivateKey:   []byte(testconsts.PrivateKey),
+			Issuer:       "issuer",
+			Subject:      "user@example.com",
+			Audience:     "audience",
+		}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+		tokenCache.On("Add", flow.CacheKey(), "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fetch token with SAML bearer grant", func(t *testing.T) {
+		// given
+		ts := newTokenServer(t, func(r *http.Request) {
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:saml2-bearer", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "testID", r.PostForm.Get("client_id"))
+			assert.Empty(t, r.Header.Get("Authorization"))
+
+			assertion := verifySAMLAssertion(t, r.PostForm.Get("assertion"))
+			assert.Equal(t, "testID", assertion.FindElement("./Issuer").Text())
+			assert.Equal(t, "user@example.com", assertion.FindElement("./Subject/NameID").Text())
+			assert.Equal(t, "http://"+r.Host, assertion.FindElement("./Conditions/AudienceRestriction/Audience").Text())
+			assert.Equal(t, "http://"+r.Host, assertion.FindElement("./Subject/SubjectConfirmation/SubjectConfirmationData").SelectAttrValue("Recipient", ""))
+		})
+		defer ts.Close()
+
+		flow := SAMLBearerFlow{
+			URL:         ts.URL,
+			ClientID:    "testID",
+			Certificate: []byte(testconsts.Certificate),
+			PrivateKey:  []byte(testconsts.PrivateKey),
+			Subject:     "user@example.com",
+		}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+		tokenCache.On("Add", flow.CacheKey(), "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should exchange token and cache it no longer than the subject token is valid", func(t *testing.T) {
+		// given
+		ts := newTokenServer(t, func(r *http.Request) {
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "user-token", r.PostForm.Get("subject_token"))
+			assert.Equal(t, "urn:ietf:params:oauth:token-type:jwt", r.PostForm.Get("subject_token_type"))
+			assert.Equal(t, "backend", r.PostForm.Get("audience"))
+			assert.Empty(t, r.PostForm.Get("resource"))
+			checkBasicAuth(t, r, "testID", "testSecret")
+		})
+		defer ts.Close()
+
+		flow := TokenExchangeFlow{
+			URL:                ts.URL,
+			ClientID:           "testID",
+			ClientSecret:       "testSecret",
+			SubjectToken:       "user-token",
+			SubjectTokenExpiry: time.Now().Add(10 * time.Minute),
+			Audience:           "backend",
+		}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+		tokenCache.On("Add", flow.CacheKey(), "123456789", mock.MatchedBy(func(expiresIn int) bool {
+			return expiresIn > 590 && expiresIn <= 600
+		})).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should not exchange expired token", func(t *testing.T) {
+		// given
+		flow := TokenExchangeFlow{URL: "http://token.com", ClientID: "testID", SubjectToken: "user-token", SubjectTokenExpiry: time.Now().Add(-time.Minute)}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		_, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeUnauthorized, err.Code())
+	})
+
+	t.Run("should fail without requesting token when assertion cannot be signed", func(t *testing.T) {
+		// given
+		flow := JWTBearerFlow{URL: "http://token.com", ClientID: "testID", PrivateKey: []byte("invalid")}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, "", token)
+		tokenCache.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should fail when unable to get token", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		flow := PrivateKeyJWTFlow{URL: ts.URL, ClientID: "testID", PrivateKey: []byte(testconsts.PrivateKey)}
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", flow.CacheKey()).Return("", false)
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenForFlow(flow)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, "", token)
+		tokenCache.AssertExpectations(t)
+	})
+}
+
+func TestFlow_CacheKey(t *testing.T) {
+	t.Run("should differ for flows of the same client", func(t *testing.T) {
+		// given
+		privateKeyJWT := PrivateKeyJWTFlow{URL: "http://token.com", ClientID: "testID"}
+		mtls := MTLSFlow{URL: "http://token.com", ClientID: "testID"}
+
+		// then
+		assert.NotEqual(t, privateKeyJWT.CacheKey(), mtls.CacheKey())
+		assert.NotEqual(t, "testID", privateKeyJWT.CacheKey())
+	})
+
+	t.Run("should differ for tokens of users", func(t *testing.T) {
+		// given
+		flow := TokenExchangeFlow{URL: "http://token.com", ClientID: "testID", SubjectToken: "token1"}
+		otherUser := TokenExchangeFlow{URL: "http://token.com", ClientID: "testID", SubjectToken: "token2"}
+
+		// then
+		assert.NotEqual(t, flow.CacheKey(), otherUser.CacheKey())
+		assert.NotContains(t, flow.CacheKey(), "token1")
+	})
+
+	t.Run("should differ for subjects and request parameters", func(t *testing.T) {
+		// given
+		headers := map[string][]string{"header": {"value"}}
+		flow := JWTBearerFlow{URL: "http://token.com", ClientID: "testID", Subject: "user1"}
+		otherSubject := JWTBearerFlow{URL: "http://token.com", ClientID: "testID", Subject: "user2"}
+		withHeaders := JWTBearerFlow{URL: "http://token.com", ClientID: "testID", Subject: "user1", Headers: &headers}
+
+		// then
+		assert.NotEqual(t, flow.CacheKey(), otherSubject.CacheKey())
+		assert.NotEqual(t, flow.CacheKey(), withHeaders.CacheKey())
+		assert.Equal(t, flow.CacheKey(), JWTBearerFlow{URL: "http://token.com", ClientID: "testID", Subject: "user1"}.CacheKey())
+	})
+}
+
+func TestSignJWT(t *testing.T) {
+	t.Run("should sign with EC key", func(t *testing.T) {
+		// given
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		privateKey := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+		// when
+		signed, err := SignJWT(privateKey, "", newJWTClaims("issuer", "subject", "audience"))
+
+		// then
+		require.NoError(t, err)
+		token, err := jwt.ParseSigned(signed)
+		require.NoError(t, err)
+		assert.Equal(t, "ES256", token.Headers[0].Algorithm)
+		claims := jwt.Claims{}
+		require.NoError(t, token.Claims(&key.PublicKey, &claims))
+		assert.Equal(t, "subject", claims.Subject)
+	})
+
+	t.Run("should fail for key not in PEM format", func(t *testing.T) {
+		// when
+		_, err := SignJWT([]byte("invalid"), "", newJWTClaims("issuer", "subject", "audience"))
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func newTokenServer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	return httptest.NewServer(tokenHandler(t, check))
+}
+
+func tokenHandler(t *testing.T, check func(r *http.Request)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, http.MethodPost, r.Method)
+		check(r)
+
+		response := oauthResponse{AccessToken: "123456789", TokenType: "bearer", ExpiresIn: 3600}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+func checkBasicAuth(t *testing.T, r *http.Request, clientID, clientSecret string) {
+	username, password, ok := r.BasicAuth()
+	require.True(t, ok)
+	assert.Equal(t, clientID, username)
+	assert.Equal(t, clientSecret, password)
+}
+
+func verifyJWT(t *testing.T, assertion, keyID string) jwt.Claims {
+	token, err := jwt.ParseSigned(assertion)
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", token.Headers[0].Algorithm)
+	assert.Equal(t, keyID, token.Headers[0].KeyID)
+
+	claims := jwt.Claims{}
+	require.NoError(t, token.Claims(testCertificate(t).PublicKey, &claims))
+	require.NoError(t, claims.Validate(jwt.Expected{Time: time.Now()}))
+	return claims
+}
+
+func verifySAMLAssertion(t *testing.T, assertion string) *etree.Element {
+	decoded, err := base64.RawURLEncoding.DecodeString(assertion)
+	require.NoError(t, err)
+
+	document := etree.NewDocument()
+	require.NoError(t, document.ReadFromBytes(decoded))
+	assert.Equal(t, "Signature", document.Root().ChildElements()[1].Tag)
+
+	certificateStore := &dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{testCertificate(t)}}
+	validationContext := dsig.NewDefaultValidationContext(certificateStore)
+	validationContext.Clock = dsig.NewFakeClockAt(testCertificate(t).NotBefore)
+	validated, err := validationContext.Validate(document.Root())
+	require.NoError(t, err)
+	return validated
+}
+
+func testCertificate(t *testing.T) *x509.Certificate {
+	block, _ := pem.Decode([]byte(testconsts.Certificate))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return certificate
+}
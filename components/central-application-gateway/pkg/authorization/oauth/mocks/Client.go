@@ -5,6 +5,8 @@ package mocks
 import (
 	apperrors "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	mock "github.com/stretchr/testify/mock"
+
+	oauth "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 )
 
 // Client is an autogenerated mock type for the Client type
@@ -35,6 +37,29 @@ func (_m *Client) GetToken(clientID string, clientSecret string, authURL string,
 	return r0, r1
 }
 
+// GetTokenForFlow provides a mock function with given fields: flow
+func (_m *Client) GetTokenForFlow(flow oauth.Flow) (string, apperrors.AppError) {
+	ret := _m.Called(flow)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(oauth.Flow) string); ok {
+		r0 = rf(flow)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(oauth.Flow) apperrors.AppError); ok {
+		r1 = rf(flow)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // InvalidateAndRetry provides a mock function with given fields: clientID, clientSecret, authURL, headers, queryParameters
 func (_m *Client) InvalidateAndRetry(clientID string, clientSecret string, authURL string, headers *map[string][]string, queryParameters *map[string][]string) (string, apperrors.AppError) {
 	ret := _m.Called(clientID, clientSecret, authURL, headers, queryParameters)
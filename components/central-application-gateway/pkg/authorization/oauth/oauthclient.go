@@ -17,6 +17,10 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 )
 
+// minCachedExpiresIn is the lifetime in seconds tokens with a limited caching time must exceed to be cached,
+// as the token cache expires tokens two seconds early
+const minCachedExpiresIn = 2
+
 type oauthResponse struct {
 	AccessToken string `json:"access_token"`
 	TokenType   string `json:"token_type"`
@@ -26,6 +30,7 @@ type oauthResponse struct {
 
 type Client interface {
 	GetToken(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string) (string, apperrors.AppError)
+	GetTokenForFlow(flow Flow) (string, apperrors.AppError)
 	InvalidateAndRetry(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string) (string, apperrors.AppError)
 	InvalidateTokenCache(clientID string)
 }
@@ -58,6 +63,35 @@ func (c *client) GetToken(clientID, clientSecret, authURL string, headers, query
 	return tokenResponse.AccessToken, nil
 }
 
+// GetTokenForFlow returns the cached token of the flow or requests a new one
+func (c *client) GetTokenForFlow(flow Flow) (string, apperrors.AppError) {
+	cacheKey := flow.CacheKey()
+	token, found := c.tokenCache.Get(cacheKey)
+	if found {
+		return token, nil
+	}
+
+	tokenRequest, err := flow.TokenRequest()
+	if err != nil {
+		return "", err
+	}
+
+	tokenResponse, err := c.doTokenRequest(tokenRequest)
+	if err != nil {
+		return "", err
+	}
+
+	expiresIn := tokenResponse.ExpiresIn
+	if tokenRequest.MaxExpiresIn > 0 && (expiresIn <= 0 || expiresIn > tokenRequest.MaxExpiresIn) {
+		expiresIn = tokenRequest.MaxExpiresIn
+	}
+	if expiresIn > minCachedExpiresIn || tokenRequest.MaxExpiresIn == 0 {
+		c.tokenCache.Add(cacheKey, tokenResponse.AccessToken, expiresIn)
+	}
+
+	return tokenResponse.AccessToken, nil
+}
+
 func (c *client) InvalidateAndRetry(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string) (string, apperrors.AppError) {
 	c.tokenCache.Remove(clientID)
 
@@ -76,26 +110,45 @@ func (c *client) InvalidateTokenCache(clientID string) {
 }
 
 func (c *client) requestToken(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string) (*oauthResponse, apperrors.AppError) {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{Transport: transport}
-
 	form := url.Values{}
 	form.Add("client_id", clientID)
 	form.Add("client_secret", clientSecret)
-	form.Add("grant_type", "client_credentials")
+	form.Add("grant_type", grantTypeClientCredentials)
+
+	return c.doTokenRequest(&TokenRequest{
+		URL:             authURL,
+		Form:            form,
+		BasicAuth:       true,
+		ClientID:        clientID,
+		ClientSecret:    clientSecret,
+		Headers:         headers,
+		QueryParameters: queryParameters,
+	})
+}
 
-	req, err := http.NewRequest(http.MethodPost, authURL, strings.NewReader(form.Encode()))
+func (c *client) doTokenRequest(tokenRequest *TokenRequest) (*oauthResponse, apperrors.AppError) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if tokenRequest.Certificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*tokenRequest.Certificate}
+	}
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	client := &http.Client{Transport: transport}
+
+	authURL := tokenRequest.URL
+	req, err := http.NewRequest(http.MethodPost, authURL, strings.NewReader(tokenRequest.Form.Encode()))
 	if err != nil {
 		return nil, apperrors.Internal("failed to create token request: %s", err.Error())
 	}
 
-	util.AddBasicAuthHeader(req, clientID, clientSecret)
+	if tokenRequest.BasicAuth {
+		util.AddBasicAuthHeader(req, tokenRequest.ClientID, tokenRequest.ClientSecret)
+	}
 	req.Header.Add(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationURLEncoded)
 
-	setCustomQueryParameters(req.URL, queryParameters)
-	setCustomHeaders(req.Header, headers)
+	setCustomQueryParameters(req.URL, tokenRequest.QueryParameters)
+	setCustomHeaders(req.Header, tokenRequest.Headers)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeoutDuration)*time.Second)
 	defer cancel()
@@ -6,6 +6,9 @@ import (
 	cache "github.com/patrickmn/go-cache"
 )
 
+// cleanupInterval is the interval expired tokens are removed in, so that tokens cached for every user do not pile up
+const cleanupInterval = 5 * time.Minute
+
 type TokenCache interface {
 	Get(clientID string) (token string, found bool)
 	Add(clientID, token string, expirationSeconds int)
@@ -18,7 +21,7 @@ type tokenCache struct {
 
 func NewTokenCache() TokenCache {
 	return &tokenCache{
-		cache: cache.New(cache.NoExpiration, cache.NoExpiration),
+		cache: cache.New(cache.NoExpiration, cleanupInterval),
 	}
 }
 
@@ -0,0 +1,106 @@
+package authorization
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	log "github.com/sirupsen/logrus"
+)
+
+// oauthFlowStrategy adds the token obtained with an OAuth flow other than the client credentials grant with a client secret
+type oauthFlowStrategy struct {
+	oauthClient OAuthClient
+	flow        oauth.Flow
+}
+
+func newPrivateKeyJWTStrategy(oauthClient OAuthClient, config OAuthPrivateKeyJWT) oauthFlowStrategy {
+	headers, queryParameters := config.RequestParameters.unpack()
+	return oauthFlowStrategy{
+		oauthClient: oauthClient,
+		flow: oauth.PrivateKeyJWTFlow{
+			URL:             config.URL,
+			ClientID:        config.ClientID,
+			PrivateKey:      config.PrivateKey,
+			KeyID:           config.KeyID,
+			Scope:           config.Scope,
+			Headers:         headers,
+			QueryParameters: queryParameters,
+		},
+	}
+}
+
+func newOAuthMTLSStrategy(oauthClient OAuthClient, config OAuthMTLS) oauthFlowStrategy {
+	headers, queryParameters := config.RequestParameters.unpack()
+	return oauthFlowStrategy{
+		oauthClient: oauthClient,
+		flow: oauth.MTLSFlow{
+			URL:             config.URL,
+			ClientID:        config.ClientID,
+			Certificate:     config.Certificate,
+			PrivateKey:      config.PrivateKey,
+			Scope:           config.Scope,
+			Headers:         headers,
+			QueryParameters: queryParameters,
+		},
+	}
+}
+
+func newJWTBearerStrategy(oauthClient OAuthClient, config OAuthJWTBearer) oauthFlowStrategy {
+	headers, queryParameters := config.RequestParameters.unpack()
+	return oauthFlowStrategy{
+		oauthClient: oauthClient,
+		flow: oauth.JWTBearerFlow{
+			URL:             config.URL,
+			ClientID:        config.ClientID,
+			ClientSecret:    config.ClientSecret,
+			PrivateKey:      config.PrivateKey,
+			KeyID:           config.KeyID,
+			Issuer:          config.Issuer,
+			Subject:         config.Subject,
+			Audience:        config.Audience,
+			Scope:           config.Scope,
+			Headers:         headers,
+			QueryParameters: queryParameters,
+		},
+	}
+}
+
+func newSAMLBearerStrategy(oauthClient OAuthClient, config OAuthSAMLBearer) oauthFlowStrategy {
+	headers, queryParameters := config.RequestParameters.unpack()
+	return oauthFlowStrategy{
+		oauthClient: oauthClient,
+		flow: oauth.SAMLBearerFlow{
+			URL:             config.URL,
+			ClientID:        config.ClientID,
+			ClientSecret:    config.ClientSecret,
+			Certificate:     config.Certificate,
+			PrivateKey:      config.PrivateKey,
+			Issuer:          config.Issuer,
+			Subject:         config.Subject,
+			Audience:        config.Audience,
+			Scope:           config.Scope,
+			Headers:         headers,
+			QueryParameters: queryParameters,
+		},
+	}
+}
+
+func (o oauthFlowStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc) apperrors.AppError {
+	token, err := o.oauthClient.GetTokenForFlow(o.flow)
+	if err != nil {
+		log.Errorf("failed to get token : '%s'", err)
+		return err
+	}
+
+	r.Header.Set(httpconsts.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+	return nil
+}
+
+func (o oauthFlowStrategy) Invalidate() {
+	o.oauthClient.InvalidateTokenCache(o.flow.CacheKey())
+}
@@ -0,0 +1,81 @@
+package authorization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	oauthMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthFlowStrategy(t *testing.T) {
+	headers := map[string][]string{"header": {"value"}}
+	config := OAuthJWTBearer{
+		URL:               "www.example.com/token",
+		ClientID:          "clientId",
+		PrivateKey:        privateKey,
+		Subject:           "user",
+		RequestParameters: &RequestParameters{Headers: &headers},
+	}
+	expectedFlow := oauth.JWTBearerFlow{
+		URL:        "www.example.com/token",
+		ClientID:   "clientId",
+		PrivateKey: privateKey,
+		Subject:    "user",
+		Headers:    &headers,
+	}
+
+	t.Run("should add Authorization header", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenForFlow", expectedFlow).Return("token", nil)
+
+		strategy := newJWTBearerStrategy(oauthClientMock, config)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer token", request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should invalidate cached token of the flow", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("InvalidateTokenCache", expectedFlow.CacheKey()).Once()
+
+		strategy := newJWTBearerStrategy(oauthClientMock, config)
+
+		// when
+		strategy.Invalidate()
+
+		// then
+		oauthClientMock.AssertExpectations(t)
+	})
+
+	t.Run("should not add Authorization header when getting token failed", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenForFlow", expectedFlow).Return("", apperrors.UpstreamServerCallFailed("failed")).Once()
+
+		strategy := newJWTBearerStrategy(oauthClientMock, config)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Empty(t, request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+}
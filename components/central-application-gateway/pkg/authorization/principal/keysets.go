@@ -0,0 +1,108 @@
+// Package principal contains the handling of the tokens of the users whose identity the Application Gateway
+// propagates to the target APIs
+package principal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+const (
+	// keySetMaxAge is the time after which a key set is fetched again
+	keySetMaxAge = 10 * time.Minute
+	// keySetMinRefreshInterval limits fetching a key set again for tokens signed with unknown keys
+	keySetMinRefreshInterval = 30 * time.Second
+)
+
+// KeySets fetches and caches the JSON Web Key Sets of the identity providers issuing the tokens of the users
+type KeySets struct {
+	client *http.Client
+	now    func() time.Time
+
+	mutex   sync.Mutex
+	keySets map[string]*keySet
+}
+
+type keySet struct {
+	// mutex serializes fetching the key set
+	mutex     sync.Mutex
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// NewKeySets creates the key set cache fetching key sets with the given timeout in seconds
+func NewKeySets(timeout int) *KeySets {
+	return &KeySets{
+		client:  &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		now:     time.Now,
+		keySets: map[string]*keySet{},
+	}
+}
+
+// Keys returns the keys with the given ID from the key set at the URL. The key set is fetched again if it is outdated
+// or does not contain the key.
+func (ks *KeySets) Keys(url, keyID string) ([]jose.JSONWebKey, error) {
+	set := ks.keySet(url)
+
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	age := ks.now().Sub(set.fetchedAt)
+	outdated := set.fetchedAt.IsZero() || age > keySetMaxAge
+	unknownKey := len(set.keys.Key(keyID)) == 0 && age > keySetMinRefreshInterval
+	if outdated || unknownKey {
+		if err := ks.fetch(url, set); err != nil {
+			if set.fetchedAt.IsZero() {
+				return nil, err
+			}
+			log.Warnf("%s, using the cached key set", err)
+		}
+	}
+
+	return set.keys.Key(keyID), nil
+}
+
+func (ks *KeySets) keySet(url string) *keySet {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	set, found := ks.keySets[url]
+	if !found {
+		set = &keySet{}
+		ks.keySets[url] = set
+	}
+	return set
+}
+
+func (ks *KeySets) fetch(url string, set *keySet) error {
+	response, err := ks.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch key set from '%s': %s", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("incorrect response code '%d' while fetching key set from '%s'", response.StatusCode, url)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read key set from '%s': %s", url, err)
+	}
+
+	keys := jose.JSONWebKeySet{}
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return fmt.Errorf("failed to unmarshal key set from '%s': %s", url, err)
+	}
+
+	set.keys = keys
+	set.fetchedAt = ks.now()
+	return nil
+}
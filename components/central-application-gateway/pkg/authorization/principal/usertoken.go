@@ -0,0 +1,123 @@
+package principal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// UserToken is the JWT of the user calling the Application Gateway
+type UserToken struct {
+	// Raw is the compact serialization of the token
+	Raw string
+	// Claims are all claims of the token
+	Claims map[string]interface{}
+	// Expiry is the expiry of the token, zero if it does not expire
+	Expiry time.Time
+}
+
+// Expected holds the expected values of the claims of verified tokens, empty values are not verified
+type Expected struct {
+	Issuer   string
+	Audience string
+}
+
+// FromHeader returns the token from the value of a header, with or without the Bearer prefix
+func FromHeader(value string) string {
+	if len(value) > len("bearer ") && strings.EqualFold(value[:len("bearer ")], "bearer ") {
+		return strings.TrimSpace(value[len("bearer "):])
+	}
+	return strings.TrimSpace(value)
+}
+
+// ParseUnverified parses the token without verifying it, for tokens verified by the identity provider they are sent to
+func ParseUnverified(raw string) (UserToken, error) {
+	token, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return UserToken{}, fmt.Errorf("failed to parse token: %s", err)
+	}
+
+	claims := map[string]interface{}{}
+	registeredClaims := jwt.Claims{}
+	if err := token.UnsafeClaimsWithoutVerification(&claims, &registeredClaims); err != nil {
+		return UserToken{}, fmt.Errorf("failed to parse token claims: %s", err)
+	}
+
+	return newUserToken(raw, claims, registeredClaims), nil
+}
+
+// Verify parses the token and verifies its signature with the key set at the URL, its expiry, and the expected claims
+func (ks *KeySets) Verify(raw, keySetURL string, expected Expected) (UserToken, error) {
+	token, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return UserToken{}, fmt.Errorf("failed to parse token: %s", err)
+	}
+	if len(token.Headers) != 1 {
+		return UserToken{}, errors.New("token must have exactly one signature")
+	}
+
+	keys, err := ks.verificationKeys(keySetURL, token.Headers[0].KeyID)
+	if err != nil {
+		return UserToken{}, err
+	}
+
+	claims := map[string]interface{}{}
+	registeredClaims := jwt.Claims{}
+	verified := false
+	for _, key := range keys {
+		if err := token.Claims(key, &claims, &registeredClaims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return UserToken{}, errors.New("failed to verify token signature")
+	}
+
+	expectedClaims := jwt.Expected{Issuer: expected.Issuer, Time: ks.now()}
+	if expected.Audience != "" {
+		expectedClaims.Audience = jwt.Audience{expected.Audience}
+	}
+	if err := registeredClaims.ValidateWithLeeway(expectedClaims, jwt.DefaultLeeway); err != nil {
+		return UserToken{}, fmt.Errorf("invalid token: %s", err)
+	}
+
+	return newUserToken(raw, claims, registeredClaims), nil
+}
+
+// verificationKeys returns the keys with the key ID of the token, or all keys of the set if the token has no key ID
+func (ks *KeySets) verificationKeys(keySetURL, keyID string) ([]jose.JSONWebKey, error) {
+	if keyID != "" {
+		keys, err := ks.Keys(keySetURL, keyID)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("key '%s' not found in key set", keyID)
+		}
+		return keys, nil
+	}
+
+	set := ks.keySet(keySetURL)
+	if _, err := ks.Keys(keySetURL, ""); err != nil {
+		return nil, err
+	}
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+	return set.keys.Keys, nil
+}
+
+func newUserToken(raw string, claims map[string]interface{}, registeredClaims jwt.Claims) UserToken {
+	userToken := UserToken{
+		Raw:    raw,
+		Claims: claims,
+	}
+	if registeredClaims.Expiry != nil {
+		userToken.Expiry = registeredClaims.Expiry.Time()
+	}
+	return userToken
+}
@@ -0,0 +1,181 @@
+package principal
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
+)
+
+func TestKeySets_Verify(t *testing.T) {
+	t.Run("should verify token and return its claims", func(t *testing.T) {
+		// given
+		server, fetches := newKeySetServer(t, "key-1")
+		defer server.Close()
+
+		raw := signToken(t, "key-1", map[string]interface{}{"email": "user@example.com"}, jwt.Claims{
+			Issuer:   "https://idp.example.com",
+			Audience: jwt.Audience{"gateway"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+
+		keySets := NewKeySets(10)
+
+		// when
+		token, err := keySets.Verify(raw, server.URL, Expected{Issuer: "https://idp.example.com", Audience: "gateway"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, raw, token.Raw)
+		assert.Equal(t, "user@example.com", token.Claims["email"])
+		assert.False(t, token.Expiry.IsZero())
+
+		// when
+		_, err = keySets.Verify(raw, server.URL, Expected{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 1, *fetches, "key set should be cached")
+	})
+
+	t.Run("should reject token with unexpected issuer", func(t *testing.T) {
+		// given
+		server, _ := newKeySetServer(t, "key-1")
+		defer server.Close()
+
+		raw := signToken(t, "key-1", jwt.Claims{Issuer: "https://other.example.com"})
+
+		// when
+		_, err := NewKeySets(10).Verify(raw, server.URL, Expected{Issuer: "https://idp.example.com"})
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("should reject expired token", func(t *testing.T) {
+		// given
+		server, _ := newKeySetServer(t, "key-1")
+		defer server.Close()
+
+		raw := signToken(t, "key-1", jwt.Claims{Expiry: jwt.NewNumericDate(time.Now().Add(-time.Hour))})
+
+		// when
+		_, err := NewKeySets(10).Verify(raw, server.URL, Expected{})
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("should reject token signed with unknown key", func(t *testing.T) {
+		// given
+		server, _ := newKeySetServer(t, "key-1")
+		defer server.Close()
+
+		raw := signToken(t, "key-2", jwt.Claims{Subject: "user"})
+
+		// when
+		_, err := NewKeySets(10).Verify(raw, server.URL, Expected{})
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("should fetch key set again for unknown key", func(t *testing.T) {
+		// given
+		server, fetches := newKeySetServer(t, "key-1")
+		defer server.Close()
+
+		now := time.Now()
+		keySets := NewKeySets(10)
+		keySets.now = func() time.Time { return now }
+
+		_, err := keySets.Verify(signToken(t, "key-1", jwt.Claims{Subject: "user"}), server.URL, Expected{})
+		require.NoError(t, err)
+
+		// when
+		now = now.Add(time.Minute)
+		_, err = keySets.Verify(signToken(t, "key-2", jwt.Claims{Subject: "user"}), server.URL, Expected{})
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, 2, *fetches)
+	})
+
+	t.Run("should fail when key set cannot be fetched", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		raw := signToken(t, "key-1", jwt.Claims{Subject: "user"})
+
+		// when
+		_, err := NewKeySets(10).Verify(raw, server.URL, Expected{})
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestParseUnverified(t *testing.T) {
+	t.Run("should parse token", func(t *testing.T) {
+		// given
+		expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+		raw := signToken(t, "", jwt.Claims{Subject: "user", Expiry: jwt.NewNumericDate(expiry)})
+
+		// when
+		token, err := ParseUnverified(raw)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "user", token.Claims["sub"])
+		assert.True(t, expiry.Equal(token.Expiry))
+	})
+
+	t.Run("should fail for invalid token", func(t *testing.T) {
+		// when
+		_, err := ParseUnverified("invalid")
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestFromHeader(t *testing.T) {
+	assert.Equal(t, "token", FromHeader("Bearer token"))
+	assert.Equal(t, "token", FromHeader("bearer token"))
+	assert.Equal(t, "token", FromHeader("token"))
+	assert.Equal(t, "", FromHeader(""))
+}
+
+func newKeySetServer(t *testing.T, keyID string) (*httptest.Server, *int) {
+	block, _ := pem.Decode([]byte(testconsts.Certificate))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	keySet := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: certificate.PublicKey, KeyID: keyID, Algorithm: "RS256", Use: "sig"}}}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(keySet)
+	}))
+	return server, &fetches
+}
+
+func signToken(t *testing.T, keyID string, claims ...interface{}) string {
+	raw, err := oauth.SignJWT([]byte(testconsts.PrivateKey), keyID, claims...)
+	require.NoError(t, err)
+	return raw
+}
@@ -0,0 +1,184 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/principal"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	defaultAssertionHeader = "X-Principal-Assertion"
+	// maxAssertionLifetime limits the validity of the JWT with the passed claims
+	maxAssertionLifetime = 5 * time.Minute
+)
+
+// userTokenKey is the key of the JWT of the user in the context of the request, so that the request can be
+// authorized again after the header with the token was removed
+type userTokenKey struct{}
+
+type principalPropagationStrategy struct {
+	oauthClient OAuthClient
+	keySets     *principal.KeySets
+	config      PrincipalPropagation
+	// strategy adds the credentials of the Application Gateway, it is not used with the token exchange
+	strategy Strategy
+}
+
+func newPrincipalPropagationStrategy(oauthClient OAuthClient, keySets *principal.KeySets, config PrincipalPropagation, strategy Strategy) principalPropagationStrategy {
+	if config.TokenHeader == "" {
+		config.TokenHeader = httpconsts.HeaderAuthorization
+	}
+	if config.SignedHeaders != nil && config.SignedHeaders.AssertionHeader == "" {
+		signedHeaders := *config.SignedHeaders
+		signedHeaders.AssertionHeader = defaultAssertionHeader
+		config.SignedHeaders = &signedHeaders
+	}
+
+	return principalPropagationStrategy{
+		oauthClient: oauthClient,
+		keySets:     keySets,
+		config:      config,
+		strategy:    strategy,
+	}
+}
+
+func (p principalPropagationStrategy) AddAuthorization(r *http.Request, setter clientcert.SetClientCertificateFunc) apperrors.AppError {
+	userToken, err := p.takeUserToken(r)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case p.config.TokenExchange != nil:
+		return p.exchangeToken(r, userToken)
+	case p.config.SignedHeaders != nil:
+		return p.addSignedHeaders(r, setter, userToken)
+	default:
+		return apperrors.Internal("principal propagation requires token exchange or signed headers configuration")
+	}
+}
+
+// takeUserToken removes the JWT of the user from the request, so that it is not sent to the target API
+func (p principalPropagationStrategy) takeUserToken(r *http.Request) (string, apperrors.AppError) {
+	userToken := principal.FromHeader(r.Header.Get(p.config.TokenHeader))
+	if userToken == "" {
+		if token, ok := r.Context().Value(userTokenKey{}).(string); ok {
+			return token, nil
+		}
+		return "", apperrors.Unauthorized("token of the user not found in the '%s' header", p.config.TokenHeader)
+	}
+
+	r.Header.Del(p.config.TokenHeader)
+	*r = *r.WithContext(context.WithValue(r.Context(), userTokenKey{}, userToken))
+	return userToken, nil
+}
+
+func (p principalPropagationStrategy) exchangeToken(r *http.Request, userToken string) apperrors.AppError {
+	parsed, parseErr := principal.ParseUnverified(userToken)
+	if parseErr != nil {
+		return apperrors.Unauthorized("invalid token of the user: %s", parseErr.Error())
+	}
+
+	config := p.config.TokenExchange
+	headers, queryParameters := config.RequestParameters.unpack()
+	token, err := p.oauthClient.GetTokenForFlow(oauth.TokenExchangeFlow{
+		URL:                config.URL,
+		ClientID:           config.ClientID,
+		ClientSecret:       config.ClientSecret,
+		SubjectToken:       userToken,
+		SubjectTokenType:   config.SubjectTokenType,
+		SubjectTokenExpiry: parsed.Expiry,
+		RequestedTokenType: config.RequestedTokenType,
+		Audience:           config.Audience,
+		Resource:           config.Resource,
+		Scope:              config.Scope,
+		Headers:            headers,
+		QueryParameters:    queryParameters,
+	})
+	if err != nil {
+		log.Errorf("failed to exchange token of the user : '%s'", err)
+		return err
+	}
+
+	r.Header.Set(httpconsts.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+	return nil
+}
+
+func (p principalPropagationStrategy) addSignedHeaders(r *http.Request, setter clientcert.SetClientCertificateFunc, userToken string) apperrors.AppError {
+	config := p.config.SignedHeaders
+
+	// headers set by the caller must not be passed as if they were signed
+	r.Header.Del(config.AssertionHeader)
+	for _, header := range config.Claims {
+		r.Header.Del(header)
+	}
+
+	verified, verifyErr := p.keySets.Verify(userToken, config.JWKSURL, principal.Expected{Issuer: config.Issuer, Audience: config.Audience})
+	if verifyErr != nil {
+		log.Errorf("failed to verify token of the user : '%s'", verifyErr)
+		return apperrors.Unauthorized("invalid token of the user: %s", verifyErr.Error())
+	}
+
+	if err := p.strategy.AddAuthorization(r, setter); err != nil {
+		return err
+	}
+
+	passedClaims := map[string]interface{}{}
+	for claim, header := range config.Claims {
+		value, found := verified.Claims[claim]
+		if !found {
+			continue
+		}
+		headerValue, err := claimHeaderValue(value)
+		if err != nil {
+			return apperrors.Internal("failed to pass claim '%s': %s", claim, err.Error())
+		}
+		r.Header.Set(header, headerValue)
+		passedClaims[claim] = value
+	}
+
+	now := time.Now()
+	expiry := now.Add(maxAssertionLifetime)
+	if !verified.Expiry.IsZero() && verified.Expiry.Before(expiry) {
+		expiry = verified.Expiry
+	}
+	assertion, signErr := oauth.SignJWT(config.PrivateKey, config.KeyID, passedClaims, jwt.Claims{
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(expiry),
+	})
+	if signErr != nil {
+		return apperrors.Internal("failed to sign claims of the user: %s", signErr.Error())
+	}
+	r.Header.Set(config.AssertionHeader, assertion)
+
+	return nil
+}
+
+// claimHeaderValue returns strings as they are and other values in JSON
+func claimHeaderValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// Invalidate invalidates the credentials of the Application Gateway. Exchanged tokens of the users are not invalidated,
+// as they are cached by the token of the user which is not known here, and expire with it.
+func (p principalPropagationStrategy) Invalidate() {
+	p.strategy.Invalidate()
+}
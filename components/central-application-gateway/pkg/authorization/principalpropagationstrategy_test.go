@@ -0,0 +1,258 @@
+package authorization
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	oauthMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/principal"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+func TestPrincipalPropagationStrategy_TokenExchange(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	userToken := signUserToken(t, jwt.Claims{Subject: "user", Expiry: jwt.NewNumericDate(expiry)})
+	config := PrincipalPropagation{
+		TokenExchange: &TokenExchange{
+			URL:          "www.example.com/token",
+			ClientID:     "clientId",
+			ClientSecret: "clientSecret",
+			Audience:     "backend",
+		},
+	}
+	isExchangeOfUserToken := mock.MatchedBy(func(flow oauth.TokenExchangeFlow) bool {
+		return flow.SubjectToken == userToken && flow.SubjectTokenExpiry.Equal(expiry) &&
+			flow.ClientID == "clientId" && flow.ClientSecret == "clientSecret" && flow.Audience == "backend"
+	})
+
+	t.Run("should replace token of the user with exchanged token", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenForFlow", isExchangeOfUserToken).Return("exchanged", nil)
+
+		strategy := newPrincipalPropagationStrategy(oauthClientMock, nil, config, newNoAuthStrategy())
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer "+userToken)
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer exchanged", request.Header.Get(httpconsts.HeaderAuthorization))
+		oauthClientMock.AssertExpectations(t)
+	})
+
+	t.Run("should exchange token of the user again when authorizing the request again", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenForFlow", isExchangeOfUserToken).Return("exchanged", nil).Twice()
+		oauthClientMock.On("InvalidateTokenCache", "technicalClientId").Once()
+
+		technicalStrategy := newOAuthStrategy(oauthClientMock, "technicalClientId", "technicalClientSecret", "www.example.com/token", nil)
+
+		strategy := newPrincipalPropagationStrategy(oauthClientMock, nil, PrincipalPropagation{TokenHeader: "X-User-Token", TokenExchange: config.TokenExchange}, technicalStrategy)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request.Header.Set("X-User-Token", userToken)
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+		require.NoError(t, err)
+		strategy.Invalidate()
+		err = strategy.AddAuthorization(request.Clone(request.Context()), nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, request.Header.Get("X-User-Token"))
+		oauthClientMock.AssertExpectations(t)
+	})
+
+	t.Run("should reject request without token of the user", func(t *testing.T) {
+		// given
+		strategy := newPrincipalPropagationStrategy(&oauthMocks.Client{}, nil, config, newNoAuthStrategy())
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeUnauthorized, err.(apperrors.AppError).Code())
+	})
+
+	t.Run("should fail when token exchange failed", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenForFlow", isExchangeOfUserToken).Return("", apperrors.UpstreamServerCallFailed("failed"))
+
+		strategy := newPrincipalPropagationStrategy(oauthClientMock, nil, config, newNoAuthStrategy())
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer "+userToken)
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Empty(t, request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+}
+
+func TestPrincipalPropagationStrategy_SignedHeaders(t *testing.T) {
+	keySetServer := newUserKeySetServer(t)
+	defer keySetServer.Close()
+
+	config := PrincipalPropagation{
+		SignedHeaders: &SignedHeaders{
+			JWKSURL: keySetServer.URL,
+			Issuer:  "https://idp.example.com",
+			Claims: map[string]string{
+				"email":  "X-User-Email",
+				"groups": "X-User-Groups",
+				"tenant": "X-User-Tenant",
+			},
+			PrivateKey: []byte(testconsts.PrivateKey),
+		},
+	}
+
+	t.Run("should pass signed claims in addition to the credentials of the gateway", func(t *testing.T) {
+		// given
+		userToken := signUserToken(t, map[string]interface{}{"email": "user@example.com", "groups": []string{"admins"}}, jwt.Claims{
+			Issuer: "https://idp.example.com",
+			Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+
+		technicalStrategy := newBasicAuthStrategy("username", "password")
+		strategy := newPrincipalPropagationStrategy(&oauthMocks.Client{}, principal.NewKeySets(10), config, technicalStrategy)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer "+userToken)
+		request.Header.Set("X-User-Tenant", "spoofed")
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, request.Header.Get(httpconsts.HeaderAuthorization), "Basic ")
+		assert.Equal(t, "user@example.com", request.Header.Get("X-User-Email"))
+		assert.Equal(t, `["admins"]`, request.Header.Get("X-User-Groups"))
+		assert.Empty(t, request.Header.Get("X-User-Tenant"))
+
+		assertion, err := jwt.ParseSigned(request.Header.Get(defaultAssertionHeader))
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		require.NoError(t, assertion.Claims(userPublicKey(t), &claims))
+		assert.Equal(t, "user@example.com", claims["email"])
+		assert.Equal(t, []interface{}{"admins"}, claims["groups"])
+		assert.NotContains(t, claims, "tenant")
+		assert.Contains(t, claims, "exp")
+	})
+
+	t.Run("should reject token of the user from unexpected issuer", func(t *testing.T) {
+		// given
+		userToken := signUserToken(t, map[string]interface{}{"email": "user@example.com"}, jwt.Claims{Issuer: "https://other.example.com"})
+
+		strategy := newPrincipalPropagationStrategy(&oauthMocks.Client{}, principal.NewKeySets(10), config, newNoAuthStrategy())
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer "+userToken)
+		request.Header.Set("X-User-Email", "spoofed")
+
+		// when
+		err = strategy.AddAuthorization(request, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeUnauthorized, err.(apperrors.AppError).Code())
+		assert.Empty(t, request.Header.Get("X-User-Email"))
+	})
+}
+
+func TestStrategyFactory_PrincipalPropagation(t *testing.T) {
+	t.Run("should create principal propagation strategy with the external token taking precedence", func(t *testing.T) {
+		// given
+		userToken := signUserToken(t, jwt.Claims{Subject: "user"})
+
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenForFlow", mock.AnythingOfType("oauth.TokenExchangeFlow")).Return("exchanged", nil).Once()
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			BasicAuth: &BasicAuth{Username: "username", Password: "password"},
+			PrincipalPropagation: &PrincipalPropagation{
+				TokenExchange: &TokenExchange{URL: "www.example.com/token", ClientID: "clientId"},
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderAuthorization, userToken)
+
+		err = strategy.AddAuthorization(request, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer exchanged", request.Header.Get(httpconsts.HeaderAuthorization))
+
+		// given
+		requestWithExternalToken, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		requestWithExternalToken.Header.Set(httpconsts.HeaderAccessToken, "Bearer external")
+
+		// when
+		err = strategy.AddAuthorization(requestWithExternalToken, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer external", requestWithExternalToken.Header.Get(httpconsts.HeaderAuthorization))
+		oauthClientMock.AssertExpectations(t)
+	})
+}
+
+func signUserToken(t *testing.T, claims ...interface{}) string {
+	token, err := oauth.SignJWT([]byte(testconsts.PrivateKey), "key-1", claims...)
+	require.NoError(t, err)
+	return token
+}
+
+func userPublicKey(t *testing.T) interface{} {
+	block, _ := pem.Decode([]byte(testconsts.Certificate))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return certificate.PublicKey
+}
+
+func newUserKeySetServer(t *testing.T) *httptest.Server {
+	keySet := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: userPublicKey(t), KeyID: "key-1", Algorithm: "RS256", Use: "sig"}}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(keySet)
+	}))
+}
@@ -21,6 +21,11 @@ const (
 	Oauth       AuthType = "oauth"
 	Basic       AuthType = "basicauth"
 	Certificate AuthType = "certificate"
+
+	OauthPrivateKeyJWT AuthType = "oauthprivatekeyjwt"
+	OauthMTLS          AuthType = "oauthmtls"
+	OauthJWTBearer     AuthType = "oauthjwtbearer"
+	OauthSAMLBearer    AuthType = "oauthsamlbearer"
 )
 
 // ProxyDestinationConfig is Proxy configuration for specific target
@@ -30,15 +35,94 @@ type ProxyDestinationConfig struct {
 }
 
 type Configuration struct {
-	RequestParameters *authorization.RequestParameters `json:"requestParameters,omitempty"`
-	CSRFConfig        *CSRFConfig                      `json:"csrfConfig,omitempty"`
-	Credentials       Credentials                      `json:"credentials,omitempty"`
+	RequestParameters    *authorization.RequestParameters `json:"requestParameters,omitempty"`
+	CSRFConfig           *CSRFConfig                      `json:"csrfConfig,omitempty"`
+	Credentials          Credentials                      `json:"credentials,omitempty"`
+	PrincipalPropagation *PrincipalPropagationConfig      `json:"principalPropagation,omitempty"`
+}
+
+// ToCredentials returns the credentials together with the principal propagation configuration
+func (c Configuration) ToCredentials() *authorization.Credentials {
+	var credentials *authorization.Credentials
+	if c.Credentials != nil {
+		credentials = c.Credentials.ToCredentials()
+	}
+	if c.PrincipalPropagation == nil {
+		return credentials
+	}
+
+	if credentials == nil {
+		credentials = &authorization.Credentials{}
+	}
+	credentials.PrincipalPropagation = c.PrincipalPropagation.ToPrincipalPropagation()
+	return credentials
 }
 
 type CSRFConfig struct {
 	TokenURL string `json:"tokenUrl"`
 }
 
+// PrincipalPropagationConfig enables propagating the identity of the user calling the Application Gateway,
+// either by exchanging the token of the user or by passing its claims in signed headers
+type PrincipalPropagationConfig struct {
+	TokenHeader   string               `json:"tokenHeader,omitempty"`
+	TokenExchange *TokenExchangeConfig `json:"tokenExchange,omitempty"`
+	SignedHeaders *SignedHeadersConfig `json:"signedHeaders,omitempty"`
+}
+
+type TokenExchangeConfig struct {
+	ClientId           string                          `json:"clientId"`
+	ClientSecret       string                          `json:"clientSecret,omitempty"`
+	TokenURL           string                          `json:"tokenUrl"`
+	SubjectTokenType   string                          `json:"subjectTokenType,omitempty"`
+	RequestedTokenType string                          `json:"requestedTokenType,omitempty"`
+	Audience           string                          `json:"audience,omitempty"`
+	Resource           string                          `json:"resource,omitempty"`
+	Scope              string                          `json:"scope,omitempty"`
+	RequestParameters  authorization.RequestParameters `json:"requestParameters,omitempty"`
+}
+
+type SignedHeadersConfig struct {
+	JWKSURL         string            `json:"jwksUrl"`
+	Issuer          string            `json:"issuer,omitempty"`
+	Audience        string            `json:"audience,omitempty"`
+	Claims          map[string]string `json:"claims"`
+	AssertionHeader string            `json:"assertionHeader,omitempty"`
+	PrivateKey      []byte            `json:"privateKey"`
+	KeyId           string            `json:"keyId,omitempty"`
+}
+
+func (pc PrincipalPropagationConfig) ToPrincipalPropagation() *authorization.PrincipalPropagation {
+	principalPropagation := &authorization.PrincipalPropagation{
+		TokenHeader: pc.TokenHeader,
+	}
+	if pc.TokenExchange != nil {
+		principalPropagation.TokenExchange = &authorization.TokenExchange{
+			URL:                pc.TokenExchange.TokenURL,
+			ClientID:           pc.TokenExchange.ClientId,
+			ClientSecret:       pc.TokenExchange.ClientSecret,
+			SubjectTokenType:   pc.TokenExchange.SubjectTokenType,
+			RequestedTokenType: pc.TokenExchange.RequestedTokenType,
+			Audience:           pc.TokenExchange.Audience,
+			Resource:           pc.TokenExchange.Resource,
+			Scope:              pc.TokenExchange.Scope,
+			RequestParameters:  &pc.TokenExchange.RequestParameters,
+		}
+	}
+	if pc.SignedHeaders != nil {
+		principalPropagation.SignedHeaders = &authorization.SignedHeaders{
+			JWKSURL:         pc.SignedHeaders.JWKSURL,
+			Issuer:          pc.SignedHeaders.Issuer,
+			Audience:        pc.SignedHeaders.Audience,
+			Claims:          pc.SignedHeaders.Claims,
+			AssertionHeader: pc.SignedHeaders.AssertionHeader,
+			PrivateKey:      pc.SignedHeaders.PrivateKey,
+			KeyID:           pc.SignedHeaders.KeyId,
+		}
+	}
+	return principalPropagation
+}
+
 type Credentials interface {
 	ToCredentials() *authorization.Credentials
 }
@@ -94,3 +178,107 @@ func (cc CertificateConfig) ToCredentials() *authorization.Credentials {
 		},
 	}
 }
+
+type OauthPrivateKeyJWTConfig struct {
+	ClientId          string                          `json:"clientId"`
+	TokenURL          string                          `json:"tokenUrl"`
+	PrivateKey        []byte                          `json:"privateKey"`
+	KeyId             string                          `json:"keyId,omitempty"`
+	Scope             string                          `json:"scope,omitempty"`
+	RequestParameters authorization.RequestParameters `json:"requestParameters,omitempty"`
+}
+
+func (oc OauthPrivateKeyJWTConfig) ToCredentials() *authorization.Credentials {
+	return &authorization.Credentials{
+		OAuthPrivateKeyJWT: &authorization.OAuthPrivateKeyJWT{
+			URL:               oc.TokenURL,
+			ClientID:          oc.ClientId,
+			PrivateKey:        oc.PrivateKey,
+			KeyID:             oc.KeyId,
+			Scope:             oc.Scope,
+			RequestParameters: &oc.RequestParameters,
+		},
+	}
+}
+
+type OauthMTLSConfig struct {
+	ClientId          string                          `json:"clientId"`
+	TokenURL          string                          `json:"tokenUrl"`
+	Certificate       []byte                          `json:"certificate"`
+	PrivateKey        []byte                          `json:"privateKey"`
+	Scope             string                          `json:"scope,omitempty"`
+	RequestParameters authorization.RequestParameters `json:"requestParameters,omitempty"`
+}
+
+func (oc OauthMTLSConfig) ToCredentials() *authorization.Credentials {
+	return &authorization.Credentials{
+		OAuthMTLS: &authorization.OAuthMTLS{
+			URL:               oc.TokenURL,
+			ClientID:          oc.ClientId,
+			Certificate:       oc.Certificate,
+			PrivateKey:        oc.PrivateKey,
+			Scope:             oc.Scope,
+			RequestParameters: &oc.RequestParameters,
+		},
+	}
+}
+
+type OauthJWTBearerConfig struct {
+	ClientId          string                          `json:"clientId"`
+	ClientSecret      string                          `json:"clientSecret,omitempty"`
+	TokenURL          string                          `json:"tokenUrl"`
+	PrivateKey        []byte                          `json:"privateKey"`
+	KeyId             string                          `json:"keyId,omitempty"`
+	Issuer            string                          `json:"issuer,omitempty"`
+	Subject           string                          `json:"subject"`
+	Audience          string                          `json:"audience,omitempty"`
+	Scope             string                          `json:"scope,omitempty"`
+	RequestParameters authorization.RequestParameters `json:"requestParameters,omitempty"`
+}
+
+func (oc OauthJWTBearerConfig) ToCredentials() *authorization.Credentials {
+	return &authorization.Credentials{
+		OAuthJWTBearer: &authorization.OAuthJWTBearer{
+			URL:               oc.TokenURL,
+			ClientID:          oc.ClientId,
+			ClientSecret:      oc.ClientSecret,
+			PrivateKey:        oc.PrivateKey,
+			KeyID:             oc.KeyId,
+			Issuer:            oc.Issuer,
+			Subject:           oc.Subject,
+			Audience:          oc.Audience,
+			Scope:             oc.Scope,
+			RequestParameters: &oc.RequestParameters,
+		},
+	}
+}
+
+type OauthSAMLBearerConfig struct {
+	ClientId          string                          `json:"clientId"`
+	ClientSecret      string                          `json:"clientSecret,omitempty"`
+	TokenURL          string                          `json:"tokenUrl"`
+	Certificate       []byte                          `json:"certificate"`
+	PrivateKey        []byte                          `json:"privateKey"`
+	Issuer            string                          `json:"issuer,omitempty"`
+	Subject           string                          `json:"subject"`
+	Audience          string                          `json:"audience,omitempty"`
+	Scope             string                          `json:"scope,omitempty"`
+	RequestParameters authorization.RequestParameters `json:"requestParameters,omitempty"`
+}
+
+func (oc OauthSAMLBearerConfig) ToCredentials() *authorization.Credentials {
+	return &authorization.Credentials{
+		OAuthSAMLBearer: &authorization.OAuthSAMLBearer{
+			URL:               oc.TokenURL,
+			ClientID:          oc.ClientId,
+			ClientSecret:      oc.ClientSecret,
+			Certificate:       oc.Certificate,
+			PrivateKey:        oc.PrivateKey,
+			Issuer:            oc.Issuer,
+			Subject:           oc.Subject,
+			Audience:          oc.Audience,
+			Scope:             oc.Scope,
+			RequestParameters: &oc.RequestParameters,
+		},
+	}
+}
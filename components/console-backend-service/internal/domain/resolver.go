@@ -122,7 +122,7 @@ func New(kubeClient kubernetes.Interface, restConfig *rest.Config, appCfg applic
 	agResolver := apigateway.New(genericServiceFactory)
 	makePluggable(agResolver)
 
-	serverlessResolver, err := serverless.New(serviceFactory, serverlessCfg, scaContainer.ServiceCatalogAddonsRetriever)
+	serverlessResolver, err := serverless.New(serviceFactory, kubeClient.CoreV1(), serverlessCfg, scaContainer.ServiceCatalogAddonsRetriever)
 	if err != nil {
 		return nil, errors.Wrap(err, "while initializing serverless resolver")
 	}
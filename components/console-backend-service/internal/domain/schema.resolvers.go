@@ -581,6 +581,10 @@ func (r *subscriptionResolver) FunctionEvent(ctx context.Context, namespace stri
 	return r.serverless.FunctionEventSubscription(ctx, namespace, functionName)
 }
 
+func (r *subscriptionResolver) FunctionBuildLog(ctx context.Context, namespace string, functionName string) (<-chan string, error) {
+	return r.serverless.FunctionBuildLogSubscription(ctx, namespace, functionName)
+}
+
 // Application returns gqlschema.ApplicationResolver implementation.
 func (r *Resolver) Application() gqlschema.ApplicationResolver { return &applicationResolver{r} }
 
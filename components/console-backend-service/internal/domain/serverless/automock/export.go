@@ -7,3 +7,7 @@ func NewFunctionService() *functionSvc {
 func NewGQLFunctionConverter() *gqlFunctionConverter {
 	return new(gqlFunctionConverter)
 }
+
+func NewBuildLogService() *buildLogSvc {
+	return new(buildLogSvc)
+}
@@ -0,0 +1,61 @@
+package serverless
+
+import (
+	"bufio"
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/kyma-project/kyma/components/console-backend-service/internal/domain/serverless/pretty"
+	"github.com/kyma-project/kyma/components/console-backend-service/internal/gqlerror"
+)
+
+const maxBuildLogLineSize = 1 << 20
+
+type buildLogResolver struct {
+	buildLogService buildLogSvc
+}
+
+func newBuildLogResolver(buildLogService buildLogSvc) *buildLogResolver {
+	return &buildLogResolver{
+		buildLogService: buildLogService,
+	}
+}
+
+// FunctionBuildLogSubscription sends the log of the latest build of the Function line by line. The channel is closed
+// when the build container terminates or the subscription is cancelled.
+func (r *buildLogResolver) FunctionBuildLogSubscription(ctx context.Context, namespace string, functionName string) (<-chan string, error) {
+	stream, err := r.buildLogService.Stream(ctx, namespace, functionName)
+	if err != nil {
+		glog.Error(errors.Wrapf(err, "while streaming build log of %s [name: %s, namespace: %s]", pretty.Function, functionName, namespace))
+		return nil, gqlerror.New(err, pretty.Function, gqlerror.WithName(functionName), gqlerror.WithNamespace(namespace))
+	}
+
+	// closing the stream unblocks the reader when the subscription is cancelled while no log lines are written
+	go func() {
+		<-ctx.Done()
+		stream.Close()
+	}()
+
+	channel := make(chan string, 1)
+	go func() {
+		defer close(channel)
+		defer stream.Close()
+
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(nil, maxBuildLogLineSize)
+		for scanner.Scan() {
+			select {
+			case channel <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			glog.Error(errors.Wrapf(err, "while reading build log of %s [name: %s, namespace: %s]", pretty.Function, functionName, namespace))
+		}
+	}()
+
+	return channel, nil
+}
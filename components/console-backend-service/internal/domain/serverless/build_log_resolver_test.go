@@ -0,0 +1,118 @@
+package serverless
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kyma-project/kyma/components/console-backend-service/internal/domain/serverless/automock"
+	"github.com/kyma-project/kyma/components/console-backend-service/internal/gqlerror"
+)
+
+func TestBuildLogResolver_FunctionBuildLogSubscription(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		svc := automock.NewBuildLogService()
+		svc.On("Stream", mock.Anything, "a", "1").Return(ioutil.NopCloser(strings.NewReader("INFO[0000] Retrieving image\nnpm ERR! code E404\n")), nil).Once()
+		defer svc.AssertExpectations(t)
+
+		resolver := newBuildLogResolver(svc)
+
+		channel, err := resolver.FunctionBuildLogSubscription(context.Background(), "a", "1")
+		require.NoError(t, err)
+
+		var lines []string
+		for line := range channel {
+			lines = append(lines, line)
+		}
+		assert.Equal(t, []string{"INFO[0000] Retrieving image", "npm ERR! code E404"}, lines)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		reader, writer := io.Pipe()
+		defer writer.Close()
+
+		svc := automock.NewBuildLogService()
+		svc.On("Stream", mock.Anything, "a", "1").Return(reader, nil).Once()
+		defer svc.AssertExpectations(t)
+
+		resolver := newBuildLogResolver(svc)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		channel, err := resolver.FunctionBuildLogSubscription(ctx, "a", "1")
+		require.NoError(t, err)
+
+		go func() {
+			_, _ = writer.Write([]byte("first line\nsecond line\n"))
+		}()
+		assert.Equal(t, "first line", <-channel)
+		cancel()
+
+		select {
+		case <-drain(channel):
+		case <-time.After(time.Second):
+			t.Fatal("channel was not closed after cancelling the subscription")
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		svc := automock.NewBuildLogService()
+		svc.On("Stream", mock.Anything, "a", "1").Return(nil, errors.New("Error")).Once()
+		defer svc.AssertExpectations(t)
+
+		resolver := newBuildLogResolver(svc)
+
+		_, err := resolver.FunctionBuildLogSubscription(context.Background(), "a", "1")
+		require.Error(t, err)
+		assert.True(t, gqlerror.IsInternal(err))
+	})
+}
+
+func TestLogContainer(t *testing.T) {
+	terminated := func(name string, exitCode int32) v1.ContainerStatus {
+		return v1.ContainerStatus{
+			Name:  name,
+			State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: exitCode}},
+		}
+	}
+
+	t.Run("BuildContainer", func(t *testing.T) {
+		pod := v1.Pod{Status: v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{terminated("repo-fetcher", 0)}}}
+		assert.Equal(t, "executor", logContainer(pod))
+	})
+
+	t.Run("FailedInitContainer", func(t *testing.T) {
+		pod := v1.Pod{Status: v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{terminated("repo-fetcher", 128)}}}
+		assert.Equal(t, "repo-fetcher", logContainer(pod))
+	})
+}
+
+func TestLatestPod(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	pods := []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "first", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "latest", CreationTimestamp: metav1.NewTime(now)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "second", CreationTimestamp: metav1.NewTime(now.Add(-time.Minute))}},
+	}
+
+	assert.Equal(t, "latest", latestPod(pods).Name)
+}
+
+func drain(channel <-chan string) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range channel {
+		}
+	}()
+	return done
+}
@@ -0,0 +1,72 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// jobNameLabel is set by Kubernetes on the Pods of a Job, it distinguishes build Pods from Function Pods
+	jobNameLabel       = "job-name"
+	buildContainerName = "executor"
+)
+
+//go:generate mockery -name=buildLogSvc -output=automock -outpkg=automock -case=underscore
+type buildLogSvc interface {
+	Stream(ctx context.Context, namespace, functionName string) (io.ReadCloser, error)
+}
+
+type buildLogService struct {
+	client corev1.CoreV1Interface
+}
+
+func newBuildLogService(client corev1.CoreV1Interface) *buildLogService {
+	return &buildLogService{
+		client: client,
+	}
+}
+
+// Stream follows the log of the latest build of the Function until the build container terminates
+func (svc *buildLogService) Stream(ctx context.Context, namespace, functionName string) (io.ReadCloser, error) {
+	pods, err := svc.client.Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s", v1alpha1.FunctionNameLabel, functionName, jobNameLabel),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "while listing build pods of function %s", functionName)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("function %s has no build pods", functionName)
+	}
+
+	pod := latestPod(pods.Items)
+	return svc.client.Pods(namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container: logContainer(pod),
+		Follow:    true,
+	}).Stream(ctx)
+}
+
+func latestPod(pods []v1.Pod) v1.Pod {
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+	return pods[0]
+}
+
+// logContainer returns the failed init container of the Pod, as the build container does not start when the repository
+// of a git Function cannot be fetched, and the build container otherwise
+func logContainer(pod v1.Pod) string {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return status.Name
+		}
+	}
+	return buildContainerName
+}
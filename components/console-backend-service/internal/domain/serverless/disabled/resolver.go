@@ -45,6 +45,15 @@ func (_m *Resolver) DeleteManyFunctions(ctx context.Context, namespace string, f
 	return r0, r1
 }
 
+// FunctionBuildLogSubscription provides a failing mock function with given fields: ctx, namespace, functionName
+func (_m *Resolver) FunctionBuildLogSubscription(ctx context.Context, namespace string, functionName string) (<-chan string, error) {
+	var r0 <-chan string
+	var r1 error
+	r1 = _m.err
+
+	return r0, r1
+}
+
 // FunctionEventSubscription provides a failing mock function with given fields: ctx, namespace, functionName
 func (_m *Resolver) FunctionEventSubscription(ctx context.Context, namespace string, functionName *string) (<-chan *gqlschema.FunctionEvent, error) {
 	var r0 <-chan *gqlschema.FunctionEvent
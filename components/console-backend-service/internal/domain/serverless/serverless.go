@@ -3,6 +3,8 @@ package serverless
 import (
 	"context"
 
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
 	"github.com/kyma-project/kyma/components/console-backend-service/internal/domain/shared"
 
 	"github.com/kyma-project/kyma/components/console-backend-service/internal/domain/serverless/disabled"
@@ -21,9 +23,10 @@ type PluggableContainer struct {
 
 	Resolver
 	serviceFactory *resource.ServiceFactory
+	coreClient     corev1.CoreV1Interface
 }
 
-func New(serviceFactory *resource.ServiceFactory, cfg Config, scaRetriever shared.ServiceCatalogAddonsRetriever) (*PluggableContainer, error) {
+func New(serviceFactory *resource.ServiceFactory, coreClient corev1.CoreV1Interface, cfg Config, scaRetriever shared.ServiceCatalogAddonsRetriever) (*PluggableContainer, error) {
 	resolver := &PluggableContainer{
 		Pluggable: module.NewPluggable("serverless"),
 		cfg: &resolverConfig{
@@ -31,6 +34,7 @@ func New(serviceFactory *resource.ServiceFactory, cfg Config, scaRetriever share
 			scaRetriever: scaRetriever,
 		},
 		serviceFactory: serviceFactory,
+		coreClient:     coreClient,
 	}
 
 	err := resolver.Disable()
@@ -44,10 +48,12 @@ func New(serviceFactory *resource.ServiceFactory, cfg Config, scaRetriever share
 func (r *PluggableContainer) Enable() error {
 	functionService := newFunctionService(r.serviceFactory)
 	functionConverter := newFunctionConverter()
+	buildLogService := newBuildLogService(r.coreClient)
 
 	r.Pluggable.EnableAndSyncDynamicInformerFactory(r.serviceFactory.InformerFactory, func() {
 		r.Resolver = &domainResolver{
 			functionResolver: newFunctionResolver(functionService, functionConverter, r.cfg.cfg, r.cfg.scaRetriever),
+			buildLogResolver: newBuildLogResolver(buildLogService),
 		}
 	})
 
@@ -78,8 +84,10 @@ type Resolver interface {
 	DeleteManyFunctions(ctx context.Context, namespace string, functions []*gqlschema.FunctionMetadataInput) ([]*gqlschema.FunctionMetadata, error)
 
 	FunctionEventSubscription(ctx context.Context, namespace string, functionName *string) (<-chan *gqlschema.FunctionEvent, error)
+	FunctionBuildLogSubscription(ctx context.Context, namespace string, functionName string) (<-chan string, error)
 }
 
 type domainResolver struct {
 	*functionResolver
+	*buildLogResolver
 }
@@ -1177,6 +1177,7 @@ type ComplexityRoot struct {
 		ClusterServiceBrokerEvent       func(childComplexity int) int
 		ConfigMapEvent                  func(childComplexity int, namespace string) int
 		DeploymentEvent                 func(childComplexity int, namespace string) int
+		FunctionBuildLog                func(childComplexity int, namespace string, functionName string) int
 		FunctionEvent                   func(childComplexity int, namespace string, functionName *string) int
 		NamespaceEvent                  func(childComplexity int, withSystemNamespaces *bool) int
 		OAuth2ClientEvent               func(childComplexity int, namespace string) int
@@ -1500,6 +1501,7 @@ type SubscriptionResolver interface {
 	AddonsConfigurationEvent(ctx context.Context, namespace string) (<-chan *AddonsConfigurationEvent, error)
 	NamespaceEvent(ctx context.Context, withSystemNamespaces *bool) (<-chan *NamespaceEvent, error)
 	FunctionEvent(ctx context.Context, namespace string, functionName *string) (<-chan *FunctionEvent, error)
+	FunctionBuildLog(ctx context.Context, namespace string, functionName string) (<-chan string, error)
 	APIRuleEvent(ctx context.Context, namespace string, serviceName *string) (<-chan *APIRuleEvent, error)
 	SubscriptionSubscription(ctx context.Context, ownerName string, namespace string) (<-chan *SubscriptionEvent, error)
 	TriggerEvent(ctx context.Context, namespace string, serviceName string) (<-chan *TriggerEvent, error)
@@ -6901,6 +6903,18 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Subscription.DeploymentEvent(childComplexity, args["namespace"].(string)), true
 
+	case "Subscription.functionBuildLog":
+		if e.complexity.Subscription.FunctionBuildLog == nil {
+			break
+		}
+
+		args, err := ec.field_Subscription_functionBuildLog_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Subscription.FunctionBuildLog(childComplexity, args["namespace"].(string), args["functionName"].(string)), true
+
 	case "Subscription.functionEvent":
 		if e.complexity.Subscription.FunctionEvent == nil {
 			break
@@ -9081,6 +9095,7 @@ type Subscription {
     namespaceEvent(withSystemNamespaces: Boolean): NamespaceEvent! @HasAccess(attributes: {resource: "namespaces", verb: "watch", apiGroup: "", apiVersion: "v1"})
 
     functionEvent(namespace: String!, functionName: String): FunctionEvent! @HasAccess(attributes: {resource: "functions", verb: "watch", apiGroup: "serverless.kyma-project.io", apiVersion: "v1alpha1", namespaceArg: "namespace"})
+    functionBuildLog(namespace: String!, functionName: String!): String! @HasAccess(attributes: {resource: "pods", subresource: "log", verb: "get", apiVersion: "v1", namespaceArg: "namespace"})
 }
 
 # Schema
@@ -12367,6 +12382,28 @@ func (ec *executionContext) field_Subscription_deploymentEvent_args(ctx context.
 	return args, nil
 }
 
+func (ec *executionContext) field_Subscription_functionBuildLog_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["namespace"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["namespace"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["functionName"]; ok {
+		arg1, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["functionName"] = arg1
+	return args, nil
+}
+
 func (ec *executionContext) field_Subscription_functionEvent_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -39808,6 +39845,81 @@ func (ec *executionContext) _Subscription_functionEvent(ctx context.Context, fie
 	}
 }
 
+func (ec *executionContext) _Subscription_functionBuildLog(ctx context.Context, field graphql.CollectedField) (ret func() graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = nil
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Subscription",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Subscription_functionBuildLog_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return nil
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Subscription().FunctionBuildLog(rctx, args["namespace"].(string), args["functionName"].(string))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			attributes, err := ec.unmarshalNResourceAttributes2githubᚗcomᚋkymaᚑprojectᚋkymaᚋcomponentsᚋconsoleᚑbackendᚑserviceᚋinternalᚋgqlschemaᚐResourceAttributes(ctx, map[string]interface{}{"apiVersion": "v1", "namespaceArg": "namespace", "resource": "pods", "subresource": "log", "verb": "get"})
+			if err != nil {
+				return nil, err
+			}
+			if ec.directives.HasAccess == nil {
+				return nil, errors.New("directive HasAccess is not implemented")
+			}
+			return ec.directives.HasAccess(ctx, nil, directive0, attributes)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(<-chan string); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be <-chan string`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return nil
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return nil
+	}
+	return func() graphql.Marshaler {
+		res, ok := <-resTmp.(<-chan string)
+		if !ok {
+			return nil
+		}
+		return graphql.WriterFunc(func(w io.Writer) {
+			w.Write([]byte{'{'})
+			graphql.MarshalString(field.Alias).MarshalGQL(w)
+			w.Write([]byte{':'})
+			ec.marshalNString2string(ctx, field.Selections, res).MarshalGQL(w)
+			w.Write([]byte{'}'})
+		})
+	}
+}
+
 func (ec *executionContext) _Subscription_apiRuleEvent(ctx context.Context, field graphql.CollectedField) (ret func() graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -50226,6 +50338,8 @@ func (ec *executionContext) _Subscription(ctx context.Context, sel ast.Selection
 		return ec._Subscription_namespaceEvent(ctx, fields[0])
 	case "functionEvent":
 		return ec._Subscription_functionEvent(ctx, fields[0])
+	case "functionBuildLog":
+		return ec._Subscription_functionBuildLog(ctx, fields[0])
 	case "apiRuleEvent":
 		return ec._Subscription_apiRuleEvent(ctx, fields[0])
 	case "subscriptionSubscription":
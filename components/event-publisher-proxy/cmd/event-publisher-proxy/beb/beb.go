@@ -5,6 +5,7 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/dedup"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/env"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/handler/beb"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/informers"
@@ -16,6 +17,7 @@ import (
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/sender"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/signals"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/subscribed"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
 	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/dynamic"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -95,9 +97,19 @@ func (c *Commander) Start() error {
 	informers.WaitForCacheSyncOrDie(ctx, subDynamicSharedInfFactory)
 	c.logger.Info("Informers are synced successfully")
 
+	// configure payload validator
+	payloadValidator := validation.NewValidator(c.envCfg.EventTypePrefix, applicationLister, dynamicClient,
+		c.envCfg.PayloadSchemaCacheTTL, c.logger)
+
 	// start handler which blocks until it receives a shutdown signal
-	if err := beb.NewHandler(messageReceiver, messageSender, c.envCfg.RequestTimeout, legacyTransformer, c.opts,
-		subscribedProcessor, c.logger, c.metricsCollector).Start(ctx); err != nil {
+	h := beb.NewHandler(messageReceiver, messageSender, c.envCfg.RequestTimeout, legacyTransformer, c.opts,
+		subscribedProcessor, c.logger, c.metricsCollector)
+	h.PayloadValidator = payloadValidator
+	if c.envCfg.DeduplicationWindow > 0 {
+		store := dedup.NewMemoryStore(c.envCfg.DeduplicationWindow, c.envCfg.DeduplicationMaxEntries)
+		h.Deduplicator = dedup.NewDeduplicator(store, c.logger)
+	}
+	if err := h.Start(ctx); err != nil {
 		c.logger.Errorf("Start handler failed with error: %s", err)
 		return err
 	}
@@ -0,0 +1,146 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/dedup"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/env"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/handler/nats"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/informers"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/metrics"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/options"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/receiver"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/sender"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/signals"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/subscribed"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/dynamic"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// Commander implements the Commander interface.
+type Commander struct {
+	cancel           context.CancelFunc
+	metricsCollector *metrics.Collector
+	logger           *logrus.Logger
+	envCfg           *env.KafkaConfig
+	opts             *options.Options
+}
+
+// NewCommander creates the Commander for publisher to Kafka.
+func NewCommander(opts *options.Options, metricsCollector *metrics.Collector, logger *logrus.Logger) *Commander {
+	return &Commander{
+		envCfg:           new(env.KafkaConfig),
+		logger:           logger,
+		metricsCollector: metricsCollector,
+		opts:             opts,
+	}
+}
+
+// Init implements the Commander interface and initializes the publisher to Kafka.
+func (c *Commander) Init() error {
+	if err := envconfig.Process("", c.envCfg); err != nil {
+		c.logger.Errorf("Read Kafka configuration failed with error: %s", err)
+		return err
+	}
+	return nil
+}
+
+// Start implements the Commander interface and starts the publisher.
+func (c *Commander) Start() error {
+	c.logger.Infof("Starting Event Publisher to Kafka, envCfg: %v; opts: %#v", c.envCfg.String(), c.opts)
+
+	// assure uniqueness
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(signals.NewContext())
+
+	// configure message receiver
+	messageReceiver := receiver.NewHttpMessageReceiver(c.envCfg.Port)
+
+	// configure message sender, which waits until all in-sync replicas have stored an event
+	producer, err := sarama.NewSyncProducer(c.envCfg.Brokers, c.newProducerConfig())
+	if err != nil {
+		c.logger.Errorf("Failed to connect to the Kafka brokers with error: %s", err)
+		return err
+	}
+	defer func() {
+		if err := producer.Close(); err != nil {
+			c.logger.Errorf("Failed to close the Kafka producer with error: %s", err)
+		}
+	}()
+	messageSenderToKafka := sender.NewKafkaMessageSender(producer, c.envCfg.Topic, c.logger)
+
+	// cluster config
+	k8sConfig := config.GetConfigOrDie()
+
+	// setup application lister
+	dynamicClient := dynamic.NewForConfigOrDie(k8sConfig)
+	applicationLister := application.NewLister(ctx, dynamicClient)
+
+	// configure legacyTransformer
+	legacyTransformer := legacy.NewTransformer(
+		c.envCfg.ToConfig().BEBNamespace,
+		c.envCfg.ToConfig().EventTypePrefix,
+		applicationLister,
+	)
+
+	// configure Subscription Lister
+	subDynamicSharedInfFactory := subscribed.GenerateSubscriptionInfFactory(k8sConfig)
+	subLister := subDynamicSharedInfFactory.ForResource(subscribed.GVR).Lister()
+	subscribedProcessor := &subscribed.Processor{
+		SubscriptionLister: &subLister,
+		Config:             c.envCfg.ToConfig(),
+		Logger:             c.logger,
+	}
+
+	// sync informer cache or die
+	c.logger.Info("Waiting for informers caches to sync")
+	informers.WaitForCacheSyncOrDie(ctx, subDynamicSharedInfFactory)
+	c.logger.Info("Informers are synced successfully")
+
+	// configure payload validator
+	payloadValidator := validation.NewValidator(c.envCfg.ToConfig().EventTypePrefix, applicationLister, dynamicClient,
+		c.envCfg.PayloadSchemaCacheTTL, c.logger)
+
+	// start handler which blocks until it receives a shutdown signal, the events are handled the same way as
+	// for NATS
+	h := nats.NewHandler(messageReceiver, messageSenderToKafka, c.envCfg.RequestTimeout, legacyTransformer, c.opts,
+		subscribedProcessor, c.logger, c.metricsCollector)
+	h.PayloadValidator = payloadValidator
+	if c.envCfg.DeduplicationWindow > 0 {
+		store := dedup.NewMemoryStore(c.envCfg.DeduplicationWindow, c.envCfg.DeduplicationMaxEntries)
+		h.Deduplicator = dedup.NewDeduplicator(store, c.logger)
+	}
+	if err := h.Start(ctx); err != nil {
+		c.logger.Errorf("Start handler failed with error: %s", err)
+		return err
+	}
+
+	c.logger.Info("Event Publisher Kafka shutdown")
+
+	return nil
+}
+
+// newProducerConfig returns the config of the Kafka producer. The default partitioner hashes the key of a record,
+// so the events of a type are appended to the same partition.
+func (c *Commander) newProducerConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.ClientID = c.envCfg.ClientID
+	config.Version = sarama.V2_0_0_0
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Timeout = c.envCfg.RequestTimeout
+	config.Producer.Return.Successes = true
+	return config
+}
+
+// Stop implements the Commander interface and stops the publisher.
+func (c *Commander) Stop() error {
+	c.cancel()
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/kelseyhightower/envconfig"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/cmd/event-publisher-proxy/beb"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/cmd/event-publisher-proxy/kafka"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/cmd/event-publisher-proxy/nats"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/metrics"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/options"
@@ -11,12 +12,13 @@ import (
 )
 
 const (
-	backendBEB  = "beb"
-	backendNATS = "nats"
+	backendBEB   = "beb"
+	backendNATS  = "nats"
+	backendKafka = "kafka"
 )
 
 type Config struct {
-	// Backend used for Eventing. It could be "nats" or "beb"
+	// Backend used for Eventing. It could be "nats", "beb" or "kafka"
 	Backend string `envconfig:"BACKEND" required:"true"`
 }
 
@@ -53,6 +55,8 @@ func main() {
 		commander = beb.NewCommander(opts, metricsCollector, logger)
 	case backendNATS:
 		commander = nats.NewCommander(opts, metricsCollector, logger)
+	case backendKafka:
+		commander = kafka.NewCommander(opts, metricsCollector, logger)
 	default:
 		logger.Fatalf("Invalid publisher backend: %v", cfg.Backend)
 	}
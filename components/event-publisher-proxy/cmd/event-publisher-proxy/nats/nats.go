@@ -2,9 +2,11 @@ package nats
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/dedup"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/env"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/handler/nats"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/informers"
@@ -16,6 +18,7 @@ import (
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/sender"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/signals"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/subscribed"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
 	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/dynamic"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -70,7 +73,11 @@ func (c *Commander) Start() error {
 	defer bc.Connection.Close()
 
 	// configure message sender
-	messageSenderToNats := sender.NewNatsMessageSender(ctx, bc, c.logger)
+	messageSenderToNats, err := c.newMessageSender(ctx, bc)
+	if err != nil {
+		c.logger.Errorf("Failed to create the message sender with error: %s", err)
+		return err
+	}
 
 	// cluster config
 	k8sConfig := config.GetConfigOrDie()
@@ -100,9 +107,19 @@ func (c *Commander) Start() error {
 	informers.WaitForCacheSyncOrDie(ctx, subDynamicSharedInfFactory)
 	c.logger.Info("Informers are synced successfully")
 
+	// configure payload validator
+	payloadValidator := validation.NewValidator(c.envCfg.ToConfig().EventTypePrefix, applicationLister, dynamicClient,
+		c.envCfg.PayloadSchemaCacheTTL, c.logger)
+
 	// start handler which blocks until it receives a shutdown signal
-	if err := nats.NewHandler(messageReceiver, messageSenderToNats, c.envCfg.RequestTimeout, legacyTransformer, c.opts,
-		subscribedProcessor, c.logger, c.metricsCollector).Start(ctx); err != nil {
+	h := nats.NewHandler(messageReceiver, messageSenderToNats, c.envCfg.RequestTimeout, legacyTransformer, c.opts,
+		subscribedProcessor, c.logger, c.metricsCollector)
+	h.PayloadValidator = payloadValidator
+	if c.envCfg.DeduplicationWindow > 0 && c.envCfg.DeduplicationStore == dedup.StoreMemory {
+		store := dedup.NewMemoryStore(c.envCfg.DeduplicationWindow, c.envCfg.DeduplicationMaxEntries)
+		h.Deduplicator = dedup.NewDeduplicator(store, c.logger)
+	}
+	if err := h.Start(ctx); err != nil {
 		c.logger.Errorf("Start handler failed with error: %s", err)
 		return err
 	}
@@ -112,6 +129,29 @@ func (c *Commander) Start() error {
 	return nil
 }
 
+// newMessageSender creates the message sender for the configured delivery mode. With the "nats" deduplication
+// store, the duplicates are dropped by the NATS stream which persists the events.
+func (c *Commander) newMessageSender(ctx context.Context, bc *pkgnats.BackendConnection) (*sender.NatsMessageSender, error) {
+	if c.envCfg.DeduplicationWindow > 0 {
+		switch c.envCfg.DeduplicationStore {
+		case dedup.StoreMemory:
+		case dedup.StoreNats:
+			if !c.envCfg.IsPersistent() {
+				return nil, fmt.Errorf("deduplication store %q requires the delivery mode %q", dedup.StoreNats,
+					env.NatsDeliveryModeAtLeastOnce)
+			}
+			return sender.NewDeduplicatingNatsMessageSender(ctx, bc, c.logger, c.metricsCollector.RecordDuplicate), nil
+		default:
+			return nil, fmt.Errorf("invalid deduplication store %q, must be %q or %q", c.envCfg.DeduplicationStore,
+				dedup.StoreMemory, dedup.StoreNats)
+		}
+	}
+	if c.envCfg.IsPersistent() {
+		return sender.NewPersistentNatsMessageSender(ctx, bc, c.logger), nil
+	}
+	return sender.NewNatsMessageSender(ctx, bc, c.logger), nil
+}
+
 // Stop implements the Commander interface and stops the publisher.
 func (c *Commander) Stop() error {
 	c.cancel()
@@ -3,6 +3,7 @@ module github.com/kyma-project/kyma/components/event-publisher-proxy
 go 1.15
 
 require (
+	github.com/Shopify/sarama v1.27.2
 	github.com/cloudevents/sdk-go/protocol/nats/v2 v2.3.1
 	github.com/cloudevents/sdk-go/v2 v2.3.1
 	github.com/google/uuid v1.1.2
@@ -13,6 +14,7 @@ require (
 	github.com/nats-io/nats.go v1.11.0
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.9.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.0.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/stretchr/testify v1.7.0
 	go.opencensus.io v0.22.4
@@ -22,6 +24,7 @@ require (
 	k8s.io/apimachinery v0.20.7
 	k8s.io/client-go v0.20.7
 	sigs.k8s.io/controller-runtime v0.8.3
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
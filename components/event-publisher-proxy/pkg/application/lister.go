@@ -7,6 +7,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -50,6 +51,30 @@ func (l Lister) Get(name string) (*applicationv1alpha1.Application, error) {
 	return application, nil
 }
 
+// List returns all applications
+func (l Lister) List() ([]*applicationv1alpha1.Application, error) {
+	objects, err := l.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	applications := make([]*applicationv1alpha1.Application, 0, len(objects))
+	for _, object := range objects {
+		applicationUnstructured, ok := object.(*unstructured.Unstructured)
+		if !ok {
+			return nil, errors.New("failed to convert runtime object to unstructured")
+		}
+
+		application := &applicationv1alpha1.Application{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(applicationUnstructured.Object, application); err != nil {
+			return nil, err
+		}
+		applications = append(applications, application)
+	}
+
+	return applications, nil
+}
+
 func GroupVersionResource() schema.GroupVersionResource {
 	return schema.GroupVersionResource{
 		Group:    applicationv1alpha1.SchemeGroupVersion.Group,
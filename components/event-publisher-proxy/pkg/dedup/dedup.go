@@ -0,0 +1,131 @@
+// Package dedup drops duplicates of events, which have the same source and id as an event which was dispatched
+// within a time window, and answers them with the response of the original event.
+package dedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StoreMemory keeps the responses of the dispatched events in the memory of the publisher
+	StoreMemory = "memory"
+	// StoreNats publishes the events to the NATS stream with a message ID, which is the key of the event, so that the
+	// stream drops duplicates within its duplicates window for all replicas of the publisher
+	StoreNats = "nats"
+)
+
+// Result is the response of the dispatch of an event
+type Result struct {
+	StatusCode int    `json:"statusCode"`
+	Body       []byte `json:"body,omitempty"`
+}
+
+// IsSuccess returns true if the event was dispatched successfully
+func (r Result) IsSuccess() bool {
+	return r.StatusCode >= http.StatusOK && r.StatusCode < http.StatusMultipleChoices
+}
+
+// Store keeps the results of successfully dispatched events for the deduplication window
+type Store interface {
+	// Get returns the result of the event with the given key or nil if the event was not dispatched within the window
+	Get(ctx context.Context, key string) (*Result, error)
+	// Put stores the result of the event with the given key
+	Put(ctx context.Context, key string, result Result) error
+}
+
+// Deduplicator dispatches an event only if no event with the same source and id was dispatched successfully within
+// the deduplication window. Concurrent duplicates wait for the dispatch of the original event.
+// A nil Deduplicator dispatches every event.
+type Deduplicator struct {
+	store  Store
+	logger *logrus.Logger
+
+	inFlightLock sync.Mutex
+	// inFlight holds the events which are being dispatched by their key
+	inFlight map[string]*call
+}
+
+// call is the dispatch of an event which duplicates wait for
+type call struct {
+	done   chan struct{}
+	result Result
+}
+
+// NewDeduplicator returns a new Deduplicator which keeps the results of the dispatched events in the given store
+func NewDeduplicator(store Store, logger *logrus.Logger) *Deduplicator {
+	return &Deduplicator{
+		store:    store,
+		logger:   logger,
+		inFlight: make(map[string]*call),
+	}
+}
+
+// Key returns the key of an event, which is unique for the source and id of the event
+func Key(source, id string) string {
+	hash := sha256.Sum256([]byte(source + "\n" + id))
+	return hex.EncodeToString(hash[:])
+}
+
+// Do dispatches the event with the given source and id by calling send and returns its result. If the event is a
+// duplicate, it returns the result of the original event without calling send and true.
+func (d *Deduplicator) Do(ctx context.Context, source, id string, send func() Result) (Result, bool) {
+	if d == nil {
+		return send(), false
+	}
+
+	key := Key(source, id)
+	for {
+		d.inFlightLock.Lock()
+		c, ok := d.inFlight[key]
+		if !ok {
+			c = &call{done: make(chan struct{})}
+			d.inFlight[key] = c
+		}
+		d.inFlightLock.Unlock()
+
+		if !ok {
+			var duplicate bool
+			c.result, duplicate = d.dispatch(ctx, key, send)
+			d.inFlightLock.Lock()
+			delete(d.inFlight, key)
+			d.inFlightLock.Unlock()
+			close(c.done)
+			return c.result, duplicate
+		}
+
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return Result{StatusCode: http.StatusGatewayTimeout, Body: []byte(ctx.Err().Error())}, false
+		}
+		if c.result.IsSuccess() {
+			return c.result, true
+		}
+		// the dispatch of the original event failed, so the duplicate is dispatched again
+	}
+}
+
+// dispatch returns the stored result of the event or dispatches it and stores its result if it was successful
+func (d *Deduplicator) dispatch(ctx context.Context, key string, send func() Result) (Result, bool) {
+	stored, err := d.store.Get(ctx, key)
+	if err != nil {
+		d.logger.Warnf("Failed to look up event for deduplication with error: %s", err)
+	}
+	if stored != nil {
+		return *stored, true
+	}
+
+	result := send()
+	if result.IsSuccess() {
+		if err := d.store.Put(ctx, key, result); err != nil {
+			d.logger.Warnf("Failed to store event for deduplication with error: %s", err)
+		}
+	}
+	return result, false
+}
@@ -0,0 +1,132 @@
+package dedup
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDeduplicatorDo(t *testing.T) {
+	testCases := []struct {
+		name          string
+		givenSource   string
+		givenID       string
+		givenResult   Result
+		wantDuplicate bool
+		wantSends     int32
+	}{
+		{
+			name:          "same source and id",
+			givenSource:   "source",
+			givenID:       "id",
+			givenResult:   Result{StatusCode: http.StatusNoContent},
+			wantDuplicate: true,
+			wantSends:     1,
+		},
+		{
+			name:        "other id",
+			givenSource: "source",
+			givenID:     "other-id",
+			givenResult: Result{StatusCode: http.StatusNoContent},
+			wantSends:   2,
+		},
+		{
+			name:        "other source",
+			givenSource: "other-source",
+			givenID:     "id",
+			givenResult: Result{StatusCode: http.StatusNoContent},
+			wantSends:   2,
+		},
+		{
+			name:        "failed original",
+			givenSource: "source",
+			givenID:     "id",
+			givenResult: Result{StatusCode: http.StatusBadGateway},
+			wantSends:   2,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			deduplicator := NewDeduplicator(NewMemoryStore(time.Minute, 10), logrus.New())
+			var sends int32
+			send := func(result Result) func() Result {
+				return func() Result {
+					atomic.AddInt32(&sends, 1)
+					return result
+				}
+			}
+
+			original := tc.givenResult
+			original.Body = []byte("original")
+			if _, duplicate := deduplicator.Do(context.Background(), "source", "id", send(original)); duplicate {
+				t.Fatalf("expected the original event not to be a duplicate")
+			}
+			result, duplicate := deduplicator.Do(context.Background(), tc.givenSource, tc.givenID,
+				send(Result{StatusCode: http.StatusNoContent, Body: []byte("second")}))
+
+			if duplicate != tc.wantDuplicate {
+				t.Errorf("incorrect duplicate, want: %v, got: %v", tc.wantDuplicate, duplicate)
+			}
+			if sends != tc.wantSends {
+				t.Errorf("incorrect number of sends, want: %d, got: %d", tc.wantSends, sends)
+			}
+			if wantBody := map[bool]string{true: "original", false: "second"}[tc.wantDuplicate]; string(result.Body) != wantBody {
+				t.Errorf("incorrect result body, want: %s, got: %s", wantBody, result.Body)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorDoConcurrently(t *testing.T) {
+	deduplicator := NewDeduplicator(NewMemoryStore(time.Minute, 10), logrus.New())
+	release := make(chan struct{})
+	var sends, duplicates int32
+	send := func() Result {
+		atomic.AddInt32(&sends, 1)
+		<-release
+		return Result{StatusCode: http.StatusNoContent}
+	}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, duplicate := deduplicator.Do(context.Background(), "source", "id", send)
+			if result.StatusCode != http.StatusNoContent {
+				t.Errorf("incorrect status code, want: %d, got: %d", http.StatusNoContent, result.StatusCode)
+			}
+			if duplicate {
+				atomic.AddInt32(&duplicates, 1)
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if sends != 1 {
+		t.Errorf("incorrect number of sends, want: 1, got: %d", sends)
+	}
+	if duplicates != requests-1 {
+		t.Errorf("incorrect number of duplicates, want: %d, got: %d", requests-1, duplicates)
+	}
+}
+
+func TestNilDeduplicator(t *testing.T) {
+	var deduplicator *Deduplicator
+	for i := 0; i < 2; i++ {
+		if _, duplicate := deduplicator.Do(context.Background(), "source", "id", func() Result {
+			return Result{StatusCode: http.StatusNoContent}
+		}); duplicate {
+			t.Errorf("expected no duplicate")
+		}
+	}
+}
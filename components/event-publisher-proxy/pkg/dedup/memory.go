@@ -0,0 +1,92 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// compile time check
+var _ Store = &MemoryStore{}
+
+// MemoryStore keeps the results of the dispatched events in memory. It holds at most a maximum number of results,
+// when it is full the oldest result is evicted.
+type MemoryStore struct {
+	window     time.Duration
+	maxEntries int
+
+	lock sync.Mutex
+	// entries holds the elements of the order list by their key
+	entries map[string]*list.Element
+	// order holds the entries from the oldest to the newest, which is also the order of their expiry
+	order *list.List
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	result  Result
+	expires time.Time
+}
+
+// NewMemoryStore returns a new MemoryStore which keeps the results for the given window
+func NewMemoryStore(window time.Duration, maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		window:     window,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get implements the Store interface
+func (s *MemoryStore) Get(_ context.Context, key string) (*Result, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !s.now().Before(entry.expires) {
+		s.remove(element)
+		return nil, nil
+	}
+	result := entry.result
+	return &result, nil
+}
+
+// Put implements the Store interface
+func (s *MemoryStore) Put(_ context.Context, key string, result Result) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	s.entries[key] = s.order.PushBack(&memoryEntry{key: key, result: result, expires: now.Add(s.window)})
+
+	for s.order.Len() > 0 {
+		oldest := s.order.Front()
+		if s.order.Len() <= s.maxEntries && now.Before(oldest.Value.(*memoryEntry).expires) {
+			break
+		}
+		s.remove(oldest)
+	}
+	return nil
+}
+
+// Len returns the number of results in the store
+func (s *MemoryStore) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	delete(s.entries, element.Value.(*memoryEntry).key)
+	s.order.Remove(element)
+}
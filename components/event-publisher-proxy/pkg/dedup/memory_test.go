@@ -0,0 +1,50 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	store := NewMemoryStore(time.Minute, 10)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if err := store.Put(context.Background(), "key", Result{StatusCode: http.StatusNoContent}); err != nil {
+		t.Fatalf("failed to put result: %v", err)
+	}
+	if result, _ := store.Get(context.Background(), "key"); result == nil || result.StatusCode != http.StatusNoContent {
+		t.Errorf("expected result within the window, got: %v", result)
+	}
+
+	now = now.Add(time.Minute)
+	if result, _ := store.Get(context.Background(), "key"); result != nil {
+		t.Errorf("expected no result after the window, got: %v", result)
+	}
+	if store.Len() != 0 {
+		t.Errorf("expected expired result to be removed, got %d results", store.Len())
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	const maxEntries = 3
+	store := NewMemoryStore(time.Minute, maxEntries)
+	for i := 0; i < maxEntries+2; i++ {
+		if err := store.Put(context.Background(), fmt.Sprintf("key-%d", i), Result{StatusCode: http.StatusNoContent}); err != nil {
+			t.Fatalf("failed to put result: %v", err)
+		}
+	}
+
+	if store.Len() != maxEntries {
+		t.Errorf("incorrect number of results, want: %d, got: %d", maxEntries, store.Len())
+	}
+	for i, wantEvicted := range []bool{true, true, false, false, false} {
+		result, _ := store.Get(context.Background(), fmt.Sprintf("key-%d", i))
+		if evicted := result == nil; evicted != wantEvicted {
+			t.Errorf("incorrect eviction of key-%d, want: %v, got: %v", i, wantEvicted, evicted)
+		}
+	}
+}
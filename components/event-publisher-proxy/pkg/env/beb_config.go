@@ -24,6 +24,12 @@ type BebConfig struct {
 	// EventTypePrefix is the prefix of each event as per the eventing specification
 	// It follows the eventType format: <eventTypePrefix>.<appName>.<event-name>.<version>
 	EventTypePrefix string `envconfig:"EVENT_TYPE_PREFIX" default:""`
+	// PayloadSchemaCacheTTL is the duration for which the AsyncAPI specs of the applications are cached
+	PayloadSchemaCacheTTL time.Duration `envconfig:"PAYLOAD_SCHEMA_CACHE_TTL" default:"5m"`
+	// DeduplicationWindow is the duration within which duplicates of an event are dropped, 0 disables the deduplication
+	DeduplicationWindow time.Duration `envconfig:"DEDUPLICATION_WINDOW" default:"0s"`
+	// DeduplicationMaxEntries is the maximum number of events which are remembered for the deduplication
+	DeduplicationMaxEntries int `envconfig:"DEDUPLICATION_MAX_ENTRIES" default:"10000"`
 }
 
 // ConfigureTransport receives an HTTP transport and configure its max idle connection properties.
@@ -35,6 +41,8 @@ func (c *BebConfig) ConfigureTransport(transport *http.Transport) {
 // String implements the fmt.Stringer interface
 func (c *BebConfig) String() string {
 	return fmt.Sprintf("BebConfig{ Port: %v; TokenEndPoint: %v; EmsPublishURL: %v; "+
-		"MaxIdleConns: %v; MaxIdleConnsPerHost: %v; RequestTimeout: %v; BEBNamespace: %v; EventTypePrefix: %v }",
-		c.Port, c.TokenEndpoint, c.EmsPublishURL, c.MaxIdleConns, c.MaxIdleConnsPerHost, c.RequestTimeout, c.BEBNamespace, c.EventTypePrefix)
+		"MaxIdleConns: %v; MaxIdleConnsPerHost: %v; RequestTimeout: %v; BEBNamespace: %v; EventTypePrefix: %v; PayloadSchemaCacheTTL: %v; "+
+		"DeduplicationWindow: %v; DeduplicationMaxEntries: %v }",
+		c.Port, c.TokenEndpoint, c.EmsPublishURL, c.MaxIdleConns, c.MaxIdleConnsPerHost, c.RequestTimeout, c.BEBNamespace,
+		c.EventTypePrefix, c.PayloadSchemaCacheTTL, c.DeduplicationWindow, c.DeduplicationMaxEntries)
 }
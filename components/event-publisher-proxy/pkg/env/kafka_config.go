@@ -0,0 +1,44 @@
+package env
+
+import (
+	"fmt"
+	"time"
+)
+
+// compile time check
+var _ fmt.Stringer = &KafkaConfig{}
+
+// KafkaConfig represents the environment config for the Event Publisher to Kafka.
+type KafkaConfig struct {
+	Port           int           `envconfig:"INGRESS_PORT" default:"8080"`
+	Brokers        []string      `envconfig:"KAFKA_BROKERS" required:"true"`
+	ClientID       string        `envconfig:"KAFKA_CLIENT_ID" default:"event-publisher-proxy"`
+	Topic          string        `envconfig:"KAFKA_TOPIC" default:"kyma-eventing"`
+	RequestTimeout time.Duration `envconfig:"REQUEST_TIMEOUT" default:"5s"`
+
+	// Legacy Namespace is used as the event source for legacy events
+	LegacyNamespace string `envconfig:"LEGACY_NAMESPACE" default:"kyma"`
+	// LegacyEventTypePrefix is the prefix of each event as per the eventing specification, used for legacy events
+	// It follows the eventType format: <LegacyEventTypePrefix>.<appName>.<event-name>.<version>
+	LegacyEventTypePrefix string `envconfig:"LEGACY_EVENT_TYPE_PREFIX" default:"kyma"`
+	// PayloadSchemaCacheTTL is the duration for which the AsyncAPI specs of the applications are cached
+	PayloadSchemaCacheTTL time.Duration `envconfig:"PAYLOAD_SCHEMA_CACHE_TTL" default:"5m"`
+	// DeduplicationWindow is the duration within which duplicates of an event are dropped, 0 disables the deduplication
+	DeduplicationWindow time.Duration `envconfig:"DEDUPLICATION_WINDOW" default:"0s"`
+	// DeduplicationMaxEntries is the maximum number of events which are remembered for the deduplication
+	DeduplicationMaxEntries int `envconfig:"DEDUPLICATION_MAX_ENTRIES" default:"10000"`
+}
+
+// ToConfig converts to a default BEB BebConfig
+func (c *KafkaConfig) ToConfig() *BebConfig {
+	cfg := &BebConfig{
+		BEBNamespace:    c.LegacyNamespace,
+		EventTypePrefix: c.LegacyEventTypePrefix,
+	}
+	return cfg
+}
+
+// String implements the fmt.Stringer interface
+func (c *KafkaConfig) String() string {
+	return fmt.Sprintf("%#v", c)
+}
@@ -8,6 +8,11 @@ import (
 // compile time check
 var _ fmt.Stringer = &NatsConfig{}
 
+const (
+	// NatsDeliveryModeAtLeastOnce publishes the events to a NATS stream which persists them
+	NatsDeliveryModeAtLeastOnce = "AtLeastOnce"
+)
+
 // NatsConfig represents the environment config for the Event Publisher to NATS.
 type NatsConfig struct {
 	Port                 int           `envconfig:"INGRESS_PORT" default:"8080"`
@@ -16,12 +21,23 @@ type NatsConfig struct {
 	MaxReconnects        int           `envconfig:"MAX_RECONNECTS" default:"10"`
 	ReconnectWait        time.Duration `envconfig:"RECONNECT_WAIT" default:"5s"`
 	RequestTimeout       time.Duration `envconfig:"REQUEST_TIMEOUT" default:"5s"`
+	DeliveryMode         string        `envconfig:"NATS_DELIVERY_MODE" default:"AtMostOnce"`
 
 	// Legacy Namespace is used as the event source for legacy events
 	LegacyNamespace string `envconfig:"LEGACY_NAMESPACE" default:"kyma"`
 	// LegacyEventTypePrefix is the prefix of each event as per the eventing specification, used for legacy events
 	// It follows the eventType format: <LegacyEventTypePrefix>.<appName>.<event-name>.<version>
 	LegacyEventTypePrefix string `envconfig:"LEGACY_EVENT_TYPE_PREFIX" default:"kyma"`
+	// PayloadSchemaCacheTTL is the duration for which the AsyncAPI specs of the applications are cached
+	PayloadSchemaCacheTTL time.Duration `envconfig:"PAYLOAD_SCHEMA_CACHE_TTL" default:"5m"`
+	// DeduplicationWindow is the duration within which duplicates of an event are dropped, 0 disables the deduplication
+	DeduplicationWindow time.Duration `envconfig:"DEDUPLICATION_WINDOW" default:"0s"`
+	// DeduplicationMaxEntries is the maximum number of events which are remembered for the deduplication
+	DeduplicationMaxEntries int `envconfig:"DEDUPLICATION_MAX_ENTRIES" default:"10000"`
+	// DeduplicationStore is the store of the events which are remembered for the deduplication, either "memory"
+	// or "nats", which lets the NATS stream of the events drop duplicates within its duplicates window and requires
+	// the "AtLeastOnce" delivery mode
+	DeduplicationStore string `envconfig:"DEDUPLICATION_STORE" default:"memory"`
 }
 
 // ToConfig converts to a default BEB BebConfig
@@ -33,6 +49,11 @@ func (c *NatsConfig) ToConfig() *BebConfig {
 	return cfg
 }
 
+// IsPersistent returns true if the events are published to a NATS stream which persists them
+func (c *NatsConfig) IsPersistent() bool {
+	return c.DeliveryMode == NatsDeliveryModeAtLeastOnce
+}
+
 // String implements the fmt.Stringer interface
 func (c *NatsConfig) String() string {
 	return fmt.Sprintf("%#v", c)
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	cev2http "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/sender"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
+)
+
+// requestBodyTooLargeErrorMessage is the error of reading a request body beyond the limit of http.MaxBytesReader
+const requestBodyTooLargeErrorMessage = "http: request body too large"
+
+// ErrBatchTooLarge is returned if a batch holds more events than allowed.
+var ErrBatchTooLarge = errors.New("batch holds too many events")
+
+// BatchResponse is the response of a request in the batched content mode,
+// it holds the response of every event in the order of the batch.
+type BatchResponse struct {
+	Events []BatchEventResponse `json:"events"`
+}
+
+// BatchEventResponse is the response of a single event of a batch.
+type BatchEventResponse struct {
+	ID         string `json:"id,omitempty"`
+	StatusCode int    `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// StatusCode returns the HTTP status code of the batch response,
+// which is 207 Multi-Status if any event of the batch was not dispatched.
+func (r *BatchResponse) StatusCode() int {
+	for _, event := range r.Events {
+		if event.StatusCode < http.StatusOK || event.StatusCode >= http.StatusMultipleChoices {
+			return http.StatusMultiStatus
+		}
+	}
+	return http.StatusOK
+}
+
+// IsABatchRequest returns true if the request holds CloudEvents in the batched content mode.
+func IsABatchRequest(request *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get(cev2http.ContentType))
+	return err == nil && mediaType == cev2event.ApplicationCloudEventsBatchJSON
+}
+
+// PublishBatch reads the CloudEvents of a request in the batched content mode, validates them and dispatches
+// the valid ones through the sender in the order of the batch. It returns an error if the request body is not
+// a JSON array and ErrBatchTooLarge if the batch holds more than maxBatchSize events, zero means no limit.
+func PublishBatch(ctx context.Context, request *http.Request, s sender.GenericSender, maxBatchSize int) (*BatchResponse, error) {
+	var rawEvents []json.RawMessage
+	if err := json.NewDecoder(request.Body).Decode(&rawEvents); err != nil {
+		return nil, err
+	}
+	if maxBatchSize > 0 && len(rawEvents) > maxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	response := &BatchResponse{Events: make([]BatchEventResponse, 0, len(rawEvents))}
+	for _, rawEvent := range rawEvents {
+		event := cev2event.New()
+		if err := json.Unmarshal(rawEvent, &event); err != nil {
+			response.Events = append(response.Events, BatchEventResponse{StatusCode: http.StatusBadRequest, Error: err.Error()})
+			continue
+		}
+		if err := event.Validate(); err != nil {
+			response.Events = append(response.Events, BatchEventResponse{ID: event.ID(), StatusCode: http.StatusBadRequest, Error: err.Error()})
+			continue
+		}
+		response.Events = append(response.Events, SendBatchEvent(ctx, s, &event))
+	}
+	return response, nil
+}
+
+// SendBatchEvent dispatches a single event of a batch through the sender. The event is not dispatched once the
+// context of the batch is done.
+func SendBatchEvent(ctx context.Context, s sender.GenericSender, event *cev2event.Event) BatchEventResponse {
+	if err := ctx.Err(); err != nil {
+		return BatchEventResponse{ID: event.ID(), StatusCode: http.StatusGatewayTimeout, Error: fmt.Sprintf("batch not dispatched in time: %s", err)}
+	}
+	statusCode, err := s.Send(ctx, event)
+	response := BatchEventResponse{ID: event.ID(), StatusCode: statusCode}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	return response
+}
+
+// BatchPublisher publishes the events of batch requests for the NATS and the BEB handlers. The events are dispatched
+// one after the other, each of them with its own request timeout, and all of them within the batch timeout.
+type BatchPublisher struct {
+	// Send dispatches a single event and returns the status code, the dispatch time and the response body
+	Send func(ctx context.Context, event *cev2event.Event) (int, time.Duration, []byte)
+	// PayloadValidator validates the data of events against their schemas, it is optional
+	PayloadValidator *validation.Validator
+	// LegacyTransformer handles transformations needed to handle legacy events
+	LegacyTransformer *legacy.Transformer
+	// RequestTimeout timeout for outgoing requests of a single event
+	RequestTimeout time.Duration
+	// MaxBatchSize is the maximum number of events of a batch, zero means no limit
+	MaxBatchSize int
+	// BatchTimeout is the maximum duration of dispatching all events of a batch, zero means no limit
+	BatchTimeout time.Duration
+	// Logger default logger
+	Logger *logrus.Logger
+}
+
+// PublishCloudEventBatch publishes the CloudEvents of a request in the batched content mode and writes the response
+// of every event.
+func (p *BatchPublisher) PublishCloudEventBatch(writer http.ResponseWriter, request *http.Request) {
+	// the events of a batch are in the structured content mode
+	ctx, cancel := p.batchContext(binding.WithForceStructured(request.Context()))
+	defer cancel()
+
+	response, err := PublishBatch(ctx, request, sender.GenericSenderFunc(p.sendEvent), p.MaxBatchSize)
+	if err != nil {
+		p.Logger.Warnf("Failed to extract events from batch request with error: %s", err)
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, ErrBatchTooLarge) || err.Error() == requestBodyTooLargeErrorMessage {
+			statusCode = http.StatusRequestEntityTooLarge
+		}
+		p.writeResponse(writer, statusCode, []byte(err.Error()))
+		return
+	}
+
+	respBody, err := json.Marshal(response)
+	if err != nil {
+		p.Logger.Errorf("Failed to marshal batch response with error: %s", err)
+		p.writeResponse(writer, http.StatusInternalServerError, []byte(err.Error()))
+		return
+	}
+	writer.Header().Set(cev2http.ContentType, legacy.ContentTypeApplicationJSON)
+	p.writeResponse(writer, response.StatusCode(), respBody)
+}
+
+// PublishLegacyEventBatchAsCE publishes the legacy events of a batch request as CloudEvents and writes the legacy
+// response of every event.
+func (p *BatchPublisher) PublishLegacyEventBatchAsCE(writer http.ResponseWriter, request *http.Request) {
+	events, responses := p.LegacyTransformer.TransformLegacyBatchRequestToCE(writer, request)
+	if events == nil {
+		p.Logger.Debug("failed to transform legacy event batch to CEs, events are nil")
+		return
+	}
+	if p.MaxBatchSize > 0 && len(events) > p.MaxBatchSize {
+		p.Logger.Warnf("Failed to publish legacy event batch with error: %s", ErrBatchTooLarge)
+		p.LegacyTransformer.TransformsBatchTooLargeToLegacyResponse(writer, ErrBatchTooLarge.Error())
+		return
+	}
+
+	ctx, cancel := p.batchContext(request.Context())
+	defer cancel()
+
+	s := sender.GenericSenderFunc(p.sendEvent)
+	for i, event := range events {
+		// the response of an invalid legacy event holds the error already
+		if event == nil {
+			continue
+		}
+		result := SendBatchEvent(ctx, s, event)
+		// Change response as per old error codes
+		responses[i] = p.LegacyTransformer.TransformCEResponseToLegacyResponse(result.StatusCode, event, result.Error)
+	}
+	p.LegacyTransformer.TransformsCEBatchResponseToLegacyResponse(writer, responses)
+}
+
+// batchContext returns the context within which all events of a batch are dispatched.
+func (p *BatchPublisher) batchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.BatchTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.BatchTimeout)
+}
+
+// sendEvent validates the data of a single event of a batch, dispatches it within the request timeout and returns
+// the status code and the error of the dispatch.
+func (p *BatchPublisher) sendEvent(ctx context.Context, event *cev2event.Event) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.RequestTimeout)
+	defer cancel()
+
+	if err := p.PayloadValidator.Validate(ctx, event); err != nil {
+		p.Logger.Warnf("Event data is invalid as per schema with error: %s", err)
+		return http.StatusBadRequest, err
+	}
+
+	statusCode, dispatchTime, respBody := p.Send(ctx, event)
+
+	p.Logger.WithFields(
+		logrus.Fields{
+			"id":           event.ID(),
+			"source":       event.Source(),
+			"type":         event.Type(),
+			"statusCode":   statusCode,
+			"duration":     dispatchTime,
+			"responseBody": string(respBody),
+		}).Info("Event dispatched")
+
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		if len(respBody) == 0 {
+			return statusCode, errors.New(http.StatusText(statusCode))
+		}
+		return statusCode, errors.New(string(respBody))
+	}
+	return statusCode, nil
+}
+
+// writeResponse writes the HTTP response given the status code and response body.
+func (p *BatchPublisher) writeResponse(writer http.ResponseWriter, statusCode int, respBody []byte) {
+	writer.WriteHeader(statusCode)
+
+	if respBody == nil {
+		return
+	}
+	if _, err := writer.Write(respBody); err != nil {
+		p.Logger.Errorf("Failed to write response body with error: %s", err)
+	}
+}
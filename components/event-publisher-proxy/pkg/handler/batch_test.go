@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/sender"
+	testingutils "github.com/kyma-project/kyma/components/event-publisher-proxy/testing"
+)
+
+func TestPublishBatch(t *testing.T) {
+	testCases := []struct {
+		name           string
+		givenBody      string
+		givenSendError error
+		wantResponse   *BatchResponse
+		wantStatusCode int
+		wantError      bool
+	}{
+		{
+			name:           "all events are dispatched",
+			givenBody:      "[" + testingutils.StructuredCloudEventPayload + "]",
+			wantResponse:   &BatchResponse{Events: []BatchEventResponse{{ID: testingutils.EventID, StatusCode: http.StatusNoContent}}},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:      "invalid events are not dispatched",
+			givenBody: testingutils.StructuredCloudEventBatchPayload,
+			wantResponse: &BatchResponse{Events: []BatchEventResponse{
+				{ID: testingutils.EventID, StatusCode: http.StatusNoContent},
+				{ID: testingutils.EventID, StatusCode: http.StatusBadRequest, Error: "source: REQUIRED\n"},
+			}},
+			wantStatusCode: http.StatusMultiStatus,
+		},
+		{
+			name:      "events which are not objects are not dispatched",
+			givenBody: `[` + testingutils.StructuredCloudEventPayload + `, "event"]`,
+			wantResponse: &BatchResponse{Events: []BatchEventResponse{
+				{ID: testingutils.EventID, StatusCode: http.StatusNoContent},
+				{StatusCode: http.StatusBadRequest},
+			}},
+			wantStatusCode: http.StatusMultiStatus,
+		},
+		{
+			name:           "failed dispatch",
+			givenBody:      "[" + testingutils.StructuredCloudEventPayload + "]",
+			givenSendError: errors.New("failed"),
+			wantResponse:   &BatchResponse{Events: []BatchEventResponse{{ID: testingutils.EventID, StatusCode: http.StatusBadGateway, Error: "failed"}}},
+			wantStatusCode: http.StatusMultiStatus,
+		},
+		{
+			name:           "empty batch",
+			givenBody:      "[]",
+			wantResponse:   &BatchResponse{Events: []BatchEventResponse{}},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:      "body is not a batch",
+			givenBody: testingutils.StructuredCloudEventPayload,
+			wantError: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var sent []string
+			s := sender.GenericSenderFunc(func(_ context.Context, event *cev2event.Event) (int, error) {
+				sent = append(sent, event.ID())
+				if tc.givenSendError != nil {
+					return http.StatusBadGateway, tc.givenSendError
+				}
+				return http.StatusNoContent, nil
+			})
+
+			request := httptest.NewRequest(http.MethodPost, PublishEndpoint, strings.NewReader(tc.givenBody))
+			gotResponse, err := PublishBatch(context.Background(), request, s, 0)
+			if tc.wantError {
+				if err == nil {
+					t.Errorf("expected an error for body: %s", tc.givenBody)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to publish batch: %v", err)
+			}
+			if len(gotResponse.Events) != len(tc.wantResponse.Events) {
+				t.Fatalf("incorrect number of event responses, want: %d, got: %d", len(tc.wantResponse.Events), len(gotResponse.Events))
+			}
+			wantSent := 0
+			for i, want := range tc.wantResponse.Events {
+				if want.StatusCode != http.StatusBadRequest {
+					wantSent++
+				}
+				got := gotResponse.Events[i]
+				if got.ID != want.ID || got.StatusCode != want.StatusCode || !strings.Contains(got.Error, want.Error) {
+					t.Errorf("incorrect response of event %d, want: %+v, got: %+v", i, want, got)
+				}
+			}
+			if len(sent) != wantSent {
+				t.Errorf("incorrect number of dispatched events, want: %d, got: %d", wantSent, len(sent))
+			}
+			if gotResponse.StatusCode() != tc.wantStatusCode {
+				t.Errorf("incorrect status code, want: %d, got: %d", tc.wantStatusCode, gotResponse.StatusCode())
+			}
+		})
+	}
+}
+
+func TestBatchPublisherTimeoutPerEvent(t *testing.T) {
+	// every event takes most of the request timeout, which must not be used up by the events before it
+	requestTimeout := 100 * time.Millisecond
+	publisher := &BatchPublisher{
+		Send: func(ctx context.Context, event *cev2event.Event) (int, time.Duration, []byte) {
+			select {
+			case <-ctx.Done():
+				return http.StatusGatewayTimeout, 0, []byte(ctx.Err().Error())
+			case <-time.After(requestTimeout / 2):
+				return http.StatusNoContent, requestTimeout / 2, nil
+			}
+		},
+		RequestTimeout: requestTimeout,
+		Logger:         logrus.New(),
+	}
+
+	body := "[" + strings.Repeat(testingutils.StructuredCloudEventPayload+",", 3) + testingutils.StructuredCloudEventPayload + "]"
+	request := httptest.NewRequest(http.MethodPost, PublishEndpoint, strings.NewReader(body))
+	writer := httptest.NewRecorder()
+	publisher.PublishCloudEventBatch(writer, request)
+
+	if writer.Code != http.StatusOK {
+		t.Errorf("incorrect status code, want: %d, got: %d, body: %s", http.StatusOK, writer.Code, writer.Body.String())
+	}
+}
+
+func TestBatchPublisherLimits(t *testing.T) {
+	batch := "[" + strings.Repeat(testingutils.StructuredCloudEventPayload+",", 2) + testingutils.StructuredCloudEventPayload + "]"
+	testCases := []struct {
+		name               string
+		givenMaxBatchSize  int
+		givenMaxBodySize   int64
+		givenBatchTimeout  time.Duration
+		wantStatusCode     int
+		wantSent           int
+		wantTimedOutEvents int
+	}{
+		{
+			name:              "batch within the limits",
+			givenMaxBatchSize: 3,
+			givenMaxBodySize:  int64(len(batch)),
+			wantStatusCode:    http.StatusOK,
+			wantSent:          3,
+		},
+		{
+			name:              "batch with too many events",
+			givenMaxBatchSize: 2,
+			givenMaxBodySize:  int64(len(batch)),
+			wantStatusCode:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:              "batch with a too large body",
+			givenMaxBatchSize: 3,
+			givenMaxBodySize:  int64(len(batch)) - 1,
+			wantStatusCode:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:               "batch which is not dispatched within the batch timeout",
+			givenMaxBatchSize:  3,
+			givenMaxBodySize:   int64(len(batch)),
+			givenBatchTimeout:  75 * time.Millisecond,
+			wantStatusCode:     http.StatusMultiStatus,
+			wantSent:           2,
+			wantTimedOutEvents: 1,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sent := 0
+			publisher := &BatchPublisher{
+				Send: func(ctx context.Context, event *cev2event.Event) (int, time.Duration, []byte) {
+					sent++
+					time.Sleep(50 * time.Millisecond)
+					return http.StatusNoContent, 50 * time.Millisecond, nil
+				},
+				RequestTimeout: time.Second,
+				MaxBatchSize:   tc.givenMaxBatchSize,
+				BatchTimeout:   tc.givenBatchTimeout,
+				Logger:         logrus.New(),
+			}
+
+			request := httptest.NewRequest(http.MethodPost, PublishEndpoint, strings.NewReader(batch))
+			writer := httptest.NewRecorder()
+			request.Body = http.MaxBytesReader(writer, request.Body, tc.givenMaxBodySize)
+			publisher.PublishCloudEventBatch(writer, request)
+
+			if writer.Code != tc.wantStatusCode {
+				t.Errorf("incorrect status code, want: %d, got: %d, body: %s", tc.wantStatusCode, writer.Code, writer.Body.String())
+			}
+			if sent != tc.wantSent {
+				t.Errorf("incorrect number of dispatched events, want: %d, got: %d", tc.wantSent, sent)
+			}
+			if got := strings.Count(writer.Body.String(), `"status":504`); got != tc.wantTimedOutEvents {
+				t.Errorf("incorrect number of events which timed out, want: %d, got: %d, body: %s", tc.wantTimedOutEvents, got, writer.Body.String())
+			}
+		})
+	}
+}
+
+func TestIsABatchRequest(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		want        bool
+	}{
+		{contentType: "application/cloudevents-batch+json", want: true},
+		{contentType: "application/cloudevents-batch+json; charset=utf-8", want: true},
+		{contentType: "application/cloudevents+json", want: false},
+		{contentType: "", want: false},
+	}
+	for _, tc := range testCases {
+		request := httptest.NewRequest(http.MethodPost, PublishEndpoint, nil)
+		request.Header.Set("Content-Type", tc.contentType)
+		if got := IsABatchRequest(request); got != tc.want {
+			t.Errorf("incorrect result for content type %q, want: %v, got: %v", tc.contentType, tc.want, got)
+		}
+	}
+}
+
+func TestIsARequestWithLegacyEventBatch(t *testing.T) {
+	got := map[string]bool{
+		"/app/v1/events/batch":      IsARequestWithLegacyEventBatch("/app/v1/events/batch"),
+		"/app/v1/events":            IsARequestWithLegacyEventBatch("/app/v1/events"),
+		"/app/v1/events/subscribed": IsARequestWithLegacyEventBatch("/app/v1/events/subscribed"),
+		"/v1/events/batch":          IsARequestWithLegacyEventBatch("/v1/events/batch"),
+	}
+	want := map[string]bool{
+		"/app/v1/events/batch":      true,
+		"/app/v1/events":            false,
+		"/app/v1/events/subscribed": false,
+		"/v1/events/batch":          false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect results, want: %v, got: %v", want, got)
+	}
+}
@@ -428,7 +428,7 @@ func setupTestResources(t *testing.T, port, maxRequestSize int, applicationName,
 
 	msgSender := sender.NewBebMessageSender(emsCEURL, client)
 	msgReceiver := receiver.NewHttpMessageReceiver(cfg.Port)
-	opts := &options.Options{MaxRequestSize: int64(maxRequestSize)}
+	opts := &options.Options{MaxRequestSize: int64(maxRequestSize), MaxBatchRequestSize: int64(maxRequestSize)}
 	appLister := handlertest.NewApplicationListerOrDie(ctx, applicationName)
 	legacyTransformer := legacy.NewTransformer(cfg.BEBNamespace, cfg.EventTypePrefix, appLister)
 
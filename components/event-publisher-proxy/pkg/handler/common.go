@@ -3,9 +3,10 @@ package handler
 import "strings"
 
 const (
-	PublishEndpoint          = "/publish"
-	LegacyEndpointSuffix     = "/v1/events"
-	SubscribedEndpointSuffix = "/v1/events/subscribed"
+	PublishEndpoint           = "/publish"
+	LegacyEndpointSuffix      = "/v1/events"
+	SubscribedEndpointSuffix  = "/v1/events/subscribed"
+	LegacyBatchEndpointSuffix = "/v1/events/batch"
 )
 
 func IsARequestWithCE(uri string) bool {
@@ -13,14 +14,7 @@ func IsARequestWithCE(uri string) bool {
 }
 func IsARequestWithLegacyEvent(uri string) bool {
 	// Assuming the path should be of the form /:application/v1/events
-	uriPathSegments := make([]string, 0)
-
-	for _, segment := range strings.Split(uri, "/") {
-		if strings.TrimSpace(segment) != "" {
-			uriPathSegments = append(uriPathSegments, segment)
-		}
-	}
-	if len(uriPathSegments) != 3 {
+	if len(pathSegments(uri)) != 3 {
 		return false
 	}
 	if !strings.HasSuffix(uri, LegacyEndpointSuffix) {
@@ -29,6 +23,27 @@ func IsARequestWithLegacyEvent(uri string) bool {
 	return true
 }
 
+func IsARequestWithLegacyEventBatch(uri string) bool {
+	// Assuming the path should be of the form /:application/v1/events/batch
+	if len(pathSegments(uri)) != 4 {
+		return false
+	}
+	if !strings.HasSuffix(uri, LegacyBatchEndpointSuffix) {
+		return false
+	}
+	return true
+}
+
+func pathSegments(uri string) []string {
+	uriPathSegments := make([]string, 0)
+	for _, segment := range strings.Split(uri, "/") {
+		if strings.TrimSpace(segment) != "" {
+			uriPathSegments = append(uriPathSegments, segment)
+		}
+	}
+	return uriPathSegments
+}
+
 func IsARequestForSubscriptions(uri string) bool {
 	// Assuming the path should be of the form /:application/v1/events/subscribed
 	if !strings.HasSuffix(uri, SubscribedEndpointSuffix) {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/dedup"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/handler"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/health"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events"
@@ -20,15 +21,16 @@ import (
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/receiver"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/sender"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/subscribed"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
 )
 
-// Handler is responsible for receiving HTTP requests and dispatching them to NATS.
-// It also assures that the messages received are compliant with the Cloud Events spec.
+// Handler is responsible for receiving HTTP requests and dispatching them to NATS, it dispatches them to Kafka as
+// well given a Kafka sender. It also assures that the messages received are compliant with the Cloud Events spec.
 type Handler struct {
 	// Receiver receives incoming HTTP requests
 	Receiver *receiver.HttpMessageReceiver
 	// Sender sends requests to the broker
-	Sender *sender.NatsMessageSender
+	Sender sender.GenericSender
 	// Defaulter sets default values to incoming events
 	Defaulter cev2client.EventDefaulter
 	// LegacyTransformer handles transformations needed to handle legacy events
@@ -41,12 +43,16 @@ type Handler struct {
 	Logger *logrus.Logger
 	// Options configures HTTP server
 	Options *options.Options
+	// PayloadValidator validates the data of events against their schemas, it is optional
+	PayloadValidator *validation.Validator
+	// Deduplicator drops duplicates of events which were dispatched before, it is optional
+	Deduplicator *dedup.Deduplicator
 	// collector collects metrics
 	collector *metrics.Collector
 }
 
 // NewHandler returns a new NATS Handler instance.
-func NewHandler(receiver *receiver.HttpMessageReceiver, sender *sender.NatsMessageSender, requestTimeout time.Duration,
+func NewHandler(receiver *receiver.HttpMessageReceiver, sender sender.GenericSender, requestTimeout time.Duration,
 	legacyTransformer *legacy.Transformer, opts *options.Options, subscribedProcessor *subscribed.Processor,
 	logger *logrus.Logger, collector *metrics.Collector) *Handler {
 	return &Handler{
@@ -77,12 +83,17 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 	// Limit server from reading a huge payload
-	request.Body = http.MaxBytesReader(writer, request.Body, h.Options.MaxRequestSize)
+	request.Body = http.MaxBytesReader(writer, request.Body, h.maxRequestSize(request))
 	uri := request.RequestURI
 
 	// Process /publish endpoint
 	// Gets a CE and sends it to NATS
 	if handler.IsARequestWithCE(uri) {
+		// Gets a batch of CEs and sends them one by one to NATS
+		if handler.IsABatchRequest(request) {
+			h.batchPublisher().PublishCloudEventBatch(writer, request)
+			return
+		}
 		h.publishCloudEvents(writer, request)
 		return
 	}
@@ -94,6 +105,13 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// Process /:application/v1/events/batch
+	// Publishes a batch of legacy events as CE v1.0 to NATS
+	if handler.IsARequestWithLegacyEventBatch(uri) {
+		h.batchPublisher().PublishLegacyEventBatchAsCE(writer, request)
+		return
+	}
+
 	// Process /:application/v1/events/subscribed
 	// Fetches the list of subscriptions available for the given application
 	if handler.IsARequestForSubscriptions(uri) {
@@ -113,6 +131,13 @@ func (h *Handler) publishLegacyEventsAsCE(writer http.ResponseWriter, request *h
 	}
 	ctx, cancel := context.WithTimeout(request.Context(), h.RequestTimeout)
 	defer cancel()
+
+	if err := h.PayloadValidator.Validate(ctx, event); err != nil {
+		h.Logger.Warnf("Event data is invalid as per schema with error: %s", err)
+		h.LegacyTransformer.TransformsValidationErrorToLegacyResponse(writer, err)
+		return
+	}
+
 	h.receive(ctx, event)
 	statusCode, dispatchTime, respBody := h.send(ctx, event)
 	// Change response as per old error codes
@@ -149,6 +174,12 @@ func (h *Handler) publishCloudEvents(writer http.ResponseWriter, request *http.R
 		return
 	}
 
+	if err := h.PayloadValidator.Validate(ctx, event); err != nil {
+		h.Logger.Warnf("Event data is invalid as per schema with error: %s", err)
+		h.writeResponse(writer, http.StatusBadRequest, []byte(err.Error()))
+		return
+	}
+
 	if request.Header.Get(cev2http.ContentType) == cev2event.ApplicationCloudEventsJSON {
 		ctx = binding.WithForceStructured(ctx)
 	} else {
@@ -170,6 +201,30 @@ func (h *Handler) publishCloudEvents(writer http.ResponseWriter, request *http.R
 		}).Info("Event dispatched")
 }
 
+// batchPublisher returns the publisher of the events of batch requests, which are received and sent one by one
+func (h *Handler) batchPublisher() *handler.BatchPublisher {
+	return &handler.BatchPublisher{
+		Send: func(ctx context.Context, event *cev2event.Event) (int, time.Duration, []byte) {
+			h.receive(ctx, event)
+			return h.send(ctx, event)
+		},
+		PayloadValidator:  h.PayloadValidator,
+		LegacyTransformer: h.LegacyTransformer,
+		RequestTimeout:    h.RequestTimeout,
+		MaxBatchSize:      h.Options.MaxBatchSize,
+		BatchTimeout:      h.Options.BatchTimeout,
+		Logger:            h.Logger,
+	}
+}
+
+// maxRequestSize returns the maximum size of the body of the given request, batch requests have their own limit
+func (h *Handler) maxRequestSize(request *http.Request) int64 {
+	if (handler.IsARequestWithCE(request.RequestURI) && handler.IsABatchRequest(request)) || handler.IsARequestWithLegacyEventBatch(request.RequestURI) {
+		return h.Options.MaxBatchRequestSize
+	}
+	return h.Options.MaxRequestSize
+}
+
 // writeResponse writes the HTTP response given the status code and response body.
 func (h *Handler) writeResponse(writer http.ResponseWriter, statusCode int, respBody []byte) {
 	writer.WriteHeader(statusCode)
@@ -192,8 +247,25 @@ func (h *Handler) receive(ctx context.Context, event *cev2event.Event) {
 	h.Logger.Infof("Event received id:[%s]", event.ID())
 }
 
-// send dispatches the given Cloud Event to NATS and returns the response details and dispatch time.
+// send dispatches the given Cloud Event to NATS and returns the response details and dispatch time. A duplicate
+// of an event, which was dispatched before, is not dispatched again, but gets the response of the original event.
 func (h *Handler) send(ctx context.Context, event *cev2event.Event) (int, time.Duration, []byte) {
+	var dispatchTime time.Duration
+	result, duplicate := h.Deduplicator.Do(ctx, event.Source(), event.ID(), func() dedup.Result {
+		var statusCode int
+		var respBody []byte
+		statusCode, dispatchTime, respBody = h.dispatch(ctx, event)
+		return dedup.Result{StatusCode: statusCode, Body: respBody}
+	})
+	if duplicate {
+		h.collector.RecordDuplicate()
+		h.Logger.Infof("Event is a duplicate, it is not dispatched again id:[%s] source:[%s]", event.ID(), event.Source())
+	}
+	return result.StatusCode, dispatchTime, result.Body
+}
+
+// dispatch dispatches the given Cloud Event to NATS and returns the response details and dispatch time.
+func (h *Handler) dispatch(ctx context.Context, event *cev2event.Event) (int, time.Duration, []byte) {
 	start := time.Now()
 	resp, err := h.Sender.Send(ctx, event)
 	dispatchTime := time.Since(start)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,14 +18,19 @@ import (
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
 	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/dedup"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/env"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/handler"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/handler/handlertest"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/informers"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events"
+	legacyapi "github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events/api"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/metrics"
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/metrics/metricstest"
 	pkgnats "github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/nats"
@@ -108,7 +114,7 @@ func (test *Test) setupResources(t *testing.T, subscription *eventingv1alpha1.Su
 	}
 
 	// start handler which blocks until it receives a shutdown signal
-	opts := &options.Options{MaxRequestSize: 65536}
+	opts := &options.Options{MaxRequestSize: 65536, MaxBatchRequestSize: 65536}
 	natsHandler := NewHandler(messageReceiver, msgSender, test.natsConfig.RequestTimeout, legacyTransformer, opts, subscribedProcessor, test.logger, test.collector)
 	assert.NotNil(t, natsHandler)
 	go func() {
@@ -241,6 +247,69 @@ func TestNatsHandlerForLegacyEvents(t *testing.T) {
 	exec(t, testingutils.ApplicationNameNotClean, testingutils.CloudEventType)
 }
 
+func TestNatsHandlerForBatches(t *testing.T) {
+	test.logger.Info("TestNatsHandlerForBatches started")
+
+	// setup test environment
+	publishEndpoint := fmt.Sprintf("http://localhost:%d/publish", test.natsConfig.Port)
+	publishLegacyBatchEndpoint := fmt.Sprintf("http://localhost:%d/%s/v1/events/batch", test.natsConfig.Port, testingutils.ApplicationName)
+	subscription := testingutils.NewSubscription(testingutils.SubscriptionWithFilter(testingutils.MessagingNamespace, testingutils.CloudEventTypeNotClean))
+	cancel := test.setupResources(t, subscription, testingutils.ApplicationName)
+	defer cancel()
+
+	t.Run("batch of cloud events", func(t *testing.T) {
+		resp, err := testingutils.SendEvent(publishEndpoint, testingutils.StructuredCloudEventBatchPayload, testingutils.GetBatchMessageHeaders())
+		if err != nil {
+			t.Fatalf("Failed to send batch with error: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusMultiStatus {
+			t.Fatalf("Test failed, want status code:%d but got:%d", http.StatusMultiStatus, resp.StatusCode)
+		}
+
+		gotResponse := handler.BatchResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(&gotResponse); err != nil {
+			t.Fatalf("Failed to decode batch response with error: %v", err)
+		}
+		if len(gotResponse.Events) != 2 {
+			t.Fatalf("Test failed, want 2 event responses but got:%d", len(gotResponse.Events))
+		}
+		if gotResponse.Events[0].StatusCode != http.StatusNoContent || gotResponse.Events[0].ID != testingutils.EventID {
+			t.Errorf("Test failed, want a dispatched event but got:%+v", gotResponse.Events[0])
+		}
+		if gotResponse.Events[1].StatusCode != http.StatusBadRequest || len(gotResponse.Events[1].Error) == 0 {
+			t.Errorf("Test failed, want an invalid event but got:%+v", gotResponse.Events[1])
+		}
+		metricstest.EnsureMetricLatency(t, test.collector)
+	})
+
+	t.Run("batch of legacy events", func(t *testing.T) {
+		resp, err := testingutils.SendEvent(publishLegacyBatchEndpoint, testingutils.LegacyEventBatchPayload, testingutils.GetApplicationJSONHeaders())
+		if err != nil {
+			t.Fatalf("Failed to send batch with error: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusMultiStatus {
+			t.Fatalf("Test failed, want status code:%d but got:%d", http.StatusMultiStatus, resp.StatusCode)
+		}
+
+		gotResponse := legacyapi.PublishBatchResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(&gotResponse); err != nil {
+			t.Fatalf("Failed to decode batch response with error: %v", err)
+		}
+		if len(gotResponse) != 2 {
+			t.Fatalf("Test failed, want 2 event responses but got:%d", len(gotResponse))
+		}
+		if gotResponse[0].Ok == nil || gotResponse[0].Ok.EventID != testingutils.EventID {
+			t.Errorf("Test failed, want a dispatched event but got:%+v", gotResponse[0])
+		}
+		wantError := legacy.ErrorResponseMissingFieldEventType().Error
+		if !reflect.DeepEqual(gotResponse[1].Error, wantError) {
+			t.Errorf("Test failed, want error:%+v but got:%+v", wantError, gotResponse[1].Error)
+		}
+	})
+}
+
 func TestNatsHandlerForSubscribedEndpoint(t *testing.T) {
 	test.logger.Info("TestNatsHandlerForSubscribedEndpoint started")
 
@@ -283,6 +352,51 @@ func TestNatsHandlerForSubscribedEndpoint(t *testing.T) {
 	}
 }
 
+func TestNatsHandlerDeduplication(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := pkgnats.NewBackendConnection(test.natsUrl, true, 3, time.Second)
+	if err := bc.Connect(); err != nil {
+		t.Fatalf("Failed to connect to NATS with error: %v", err)
+	}
+	defer bc.Connection.Close()
+
+	// count the events which are dispatched to NATS
+	var dispatched int32
+	eventType := testingutils.CloudEventType
+	if _, err := bc.Connection.Subscribe(eventType, func(*nats.Msg) { atomic.AddInt32(&dispatched, 1) }); err != nil {
+		t.Fatalf("Failed to subscribe to event with error: %v", err)
+	}
+
+	collector := metrics.NewCollector()
+	h := &Handler{
+		Sender:       sender.NewNatsMessageSender(ctx, bc, test.logger),
+		Logger:       test.logger,
+		Deduplicator: dedup.NewDeduplicator(dedup.NewMemoryStore(time.Minute, 10), test.logger),
+		collector:    collector,
+	}
+
+	event := cev2event.New()
+	event.SetID(testingutils.EventID)
+	event.SetSource(testingutils.CloudEventSource)
+	event.SetType(eventType)
+	for i := 0; i < 2; i++ {
+		if statusCode, _, _ := h.send(ctx, &event); statusCode != http.StatusNoContent {
+			t.Errorf("Test failed, want status code:%d but got:%d", http.StatusNoContent, statusCode)
+		}
+	}
+	if err := bc.Connection.Flush(); err != nil {
+		t.Fatalf("Failed to flush NATS connection with error: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&dispatched); got != 1 {
+		t.Errorf("Test failed, want dispatched events:1 but got:%d", got)
+	}
+	metricstest.EnsureMetricDuplicates(t, collector)
+}
+
 func newEnvConfig(port, natsPort int) *env.NatsConfig {
 	return &env.NatsConfig{
 		Port:                  port,
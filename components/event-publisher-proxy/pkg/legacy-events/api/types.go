@@ -47,3 +47,12 @@ type PublishEventResponses struct {
 	Ok    *PublishResponse
 	Error *Error
 }
+
+// PublishBatchEventResponse holds the response of a single event of a batch, either Ok or Error is set
+type PublishBatchEventResponse struct {
+	Ok    *PublishResponse `json:"ok,omitempty"`
+	Error *Error           `json:"error,omitempty"`
+}
+
+// PublishBatchResponse holds the responses of the events of a batch in the order of the batch
+type PublishBatchResponse []PublishBatchEventResponse
@@ -61,6 +61,15 @@ func ErrorResponseMissingFieldData() (response *api.PublishEventResponses) {
 	return CreateMissingFieldError(FieldData)
 }
 
+// ErrorResponseInvalidData returns an error of type PublishEventResponses for data which does not conform to the
+// schema of the event, the field is the path of the invalid value in the event
+func ErrorResponseInvalidData(field, moreInfo string) (response *api.PublishEventResponses) {
+	apiErrorDetail := api.ErrorDetail{Field: field, Type: ErrorTypeInvalidField, Message: ErrorMessageInvalidField, MoreInfo: moreInfo}
+	details := []api.ErrorDetail{apiErrorDetail}
+	apiError := api.Error{Status: http.StatusBadRequest, Type: ErrorTypeValidationViolation, Message: ErrorMessageInvalidField, MoreInfo: "", Details: details}
+	return &api.PublishEventResponses{Ok: nil, Error: &apiError}
+}
+
 // ErrorResponse returns an error of type PublishEventResponses with the given status and error
 func ErrorResponse(status int, err error) *api.PublishEventResponses {
 	return &api.PublishEventResponses{Error: &api.Error{Status: status, Message: err.Error()}}
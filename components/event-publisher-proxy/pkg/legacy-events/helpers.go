@@ -49,6 +49,15 @@ func writeJSONResponse(w http.ResponseWriter, resp *api.PublishEventResponses) {
 	log.Errorf("received an empty response")
 }
 
+// writeJSONBatchResponse writes a JSON response for a batch of events
+func writeJSONBatchResponse(w http.ResponseWriter, statusCode int, resp api.PublishBatchResponse) {
+	w.Header().Set("Content-Type", ContentTypeApplicationJSON)
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Errorf("failed to write the batch response: %v", err)
+	}
+}
+
 // formatEventType4BEB format eventType as per BEB spec
 func formatEventType4BEB(eventTypePrefix, app, eventType, version string) string {
 	return fmt.Sprintf(eventTypePrefixFormat, eventTypePrefix, app, eventType, version)
@@ -3,6 +3,7 @@ package legacy
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application"
 	apiv1 "github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events/api"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
 )
 
 var (
@@ -80,7 +82,7 @@ func (t Transformer) checkParameters(parameters *apiv1.PublishEventParametersV1)
 
 func (t Transformer) TransformLegacyRequestsToCE(writer http.ResponseWriter, request *http.Request) *cev2event.Event {
 	// parse request body to PublishRequestV1
-	if request.Body == nil || request.ContentLength == 0 {
+	if request.Body == nil {
 		resp := ErrorResponseBadRequest(ErrorMessageBadPayload)
 		writeJSONResponse(writer, resp)
 		return nil
@@ -89,44 +91,105 @@ func (t Transformer) TransformLegacyRequestsToCE(writer http.ResponseWriter, req
 	parameters := &apiv1.PublishEventParametersV1{}
 	decoder := json.NewDecoder(request.Body)
 	if err := decoder.Decode(&parameters.PublishrequestV1); err != nil {
-		var resp *apiv1.PublishEventResponses
-		if err.Error() == requestBodyTooLargeErrorMessage {
-			resp = ErrorResponseRequestBodyTooLarge(err.Error())
-		} else {
-			resp = ErrorResponseBadRequest(err.Error())
-		}
+		writeJSONResponse(writer, decodingErrorResponse(err))
+		return nil
+	}
+
+	event, resp := t.transformPublishRequestToCE(t.getApplicationName(request), parameters)
+	if resp != nil {
 		writeJSONResponse(writer, resp)
 		return nil
 	}
+	return event
+}
+
+// TransformLegacyBatchRequestToCE transforms the legacy events of a batch request to CEs. It returns the CE and
+// the error response of every event of the batch, the error response is nil unless the legacy event is invalid.
+// If the request body is not a batch of legacy events, it writes the error response and returns nil.
+func (t Transformer) TransformLegacyBatchRequestToCE(writer http.ResponseWriter, request *http.Request) ([]*cev2event.Event, []*apiv1.PublishEventResponses) {
+	if request.Body == nil {
+		resp := ErrorResponseBadRequest(ErrorMessageBadPayload)
+		writeJSONResponse(writer, resp)
+		return nil, nil
+	}
+
+	var publishRequests []apiv1.PublishRequestV1
+	decoder := json.NewDecoder(request.Body)
+	if err := decoder.Decode(&publishRequests); err != nil {
+		writeJSONResponse(writer, decodingErrorResponse(err))
+		return nil, nil
+	}
+
+	appName := t.getApplicationName(request)
+	events := make([]*cev2event.Event, len(publishRequests))
+	responses := make([]*apiv1.PublishEventResponses, len(publishRequests))
+	for i, publishRequest := range publishRequests {
+		parameters := &apiv1.PublishEventParametersV1{PublishrequestV1: publishRequest}
+		events[i], responses[i] = t.transformPublishRequestToCE(appName, parameters)
+	}
+	return events, responses
+}
 
+// transformPublishRequestToCE validates the given publish request and converts it to a CE,
+// it returns the error response if the publish request is invalid
+func (t Transformer) transformPublishRequestToCE(appName string, parameters *apiv1.PublishEventParametersV1) (*cev2event.Event, *apiv1.PublishEventResponses) {
 	// validate the PublishRequestV1 for missing / incoherent values
 	checkResp := t.checkParameters(parameters)
 	if checkResp.Error != nil {
-		writeJSONResponse(writer, checkResp)
-		return nil
+		return nil, checkResp
 	}
 
-	// clean the application name form non-alphanumeric characters
+	event, err := t.convertPublishRequestToCloudEvent(appName, parameters)
+	if err != nil {
+		return nil, ErrorResponse(http.StatusInternalServerError, err)
+	}
+	return event, nil
+}
+
+// getApplicationName returns the application name of the request cleaned from non-alphanumeric characters
+func (t Transformer) getApplicationName(request *http.Request) string {
 	appName := ParseApplicationNameFromPath(request.URL.Path)
 	if appObj, err := t.applicationLister.Get(appName); err == nil {
 		// handle existing applications
-		appName = application.GetCleanTypeOrName(appObj)
-	} else {
-		// handle non-existing applications
-		appName = application.GetCleanName(appName)
+		return application.GetCleanTypeOrName(appObj)
 	}
+	// handle non-existing applications
+	return application.GetCleanName(appName)
+}
 
-	event, err := t.convertPublishRequestToCloudEvent(appName, parameters)
-	if err != nil {
-		response := ErrorResponse(http.StatusInternalServerError, err)
-		writeJSONResponse(writer, response)
-		return nil
+// decodingErrorResponse returns the error response of a request body which could not be decoded. An empty body is
+// detected by decoding it, since the content length of a chunked request is unknown.
+func decodingErrorResponse(err error) *apiv1.PublishEventResponses {
+	if err == io.EOF {
+		return ErrorResponseBadRequest(ErrorMessageBadPayload)
 	}
-
-	return event
+	if err.Error() == requestBodyTooLargeErrorMessage {
+		return ErrorResponseRequestBodyTooLarge(err.Error())
+	}
+	return ErrorResponseBadRequest(err.Error())
 }
 
 func (t Transformer) TransformsCEResponseToLegacyResponse(writer http.ResponseWriter, statusCode int, event *cev2event.Event, msg string) {
+	writeJSONResponse(writer, t.TransformCEResponseToLegacyResponse(statusCode, event, msg))
+}
+
+// TransformsValidationErrorToLegacyResponse writes the legacy response of an event whose data does not conform to
+// the schema of the event
+func (t Transformer) TransformsValidationErrorToLegacyResponse(writer http.ResponseWriter, err error) {
+	if validationErr, ok := err.(*validation.Error); ok {
+		writeJSONResponse(writer, ErrorResponseInvalidData(validationErr.Field, validationErr.Message))
+		return
+	}
+	writeJSONResponse(writer, ErrorResponseInvalidData(FieldData, err.Error()))
+}
+
+// TransformsBatchTooLargeToLegacyResponse writes the legacy response of a batch which holds too many events
+func (t Transformer) TransformsBatchTooLargeToLegacyResponse(writer http.ResponseWriter, msg string) {
+	writeJSONResponse(writer, ErrorResponseRequestBodyTooLarge(msg))
+}
+
+// TransformCEResponseToLegacyResponse returns the legacy response of a dispatched CE
+func (t Transformer) TransformCEResponseToLegacyResponse(statusCode int, event *cev2event.Event, msg string) *apiv1.PublishEventResponses {
 	response := &apiv1.PublishEventResponses{}
 	// Fail
 	if !is2XXStatusCode(statusCode) {
@@ -134,14 +197,26 @@ func (t Transformer) TransformsCEResponseToLegacyResponse(writer http.ResponseWr
 			Status:  statusCode,
 			Message: msg,
 		}
-		writeJSONResponse(writer, response)
-		return
+		return response
 	}
 
 	// Success
 	response.Ok = &apiv1.PublishResponse{EventID: event.ID()}
-	writeJSONResponse(writer, response)
-	return
+	return response
+}
+
+// TransformsCEBatchResponseToLegacyResponse writes the legacy responses of all events of a batch. The status code
+// is 207 Multi-Status if any event of the batch was not dispatched.
+func (t Transformer) TransformsCEBatchResponseToLegacyResponse(writer http.ResponseWriter, responses []*apiv1.PublishEventResponses) {
+	batchResponse := make(apiv1.PublishBatchResponse, 0, len(responses))
+	statusCode := http.StatusOK
+	for _, response := range responses {
+		if response.Error != nil {
+			statusCode = http.StatusMultiStatus
+		}
+		batchResponse = append(batchResponse, apiv1.PublishBatchEventResponse{Ok: response.Ok, Error: response.Error})
+	}
+	writeJSONBatchResponse(writer, statusCode, batchResponse)
 }
 
 // convertPublishRequestToCloudEvent converts the given publish request to a CloudEvent.
@@ -1,10 +1,18 @@
 package legacy
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application/applicationtest"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application/fake"
 	legacyapi "github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/legacy-events/api"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/validation"
 	. "github.com/kyma-project/kyma/components/event-publisher-proxy/testing"
 )
 
@@ -94,3 +102,67 @@ func TestCombineEventTypeSegments(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformsValidationErrorToLegacyResponse(t *testing.T) {
+	legacyTransformer := NewTransformer(MessagingNamespace, MessagingEventTypePrefix, nil)
+	writer := httptest.NewRecorder()
+	legacyTransformer.TransformsValidationErrorToLegacyResponse(writer, &validation.Error{Field: "data/order/id", Message: "is required"})
+
+	if writer.Code != http.StatusBadRequest {
+		t.Errorf("incorrect status code, want: %d, got: %d", http.StatusBadRequest, writer.Code)
+	}
+	response := &legacyapi.PublishEventResponses{}
+	if err := json.NewDecoder(writer.Body).Decode(&response.Error); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Error.Details) != 1 {
+		t.Fatalf("incorrect number of error details, want: 1, got: %d", len(response.Error.Details))
+	}
+	detail := response.Error.Details[0]
+	if detail.Field != "data/order/id" || detail.Type != ErrorTypeInvalidField || detail.MoreInfo != "is required" {
+		t.Errorf("incorrect error detail: %+v", detail)
+	}
+}
+
+func TestTransformLegacyBatchRequestToCEWithoutContentLength(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	appLister := fake.NewListerOrDie(ctx, applicationtest.NewApplication(ApplicationName, nil))
+	legacyTransformer := NewTransformer(MessagingNamespace, MessagingEventTypePrefix, appLister)
+
+	testCases := []struct {
+		name           string
+		givenBody      string
+		wantEvents     int
+		wantStatusCode int
+	}{
+		{
+			name:           "chunked batch",
+			givenBody:      "[" + ValidLegacyEventPayloadWithEventId + "]",
+			wantEvents:     1,
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "empty chunked body",
+			givenBody:      "",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/"+ApplicationName+"/v1/events/batch", strings.NewReader(tc.givenBody))
+			// the content length of a chunked request is unknown
+			request.ContentLength = -1
+			writer := httptest.NewRecorder()
+
+			events, _ := legacyTransformer.TransformLegacyBatchRequestToCE(writer, request)
+			if len(events) != tc.wantEvents {
+				t.Errorf("incorrect number of events, want: %d, got: %d", tc.wantEvents, len(events))
+			}
+			if writer.Code != tc.wantStatusCode {
+				t.Errorf("incorrect status code, want: %d, got: %d", tc.wantStatusCode, writer.Code)
+			}
+		})
+	}
+}
@@ -11,16 +11,21 @@ const (
 	Errors = "event_publish_to_messaging_server_errors_total"
 	// Latency name of the latency metric
 	Latency = "event_publish_to_messaging_server_latency"
+	// Duplicates name of the duplicates metric
+	Duplicates = "event_publish_duplicates_total"
 	// errorsHelp help for the errors metric
 	errorsHelp = "The total number of errors while sending Events to the messaging server"
 	// latencyHelp help for the latency metric
 	latencyHelp = "The duration of sending Events to the messaging server"
+	// duplicatesHelp help for the duplicates metric
+	duplicatesHelp = "The total number of duplicate Events which were not sent to the messaging server"
 )
 
 // Collector implements the prometheus.Collector interface
 type Collector struct {
-	errors  *prometheus.CounterVec
-	latency *prometheus.HistogramVec
+	errors     *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	duplicates *prometheus.CounterVec
 }
 
 // NewCollector a new instance of Collector
@@ -40,6 +45,13 @@ func NewCollector() *Collector {
 			},
 			[]string{},
 		),
+		duplicates: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: Duplicates,
+				Help: duplicatesHelp,
+			},
+			[]string{},
+		),
 	}
 }
 
@@ -47,12 +59,14 @@ func NewCollector() *Collector {
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	c.errors.Describe(ch)
 	c.latency.Describe(ch)
+	c.duplicates.Describe(ch)
 }
 
 // Collect implements the prometheus.Collector interface Collect method
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	c.errors.Collect(ch)
 	c.latency.Collect(ch)
+	c.duplicates.Collect(ch)
 }
 
 // RecordError records an error metric
@@ -64,3 +78,8 @@ func (c *Collector) RecordError() {
 func (c *Collector) RecordLatency(duration time.Duration) {
 	c.latency.WithLabelValues().Observe(duration.Seconds())
 }
+
+// RecordDuplicate records a duplicate metric
+func (c *Collector) RecordDuplicate() {
+	c.duplicates.WithLabelValues().Inc()
+}
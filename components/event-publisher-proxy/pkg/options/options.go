@@ -1,20 +1,32 @@
 package options
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
 type Options struct {
-	MaxRequestSize int64
-	MetricsAddress string
+	MaxRequestSize      int64
+	MaxBatchRequestSize int64
+	MaxBatchSize        int
+	BatchTimeout        time.Duration
+	MetricsAddress      string
 }
 
 func ParseArgs() *Options {
 	maxRequestSize := flag.Int64("max-request-size", 65536, "The maximum request size in bytes.")
+	maxBatchRequestSize := flag.Int64("max-batch-request-size", 1048576, "The maximum size in bytes of a batch request.")
+	maxBatchSize := flag.Int("max-batch-size", 100, "The maximum number of events of a batch request.")
+	batchTimeout := flag.Duration("batch-timeout", 30*time.Second, "The maximum duration of dispatching the events of a batch request.")
 	metricsAddress := flag.String("metrics-addr", ":9090", "The address the metric endpoint binds to.")
 
 	flag.Parse()
 
 	return &Options{
-		MaxRequestSize: *maxRequestSize,
-		MetricsAddress: *metricsAddress,
+		MaxRequestSize:      *maxRequestSize,
+		MaxBatchRequestSize: *maxBatchRequestSize,
+		MaxBatchSize:        *maxBatchSize,
+		BatchTimeout:        *batchTimeout,
+		MetricsAddress:      *metricsAddress,
 	}
 }
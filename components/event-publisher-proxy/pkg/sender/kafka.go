@@ -0,0 +1,59 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Shopify/sarama"
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/sirupsen/logrus"
+)
+
+// compile time check
+var _ GenericSender = &KafkaMessageSender{}
+
+const (
+	// kafkaContentTypeHeader is the record header which holds the content type of the structured Cloud Event
+	kafkaContentTypeHeader = "content-type"
+	kafkaContentType       = "application/cloudevents+json; charset=UTF-8"
+)
+
+// KafkaMessageSender is responsible for sending messages to a Kafka topic.
+type KafkaMessageSender struct {
+	producer sarama.SyncProducer
+	topic    string
+	logger   *logrus.Logger
+}
+
+// NewKafkaMessageSender returns a new KafkaMessageSender instance which sends the events to the given topic.
+// The producer must wait until all in-sync replicas have stored an event.
+func NewKafkaMessageSender(producer sarama.SyncProducer, topic string, logger *logrus.Logger) *KafkaMessageSender {
+	return &KafkaMessageSender{producer: producer, topic: topic, logger: logger}
+}
+
+// Send appends the given Cloud Event in structured mode to the Kafka topic and returns once the producer has stored
+// it. The event type is the key of the record, so the events of a type are appended to the same partition and keep
+// their order.
+func (s *KafkaMessageSender) Send(_ context.Context, event *cev2event.Event) (int, error) {
+	s.logger.Infof("Sending event to Kafka, id:[%s]", event.ID())
+	data, err := event.MarshalJSON()
+	if err != nil {
+		s.logger.Errorf("Failed to marshal event, %s", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic:   s.topic,
+		Key:     sarama.StringEncoder(event.Type()),
+		Value:   sarama.ByteEncoder(data),
+		Headers: []sarama.RecordHeader{{Key: []byte(kafkaContentTypeHeader), Value: []byte(kafkaContentType)}},
+	}
+	partition, offset, err := s.producer.SendMessage(msg)
+	if err != nil {
+		s.logger.Errorf("Failed to send: %s", err.Error())
+		return http.StatusBadGateway, err
+	}
+
+	s.logger.Infof("stored id:[%s] in topic:[%s] partition:[%d] with offset:[%d]", event.ID(), s.topic, partition, offset)
+	return http.StatusNoContent, nil
+}
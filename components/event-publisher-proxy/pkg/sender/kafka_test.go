@@ -0,0 +1,74 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testingutils "github.com/kyma-project/kyma/components/event-publisher-proxy/testing"
+)
+
+// recordingProducer is a sarama.SyncProducer which keeps the sent messages or fails with the given error
+type recordingProducer struct {
+	sarama.SyncProducer
+	messages []*sarama.ProducerMessage
+	err      error
+}
+
+func (p *recordingProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	if p.err != nil {
+		return 0, 0, p.err
+	}
+	p.messages = append(p.messages, msg)
+	return 0, int64(len(p.messages) - 1), nil
+}
+
+func TestSendCloudEventToKafka(t *testing.T) {
+	logger := logrus.New()
+	logger.Info("TestKafkaSender started")
+
+	// create message sender
+	producer := &recordingProducer{}
+	topic := "kyma-eventing"
+	sender := NewKafkaMessageSender(producer, topic, logger)
+
+	// create cloudevent
+	ce := testingutils.StructuredCloudEventPayloadWithCleanEventType
+	event := cloudevents.NewEvent()
+	event.SetType(testingutils.CloudEventType)
+	err := json.Unmarshal([]byte(ce), &event)
+	assert.Nil(t, err)
+
+	// send cloudevent
+	ctx := context.Background()
+	status, err := sender.Send(ctx, &event)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	// the event type is the key of the record, which selects its partition
+	require.Len(t, producer.messages, 1)
+	msg := producer.messages[0]
+	assert.Equal(t, topic, msg.Topic)
+	assert.Equal(t, sarama.StringEncoder(testingutils.CloudEventType), msg.Key)
+	assert.Equal(t, []sarama.RecordHeader{{Key: []byte(kafkaContentTypeHeader), Value: []byte(kafkaContentType)}}, msg.Headers)
+	value, err := msg.Value.Encode()
+	require.NoError(t, err)
+	got := cloudevents.NewEvent()
+	require.NoError(t, json.Unmarshal(value, &got))
+	assert.Equal(t, event.ID(), got.ID())
+	assert.Equal(t, event.Type(), got.Type())
+	assert.Equal(t, event.Data(), got.Data())
+
+	// a failed send is reported as a bad gateway
+	producer.err = sarama.ErrUnknownTopicOrPartition
+	status, err = sender.Send(ctx, &event)
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadGateway, status)
+}
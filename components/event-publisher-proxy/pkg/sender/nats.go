@@ -7,7 +7,9 @@ import (
 	cenats "github.com/cloudevents/sdk-go/protocol/nats/v2"
 	cev2 "github.com/cloudevents/sdk-go/v2"
 	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/dedup"
 	pkgnats "github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/nats"
+	"github.com/nats-io/nats.go"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,11 +20,25 @@ type GenericSender interface {
 	Send(context.Context, *cev2event.Event) (int, error)
 }
 
+// GenericSenderFunc is an adapter to use an ordinary function as a GenericSender.
+type GenericSenderFunc func(context.Context, *cev2event.Event) (int, error)
+
+// Send calls f(ctx, event).
+func (f GenericSenderFunc) Send(ctx context.Context, event *cev2event.Event) (int, error) {
+	return f(ctx, event)
+}
+
 // BebMessageSender is responsible for sending messages over HTTP.
 type NatsMessageSender struct {
 	ctx               context.Context
 	logger            *logrus.Logger
 	backendConnection *pkgnats.BackendConnection
+	// persistent is true if the events are published to a NATS stream
+	persistent bool
+	// deduplicate is true if the events are published with a message ID, so that the stream drops duplicates
+	deduplicate bool
+	// onDuplicate is called for every event which the stream dropped as a duplicate
+	onDuplicate func()
 }
 
 // NewNatsMessageSender returns a new NewNatsMessageSender instance with the given nats connection.
@@ -30,12 +46,32 @@ func NewNatsMessageSender(ctx context.Context, bc *pkgnats.BackendConnection, lo
 	return &NatsMessageSender{ctx: ctx, backendConnection: bc, logger: logger}
 }
 
+// NewPersistentNatsMessageSender returns a new NatsMessageSender instance with the given nats connection,
+// which publishes the events to a NATS stream and waits until the stream has stored them.
+func NewPersistentNatsMessageSender(ctx context.Context, bc *pkgnats.BackendConnection, logger *logrus.Logger) *NatsMessageSender {
+	return &NatsMessageSender{ctx: ctx, backendConnection: bc, logger: logger, persistent: true}
+}
+
+// NewDeduplicatingNatsMessageSender returns a new NatsMessageSender instance with the given nats connection,
+// which publishes the events to a NATS stream with a message ID unique for their source and id. The stream drops
+// the duplicates of an event within its duplicates window, which are answered like the original event and reported
+// by calling onDuplicate.
+func NewDeduplicatingNatsMessageSender(ctx context.Context, bc *pkgnats.BackendConnection, logger *logrus.Logger,
+	onDuplicate func()) *NatsMessageSender {
+	return &NatsMessageSender{ctx: ctx, backendConnection: bc, logger: logger, persistent: true, deduplicate: true,
+		onDuplicate: onDuplicate}
+}
+
 // Send dispatches the given Cloud Event to NATS and returns the response details and dispatch time.
 func (h *NatsMessageSender) Send(ctx context.Context, event *cev2event.Event) (int, error) {
 	h.logger.Infof("Sending event to NATS, id:[%s]", event.ID())
 	// The same Nats subject used by Nats subscription
 	subject := event.Type()
 
+	if h.persistent {
+		return h.sendToStream(ctx, subject, event)
+	}
+
 	sender, err := cenats.NewSenderFromConn(h.backendConnection.Connection, subject)
 	if err != nil {
 		h.logger.Errorf("Failed to create nats protocol, %s", err.Error())
@@ -63,3 +99,47 @@ func (h *NatsMessageSender) Send(ctx context.Context, event *cev2event.Event) (i
 	h.logger.Infof("sent id:[%s], accepted: %t", event.ID(), cev2.IsACK(err))
 	return http.StatusNoContent, nil
 }
+
+// sendToStream publishes the given Cloud Event to the NATS stream which is bound to the subject
+// and returns once the stream acknowledged that the event is stored.
+func (h *NatsMessageSender) sendToStream(ctx context.Context, subject string, event *cev2event.Event) (int, error) {
+	data, err := event.MarshalJSON()
+	if err != nil {
+		h.logger.Errorf("Failed to marshal event, %s", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	if h.backendConnection.Connection.IsClosed() {
+		h.logger.Info("Reconnect...")
+		if err := h.backendConnection.Reconnect(); err != nil {
+			h.logger.Errorf("Failed to reconnect: %s", err.Error())
+			return http.StatusBadGateway, err
+		}
+	}
+
+	jsContext, err := h.backendConnection.Connection.JetStream()
+	if err != nil {
+		h.logger.Errorf("Failed to create JetStream context, %s", err.Error())
+		return http.StatusBadGateway, err
+	}
+
+	opts := []nats.PubOpt{nats.Context(ctx)}
+	if h.deduplicate {
+		opts = append(opts, nats.MsgId(dedup.Key(event.Source(), event.ID())))
+	}
+	ack, err := jsContext.Publish(subject, data, opts...)
+	if err != nil {
+		h.logger.Errorf("Failed to store event in stream: %s", err.Error())
+		return http.StatusBadGateway, err
+	}
+	if ack.Duplicate {
+		h.logger.Infof("Event is a duplicate, it is not stored again id:[%s] source:[%s]", event.ID(), event.Source())
+		if h.onDuplicate != nil {
+			h.onDuplicate()
+		}
+		return http.StatusNoContent, nil
+	}
+
+	h.logger.Infof("stored id:[%s] in stream:[%s] with sequence:[%d]", event.ID(), ack.Stream, ack.Sequence)
+	return http.StatusNoContent, nil
+}
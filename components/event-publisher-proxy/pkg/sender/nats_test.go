@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"testing"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/nats-io/nats.go"
+
 	pkgnats "github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/nats"
 	testingutils "github.com/kyma-project/kyma/components/event-publisher-proxy/testing"
 )
@@ -112,3 +116,106 @@ func TestSendCloudEventWithReconnect(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, status, http.StatusNoContent)
 }
+
+func TestSendCloudEventToStream(t *testing.T) {
+	logger := logrus.New()
+	logger.Info("TestNatsSender started")
+
+	// Start Nats server with JetStream
+	storeDir, err := ioutil.TempDir("", "jetstream")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(storeDir) }()
+	natsServer := testingutils.StartNatsServerWithJetStream(storeDir)
+	assert.NotNil(t, natsServer)
+	defer natsServer.Shutdown()
+
+	// connect to nats
+	bc := pkgnats.NewBackendConnection(natsServer.ClientURL(), true, 1, time.Second)
+	err = bc.Connect()
+	assert.Nil(t, err)
+	assert.NotNil(t, bc.Connection)
+
+	// create message sender
+	ctx := context.Background()
+	sender := NewPersistentNatsMessageSender(ctx, bc, logger)
+
+	// create cloudevent
+	ce := testingutils.StructuredCloudEventPayloadWithCleanEventType
+	event := cloudevents.NewEvent()
+	event.SetType(testingutils.CloudEventType)
+	err = json.Unmarshal([]byte(ce), &event)
+	assert.Nil(t, err)
+
+	// send cloudevent without a stream, the event is not stored
+	status, err := sender.Send(ctx, &event)
+	assert.NotNil(t, err)
+	assert.Equal(t, status, http.StatusBadGateway)
+
+	// send cloudevent to the stream
+	testingutils.AddStreamOrFail(t, bc.Connection, "kyma", testingutils.CloudEventType)
+	status, err = sender.Send(ctx, &event)
+	assert.Nil(t, err)
+	assert.Equal(t, status, http.StatusNoContent)
+
+	// the stored event is delivered to a subscriber which subscribes later on
+	jsContext, err := bc.Connection.JetStream()
+	assert.Nil(t, err)
+	done := make(chan bool, 1)
+	validator := testingutils.ValidateNatsMessageDataOrFail(t, fmt.Sprintf(`"%s"`, testingutils.CloudEventData), done)
+	_, err = jsContext.Subscribe(testingutils.CloudEventType, validator, nats.DeliverAll())
+	assert.Nil(t, err)
+
+	// wait for subscriber to receive the messages
+	if err := testingutils.WaitForChannelOrTimeout(done, time.Second*3); err != nil {
+		t.Fatalf("Subscriber did not receive the message with error: %v", err)
+	}
+}
+
+func TestSendDuplicateCloudEventToStream(t *testing.T) {
+	logger := logrus.New()
+
+	// Start Nats server with JetStream
+	storeDir, err := ioutil.TempDir("", "jetstream")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(storeDir) }()
+	natsServer := testingutils.StartNatsServerWithJetStream(storeDir)
+	assert.NotNil(t, natsServer)
+	defer natsServer.Shutdown()
+
+	// connect to nats
+	bc := pkgnats.NewBackendConnection(natsServer.ClientURL(), true, 1, time.Second)
+	err = bc.Connect()
+	assert.Nil(t, err)
+	testingutils.AddStreamOrFail(t, bc.Connection, "kyma", testingutils.CloudEventType)
+
+	// create message sender
+	ctx := context.Background()
+	var duplicates int
+	sender := NewDeduplicatingNatsMessageSender(ctx, bc, logger, func() { duplicates++ })
+
+	// create cloudevent
+	event := cloudevents.NewEvent()
+	err = json.Unmarshal([]byte(testingutils.StructuredCloudEventPayloadWithCleanEventType), &event)
+	assert.Nil(t, err)
+
+	// the duplicate gets the response of the original event, but is not stored again
+	for i := 0; i < 2; i++ {
+		status, err := sender.Send(ctx, &event)
+		assert.Nil(t, err)
+		assert.Equal(t, status, http.StatusNoContent)
+	}
+	assert.Equal(t, 1, duplicates)
+
+	// an event with another id is stored
+	event.SetID("other-id")
+	status, err := sender.Send(ctx, &event)
+	assert.Nil(t, err)
+	assert.Equal(t, status, http.StatusNoContent)
+	assert.Equal(t, 1, duplicates)
+
+	jsContext, err := bc.Connection.JetStream()
+	assert.Nil(t, err)
+	info, err := jsContext.StreamInfo("kyma")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), info.State.Msgs)
+}
@@ -0,0 +1,212 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// specURL is the URL under which an AsyncAPI spec is compiled, the local references of the schemas are resolved
+	// against it
+	specURL = "mem:///asyncapi.json"
+	// payloadsKey is the field of the compiled spec which holds the payload schemas of the events, it is added to
+	// the spec, so that the payloads are compiled along with the parts of the spec they refer to
+	payloadsKey = "x-kyma-event-payloads"
+)
+
+// operations of a channel or topic in an AsyncAPI spec, which can declare the message of an event
+var operations = []string{"subscribe", "publish"}
+
+// ParseAsyncAPI parses an AsyncAPI spec in the version 1.x or 2.x in JSON or YAML and returns the payload schemas
+// of its events. The schemas are mapped by the event type and version as used in CE types, e.g. "order.created.v1".
+// The channels or topics with an invalid payload schema are skipped and returned with their error, the spec itself
+// is only rejected if it declares no events at all.
+func ParseAsyncAPI(spec []byte) (map[string]*jsonschema.Schema, map[string]error, error) {
+	data, err := yaml.YAMLToJSON(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, nil, err
+	}
+
+	// AsyncAPI 2.x declares the events as channels, AsyncAPI 1.x as topics
+	events, payload := document["channels"], channelPayload
+	if _, ok := events.(map[string]interface{}); !ok {
+		events, payload = document["topics"], topicPayload
+	}
+	eventsByName, ok := events.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("spec declares neither channels nor topics")
+	}
+
+	var names []string
+	var payloads []interface{}
+	invalid := make(map[string]error)
+	for name, event := range eventsByName {
+		p, err := payload(document, event)
+		if err != nil {
+			invalid[name] = err
+			continue
+		}
+		if p == nil {
+			continue
+		}
+		names = append(names, name)
+		payloads = append(payloads, p)
+	}
+
+	schemas, err := compileSchemas(document, names, payloads, invalid)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schemas, invalid, nil
+}
+
+// compileSchemas compiles the payload schemas of the events with the given names, the events whose schema cannot
+// be compiled are added to the invalid events
+func compileSchemas(document map[string]interface{}, names []string, payloads []interface{},
+	invalid map[string]error) (map[string]*jsonschema.Schema, error) {
+	spec := make(map[string]interface{}, len(document)+1)
+	for key, value := range document {
+		spec[key] = value
+	}
+	spec[payloadsKey] = payloads
+	// the schema id of an AsyncAPI spec would change the URL the references are resolved against
+	delete(spec, "$id")
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	// AsyncAPI schemas are a superset of the JSON Schema draft 7
+	compiler.Draft = jsonschema.Draft7
+	// only local references are supported, the validator must not load any other resources
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("unsupported reference %s, only local references are supported", url)
+	}
+	if err := compiler.AddResource(specURL, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]*jsonschema.Schema, len(names))
+	for i, name := range names {
+		schema, err := compiler.Compile(fmt.Sprintf("%s#/%s/%d", specURL, payloadsKey, i))
+		if err != nil {
+			invalid[name] = err
+			continue
+		}
+		schemas[EventTypeKey(name)] = schema
+	}
+	return schemas, nil
+}
+
+// channelPayload returns the payload schema of the message of an AsyncAPI 2.x channel
+func channelPayload(document map[string]interface{}, channel interface{}) (interface{}, error) {
+	for _, operation := range operations {
+		op, err := lookup(document, channel, operation)
+		if err != nil || op == nil {
+			continue
+		}
+		message, err := lookup(document, op, "message")
+		if err != nil {
+			return nil, err
+		}
+		if message == nil {
+			continue
+		}
+		// a channel with several messages accepts the payload of any of them
+		if oneOf, ok := message.(map[string]interface{})["oneOf"].([]interface{}); ok {
+			payloads := make([]interface{}, 0, len(oneOf))
+			for _, m := range oneOf {
+				payload, err := lookup(document, m, "payload")
+				if err != nil {
+					return nil, err
+				}
+				if payload == nil {
+					payload = true
+				}
+				payloads = append(payloads, payload)
+			}
+			return map[string]interface{}{"anyOf": payloads}, nil
+		}
+		return lookup(document, message, "payload")
+	}
+	return nil, nil
+}
+
+// topicPayload returns the payload schema of an AsyncAPI 1.x topic
+func topicPayload(document map[string]interface{}, topic interface{}) (interface{}, error) {
+	for _, operation := range operations {
+		op, err := lookup(document, topic, operation)
+		if err != nil || op == nil {
+			continue
+		}
+		return lookup(document, op, "payload")
+	}
+	return nil, nil
+}
+
+// lookup returns the field of an object, the object and the field can be local references to other parts of
+// the document. The payload itself is returned as it is, because the schema resolves its references.
+func lookup(document map[string]interface{}, object interface{}, field string) (interface{}, error) {
+	resolved, err := resolveReference(document, object)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := resolved.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	value := o[field]
+	if value == nil || field == "payload" {
+		return value, nil
+	}
+	return resolveReference(document, value)
+}
+
+func resolveReference(document map[string]interface{}, object interface{}) (interface{}, error) {
+	o, ok := object.(map[string]interface{})
+	if !ok {
+		return object, nil
+	}
+	ref, ok := o["$ref"].(string)
+	if !ok {
+		return object, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference %s, only local references are supported", ref)
+	}
+	var node interface{} = document
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		n, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %s", ref)
+		}
+		if node, ok = n[token]; !ok {
+			return nil, fmt.Errorf("unresolvable reference %s", ref)
+		}
+	}
+	return node, nil
+}
+
+// EventTypeKey returns the key of an event type and version, e.g. "order.created.v1", as it is used in CE types.
+// Event types with more than two segments are combined the same way as legacy events are converted to CEs, e.g.
+// "account.order.created.v1" becomes "accountorder.created.v1". The key is case-insensitive.
+func EventTypeKey(eventTypeAndVersion string) string {
+	segments := strings.Split(strings.ToLower(eventTypeAndVersion), ".")
+	if len(segments) > 3 {
+		businessObject := strings.Join(segments[:len(segments)-2], "")
+		segments = append([]string{businessObject}, segments[len(segments)-2:]...)
+	}
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"testing"
+)
+
+const asyncAPIv1Spec = `
+asyncapi: "1.0.0"
+info:
+  title: Orders
+  version: "1.0"
+topics:
+  order.created.v1:
+    subscribe:
+      summary: Order created
+      payload:
+        $ref: "#/components/schemas/Order"
+  account.order.deleted.v1:
+    subscribe:
+      payload:
+        type: object
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+`
+
+const asyncAPIv2Spec = `{
+	"asyncapi": "2.0.0",
+	"channels": {
+		"order.created.v1": {
+			"subscribe": {"message": {"$ref": "#/components/messages/OrderCreated"}}
+		},
+		"order.updated.v1": {
+			"publish": {"message": {"oneOf": [{"payload": {"type": "string"}}, {"payload": {"type": "integer"}}]}}
+		},
+		"order.archived.v1": {}
+	},
+	"components": {
+		"messages": {
+			"OrderCreated": {"payload": {"$ref": "#/components/schemas/Order"}}
+		},
+		"schemas": {
+			"Order": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}}
+		}
+	}
+}`
+
+func TestParseAsyncAPI(t *testing.T) {
+	testCases := []struct {
+		name         string
+		givenSpec    string
+		wantEvents   []string
+		wantValid    map[string]string
+		wantInvalid  map[string]string
+		wantSkipped  []string
+		wantParseErr bool
+	}{
+		{
+			name:        "AsyncAPI 1.x",
+			givenSpec:   asyncAPIv1Spec,
+			wantEvents:  []string{"order.created.v1", "accountorder.deleted.v1"},
+			wantValid:   map[string]string{"order.created.v1": `{"id": "1"}`, "accountorder.deleted.v1": `{}`},
+			wantInvalid: map[string]string{"order.created.v1": `{"id": 1}`, "accountorder.deleted.v1": `[]`},
+		},
+		{
+			name:        "AsyncAPI 2.x",
+			givenSpec:   asyncAPIv2Spec,
+			wantEvents:  []string{"order.created.v1", "order.updated.v1"},
+			wantValid:   map[string]string{"order.created.v1": `{"id": "1"}`, "order.updated.v1": `1`},
+			wantInvalid: map[string]string{"order.created.v1": `{}`, "order.updated.v1": `true`},
+		},
+		{
+			name:         "no events",
+			givenSpec:    `{"asyncapi": "2.0.0"}`,
+			wantParseErr: true,
+		},
+		{
+			name: "invalid channels are skipped",
+			givenSpec: `{"asyncapi": "2.0.0", "channels": {
+				"a.b.v1": {"subscribe": {"message": {"$ref": "messages.json"}}},
+				"a.c.v1": {"subscribe": {"message": {"payload": {"$ref": "file:///etc/hosts"}}}},
+				"a.d.v1": {"subscribe": {"message": {"payload": {"type": 1}}}},
+				"a.e.v1": {"subscribe": {"message": {"payload": {"$ref": "#/components/schemas/Missing"}}}},
+				"a.f.v1": {"subscribe": {"message": {"payload": {"type": "string"}}}}
+			}}`,
+			wantEvents:  []string{"a.f.v1"},
+			wantValid:   map[string]string{"a.f.v1": `"f"`},
+			wantInvalid: map[string]string{"a.f.v1": `1`},
+			wantSkipped: []string{"a.b.v1", "a.c.v1", "a.d.v1", "a.e.v1"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			schemas, skipped, err := ParseAsyncAPI([]byte(tc.givenSpec))
+			if tc.wantParseErr {
+				if err == nil {
+					t.Errorf("expected a parse error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse spec: %v", err)
+			}
+			if len(skipped) != len(tc.wantSkipped) {
+				t.Errorf("incorrect number of skipped events, want: %d, got: %v", len(tc.wantSkipped), skipped)
+			}
+			for _, event := range tc.wantSkipped {
+				if _, ok := skipped[event]; !ok {
+					t.Errorf("event %s was not skipped", event)
+				}
+			}
+			if len(schemas) != len(tc.wantEvents) {
+				t.Errorf("incorrect number of schemas, want: %d, got: %d", len(tc.wantEvents), len(schemas))
+			}
+			for _, event := range tc.wantEvents {
+				schema, ok := schemas[event]
+				if !ok {
+					t.Fatalf("missing schema of event %s", event)
+				}
+				if err := validateJSON(schema, []byte(tc.wantValid[event])); err != nil {
+					t.Errorf("expected valid data of event %s, but got: %v", event, err)
+				}
+				if err := validateJSON(schema, []byte(tc.wantInvalid[event])); err == nil {
+					t.Errorf("expected invalid data of event %s", event)
+				}
+			}
+		})
+	}
+}
+
+func TestEventTypeKey(t *testing.T) {
+	testCases := map[string]string{
+		"order.created.v1":         "order.created.v1",
+		"Order.Created.V1":         "order.created.v1",
+		"account.order.created.v1": "accountorder.created.v1",
+		"created.v1":               "created.v1",
+	}
+	for given, want := range testCases {
+		if got := EventTypeKey(given); got != want {
+			t.Errorf("incorrect key of %s, want: %s, got: %s", given, want, got)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// Package validation validates the data of events against the schemas of the events, which are declared in the
+// AsyncAPI specs registered for an Application through the application-registry.
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	applicationv1alpha1 "github.com/kyma-project/kyma/components/application-operator/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application"
+)
+
+const (
+	// ModeAnnotation is the annotation of an Application which enables the validation of the data of its events
+	ModeAnnotation = "eventing.kyma-project.io/payload-validation"
+	// ModeEnforce rejects events whose data does not conform to the schema
+	ModeEnforce Mode = "enforce"
+	// ModeWarn logs events whose data does not conform to the schema, but dispatches them
+	ModeWarn Mode = "warn"
+
+	// eventsEntryType is the type of the service entries of an Application which declare events
+	eventsEntryType = "Events"
+	// asyncAPISourceType is the source type of the AsyncAPI spec in the ClusterAssetGroup of a service
+	asyncAPISourceType = "asyncapi"
+	// defaultSpecRetryInterval is the longest time after which a spec, which could not be fetched, is fetched again
+	defaultSpecRetryInterval = 10 * time.Second
+	// specFetchTimeout is the longest time a spec is fetched, independent of the requests which wait for it
+	specFetchTimeout = 30 * time.Second
+)
+
+// Mode is the validation mode of an Application
+type Mode string
+
+// Error is returned if the data of an event does not conform to the schema of the event
+type Error struct {
+	// Field is the path of the invalid value in the event, e.g. "data/order/id"
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid event data at %s: %s", e.Field, e.Message)
+}
+
+// ApplicationLister lists the Applications
+type ApplicationLister interface {
+	List() ([]*applicationv1alpha1.Application, error)
+}
+
+// Validator validates the data of events against the schemas of the Application which sent the event.
+// A nil Validator accepts every event.
+type Validator struct {
+	eventTypePrefix   string
+	applicationLister ApplicationLister
+	dynamicClient     dynamic.Interface
+	httpClient        *http.Client
+	specCacheTTL      time.Duration
+	specRetryInterval time.Duration
+	logger            *logrus.Logger
+
+	specsLock sync.Mutex
+	// specs caches the schemas of the events of a service by the service ID
+	specs map[string]*cachedSpec
+	// fetches holds the fetches of specs in progress by the service ID, which all requests of a service wait for
+	fetches map[string]*specFetch
+}
+
+type cachedSpec struct {
+	schemas map[string]*jsonschema.Schema
+	expires time.Time
+}
+
+// specFetch is a fetch of a spec in progress, its schemas are set once done is closed
+type specFetch struct {
+	done    chan struct{}
+	schemas map[string]*jsonschema.Schema
+}
+
+// NewValidator returns a new Validator for events with the given event type prefix. The specs of the services are
+// cached for the given TTL. A spec which could not be fetched is fetched again after a short retry interval, in the
+// meantime the schemas fetched before are used. The validation fails open: events whose schema is not available,
+// because the spec was never fetched successfully or is not fetched within the request timeout, are dispatched
+// without validation, also in the enforce mode.
+func NewValidator(eventTypePrefix string, applicationLister ApplicationLister, dynamicClient dynamic.Interface,
+	specCacheTTL time.Duration, logger *logrus.Logger) *Validator {
+	return &Validator{
+		eventTypePrefix:   eventTypePrefix,
+		applicationLister: applicationLister,
+		dynamicClient:     dynamicClient,
+		httpClient:        &http.Client{Timeout: 10 * time.Second},
+		specCacheTTL:      specCacheTTL,
+		specRetryInterval: defaultSpecRetryInterval,
+		logger:            logger,
+		specs:             make(map[string]*cachedSpec),
+		fetches:           make(map[string]*specFetch),
+	}
+}
+
+// ClusterAssetGroupGVR returns the GroupVersionResource of the ClusterAssetGroups holding the specs of the services
+func ClusterAssetGroupGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "rafter.kyma-project.io",
+		Version:  "v1beta1",
+		Resource: "clusterassetgroups",
+	}
+}
+
+// Validate validates the data of the event if the validation is enabled for the Application which sent the event.
+// It returns an *Error if the data does not conform to the schema of the event and the Application enforces the
+// validation. Events without a schema are accepted.
+func (v *Validator) Validate(ctx context.Context, event *cev2event.Event) error {
+	if v == nil {
+		return nil
+	}
+
+	if !isJSON(event.DataContentType()) {
+		return nil
+	}
+
+	app, eventTypeKey := v.resolveApplication(event.Type())
+	if app == nil {
+		return nil
+	}
+	mode := Mode(app.Annotations[ModeAnnotation])
+	if mode != ModeEnforce && mode != ModeWarn {
+		return nil
+	}
+
+	eventSchema := v.getSchema(ctx, app, eventTypeKey)
+	if eventSchema == nil {
+		v.logger.Debugf("No schema found for event type %s of application %s", event.Type(), app.Name)
+		return nil
+	}
+
+	if err := validateData(eventSchema, event); err != nil {
+		validationErr := toError(err)
+		if mode == ModeWarn {
+			v.logger.WithFields(logrus.Fields{
+				"id":          event.ID(),
+				"type":        event.Type(),
+				"application": app.Name,
+			}).Warnf("Event data does not conform to the schema: %s", validationErr)
+			return nil
+		}
+		return validationErr
+	}
+	return nil
+}
+
+// validateData validates the data of an event in JSON, events without data are validated as null
+func validateData(eventSchema *jsonschema.Schema, event *cev2event.Event) error {
+	return validateJSON(eventSchema, event.Data())
+}
+
+// validateJSON validates a JSON document, an empty document is validated as null
+func validateJSON(eventSchema *jsonschema.Schema, data []byte) error {
+	var value interface{}
+	if len(data) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		// the numbers are kept as they are, e.g. large integers are not rounded to float64
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return &Error{Field: "data", Message: fmt.Sprintf("invalid JSON: %v", err)}
+		}
+	}
+	return eventSchema.Validate(value)
+}
+
+// toError returns the error of the first violation of the schema, which is the most specific one
+func toError(err error) *Error {
+	var validationErr *Error
+	if errors.As(err, &validationErr) {
+		return validationErr
+	}
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return &Error{Field: "data", Message: err.Error()}
+	}
+	for len(schemaErr.Causes) > 0 {
+		schemaErr = schemaErr.Causes[0]
+	}
+	return &Error{Field: "data" + schemaErr.InstanceLocation, Message: schemaErr.Message}
+}
+
+// isJSON returns true if the data content type is JSON, which is the default of CEs
+func isJSON(dataContentType string) bool {
+	if len(dataContentType) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(dataContentType)
+	return err == nil && (mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// resolveApplication returns the Application which sent an event of the given type and the key of the event type
+// and version, e.g. "order.created.v1" for the type "sap.kyma.custom.commerce.order.created.v1"
+func (v *Validator) resolveApplication(eventType string) (*applicationv1alpha1.Application, string) {
+	prefix := v.eventTypePrefix + "."
+	if !strings.HasPrefix(eventType, prefix) {
+		return nil, ""
+	}
+	segments := strings.SplitN(strings.TrimPrefix(eventType, prefix), ".", 2)
+	if len(segments) != 2 {
+		return nil, ""
+	}
+
+	applications, err := v.applicationLister.List()
+	if err != nil {
+		v.logger.Errorf("Failed to list applications with error: %s", err)
+		return nil, ""
+	}
+	for _, app := range applications {
+		if application.GetCleanTypeOrName(app) == segments[0] {
+			return app, EventTypeKey(segments[1])
+		}
+	}
+	return nil, ""
+}
+
+// getSchema returns the schema of the event type in the specs of the services of the Application
+func (v *Validator) getSchema(ctx context.Context, app *applicationv1alpha1.Application, eventTypeKey string) *jsonschema.Schema {
+	for _, service := range app.Spec.Services {
+		if !hasEvents(service) {
+			continue
+		}
+		if eventSchema, ok := v.getSpec(ctx, service)[eventTypeKey]; ok {
+			return eventSchema
+		}
+	}
+	return nil
+}
+
+func hasEvents(service applicationv1alpha1.Service) bool {
+	for _, entry := range service.Entries {
+		if entry.Type == eventsEntryType {
+			return true
+		}
+	}
+	return false
+}
+
+// getSpec returns the schemas of the events of a service from the cache or fetches them. The spec is fetched once
+// in the background for all requests which need it, each request waits for the fetch until its context is done and
+// then uses the schemas fetched before, if any.
+func (v *Validator) getSpec(ctx context.Context, service applicationv1alpha1.Service) map[string]*jsonschema.Schema {
+	v.specsLock.Lock()
+	cached, ok := v.specs[service.ID]
+	if ok && time.Now().Before(cached.expires) {
+		v.specsLock.Unlock()
+		return cached.schemas
+	}
+	fetch, fetching := v.fetches[service.ID]
+	if !fetching {
+		fetch = &specFetch{done: make(chan struct{})}
+		v.fetches[service.ID] = fetch
+		go v.refreshSpec(service, fetch, cached)
+	}
+	v.specsLock.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.schemas
+	case <-ctx.Done():
+		v.logger.Warnf("AsyncAPI spec of service %s is not fetched in time, using the schemas fetched before", service.ID)
+		if ok {
+			return cached.schemas
+		}
+		return nil
+	}
+}
+
+// refreshSpec fetches the spec of a service and caches its schemas. If the spec cannot be fetched, the schemas
+// fetched before are kept and the spec is fetched again after the retry interval.
+func (v *Validator) refreshSpec(service applicationv1alpha1.Service, fetch *specFetch, cached *cachedSpec) {
+	ctx, cancel := context.WithTimeout(context.Background(), specFetchTimeout)
+	defer cancel()
+
+	ttl := v.specCacheTTL
+	schemas, err := v.fetchSpec(ctx, service)
+	if err != nil {
+		v.logger.Errorf("Failed to fetch AsyncAPI spec of service %s with error: %s", service.ID, err)
+		if cached != nil {
+			schemas = cached.schemas
+		}
+		if ttl > v.specRetryInterval {
+			ttl = v.specRetryInterval
+		}
+	}
+
+	v.specsLock.Lock()
+	defer v.specsLock.Unlock()
+	v.specs[service.ID] = &cachedSpec{schemas: schemas, expires: time.Now().Add(ttl)}
+	delete(v.fetches, service.ID)
+	fetch.schemas = schemas
+	close(fetch.done)
+}
+
+// fetchSpec downloads the AsyncAPI spec of a service, which the application-registry stored in a ClusterAssetGroup
+// named after the service ID, and parses the schemas of its events
+func (v *Validator) fetchSpec(ctx context.Context, service applicationv1alpha1.Service) (map[string]*jsonschema.Schema, error) {
+	assetGroup, err := v.dynamicClient.Resource(ClusterAssetGroupGVR()).Get(ctx, service.ID, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := asyncAPISourceURL(assetGroup)
+	if err != nil || len(url) == 0 {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := v.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", response.StatusCode, url)
+	}
+	spec, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	schemas, invalid, err := ParseAsyncAPI(spec)
+	for name, err := range invalid {
+		v.logger.Warnf("Skipped invalid event %s in AsyncAPI spec of service %s with error: %s", name, service.ID, err)
+	}
+	return schemas, err
+}
+
+func asyncAPISourceURL(assetGroup *unstructured.Unstructured) (string, error) {
+	sources, _, err := unstructured.NestedSlice(assetGroup.Object, "spec", "sources")
+	if err != nil {
+		return "", err
+	}
+	for _, source := range sources {
+		s, ok := source.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if s["type"] == asyncAPISourceType {
+			url, _ := s["url"].(string)
+			return url, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,293 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	applicationv1alpha1 "github.com/kyma-project/kyma/components/application-operator/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/kyma-project/kyma/components/event-publisher-proxy/pkg/application/applicationtest"
+)
+
+const (
+	eventTypePrefix = "sap.kyma.custom"
+	serviceID       = "2d1f7c8e-1a7e-4a5c-9c4b-3b1a6f0e9d21"
+)
+
+type applicationListerFunc func() ([]*applicationv1alpha1.Application, error)
+
+func (f applicationListerFunc) List() ([]*applicationv1alpha1.Application, error) {
+	return f()
+}
+
+func TestValidate(t *testing.T) {
+	var specRequests int32
+	specServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&specRequests, 1)
+		_, _ = w.Write([]byte(asyncAPIv2Spec))
+	}))
+	defer specServer.Close()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newClusterAssetGroup(serviceID, specServer.URL))
+
+	testCases := []struct {
+		name          string
+		givenMode     Mode
+		givenType     string
+		givenData     string
+		givenDataType string
+		wantErrorPath string
+	}{
+		{
+			name:      "valid data",
+			givenMode: ModeEnforce,
+			givenType: "sap.kyma.custom.commerce.order.created.v1",
+			givenData: `{"id": "1"}`,
+		},
+		{
+			name:          "invalid data is rejected in enforce mode",
+			givenMode:     ModeEnforce,
+			givenType:     "sap.kyma.custom.commerce.order.created.v1",
+			givenData:     `{"id": 1}`,
+			wantErrorPath: "data/id",
+		},
+		{
+			name:      "invalid data is accepted in warn mode",
+			givenMode: ModeWarn,
+			givenType: "sap.kyma.custom.commerce.order.created.v1",
+			givenData: `{"id": 1}`,
+		},
+		{
+			name:      "invalid data is accepted without mode",
+			givenType: "sap.kyma.custom.commerce.order.created.v1",
+			givenData: `{"id": 1}`,
+		},
+		{
+			name:      "event without schema is accepted",
+			givenMode: ModeEnforce,
+			givenType: "sap.kyma.custom.commerce.order.archived.v1",
+			givenData: `{"id": 1}`,
+		},
+		{
+			name:          "data which is not declared as JSON is accepted",
+			givenMode:     ModeEnforce,
+			givenType:     "sap.kyma.custom.commerce.order.created.v1",
+			givenData:     "text",
+			givenDataType: "text/plain",
+		},
+		{
+			name:      "event of unknown application is accepted",
+			givenMode: ModeEnforce,
+			givenType: "sap.kyma.custom.other.order.created.v1",
+			givenData: `{"id": 1}`,
+		},
+		{
+			name:          "missing data is rejected",
+			givenMode:     ModeEnforce,
+			givenType:     "sap.kyma.custom.commerce.order.created.v1",
+			wantErrorPath: "data",
+		},
+		{
+			name:          "data which is not JSON is rejected",
+			givenMode:     ModeEnforce,
+			givenType:     "sap.kyma.custom.commerce.order.created.v1",
+			givenData:     `{`,
+			wantErrorPath: "data",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			app := newApplication("commerce", tc.givenMode)
+			lister := applicationListerFunc(func() ([]*applicationv1alpha1.Application, error) {
+				return []*applicationv1alpha1.Application{app}, nil
+			})
+			validator := NewValidator(eventTypePrefix, lister, dynamicClient, time.Minute, logrus.New())
+
+			event := cev2event.New()
+			event.SetType(tc.givenType)
+			if len(tc.givenDataType) == 0 {
+				tc.givenDataType = cev2event.ApplicationJSON
+			}
+			if len(tc.givenData) > 0 {
+				if err := event.SetData(tc.givenDataType, []byte(tc.givenData)); err != nil {
+					t.Fatalf("failed to set event data: %v", err)
+				}
+			}
+
+			err := validator.Validate(context.Background(), &event)
+			if len(tc.wantErrorPath) == 0 {
+				if err != nil {
+					t.Errorf("expected no error, but got: %v", err)
+				}
+				return
+			}
+			validationErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected a validation error, but got: %v", err)
+			}
+			if validationErr.Field != tc.wantErrorPath {
+				t.Errorf("incorrect error path, want: %s, got: %s", tc.wantErrorPath, validationErr.Field)
+			}
+		})
+	}
+
+	// the spec is fetched once by every validator
+	if got := atomic.LoadInt32(&specRequests); got == 0 || got > int32(len(testCases)) {
+		t.Errorf("incorrect number of spec requests, got: %d", got)
+	}
+}
+
+func TestValidateCachesSpecs(t *testing.T) {
+	var specRequests int32
+	specServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&specRequests, 1)
+		_, _ = w.Write([]byte(asyncAPIv2Spec))
+	}))
+	defer specServer.Close()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newClusterAssetGroup(serviceID, specServer.URL))
+	app := newApplication("commerce", ModeEnforce)
+	lister := applicationListerFunc(func() ([]*applicationv1alpha1.Application, error) {
+		return []*applicationv1alpha1.Application{app}, nil
+	})
+	validator := NewValidator(eventTypePrefix, lister, dynamicClient, time.Minute, logrus.New())
+
+	event := cev2event.New()
+	event.SetType("sap.kyma.custom.commerce.order.created.v1")
+	_ = event.SetData(cev2event.ApplicationJSON, []byte(`{"id": "1"}`))
+	for i := 0; i < 3; i++ {
+		if err := validator.Validate(context.Background(), &event); err != nil {
+			t.Fatalf("expected no error, but got: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&specRequests); got != 1 {
+		t.Errorf("incorrect number of spec requests, want: 1, got: %d", got)
+	}
+}
+
+func TestValidateRetriesFailedSpecs(t *testing.T) {
+	var specRequests int32
+	specServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the spec is not available at the first request
+		if atomic.AddInt32(&specRequests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(asyncAPIv2Spec))
+	}))
+	defer specServer.Close()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newClusterAssetGroup(serviceID, specServer.URL))
+	app := newApplication("commerce", ModeEnforce)
+	lister := applicationListerFunc(func() ([]*applicationv1alpha1.Application, error) {
+		return []*applicationv1alpha1.Application{app}, nil
+	})
+	validator := NewValidator(eventTypePrefix, lister, dynamicClient, time.Minute, logrus.New())
+	validator.specRetryInterval = 10 * time.Millisecond
+
+	event := cev2event.New()
+	event.SetType("sap.kyma.custom.commerce.order.created.v1")
+	_ = event.SetData(cev2event.ApplicationJSON, []byte(`{"id": 1}`))
+	if err := validator.Validate(context.Background(), &event); err != nil {
+		t.Fatalf("expected no error without spec, but got: %v", err)
+	}
+
+	// the spec is fetched again after the retry interval instead of the TTL
+	time.Sleep(20 * time.Millisecond)
+	if err := validator.Validate(context.Background(), &event); err == nil {
+		t.Errorf("expected an error once the spec is fetched")
+	}
+	if got := atomic.LoadInt32(&specRequests); got != 2 {
+		t.Errorf("incorrect number of spec requests, want: 2, got: %d", got)
+	}
+}
+
+func TestValidateFetchesSpecsOnceInBackground(t *testing.T) {
+	var specRequests int32
+	release := make(chan struct{})
+	specServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&specRequests, 1)
+		<-release
+		_, _ = w.Write([]byte(asyncAPIv2Spec))
+	}))
+	defer specServer.Close()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newClusterAssetGroup(serviceID, specServer.URL))
+	app := newApplication("commerce", ModeEnforce)
+	lister := applicationListerFunc(func() ([]*applicationv1alpha1.Application, error) {
+		return []*applicationv1alpha1.Application{app}, nil
+	})
+	validator := NewValidator(eventTypePrefix, lister, dynamicClient, time.Minute, logrus.New())
+
+	event := cev2event.New()
+	event.SetType("sap.kyma.custom.commerce.order.created.v1")
+	_ = event.SetData(cev2event.ApplicationJSON, []byte(`{"id": 1}`))
+
+	// the requests which time out while the spec is fetched are dispatched without validation
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := validator.Validate(ctx, &event); err != nil {
+				t.Errorf("expected no error while the spec is fetched, but got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the fetch is not canceled with the requests and validates the next request
+	close(release)
+	if err := validator.Validate(context.Background(), &event); err == nil {
+		t.Errorf("expected an error once the spec is fetched")
+	}
+	if got := atomic.LoadInt32(&specRequests); got != 1 {
+		t.Errorf("incorrect number of spec requests, want: 1, got: %d", got)
+	}
+}
+
+func TestNilValidator(t *testing.T) {
+	var validator *Validator
+	event := cev2event.New()
+	if err := validator.Validate(context.Background(), &event); err != nil {
+		t.Errorf("expected no error, but got: %v", err)
+	}
+}
+
+func newApplication(name string, mode Mode) *applicationv1alpha1.Application {
+	app := applicationtest.NewApplication(name, nil)
+	if len(mode) > 0 {
+		app.Annotations = map[string]string{ModeAnnotation: string(mode)}
+	}
+	app.Spec.Services = []applicationv1alpha1.Service{
+		{ID: serviceID, Entries: []applicationv1alpha1.Entry{{Type: eventsEntryType}}},
+	}
+	return app
+}
+
+func newClusterAssetGroup(name, specURL string) *unstructured.Unstructured {
+	gvr := ClusterAssetGroupGVR()
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       "ClusterAssetGroup",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"sources": []interface{}{
+				map[string]interface{}{"type": asyncAPISourceType, "url": specURL, "mode": "single"},
+			},
+		},
+	}}
+}
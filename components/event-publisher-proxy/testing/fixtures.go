@@ -128,4 +128,8 @@ const (
             "event-type-version":"` + LegacyEventTypeVersion + `",
             "event-time": "` + LegacyEventTime + `"
         }`
+
+	// batches with a valid and an invalid event
+	StructuredCloudEventBatchPayload = `[` + StructuredCloudEventPayloadWithCleanEventType + `,` + StructuredCloudEventPayloadWithoutSource + `]`
+	LegacyEventBatchPayload          = `[` + ValidLegacyEventPayloadWithEventId + `,` + LegacyEventPayloadWithoutEventType + `]`
 )
@@ -18,6 +18,27 @@ func StartNatsServer() *server.Server {
 	return test.RunServer(&opts)
 }
 
+// StartNatsServerWithJetStream starts a NATS server with JetStream enabled, which keeps file based streams
+// in the given directory.
+func StartNatsServerWithJetStream(storeDir string) *server.Server {
+	opts := test.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = storeDir
+	return test.RunServer(&opts)
+}
+
+// AddStreamOrFail adds a NATS stream with the given name and subjects to the given NATS connection.
+func AddStreamOrFail(t *testing.T, connection *nats.Conn, name string, subjects ...string) {
+	jsContext, err := connection.JetStream()
+	if err != nil {
+		t.Fatalf("Failed to create JetStream context with error: %v", err)
+	}
+	if _, err := jsContext.AddStream(&nats.StreamConfig{Name: name, Subjects: subjects, Storage: nats.MemoryStorage}); err != nil {
+		t.Fatalf("Failed to add stream with error: %v", err)
+	}
+}
+
 func SubscribeToEventOrFail(t *testing.T, connection *nats.Conn, eventType string, validator nats.MsgHandler) {
 	if _, err := connection.Subscribe(eventType, validator); err != nil {
 		t.Fatalf("Failed to subscribe to event with error: %v", err)
@@ -4,22 +4,36 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// +kubebuilder:validation:Enum=BEB;NATS
+// +kubebuilder:validation:Enum=BEB;NATS;KAFKA
 type BackendType string
 
 const (
-	BebBackendType  BackendType = "BEB"
-	NatsBackendType BackendType = "NATS"
+	BebBackendType   BackendType = "BEB"
+	NatsBackendType  BackendType = "NATS"
+	KafkaBackendType BackendType = "KAFKA"
+)
+
+// +kubebuilder:validation:Enum=AtMostOnce;AtLeastOnce
+type NatsDeliveryMode string
+
+const (
+	// NatsDeliveryModeAtMostOnce dispatches events through core NATS, events are lost if they cannot be delivered
+	NatsDeliveryModeAtMostOnce NatsDeliveryMode = "AtMostOnce"
+	// NatsDeliveryModeAtLeastOnce stores events in a persistent stream and dispatches them through durable consumers
+	NatsDeliveryModeAtLeastOnce NatsDeliveryMode = "AtLeastOnce"
 )
 
 // EventingBackendSpec defines the desired state of EventingBackend
 type EventingBackendSpec struct {
-	// For now spec is empty!
+	// Specifies the delivery guarantee of the NATS backend. Allowed values are "AtMostOnce" and "AtLeastOnce".
+	// If not set, the default of the eventing controller is used.
+	// +optional
+	NatsDeliveryMode NatsDeliveryMode `json:"natsDeliveryMode,omitempty"`
 }
 
 // EventingBackendStatus defines the observed state of EventingBackend
 type EventingBackendStatus struct {
-	// Specifies the backend type used. Allowed values are "BEB" and "NATS"
+	// Specifies the backend type used. Allowed values are "BEB", "NATS" and "KAFKA"
 	// +optional
 	Backend BackendType `json:"backendType"`
 
@@ -26,14 +26,16 @@ type Condition struct {
 type ConditionReason string
 
 const (
-	ConditionReasonSubscriptionCreated        ConditionReason = "BEB Subscription created"
-	ConditionReasonSubscriptionCreationFailed ConditionReason = "BEB Subscription creation failed"
-	ConditionReasonSubscriptionActive         ConditionReason = "BEB Subscription active"
-	ConditionReasonSubscriptionNotActive      ConditionReason = "BEB Subscription not active"
-	ConditionReasonSubscriptionDeleted        ConditionReason = "BEB Subscription deleted"
-	ConditionReasonAPIRuleStatusReady         ConditionReason = "APIRule status ready"
-	ConditionReasonAPIRuleStatusNotReady      ConditionReason = "APIRule status not ready"
-	ConditionReasonNATSSubscriptionActive     ConditionReason = "NATS Subscription active"
+	ConditionReasonSubscriptionCreated          ConditionReason = "BEB Subscription created"
+	ConditionReasonSubscriptionCreationFailed   ConditionReason = "BEB Subscription creation failed"
+	ConditionReasonSubscriptionSpecNotSupported ConditionReason = "BEB Subscription spec not supported"
+	ConditionReasonSubscriptionActive           ConditionReason = "BEB Subscription active"
+	ConditionReasonSubscriptionNotActive        ConditionReason = "BEB Subscription not active"
+	ConditionReasonSubscriptionDeleted          ConditionReason = "BEB Subscription deleted"
+	ConditionReasonAPIRuleStatusReady           ConditionReason = "APIRule status ready"
+	ConditionReasonAPIRuleStatusNotReady        ConditionReason = "APIRule status not ready"
+	ConditionReasonNATSSubscriptionActive       ConditionReason = "NATS Subscription active"
+	ConditionReasonKafkaSubscriptionActive      ConditionReason = "Kafka Subscription active"
 )
 
 // InitializeConditions sets unset conditions to Unknown
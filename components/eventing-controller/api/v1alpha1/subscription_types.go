@@ -51,9 +51,14 @@ const (
 	ProtocolSettingsContentModeStructured string = "STRUCTURED"
 )
 
+const (
+	FilterTypeExact  string = "exact"
+	FilterTypePrefix string = "prefix"
+)
+
 // Filter defines the CE filter element
 type Filter struct {
-	// Type defines the type of the filter
+	// Type defines the type of the filter, either "exact" or "prefix". The NATS backend defaults to "exact"
 	// +optional
 	Type string `json:"type,omitempty"`
 
@@ -71,6 +76,10 @@ type BebFilter struct {
 
 	// EventType defines the type of CE filter
 	EventType *Filter `json:"eventType"`
+
+	// Extensions defines the filters on the extension attributes of CE, supported by NATS only
+	// +optional
+	Extensions []*Filter `json:"extensions,omitempty"`
 }
 
 func (bf *BebFilter) hash() (uint64, error) {
@@ -83,13 +92,19 @@ type BebFilters struct {
 	Dialect string `json:"dialect,omitempty"`
 
 	Filters []*BebFilter `json:"filters"`
+
+	// Expression defines a CloudEvents SQL expression, which the events have to match in addition to the filters,
+	// supported by NATS only
+	// +optional
+	Expression string `json:"expression,omitempty"`
 }
 
 // Deduplicate returns a deduplicated copy of BebFilters
 func (bf *BebFilters) Deduplicate() (*BebFilters, error) {
 	seen := map[uint64]struct{}{}
 	result := &BebFilters{
-		Dialect: bf.Dialect,
+		Dialect:    bf.Dialect,
+		Expression: bf.Expression,
 	}
 	for _, f := range bf.Filters {
 		h, err := f.hash()
@@ -104,6 +119,42 @@ func (bf *BebFilters) Deduplicate() (*BebFilters, error) {
 	return result, nil
 }
 
+// BackoffStrategy defines how the delay between two delivery attempts grows
+// +kubebuilder:validation:Enum=Constant;Linear;Exponential
+type BackoffStrategy string
+
+const (
+	BackoffStrategyConstant    BackoffStrategy = "Constant"
+	BackoffStrategyLinear      BackoffStrategy = "Linear"
+	BackoffStrategyExponential BackoffStrategy = "Exponential"
+)
+
+// DeliveryPolicy defines how often an event is delivered to the sink and what happens to events which cannot be delivered
+type DeliveryPolicy struct {
+	// MaxAttempts defines the maximum number of attempts to deliver an event to the sink.
+	// For BEB, a value of 1 disables the redelivery of events if no Qos is set in the protocol settings.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+
+	// BackoffStrategy defines how the delay between two delivery attempts grows, supported by NATS only
+	// +optional
+	BackoffStrategy BackoffStrategy `json:"backoffStrategy,omitempty"`
+
+	// BackoffDelay defines the delay of the first retry, supported by NATS only
+	// +optional
+	BackoffDelay *metav1.Duration `json:"backoffDelay,omitempty"`
+
+	// MaxBackoff defines the maximum delay between two delivery attempts, supported by NATS only
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+
+	// DeadLetterSink defines the endpoint which receives the events that could not be delivered to the sink,
+	// supported by NATS only
+	// +optional
+	DeadLetterSink string `json:"deadLetterSink,omitempty"`
+}
+
 // SubscriptionSpec defines the desired state of Subscription
 type SubscriptionSpec struct {
 	// ID is the unique identifier of Subscription, read-only.
@@ -123,6 +174,22 @@ type SubscriptionSpec struct {
 
 	// Filter defines the list of filters
 	Filter *BebFilters `json:"filter"`
+
+	// DeliveryPolicy defines the retries and the dead-letter sink of the events
+	// +optional
+	DeliveryPolicy *DeliveryPolicy `json:"deliveryPolicy,omitempty"`
+
+	// MaxInFlight defines the maximum number of events which are delivered to the sink at the same time,
+	// supported by NATS only. The events are delivered one after the other by default
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxInFlight *int `json:"maxInFlight,omitempty"`
+
+	// MaxDeliveryRate defines the maximum number of events per second which are delivered to the sink,
+	// supported by NATS only. The delivery rate is not limited by default
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxDeliveryRate *int `json:"maxDeliveryRate,omitempty"`
 }
 
 type EmsSubscriptionStatus struct {
@@ -147,6 +214,69 @@ type EmsSubscriptionStatus struct {
 	LastFailedDeliveryReason string `json:"lastFailedDeliveryReason,omitempty"`
 }
 
+// DispatcherStatus defines the state of the delivery of events to the sink
+type DispatcherStatus struct {
+	// Backlog defines the number of events which wait for their delivery
+	Backlog int64 `json:"backlog"`
+
+	// InFlight defines the number of events which are being delivered
+	InFlight int64 `json:"inFlight"`
+
+	// Throttled defines whether events wait, because the maximum number of in-flight events
+	// or the maximum delivery rate is reached
+	Throttled bool `json:"throttled"`
+
+	// Delivered defines the number of events which were delivered to the sink
+	// +optional
+	Delivered int64 `json:"delivered,omitempty"`
+
+	// Failed defines the number of failed attempts to deliver an event to the sink
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+}
+
+// ReplayAnnotation requests the NATS backend to redeliver the persisted events to the sink of a subscription. Its
+// value is the start of the replay, either a time in RFC3339 format or a sequence number of the stream.
+const ReplayAnnotation = "eventing.kyma-project.io/replay"
+
+// ReplayPhase defines the phase of a replay
+type ReplayPhase string
+
+const (
+	ReplayPhaseRunning   ReplayPhase = "Running"
+	ReplayPhaseSucceeded ReplayPhase = "Succeeded"
+	ReplayPhaseFailed    ReplayPhase = "Failed"
+)
+
+// ReplayStatus defines the state of the redelivery of persisted events to the sink
+type ReplayStatus struct {
+	// Request defines the value of the replay annotation which is replayed
+	Request string `json:"request"`
+
+	// Phase defines the phase of the replay
+	Phase ReplayPhase `json:"phase"`
+
+	// StartTime defines the time the replay started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime defines the time the replay succeeded or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Replayed defines the number of events which were redelivered to the sink
+	// +optional
+	Replayed int64 `json:"replayed,omitempty"`
+
+	// Failed defines the number of events which could not be redelivered to the sink
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+
+	// Message defines the reason of a failed replay
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // SubscriptionStatus defines the observed state of Subscription
 // +kubebuilder:subresource:status
 type SubscriptionStatus struct {
@@ -177,9 +307,18 @@ type SubscriptionStatus struct {
 	// +optional
 	APIRuleName string `json:"apiRuleName,omitempty"`
 
-	// EmsSubscriptionStatus defines the status of Subscription in BEB
+	// EmsSubscriptionStatus defines the status of Subscription in BEB, the NATS backend reports the
+	// last deliveries only
 	// +optional
 	EmsSubscriptionStatus EmsSubscriptionStatus `json:"emsSubscriptionStatus,omitempty"`
+
+	// Dispatcher defines the state of the delivery of events to the sink, reported by NATS only
+	// +optional
+	Dispatcher *DispatcherStatus `json:"dispatcher,omitempty"`
+
+	// Replay defines the state of the latest replay of persisted events, reported by NATS only
+	// +optional
+	Replay *ReplayStatus `json:"replay,omitempty"`
 }
 
 // +kubebuilder:object:root=true
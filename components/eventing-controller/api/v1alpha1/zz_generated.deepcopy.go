@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -21,6 +22,17 @@ func (in *BebFilter) DeepCopyInto(out *BebFilter) {
 		*out = new(Filter)
 		**out = **in
 	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]*Filter, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Filter)
+				**out = **in
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BebFilter.
@@ -75,6 +87,51 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeliveryPolicy) DeepCopyInto(out *DeliveryPolicy) {
+	*out = *in
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int)
+		**out = **in
+	}
+	if in.BackoffDelay != nil {
+		in, out := &in.BackoffDelay, &out.BackoffDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeliveryPolicy.
+func (in *DeliveryPolicy) DeepCopy() *DeliveryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliveryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DispatcherStatus) DeepCopyInto(out *DispatcherStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DispatcherStatus.
+func (in *DispatcherStatus) DeepCopy() *DispatcherStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DispatcherStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmsSubscriptionStatus) DeepCopyInto(out *EmsSubscriptionStatus) {
 	*out = *in
@@ -244,6 +301,29 @@ func (in *ProtocolSettings) DeepCopy() *ProtocolSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplayStatus) DeepCopyInto(out *ReplayStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplayStatus.
+func (in *ReplayStatus) DeepCopy() *ReplayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Subscription) DeepCopyInto(out *Subscription) {
 	*out = *in
@@ -316,6 +396,21 @@ func (in *SubscriptionSpec) DeepCopyInto(out *SubscriptionSpec) {
 		*out = new(BebFilters)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeliveryPolicy != nil {
+		in, out := &in.DeliveryPolicy, &out.DeliveryPolicy
+		*out = new(DeliveryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxInFlight != nil {
+		in, out := &in.MaxInFlight, &out.MaxInFlight
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxDeliveryRate != nil {
+		in, out := &in.MaxDeliveryRate, &out.MaxDeliveryRate
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionSpec.
@@ -339,6 +434,16 @@ func (in *SubscriptionStatus) DeepCopyInto(out *SubscriptionStatus) {
 		}
 	}
 	out.EmsSubscriptionStatus = in.EmsSubscriptionStatus
+	if in.Dispatcher != nil {
+		in, out := &in.Dispatcher, &out.Dispatcher
+		*out = new(DispatcherStatus)
+		**out = **in
+	}
+	if in.Replay != nil {
+		in, out := &in.Replay, &out.Replay
+		*out = new(ReplayStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionStatus.
@@ -14,6 +14,7 @@ import (
 	"github.com/kyma-project/kyma/components/eventing-controller/logger"
 	"github.com/kyma-project/kyma/components/eventing-controller/options"
 	"github.com/kyma-project/kyma/components/eventing-controller/pkg/commander/beb"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/commander/kafka"
 	"github.com/kyma-project/kyma/components/eventing-controller/pkg/commander/nats"
 	"github.com/kyma-project/kyma/components/eventing-controller/reconciler/backend"
 )
@@ -79,6 +80,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	kafkaCommander := kafka.NewCommander(restCfg, opts.MetricsAddr)
+	if err := kafkaCommander.Init(mgr); err != nil {
+		setupLogger.Error(err, "unable to initialize the Kafka commander")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck(opts.HealthEndpoint, healthz.Ping); err != nil {
 		setupLogger.Error(err, "unable to set up health check: %v")
 		os.Exit(1)
@@ -91,7 +98,7 @@ func main() {
 	// Start the backend manager.
 	ctx := context.Background()
 	recorder := mgr.GetEventRecorderFor("backend-controller")
-	backendReconciler := backend.NewReconciler(ctx, natsCommander, bebCommander, mgr.GetClient(), mgr.GetCache(), ctrLogger, recorder)
+	backendReconciler := backend.NewReconciler(ctx, natsCommander, bebCommander, kafkaCommander, mgr.GetClient(), mgr.GetCache(), ctrLogger, recorder)
 	if err := backendReconciler.SetupWithManager(mgr); err != nil {
 		setupLogger.Error(err, "unable to start the backend controller")
 		os.Exit(1)
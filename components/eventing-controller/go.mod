@@ -3,6 +3,7 @@ module github.com/kyma-project/kyma/components/eventing-controller
 go 1.14
 
 require (
+	github.com/Shopify/sarama v1.27.2
 	github.com/avast/retry-go v2.4.1+incompatible
 	github.com/cloudevents/sdk-go/v2 v2.3.1
 	github.com/go-logr/logr v0.4.0
@@ -19,9 +20,11 @@ require (
 	github.com/ory/hydra-maester v0.0.23
 	github.com/ory/oathkeeper-maester v0.1.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.7.1
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/zap v1.16.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
 	golang.org/x/tools v0.1.0 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
 	k8s.io/api v0.20.7
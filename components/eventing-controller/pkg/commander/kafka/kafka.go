@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/commander"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/application"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers"
+	subscription "github.com/kyma-project/kyma/components/eventing-controller/reconciler/subscription-kafka"
+)
+
+// AddToScheme adds the own schemes to the runtime scheme.
+func AddToScheme(scheme *runtime.Scheme) error {
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := eventingv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Commander implements the Commander interface.
+type Commander struct {
+	cancel      context.CancelFunc
+	envCfg      env.KafkaConfig
+	restCfg     *rest.Config
+	metricsAddr string
+	mgr         manager.Manager
+	backend     handlers.MessagingBackend
+}
+
+// NewCommander creates the Commander for Kafka and initializes it as far as it
+// does not depend on non-common options.
+func NewCommander(restCfg *rest.Config, metricsAddr string) *Commander {
+	return &Commander{
+		envCfg:      env.GetKafkaConfig(),
+		restCfg:     restCfg,
+		metricsAddr: metricsAddr,
+	}
+}
+
+// Init implements the Commander interface.
+func (c *Commander) Init(mgr manager.Manager) error {
+	c.mgr = mgr
+	return nil
+}
+
+// Start implements the Commander interface and starts the commander. The brokers and the topic of the
+// EventingBackend secret take precedence over the default ones.
+func (c *Commander) Start(params commander.Params) error {
+	if brokers, ok := params["brokers"].(string); ok && len(brokers) > 0 {
+		c.envCfg.Brokers = splitBrokers(brokers)
+	}
+	if topic, ok := params["topic"].(string); ok && len(topic) > 0 {
+		c.envCfg.Topic = topic
+	}
+	if len(c.envCfg.Brokers) == 0 {
+		return fmt.Errorf("no Kafka brokers are configured")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.cancel = cancel
+	dynamicClient := dynamic.NewForConfigOrDie(c.restCfg)
+	applicationLister := application.NewLister(ctx, dynamicClient)
+	kafkaReconciler := subscription.NewReconciler(
+		ctx,
+		c.mgr.GetClient(),
+		applicationLister,
+		c.mgr.GetCache(),
+		ctrl.Log.WithName("reconciler").WithName("Subscription"),
+		c.mgr.GetEventRecorderFor("eventing-controller-kafka"),
+		c.envCfg,
+	)
+	c.backend = kafkaReconciler.Backend
+	if err := kafkaReconciler.SetupUnmanaged(c.mgr); err != nil {
+		return fmt.Errorf("unable to setup the Kafka subscription controller: %v", err)
+	}
+	return nil
+}
+
+// Stop implements the Commander interface and stops the commander.
+func (c *Commander) Stop() error {
+	c.cancel()
+
+	dynamicClient := dynamic.NewForConfigOrDie(c.restCfg)
+	return cleanup(c.backend, dynamicClient)
+}
+
+// splitBrokers returns the addresses of a comma-separated list of Kafka brokers
+func splitBrokers(brokers string) []string {
+	var result []string
+	for _, broker := range strings.Split(brokers, ",") {
+		if broker = strings.TrimSpace(broker); len(broker) > 0 {
+			result = append(result, broker)
+		}
+	}
+	return result
+}
+
+// cleanup removes the statuses of the subscriptions and stops the Kafka consumers. The consumer groups
+// are kept, so that the subscriptions continue where they stopped if the Kafka backend is chosen again.
+func cleanup(backend handlers.MessagingBackend, dynamicClient dynamic.Interface) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := ctrl.Log.WithName("eventing-controller-kafka-cleaner").WithName("Subscription")
+	isCleanupSuccessful := true
+	if kafkaBackend, ok := backend.(*handlers.Kafka); ok {
+		kafkaBackend.Close()
+	} else {
+		isCleanupSuccessful = false
+		kafkaBackendErr := errors.New("failed to convert backend to handlers.Kafka")
+		logger.Error(kafkaBackendErr, "no Kafka backend exists")
+	}
+
+	// Fetch all subscriptions.
+	subscriptionsUnstructured, err := dynamicClient.Resource(handlers.SubscriptionGroupVersionResource()).Namespace(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list subscriptions")
+	}
+	subs, err := handlers.ToSubscriptionList(subscriptionsUnstructured)
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert to subscriptionList from unstructured list")
+	}
+
+	for _, sub := range subs.Items {
+		// Clean statuses.
+		subKey := types.NamespacedName{
+			Namespace: sub.Namespace,
+			Name:      sub.Name,
+		}
+		desiredSub := handlers.RemoveStatus(sub)
+		err := handlers.UpdateSubscriptionStatus(ctx, dynamicClient, desiredSub)
+		if err != nil {
+			isCleanupSuccessful = false
+			logger.Error(err, fmt.Sprintf("failed to update status of Subscription: %s", subKey.String()))
+		}
+	}
+
+	if isCleanupSuccessful {
+		logger.Info("Cleanup process succeeded!")
+	}
+	return nil
+}
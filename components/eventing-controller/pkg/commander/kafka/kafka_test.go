@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/commander/fake"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/kafka/kafkatest"
+	controllertesting "github.com/kyma-project/kyma/components/eventing-controller/testing"
+)
+
+func TestCleanup(t *testing.T) {
+	kafkaCommander := fake.Commander{}
+	g := gomega.NewWithT(t)
+	ctx := context.Background()
+	log := ctrl.Log.WithName("test-cleaner-kafka")
+
+	// Create test subscription
+	testSub := controllertesting.NewSubscription("test", "test", controllertesting.WithFakeSubscriptionStatus, controllertesting.WithEventTypeFilter)
+	testSub.Spec.Sink = "http://localhost:8082/store"
+
+	// Create Kafka stand-in
+	cluster := kafkatest.NewCluster()
+
+	envConf := env.KafkaConfig{
+		Topic:                  "kyma-eventing",
+		TopicPartitions:        1,
+		TopicReplicationFactor: 1,
+		ConsumerGroupPrefix:    "kyma-eventing.",
+		EventTypePrefix:        controllertesting.EventTypePrefix,
+	}
+	kafkaBackend := handlers.NewKafka(envConf, cluster, log)
+	kafkaCommander.Backend = kafkaBackend
+	err := kafkaCommander.Backend.Initialize(env.Config{})
+	g.Expect(err).To(gomega.BeNil())
+
+	// Create fake Dynamic clients
+	fakeClient, err := controllertesting.NewFakeSubscriptionClient(testSub)
+	g.Expect(err).To(gomega.BeNil())
+	kafkaCommander.Client = fakeClient
+
+	// Create Kafka consumers
+	_, err = kafkaCommander.Backend.SyncSubscription(testSub, &fake.Cleaner{})
+	g.Expect(err).To(gomega.BeNil())
+	group := "kyma-eventing.test.test"
+	g.Eventually(func() bool { return cluster.HasGroup(group) }).Should(gomega.BeTrue())
+
+	// Then
+	err = cleanup(kafkaCommander.Backend, kafkaCommander.Client)
+	g.Expect(err).To(gomega.BeNil())
+
+	// Expect
+	unstructuredSub, err := kafkaCommander.Client.Resource(controllertesting.SubscriptionGroupVersionResource()).Namespace("test").Get(ctx, testSub.Name, metav1.GetOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	gotSub, err := controllertesting.ToSubscription(unstructuredSub)
+	g.Expect(err).To(gomega.BeNil())
+	expectedSubStatus := eventingv1alpha1.SubscriptionStatus{}
+	g.Expect(expectedSubStatus).To(gomega.Equal(gotSub.Status))
+
+	// The consumer group is kept for the next start of the Kafka backend
+	g.Expect(cluster.HasGroup(group)).To(gomega.BeTrue())
+}
+
+func TestSplitBrokers(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(splitBrokers("kafka-0:9092, kafka-1:9092,")).To(gomega.Equal([]string{"kafka-0:9092", "kafka-1:9092"}))
+	g.Expect(splitBrokers(" ")).To(gomega.BeEmpty())
+}
@@ -62,12 +62,23 @@ func (c *Commander) Init(mgr manager.Manager) error {
 	if len(c.envCfg.Url) == 0 {
 		return fmt.Errorf("env var URL must be a non-empty value")
 	}
+	if err := validateDeliveryMode(c.envCfg.DeliveryMode); err != nil {
+		return err
+	}
 	c.mgr = mgr
 	return nil
 }
 
 // Start implements the Commander interface and starts the commander.
-func (c *Commander) Start(_ commander.Params) error {
+func (c *Commander) Start(params commander.Params) error {
+	// The delivery mode of the EventingBackend takes precedence over the default one
+	if deliveryMode, ok := params["delivery_mode"].(string); ok && len(deliveryMode) > 0 {
+		if err := validateDeliveryMode(deliveryMode); err != nil {
+			return err
+		}
+		c.envCfg.DeliveryMode = deliveryMode
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	c.cancel = cancel
@@ -97,6 +108,15 @@ func (c *Commander) Stop() error {
 	return cleanup(c.backend, dynamicClient)
 }
 
+func validateDeliveryMode(deliveryMode string) error {
+	switch eventingv1alpha1.NatsDeliveryMode(deliveryMode) {
+	case eventingv1alpha1.NatsDeliveryModeAtMostOnce, eventingv1alpha1.NatsDeliveryModeAtLeastOnce:
+		return nil
+	default:
+		return fmt.Errorf("invalid NATS delivery mode: %s", deliveryMode)
+	}
+}
+
 // clean removes all NATS artifacts.
 func cleanup(backend handlers.MessagingBackend, dynamicClient dynamic.Interface) error {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -90,7 +90,7 @@ func NewBEBPublisherDeployment(publisherConfig env.PublisherConfig) *appsv1.Depl
 	}
 }
 
-func NewNATSPublisherDeployment(publisherConfig env.PublisherConfig) *appsv1.Deployment {
+func NewNATSPublisherDeployment(publisherConfig env.PublisherConfig, deliveryMode string) *appsv1.Deployment {
 	labels := map[string]string{
 		AppLabelKey:       PublisherName,
 		instanceLabelKey:  instanceLabelValue,
@@ -117,7 +117,55 @@ func NewNATSPublisherDeployment(publisherConfig env.PublisherConfig) *appsv1.Dep
 							Name:            PublisherName,
 							Image:           publisherConfig.Image,
 							Ports:           getContainerPorts(),
-							Env:             getNATSEnvVars(),
+							Env:             getNATSEnvVars(deliveryMode),
+							LivenessProbe:   getLivenessProbe(),
+							ReadinessProbe:  getReadinessProbe(),
+							ImagePullPolicy: getImagePullPolicy(publisherConfig.ImagePullPolicy),
+							SecurityContext: getSecurityContext(),
+							Resources: getResources(publisherConfig.RequestsCPU,
+								publisherConfig.RequestsMemory,
+								publisherConfig.LimitsCPU,
+								publisherConfig.LimitsMemory),
+						},
+					},
+					RestartPolicy:                 v1.RestartPolicyAlways,
+					ServiceAccountName:            publisherConfig.ServiceAccount,
+					TerminationGracePeriodSeconds: &TerminationGracePeriodSeconds,
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{},
+	}
+}
+
+func NewKafkaPublisherDeployment(publisherConfig env.PublisherConfig, brokers, topic string) *appsv1.Deployment {
+	labels := map[string]string{
+		AppLabelKey:       PublisherName,
+		instanceLabelKey:  instanceLabelValue,
+		dashboardLabelKey: dashboardLabelValue,
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PublisherName,
+			Namespace: PublisherNamespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+
+			Replicas: utils.Int32Ptr(publisherConfig.Replicas),
+			Selector: metav1.SetAsLabelSelector(labels),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   PublisherName,
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:            PublisherName,
+							Image:           publisherConfig.Image,
+							Ports:           getContainerPorts(),
+							Env:             getKafkaEnvVars(brokers, topic),
 							LivenessProbe:   getLivenessProbe(),
 							ReadinessProbe:  getReadinessProbe(),
 							ImagePullPolicy: getImagePullPolicy(publisherConfig.ImagePullPolicy),
@@ -254,7 +302,7 @@ func getBEBEnvVars() []v1.EnvVar {
 	}
 }
 
-func getNATSEnvVars() []v1.EnvVar {
+func getNATSEnvVars(deliveryMode string) []v1.EnvVar {
 	return []v1.EnvVar{
 		{Name: "BACKEND", Value: "nats"},
 		{Name: "PORT", Value: strconv.Itoa(int(publisherPortNum))},
@@ -263,6 +311,20 @@ func getNATSEnvVars() []v1.EnvVar {
 		{Name: "LEGACY_NAMESPACE", Value: "kyma"},
 		{Name: "LEGACY_EVENT_TYPE_PREFIX", Value: "sap.kyma.custom"},
 		{Name: "EVENT_TYPE_PREFIX", Value: "sap.kyma.custom"},
+		{Name: "NATS_DELIVERY_MODE", Value: deliveryMode},
+	}
+}
+
+func getKafkaEnvVars(brokers, topic string) []v1.EnvVar {
+	return []v1.EnvVar{
+		{Name: "BACKEND", Value: "kafka"},
+		{Name: "PORT", Value: strconv.Itoa(int(publisherPortNum))},
+		{Name: "KAFKA_BROKERS", Value: brokers},
+		{Name: "KAFKA_TOPIC", Value: topic},
+		{Name: "REQUEST_TIMEOUT", Value: "5s"},
+		{Name: "LEGACY_NAMESPACE", Value: "kyma"},
+		{Name: "LEGACY_EVENT_TYPE_PREFIX", Value: "sap.kyma.custom"},
+		{Name: "EVENT_TYPE_PREFIX", Value: "sap.kyma.custom"},
 	}
 }
 
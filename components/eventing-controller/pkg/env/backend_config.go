@@ -12,6 +12,12 @@ type BackendConfig struct {
 
 	BackendCRNamespace string `envconfig:"BACKEND_CR_NAMESPACE" default:"kyma-system"`
 	BackendCRName      string `envconfig:"BACKEND_CR_NAME" default:"eventing-backend"`
+
+	// DefaultNatsDeliveryMode is used if the EventingBackend resource does not specify a NATS delivery mode
+	DefaultNatsDeliveryMode string `envconfig:"NATS_DELIVERY_MODE" default:"AtMostOnce"`
+
+	// DefaultKafkaTopic is used if the Kafka backend secret does not specify a topic
+	DefaultKafkaTopic string `envconfig:"KAFKA_TOPIC" default:"kyma-eventing"`
 }
 
 type PublisherConfig struct {
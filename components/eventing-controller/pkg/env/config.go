@@ -13,8 +13,9 @@ import (
 const (
 	BACKEND = "BACKEND"
 
-	BACKEND_VALUE_BEB  = "BEB"
-	BACKEND_VALUE_NATS = "NATS"
+	BACKEND_VALUE_BEB   = "BEB"
+	BACKEND_VALUE_NATS  = "NATS"
+	BACKEND_VALUE_KAFKA = "KAFKA"
 )
 
 // Backend returns the selected backend based on the environment variable
@@ -25,6 +26,8 @@ func Backend() (string, error) {
 	switch backend {
 	case BACKEND_VALUE_BEB:
 		return BACKEND_VALUE_BEB, nil
+	case BACKEND_VALUE_KAFKA:
+		return BACKEND_VALUE_KAFKA, nil
 	case BACKEND_VALUE_NATS, "":
 		return BACKEND_VALUE_NATS, nil
 	default:
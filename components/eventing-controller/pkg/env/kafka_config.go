@@ -0,0 +1,44 @@
+package env
+
+import (
+	"log"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// KafkaConfig represents the environment config for the Eventing Controller with Kafka.
+type KafkaConfig struct {
+	// Brokers are the bootstrap brokers of the Kafka cluster, the backend secret takes precedence
+	Brokers  []string `envconfig:"KAFKA_BROKERS"`
+	ClientID string   `envconfig:"KAFKA_CLIENT_ID" default:"eventing-controller"`
+
+	// Topic is the topic which holds the events of all types, the backend secret takes precedence.
+	// It is created with the given number of partitions and replicas if it does not exist.
+	Topic                  string `envconfig:"KAFKA_TOPIC" default:"kyma-eventing"`
+	TopicPartitions        int32  `envconfig:"KAFKA_TOPIC_PARTITIONS" default:"3"`
+	TopicReplicationFactor int16  `envconfig:"KAFKA_TOPIC_REPLICATION_FACTOR" default:"1"`
+
+	// ConsumerGroupPrefix is the prefix of the consumer group of every subscription
+	ConsumerGroupPrefix string `envconfig:"KAFKA_CONSUMER_GROUP_PREFIX" default:"kyma-eventing."`
+	// FetchMaxWait is the maximum time a fetch request waits for new events
+	FetchMaxWait time.Duration `envconfig:"KAFKA_FETCH_MAX_WAIT" default:"500ms"`
+
+	// EventTypePrefix prefix for the EventType
+	// note: eventType format is <prefix>.<application>.<event>.<version>
+	EventTypePrefix string `envconfig:"EVENT_TYPE_PREFIX" required:"true"`
+
+	// HTTP Transport config for the message dispatcher
+	MaxIdleConns        int           `envconfig:"MAX_IDLE_CONNS" default:"50"`
+	MaxConnsPerHost     int           `envconfig:"MAX_CONNS_PER_HOST" default:"50"`
+	MaxIdleConnsPerHost int           `envconfig:"MAX_IDLE_CONNS_PER_HOST" default:"50"`
+	IdleConnTimeout     time.Duration `envconfig:"IDLE_CONN_TIMEOUT" default:"10s"`
+}
+
+func GetKafkaConfig() KafkaConfig {
+	cfg := KafkaConfig{}
+	if err := envconfig.Process("", &cfg); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	return cfg
+}
@@ -0,0 +1,49 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_GetKafkaConfig(t *testing.T) {
+	fetchMaxWait := time.Second * 2
+
+	envs := map[string]string{
+		"KAFKA_BROKERS":                  "kafka-0:9092,kafka-1:9092",
+		"KAFKA_CLIENT_ID":                "KAFKA_CLIENT_ID",
+		"KAFKA_TOPIC":                    "KAFKA_TOPIC",
+		"KAFKA_TOPIC_PARTITIONS":         "6",
+		"KAFKA_TOPIC_REPLICATION_FACTOR": "3",
+		"KAFKA_CONSUMER_GROUP_PREFIX":    "KAFKA_CONSUMER_GROUP_PREFIX",
+		"KAFKA_FETCH_MAX_WAIT":           fmt.Sprintf("%v", fetchMaxWait),
+		"EVENT_TYPE_PREFIX":              "EVENT_TYPE_PREFIX",
+	}
+
+	g := NewGomegaWithT(t)
+	defer func() {
+		for k := range envs {
+			err := os.Unsetenv(k)
+			g.Expect(err).ShouldNot(HaveOccurred())
+		}
+	}()
+
+	for k, v := range envs {
+		err := os.Setenv(k, v)
+		g.Expect(err).ShouldNot(HaveOccurred())
+	}
+
+	config := GetKafkaConfig()
+
+	g.Expect(config.Brokers).To(Equal([]string{"kafka-0:9092", "kafka-1:9092"}))
+	g.Expect(config.ClientID).To(Equal(envs["KAFKA_CLIENT_ID"]))
+	g.Expect(config.Topic).To(Equal(envs["KAFKA_TOPIC"]))
+	g.Expect(config.TopicPartitions).To(BeEquivalentTo(6))
+	g.Expect(config.TopicReplicationFactor).To(BeEquivalentTo(3))
+	g.Expect(config.ConsumerGroupPrefix).To(Equal(envs["KAFKA_CONSUMER_GROUP_PREFIX"]))
+	g.Expect(config.FetchMaxWait).To(Equal(fetchMaxWait))
+	g.Expect(config.EventTypePrefix).To(Equal(envs["EVENT_TYPE_PREFIX"]))
+}
@@ -23,6 +23,23 @@ type NatsConfig struct {
 	MaxConnsPerHost     int           `envconfig:"MAX_CONNS_PER_HOST" default:"50"`
 	MaxIdleConnsPerHost int           `envconfig:"MAX_IDLE_CONNS_PER_HOST" default:"50"`
 	IdleConnTimeout     time.Duration `envconfig:"IDLE_CONN_TIMEOUT" default:"10s"`
+
+	// DeliveryMode is the default delivery guarantee of the NATS backend, either "AtMostOnce" or "AtLeastOnce".
+	// It can be overridden in the EventingBackend resource.
+	DeliveryMode string `envconfig:"NATS_DELIVERY_MODE" default:"AtMostOnce"`
+
+	// Persistent stream config, used only by the "AtLeastOnce" delivery mode
+	StreamName        string        `envconfig:"NATS_STREAM_NAME" default:"kyma"`
+	StreamStorageType string        `envconfig:"NATS_STREAM_STORAGE_TYPE" default:"file"`
+	StreamMaxAge      time.Duration `envconfig:"NATS_STREAM_MAX_AGE" default:"24h"`
+	// StreamDuplicatesWindow is the duration within which the stream drops events with the message ID of a stored
+	// event, which the Event Publisher Proxy sets for its "nats" deduplication store
+	StreamDuplicatesWindow time.Duration `envconfig:"NATS_STREAM_DUPLICATES_WINDOW" default:"2m"`
+	AckWait                time.Duration `envconfig:"NATS_ACK_WAIT" default:"30s"`
+
+	// StatusUpdateInterval is the interval in which the state of the event delivery is written to the
+	// status of the subscriptions
+	StatusUpdateInterval time.Duration `envconfig:"NATS_STATUS_UPDATE_INTERVAL" default:"30s"`
 }
 
 func GetNatsConfig(maxReconnects int, reconnectWait time.Duration) NatsConfig {
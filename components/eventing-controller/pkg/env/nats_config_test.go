@@ -14,14 +14,25 @@ func Test_GetNatsConfig(t *testing.T) {
 	maxConnsPerHost := 20
 	maxIdleConnsPerHost := 30
 	idleConnTimeout := time.Second * 40
+	streamMaxAge := time.Hour * 2
+	streamDuplicatesWindow := time.Minute * 5
+	ackWait := time.Second * 50
+	statusUpdateInterval := time.Second * 60
 
 	envs := map[string]string{
-		"NATS_URL":                "NATS_URL",
-		"EVENT_TYPE_PREFIX":       "EVENT_TYPE_PREFIX",
-		"MAX_IDLE_CONNS":          fmt.Sprintf("%d", maxIdleConns),
-		"MAX_CONNS_PER_HOST":      fmt.Sprintf("%d", maxConnsPerHost),
-		"MAX_IDLE_CONNS_PER_HOST": fmt.Sprintf("%d", maxIdleConnsPerHost),
-		"IDLE_CONN_TIMEOUT":       fmt.Sprintf("%v", idleConnTimeout),
+		"NATS_URL":                      "NATS_URL",
+		"EVENT_TYPE_PREFIX":             "EVENT_TYPE_PREFIX",
+		"MAX_IDLE_CONNS":                fmt.Sprintf("%d", maxIdleConns),
+		"MAX_CONNS_PER_HOST":            fmt.Sprintf("%d", maxConnsPerHost),
+		"MAX_IDLE_CONNS_PER_HOST":       fmt.Sprintf("%d", maxIdleConnsPerHost),
+		"IDLE_CONN_TIMEOUT":             fmt.Sprintf("%v", idleConnTimeout),
+		"NATS_DELIVERY_MODE":            "AtLeastOnce",
+		"NATS_STREAM_NAME":              "NATS_STREAM_NAME",
+		"NATS_STREAM_STORAGE_TYPE":      "memory",
+		"NATS_STREAM_MAX_AGE":           fmt.Sprintf("%v", streamMaxAge),
+		"NATS_STREAM_DUPLICATES_WINDOW": fmt.Sprintf("%v", streamDuplicatesWindow),
+		"NATS_ACK_WAIT":                 fmt.Sprintf("%v", ackWait),
+		"NATS_STATUS_UPDATE_INTERVAL":   fmt.Sprintf("%v", statusUpdateInterval),
 	}
 
 	g := NewGomegaWithT(t)
@@ -50,4 +61,12 @@ func Test_GetNatsConfig(t *testing.T) {
 	g.Expect(config.MaxConnsPerHost).To(Equal(maxConnsPerHost))
 	g.Expect(config.MaxIdleConnsPerHost).To(Equal(maxIdleConnsPerHost))
 	g.Expect(config.IdleConnTimeout).To(Equal(idleConnTimeout))
+
+	g.Expect(config.DeliveryMode).To(Equal(envs["NATS_DELIVERY_MODE"]))
+	g.Expect(config.StreamName).To(Equal(envs["NATS_STREAM_NAME"]))
+	g.Expect(config.StreamStorageType).To(Equal(envs["NATS_STREAM_STORAGE_TYPE"]))
+	g.Expect(config.StreamMaxAge).To(Equal(streamMaxAge))
+	g.Expect(config.StreamDuplicatesWindow).To(Equal(streamDuplicatesWindow))
+	g.Expect(config.AckWait).To(Equal(ackWait))
+	g.Expect(config.StatusUpdateInterval).To(Equal(statusUpdateInterval))
 }
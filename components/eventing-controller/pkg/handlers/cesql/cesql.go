@@ -0,0 +1,486 @@
+// Package cesql implements a subset of the CloudEvents SQL expression language (CESQL) to filter events.
+//
+// Supported are the logical operators AND, OR and NOT, the comparison operators =, !=, <>, <, <=, > and >=,
+// the LIKE and IN operators, which can be negated with NOT, and the EXISTS operator. Operands are context
+// attributes or extensions of the event, string literals in single or double quotes, integers, TRUE and FALSE.
+// For example:
+//
+//	type LIKE 'sap.kyma.custom.%.order.created.v1' AND (EXISTS tenant AND tenant != 'test' OR priority > 2)
+package cesql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Expression is a parsed expression which can be evaluated against events
+type Expression interface {
+	// Matches returns true if the expression evaluates to true for the given event
+	Matches(event *cev2event.Event) bool
+}
+
+// node is a node of the syntax tree of an expression, it evaluates to a string, an int64, a bool or nil,
+// if an attribute does not exist
+type node interface {
+	evaluate(event *cev2event.Event) interface{}
+}
+
+type expression struct {
+	root node
+}
+
+func (e expression) Matches(event *cev2event.Event) bool {
+	result, ok := e.root.evaluate(event).(bool)
+	return ok && result
+}
+
+// Parse parses the given expression
+func Parse(value string) (Expression, error) {
+	tokens, err := tokenize(value)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", t.value, t.pos)
+	}
+	return expression{root: root}, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().isKeyword("NOT") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (node, error) {
+	if p.peek().isKeyword("EXISTS") {
+		p.next()
+		t := p.next()
+		if t.kind != tokenIdentifier {
+			return nil, fmt.Errorf("expected attribute name after EXISTS at position %d", t.pos)
+		}
+		return existsNode{name: strings.ToLower(t.value)}, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind == tokenOperator {
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return comparisonNode{operator: t.value, left: left, right: right}, nil
+	}
+
+	negate := false
+	if p.peek().isKeyword("NOT") {
+		p.next()
+		negate = true
+	}
+	switch t := p.peek(); {
+	case t.isKeyword("LIKE"):
+		p.next()
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, fmt.Errorf("expected pattern after LIKE at position %d", pattern.pos)
+		}
+		return likeNode{operand: left, pattern: compileLikePattern(pattern.value), negate: negate}, nil
+	case t.isKeyword("IN"):
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return inNode{operand: left, values: values, negate: negate}, nil
+	case negate:
+		return nil, fmt.Errorf("expected LIKE or IN after NOT at position %d", t.pos)
+	default:
+		// the operand is a predicate on its own, e.g. a boolean literal
+		return left, nil
+	}
+}
+
+func (p *parser) parseList() ([]node, error) {
+	if t := p.next(); t.kind != tokenLeftParen {
+		return nil, fmt.Errorf("expected '(' at position %d", t.pos)
+	}
+	var values []node
+	for {
+		value, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		switch t := p.next(); t.kind {
+		case tokenComma:
+			continue
+		case tokenRightParen:
+			return values, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or ')' at position %d", t.pos)
+		}
+	}
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return literalNode{value: t.value}, nil
+	case t.kind == tokenInteger:
+		value, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer '%s' at position %d", t.value, t.pos)
+		}
+		return literalNode{value: value}, nil
+	case t.isKeyword("TRUE"):
+		return literalNode{value: true}, nil
+	case t.isKeyword("FALSE"):
+		return literalNode{value: false}, nil
+	case t.kind == tokenIdentifier:
+		return attributeNode{name: strings.ToLower(t.value)}, nil
+	case t.kind == tokenLeftParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.pos)
+		}
+		return inner, nil
+	case t.kind == tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected '%s' at position %d", t.value, t.pos)
+	}
+}
+
+// compileLikePattern converts a LIKE pattern to a regular expression, % matches any number of characters
+// and _ matches a single character, unless they are escaped with a backslash
+func compileLikePattern(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) evaluate(*cev2event.Event) interface{} {
+	return n.value
+}
+
+type attributeNode struct {
+	name string
+}
+
+func (n attributeNode) evaluate(event *cev2event.Event) interface{} {
+	return getAttribute(event, n.name)
+}
+
+type existsNode struct {
+	name string
+}
+
+func (n existsNode) evaluate(event *cev2event.Event) interface{} {
+	return getAttribute(event, n.name) != nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n notNode) evaluate(event *cev2event.Event) interface{} {
+	value, ok := n.operand.evaluate(event).(bool)
+	if !ok {
+		return nil
+	}
+	return !value
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) evaluate(event *cev2event.Event) interface{} {
+	left, _ := n.left.evaluate(event).(bool)
+	right, _ := n.right.evaluate(event).(bool)
+	return left && right
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) evaluate(event *cev2event.Event) interface{} {
+	left, _ := n.left.evaluate(event).(bool)
+	right, _ := n.right.evaluate(event).(bool)
+	return left || right
+}
+
+type comparisonNode struct {
+	operator    string
+	left, right node
+}
+
+func (n comparisonNode) evaluate(event *cev2event.Event) interface{} {
+	order, ok := compare(n.left.evaluate(event), n.right.evaluate(event))
+	if !ok {
+		return nil
+	}
+	switch n.operator {
+	case "=":
+		return order == 0
+	case "!=", "<>":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	default:
+		return nil
+	}
+}
+
+type likeNode struct {
+	operand node
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+func (n likeNode) evaluate(event *cev2event.Event) interface{} {
+	value := n.operand.evaluate(event)
+	if value == nil {
+		return nil
+	}
+	return n.pattern.MatchString(toString(value)) != n.negate
+}
+
+type inNode struct {
+	operand node
+	values  []node
+	negate  bool
+}
+
+func (n inNode) evaluate(event *cev2event.Event) interface{} {
+	value := n.operand.evaluate(event)
+	if value == nil {
+		return nil
+	}
+	for _, v := range n.values {
+		if order, ok := compare(value, v.evaluate(event)); ok && order == 0 {
+			return !n.negate
+		}
+	}
+	return n.negate
+}
+
+// compare compares two values, the right value is converted to the type of the left value if necessary.
+// It returns false if the values cannot be compared.
+func compare(left, right interface{}) (int, bool) {
+	if left == nil || right == nil {
+		return 0, false
+	}
+	switch l := left.(type) {
+	case int64:
+		r, ok := toInteger(right)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case bool:
+		r, ok := toBool(right)
+		if !ok || l != r {
+			return 1, ok
+		}
+		return 0, true
+	default:
+		// an integer or a boolean on the right side determines the type of the comparison
+		if _, ok := right.(string); !ok {
+			order, ok := compare(right, left)
+			return -order, ok
+		}
+		return strings.Compare(toString(left), toString(right)), true
+	}
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func toInteger(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// getAttribute returns the value of a context attribute or an extension of the event, or nil if it does not exist
+func getAttribute(event *cev2event.Event, name string) interface{} {
+	switch name {
+	case "id":
+		return event.ID()
+	case "source":
+		return event.Source()
+	case "specversion":
+		return event.SpecVersion()
+	case "type":
+		return event.Type()
+	case "datacontenttype":
+		return optional(event.DataContentType())
+	case "dataschema":
+		return optional(event.DataSchema())
+	case "subject":
+		return optional(event.Subject())
+	case "time":
+		if event.Time().IsZero() {
+			return nil
+		}
+		return types.FormatTime(event.Time())
+	}
+	value, ok := event.Extensions()[name]
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int32:
+		return int64(v)
+	default:
+		s, err := types.Format(v)
+		if err != nil {
+			return nil
+		}
+		return s
+	}
+}
+
+func optional(value string) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,78 @@
+package cesql
+
+import (
+	"testing"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	. "github.com/onsi/gomega"
+)
+
+func TestMatches(t *testing.T) {
+	event := cev2event.New(cev2event.CloudEventsVersionV1)
+	event.SetID("id")
+	event.SetSource("/default/sap.kyma/tunas")
+	event.SetType("sap.kyma.custom.commerce.order.created.v1")
+	event.SetExtension("tenant", "acme")
+	event.SetExtension("priority", 3)
+	event.SetExtension("urgent", true)
+
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: "type = 'sap.kyma.custom.commerce.order.created.v1'", expected: true},
+		{expression: "TYPE = \"sap.kyma.custom.commerce.order.created.v1\"", expected: true},
+		{expression: "type != 'sap.kyma.custom.commerce.order.created.v1'", expected: false},
+		{expression: "type <> 'foo'", expected: true},
+		{expression: "type LIKE 'sap.kyma.custom.%.order.created.v_'", expected: true},
+		{expression: "type NOT LIKE 'sap.kyma.custom.%'", expected: false},
+		{expression: "source LIKE '/default/sap\\.kyma/%'", expected: true},
+		{expression: "tenant IN ('foo', 'acme')", expected: true},
+		{expression: "tenant NOT IN ('foo', 'acme')", expected: false},
+		{expression: "priority > 2 AND priority <= 3", expected: true},
+		{expression: "priority = '3'", expected: true},
+		{expression: "priority < -1", expected: false},
+		{expression: "urgent", expected: true},
+		{expression: "urgent = TRUE AND NOT (tenant = 'test')", expected: true},
+		{expression: "EXISTS tenant AND NOT EXISTS region", expected: true},
+		{expression: "region = 'eu' OR tenant = 'acme'", expected: true},
+		{expression: "region = 'eu'", expected: false},
+		{expression: "NOT region = 'eu'", expected: false},
+		{expression: "subject = 'foo'", expected: false},
+		{expression: "'it''s' = 'it''s'", expected: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expression, func(t *testing.T) {
+			g := NewWithT(t)
+			expression, err := Parse(tc.expression)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(expression.Matches(&event)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestParseInvalidExpression(t *testing.T) {
+	testCases := []string{
+		"",
+		"type =",
+		"type = 'foo",
+		"type ! 'foo'",
+		"type = 'foo' AND",
+		"(type = 'foo'",
+		"type = 'foo')",
+		"type LIKE foo",
+		"type NOT 'foo'",
+		"tenant IN ('foo' 'bar')",
+		"EXISTS 'tenant'",
+		"type = 'foo' # comment",
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc, func(t *testing.T) {
+			g := NewWithT(t)
+			_, err := Parse(tc)
+			g.Expect(err).Should(HaveOccurred())
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package cesql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenInteger
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+// token is a lexical token of an expression, keywords are identifiers
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// isKeyword returns true if the token is the given keyword, ignoring the case
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenIdentifier && strings.EqualFold(t.value, keyword)
+}
+
+// tokenize splits the expression into tokens, the last token is always tokenEOF
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, value: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, value: ",", pos: i})
+			i++
+		case r == '\'' || r == '"':
+			value, next, err := readString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: i})
+			i = next
+		case isDigit(r) || (r == '-' && i+1 < len(runes) && isDigit(runes[i+1])):
+			start := i
+			for i++; i < len(runes) && isDigit(runes[i]); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenInteger, value: string(runes[start:i]), pos: start})
+		case isLetter(r):
+			start := i
+			for i++; i < len(runes) && (isLetter(runes[i]) || isDigit(runes[i])); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: string(runes[start:i]), pos: start})
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected character '!' at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op, pos: start})
+			i += len(op)
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", r, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// readString reads a string literal starting at the given quote, a quote is escaped by doubling it
+func readString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var value strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] != quote {
+			value.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == quote {
+			value.WriteRune(quote)
+			i++
+			continue
+		}
+		return value.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated string literal at position %d", start)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
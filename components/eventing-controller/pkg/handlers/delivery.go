@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	cev2http "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+)
+
+const (
+	// DeadLetterEventType is the type of the events which are sent to the dead-letter sink of a subscription
+	DeadLetterEventType = "kyma.eventing.deadletter.v1"
+
+	// the extensions of a dead-letter event
+	deadLetterExtensionOriginalSink = "originalsink"
+	deadLetterExtensionStatusCode   = "deliverystatuscode"
+	deadLetterExtensionAttempts     = "deliveryattempts"
+)
+
+// retryableStatusCodes are the status codes of a sink which make it worth to retry the delivery
+var retryableStatusCodes = map[int]string{
+	404: "Not Found",
+	413: "Payload Too Large",
+	425: "Too Early",
+	429: "Too Many Requests",
+	502: "Bad Gateway",
+	503: "Service Unavailable",
+	504: "Gateway Timeout",
+}
+
+// deliveryPolicy is the delivery policy of a subscription with all the defaults applied
+type deliveryPolicy struct {
+	sink           string
+	maxAttempts    int
+	strategy       eventingv1alpha1.BackoffStrategy
+	delay          time.Duration
+	maxBackoff     time.Duration
+	deadLetterSink string
+}
+
+// deliveryResult is the result of the attempts to deliver an event to a sink
+type deliveryResult struct {
+	// statusCode is the last HTTP status code of the sink, zero if the sink could not be reached
+	statusCode int
+	attempts   int
+	err        error
+}
+
+// unlimitedAttempts is the maximum number of attempts of a delivery policy which never gives up
+const unlimitedAttempts = 0
+
+// newDeliveryPolicy applies the defaults to the delivery policy of the given subscription. The default is to
+// retry the delivery with an exponential backoff, starting with two minutes, until the attempts are used up.
+func newDeliveryPolicy(sub *eventingv1alpha1.Subscription, defaultMaxAttempts int) deliveryPolicy {
+	policy := deliveryPolicy{
+		sink:        sub.Spec.Sink,
+		maxAttempts: defaultMaxAttempts,
+		strategy:    eventingv1alpha1.BackoffStrategyExponential,
+		delay:       2 * period,
+	}
+	spec := sub.Spec.DeliveryPolicy
+	if spec == nil {
+		return policy
+	}
+	if spec.MaxAttempts != nil {
+		policy.maxAttempts = *spec.MaxAttempts
+	}
+	if len(spec.BackoffStrategy) > 0 {
+		policy.strategy = spec.BackoffStrategy
+	}
+	if spec.BackoffDelay != nil {
+		policy.delay = spec.BackoffDelay.Duration
+	}
+	if spec.MaxBackoff != nil {
+		policy.maxBackoff = spec.MaxBackoff.Duration
+	}
+	policy.deadLetterSink = spec.DeadLetterSink
+	return policy
+}
+
+// backoff returns the delay before the given retry, which starts at one.
+func (p deliveryPolicy) backoff(retry int) time.Duration {
+	var backoff time.Duration
+	switch p.strategy {
+	case eventingv1alpha1.BackoffStrategyConstant:
+		backoff = p.delay
+	case eventingv1alpha1.BackoffStrategyLinear:
+		backoff = p.delay * time.Duration(retry)
+	default:
+		backoff = p.delay * time.Duration(math.Exp2(float64(retry-1)))
+	}
+	if p.maxBackoff > 0 && (backoff > p.maxBackoff || backoff < 0) {
+		return p.maxBackoff
+	}
+	return backoff
+}
+
+// hasAttemptsLeft returns true if the delivery can be retried after the given number of attempts.
+func (p deliveryPolicy) hasAttemptsLeft(attempts int) bool {
+	return p.maxAttempts == unlimitedAttempts || attempts < p.maxAttempts
+}
+
+// deliver sends the event to the sink of the policy and retries the delivery with a backoff as long as the sink
+// responds with a retryable status code and there are attempts left. The backoff is waited for with the given function.
+// The attempts are counted from first, which is greater than one if earlier attempts were made already.
+func deliver(ctx context.Context, client cev2.Client, event cev2event.Event, policy deliveryPolicy, first int,
+	wait func(context.Context, time.Duration) bool) deliveryResult {
+	for attempts := first; ; attempts++ {
+		result := send(ctx, client, event, policy.sink)
+		result.attempts = attempts
+		if result.err == nil || !isRetryable(result.statusCode) || !policy.hasAttemptsLeft(attempts) {
+			return result
+		}
+		if !wait(ctx, policy.backoff(attempts)) {
+			return result
+		}
+	}
+}
+
+// send sends the event to the sink once.
+func send(ctx context.Context, client cev2.Client, event cev2event.Event, sink string) deliveryResult {
+	result := client.Send(cev2.ContextWithTarget(ctx, sink), event)
+	if cev2.IsACK(result) {
+		return deliveryResult{}
+	}
+	delivery := deliveryResult{err: result}
+	var httpResult *cev2http.Result
+	if errors.As(result, &httpResult) {
+		delivery.statusCode = httpResult.StatusCode
+	}
+	return delivery
+}
+
+func isRetryable(statusCode int) bool {
+	// the sink could not be reached
+	if statusCode == 0 {
+		return true
+	}
+	_, ok := retryableStatusCodes[statusCode]
+	return ok
+}
+
+// newDeadLetterEvent wraps an event which could not be delivered to the sink of the given subscription.
+func newDeadLetterEvent(sub *eventingv1alpha1.Subscription, event cev2event.Event, delivery deliveryResult) (*cev2event.Event, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	deadLetter := cev2event.New(cev2event.CloudEventsVersionV1)
+	deadLetter.SetID(event.ID())
+	deadLetter.SetType(DeadLetterEventType)
+	deadLetter.SetSource(fmt.Sprintf("/namespaces/%s/subscriptions/%s", url.PathEscape(sub.Namespace), url.PathEscape(sub.Name)))
+	deadLetter.SetExtension(deadLetterExtensionOriginalSink, sub.Spec.Sink)
+	deadLetter.SetExtension(deadLetterExtensionStatusCode, delivery.statusCode)
+	deadLetter.SetExtension(deadLetterExtensionAttempts, delivery.attempts)
+	if err := deadLetter.SetData(cev2event.ApplicationJSON, data); err != nil {
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+// sendDeadLetter sends an event which could not be delivered to the dead-letter sink of the subscription.
+// The event is dropped if the subscription has no dead-letter sink.
+func sendDeadLetter(ctx context.Context, client cev2.Client, log logr.Logger, sub *eventingv1alpha1.Subscription,
+	ce cev2event.Event, policy deliveryPolicy, delivery deliveryResult) error {
+	if len(policy.deadLetterSink) == 0 {
+		log.Info("dropped event which could not be dispatched", "id", ce.ID())
+		return nil
+	}
+	deadLetter, err := newDeadLetterEvent(sub, ce, delivery)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the dead-letter event")
+	}
+	if result := send(ctx, client, *deadLetter, policy.deadLetterSink); result.err != nil {
+		return result.err
+	}
+	log.Info(fmt.Sprintf("Successfully dispatched event id: %s to dead-letter sink: %s", ce.ID(), policy.deadLetterSink))
+	return nil
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cev2binding "github.com/cloudevents/sdk-go/v2/binding"
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	cev2http "github.com/cloudevents/sdk-go/v2/protocol/http"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	eventingtesting "github.com/kyma-project/kyma/components/eventing-controller/testing"
+)
+
+func TestNewDeliveryPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	sub := eventingtesting.NewSubscription("sub", "foo")
+	sub.Spec.Sink = "http://sink"
+
+	// defaults
+	policy := newDeliveryPolicy(sub, maxTries+1)
+	g.Expect(policy.sink).To(Equal("http://sink"))
+	g.Expect(policy.maxAttempts).To(Equal(maxTries + 1))
+	g.Expect(policy.strategy).To(Equal(eventingv1alpha1.BackoffStrategyExponential))
+	g.Expect(policy.delay).To(Equal(2 * period))
+	g.Expect(policy.maxBackoff).To(BeZero())
+	g.Expect(policy.deadLetterSink).To(BeEmpty())
+
+	// overridden by the subscription
+	maxAttempts := 3
+	sub.Spec.DeliveryPolicy = &eventingv1alpha1.DeliveryPolicy{
+		MaxAttempts:     &maxAttempts,
+		BackoffStrategy: eventingv1alpha1.BackoffStrategyLinear,
+		BackoffDelay:    &metav1.Duration{Duration: time.Second},
+		MaxBackoff:      &metav1.Duration{Duration: time.Minute},
+		DeadLetterSink:  "http://dead-letter-sink",
+	}
+	policy = newDeliveryPolicy(sub, unlimitedAttempts)
+	g.Expect(policy.maxAttempts).To(Equal(maxAttempts))
+	g.Expect(policy.strategy).To(Equal(eventingv1alpha1.BackoffStrategyLinear))
+	g.Expect(policy.delay).To(Equal(time.Second))
+	g.Expect(policy.maxBackoff).To(Equal(time.Minute))
+	g.Expect(policy.deadLetterSink).To(Equal("http://dead-letter-sink"))
+}
+
+func TestDeliveryPolicyBackoff(t *testing.T) {
+	testCases := []struct {
+		name            string
+		policy          deliveryPolicy
+		retry           int
+		expectedBackoff time.Duration
+	}{
+		{
+			name:            "constant backoff",
+			policy:          deliveryPolicy{strategy: eventingv1alpha1.BackoffStrategyConstant, delay: time.Second},
+			retry:           3,
+			expectedBackoff: time.Second,
+		},
+		{
+			name:            "linear backoff",
+			policy:          deliveryPolicy{strategy: eventingv1alpha1.BackoffStrategyLinear, delay: time.Second},
+			retry:           3,
+			expectedBackoff: 3 * time.Second,
+		},
+		{
+			name:            "exponential backoff",
+			policy:          deliveryPolicy{strategy: eventingv1alpha1.BackoffStrategyExponential, delay: time.Second},
+			retry:           3,
+			expectedBackoff: 4 * time.Second,
+		},
+		{
+			name: "exponential backoff limited by the max backoff",
+			policy: deliveryPolicy{
+				strategy:   eventingv1alpha1.BackoffStrategyExponential,
+				delay:      time.Second,
+				maxBackoff: 5 * time.Second,
+			},
+			retry:           4,
+			expectedBackoff: 5 * time.Second,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tc.policy.backoff(tc.retry)).To(Equal(tc.expectedBackoff))
+		})
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	testCases := []struct {
+		name               string
+		statusCodes        []int
+		maxAttempts        int
+		expectedAttempts   int
+		expectedStatusCode int
+		expectedError      bool
+	}{
+		{
+			name:             "delivered at the first attempt",
+			statusCodes:      []int{http.StatusNoContent},
+			maxAttempts:      3,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "delivered after retries",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			maxAttempts:      3,
+			expectedAttempts: 3,
+		},
+		{
+			name:               "attempts used up",
+			statusCodes:        []int{http.StatusServiceUnavailable},
+			maxAttempts:        3,
+			expectedAttempts:   3,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedError:      true,
+		},
+		{
+			name:               "status code which is not retryable",
+			statusCodes:        []int{http.StatusBadRequest},
+			maxAttempts:        3,
+			expectedAttempts:   1,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			requests := 0
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// the last status code is repeated once the given status codes are used up
+				statusCode := tc.statusCodes[len(tc.statusCodes)-1]
+				if requests < len(tc.statusCodes) {
+					statusCode = tc.statusCodes[requests]
+				}
+				requests++
+				w.WriteHeader(statusCode)
+			}))
+			defer sink.Close()
+
+			client, err := newCloudeventClient(env.NatsConfig{})
+			g.Expect(err).ShouldNot(HaveOccurred())
+			policy := deliveryPolicy{
+				sink:        sink.URL,
+				maxAttempts: tc.maxAttempts,
+				strategy:    eventingv1alpha1.BackoffStrategyConstant,
+				delay:       time.Millisecond,
+			}
+
+			result := deliver(context.Background(), client, newTestEvent(t), policy, 1, sleepContext)
+			g.Expect(result.attempts).To(Equal(tc.expectedAttempts))
+			g.Expect(requests).To(Equal(tc.expectedAttempts))
+			g.Expect(result.statusCode).To(Equal(tc.expectedStatusCode))
+			g.Expect(result.err != nil).To(Equal(tc.expectedError))
+		})
+	}
+}
+
+func TestSendToDeadLetterSink(t *testing.T) {
+	g := NewWithT(t)
+
+	var lock sync.Mutex
+	var deadLetters []cev2event.Event
+	deadLetterSink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := cev2binding.ToEvent(r.Context(), cev2http.NewMessageFromHttpRequest(r))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		deadLetters = append(deadLetters, *event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer deadLetterSink.Close()
+
+	client, err := newCloudeventClient(env.NatsConfig{})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	natsClient := Nats{client: client, log: ctrl.Log.WithName("reconciler").WithName("Subscription")}
+
+	sub := eventingtesting.NewSubscription("sub", "foo")
+	sub.Spec.Sink = "http://sink"
+	sub.Spec.DeliveryPolicy = &eventingv1alpha1.DeliveryPolicy{DeadLetterSink: deadLetterSink.URL}
+	event := newTestEvent(t)
+	delivery := deliveryResult{statusCode: http.StatusServiceUnavailable, attempts: 3}
+
+	err = natsClient.sendToDeadLetterSink(context.Background(), sub, event, newDeliveryPolicy(sub, 3), delivery)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	lock.Lock()
+	defer lock.Unlock()
+	g.Expect(deadLetters).To(HaveLen(1))
+	deadLetter := deadLetters[0]
+	g.Expect(deadLetter.Type()).To(Equal(DeadLetterEventType))
+	g.Expect(deadLetter.ID()).To(Equal(event.ID()))
+	g.Expect(deadLetter.Source()).To(Equal("/namespaces/foo/subscriptions/sub"))
+	g.Expect(deadLetter.Extensions()).To(HaveKeyWithValue(deadLetterExtensionOriginalSink, "http://sink"))
+	g.Expect(deadLetter.Extensions()).To(HaveKeyWithValue(deadLetterExtensionStatusCode, "503"))
+	g.Expect(deadLetter.Extensions()).To(HaveKeyWithValue(deadLetterExtensionAttempts, "3"))
+
+	// the original event is the data of the dead-letter event
+	originalEvent := cev2event.New()
+	g.Expect(json.Unmarshal(deadLetter.Data(), &originalEvent)).Should(Succeed())
+	g.Expect(originalEvent.ID()).To(Equal(event.ID()))
+	g.Expect(originalEvent.Type()).To(Equal(event.Type()))
+	g.Expect(originalEvent.Data()).To(Equal(event.Data()))
+}
+
+func newTestEvent(t *testing.T) cev2event.Event {
+	eventTime := time.Now().Format(time.RFC3339)
+	return eventingtesting.NewCloudEvent("\"foo-data\"", "id", eventingtesting.EventSource, eventTime, eventingtesting.OrderCreatedEventType, t)
+}
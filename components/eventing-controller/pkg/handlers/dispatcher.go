@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"golang.org/x/time/rate"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+)
+
+// defaultMaxInFlight delivers the events of a subscription one after the other
+const defaultMaxInFlight = 1
+
+// dispatcher limits the number of concurrent deliveries and the delivery rate of a Kyma subscription.
+// It is shared by the Nats subscriptions of all subjects of the Kyma subscription, hence a delivery does not hold
+// its slot while it waits for the backoff of a retry. As many deliveries as there are slots may back off without
+// a slot, further ones keep their slots, so that at most twice the slots are in progress.
+type dispatcher struct {
+	slots        chan struct{}
+	backoffSlots chan struct{}
+	limiter      *rate.Limiter
+
+	// waiting is the number of events which were received, but wait for a free slot or the rate limit
+	waiting  int64
+	inFlight int64
+	// backingOff is the number of events which wait for the retry of a failed delivery without a slot
+	backingOff int64
+
+	lock          sync.Mutex
+	subscriptions []*nats.Subscription
+}
+
+func newDispatcher(sub *eventingv1alpha1.Subscription) *dispatcher {
+	maxInFlight := defaultMaxInFlight
+	if sub.Spec.MaxInFlight != nil && *sub.Spec.MaxInFlight > 0 {
+		maxInFlight = *sub.Spec.MaxInFlight
+	}
+	d := &dispatcher{
+		slots:        make(chan struct{}, maxInFlight),
+		backoffSlots: make(chan struct{}, maxInFlight),
+	}
+	if sub.Spec.MaxDeliveryRate != nil && *sub.Spec.MaxDeliveryRate > 0 {
+		d.limiter = rate.NewLimiter(rate.Limit(*sub.Spec.MaxDeliveryRate), 1)
+	}
+	return d
+}
+
+// dispatch blocks until the delivery can start without exceeding the limits, then it runs the delivery
+// asynchronously. Blocking the callback of a Nats subscription keeps the events in its pending queue.
+func (d *dispatcher) dispatch(delivery func()) {
+	d.acquire()
+	go func() {
+		defer d.release()
+		delivery()
+	}()
+}
+
+// run blocks until the delivery can start without exceeding the limits, then it runs the delivery in the calling
+// goroutine. It is used by consumers which must finish a delivery before they proceed with the next event.
+func (d *dispatcher) run(delivery func()) {
+	d.acquire()
+	defer d.release()
+	delivery()
+}
+
+func (d *dispatcher) acquire() {
+	atomic.AddInt64(&d.waiting, 1)
+	d.slots <- struct{}{}
+	if d.limiter != nil {
+		// waiting without a deadline never fails, because the burst is at least one
+		_ = d.limiter.Wait(context.Background())
+	}
+	atomic.AddInt64(&d.waiting, -1)
+	atomic.AddInt64(&d.inFlight, 1)
+}
+
+func (d *dispatcher) release() {
+	atomic.AddInt64(&d.inFlight, -1)
+	<-d.slots
+}
+
+// wait releases the slot of the running delivery for the backoff of a retry and acquires a slot again afterwards,
+// so that the retries of one event do not block the deliveries of the other events. If too many deliveries back off
+// already, the delivery keeps its slot, which stops new deliveries until the sink recovers. It returns false if the
+// context is done before the backoff is over.
+func (d *dispatcher) wait(ctx context.Context, backoff time.Duration) bool {
+	select {
+	case d.backoffSlots <- struct{}{}:
+	default:
+		return sleepContext(ctx, backoff)
+	}
+
+	d.release()
+	atomic.AddInt64(&d.backingOff, 1)
+	waited := sleepContext(ctx, backoff)
+	atomic.AddInt64(&d.backingOff, -1)
+	<-d.backoffSlots
+	d.acquire()
+	return waited
+}
+
+// maxPending returns the maximum number of events in progress, which are either in flight or back off for a retry
+func (d *dispatcher) maxPending() int {
+	return cap(d.slots) + cap(d.backoffSlots)
+}
+
+// addSubscription adds a Nats subscription whose pending events are part of the backlog
+func (d *dispatcher) addSubscription(sub *nats.Subscription) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.subscriptions = append(d.subscriptions, sub)
+}
+
+// status returns the current state of the delivery
+func (d *dispatcher) status() eventingv1alpha1.DispatcherStatus {
+	waiting := atomic.LoadInt64(&d.waiting)
+	backlog := waiting + atomic.LoadInt64(&d.backingOff)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, sub := range d.subscriptions {
+		if !sub.IsValid() {
+			continue
+		}
+		if pending, _, err := sub.Pending(); err == nil {
+			backlog += int64(pending)
+		}
+	}
+
+	return eventingv1alpha1.DispatcherStatus{
+		Backlog:   backlog,
+		InFlight:  atomic.LoadInt64(&d.inFlight),
+		Throttled: waiting > 0,
+	}
+}
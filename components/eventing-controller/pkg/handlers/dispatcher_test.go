@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+)
+
+func TestDispatcherMaxInFlight(t *testing.T) {
+	g := NewWithT(t)
+
+	maxInFlight := 2
+	d := newDispatcher(&eventingv1alpha1.Subscription{
+		Spec: eventingv1alpha1.SubscriptionSpec{MaxInFlight: &maxInFlight},
+	})
+
+	release := make(chan struct{})
+	var running, maxRunning int64
+	var wg sync.WaitGroup
+	delivery := func() {
+		defer wg.Done()
+		current := atomic.AddInt64(&running, 1)
+		for {
+			observed := atomic.LoadInt64(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt64(&maxRunning, observed, current) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt64(&running, -1)
+	}
+
+	events := 4
+	wg.Add(events)
+	go func() {
+		for i := 0; i < events; i++ {
+			d.dispatch(delivery)
+		}
+	}()
+
+	// the third event waits for a free slot
+	g.Eventually(d.status).Should(Equal(eventingv1alpha1.DispatcherStatus{Backlog: 1, InFlight: 2, Throttled: true}))
+	g.Consistently(func() int64 { return atomic.LoadInt64(&running) }, 100*time.Millisecond).Should(BeEquivalentTo(2))
+
+	close(release)
+	wg.Wait()
+	g.Expect(atomic.LoadInt64(&maxRunning)).To(BeEquivalentTo(maxInFlight))
+	g.Eventually(d.status).Should(Equal(eventingv1alpha1.DispatcherStatus{}))
+}
+
+func TestDispatcherWaitReleasesSlot(t *testing.T) {
+	g := NewWithT(t)
+
+	d := newDispatcher(&eventingv1alpha1.Subscription{})
+
+	// the first event backs off for a retry, which must not block the second event
+	retried := make(chan struct{})
+	d.dispatch(func() {
+		d.wait(context.Background(), 200*time.Millisecond)
+		close(retried)
+	})
+	delivered := make(chan struct{})
+	d.dispatch(func() { close(delivered) })
+
+	g.Eventually(delivered, 100*time.Millisecond).Should(BeClosed())
+	g.Eventually(retried, time.Second).Should(BeClosed())
+	g.Eventually(d.status).Should(Equal(eventingv1alpha1.DispatcherStatus{}))
+}
+
+func TestDispatcherWaitLimitsBackingOff(t *testing.T) {
+	g := NewWithT(t)
+
+	d := newDispatcher(&eventingv1alpha1.Subscription{})
+	g.Expect(d.maxPending()).To(Equal(2))
+
+	// the first event backs off without its slot
+	ctx, cancel := context.WithCancel(context.Background())
+	firstRetried := make(chan struct{})
+	d.dispatch(func() {
+		d.wait(ctx, time.Hour)
+		close(firstRetried)
+	})
+	g.Eventually(func() int64 { return atomic.LoadInt64(&d.backingOff) }).Should(BeEquivalentTo(1))
+
+	// the second event keeps its slot while it backs off, so the third one waits for it
+	secondRetried := make(chan struct{})
+	d.dispatch(func() {
+		d.wait(context.Background(), 200*time.Millisecond)
+		close(secondRetried)
+	})
+	delivered := make(chan struct{})
+	go d.dispatch(func() { close(delivered) })
+
+	g.Consistently(delivered, 100*time.Millisecond).ShouldNot(BeClosed())
+	g.Eventually(secondRetried, time.Second).Should(BeClosed())
+	g.Eventually(delivered, time.Second).Should(BeClosed())
+	g.Expect(firstRetried).NotTo(BeClosed())
+
+	cancel()
+	g.Eventually(firstRetried, time.Second).Should(BeClosed())
+	g.Eventually(d.status).Should(Equal(eventingv1alpha1.DispatcherStatus{}))
+}
+
+func TestDispatcherMaxDeliveryRate(t *testing.T) {
+	g := NewWithT(t)
+
+	maxInFlight, maxDeliveryRate := 10, 20
+	d := newDispatcher(&eventingv1alpha1.Subscription{
+		Spec: eventingv1alpha1.SubscriptionSpec{MaxInFlight: &maxInFlight, MaxDeliveryRate: &maxDeliveryRate},
+	})
+
+	// the first event is delivered immediately, the others one every 50ms
+	events := 5
+	var wg sync.WaitGroup
+	wg.Add(events)
+	start := time.Now()
+	for i := 0; i < events; i++ {
+		d.dispatch(wg.Done)
+	}
+	wg.Wait()
+
+	g.Expect(time.Since(start)).To(BeNumerically(">=", 190*time.Millisecond))
+}
+
+func TestNewDispatcherDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	d := newDispatcher(&eventingv1alpha1.Subscription{})
+	g.Expect(cap(d.slots)).To(Equal(defaultMaxInFlight))
+	g.Expect(d.limiter).To(BeNil())
+}
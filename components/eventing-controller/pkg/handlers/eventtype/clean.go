@@ -1,7 +1,9 @@
 package eventtype
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/kyma-project/kyma/components/eventing-controller/pkg/application"
@@ -64,3 +66,31 @@ func (c cleaner) Clean(eventType string) (string, error) {
 func cleanEventType(eventType string) string {
 	return invalidEventTypeSegment.ReplaceAllString(eventType, "")
 }
+
+// CleanPrefix cleans an event-type prefix the same way as the event-types starting with it and returns the cleaned
+// prefix along with the complete segments of the cleaned prefix. The application name is only cleaned through the
+// cleaner if it is a complete segment of the prefix.
+func CleanPrefix(cleaner Cleaner, eventTypePrefix, prefix string) (string, string, error) {
+	// all event-types start with the event-type prefix, hence shorter prefixes match all events
+	if strings.HasPrefix(eventTypePrefix, prefix) {
+		return eventTypePrefix, eventTypePrefix, nil
+	}
+	if !strings.HasPrefix(prefix, eventTypePrefix+".") {
+		return "", "", fmt.Errorf("event-type prefix [%s] does not start with the prefix of the events [%s]", prefix, eventTypePrefix)
+	}
+
+	segments := strings.SplitN(strings.TrimPrefix(prefix, eventTypePrefix+"."), ".", 2)
+	if len(segments) == 1 {
+		return cleanEventType(prefix), eventTypePrefix, nil
+	}
+
+	// the cleaner parses complete event-types only, the remaining segments do not change the application name
+	eventType, err := cleaner.Clean(build(eventTypePrefix, segments[0], "placeholder.placeholder", "v1"))
+	if err != nil {
+		return "", "", err
+	}
+	cleanApplicationName := strings.SplitN(strings.TrimPrefix(eventType, eventTypePrefix+"."), ".", 2)[0]
+	applicationPrefix := fmt.Sprintf("%s.%s", eventTypePrefix, cleanApplicationName)
+
+	return fmt.Sprintf("%s.%s", applicationPrefix, cleanEventType(segments[1])), applicationPrefix, nil
+}
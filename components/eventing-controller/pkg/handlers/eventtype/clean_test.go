@@ -171,3 +171,61 @@ func TestCleaner(t *testing.T) {
 		})
 	}
 }
+
+func TestCleanPrefix(t *testing.T) {
+	app := applicationtest.NewApplication("te--s__t!!a@@p##p%%", nil)
+	appLister := fake.NewApplicationListerOrDie(context.Background(), app)
+	cleaner := NewCleaner("sap.kyma", appLister, ctrl.Log.WithName("cleaner"))
+
+	testCases := []struct {
+		name                 string
+		givenPrefix          string
+		wantPrefix           string
+		wantCompleteSegments string
+		wantError            bool
+	}{
+		{
+			name:                 "prefix of the event-type prefix matches all events",
+			givenPrefix:          "sap.ky",
+			wantPrefix:           "sap.kyma",
+			wantCompleteSegments: "sap.kyma",
+		},
+		{
+			name:                 "incomplete application name is cleaned from none-alphanumeric characters",
+			givenPrefix:          "sap.kyma.te--s",
+			wantPrefix:           "sap.kyma.tes",
+			wantCompleteSegments: "sap.kyma",
+		},
+		{
+			name:                 "complete application name is cleaned by the cleaner",
+			givenPrefix:          "sap.kyma.te--s__t!!a@@p##p%%.order-",
+			wantPrefix:           "sap.kyma.testapp.order",
+			wantCompleteSegments: "sap.kyma.testapp",
+		},
+		{
+			name:        "fail if the prefix does not start with the event-type prefix",
+			givenPrefix: "other.testapp",
+			wantError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotPrefix, gotCompleteSegments, err := CleanPrefix(cleaner, "sap.kyma", tc.givenPrefix)
+
+			if tc.wantError {
+				if err == nil {
+					t.Fatalf("%s: should have failed with an error", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: should have succeeded without an error: %v", tc.name, err)
+			}
+			if gotPrefix != tc.wantPrefix || gotCompleteSegments != tc.wantCompleteSegments {
+				t.Fatalf("%s: want prefix [%s] with complete segments [%s] but got [%s] with [%s]",
+					tc.name, tc.wantPrefix, tc.wantCompleteSegments, gotPrefix, gotCompleteSegments)
+			}
+		})
+	}
+}
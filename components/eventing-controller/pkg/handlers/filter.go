@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/cesql"
+)
+
+// eventFilter decides which of the events received on a NATS subject are dispatched to the sink. An event is
+// dispatched if it matches the expression of the subscription and at least one of the filters of the subject.
+type eventFilter struct {
+	filters    []*eventingv1alpha1.BebFilter
+	expression cesql.Expression
+	// checkType checks the exact type filters as well, it is set by backends which do not route the events by type
+	checkType bool
+}
+
+func (f eventFilter) matches(event *cev2event.Event) bool {
+	if f.expression != nil && !f.expression.Matches(event) {
+		return false
+	}
+	for _, filter := range f.filters {
+		if matchesBebFilter(filter, event, f.checkType) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesBebFilter returns true if the event matches the source, the type and the extension filters. An exact
+// type filter is only checked if requested, because for NATS it is the subject on which the event was received.
+func matchesBebFilter(filter *eventingv1alpha1.BebFilter, event *cev2event.Event, checkType bool) bool {
+	// a missing or an empty source matches all sources, as NATS subscriptions did not filter by source before
+	if filter.EventSource != nil && len(strings.TrimSpace(filter.EventSource.Value)) > 0 &&
+		!matchesFilter(filter.EventSource, event.Source()) {
+		return false
+	}
+	if (checkType || filter.EventType.Type == eventingv1alpha1.FilterTypePrefix) && !matchesFilter(filter.EventType, event.Type()) {
+		return false
+	}
+	for _, extension := range filter.Extensions {
+		value, ok := event.Extensions()[strings.ToLower(extension.Property)]
+		if !ok {
+			return false
+		}
+		formatted, err := types.Format(value)
+		if err != nil || !matchesFilter(extension, formatted) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesFilter(filter *eventingv1alpha1.Filter, value string) bool {
+	if filter.Type == eventingv1alpha1.FilterTypePrefix {
+		return strings.HasPrefix(value, filter.Value)
+	}
+	return value == filter.Value
+}
+
+// validateFilter returns an error if the NATS or the Kafka backend does not support the given filter
+func validateFilter(filter *eventingv1alpha1.BebFilter) error {
+	if filter.EventType == nil {
+		return fmt.Errorf("event type filter is missing")
+	}
+	filters := append([]*eventingv1alpha1.Filter{filter.EventSource, filter.EventType}, filter.Extensions...)
+	for _, f := range filters {
+		if f == nil {
+			continue
+		}
+		if f.Type != "" && f.Type != eventingv1alpha1.FilterTypeExact && f.Type != eventingv1alpha1.FilterTypePrefix {
+			return fmt.Errorf("invalid filter type %s of property %s", f.Type, f.Property)
+		}
+	}
+	for _, extension := range filter.Extensions {
+		if len(extension.Property) == 0 {
+			return fmt.Errorf("extension filter without property")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	cev2event "github.com/cloudevents/sdk-go/v2/event"
+	. "github.com/onsi/gomega"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/cesql"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
+	eventingtesting "github.com/kyma-project/kyma/components/eventing-controller/testing"
+)
+
+func TestEventFilterMatches(t *testing.T) {
+	event := cev2event.New(cev2event.CloudEventsVersionV1)
+	event.SetID("id")
+	event.SetSource("commerce")
+	event.SetType(eventingtesting.OrderCreatedEventType)
+	event.SetExtension("tenant", "acme")
+
+	testCases := []struct {
+		name       string
+		filter     *eventingv1alpha1.BebFilter
+		expression string
+		checkType  bool
+		expected   bool
+	}{
+		{
+			name:     "empty source matches all sources",
+			filter:   newBebFilter("exact", ""),
+			expected: true,
+		},
+		{
+			name: "missing source matches all sources",
+			filter: &eventingv1alpha1.BebFilter{
+				EventType: &eventingv1alpha1.Filter{Type: "exact", Property: "type", Value: eventingtesting.OrderCreatedEventType},
+			},
+			expected: true,
+		},
+		{
+			name:     "blank source matches all sources",
+			filter:   newBebFilter("exact", " "),
+			expected: true,
+		},
+		{
+			name:     "exact source",
+			filter:   newBebFilter("exact", "commerce"),
+			expected: true,
+		},
+		{
+			name:     "different exact source",
+			filter:   newBebFilter("exact", "comm"),
+			expected: false,
+		},
+		{
+			name:     "source prefix",
+			filter:   newBebFilter("prefix", "comm"),
+			expected: true,
+		},
+		{
+			name: "type prefix",
+			filter: &eventingv1alpha1.BebFilter{
+				EventSource: &eventingv1alpha1.Filter{Property: "source"},
+				EventType:   &eventingv1alpha1.Filter{Type: "prefix", Property: "type", Value: eventingtesting.EventTypePrefix + "." + eventingtesting.ApplicationName},
+			},
+			expected: true,
+		},
+		{
+			name: "different type prefix",
+			filter: &eventingv1alpha1.BebFilter{
+				EventSource: &eventingv1alpha1.Filter{Property: "source"},
+				EventType:   &eventingv1alpha1.Filter{Type: "prefix", Property: "type", Value: eventingtesting.EventTypePrefix + ".marketing"},
+			},
+			expected: false,
+		},
+		{
+			name:     "exact extension",
+			filter:   newBebFilter("exact", "", &eventingv1alpha1.Filter{Property: "tenant", Value: "acme"}),
+			expected: true,
+		},
+		{
+			name:     "extension prefix",
+			filter:   newBebFilter("exact", "", &eventingv1alpha1.Filter{Type: "prefix", Property: "Tenant", Value: "ac"}),
+			expected: true,
+		},
+		{
+			name:     "different extension",
+			filter:   newBebFilter("exact", "", &eventingv1alpha1.Filter{Property: "tenant", Value: "test"}),
+			expected: false,
+		},
+		{
+			name:     "missing extension",
+			filter:   newBebFilter("exact", "", &eventingv1alpha1.Filter{Property: "region", Value: "eu"}),
+			expected: false,
+		},
+		{
+			name:       "matching expression",
+			filter:     newBebFilter("exact", "commerce"),
+			expression: "tenant = 'acme'",
+			expected:   true,
+		},
+		{
+			name:       "expression which does not match",
+			filter:     newBebFilter("exact", "commerce"),
+			expression: "tenant = 'test'",
+			expected:   false,
+		},
+		{
+			name: "different exact type is not checked",
+			filter: &eventingv1alpha1.BebFilter{
+				EventType: &eventingv1alpha1.Filter{Type: "exact", Property: "type", Value: eventingtesting.OrderCreatedEventType + ".other"},
+			},
+			expected: true,
+		},
+		{
+			name: "different exact type is checked",
+			filter: &eventingv1alpha1.BebFilter{
+				EventType: &eventingv1alpha1.Filter{Type: "exact", Property: "type", Value: eventingtesting.OrderCreatedEventType + ".other"},
+			},
+			checkType: true,
+			expected:  false,
+		},
+		{
+			name:      "checked exact type",
+			filter:    newBebFilter("exact", "commerce"),
+			checkType: true,
+			expected:  true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			filter := eventFilter{filters: []*eventingv1alpha1.BebFilter{tc.filter}, checkType: tc.checkType}
+			if len(tc.expression) > 0 {
+				expression, err := cesql.Parse(tc.expression)
+				g.Expect(err).ShouldNot(HaveOccurred())
+				filter.expression = expression
+			}
+			g.Expect(filter.matches(&event)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(validateFilter(newBebFilter("exact", "commerce"))).Should(Succeed())
+	g.Expect(validateFilter(newBebFilter("prefix", "commerce"))).Should(Succeed())
+	g.Expect(validateFilter(newBebFilter("suffix", "commerce"))).ShouldNot(Succeed())
+	g.Expect(validateFilter(newBebFilter("exact", "", &eventingv1alpha1.Filter{Value: "acme"}))).ShouldNot(Succeed())
+	g.Expect(validateFilter(&eventingv1alpha1.BebFilter{})).ShouldNot(Succeed())
+}
+
+func TestCreateWildcardSubject(t *testing.T) {
+	natsClient := Nats{config: env.NatsConfig{EventTypePrefix: "sap.kyma.custom"}}
+	cleaner := eventtype.CleanerFunc(func(et string) (string, error) {
+		return strings.ReplaceAll(et, "-", ""), nil
+	})
+
+	testCases := []struct {
+		prefix          string
+		expectedSubject string
+		expectedPrefix  string
+		wantErr         bool
+	}{
+		{prefix: "sap.kyma.custom.comm-erce.order", expectedSubject: "sap.kyma.custom.commerce.>", expectedPrefix: "sap.kyma.custom.commerce.order"},
+		{prefix: "sap.kyma.custom.commerce.", expectedSubject: "sap.kyma.custom.commerce.>", expectedPrefix: "sap.kyma.custom.commerce."},
+		{prefix: "sap.kyma.custom.comm-", expectedSubject: "sap.kyma.custom.>", expectedPrefix: "sap.kyma.custom.comm"},
+		{prefix: "sap.kyma", expectedSubject: "sap.kyma.custom.>", expectedPrefix: "sap.kyma.custom"},
+		{prefix: "", expectedSubject: "sap.kyma.custom.>", expectedPrefix: "sap.kyma.custom"},
+		{prefix: "other.commerce", wantErr: true},
+		{prefix: "other", wantErr: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.prefix, func(t *testing.T) {
+			g := NewWithT(t)
+			subject, prefix, err := natsClient.createWildcardSubject(tc.prefix, cleaner)
+			if tc.wantErr {
+				g.Expect(err).Should(HaveOccurred())
+				return
+			}
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(subject).To(Equal(tc.expectedSubject))
+			g.Expect(prefix).To(Equal(tc.expectedPrefix))
+		})
+	}
+}
+
+func TestMergeOverlappingSubjects(t *testing.T) {
+	g := NewWithT(t)
+	exactFilter := newBebFilter("exact", "")
+	appFilter := &eventingv1alpha1.BebFilter{
+		EventType: &eventingv1alpha1.Filter{Type: "prefix", Property: "type", Value: eventingtesting.EventTypePrefix + "." + eventingtesting.ApplicationName},
+	}
+	allFilter := &eventingv1alpha1.BebFilter{
+		EventType: &eventingv1alpha1.Filter{Type: "prefix", Property: "type", Value: eventingtesting.EventTypePrefix},
+	}
+	otherFilter := &eventingv1alpha1.BebFilter{
+		EventType: &eventingv1alpha1.Filter{Type: "exact", Property: "type", Value: "other.type"},
+	}
+	appSubject := eventingtesting.EventTypePrefix + "." + eventingtesting.ApplicationName + ".>"
+	allSubject := eventingtesting.EventTypePrefix + ".>"
+
+	subjects, filtersBySubject := mergeOverlappingSubjects(
+		[]string{eventingtesting.OrderCreatedEventType, appSubject, allSubject, "other.type"},
+		map[string]eventFilter{
+			eventingtesting.OrderCreatedEventType: {filters: []*eventingv1alpha1.BebFilter{exactFilter}},
+			appSubject:                            {filters: []*eventingv1alpha1.BebFilter{appFilter}},
+			allSubject:                            {filters: []*eventingv1alpha1.BebFilter{allFilter}},
+			"other.type":                          {filters: []*eventingv1alpha1.BebFilter{otherFilter}},
+		},
+	)
+
+	g.Expect(subjects).To(Equal([]string{allSubject, "other.type"}))
+	g.Expect(filtersBySubject[allSubject].filters).To(ConsistOf(exactFilter, appFilter, allFilter))
+	g.Expect(filtersBySubject[allSubject].checkType).To(BeTrue())
+	g.Expect(filtersBySubject["other.type"].filters).To(ConsistOf(otherFilter))
+	g.Expect(filtersBySubject["other.type"].checkType).To(BeFalse())
+}
+
+func newBebFilter(sourceFilterType, source string, extensions ...*eventingv1alpha1.Filter) *eventingv1alpha1.BebFilter {
+	return &eventingv1alpha1.BebFilter{
+		EventSource: &eventingv1alpha1.Filter{Type: sourceFilterType, Property: "source", Value: source},
+		EventType:   &eventingv1alpha1.Filter{Type: "exact", Property: "type", Value: eventingtesting.OrderCreatedEventType},
+		Extensions:  extensions,
+	}
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	cev2 "github.com/cloudevents/sdk-go/v2"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/cesql"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/kafka"
+)
+
+const (
+	// kafkaRequestTimeout limits the requests to the Kafka cluster which are not part of the consumption
+	kafkaRequestTimeout = 30 * time.Second
+	// kafkaRetryPeriod is the time a consumer waits after a failed request before it tries again
+	kafkaRetryPeriod = 5 * time.Second
+)
+
+// compile time checks
+var _ MessagingBackend = &Kafka{}
+var _ sarama.ConsumerGroupHandler = &kafkaGroupHandler{}
+
+// Kafka dispatches the events of a Kafka topic to the sinks of the Kyma subscriptions. Every Kyma subscription has
+// a consumer group of its own, whose members share the partitions of the topic and commit the offsets of the
+// dispatched events. A new consumer group starts with the events which are published after its creation.
+type Kafka struct {
+	config   env.KafkaConfig
+	log      logr.Logger
+	cluster  kafka.Cluster
+	client   sarama.Client
+	admin    sarama.ClusterAdmin
+	ceClient cev2.Client
+
+	consumersLock sync.Mutex
+	consumers     map[string]*kafkaConsumer
+}
+
+// kafkaConsumer is the member of the consumer group of a Kyma subscription
+type kafkaConsumer struct {
+	group  sarama.ConsumerGroup
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// kafkaGroupHandler dispatches the events of the partitions which are claimed by the member of the consumer group
+// of a Kyma subscription
+type kafkaGroupHandler struct {
+	kafka  *Kafka
+	sub    *eventingv1alpha1.Subscription
+	group  string
+	filter eventFilter
+	policy deliveryPolicy
+	d      *dispatcher
+}
+
+func NewKafka(config env.KafkaConfig, cluster kafka.Cluster, log logr.Logger) *Kafka {
+	return &Kafka{
+		config:    config,
+		log:       log,
+		cluster:   cluster,
+		consumers: make(map[string]*kafkaConsumer),
+	}
+}
+
+// Initialize creates the clients of the Kafka cluster and makes sure that the topic of the events exists
+func (k *Kafka) Initialize(cfg env.Config) error {
+	if k.client == nil {
+		client, err := k.cluster.NewClient()
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the Kafka client")
+		}
+		admin, err := k.cluster.NewClusterAdmin()
+		if err != nil {
+			_ = client.Close()
+			return errors.Wrapf(err, "failed to create the Kafka admin client")
+		}
+		k.client, k.admin = client, admin
+	}
+
+	k.log.Info("Create the Kafka topic", "topic", k.config.Topic)
+	detail := &sarama.TopicDetail{NumPartitions: k.config.TopicPartitions, ReplicationFactor: k.config.TopicReplicationFactor}
+	if err := k.admin.CreateTopic(k.config.Topic, detail, false); err != nil {
+		var topicErr *sarama.TopicError
+		if !errors.As(err, &topicErr) || topicErr.Err != sarama.ErrTopicAlreadyExists {
+			return errors.Wrapf(err, "failed to create the Kafka topic %s", k.config.Topic)
+		}
+	}
+
+	if k.ceClient != nil {
+		return nil
+	}
+	k.log.Info("Initialize cloudevents client")
+	var err error
+	k.ceClient, err = newHTTPCloudeventClient(&http.Transport{
+		MaxIdleConns:        k.config.MaxIdleConns,
+		MaxConnsPerHost:     k.config.MaxConnsPerHost,
+		MaxIdleConnsPerHost: k.config.MaxIdleConnsPerHost,
+		IdleConnTimeout:     k.config.IdleConnTimeout,
+	})
+	return err
+}
+
+// SyncSubscription joins the consumer group of the Kyma subscription, the member of a previous sync leaves the
+// group before. The returned bool should be ignored, it is always false.
+func (k *Kafka) SyncSubscription(sub *eventingv1alpha1.Subscription, cleaner eventtype.Cleaner, params ...interface{}) (bool, error) {
+	filter, err := getKafkaEventFilter(sub, cleaner)
+	if err != nil {
+		return false, err
+	}
+
+	key := createKeyPrefix(sub)
+	k.stopConsumer(key)
+
+	sub = sub.DeepCopy()
+	group := k.consumerGroup(sub)
+	consumerGroup, err := k.cluster.NewConsumerGroup(group)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to create the Kafka consumer group %s", group)
+	}
+	handler := &kafkaGroupHandler{
+		kafka:  k,
+		sub:    sub,
+		group:  group,
+		filter: filter,
+		policy: newDeliveryPolicy(sub, maxTries+1),
+		// the limits of the Kyma subscription are shared by the consumers of all claimed partitions
+		d: newDispatcher(sub),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := &kafkaConsumer{group: consumerGroup, cancel: cancel}
+	consumer.done.Add(2)
+	go func() {
+		defer consumer.done.Done()
+		for err := range consumerGroup.Errors() {
+			k.log.Error(err, "failed to consume events from Kafka", "group", group)
+		}
+	}()
+	go func() {
+		defer consumer.done.Done()
+		k.consume(ctx, consumerGroup, group, handler)
+	}()
+
+	k.consumersLock.Lock()
+	defer k.consumersLock.Unlock()
+	k.consumers[key] = consumer
+	k.log.Info("started Kafka consumer", "group", group)
+	return false, nil
+}
+
+// getKafkaEventFilter returns the event filter of a Kyma subscription. All events are read from the same topic,
+// hence the exact event types are checked by the filter, they are cleaned the same way as by the publisher.
+func getKafkaEventFilter(sub *eventingv1alpha1.Subscription, cleaner eventtype.Cleaner) (eventFilter, error) {
+	filter := eventFilter{checkType: true}
+	if sub.Spec.Filter == nil {
+		return filter, nil
+	}
+	uniqueFilters, err := sub.Spec.Filter.Deduplicate()
+	if err != nil {
+		return filter, errors.Wrap(err, "error deduplicating subscription filters")
+	}
+	if len(sub.Spec.Filter.Expression) > 0 {
+		if filter.expression, err = cesql.Parse(sub.Spec.Filter.Expression); err != nil {
+			return filter, errors.Wrap(err, "invalid filter expression")
+		}
+	}
+	for _, f := range uniqueFilters.Filters {
+		if err := validateFilter(f); err != nil {
+			return filter, errors.Wrap(err, "invalid subscription filter")
+		}
+		f = f.DeepCopy()
+		f.EventType.Value = strings.TrimSpace(f.EventType.Value)
+		if f.EventType.Type != eventingv1alpha1.FilterTypePrefix {
+			if len(f.EventType.Value) == 0 {
+				return filter, errors.New("invalid subscription filter: event type is empty")
+			}
+			if f.EventType.Value, err = cleaner.Clean(f.EventType.Value); err != nil {
+				return filter, errors.Wrap(err, "failed to clean the event type")
+			}
+		}
+		filter.filters = append(filter.filters, f)
+	}
+	return filter, nil
+}
+
+// DeleteSubscription stops the consumer of the Kyma subscription. Its consumer group keeps the offsets, so that
+// the events published in the meantime are dispatched once the subscription is synced again. The consumer group is
+// deleted only if the Kyma subscription itself is being deleted.
+func (k *Kafka) DeleteSubscription(sub *eventingv1alpha1.Subscription) error {
+	k.stopConsumer(createKeyPrefix(sub))
+	if sub.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	group := k.consumerGroup(sub)
+	if err := k.admin.DeleteConsumerGroup(group); err != nil && !errors.Is(err, sarama.ErrGroupIDNotFound) {
+		return errors.Wrapf(err, "failed to delete the Kafka consumer group %s", group)
+	}
+	k.log.Info("successfully deleted consumer group", "group", group)
+	return nil
+}
+
+// Close stops the consumers of all Kyma subscriptions and closes the clients of the Kafka cluster. The consumer
+// groups are kept.
+func (k *Kafka) Close() {
+	k.consumersLock.Lock()
+	keys := make([]string, 0, len(k.consumers))
+	for key := range k.consumers {
+		keys = append(keys, key)
+	}
+	k.consumersLock.Unlock()
+	for _, key := range keys {
+		k.stopConsumer(key)
+	}
+	if k.admin != nil {
+		if err := k.admin.Close(); err != nil {
+			k.log.Error(err, "failed to close the Kafka admin client")
+		}
+	}
+	if k.client != nil {
+		if err := k.client.Close(); err != nil {
+			k.log.Error(err, "failed to close the Kafka client")
+		}
+	}
+}
+
+// stopConsumer stops the consumer with the given key, which commits its offsets and leaves the consumer group
+func (k *Kafka) stopConsumer(key string) {
+	k.consumersLock.Lock()
+	consumer, ok := k.consumers[key]
+	delete(k.consumers, key)
+	k.consumersLock.Unlock()
+	if !ok {
+		return
+	}
+	consumer.cancel()
+	if err := consumer.group.Close(); err != nil {
+		k.log.Error(err, "failed to close the Kafka consumer group")
+	}
+	consumer.done.Wait()
+}
+
+func (k *Kafka) consumerGroup(sub *eventingv1alpha1.Subscription) string {
+	return fmt.Sprintf("%s%s.%s", k.config.ConsumerGroupPrefix, sub.Namespace, sub.Name)
+}
+
+// consume joins the consumer group until the context is done. A session of the consumer group ends when the
+// partitions are rebalanced between its members, then the consumer joins the group again.
+func (k *Kafka) consume(ctx context.Context, consumerGroup sarama.ConsumerGroup, group string, handler *kafkaGroupHandler) {
+	for ctx.Err() == nil {
+		if err := consumerGroup.Consume(ctx, []string{k.config.Topic}, handler); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return
+			}
+			k.log.Error(err, "failed to join the Kafka consumer group", "group", group)
+			sleepContext(ctx, kafkaRetryPeriod)
+		}
+	}
+}
+
+// Setup implements the sarama.ConsumerGroupHandler interface. A claimed partition without a committed offset
+// starts at its end, this offset is committed right away so that the events published from now on are not skipped
+// if the consumer stops before it commits.
+func (h *kafkaGroupHandler) Setup(sess sarama.ConsumerGroupSession) error {
+	offsets, err := h.kafka.admin.ListConsumerGroupOffsets(h.group, sess.Claims())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the offsets of the consumer group")
+	}
+	committed := false
+	for topic, partitions := range sess.Claims() {
+		for _, partition := range partitions {
+			if block := offsets.GetBlock(topic, partition); block != nil && block.Offset >= 0 {
+				continue
+			}
+			offset, err := h.kafka.client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get the latest offset of the partition %d", partition)
+			}
+			sess.MarkOffset(topic, partition, offset, "")
+			committed = true
+		}
+	}
+	if committed {
+		sess.Commit()
+	}
+	return nil
+}
+
+// Cleanup implements the sarama.ConsumerGroupHandler interface
+func (h *kafkaGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim implements the sarama.ConsumerGroupHandler interface. It dispatches the events of a partition one
+// after the other and marks their offsets, which the consumer group commits periodically and when the session ends.
+// So an event is dispatched again only if the controller stops without committing.
+func (h *kafkaGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		h.kafka.dispatchRecord(sess.Context(), h.sub, msg, h.filter, h.policy, h.d)
+		if sess.Context().Err() != nil {
+			// the interrupted delivery is repeated once the subscription is synced again
+			return nil
+		}
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// dispatchRecord delivers the event of a record to the sink, if it matches the filter of the Kyma subscription.
+// An event which cannot be delivered with the attempts of the delivery policy is sent to the dead-letter sink.
+func (k *Kafka) dispatchRecord(ctx context.Context, sub *eventingv1alpha1.Subscription, record *sarama.ConsumerMessage,
+	filter eventFilter, policy deliveryPolicy, d *dispatcher) {
+	ce, err := unmarshalCE(record.Value)
+	if err != nil {
+		k.log.Error(err, "failed to convert Kafka record to CE", "offset", record.Offset)
+		return
+	}
+	if !filter.matches(ce) {
+		return
+	}
+
+	d.run(func() {
+		delivery := deliver(ctx, k.ceClient, *ce, policy, 1, d.wait)
+		if delivery.err == nil {
+			k.log.Info(fmt.Sprintf("Successfully dispatched event id: %s to sink: %s", ce.ID(), policy.sink))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		k.log.Error(delivery.err, "failed to dispatch event", "id", ce.ID(), "attempts", delivery.attempts)
+		if err := sendDeadLetter(ctx, k.ceClient, k.log, sub, *ce, policy, delivery); err != nil {
+			k.log.Error(err, "failed to dispatch event to the dead-letter sink", "id", ce.ID())
+		}
+	})
+}
+
+// sleepContext waits for the given time and returns false if the context is done before
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
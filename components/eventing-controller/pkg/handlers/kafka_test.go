@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/application/applicationtest"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/application/fake"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/kafka/kafkatest"
+	eventingtesting "github.com/kyma-project/kyma/components/eventing-controller/testing"
+)
+
+func TestKafkaSubscription(t *testing.T) {
+	g := NewWithT(t)
+
+	subscriberPort := 8080
+	subscriberReceiveURL := fmt.Sprintf("http://127.0.0.1:%d/store", subscriberPort)
+	subscriberCheckURL := fmt.Sprintf("http://127.0.0.1:%d/check", subscriberPort)
+
+	// Start the Kafka stand-in
+	cluster := kafkatest.NewCluster()
+
+	topic := "kyma-eventing"
+	kafkaClient := NewKafka(env.KafkaConfig{
+		Topic:                  topic,
+		TopicPartitions:        2,
+		TopicReplicationFactor: 1,
+		ConsumerGroupPrefix:    "kyma-eventing.",
+	}, cluster, ctrl.Log.WithName("reconciler").WithName("Subscription"))
+	g.Expect(kafkaClient.Initialize(env.Config{})).Should(Succeed())
+	// initializing an existing topic succeeds
+	g.Expect(kafkaClient.Initialize(env.Config{})).Should(Succeed())
+	defer kafkaClient.Close()
+
+	// Create a new subscriber
+	subscriber := eventingtesting.NewSubscriber(fmt.Sprintf(":%d", subscriberPort))
+	subscriber.Start()
+	defer subscriber.Shutdown()
+	g.Expect(subscriber.CheckEvent("", subscriberCheckURL)).Should(Succeed())
+
+	// Prepare event-type cleaner
+	application := applicationtest.NewApplication(eventingtesting.ApplicationNameNotClean, nil)
+	applicationLister := fake.NewApplicationListerOrDie(context.Background(), application)
+	cleaner := eventtype.NewCleaner(eventingtesting.EventTypePrefix, applicationLister, ctrl.Log.WithName("cleaner"))
+
+	// Create a subscription
+	sub := eventingtesting.NewSubscription("sub", "foo", eventingtesting.WithNotCleanEventTypeFilter)
+	sub.Spec.Sink = subscriberReceiveURL
+	_, err := kafkaClient.SyncSubscription(sub, cleaner)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	// the consumer starts at the latest offsets of the partitions, which are committed right away
+	group := "kyma-eventing.foo.sub"
+	committedOffset := func(partition int32) func() int64 {
+		return func() int64 {
+			offset, ok := cluster.CommittedOffset(group, topic, partition)
+			if !ok {
+				return -1
+			}
+			return offset
+		}
+	}
+	g.Eventually(func() bool { return cluster.HasGroup(group) }).Should(BeTrue())
+	g.Eventually(committedOffset(0)).Should(BeZero())
+	g.Eventually(committedOffset(1)).Should(BeZero())
+
+	sendEvent := func(partition int32, eventType, data string) {
+		eventTime := time.Now().Format(time.RFC3339)
+		payload := NewNatsMessagePayload(data, "id", eventingtesting.EventSource, eventTime, eventType)
+		cluster.Produce(topic, partition, []byte(payload))
+	}
+
+	// an event of another type is skipped, but its offset is committed
+	sendEvent(0, eventingtesting.EventTypePrefix+".other.order.created.v1", "otherdata")
+	sendEvent(1, eventingtesting.OrderCreatedEventType, "sampledata")
+	g.Expect(subscriber.CheckEvent("\"sampledata\"", subscriberCheckURL)).Should(Succeed())
+	g.Eventually(committedOffset(0)).Should(BeEquivalentTo(1))
+	g.Eventually(committedOffset(1)).Should(BeEquivalentTo(1))
+
+	// the consumer group keeps its offsets while the consumers are stopped
+	g.Expect(kafkaClient.DeleteSubscription(sub)).Should(Succeed())
+	sendEvent(0, eventingtesting.OrderCreatedEventType, "dataofstoppedsubscription")
+	_, err = kafkaClient.SyncSubscription(sub, cleaner)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(subscriber.CheckEvent("\"dataofstoppedsubscription\"", subscriberCheckURL)).Should(Succeed())
+	g.Eventually(committedOffset(0)).Should(BeEquivalentTo(2))
+
+	// the consumer group is deleted together with the subscription
+	now := metav1.Now()
+	sub.DeletionTimestamp = &now
+	g.Expect(kafkaClient.DeleteSubscription(sub)).Should(Succeed())
+	g.Expect(cluster.HasGroup(group)).Should(BeFalse())
+	g.Expect(kafkaClient.consumers).To(BeEmpty())
+}
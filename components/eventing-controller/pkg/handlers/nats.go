@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -19,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 
 	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/cesql"
 	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
 )
 
@@ -30,11 +33,29 @@ type Nats struct {
 	log           logr.Logger
 	client        cev2.Client
 	connection    *nats.Conn
+	jsContext     nats.JetStreamContext
 	subscriptions map[string]*nats.Subscription
+
+	// dispatchers are accessed by the status reporting as well, hence they are guarded by a lock
+	dispatchersLock sync.RWMutex
+	dispatchers     map[string]*dispatcher
+
+	// stats are the outcomes of the deliveries since they were collected the last time
+	statsLock sync.Mutex
+	stats     map[types.NamespacedName]*DeliveryStats
+
+	// replays are the running replays and the finished ones which were not reported yet
+	replaysLock sync.Mutex
+	replays     map[types.NamespacedName]*replay
 }
 
 func NewNats(config env.NatsConfig, log logr.Logger) *Nats {
-	return &Nats{config: config, log: log, subscriptions: make(map[string]*nats.Subscription)}
+	return &Nats{
+		config:        config,
+		log:           log,
+		subscriptions: make(map[string]*nats.Subscription),
+		dispatchers:   make(map[string]*dispatcher),
+	}
 }
 
 const (
@@ -61,6 +82,12 @@ func (n *Nats) Initialize(cfg env.Config) error {
 	}
 	n.log.Info("Successfully connected to Nats", "status", n.connection.Status())
 
+	if n.isPersistent() {
+		if err := n.initializeStream(); err != nil {
+			return err
+		}
+	}
+
 	if n.client != nil {
 		return nil
 	}
@@ -73,13 +100,61 @@ func (n *Nats) Initialize(cfg env.Config) error {
 	return nil
 }
 
+// initializeStream creates the JetStream context and makes sure that the stream which persists the events exists.
+func (n *Nats) initializeStream() error {
+	var err error
+	if n.jsContext, err = n.connection.JetStream(); err != nil {
+		return errors.Wrapf(err, "failed to create the JetStream context")
+	}
+	if _, err := n.jsContext.StreamInfo(n.config.StreamName); err == nil {
+		return nil
+	}
+	storage, err := getStorageType(n.config.StreamStorageType)
+	if err != nil {
+		return err
+	}
+	streamConfig := &nats.StreamConfig{
+		Name:       n.config.StreamName,
+		Subjects:   []string{fmt.Sprintf("%s.>", n.config.EventTypePrefix)},
+		Retention:  nats.LimitsPolicy,
+		MaxAge:     n.config.StreamMaxAge,
+		Storage:    storage,
+		Duplicates: n.config.StreamDuplicatesWindow,
+	}
+	n.log.Info("Create the NATS stream", "stream", n.config.StreamName)
+	if _, err := n.jsContext.AddStream(streamConfig); err != nil {
+		return errors.Wrapf(err, "failed to create the NATS stream %s", n.config.StreamName)
+	}
+	return nil
+}
+
+func getStorageType(storageType string) (nats.StorageType, error) {
+	switch storageType {
+	case "file":
+		return nats.FileStorage, nil
+	case "memory":
+		return nats.MemoryStorage, nil
+	default:
+		return 0, fmt.Errorf("invalid stream storage type: %s", storageType)
+	}
+}
+
+// isPersistent returns true if the events are persisted in a stream and delivered at least once.
+func (n *Nats) isPersistent() bool {
+	return n.config.DeliveryMode == string(eventingv1alpha1.NatsDeliveryModeAtLeastOnce)
+}
+
 func newCloudeventClient(config env.NatsConfig) (cev2.Client, error) {
-	transport := &http.Transport{
+	return newHTTPCloudeventClient(&http.Transport{
 		MaxIdleConns:        config.MaxIdleConns,
 		MaxConnsPerHost:     config.MaxConnsPerHost,
 		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
 		IdleConnTimeout:     config.IdleConnTimeout,
-	}
+	})
+}
+
+// newHTTPCloudeventClient returns a client which sends events to the sinks through the given transport
+func newHTTPCloudeventClient(transport *http.Transport) (cev2.Client, error) {
 	protocol, err := cev2.NewHTTP(cev2.WithRoundTripper(transport))
 	if err != nil {
 		return nil, err
@@ -89,23 +164,22 @@ func newCloudeventClient(config env.NatsConfig) (cev2.Client, error) {
 
 // The returned bool should be ignored now. It's a marker for changed subscription status
 func (n *Nats) SyncSubscription(sub *eventingv1alpha1.Subscription, cleaner eventtype.Cleaner, params ...interface{}) (bool, error) {
-	var filters []*eventingv1alpha1.BebFilter
-	if sub.Spec.Filter != nil {
-		uniqueFilters, err := sub.Spec.Filter.Deduplicate()
-		if err != nil {
-			return false, errors.Wrap(err, "error deduplicating subscription filters")
-		}
-		filters = uniqueFilters.Filters
+	subjects, filtersBySubject, err := n.getSubjectFilters(sub, cleaner)
+	if err != nil {
+		return false, err
 	}
-	// Create subscriptions in Nats
-	for _, filter := range filters {
-		subject, err := createSubject(filter, cleaner)
-		if err != nil {
-			n.log.Error(err, "failed to create a Nats subject")
+
+	if n.isPersistent() {
+		if err := n.deleteUnusedConsumers(sub, subjects); err != nil {
+			n.log.Error(err, "failed to delete the consumers of removed filters")
 			return false, err
 		}
+	}
 
-		callback := n.getCallback(sub.Spec.Sink)
+	// Create subscriptions in Nats, which share the limits of the Kyma subscription
+	d := newDispatcher(sub)
+	for _, subject := range subjects {
+		callback := n.getCallback(sub, filtersBySubject[subject], d)
 
 		if n.connection.Status() != nats.CONNECTED {
 			n.log.Info("connection to Nats", "status", fmt.Sprintf("%v", n.connection.Status()))
@@ -116,18 +190,146 @@ func (n *Nats) SyncSubscription(sub *eventingv1alpha1.Subscription, cleaner even
 			}
 		}
 
-		natsSub, subscribeErr := n.connection.Subscribe(subject, callback)
+		natsSub, subscribeErr := n.subscribe(sub, subject, callback, d.maxPending())
 		if subscribeErr != nil {
 			n.log.Error(subscribeErr, "failed to create a Nats subscription")
 			return false, subscribeErr
 		}
 		n.subscriptions[createKey(sub, subject)] = natsSub
+		d.addSubscription(natsSub)
 	}
+	n.setDispatcher(createKeyPrefix(sub), d)
 	return false, nil
 }
 
+// getSubjectFilters returns the NATS subjects of the filters of a Kyma subscription in the order of the filters and
+// the event filter of every subject. The filters are grouped by their subject, and the subjects covered by a wildcard
+// subject of the same subscription are merged into it, since an event must be received on one subject only.
+func (n *Nats) getSubjectFilters(sub *eventingv1alpha1.Subscription, cleaner eventtype.Cleaner) ([]string, map[string]eventFilter, error) {
+	var filters []*eventingv1alpha1.BebFilter
+	var expression cesql.Expression
+	if sub.Spec.Filter != nil {
+		uniqueFilters, err := sub.Spec.Filter.Deduplicate()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error deduplicating subscription filters")
+		}
+		filters = uniqueFilters.Filters
+		if len(sub.Spec.Filter.Expression) > 0 {
+			if expression, err = cesql.Parse(sub.Spec.Filter.Expression); err != nil {
+				return nil, nil, errors.Wrap(err, "invalid filter expression")
+			}
+		}
+	}
+
+	var subjects []string
+	filtersBySubject := make(map[string]eventFilter)
+	for _, filter := range filters {
+		if err := validateFilter(filter); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid subscription filter")
+		}
+		subject, eventType, err := n.createSubject(filter, cleaner)
+		if err != nil {
+			n.log.Error(err, "failed to create a Nats subject")
+			return nil, nil, err
+		}
+		// the events are matched by their cleaned types
+		cleanFilter := filter.DeepCopy()
+		cleanFilter.EventType.Value = eventType
+
+		subjectFilter, ok := filtersBySubject[subject]
+		if !ok {
+			subjects = append(subjects, subject)
+			subjectFilter = eventFilter{expression: expression}
+		}
+		subjectFilter.filters = append(subjectFilter.filters, cleanFilter)
+		filtersBySubject[subject] = subjectFilter
+	}
+	subjects, filtersBySubject = mergeOverlappingSubjects(subjects, filtersBySubject)
+	return subjects, filtersBySubject, nil
+}
+
+// mergeOverlappingSubjects moves the filters of the subjects covered by a wildcard subject to the broadest wildcard
+// subject covering them. The types of the moved filters are checked, since the wildcard subject receives other
+// events as well.
+func mergeOverlappingSubjects(subjects []string, filtersBySubject map[string]eventFilter) ([]string, map[string]eventFilter) {
+	var mergedSubjects []string
+	mergedFilters := make(map[string]eventFilter, len(filtersBySubject))
+	for _, subject := range subjects {
+		target := subject
+		for _, other := range subjects {
+			if other != target && subjectCovers(other, target) {
+				target = other
+			}
+		}
+		targetFilter, ok := mergedFilters[target]
+		if !ok {
+			mergedSubjects = append(mergedSubjects, target)
+			targetFilter = eventFilter{expression: filtersBySubject[subject].expression}
+		}
+		targetFilter.filters = append(targetFilter.filters, filtersBySubject[subject].filters...)
+		targetFilter.checkType = targetFilter.checkType || target != subject
+		mergedFilters[target] = targetFilter
+	}
+	return mergedSubjects, mergedFilters
+}
+
+// subjectCovers returns true if the wildcard subject receives all the events of the other subject
+func subjectCovers(wildcard, subject string) bool {
+	if !strings.HasSuffix(wildcard, ">") {
+		return false
+	}
+	return strings.HasPrefix(subject, strings.TrimSuffix(wildcard, ">"))
+}
+
+func (n *Nats) setDispatcher(key string, d *dispatcher) {
+	n.dispatchersLock.Lock()
+	defer n.dispatchersLock.Unlock()
+	if n.dispatchers == nil {
+		n.dispatchers = make(map[string]*dispatcher)
+	}
+	if d == nil {
+		delete(n.dispatchers, key)
+		return
+	}
+	n.dispatchers[key] = d
+}
+
+// GetDispatcherStatus returns the state of the delivery of events for all Kyma subscriptions
+func (n *Nats) GetDispatcherStatus() map[types.NamespacedName]eventingv1alpha1.DispatcherStatus {
+	n.dispatchersLock.RLock()
+	defer n.dispatchersLock.RUnlock()
+	status := make(map[types.NamespacedName]eventingv1alpha1.DispatcherStatus, len(n.dispatchers))
+	for key, d := range n.dispatchers {
+		nsn := strings.SplitN(key, string(types.Separator), 2)
+		status[types.NamespacedName{Namespace: nsn[0], Name: nsn[1]}] = d.status()
+	}
+	return status
+}
+
+// subscribe creates a NATS subscription for the given subject. In the persistent mode, the subscription is bound to
+// a durable consumer, which keeps track of the acknowledged events. The consumer pushes at most maxAckPending events
+// which are not acknowledged yet, the limit is set when the consumer is created.
+func (n *Nats) subscribe(sub *eventingv1alpha1.Subscription, subject string, callback nats.MsgHandler, maxAckPending int) (*nats.Subscription, error) {
+	if !n.isPersistent() {
+		return n.connection.Subscribe(subject, callback)
+	}
+	return n.jsContext.Subscribe(subject, callback,
+		nats.Durable(createDurableName(sub, subject)),
+		nats.BindStream(n.config.StreamName),
+		nats.DeliverNew(),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.AckWait(n.config.AckWait),
+		nats.MaxAckPending(maxAckPending),
+	)
+}
+
 // DeleteSubscription deletes all NATS subscriptions corresponding to a Kyma subscription
 func (n *Nats) DeleteSubscription(subscription *eventingv1alpha1.Subscription) error {
+	n.setDispatcher(createKeyPrefix(subscription), nil)
+	if n.isPersistent() {
+		return n.deletePersistentSubscription(subscription)
+	}
 	for key, sub := range n.subscriptions {
 		if strings.HasPrefix(key, createKeyPrefix(subscription)) {
 			n.log.Info("connection status", "status", n.connection.Status())
@@ -152,6 +354,57 @@ func (n *Nats) DeleteSubscription(subscription *eventingv1alpha1.Subscription) e
 	return nil
 }
 
+// deletePersistentSubscription drains all NATS subscriptions corresponding to a Kyma subscription. Draining keeps
+// the durable consumers, so that the events which were not acknowledged yet are redelivered once the subscriptions
+// are synced again. The consumers are deleted only if the Kyma subscription itself is being deleted.
+func (n *Nats) deletePersistentSubscription(subscription *eventingv1alpha1.Subscription) error {
+	if n.connection.Status() != nats.CONNECTED {
+		if err := n.Initialize(env.Config{}); err != nil {
+			return errors.Wrapf(err, "can't connect to NATS server")
+		}
+	}
+	for key, sub := range n.subscriptions {
+		if !strings.HasPrefix(key, createKeyPrefix(subscription)) {
+			continue
+		}
+		if sub.IsValid() {
+			if err := sub.Drain(); err != nil {
+				return errors.Wrapf(err, "failed to drain")
+			}
+		}
+		delete(n.subscriptions, key)
+	}
+	if subscription.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	return n.deleteUnusedConsumers(subscription, nil)
+}
+
+// deleteUnusedConsumers deletes the durable consumers of a Kyma subscription which do not belong to any of the given
+// subjects, so that the consumers of removed filters do not keep their events and redelivery state forever.
+func (n *Nats) deleteUnusedConsumers(subscription *eventingv1alpha1.Subscription, subjects []string) error {
+	used := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		used[createDurableName(subscription, subject)] = true
+	}
+
+	// the consumers are looked up on the server, since they outlive the subscriptions of a controller instance
+	durablePrefix := hashString(createKeyPrefix(subscription))
+	var unused []string
+	for consumer := range n.jsContext.ConsumerNames(n.config.StreamName) {
+		if strings.HasPrefix(consumer, durablePrefix) && !used[consumer] {
+			unused = append(unused, consumer)
+		}
+	}
+	for _, consumer := range unused {
+		if err := n.jsContext.DeleteConsumer(n.config.StreamName, consumer); err != nil {
+			return errors.Wrapf(err, "failed to delete the consumer %s", consumer)
+		}
+		n.log.Info("successfully deleted consumer", "consumer", consumer)
+	}
+	return nil
+}
+
 // GetInvalidSubscriptions returns the NamespacedName of Kyma subscriptions corresponding to NATS subscriptions marked as "invalid" by NATS client
 func (n *Nats) GetInvalidSubscriptions() *[]types.NamespacedName {
 	var nsn []types.NamespacedName
@@ -164,36 +417,151 @@ func (n *Nats) GetInvalidSubscriptions() *[]types.NamespacedName {
 	return &nsn
 }
 
-func (n *Nats) getCallback(sink string) nats.MsgHandler {
+func (n *Nats) getCallback(sub *eventingv1alpha1.Subscription, filter eventFilter, d *dispatcher) nats.MsgHandler {
+	sub = sub.DeepCopy()
+	// persisted events are redelivered until they are acknowledged, unless the subscription limits the attempts
+	defaultMaxAttempts := maxTries + 1
+	if n.isPersistent() {
+		defaultMaxAttempts = unlimitedAttempts
+	}
+	policy := newDeliveryPolicy(sub, defaultMaxAttempts)
+	subscription := types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name}
 	return func(msg *nats.Msg) {
 		ce, err := convertMsgToCE(msg)
 		if err != nil {
 			n.log.Error(err, "failed to convert Nats message to CE")
+			if n.isPersistent() {
+				// an invalid event is never going to be dispatched, hence it should not be redelivered
+				if err := msg.Term(); err != nil {
+					n.log.Error(err, "failed to terminate the delivery of the Nats message")
+				}
+			}
+			return
+		}
+
+		if !filter.matches(ce) {
+			if n.isPersistent() {
+				if err := msg.Ack(); err != nil {
+					n.log.Error(err, "failed to acknowledge the Nats message", "id", ce.ID())
+				}
+			}
 			return
 		}
 
-		// Creating a context with cancellable
-		ctxWithCancel, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		if n.isPersistent() {
+			// the consumer limits the events which are not acknowledged yet, hence they wait for a slot without
+			// blocking the callback, and they are kept in progress meanwhile, so that they are not redelivered
+			stop := n.keepInProgress(msg, ce.ID())
+			go func() {
+				defer stop()
+				d.run(func() {
+					n.dispatchPersistedEvent(context.Background(), sub, msg, *ce, policy, d.wait)
+				})
+			}()
+			return
+		}
 
-		// Creating a context with retries
-		ctxWithRetries := cev2.ContextWithRetriesExponentialBackoff(ctxWithCancel, period, maxTries)
+		d.dispatch(func() {
+			// Creating a context with cancellable
+			ctxWithCancel, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			delivery := deliver(ctxWithCancel, n.client, *ce, policy, 1, d.wait)
+			n.recordDelivery(subscription, delivery)
+			if delivery.err != nil {
+				n.log.Error(delivery.err, "failed to dispatch event", "id", ce.ID(), "attempts", delivery.attempts)
+				if err := n.sendToDeadLetterSink(ctxWithCancel, sub, *ce, policy, delivery); err != nil {
+					n.log.Error(err, "failed to dispatch event to the dead-letter sink", "id", ce.ID())
+				}
+				return
+			}
+
+			n.log.Info(fmt.Sprintf("Successfully dispatched event id: %s to sink: %s", ce.ID(), policy.sink))
+		})
+	}
+}
 
-		// Creating a context with target
-		ctxWithCE := cev2.ContextWithTarget(ctxWithRetries, sink)
+// dispatchPersistedEvent delivers an event of the stream to the sink with the retries of the delivery policy. The
+// deliveries of the event by the stream count as attempts, so that a redelivered event continues with its attempts.
+// The event is acknowledged once it is delivered or sent to the dead-letter sink, otherwise the stream redelivers it
+// after the ack wait time.
+func (n *Nats) dispatchPersistedEvent(ctx context.Context, sub *eventingv1alpha1.Subscription, msg *nats.Msg,
+	ce cev2event.Event, policy deliveryPolicy, wait func(context.Context, time.Duration) bool) {
+	first := 1
+	if metadata, err := msg.Metadata(); err == nil {
+		first = int(metadata.NumDelivered)
+	} else {
+		n.log.Error(err, "failed to get the metadata of the Nats message", "id", ce.ID())
+	}
 
-		if result := n.client.Send(ctxWithCE, *ce); !cev2.IsACK(result) {
-			n.log.Error(result, "failed to dispatch event")
+	delivery := deliver(ctx, n.client, ce, policy, first, wait)
+	// the attempts of earlier deliveries of the stream were recorded already
+	n.recordDelivery(types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name},
+		deliveryResult{attempts: delivery.attempts - first + 1, err: delivery.err})
+	if delivery.err == nil {
+		if err := msg.Ack(); err != nil {
+			n.log.Error(err, "failed to acknowledge the Nats message", "id", ce.ID())
 			return
 		}
+		n.log.Info(fmt.Sprintf("Successfully dispatched event id: %s to sink: %s", ce.ID(), policy.sink))
+		return
+	}
 
-		n.log.Info(fmt.Sprintf("Successfully dispatched event id: %s to sink: %s", ce.ID(), sink))
+	n.log.Error(delivery.err, "failed to dispatch event", "id", ce.ID(), "attempts", delivery.attempts)
+	if isRetryable(delivery.statusCode) && policy.hasAttemptsLeft(delivery.attempts) {
+		// the backoff was interrupted, the stream redelivers the event
+		return
+	}
+
+	// the event is redelivered if it cannot be sent to the dead-letter sink either
+	if err := n.sendToDeadLetterSink(ctx, sub, ce, policy, delivery); err != nil {
+		n.log.Error(err, "failed to dispatch event to the dead-letter sink", "id", ce.ID())
+		return
+	}
+	if err := msg.Term(); err != nil {
+		n.log.Error(err, "failed to terminate the delivery of the Nats message", "id", ce.ID())
 	}
 }
 
+// keepInProgress resets the redelivery timer of a persisted event every half of the ack wait time, until the returned
+// function is called.
+func (n *Nats) keepInProgress(msg *nats.Msg, id string) func() {
+	done := make(chan struct{})
+	if n.config.AckWait <= 0 {
+		return func() {}
+	}
+	go func() {
+		ticker := time.NewTicker(n.config.AckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					n.log.Error(err, "failed to mark the Nats message in progress", "id", id)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// sendToDeadLetterSink sends an event which could not be delivered to the dead-letter sink of the subscription.
+// The event is dropped if the subscription has no dead-letter sink.
+func (n *Nats) sendToDeadLetterSink(ctx context.Context, sub *eventingv1alpha1.Subscription, ce cev2event.Event,
+	policy deliveryPolicy, delivery deliveryResult) error {
+	return sendDeadLetter(ctx, n.client, n.log, sub, ce, policy, delivery)
+}
+
 func convertMsgToCE(msg *nats.Msg) (*cev2event.Event, error) {
+	return unmarshalCE(msg.Data)
+}
+
+// unmarshalCE decodes a CloudEvent in the structured JSON format and validates it
+func unmarshalCE(data []byte) (*cev2event.Event, error) {
 	event := cev2event.New(cev2event.CloudEventsVersionV1)
-	err := json.Unmarshal(msg.Data, &event)
+	err := json.Unmarshal(data, &event)
 	if err != nil {
 		return nil, err
 	}
@@ -215,14 +583,45 @@ func createKey(sub *eventingv1alpha1.Subscription, subject string) string {
 	return fmt.Sprintf("%s.%s", createKeyPrefix(sub), subject)
 }
 
-func createSubject(filter *eventingv1alpha1.BebFilter, cleaner eventtype.Cleaner) (string, error) {
+// createDurableName returns the name of the durable consumer for the given Kyma subscription and NATS subject.
+// The name starts with the hash of the Kyma subscription's namespaced name to find all its consumers.
+func createDurableName(sub *eventingv1alpha1.Subscription, subject string) string {
+	return hashString(createKeyPrefix(sub)) + hashString(subject)
+}
+
+func hashString(value string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// createSubject returns the Nats subject of the given filter along with the cleaned value of its event type. An event
+// type prefix results in a wildcard subject, which ends after the last complete segment of the cleaned prefix.
+func (n *Nats) createSubject(filter *eventingv1alpha1.BebFilter, cleaner eventtype.Cleaner) (string, string, error) {
 	eventType := strings.TrimSpace(filter.EventType.Value)
+	if filter.EventType.Type == eventingv1alpha1.FilterTypePrefix {
+		return n.createWildcardSubject(eventType, cleaner)
+	}
 	if len(eventType) == 0 {
-		return "", nats.ErrBadSubject
+		return "", "", nats.ErrBadSubject
 	}
 	// clean the application name segment in the event-type from none-alphanumeric characters
 	// return it as a Nats subject
-	return cleaner.Clean(eventType)
+	cleanEventType, err := cleaner.Clean(eventType)
+	if err != nil {
+		return "", "", err
+	}
+	return cleanEventType, cleanEventType, nil
+}
+
+// createWildcardSubject returns the wildcard subject of the event-type prefix along with the cleaned prefix. The
+// prefix has to start with the event-type prefix, since all the events and the subject of the stream start with it.
+func (n *Nats) createWildcardSubject(prefix string, cleaner eventtype.Cleaner) (string, string, error) {
+	cleanPrefix, completeSegments, err := eventtype.CleanPrefix(cleaner, n.config.EventTypePrefix, prefix)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s.>", completeSegments), cleanPrefix, nil
 }
 
 func createKymaSubscriptionNamespacedName(key string, sub *nats.Subscription) types.NamespacedName {
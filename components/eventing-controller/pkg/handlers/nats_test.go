@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +18,7 @@ import (
 	"github.com/avast/retry-go"
 
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/nats-io/nats.go"
@@ -250,6 +256,151 @@ func TestSubscriptionWithDuplicateFilters(t *testing.T) {
 	g.Expect(err).Should(HaveOccurred())
 }
 
+func TestPersistentSubscription(t *testing.T) {
+	g := NewWithT(t)
+
+	natsPort := 5224
+	subscriberPort := 8080
+	subscriberReceiveURL := fmt.Sprintf("http://127.0.0.1:%d/store", subscriberPort)
+	subscriberCheckURL := fmt.Sprintf("http://127.0.0.1:%d/check", subscriberPort)
+
+	// Start NATS server with JetStream
+	storeDir, err := ioutil.TempDir("", "jetstream")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer func() { _ = os.RemoveAll(storeDir) }()
+	natsServer := eventingtesting.RunNatsServerWithJetStreamOnPort(natsPort, storeDir)
+	defer natsServer.Shutdown()
+
+	natsClient := Nats{
+		subscriptions: make(map[string]*nats.Subscription),
+		config: env.NatsConfig{
+			Url:               natsServer.ClientURL(),
+			MaxReconnects:     2,
+			ReconnectWait:     time.Second,
+			EventTypePrefix:   eventingtesting.EventTypePrefix,
+			DeliveryMode:      string(eventingv1alpha1.NatsDeliveryModeAtLeastOnce),
+			StreamName:        "kyma",
+			StreamStorageType: "memory",
+			AckWait:           time.Second,
+		},
+		log: ctrl.Log.WithName("reconciler").WithName("Subscription"),
+	}
+	if err := natsClient.Initialize(env.Config{}); err != nil {
+		t.Fatalf("failed to connect to NATS Server: %v", err)
+	}
+
+	// Create a subscription while the subscriber is not running yet
+	sub := eventingtesting.NewSubscription("sub", "foo", eventingtesting.WithEventTypeFilter)
+	sub.Spec.Sink = subscriberReceiveURL
+	sub.Spec.DeliveryPolicy = &eventingv1alpha1.DeliveryPolicy{
+		BackoffStrategy: eventingv1alpha1.BackoffStrategyConstant,
+		BackoffDelay:    &metav1.Duration{Duration: 200 * time.Millisecond},
+	}
+	idFunc := func(et string) (string, error) { return et, nil }
+	if _, err := natsClient.SyncSubscription(sub, eventtype.CleanerFunc(idFunc)); err != nil {
+		t.Fatalf("failed to sync subscription: %v", err)
+	}
+
+	// Send an event which cannot be dispatched
+	data := "sampledata"
+	if err := SendEventToNATS(&natsClient, data); err != nil {
+		t.Fatalf("failed to publish event: %v", err)
+	}
+
+	// The event is redelivered once the subscriber is running
+	subscriber := eventingtesting.NewSubscriber(fmt.Sprintf(":%d", subscriberPort))
+	subscriber.Start()
+	defer subscriber.Shutdown()
+	expectedDataInStore := fmt.Sprintf("\"%s\"", data)
+	if err := subscriber.CheckEvent(expectedDataInStore, subscriberCheckURL); err != nil {
+		t.Fatalf("subscriber did not receive the event: %v", err)
+	}
+
+	// Resyncing the subscription keeps its consumer
+	durableName := createDurableName(sub, eventingtesting.OrderCreatedEventType)
+	g.Expect(natsClient.DeleteSubscription(sub)).Should(Succeed())
+	_, err = natsClient.jsContext.ConsumerInfo("kyma", durableName)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	_, err = natsClient.SyncSubscription(sub, eventtype.CleanerFunc(idFunc))
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	// Replacing the filter deletes the consumer of the removed filter
+	changedSub := sub.DeepCopy()
+	changedSub.Spec.Filter.Filters[0].EventType.Value = eventingtesting.OrderCreatedEventType + "2"
+	changedDurableName := createDurableName(changedSub, changedSub.Spec.Filter.Filters[0].EventType.Value)
+	g.Expect(natsClient.DeleteSubscription(changedSub)).Should(Succeed())
+	_, err = natsClient.SyncSubscription(changedSub, eventtype.CleanerFunc(idFunc))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	_, err = natsClient.jsContext.ConsumerInfo("kyma", durableName)
+	g.Expect(err).Should(HaveOccurred())
+	_, err = natsClient.jsContext.ConsumerInfo("kyma", changedDurableName)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	durableName = changedDurableName
+	sub = changedSub
+
+	// Deleting the subscription deletes its consumer
+	now := metav1.Now()
+	sub.DeletionTimestamp = &now
+	g.Expect(natsClient.DeleteSubscription(sub)).Should(Succeed())
+	_, err = natsClient.jsContext.ConsumerInfo("kyma", durableName)
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestPersistentSubscriptionBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	// the sink fails the first attempt only
+	var requests int64
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sink.Close()
+
+	storeDir, err := ioutil.TempDir("", "jetstream")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer func() { _ = os.RemoveAll(storeDir) }()
+	natsServer := eventingtesting.RunNatsServerWithJetStreamOnPort(5226, storeDir)
+	defer natsServer.Shutdown()
+
+	natsClient := Nats{
+		subscriptions: make(map[string]*nats.Subscription),
+		config: env.NatsConfig{
+			Url:               natsServer.ClientURL(),
+			MaxReconnects:     2,
+			ReconnectWait:     time.Second,
+			EventTypePrefix:   eventingtesting.EventTypePrefix,
+			DeliveryMode:      string(eventingv1alpha1.NatsDeliveryModeAtLeastOnce),
+			StreamName:        "kyma",
+			StreamStorageType: "memory",
+			AckWait:           time.Second,
+		},
+		log: ctrl.Log.WithName("reconciler").WithName("Subscription"),
+	}
+	g.Expect(natsClient.Initialize(env.Config{})).Should(Succeed())
+
+	// the backoff of the retry is longer than the ack wait time of the stream
+	sub := eventingtesting.NewSubscription("sub", "foo", eventingtesting.WithEventTypeFilter)
+	sub.Spec.Sink = sink.URL
+	sub.Spec.DeliveryPolicy = &eventingv1alpha1.DeliveryPolicy{
+		BackoffStrategy: eventingv1alpha1.BackoffStrategyConstant,
+		BackoffDelay:    &metav1.Duration{Duration: 2500 * time.Millisecond},
+	}
+	idFunc := func(et string) (string, error) { return et, nil }
+	_, err = natsClient.SyncSubscription(sub, eventtype.CleanerFunc(idFunc))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(SendEventToNATS(&natsClient, "sampledata")).Should(Succeed())
+
+	// the event is retried after the backoff only, and it is not redelivered by the stream meanwhile
+	g.Eventually(func() int64 { return atomic.LoadInt64(&requests) }).Should(BeEquivalentTo(1))
+	g.Consistently(func() int64 { return atomic.LoadInt64(&requests) }, 2*time.Second).Should(BeEquivalentTo(1))
+	g.Eventually(func() int64 { return atomic.LoadInt64(&requests) }, 2*time.Second).Should(BeEquivalentTo(2))
+	g.Consistently(func() int64 { return atomic.LoadInt64(&requests) }, 2*time.Second).Should(BeEquivalentTo(2))
+}
+
 func TestIsValidSubscription(t *testing.T) {
 	g := NewWithT(t)
 
@@ -291,7 +442,7 @@ func TestIsValidSubscription(t *testing.T) {
 
 	// get filter
 	filter := sub.Spec.Filter.Filters[0]
-	subject, err := createSubject(filter, cleaner)
+	subject, _, err := natsClient.createSubject(filter, cleaner)
 	g.Expect(err).ShouldNot(HaveOccurred())
 	g.Expect(subject).To(Not(BeEmpty()))
 
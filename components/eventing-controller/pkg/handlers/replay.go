@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
+)
+
+// replayPollInterval is the time a replay waits for the next event of the stream before it checks whether events
+// are still pending for its consumer
+const replayPollInterval = 5 * time.Second
+
+// replay is a running or finished redelivery of persisted events to the sink of a Kyma subscription
+type replay struct {
+	cancel context.CancelFunc
+	status eventingv1alpha1.ReplayStatus
+}
+
+// replayStart is the first event of a replay, which is either the first event at or after the given time or
+// the event with the given stream sequence number
+type replayStart struct {
+	time     time.Time
+	sequence uint64
+}
+
+// parseReplayStart parses the value of the replay annotation
+func parseReplayStart(value string) (replayStart, error) {
+	if sequence, err := strconv.ParseUint(value, 10, 64); err == nil && sequence > 0 {
+		return replayStart{sequence: sequence}, nil
+	}
+	startTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return replayStart{}, fmt.Errorf("invalid replay start %q, must be a time in RFC3339 format or a positive stream sequence number", value)
+	}
+	return replayStart{time: startTime}, nil
+}
+
+func (s replayStart) subOpt() nats.SubOpt {
+	if s.sequence > 0 {
+		return nats.StartSequence(s.sequence)
+	}
+	return nats.StartTime(s.time)
+}
+
+// StartReplay starts to redeliver the persisted events to the sink of the Kyma subscription, as requested by its
+// replay annotation, and returns the status of the replay. A running replay of the same request is kept, a running
+// replay of another request is canceled.
+func (n *Nats) StartReplay(sub *eventingv1alpha1.Subscription, cleaner eventtype.Cleaner) eventingv1alpha1.ReplayStatus {
+	namespacedName := types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name}
+	request := sub.Annotations[eventingv1alpha1.ReplayAnnotation]
+
+	n.replaysLock.Lock()
+	defer n.replaysLock.Unlock()
+	if n.replays == nil {
+		n.replays = make(map[types.NamespacedName]*replay)
+	}
+	if r, ok := n.replays[namespacedName]; ok {
+		if r.status.Request == request {
+			return r.status
+		}
+		r.cancel()
+	}
+
+	now := metav1.Now()
+	r := &replay{
+		cancel: func() {},
+		status: eventingv1alpha1.ReplayStatus{Request: request, Phase: eventingv1alpha1.ReplayPhaseRunning, StartTime: &now},
+	}
+	n.replays[namespacedName] = r
+
+	start, err := parseReplayStart(request)
+	if err == nil && !n.isPersistent() {
+		err = fmt.Errorf("replay requires the %s delivery mode", eventingv1alpha1.NatsDeliveryModeAtLeastOnce)
+	}
+	var subjects []string
+	var filtersBySubject map[string]eventFilter
+	if err == nil {
+		subjects, filtersBySubject, err = n.getSubjectFilters(sub, cleaner)
+	}
+	if err != nil {
+		r.status.Phase = eventingv1alpha1.ReplayPhaseFailed
+		r.status.CompletionTime = &now
+		r.status.Message = err.Error()
+		return r.status
+	}
+
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	policy := newDeliveryPolicy(sub, 1)
+	go func() {
+		err := n.replay(ctx, r, subjects, filtersBySubject, start, policy)
+		n.finishReplay(namespacedName, r, err)
+	}()
+	return r.status
+}
+
+// CancelReplay cancels the replay of the Kyma subscription and forgets its status
+func (n *Nats) CancelReplay(namespacedName types.NamespacedName) {
+	n.replaysLock.Lock()
+	defer n.replaysLock.Unlock()
+	if r, ok := n.replays[namespacedName]; ok {
+		r.cancel()
+		delete(n.replays, namespacedName)
+	}
+}
+
+// CollectReplayStatus returns the status of all replays. Finished replays are reported only once.
+func (n *Nats) CollectReplayStatus() map[types.NamespacedName]eventingv1alpha1.ReplayStatus {
+	n.replaysLock.Lock()
+	defer n.replaysLock.Unlock()
+	status := make(map[types.NamespacedName]eventingv1alpha1.ReplayStatus, len(n.replays))
+	for namespacedName, r := range n.replays {
+		status[namespacedName] = *r.status.DeepCopy()
+		if r.status.Phase != eventingv1alpha1.ReplayPhaseRunning {
+			delete(n.replays, namespacedName)
+		}
+	}
+	return status
+}
+
+func (n *Nats) finishReplay(namespacedName types.NamespacedName, r *replay, err error) {
+	n.replaysLock.Lock()
+	defer n.replaysLock.Unlock()
+	now := metav1.Now()
+	r.status.CompletionTime = &now
+	r.status.Phase = eventingv1alpha1.ReplayPhaseSucceeded
+	if err != nil {
+		r.status.Phase = eventingv1alpha1.ReplayPhaseFailed
+		r.status.Message = err.Error()
+	}
+	n.log.Info("replay finished", "namespace", namespacedName.Namespace, "name", namespacedName.Name,
+		"phase", r.status.Phase, "replayed", r.status.Replayed, "failed", r.status.Failed)
+}
+
+func (n *Nats) recordReplay(r *replay, err error) {
+	n.replaysLock.Lock()
+	defer n.replaysLock.Unlock()
+	if err != nil {
+		r.status.Failed++
+		return
+	}
+	r.status.Replayed++
+}
+
+// replay redelivers the events of the stream, which were persisted before the replay started, to the sink.
+// Every subject is read by an ephemeral consumer, which starts at the requested event.
+func (n *Nats) replay(ctx context.Context, r *replay, subjects []string,
+	filtersBySubject map[string]eventFilter, start replayStart, policy deliveryPolicy) error {
+	info, err := n.jsContext.StreamInfo(n.config.StreamName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the info of the NATS stream %s", n.config.StreamName)
+	}
+	// events after the last event of the stream are delivered by the subscription itself
+	lastSequence := info.State.LastSeq
+	if lastSequence == 0 || start.sequence > lastSequence {
+		return nil
+	}
+
+	for _, subject := range subjects {
+		if err := n.replaySubject(ctx, r, subject, filtersBySubject[subject], start, lastSequence, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Nats) replaySubject(ctx context.Context, r *replay, subject string, filter eventFilter, start replayStart,
+	lastSequence uint64, policy deliveryPolicy) error {
+	natsSub, err := n.jsContext.SubscribeSync(subject,
+		nats.BindStream(n.config.StreamName),
+		nats.AckNone(),
+		start.subOpt(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to replay the subject %s", subject)
+	}
+	defer func() {
+		if err := natsSub.Unsubscribe(); err != nil {
+			n.log.Error(err, "failed to unsubscribe the replay", "subject", subject)
+		}
+	}()
+
+	// the replay is complete once the consumer has no pending events anymore and all the delivered events were
+	// received, it does not rely on a timeout, since the events of a large stream may take longer to arrive
+	var received uint64
+	if done, err := replayComplete(natsSub, received); err != nil || done {
+		return err
+	}
+	for {
+		msgCtx, cancel := context.WithTimeout(ctx, replayPollInterval)
+		msg, err := natsSub.NextMsgWithContext(msgCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return errors.New("replay canceled")
+			}
+			if done, err := replayComplete(natsSub, received); err != nil || done {
+				return err
+			}
+			continue
+		}
+
+		metadata, err := msg.Metadata()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the metadata of the Nats message")
+		}
+		received = metadata.Sequence.Consumer
+		if metadata.Sequence.Stream > lastSequence {
+			return nil
+		}
+
+		ce, err := convertMsgToCE(msg)
+		if err != nil {
+			n.log.Error(err, "failed to convert Nats message to CE")
+		} else if filter.matches(ce) {
+			result := send(ctx, n.client, *ce, policy.sink)
+			if result.err != nil {
+				n.log.Error(result.err, "failed to replay event", "id", ce.ID())
+			}
+			n.recordReplay(r, result.err)
+		}
+
+		if metadata.NumPending == 0 || metadata.Sequence.Stream == lastSequence {
+			return nil
+		}
+	}
+}
+
+// replayComplete returns true if the stream has no events pending for the consumer of the subscription and the
+// subscription received all the events delivered to the consumer, received is the consumer sequence of the last
+// received event
+func replayComplete(natsSub *nats.Subscription, received uint64) (bool, error) {
+	info, err := natsSub.ConsumerInfo()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get the info of the replay consumer")
+	}
+	return info.NumPending == 0 && info.Delivered.Consumer <= received, nil
+}
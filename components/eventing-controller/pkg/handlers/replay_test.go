@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
+	eventingtesting "github.com/kyma-project/kyma/components/eventing-controller/testing"
+)
+
+func TestReplay(t *testing.T) {
+	g := NewWithT(t)
+
+	// Start NATS server with JetStream
+	storeDir, err := ioutil.TempDir("", "jetstream")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer func() { _ = os.RemoveAll(storeDir) }()
+	natsServer := eventingtesting.RunNatsServerWithJetStreamOnPort(5225, storeDir)
+	defer natsServer.Shutdown()
+
+	var received int64
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&received, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sink.Close()
+
+	natsClient := Nats{
+		subscriptions: make(map[string]*nats.Subscription),
+		config: env.NatsConfig{
+			Url:               natsServer.ClientURL(),
+			MaxReconnects:     2,
+			ReconnectWait:     time.Second,
+			EventTypePrefix:   eventingtesting.EventTypePrefix,
+			DeliveryMode:      string(eventingv1alpha1.NatsDeliveryModeAtLeastOnce),
+			StreamName:        "kyma",
+			StreamStorageType: "memory",
+			AckWait:           time.Second,
+		},
+		log: ctrl.Log.WithName("reconciler").WithName("Subscription"),
+	}
+	g.Expect(natsClient.Initialize(env.Config{})).Should(Succeed())
+
+	sub := eventingtesting.NewSubscription("sub", "foo", eventingtesting.WithEventTypeFilter)
+	sub.Spec.Sink = sink.URL
+	// the subject of the second filter has no events, which must not delay the completion of the replay
+	noEventsFilter := sub.Spec.Filter.Filters[0].DeepCopy()
+	noEventsFilter.EventType.Value = eventingtesting.OrderCreatedEventType + "2"
+	sub.Spec.Filter.Filters = append(sub.Spec.Filter.Filters, noEventsFilter)
+	cleaner := eventtype.CleanerFunc(func(et string) (string, error) { return et, nil })
+	_, err = natsClient.SyncSubscription(sub, cleaner)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	// The events are delivered once by the subscription
+	for i := 0; i < 3; i++ {
+		g.Expect(SendEventToNATS(&natsClient, fmt.Sprintf("data%d", i))).Should(Succeed())
+	}
+	g.Eventually(func() int64 { return atomic.LoadInt64(&received) }, 10*time.Second).Should(BeEquivalentTo(3))
+
+	// The replay redelivers the events starting at the second one
+	sub.Annotations = map[string]string{eventingv1alpha1.ReplayAnnotation: "2"}
+	status := natsClient.StartReplay(sub, cleaner)
+	g.Expect(status.Phase).Should(Equal(eventingv1alpha1.ReplayPhaseRunning))
+	g.Expect(status.Request).Should(Equal("2"))
+
+	namespacedName := types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name}
+	var finalStatus eventingv1alpha1.ReplayStatus
+	g.Eventually(func() eventingv1alpha1.ReplayPhase {
+		finalStatus = natsClient.CollectReplayStatus()[namespacedName]
+		return finalStatus.Phase
+	}, replayPollInterval/2).Should(Equal(eventingv1alpha1.ReplayPhaseSucceeded))
+	g.Expect(finalStatus.Replayed).Should(BeEquivalentTo(2))
+	g.Expect(finalStatus.Failed).Should(BeZero())
+	g.Expect(finalStatus.CompletionTime).ShouldNot(BeNil())
+	g.Expect(atomic.LoadInt64(&received)).Should(BeEquivalentTo(5))
+
+	// Finished replays are reported once
+	g.Expect(natsClient.CollectReplayStatus()).Should(BeEmpty())
+}
+
+func TestReplayWithoutStream(t *testing.T) {
+	g := NewWithT(t)
+
+	natsClient := Nats{config: env.NatsConfig{DeliveryMode: string(eventingv1alpha1.NatsDeliveryModeAtMostOnce)}}
+	sub := eventingtesting.NewSubscription("sub", "foo", eventingtesting.WithEventTypeFilter)
+	cleaner := eventtype.CleanerFunc(func(et string) (string, error) { return et, nil })
+
+	sub.Annotations = map[string]string{eventingv1alpha1.ReplayAnnotation: "2021-06-01T10:00:00Z"}
+	status := natsClient.StartReplay(sub, cleaner)
+	g.Expect(status.Phase).Should(Equal(eventingv1alpha1.ReplayPhaseFailed))
+	g.Expect(status.Message).Should(ContainSubstring("AtLeastOnce"))
+}
+
+func TestParseReplayStart(t *testing.T) {
+	g := NewWithT(t)
+
+	start, err := parseReplayStart("42")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(start.sequence).Should(BeEquivalentTo(42))
+
+	start, err = parseReplayStart("2021-06-01T10:00:00Z")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(start.time).Should(Equal(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)))
+
+	for _, invalid := range []string{"", "0", "-1", "yesterday"} {
+		_, err = parseReplayStart(invalid)
+		g.Expect(err).Should(HaveOccurred(), invalid)
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	deliveredEventsMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eventing_nats_subscription_delivered_events_total",
+			Help: "The number of events delivered to the sink of a subscription",
+		},
+		[]string{"namespace", "name"},
+	)
+	failedDeliveriesMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eventing_nats_subscription_failed_deliveries_total",
+			Help: "The number of failed attempts to deliver an event to the sink of a subscription",
+		},
+		[]string{"namespace", "name"},
+	)
+	lastSuccessfulDeliveryMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eventing_nats_subscription_last_successful_delivery_timestamp_seconds",
+			Help: "The time of the last successful delivery of an event to the sink of a subscription",
+		},
+		[]string{"namespace", "name"},
+	)
+	lastFailedDeliveryMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eventing_nats_subscription_last_failed_delivery_timestamp_seconds",
+			Help: "The time of the last failed attempt to deliver an event to the sink of a subscription",
+		},
+		[]string{"namespace", "name"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(deliveredEventsMetric, failedDeliveriesMetric, lastSuccessfulDeliveryMetric,
+		lastFailedDeliveryMetric)
+}
+
+// DeliveryStats are the outcomes of the event deliveries of a subscription since they were collected the last time
+type DeliveryStats struct {
+	// Delivered is the number of events delivered to the sink
+	Delivered int64
+	// Failed is the number of failed attempts to deliver an event to the sink
+	Failed                   int64
+	LastSuccessfulDelivery   time.Time
+	LastFailedDelivery       time.Time
+	LastFailedDeliveryReason string
+}
+
+// Add merges the given, more recent stats into the stats
+func (s *DeliveryStats) Add(other DeliveryStats) {
+	s.Delivered += other.Delivered
+	s.Failed += other.Failed
+	if !other.LastSuccessfulDelivery.IsZero() {
+		s.LastSuccessfulDelivery = other.LastSuccessfulDelivery
+	}
+	if !other.LastFailedDelivery.IsZero() {
+		s.LastFailedDelivery = other.LastFailedDelivery
+		s.LastFailedDeliveryReason = other.LastFailedDeliveryReason
+	}
+}
+
+// recordDelivery adds the outcome of a delivery to the stats and the metrics of the subscription
+func (n *Nats) recordDelivery(subscription types.NamespacedName, delivery deliveryResult) {
+	now := time.Now()
+	outcome := DeliveryStats{}
+	if delivery.err == nil {
+		// all attempts before the successful one failed
+		outcome.Delivered = 1
+		outcome.Failed = int64(delivery.attempts - 1)
+		outcome.LastSuccessfulDelivery = now
+	} else {
+		outcome.Failed = int64(delivery.attempts)
+		outcome.LastFailedDelivery = now
+		outcome.LastFailedDeliveryReason = delivery.err.Error()
+	}
+
+	labels := prometheus.Labels{"namespace": subscription.Namespace, "name": subscription.Name}
+	deliveredEventsMetric.With(labels).Add(float64(outcome.Delivered))
+	failedDeliveriesMetric.With(labels).Add(float64(outcome.Failed))
+	if delivery.err == nil {
+		lastSuccessfulDeliveryMetric.With(labels).Set(float64(now.Unix()))
+	} else {
+		lastFailedDeliveryMetric.With(labels).Set(float64(now.Unix()))
+	}
+
+	n.statsLock.Lock()
+	defer n.statsLock.Unlock()
+	if n.stats == nil {
+		n.stats = make(map[types.NamespacedName]*DeliveryStats)
+	}
+	stats, ok := n.stats[subscription]
+	if !ok {
+		stats = &DeliveryStats{}
+		n.stats[subscription] = stats
+	}
+	stats.Add(outcome)
+}
+
+// CollectDeliveryStats returns the delivery stats of all subscriptions with deliveries since the last collection
+// and resets them
+func (n *Nats) CollectDeliveryStats() map[types.NamespacedName]DeliveryStats {
+	n.statsLock.Lock()
+	defer n.statsLock.Unlock()
+	collected := make(map[types.NamespacedName]DeliveryStats, len(n.stats))
+	for subscription, stats := range n.stats {
+		collected[subscription] = *stats
+	}
+	n.stats = nil
+	return collected
+}
+
+// DeleteDeliveryMetrics removes the metrics of a deleted subscription
+func DeleteDeliveryMetrics(subscription types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": subscription.Namespace, "name": subscription.Name}
+	deliveredEventsMetric.Delete(labels)
+	failedDeliveriesMetric.Delete(labels)
+	lastSuccessfulDeliveryMetric.Delete(labels)
+	lastFailedDeliveryMetric.Delete(labels)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCollectDeliveryStats(t *testing.T) {
+	g := NewWithT(t)
+
+	natsBackend := Nats{}
+	subscription := types.NamespacedName{Namespace: "stats", Name: "sub"}
+	otherSubscription := types.NamespacedName{Namespace: "stats", Name: "other-sub"}
+	defer DeleteDeliveryMetrics(subscription)
+	defer DeleteDeliveryMetrics(otherSubscription)
+
+	natsBackend.recordDelivery(subscription, deliveryResult{attempts: 3})
+	natsBackend.recordDelivery(subscription, deliveryResult{attempts: 2, statusCode: 500, err: errors.New("failed")})
+	natsBackend.recordDelivery(otherSubscription, deliveryResult{attempts: 1})
+
+	stats := natsBackend.CollectDeliveryStats()
+	g.Expect(stats).To(HaveLen(2))
+	g.Expect(stats[subscription].Delivered).To(BeEquivalentTo(1))
+	g.Expect(stats[subscription].Failed).To(BeEquivalentTo(4))
+	g.Expect(stats[subscription].LastSuccessfulDelivery).To(BeTemporally("~", time.Now(), time.Second))
+	g.Expect(stats[subscription].LastFailedDelivery).To(BeTemporally("~", time.Now(), time.Second))
+	g.Expect(stats[subscription].LastFailedDeliveryReason).To(Equal("failed"))
+	g.Expect(stats[otherSubscription].Delivered).To(BeEquivalentTo(1))
+	g.Expect(stats[otherSubscription].Failed).To(BeZero())
+	g.Expect(stats[otherSubscription].LastFailedDelivery.IsZero()).To(BeTrue())
+
+	// the stats are reset by the collection, the metrics are cumulative
+	g.Expect(natsBackend.CollectDeliveryStats()).To(BeEmpty())
+	g.Expect(testutil.ToFloat64(deliveredEventsMetric.WithLabelValues("stats", "sub"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(failedDeliveriesMetric.WithLabelValues("stats", "sub"))).To(Equal(4.0))
+}
+
+func TestDeliveryStatsAdd(t *testing.T) {
+	g := NewWithT(t)
+
+	failure := time.Now()
+	stats := DeliveryStats{Delivered: 1, Failed: 1, LastFailedDelivery: failure, LastFailedDeliveryReason: "failed"}
+	success := failure.Add(time.Second)
+	stats.Add(DeliveryStats{Delivered: 2, LastSuccessfulDelivery: success})
+
+	g.Expect(stats).To(Equal(DeliveryStats{
+		Delivered:                3,
+		Failed:                   1,
+		LastSuccessfulDelivery:   success,
+		LastFailedDelivery:       failure,
+		LastFailedDeliveryReason: "failed",
+	}))
+}
@@ -77,7 +77,59 @@ func getQos(qosStr string) (types.Qos, error) {
 	}
 }
 
+// getQosForMaxAttempts returns the Qos which matches the given number of delivery attempts the best
+func getQosForMaxAttempts(maxAttempts int) types.Qos {
+	if maxAttempts <= 1 {
+		return types.QosAtMostOnce
+	}
+	return types.QosAtLeastOnce
+}
+
+// UnsupportedSpecError is returned for a subscription whose spec sets fields which the backend does not support.
+// Such a subscription is not synced, since ignoring the fields would change its filters or delivery silently.
+type UnsupportedSpecError struct {
+	Fields []string
+}
+
+func (e *UnsupportedSpecError) Error() string {
+	return fmt.Sprintf("fields not supported by BEB: %s", strings.Join(e.Fields, ", "))
+}
+
+// getUnsupportedBEBFields returns the fields of the subscription spec which BEB does not support
+func getUnsupportedBEBFields(subscription *eventingv1alpha1.Subscription) []string {
+	var fields []string
+	if policy := subscription.Spec.DeliveryPolicy; policy != nil {
+		if len(policy.BackoffStrategy) > 0 {
+			fields = append(fields, "spec.deliveryPolicy.backoffStrategy")
+		}
+		if policy.BackoffDelay != nil {
+			fields = append(fields, "spec.deliveryPolicy.backoffDelay")
+		}
+		if policy.MaxBackoff != nil {
+			fields = append(fields, "spec.deliveryPolicy.maxBackoff")
+		}
+		if len(policy.DeadLetterSink) > 0 {
+			fields = append(fields, "spec.deliveryPolicy.deadLetterSink")
+		}
+	}
+	if filter := subscription.Spec.Filter; filter != nil {
+		for i, f := range filter.Filters {
+			if f != nil && len(f.Extensions) > 0 {
+				fields = append(fields, fmt.Sprintf("spec.filter.filters[%d].extensions", i))
+			}
+		}
+		if len(filter.Expression) > 0 {
+			fields = append(fields, "spec.filter.expression")
+		}
+	}
+	return fields
+}
+
 func getInternalView4Ev2(subscription *eventingv1alpha1.Subscription, apiRule *apigatewayv1alpha1.APIRule, defaultWebhookAuth *types.WebhookAuth, defaultProtocolSettings *eventingv1alpha1.ProtocolSettings, defaultNamespace string) (*types.Subscription, error) {
+	if fields := getUnsupportedBEBFields(subscription); len(fields) > 0 {
+		return nil, &UnsupportedSpecError{Fields: fields}
+	}
+
 	emsSubscription, err := getDefaultSubscription(defaultProtocolSettings)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to apply default protocol settings")
@@ -85,6 +137,11 @@ func getInternalView4Ev2(subscription *eventingv1alpha1.Subscription, apiRule *a
 	// Name
 	emsSubscription.Name = subscription.Name
 
+	// Qos derived from the max attempts of the delivery policy, the other fields of the policy are rejected above
+	if subscription.Spec.DeliveryPolicy != nil && subscription.Spec.DeliveryPolicy.MaxAttempts != nil {
+		emsSubscription.Qos = getQosForMaxAttempts(*subscription.Spec.DeliveryPolicy.MaxAttempts)
+	}
+
 	// Applying protocol settings if provided in subscription CR
 	if subscription.Spec.ProtocolSettings != nil {
 		if subscription.Spec.ProtocolSettings.ContentMode != nil {
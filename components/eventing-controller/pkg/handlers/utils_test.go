@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
 	"github.com/kyma-project/kyma/components/eventing-controller/pkg/ems/api/events/types"
@@ -145,6 +148,55 @@ func TestGetInternalView4Ev2(t *testing.T) {
 		g.Expect(err).To(BeNil())
 		g.Expect(expectedBEBSubscription).To(Equal(*gotBEBSubscription))
 	})
+
+	t.Run("subscription with delivery policy", func(t *testing.T) {
+		// given
+		subscription := reconcilertesting.NewSubscription("name", "namespace", eventingtesting.WithEventTypeFilter)
+		eventingtesting.WithValidSink("ns", svcName, subscription)
+		apiRule := reconcilertesting.NewAPIRule(subscription, reconcilertesting.WithPath)
+		reconcilertesting.WithService(host, svcName, apiRule)
+
+		// A single attempt means no redelivery
+		maxAttempts := 1
+		subscription.Spec.DeliveryPolicy = &eventingv1alpha1.DeliveryPolicy{MaxAttempts: &maxAttempts}
+		gotBEBSubscription, err := getInternalView4Ev2(subscription, apiRule, defaultWebhookAuth, defaultProtocolSettings, defaultNamespace)
+		g.Expect(err).To(BeNil())
+		g.Expect(gotBEBSubscription.Qos).To(Equal(types.QosAtMostOnce))
+
+		// The Qos of the protocol settings takes precedence
+		qos := string(types.QosAtLeastOnce)
+		subscription.Spec.ProtocolSettings = &eventingv1alpha1.ProtocolSettings{Qos: &qos}
+		gotBEBSubscription, err = getInternalView4Ev2(subscription, apiRule, defaultWebhookAuth, defaultProtocolSettings, defaultNamespace)
+		g.Expect(err).To(BeNil())
+		g.Expect(gotBEBSubscription.Qos).To(Equal(types.QosAtLeastOnce))
+	})
+
+	t.Run("subscription with fields not supported by BEB", func(t *testing.T) {
+		// given
+		subscription := reconcilertesting.NewSubscription("name", "namespace", eventingtesting.WithEventTypeFilter)
+		eventingtesting.WithValidSink("ns", svcName, subscription)
+		apiRule := reconcilertesting.NewAPIRule(subscription, reconcilertesting.WithPath)
+		reconcilertesting.WithService(host, svcName, apiRule)
+		subscription.Spec.DeliveryPolicy = &eventingv1alpha1.DeliveryPolicy{
+			DeadLetterSink: "http://dead-letter",
+			BackoffDelay:   &metav1.Duration{Duration: time.Second},
+		}
+		subscription.Spec.Filter.Filters[0].Extensions = []*eventingv1alpha1.Filter{{Type: "exact", Property: "foo", Value: "bar"}}
+		subscription.Spec.Filter.Expression = "foo = 'bar'"
+
+		// when
+		_, err := getInternalView4Ev2(subscription, apiRule, defaultWebhookAuth, defaultProtocolSettings, defaultNamespace)
+
+		// then
+		var unsupported *UnsupportedSpecError
+		g.Expect(errors.As(err, &unsupported)).To(BeTrue())
+		g.Expect(unsupported.Fields).To(Equal([]string{
+			"spec.deliveryPolicy.backoffDelay",
+			"spec.deliveryPolicy.deadLetterSink",
+			"spec.filter.filters[0].extensions",
+			"spec.filter.expression",
+		}))
+	})
 }
 
 func TestGetInternalView4Ems(t *testing.T) {
@@ -0,0 +1,56 @@
+// Package kafka connects to the brokers of a Kafka cluster with the clients of sarama.
+package kafka
+
+import (
+	"github.com/Shopify/sarama"
+
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+)
+
+// compile time check
+var _ Cluster = &brokerCluster{}
+
+// Cluster creates the clients of a Kafka cluster
+type Cluster interface {
+	// NewClient returns a new client of the cluster
+	NewClient() (sarama.Client, error)
+	// NewClusterAdmin returns a new admin client of the cluster
+	NewClusterAdmin() (sarama.ClusterAdmin, error)
+	// NewConsumerGroup returns a new member of the consumer group with the given name
+	NewConsumerGroup(group string) (sarama.ConsumerGroup, error)
+}
+
+// brokerCluster is a Kafka cluster which is reached by its bootstrap brokers
+type brokerCluster struct {
+	brokers []string
+	config  *sarama.Config
+}
+
+// NewCluster returns a new Cluster which bootstraps from the brokers of the config. The consumer groups mark the
+// offsets of the consumed events, which are committed periodically and when they are closed. A consumer group which
+// has no valid offset, because the events were removed by the retention of the topic, continues with the earliest
+// event.
+func NewCluster(cfg env.KafkaConfig) Cluster {
+	config := sarama.NewConfig()
+	config.ClientID = cfg.ClientID
+	config.Version = sarama.V2_0_0_0
+	config.Consumer.MaxWaitTime = cfg.FetchMaxWait
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	return &brokerCluster{brokers: cfg.Brokers, config: config}
+}
+
+// NewClient implements the Cluster interface
+func (c *brokerCluster) NewClient() (sarama.Client, error) {
+	return sarama.NewClient(c.brokers, c.config)
+}
+
+// NewClusterAdmin implements the Cluster interface
+func (c *brokerCluster) NewClusterAdmin() (sarama.ClusterAdmin, error) {
+	return sarama.NewClusterAdmin(c.brokers, c.config)
+}
+
+// NewConsumerGroup implements the Cluster interface
+func (c *brokerCluster) NewConsumerGroup(group string) (sarama.ConsumerGroup, error) {
+	return sarama.NewConsumerGroup(c.brokers, group, c.config)
+}
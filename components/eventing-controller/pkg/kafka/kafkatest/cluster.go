@@ -0,0 +1,314 @@
+// Package kafkatest provides an in-memory stand-in for a Kafka cluster, which implements the parts of the sarama
+// clients that the Eventing Controller uses.
+package kafkatest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/kafka"
+)
+
+// compile time check
+var _ kafka.Cluster = &Cluster{}
+
+type groupPartition struct {
+	group     string
+	topic     string
+	partition int32
+}
+
+// Cluster keeps its topics and the offsets of the consumer groups in memory. A consumer group has a single member,
+// which claims all partitions of the topics, and the offsets marked by a consumer group are committed right away.
+type Cluster struct {
+	lock    sync.Mutex
+	topics  map[string][][]*sarama.ConsumerMessage
+	offsets map[groupPartition]int64
+	groups  map[string]bool
+	// appended is closed and replaced when records are appended, so that the consumers can wait for new records
+	appended chan struct{}
+}
+
+// NewCluster returns a new Cluster without topics
+func NewCluster() *Cluster {
+	return &Cluster{
+		topics:   make(map[string][][]*sarama.ConsumerMessage),
+		offsets:  make(map[groupPartition]int64),
+		groups:   make(map[string]bool),
+		appended: make(chan struct{}),
+	}
+}
+
+// CreateTopic creates a topic with the given number of partitions, an existing topic is kept
+func (c *Cluster) CreateTopic(topic string, partitions int) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.topics[topic]; ok {
+		return false
+	}
+	c.topics[topic] = make([][]*sarama.ConsumerMessage, partitions)
+	return true
+}
+
+// Produce appends a record to a partition and returns its offset
+func (c *Cluster) Produce(topic string, partition int32, value []byte) int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	offset := int64(len(c.topics[topic][partition]))
+	c.topics[topic][partition] = append(c.topics[topic][partition], &sarama.ConsumerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Offset:    offset,
+		Value:     value,
+	})
+	close(c.appended)
+	c.appended = make(chan struct{})
+	return offset
+}
+
+// CommittedOffset returns the offset of a partition which the consumer group committed
+func (c *Cluster) CommittedOffset(group, topic string, partition int32) (int64, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	offset, ok := c.offsets[groupPartition{group: group, topic: topic, partition: partition}]
+	return offset, ok
+}
+
+// HasGroup returns true if the consumer group exists
+func (c *Cluster) HasGroup(group string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.groups[group]
+}
+
+// NewClient implements the kafka.Cluster interface
+func (c *Cluster) NewClient() (sarama.Client, error) {
+	return &client{cluster: c}, nil
+}
+
+// NewClusterAdmin implements the kafka.Cluster interface
+func (c *Cluster) NewClusterAdmin() (sarama.ClusterAdmin, error) {
+	return &clusterAdmin{cluster: c}, nil
+}
+
+// NewConsumerGroup implements the kafka.Cluster interface
+func (c *Cluster) NewConsumerGroup(group string) (sarama.ConsumerGroup, error) {
+	return &consumerGroup{cluster: c, group: group, errors: make(chan error)}, nil
+}
+
+// records returns the records of a partition starting at the given offset and a channel which is closed once
+// more records are appended
+func (c *Cluster) records(topic string, partition int32, offset int64) ([]*sarama.ConsumerMessage, <-chan struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	records := c.topics[topic][partition]
+	if offset >= int64(len(records)) {
+		return nil, c.appended
+	}
+	return records[offset:], c.appended
+}
+
+func (c *Cluster) commit(group, topic string, partition int32, offset int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.offsets[groupPartition{group: group, topic: topic, partition: partition}] = offset
+}
+
+// client implements the offset lookup of sarama.Client, the other methods must not be called
+type client struct {
+	sarama.Client
+	cluster *Cluster
+}
+
+func (c *client) GetOffset(topic string, partition int32, time int64) (int64, error) {
+	c.cluster.lock.Lock()
+	defer c.cluster.lock.Unlock()
+	partitions, ok := c.cluster.topics[topic]
+	if !ok || partition < 0 || int(partition) >= len(partitions) {
+		return 0, sarama.ErrUnknownTopicOrPartition
+	}
+	if time == sarama.OffsetOldest {
+		return 0, nil
+	}
+	return int64(len(partitions[partition])), nil
+}
+
+func (c *client) Close() error {
+	return nil
+}
+
+// clusterAdmin implements the topic and consumer group methods of sarama.ClusterAdmin, the other methods must not
+// be called
+type clusterAdmin struct {
+	sarama.ClusterAdmin
+	cluster *Cluster
+}
+
+func (a *clusterAdmin) CreateTopic(topic string, detail *sarama.TopicDetail, _ bool) error {
+	if !a.cluster.CreateTopic(topic, int(detail.NumPartitions)) {
+		return &sarama.TopicError{Err: sarama.ErrTopicAlreadyExists}
+	}
+	return nil
+}
+
+func (a *clusterAdmin) ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*sarama.OffsetFetchResponse, error) {
+	resp := &sarama.OffsetFetchResponse{}
+	for topic, partitions := range topicPartitions {
+		for _, partition := range partitions {
+			offset, ok := a.cluster.CommittedOffset(group, topic, partition)
+			if !ok {
+				offset = -1
+			}
+			resp.AddBlock(topic, partition, &sarama.OffsetFetchResponseBlock{Offset: offset})
+		}
+	}
+	return resp, nil
+}
+
+func (a *clusterAdmin) DeleteConsumerGroup(group string) error {
+	a.cluster.lock.Lock()
+	defer a.cluster.lock.Unlock()
+	if !a.cluster.groups[group] {
+		return sarama.ErrGroupIDNotFound
+	}
+	delete(a.cluster.groups, group)
+	for gp := range a.cluster.offsets {
+		if gp.group == group {
+			delete(a.cluster.offsets, gp)
+		}
+	}
+	return nil
+}
+
+func (a *clusterAdmin) Close() error {
+	return nil
+}
+
+// consumerGroup is the only member of a consumer group, a session lasts until the context of Consume is done
+type consumerGroup struct {
+	cluster   *Cluster
+	group     string
+	errors    chan error
+	closeOnce sync.Once
+}
+
+func (g *consumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	claims := make(map[string][]int32)
+	g.cluster.lock.Lock()
+	for _, topic := range topics {
+		partitions, ok := g.cluster.topics[topic]
+		if !ok {
+			g.cluster.lock.Unlock()
+			return sarama.ErrUnknownTopicOrPartition
+		}
+		for partition := range partitions {
+			claims[topic] = append(claims[topic], int32(partition))
+		}
+	}
+	g.cluster.groups[g.group] = true
+	g.cluster.lock.Unlock()
+
+	sess := &session{ctx: ctx, group: g, claims: claims}
+	if err := handler.Setup(sess); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for topic, partitions := range claims {
+		for _, partition := range partitions {
+			c := &claim{topic: topic, partition: partition, offset: sarama.OffsetOldest, messages: make(chan *sarama.ConsumerMessage)}
+			if offset, ok := g.cluster.CommittedOffset(g.group, topic, partition); ok {
+				c.offset = offset
+			}
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				c.feed(ctx, g.cluster)
+			}()
+			go func() {
+				defer wg.Done()
+				_ = handler.ConsumeClaim(sess, c)
+			}()
+		}
+	}
+	wg.Wait()
+	return handler.Cleanup(sess)
+}
+
+func (g *consumerGroup) Errors() <-chan error {
+	return g.errors
+}
+
+func (g *consumerGroup) Close() error {
+	g.closeOnce.Do(func() { close(g.errors) })
+	return nil
+}
+
+type session struct {
+	ctx    context.Context
+	group  *consumerGroup
+	claims map[string][]int32
+}
+
+func (s *session) Claims() map[string][]int32 { return s.claims }
+func (s *session) MemberID() string           { return s.group.group }
+func (s *session) GenerationID() int32        { return 1 }
+func (s *session) Commit()                    {}
+func (s *session) Context() context.Context   { return s.ctx }
+
+func (s *session) MarkOffset(topic string, partition int32, offset int64, _ string) {
+	if committed, ok := s.group.cluster.CommittedOffset(s.group.group, topic, partition); !ok || offset > committed {
+		s.group.cluster.commit(s.group.group, topic, partition, offset)
+	}
+}
+
+func (s *session) ResetOffset(topic string, partition int32, offset int64, _ string) {
+	s.group.cluster.commit(s.group.group, topic, partition, offset)
+}
+
+func (s *session) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+type claim struct {
+	topic     string
+	partition int32
+	offset    int64
+	messages  chan *sarama.ConsumerMessage
+}
+
+func (c *claim) Topic() string              { return c.topic }
+func (c *claim) Partition() int32           { return c.partition }
+func (c *claim) InitialOffset() int64       { return c.offset }
+func (c *claim) HighWaterMarkOffset() int64 { return 0 }
+
+func (c *claim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+// feed sends the records of the partition to the messages channel until the context is done
+func (c *claim) feed(ctx context.Context, cluster *Cluster) {
+	defer close(c.messages)
+	offset := c.offset
+	if offset < 0 {
+		offset = 0
+	}
+	for {
+		records, appended := cluster.records(c.topic, c.partition, offset)
+		for _, record := range records {
+			select {
+			case c.messages <- record:
+				offset = record.Offset + 1
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(records) > 0 {
+			continue
+		}
+		select {
+		case <-appended:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -292,7 +292,7 @@ func TestPublisherProxyDeploymentEqual(t *testing.T) {
 		LimitsCPU:      "64m",
 		LimitsMemory:   "128Mi",
 	}
-	defaultNATSPublisher := deployment.NewNATSPublisherDeployment(publisherCfg)
+	defaultNATSPublisher := deployment.NewNATSPublisherDeployment(publisherCfg, "AtMostOnce")
 	defaultBEBPublisher := deployment.NewBEBPublisherDeployment(publisherCfg)
 
 	testCases := map[string]struct {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
 	"github.com/pkg/errors"
@@ -36,6 +37,12 @@ import (
 const (
 	BEBBackendSecretLabelKey   = "kyma-project.io/eventing-backend"
 	BEBBackendSecretLabelValue = "beb"
+	// KafkaBackendSecretLabelValue labels the secret which selects the Kafka backend, it uses the same label key as BEB
+	KafkaBackendSecretLabelValue = "kafka"
+	// KafkaSecretBrokersKey is the key of the comma-separated list of brokers in the Kafka backend secret
+	KafkaSecretBrokersKey = "brokers"
+	// KafkaSecretTopicKey is the key of the optional topic in the Kafka backend secret
+	KafkaSecretTopicKey = "topic"
 
 	BackendCRLabelKey   = "kyma-project.io/eventing"
 	BackendCRLabelValue = "backend"
@@ -52,11 +59,16 @@ const (
 )
 
 type Reconciler struct {
-	ctx                  context.Context
-	natsCommander        commander.Commander
-	natsCommanderStarted bool
-	bebCommander         commander.Commander
-	bebCommanderStarted  bool
+	ctx                   context.Context
+	natsCommander         commander.Commander
+	natsCommanderStarted  bool
+	natsDeliveryMode      eventingv1alpha1.NatsDeliveryMode
+	bebCommander          commander.Commander
+	bebCommanderStarted   bool
+	kafkaCommander        commander.Commander
+	kafkaCommanderStarted bool
+	kafkaBrokers          string
+	kafkaTopic            string
 	client.Client
 	// TODO: Do we need to explicitly pass and use a cache here? The default client that we get from manager
 	//  already uses a cache internally (check manager.DefaultNewClient)
@@ -69,17 +81,18 @@ type Reconciler struct {
 	backendType eventingv1alpha1.BackendType
 }
 
-func NewReconciler(ctx context.Context, natsCommander, bebCommander commander.Commander, client client.Client, cache cache.Cache, logger *logger.Logger, recorder record.EventRecorder) *Reconciler {
+func NewReconciler(ctx context.Context, natsCommander, bebCommander, kafkaCommander commander.Commander, client client.Client, cache cache.Cache, logger *logger.Logger, recorder record.EventRecorder) *Reconciler {
 	cfg := env.GetBackendConfig()
 	return &Reconciler{
-		ctx:           ctx,
-		natsCommander: natsCommander,
-		bebCommander:  bebCommander,
-		Client:        client,
-		Cache:         cache,
-		logger:        logger,
-		record:        recorder,
-		cfg:           cfg,
+		ctx:            ctx,
+		natsCommander:  natsCommander,
+		bebCommander:   bebCommander,
+		kafkaCommander: kafkaCommander,
+		Client:         client,
+		Cache:          cache,
+		logger:         logger,
+		record:         recorder,
+		cfg:            cfg,
 	}
 }
 
@@ -99,30 +112,18 @@ func (r *Reconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result
 	}
 	r.namedLogger().Debugw("found secrets with BEB label", "count", len(secretList.Items))
 
-	if len(secretList.Items) > 1 {
+	var kafkaSecretList v1.SecretList
+	if err := r.Cache.List(ctx, &kafkaSecretList, client.MatchingLabels{
+		BEBBackendSecretLabelKey: KafkaBackendSecretLabelValue,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.namedLogger().Debugw("found secrets with Kafka label", "count", len(kafkaSecretList.Items))
+
+	if len(secretList.Items)+len(kafkaSecretList.Items) > 1 {
 		// This is not allowed!
-		r.namedLogger().Debugw("more than one secret with the label exist", "key", BEBBackendSecretLabelKey, "value", BEBBackendSecretLabelValue)
-		currentBackend, err := r.getCurrentBackendCR(ctx)
-		if err != nil {
-			if k8serrors.IsNotFound(err) {
-				return ctrl.Result{}, nil
-			}
-			return ctrl.Result{}, err
-		}
-		desiredBackend := currentBackend.DeepCopy()
-		desiredBackend.Status = getDefaultBackendStatus()
-		// Do not change the value of backend type if cannot change it
-		desiredBackend.Status.Backend = currentBackend.Status.Backend
-		desiredBackend.Status.EventingReady = utils.BoolPtr(false)
-		if object.Semantic.DeepEqual(&desiredBackend.Status, &currentBackend.Status) {
-			r.namedLogger().Debug("no need to update backend CR status")
-			return ctrl.Result{}, nil
-		}
-		if err := r.Client.Status().Update(ctx, desiredBackend); err != nil {
-			r.namedLogger().Errorw("error updating EventingBackend status", "error", err)
-			return ctrl.Result{}, err
-		}
-		return ctrl.Result{}, nil
+		r.namedLogger().Debugw("more than one secret with the label exist", "key", BEBBackendSecretLabelKey)
+		return r.markBackendNotReady(ctx)
 	}
 
 	// If secret with label then BEB flow
@@ -130,10 +131,41 @@ func (r *Reconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result
 		return r.reconcileBEBBackend(ctx, &secretList.Items[0])
 	}
 
+	// If secret with Kafka label then Kafka flow
+	if len(kafkaSecretList.Items) == 1 {
+		return r.reconcileKafkaBackend(ctx, &kafkaSecretList.Items[0])
+	}
+
 	// Default: NATS flow
 	return r.reconcileNATSBackend(ctx)
 }
 
+// markBackendNotReady marks the EventingBackend as not ready without changing its backend type, it is used if the
+// backend cannot be chosen unambiguously
+func (r *Reconciler) markBackendNotReady(ctx context.Context) (ctrl.Result, error) {
+	currentBackend, err := r.getCurrentBackendCR(ctx)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	desiredBackend := currentBackend.DeepCopy()
+	desiredBackend.Status = getDefaultBackendStatus()
+	// Do not change the value of backend type if cannot change it
+	desiredBackend.Status.Backend = currentBackend.Status.Backend
+	desiredBackend.Status.EventingReady = utils.BoolPtr(false)
+	if object.Semantic.DeepEqual(&desiredBackend.Status, &currentBackend.Status) {
+		r.namedLogger().Debug("no need to update backend CR status")
+		return ctrl.Result{}, nil
+	}
+	if err := r.Client.Status().Update(ctx, desiredBackend); err != nil {
+		r.namedLogger().Errorw("error updating EventingBackend status", "error", err)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
 func (r *Reconciler) reconcileNATSBackend(ctx context.Context) (ctrl.Result, error) {
 	// CreateOrUpdate CR with NATS
 	r.backendType = eventingv1alpha1.NatsBackendType
@@ -147,6 +179,7 @@ func (r *Reconciler) reconcileNATSBackend(ctx context.Context) (ctrl.Result, err
 		return ctrl.Result{}, errors.Wrapf(err, "failed to createOrUpdate EventingBackend, type: %s", eventingv1alpha1.NatsBackendType)
 	}
 	r.namedLogger().Debug("created/updated backend CR")
+	deliveryMode := r.getNatsDeliveryMode(newBackend)
 
 	// Stop the BEB subscription controller
 	if err := r.stopBEBController(); err != nil {
@@ -156,8 +189,26 @@ func (r *Reconciler) reconcileNATSBackend(ctx context.Context) (ctrl.Result, err
 		}
 		return ctrl.Result{}, err
 	}
+	// Stop the Kafka subscription controller
+	if err := r.stopKafkaController(); err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when stopKafkaController failed")
+		}
+		return ctrl.Result{}, err
+	}
+	// Restart the NATS subscription controller if the delivery mode was changed
+	if r.natsCommanderStarted && r.natsDeliveryMode != deliveryMode {
+		if err := r.stopNATSController(); err != nil {
+			updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+			if updateErr != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to update status when stopNATSController failed")
+			}
+			return ctrl.Result{}, err
+		}
+	}
 	// Start the NATS subscription controller
-	if err := r.startNATSController(); err != nil {
+	if err := r.startNATSController(deliveryMode); err != nil {
 		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
 		if updateErr != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when startNATSController failed")
@@ -195,6 +246,14 @@ func (r *Reconciler) reconcileNATSBackend(ctx context.Context) (ctrl.Result, err
 	return ctrl.Result{}, err
 }
 
+// getNatsDeliveryMode returns the delivery mode of the given EventingBackend or the default one if it is not set.
+func (r *Reconciler) getNatsDeliveryMode(backend *eventingv1alpha1.EventingBackend) eventingv1alpha1.NatsDeliveryMode {
+	if len(backend.Spec.NatsDeliveryMode) > 0 {
+		return backend.Spec.NatsDeliveryMode
+	}
+	return eventingv1alpha1.NatsDeliveryMode(r.cfg.DefaultNatsDeliveryMode)
+}
+
 func (r *Reconciler) reconcileBEBBackend(ctx context.Context, bebSecret *v1.Secret) (ctrl.Result, error) {
 	r.backendType = eventingv1alpha1.BebBackendType
 	// CreateOrUpdate CR with BEB
@@ -215,6 +274,14 @@ func (r *Reconciler) reconcileBEBBackend(ctx context.Context, bebSecret *v1.Secr
 		}
 		return ctrl.Result{}, err
 	}
+	// Stop the Kafka subscription controller
+	if err := r.stopKafkaController(); err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, bebSecret)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when stopKafkaController failed")
+		}
+		return ctrl.Result{}, err
+	}
 
 	// Ensure that an OAuth2Client CR exists that gets processed by the Hydra operator and creates a secret
 	// containing the oauth2 credentials.
@@ -277,6 +344,104 @@ func (r *Reconciler) reconcileBEBBackend(ctx context.Context, bebSecret *v1.Secr
 	return ctrl.Result{}, nil
 }
 
+func (r *Reconciler) reconcileKafkaBackend(ctx context.Context, kafkaSecret *v1.Secret) (ctrl.Result, error) {
+	r.backendType = eventingv1alpha1.KafkaBackendType
+	// CreateOrUpdate CR with Kafka
+	newBackend, err := r.CreateOrUpdateBackendCR(ctx)
+	if err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, nil, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when CreateOrUpdateBackendCR failed")
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "failed to createOrUpdate EventingBackend, type: %s", eventingv1alpha1.KafkaBackendType)
+	}
+
+	brokers, topic, err := r.getKafkaConnection(kafkaSecret)
+	if err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when getKafkaConnection failed")
+		}
+		r.namedLogger().Errorw("invalid Kafka backend secret", "namespace", kafkaSecret.Namespace, "name", kafkaSecret.Name, "error", err)
+		// No point in reconciling until the secret is changed
+		return ctrl.Result{}, nil
+	}
+
+	// Stop the BEB and NATS subscription controllers
+	if err := r.stopBEBController(); err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when stopBEBController failed")
+		}
+		return ctrl.Result{}, err
+	}
+	if err := r.stopNATSController(); err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when stopNATSController failed")
+		}
+		return ctrl.Result{}, err
+	}
+	// Restart the Kafka subscription controller if the connection was changed
+	if r.kafkaCommanderStarted && (r.kafkaBrokers != brokers || r.kafkaTopic != topic) {
+		if err := r.stopKafkaController(); err != nil {
+			updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+			if updateErr != nil {
+				return ctrl.Result{}, errors.Wrapf(err, "failed to update status when stopKafkaController failed")
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	// Start the Kafka subscription controller
+	if err := r.startKafkaController(brokers, topic); err != nil {
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when startKafkaController failed")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Delete secret for publisher proxy if it exists
+	err = r.DeletePublisherProxySecret(ctx)
+	if err != nil {
+		// Update status if bad
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when DeletePublisherProxySecret failed")
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "cannot delete eventing publisher proxy secret")
+	}
+
+	// CreateOrUpdate deployment for publisher proxy
+	publisher, err := r.CreateOrUpdatePublisherProxy(ctx, r.backendType)
+	if err != nil {
+		// Update status if bad
+		updateErr := r.UpdateBackendStatus(ctx, r.backendType, newBackend, nil, nil)
+		if updateErr != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update status when createOrUpdatePublisherProxy failed")
+		}
+		r.namedLogger().Errorw("failed to create or update publisher proxy", "backend", r.backendType, "error", err)
+		return ctrl.Result{}, err
+	}
+
+	// CreateOrUpdate status of the CR
+	err = r.UpdateBackendStatus(ctx, r.backendType, newBackend, publisher, nil)
+	return ctrl.Result{}, err
+}
+
+// getKafkaConnection returns the brokers and the topic of the Kafka backend secret, the topic is optional
+func (r *Reconciler) getKafkaConnection(kafkaSecret *v1.Secret) (brokers, topic string, err error) {
+	brokers = strings.TrimSpace(string(kafkaSecret.Data[KafkaSecretBrokersKey]))
+	if len(brokers) == 0 {
+		return "", "", fmt.Errorf("%s is missing from Kafka secret", KafkaSecretBrokersKey)
+	}
+	topic = strings.TrimSpace(string(kafkaSecret.Data[KafkaSecretTopicKey]))
+	if len(topic) == 0 {
+		topic = r.cfg.DefaultKafkaTopic
+	}
+	return brokers, topic, nil
+}
+
 func setUpEnvironmentForBEBController(secret *v1.Secret) error {
 	err := os.Setenv("BEB_API_URL", fmt.Sprintf("%s%s", string(secret.Data[PublisherSecretEMSHostKey]), BEBPublishEndpointForSubscriber))
 	if err != nil {
@@ -374,6 +539,10 @@ func (r *Reconciler) UpdateBackendStatus(ctx context.Context, backendType eventi
 		desiredStatus.BebSecretName = ""
 		desiredStatus.BebSecretNamespace = ""
 		subscriptionControllerReady = r.natsCommanderStarted
+	case eventingv1alpha1.KafkaBackendType:
+		desiredStatus.BebSecretName = ""
+		desiredStatus.BebSecretNamespace = ""
+		subscriptionControllerReady = r.kafkaCommanderStarted
 	}
 	eventingReady := subscriptionControllerReady && publisherReady
 
@@ -556,9 +725,11 @@ func (r *Reconciler) CreateOrUpdatePublisherProxy(ctx context.Context, backend e
 
 	switch backend {
 	case eventingv1alpha1.NatsBackendType:
-		desiredPublisher = deployment.NewNATSPublisherDeployment(r.cfg.PublisherConfig)
+		desiredPublisher = deployment.NewNATSPublisherDeployment(r.cfg.PublisherConfig, string(r.natsDeliveryMode))
 	case eventingv1alpha1.BebBackendType:
 		desiredPublisher = deployment.NewBEBPublisherDeployment(r.cfg.PublisherConfig)
+	case eventingv1alpha1.KafkaBackendType:
+		desiredPublisher = deployment.NewKafkaPublisherDeployment(r.cfg.PublisherConfig, r.kafkaBrokers, r.kafkaTopic)
 	default:
 		return nil, fmt.Errorf("unknown eventing backend type %q", backend)
 	}
@@ -620,6 +791,8 @@ func (r *Reconciler) CreateOrUpdateBackendCR(ctx context.Context) (*eventingv1al
 
 	r.namedLogger().Debug("found existing backend CR")
 	desiredBackend.ResourceVersion = currentBackend.ResourceVersion
+	// The spec is owned by the user
+	desiredBackend.Spec = currentBackend.Spec
 	if object.Semantic.DeepEqual(&currentBackend, &desiredBackend) {
 		r.namedLogger().Debug("no need to update existing backend CR")
 		return currentBackend, nil
@@ -721,14 +894,16 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *Reconciler) startNATSController() error {
+func (r *Reconciler) startNATSController(deliveryMode eventingv1alpha1.NatsDeliveryMode) error {
 	if !r.natsCommanderStarted {
-		if err := r.natsCommander.Start(commander.Params{}); err != nil {
+		natsCommanderParams := commander.Params{"delivery_mode": string(deliveryMode)}
+		if err := r.natsCommander.Start(natsCommanderParams); err != nil {
 			r.namedLogger().Errorw("failed to start the NATS commander", "error", err)
 			return err
 		}
 		r.natsCommanderStarted = true
-		r.namedLogger().Info("commander for NATS successfully started")
+		r.natsDeliveryMode = deliveryMode
+		r.namedLogger().Infow("commander for NATS successfully started", "deliveryMode", deliveryMode)
 	}
 	return nil
 }
@@ -770,6 +945,33 @@ func (r *Reconciler) stopBEBController() error {
 	return nil
 }
 
+func (r *Reconciler) startKafkaController(brokers, topic string) error {
+	if !r.kafkaCommanderStarted {
+		kafkaCommanderParams := commander.Params{"brokers": brokers, "topic": topic}
+		if err := r.kafkaCommander.Start(kafkaCommanderParams); err != nil {
+			r.namedLogger().Errorw("failed to start the Kafka commander", "error", err)
+			return err
+		}
+		r.kafkaCommanderStarted = true
+		r.kafkaBrokers = brokers
+		r.kafkaTopic = topic
+		r.namedLogger().Infow("commander for Kafka successfully started", "brokers", brokers, "topic", topic)
+	}
+	return nil
+}
+
+func (r *Reconciler) stopKafkaController() error {
+	if r.kafkaCommanderStarted {
+		if err := r.kafkaCommander.Stop(); err != nil {
+			r.namedLogger().Errorw("failed to stop the Kafka commander", "error", err)
+			return err
+		}
+		r.kafkaCommanderStarted = false
+		r.namedLogger().Info("commander for Kafka successfully stopped")
+	}
+	return nil
+}
+
 func (r *Reconciler) namedLogger() *zap.SugaredLogger {
 	return r.logger.WithContext().Named(reconcilerName).With("backend", r.backendType)
 }
@@ -46,6 +46,8 @@ const (
 	eventingBackendName      = "eventing-backend"
 	bebSecret1name           = "beb-secret-1"
 	bebSecret2name           = "beb-secret-2"
+	kafkaSecretName          = "kafka-secret"
+	kafkaBrokers             = "kafka-0.kafka:9092,kafka-1.kafka:9092"
 )
 
 var (
@@ -53,8 +55,9 @@ var (
 	k8sClient     client.Client
 	testEnv       *envtest.Environment
 
-	natsCommander = &TestCommander{}
-	bebCommander  = &TestCommander{}
+	natsCommander  = &TestCommander{}
+	bebCommander   = &TestCommander{}
+	kafkaCommander = &TestCommander{}
 )
 
 // TestGetSecretForPublisher verifies the successful and failing retrieval
@@ -189,6 +192,7 @@ var _ = BeforeSuite(func(done Done) {
 		context.Background(),
 		natsCommander,
 		bebCommander,
+		kafkaCommander,
 		k8sManager.GetClient(),
 		k8sManager.GetCache(),
 		defaultLogger,
@@ -456,8 +460,96 @@ var _ = Describe("Backend Reconciliation Tests", func() {
 				}))
 		})
 	})
+
+	When("A secret labeled for Kafka is found in addition to the BEB secret", func() {
+		It("Should take down eventing", func() {
+			ctx := context.Background()
+			ensureKafkaSecretCreated(ctx, kafkaSecretName, kymaSystemNamespace)
+			By("Checking EventingReady status is set to false")
+			Eventually(eventingBackendStatusGetter(ctx, eventingBackendName, kymaSystemNamespace), timeout, pollingInterval).
+				Should(Equal(&eventingv1alpha1.EventingBackendStatus{
+					Backend:                     eventingv1alpha1.BebBackendType,
+					EventingReady:               utils.BoolPtr(false),
+					SubscriptionControllerReady: utils.BoolPtr(false),
+					PublisherProxyReady:         utils.BoolPtr(false),
+					BebSecretName:               "",
+					BebSecretNamespace:          "",
+				}))
+		})
+	})
+
+	When("The BEB secret is un-labeled and only the Kafka secret is left", func() {
+		It("Should switch from BEB to Kafka", func() {
+			ctx := context.Background()
+			resetPublisherProxyStatus(ctx)
+			By("Un-label the BEB secret to switch to Kafka")
+			bebSecret := reconcilertesting.WithBEBMessagingSecret(bebSecret1name, kymaSystemNamespace)
+			bebSecret.Labels = map[string]string{}
+			Expect(k8sClient.Update(ctx, bebSecret)).Should(BeNil())
+			Eventually(eventingBackendStatusGetter(ctx, eventingBackendName, kymaSystemNamespace), timeout, pollingInterval).
+				Should(Equal(&eventingv1alpha1.EventingBackendStatus{
+					Backend:                     eventingv1alpha1.KafkaBackendType,
+					EventingReady:               utils.BoolPtr(false),
+					SubscriptionControllerReady: utils.BoolPtr(true),
+					PublisherProxyReady:         utils.BoolPtr(false),
+					BebSecretName:               "",
+					BebSecretNamespace:          "",
+				}))
+			By("Ensure publisher proxy secret is removed")
+			eventuallyPublisherProxySecret(ctx).Should(BeNil())
+			By("Ensure publisher proxy publishes to the Kafka brokers of the secret")
+			Eventually(publisherProxyEnvGetter(ctx, "KAFKA_BROKERS"), timeout, pollingInterval).
+				Should(Equal(kafkaBrokers))
+		})
+		It("Should mark eventing as ready when publisher proxy is ready", func() {
+			ctx := context.Background()
+			ensurePublisherProxyIsReady(ctx)
+			Eventually(eventingBackendStatusGetter(ctx, eventingBackendName, kymaSystemNamespace), timeout, pollingInterval).
+				Should(Equal(&eventingv1alpha1.EventingBackendStatus{
+					Backend:                     eventingv1alpha1.KafkaBackendType,
+					EventingReady:               utils.BoolPtr(true),
+					SubscriptionControllerReady: utils.BoolPtr(true),
+					PublisherProxyReady:         utils.BoolPtr(true),
+					BebSecretName:               "",
+					BebSecretNamespace:          "",
+				}))
+		})
+	})
 })
 
+func ensureKafkaSecretCreated(ctx context.Context, name, ns string) {
+	kafkaSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				BEBBackendSecretLabelKey: KafkaBackendSecretLabelValue,
+			},
+		},
+		StringData: map[string]string{
+			KafkaSecretBrokersKey: kafkaBrokers,
+		},
+	}
+	By(fmt.Sprintf("Ensuring a Kafka Secret %q/%q is created", name, ns))
+	err := k8sClient.Create(ctx, kafkaSecret)
+	Expect(err).Should(BeNil())
+}
+
+func publisherProxyEnvGetter(ctx context.Context, name string) func() (string, error) {
+	return func() (string, error) {
+		dep, err := publisherProxyDeploymentGetter(ctx)()
+		if err != nil {
+			return "", err
+		}
+		for _, env := range dep.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == name {
+				return env.Value, nil
+			}
+		}
+		return "", nil
+	}
+}
+
 func ensureNamespaceCreated(ctx context.Context, namespace string) {
 	By(fmt.Sprintf("Ensuring the namespace %q is created", namespace))
 	ns := reconcilertesting.WithNamespace(namespace)
@@ -0,0 +1,230 @@
+package subscription_kafka
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/application"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/env"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/handlers/eventtype"
+	"github.com/kyma-project/kyma/components/eventing-controller/pkg/kafka"
+	"github.com/kyma-project/kyma/components/eventing-controller/utils"
+)
+
+// Reconciler reconciles a Subscription object
+type Reconciler struct {
+	ctx context.Context
+	client.Client
+	cache.Cache
+	Backend          handlers.MessagingBackend
+	Log              logr.Logger
+	recorder         record.EventRecorder
+	eventTypeCleaner eventtype.Cleaner
+}
+
+var (
+	Finalizer = eventingv1alpha1.GroupVersion.Group
+)
+
+func NewReconciler(ctx context.Context, client client.Client, applicationLister *application.Lister, cache cache.Cache,
+	log logr.Logger, recorder record.EventRecorder, cfg env.KafkaConfig) *Reconciler {
+	kafkaHandler := handlers.NewKafka(cfg, kafka.NewCluster(cfg), log)
+	err := kafkaHandler.Initialize(env.Config{})
+	if err != nil {
+		log.Error(err, "reconciler can't start")
+		panic(err)
+	}
+	return &Reconciler{
+		ctx:              ctx,
+		Client:           client,
+		Cache:            cache,
+		Backend:          kafkaHandler,
+		Log:              log,
+		recorder:         recorder,
+		eventTypeCleaner: eventtype.NewCleaner(cfg.EventTypePrefix, applicationLister, log),
+	}
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eventingv1alpha1.Subscription{}).
+		Complete(r)
+}
+
+//  SetupUnmanaged creates a controller under the client control
+func (r *Reconciler) SetupUnmanaged(mgr ctrl.Manager) error {
+	ctru, err := controller.NewUnmanaged("kafka-subscription-controller", mgr, controller.Options{
+		Reconciler: r,
+	})
+	if err != nil {
+		r.Log.Error(err, "failed to create a unmanaged Kafka controller")
+		return err
+	}
+
+	if err := ctru.Watch(&source.Kind{Type: &eventingv1alpha1.Subscription{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		r.Log.Error(err, "unable to watch subscriptions")
+		return err
+	}
+
+	go func(r *Reconciler, c controller.Controller) {
+		if err := c.Start(r.ctx); err != nil {
+			r.Log.Error(err, "failed to start the kafka-subscription-controller")
+			os.Exit(1)
+		}
+	}(r, ctru)
+
+	return nil
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Log.Info("received subscription reconciliation request", "namespace", req.Namespace, "name", req.Name)
+
+	actualSubscription := &eventingv1alpha1.Subscription{}
+
+	// Ensure the object was not deleted in the meantime
+	err := r.Client.Get(ctx, req.NamespacedName, actualSubscription)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	desiredSubscription := actualSubscription.DeepCopy()
+	//Bind fields to logger
+	log := r.Log.WithValues("kind", desiredSubscription.GetObjectKind().GroupVersionKind().Kind,
+		"name", desiredSubscription.GetName(),
+		"namespace", desiredSubscription.GetNamespace(),
+		"version", desiredSubscription.GetGeneration(),
+	)
+
+	if !desiredSubscription.ObjectMeta.DeletionTimestamp.IsZero() {
+		// The object is being deleted
+		if utils.ContainsString(desiredSubscription.ObjectMeta.Finalizers, Finalizer) {
+			// the consumer group of the subscription is deleted together with its consumers
+			if err := r.Backend.DeleteSubscription(desiredSubscription); err != nil {
+				log.Error(err, "failed to delete subscription")
+				// if failed to delete the external dependency here, return with error
+				// so that it can be retried
+				return ctrl.Result{}, err
+			}
+
+			// remove our finalizer from the list and update it.
+			log.Info("Removing finalizer from subscription object")
+			desiredSubscription.ObjectMeta.Finalizers = utils.RemoveString(desiredSubscription.ObjectMeta.Finalizers,
+				Finalizer)
+			if err := r.Client.Update(ctx, desiredSubscription); err != nil {
+				log.Error(err, "failed to remove finalizer from subscription object")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Check for valid sink
+	if err := r.assertSinkValidity(actualSubscription.Spec.Sink); err != nil {
+		log.Error(err, "failed to parse sink URL")
+		if err := r.syncSubscriptionStatus(ctx, actualSubscription, false, err.Error()); err != nil {
+			return ctrl.Result{}, err
+		}
+		// No point in reconciling as the sink is invalid
+		return ctrl.Result{}, nil
+	}
+	// Check for valid dead-letter sink
+	if policy := actualSubscription.Spec.DeliveryPolicy; policy != nil && len(policy.DeadLetterSink) > 0 {
+		if err := r.assertSinkValidity(policy.DeadLetterSink); err != nil {
+			log.Error(err, "failed to parse dead-letter sink URL")
+			if err := r.syncSubscriptionStatus(ctx, actualSubscription, false, err.Error()); err != nil {
+				return ctrl.Result{}, err
+			}
+			// No point in reconciling as the dead-letter sink is invalid
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// registering our finalizer.
+	if !utils.ContainsString(desiredSubscription.ObjectMeta.Finalizers, Finalizer) {
+		log.Info("Adding finalizer to subscription object")
+		desiredSubscription.ObjectMeta.Finalizers = append(desiredSubscription.ObjectMeta.Finalizers, Finalizer)
+		if err := r.Update(ctx, desiredSubscription); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Syncing the subscription replaces its running consumers
+	if _, err := r.Backend.SyncSubscription(desiredSubscription, r.eventTypeCleaner); err != nil {
+		log.Error(err, "failed to sync subscription")
+		if err := r.syncSubscriptionStatus(ctx, actualSubscription, false, err.Error()); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+
+	log.Info("successfully created Kafka consumers")
+
+	// Update status
+	if err := r.syncSubscriptionStatus(ctx, actualSubscription, true, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// syncSubscriptionStatus syncs Subscription status
+func (r Reconciler) syncSubscriptionStatus(ctx context.Context, sub *eventingv1alpha1.Subscription,
+	isKafkaSubReady bool, message string) error {
+	desiredSubscription := sub.DeepCopy()
+	desiredConditions := make([]eventingv1alpha1.Condition, 0)
+	conditionAdded := false
+	condition := eventingv1alpha1.MakeCondition(eventingv1alpha1.ConditionSubscriptionActive,
+		eventingv1alpha1.ConditionReasonKafkaSubscriptionActive, corev1.ConditionFalse, message)
+	if isKafkaSubReady {
+		condition = eventingv1alpha1.MakeCondition(eventingv1alpha1.ConditionSubscriptionActive,
+			eventingv1alpha1.ConditionReasonKafkaSubscriptionActive, corev1.ConditionTrue, "")
+	}
+	for _, c := range sub.Status.Conditions {
+		var chosenCondition eventingv1alpha1.Condition
+		if c.Type == condition.Type {
+			// take given condition
+			chosenCondition = condition
+			conditionAdded = true
+		} else {
+			// take already present condition
+			chosenCondition = c
+		}
+		desiredConditions = append(desiredConditions, chosenCondition)
+	}
+	if !conditionAdded {
+		desiredConditions = append(desiredConditions, condition)
+	}
+	desiredSubscription.Status.Conditions = desiredConditions
+	desiredSubscription.Status.Ready = isKafkaSubReady
+
+	if !reflect.DeepEqual(sub.Status, desiredSubscription.Status) {
+		err := r.Client.Status().Update(ctx, desiredSubscription, &client.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update subscription status")
+		}
+		r.Log.Info("successfully updated subscription status")
+	}
+	return nil
+}
+
+func (r Reconciler) assertSinkValidity(sink string) error {
+	_, err := url.ParseRequestURI(sink)
+	return err
+}
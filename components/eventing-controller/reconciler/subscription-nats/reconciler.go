@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
 	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
@@ -16,8 +17,11 @@ import (
 	"github.com/kyma-project/kyma/components/eventing-controller/utils"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -36,6 +40,8 @@ type Reconciler struct {
 	Log              logr.Logger
 	recorder         record.EventRecorder
 	eventTypeCleaner eventtype.Cleaner
+	// statusUpdateInterval is the interval in which the delivery status of the subscriptions is updated
+	statusUpdateInterval time.Duration
 }
 
 var (
@@ -59,19 +65,21 @@ func NewReconciler(ctx context.Context, client client.Client, applicationLister
 		panic(err)
 	}
 	return &Reconciler{
-		ctx:              ctx,
-		Client:           client,
-		Cache:            cache,
-		Backend:          natsHandler,
-		Log:              log,
-		recorder:         recorder,
-		eventTypeCleaner: eventtype.NewCleaner(cfg.EventTypePrefix, applicationLister, log),
+		ctx:                  ctx,
+		Client:               client,
+		Cache:                cache,
+		Backend:              natsHandler,
+		Log:                  log,
+		recorder:             recorder,
+		eventTypeCleaner:     eventtype.NewCleaner(cfg.EventTypePrefix, applicationLister, log),
+		statusUpdateInterval: cfg.StatusUpdateInterval,
 	}
 }
 
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	go r.reportDeliveryStatus(r.ctx)
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&eventingv1alpha1.Subscription{}).
+		For(&eventingv1alpha1.Subscription{}, builder.WithPredicates(subscriptionPredicate())).
 		Complete(r)
 }
 
@@ -85,7 +93,8 @@ func (r *Reconciler) SetupUnmanaged(mgr ctrl.Manager) error {
 		return err
 	}
 
-	if err := ctru.Watch(&source.Kind{Type: &eventingv1alpha1.Subscription{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	if err := ctru.Watch(&source.Kind{Type: &eventingv1alpha1.Subscription{}}, &handler.EnqueueRequestForObject{},
+		subscriptionPredicate()); err != nil {
 		r.Log.Error(err, "unable to watch subscriptions")
 		return err
 	}
@@ -122,6 +131,197 @@ func (r *Reconciler) SetupUnmanaged(mgr ctrl.Manager) error {
 		}
 	}(r, ctru)
 
+	go r.reportDeliveryStatus(r.ctx)
+
+	return nil
+}
+
+// subscriptionPredicate filters out the updates of a subscription which only change its delivery status,
+// they are written periodically by the reconciler itself and do not require to sync the NATS subscriptions.
+func subscriptionPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSub, oldOk := e.ObjectOld.(*eventingv1alpha1.Subscription)
+			newSub, newOk := e.ObjectNew.(*eventingv1alpha1.Subscription)
+			if !oldOk || !newOk {
+				return true
+			}
+			return !isDeliveryStatusUpdate(oldSub, newSub)
+		},
+	}
+}
+
+// isDeliveryStatusUpdate returns true if the subscriptions differ in their delivery status only
+func isDeliveryStatusUpdate(oldSub, newSub *eventingv1alpha1.Subscription) bool {
+	if reflect.DeepEqual(oldSub.Status, newSub.Status) {
+		return false
+	}
+	oldCopy, newCopy := oldSub.DeepCopy(), newSub.DeepCopy()
+	for _, sub := range []*eventingv1alpha1.Subscription{oldCopy, newCopy} {
+		sub.ResourceVersion = ""
+		sub.ManagedFields = nil
+		sub.Status.Dispatcher = nil
+		sub.Status.Replay = nil
+		sub.Status.EmsSubscriptionStatus.LastSuccessfulDelivery = ""
+		sub.Status.EmsSubscriptionStatus.LastFailedDelivery = ""
+		sub.Status.EmsSubscriptionStatus.LastFailedDeliveryReason = ""
+	}
+	return reflect.DeepEqual(oldCopy, newCopy)
+}
+
+// reportDeliveryStatus periodically writes the state and the outcomes of the event delivery of the NATS backend
+// to the status of the subscriptions, until the context is done.
+// The stats and replay states which could not be written are kept in memory and retried in the next interval. They
+// are kept per subscription without a bound for as long as the status updates fail, and they are lost if the
+// controller restarts before they are written, hence the delivered and failed counters of the status are best effort.
+func (r *Reconciler) reportDeliveryStatus(ctx context.Context) {
+	natsHandler, ok := r.Backend.(*handlers.Nats)
+	if !ok || r.statusUpdateInterval <= 0 {
+		return
+	}
+	// pendingStats are the delivery stats which were not written to the status yet, they are not persisted
+	pendingStats := make(map[types.NamespacedName]*handlers.DeliveryStats)
+	// pendingReplays are the replay states which were not written to the status yet
+	pendingReplays := make(map[types.NamespacedName]eventingv1alpha1.ReplayStatus)
+	ticker := time.NewTicker(r.statusUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		dispatcherStatus := natsHandler.GetDispatcherStatus()
+		for namespacedName, stats := range natsHandler.CollectDeliveryStats() {
+			if _, ok := pendingStats[namespacedName]; !ok {
+				pendingStats[namespacedName] = &handlers.DeliveryStats{}
+			}
+			pendingStats[namespacedName].Add(stats)
+		}
+
+		namespacedNames := make(map[types.NamespacedName]bool)
+		for namespacedName := range dispatcherStatus {
+			namespacedNames[namespacedName] = true
+		}
+		for namespacedName := range pendingStats {
+			namespacedNames[namespacedName] = true
+		}
+		for namespacedName := range namespacedNames {
+			var status *eventingv1alpha1.DispatcherStatus
+			if s, ok := dispatcherStatus[namespacedName]; ok {
+				status = &s
+			}
+			stats := handlers.DeliveryStats{}
+			if s, ok := pendingStats[namespacedName]; ok {
+				stats = *s
+			}
+			if err := r.syncDeliveryStatus(ctx, namespacedName, status, stats); err != nil {
+				// the pending stats are written in the next interval
+				r.Log.Error(err, "failed to update the delivery status",
+					"namespace", namespacedName.Namespace, "name", namespacedName.Name)
+				continue
+			}
+			delete(pendingStats, namespacedName)
+		}
+
+		for namespacedName, status := range natsHandler.CollectReplayStatus() {
+			pendingReplays[namespacedName] = status
+		}
+		for namespacedName, status := range pendingReplays {
+			if err := r.syncReplayStatus(ctx, namespacedName, status); err != nil {
+				r.Log.Error(err, "failed to update the replay status",
+					"namespace", namespacedName.Namespace, "name", namespacedName.Name)
+				continue
+			}
+			delete(pendingReplays, namespacedName)
+		}
+	}
+}
+
+// syncDeliveryStatus syncs the delivery status of a Subscription. The status of the dispatcher is replaced,
+// if it is known, and the delivery stats are added to the status.
+func (r *Reconciler) syncDeliveryStatus(ctx context.Context, namespacedName types.NamespacedName,
+	status *eventingv1alpha1.DispatcherStatus, stats handlers.DeliveryStats) error {
+	sub := &eventingv1alpha1.Subscription{}
+	if err := r.Client.Get(ctx, namespacedName, sub); err != nil {
+		if k8serrors.IsNotFound(err) {
+			handlers.DeleteDeliveryMetrics(namespacedName)
+			return nil
+		}
+		return err
+	}
+
+	desiredSubscription := sub.DeepCopy()
+	desiredStatus := &desiredSubscription.Status
+	if status != nil {
+		if desiredStatus.Dispatcher != nil {
+			status.Delivered = desiredStatus.Dispatcher.Delivered
+			status.Failed = desiredStatus.Dispatcher.Failed
+		}
+		desiredStatus.Dispatcher = status
+	}
+	if stats.Delivered > 0 || stats.Failed > 0 {
+		if desiredStatus.Dispatcher == nil {
+			desiredStatus.Dispatcher = &eventingv1alpha1.DispatcherStatus{}
+		}
+		desiredStatus.Dispatcher.Delivered += stats.Delivered
+		desiredStatus.Dispatcher.Failed += stats.Failed
+	}
+	if !stats.LastSuccessfulDelivery.IsZero() {
+		desiredStatus.EmsSubscriptionStatus.LastSuccessfulDelivery = stats.LastSuccessfulDelivery.Format(time.RFC3339)
+	}
+	if !stats.LastFailedDelivery.IsZero() {
+		desiredStatus.EmsSubscriptionStatus.LastFailedDelivery = stats.LastFailedDelivery.Format(time.RFC3339)
+		desiredStatus.EmsSubscriptionStatus.LastFailedDeliveryReason = stats.LastFailedDeliveryReason
+	}
+
+	if reflect.DeepEqual(sub.Status, desiredSubscription.Status) {
+		return nil
+	}
+	if err := r.Client.Status().Update(ctx, desiredSubscription); err != nil {
+		return errors.Wrapf(err, "failed to update subscription status")
+	}
+	return nil
+}
+
+// syncReplay starts the replay of persisted events which is requested by the replay annotation of a Subscription,
+// unless the status shows that it was started already
+func (r *Reconciler) syncReplay(ctx context.Context, sub *eventingv1alpha1.Subscription) error {
+	natsHandler, ok := r.Backend.(*handlers.Nats)
+	if !ok {
+		return nil
+	}
+	request, ok := sub.Annotations[eventingv1alpha1.ReplayAnnotation]
+	if !ok || (sub.Status.Replay != nil && sub.Status.Replay.Request == request) {
+		return nil
+	}
+	status := natsHandler.StartReplay(sub, r.eventTypeCleaner)
+	r.Log.Info("started replay", "namespace", sub.Namespace, "name", sub.Name, "request", request,
+		"phase", status.Phase)
+	return r.syncReplayStatus(ctx, types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name}, status)
+}
+
+// syncReplayStatus writes the state of a replay to the status of the Subscription. The state of a replay which
+// was replaced by another request in the meantime is dropped.
+func (r *Reconciler) syncReplayStatus(ctx context.Context, namespacedName types.NamespacedName,
+	status eventingv1alpha1.ReplayStatus) error {
+	sub := &eventingv1alpha1.Subscription{}
+	if err := r.Client.Get(ctx, namespacedName, sub); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if sub.Annotations[eventingv1alpha1.ReplayAnnotation] != status.Request {
+		return nil
+	}
+
+	desiredSubscription := sub.DeepCopy()
+	desiredSubscription.Status.Replay = &status
+	if reflect.DeepEqual(sub.Status, desiredSubscription.Status) {
+		return nil
+	}
+	if err := r.Client.Status().Update(ctx, desiredSubscription); err != nil {
+		return errors.Wrapf(err, "failed to update subscription status")
+	}
 	return nil
 }
 
@@ -162,6 +362,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				return ctrl.Result{}, err
 			}
 
+			handlers.DeleteDeliveryMetrics(req.NamespacedName)
+			if natsHandler, ok := r.Backend.(*handlers.Nats); ok {
+				natsHandler.CancelReplay(req.NamespacedName)
+			}
+
 			// remove our finalizer from the list and update it.
 			log.Info("Removing finalizer from subscription object")
 			desiredSubscription.ObjectMeta.Finalizers = utils.RemoveString(desiredSubscription.ObjectMeta.Finalizers,
@@ -182,6 +387,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// No point in reconciling as the sink is invalid
 		return ctrl.Result{}, nil
 	}
+	// Check for valid dead-letter sink
+	if policy := actualSubscription.Spec.DeliveryPolicy; policy != nil && len(policy.DeadLetterSink) > 0 {
+		if err := r.assertSinkValidity(policy.DeadLetterSink); err != nil {
+			r.Log.Error(err, "failed to parse dead-letter sink URL")
+			if err := r.syncSubscriptionStatus(ctx, actualSubscription, false, err.Error()); err != nil {
+				return ctrl.Result{}, err
+			}
+			// No point in reconciling as the dead-letter sink is invalid
+			return ctrl.Result{}, nil
+		}
+	}
 
 	// Clean up the old subscriptions
 	err = r.Backend.DeleteSubscription(desiredSubscription)
@@ -225,6 +441,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Start the requested replay
+	if err := r.syncReplay(ctx, actualSubscription); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return result, nil
 }
 
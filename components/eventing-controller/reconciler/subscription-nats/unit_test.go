@@ -0,0 +1,75 @@
+package subscription_nats
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	eventingv1alpha1 "github.com/kyma-project/kyma/components/eventing-controller/api/v1alpha1"
+	reconcilertesting "github.com/kyma-project/kyma/components/eventing-controller/testing"
+)
+
+func Test_isDeliveryStatusUpdate(t *testing.T) {
+	var tests = []struct {
+		name                   string
+		givenUpdate            func(sub *eventingv1alpha1.Subscription)
+		isDeliveryStatusUpdate bool
+	}{
+		{
+			name:                   "No change",
+			givenUpdate:            func(sub *eventingv1alpha1.Subscription) {},
+			isDeliveryStatusUpdate: false,
+		},
+		{
+			name: "Dispatcher status changed",
+			givenUpdate: func(sub *eventingv1alpha1.Subscription) {
+				sub.ResourceVersion = "2"
+				sub.Status.Dispatcher = &eventingv1alpha1.DispatcherStatus{Backlog: 10, InFlight: 1, Throttled: true}
+			},
+			isDeliveryStatusUpdate: true,
+		},
+		{
+			name: "Last deliveries changed",
+			givenUpdate: func(sub *eventingv1alpha1.Subscription) {
+				sub.Status.EmsSubscriptionStatus.LastSuccessfulDelivery = "2021-06-01T10:00:00Z"
+				sub.Status.EmsSubscriptionStatus.LastFailedDelivery = "2021-06-01T09:00:00Z"
+				sub.Status.EmsSubscriptionStatus.LastFailedDeliveryReason = "500 Internal Server Error"
+			},
+			isDeliveryStatusUpdate: true,
+		},
+		{
+			name: "Replay status changed",
+			givenUpdate: func(sub *eventingv1alpha1.Subscription) {
+				sub.Status.Replay = &eventingv1alpha1.ReplayStatus{Request: "1", Phase: eventingv1alpha1.ReplayPhaseRunning, Replayed: 10}
+			},
+			isDeliveryStatusUpdate: true,
+		},
+		{
+			name: "Dispatcher status and readiness changed",
+			givenUpdate: func(sub *eventingv1alpha1.Subscription) {
+				sub.Status.Ready = false
+				sub.Status.Dispatcher = &eventingv1alpha1.DispatcherStatus{Backlog: 10}
+			},
+			isDeliveryStatusUpdate: false,
+		},
+		{
+			name: "Dispatcher status and spec changed",
+			givenUpdate: func(sub *eventingv1alpha1.Subscription) {
+				sub.Spec.Sink = "https://other.sink"
+				sub.Status.Dispatcher = &eventingv1alpha1.DispatcherStatus{Backlog: 10}
+			},
+			isDeliveryStatusUpdate: false,
+		},
+	}
+
+	g := NewGomegaWithT(t)
+	for _, tt := range tests {
+		oldSub := reconcilertesting.NewSubscription("some-name", "some-namespace", reconcilertesting.WithNotCleanEventTypeFilter)
+		oldSub.ResourceVersion = "1"
+		oldSub.Status.Ready = true
+		oldSub.Status.Dispatcher = &eventingv1alpha1.DispatcherStatus{}
+		newSub := oldSub.DeepCopy()
+		tt.givenUpdate(newSub)
+		g.Expect(isDeliveryStatusUpdate(oldSub, newSub)).To(Equal(tt.isDeliveryStatusUpdate), tt.name)
+	}
+}
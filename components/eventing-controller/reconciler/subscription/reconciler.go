@@ -241,6 +241,13 @@ func (r *Reconciler) syncBEBSubscription(subscription *eventingv1alpha1.Subscrip
 	var statusChanged bool
 	var err error
 	if statusChanged, err = r.Backend.SyncSubscription(subscription, r.eventTypeCleaner, apiRule); err != nil {
+		// the subscription is not synced until its spec changes, hence it is not requeued
+		var unsupported *handlers.UnsupportedSpecError
+		if errors.As(err, &unsupported) {
+			logger.Info("BEB subscription not synced", "reason", err.Error())
+			condition := eventingv1alpha1.MakeCondition(eventingv1alpha1.ConditionSubscribed, eventingv1alpha1.ConditionReasonSubscriptionSpecNotSupported, corev1.ConditionFalse, err.Error())
+			return false, r.updateCondition(subscription, condition, ctx)
+		}
 		logger.Error(err, "Update BEB subscription failed")
 		condition := eventingv1alpha1.MakeCondition(eventingv1alpha1.ConditionSubscribed, eventingv1alpha1.ConditionReasonSubscriptionCreationFailed, corev1.ConditionFalse, "")
 		if err := r.updateCondition(subscription, condition, ctx); err != nil {
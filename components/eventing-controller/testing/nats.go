@@ -12,6 +12,16 @@ func RunNatsServerOnPort(port int) *server.Server {
 	return natstestserver.RunServer(&opts)
 }
 
+// RunNatsServerWithJetStreamOnPort runs a NATS server with JetStream enabled, which keeps file based streams
+// in the given directory.
+func RunNatsServerWithJetStreamOnPort(port int, storeDir string) *server.Server {
+	opts := natstestserver.DefaultTestOptions
+	opts.Port = port
+	opts.JetStream = true
+	opts.StoreDir = storeDir
+	return natstestserver.RunServer(&opts)
+}
+
 // ShutDownNATSServer shuts down test NATS server and waits until shutdown is complete
 func ShutDownNATSServer(natsServer *server.Server) {
 	natsServer.Shutdown()
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+
+	"github.com/vrischmann/envconfig"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/activator"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+var setupLog = ctrl.Log.WithName("setup")
+
+func main() {
+	ctrl.SetLogger(ctrlzap.New())
+
+	config := activator.Config{}
+	if err := envconfig.InitWithPrefix(&config, "APP"); err != nil {
+		setupLog.Error(err, "unable to load config")
+		os.Exit(1)
+	}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = serverlessv1alpha1.AddToScheme(scheme)
+
+	k8sClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create kubernetes client")
+		os.Exit(1)
+	}
+
+	stop := ctrl.SetupSignalHandler()
+
+	setupLog.Info("Starting activator", "namespace", config.Namespace)
+	if err := activator.New(k8sClient, ctrl.Log, config).Start(stop); err != nil {
+		setupLog.Error(err, "unable to run activator")
+		os.Exit(1)
+	}
+}
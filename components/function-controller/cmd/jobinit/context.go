@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vrischmann/envconfig"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/buildcontext"
+	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
+	"github.com/kyma-project/kyma/components/function-controller/internal/git"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+const contextCommand = "context"
+
+type contextFlags struct {
+	function              string
+	name                  string
+	runtimeConfig         stringList
+	webhookConfig         string
+	dockerfile            string
+	packageRegistryConfig string
+	sourceDir             string
+	repositoryURL         string
+	reference             string
+	output                string
+}
+
+// stringList is a flag that can be set multiple times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runContext assembles the build context the build Job of a Function would use, so that a failing build can be
+// reproduced locally and Functions can be validated before they are applied
+func runContext(args []string) error {
+	flags := contextFlags{}
+	flagSet := flag.NewFlagSet(contextCommand, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), "Usage: jobinit %s --function function.yaml --output context.tar.gz [options]\n\n", contextCommand)
+		flagSet.PrintDefaults()
+	}
+	flagSet.StringVar(&flags.function, "function", "", "File with the Function manifest, - reads it from the standard input")
+	flagSet.StringVar(&flags.name, "name", "", "Name of the Function if the manifest holds multiple Functions")
+	flagSet.Var(&flags.runtimeConfig, "runtime-config", "File with the runtime ConfigMaps holding the Dockerfiles and optionally FunctionRuntimes, can be repeated")
+	flagSet.StringVar(&flags.webhookConfig, "webhook-config", "", "File with the ConfigMap of the webhook, which configures the defaulting and validation of the Function")
+	flagSet.StringVar(&flags.dockerfile, "dockerfile", "", "Dockerfile used instead of the one from the runtime ConfigMap")
+	flagSet.StringVar(&flags.packageRegistryConfig, "package-registry-config", "", "Configuration of the package registry, e.g. an .npmrc or pip.conf file")
+	flagSet.StringVar(&flags.sourceDir, "source-dir", "", "Local checkout of the repository of a git Function")
+	flagSet.StringVar(&flags.repositoryURL, "repository-url", "", "URL of the repository of a git Function, which is cloned if --source-dir is not set")
+	flagSet.StringVar(&flags.reference, "reference", "", "Commit, branch or tag to clone, spec.reference of the Function by default")
+	flagSet.StringVar(&flags.output, "output", "", "Directory to write the build context to, or a .tar.gz or .tgz file, - writes a tarball to the standard output")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flags.function == "" || flags.output == "" {
+		flagSet.Usage()
+		return errors.New("--function and --output are required")
+	}
+
+	manifests := buildcontext.NewManifests()
+	if err := readManifests(manifests, flags.function); err != nil {
+		return err
+	}
+	instance, err := selectFunction(manifests.Functions, flags.name)
+	if err != nil {
+		return err
+	}
+	for _, file := range flags.runtimeConfig {
+		if err := readManifests(manifests, file); err != nil {
+			return err
+		}
+	}
+	if flags.webhookConfig != "" {
+		if err := readManifests(manifests, flags.webhookConfig); err != nil {
+			return err
+		}
+	}
+	// the Function is defaulted and validated as if it was applied, e.g. it can't write files outside of its sources
+	if err := validateFunction(instance, manifests); err != nil {
+		return err
+	}
+
+	rtmConfig, rtm, err := runtime.Resolve(context.Background(), manifests.FunctionRuntimeGetter(), instance.Spec.Runtime)
+	if err != nil {
+		return errors.Wrapf(err, "while resolving runtime of function %s", instance.Name)
+	}
+	dockerfile, err := readDockerfile(manifests, flags.dockerfile, rtmConfig)
+	if err != nil {
+		return err
+	}
+	packageRegistryConfig, err := readOptionalFile(flags.packageRegistryConfig)
+	if err != nil {
+		return err
+	}
+
+	var buildContext *buildcontext.Context
+	if instance.Spec.Type == serverlessv1alpha1.SourceTypeGit {
+		sourceDir, cleanup, err := repositoryDir(instance, flags)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		buildContext, err = buildcontext.ForRepository(instance, sourceDir, rtmConfig, dockerfile, packageRegistryConfig)
+		if err != nil {
+			return err
+		}
+	} else {
+		buildContext, err = buildcontext.ForInline(instance, rtmConfig, rtm, dockerfile, packageRegistryConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeContext(buildContext, flags.output); err != nil {
+		return err
+	}
+	log.Printf("Build context of function %s written to %s", instance.Name, flags.output)
+	return nil
+}
+
+// validateFunction defaults and validates the Function like the webhook. The configuration of the webhook is read from
+// its ConfigMap in the manifests, the environment variables of the webhook take precedence.
+func validateFunction(instance *serverlessv1alpha1.Function, manifests *buildcontext.Manifests) error {
+	for key, value := range manifests.WebhookEnvs {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return errors.Wrapf(err, "while setting %s", key)
+		}
+	}
+
+	defaultingCfg, err := readDefaultingConfig()
+	if err != nil {
+		return err
+	}
+	validationCfg := serverlessv1alpha1.ValidationConfig{}
+	if err := envconfig.InitWithPrefix(&validationCfg, "WEBHOOK_VALIDATION"); err != nil {
+		return errors.Wrap(err, "while reading env validation variables")
+	}
+
+	// like kubectl, manifests without a Namespace are applied to the default one
+	if instance.Namespace == "" {
+		instance.Namespace = metav1.NamespaceDefault
+	}
+	ctx := context.WithValue(context.Background(), serverlessv1alpha1.DefaultingConfigKey, defaultingCfg)
+	instance.SetDefaults(ctx)
+
+	ctx = context.WithValue(ctx, serverlessv1alpha1.ValidationConfigKey, validationCfg)
+	ctx = context.WithValue(ctx, serverlessv1alpha1.FunctionRuntimeGetterKey, manifests.FunctionRuntimeGetter())
+	if err := instance.Validate(ctx); err != nil {
+		return errors.Wrapf(err, "function %s is invalid", instance.Name)
+	}
+	return nil
+}
+
+func readDefaultingConfig() (serverlessv1alpha1.DefaultingConfig, error) {
+	cfg := serverlessv1alpha1.DefaultingConfig{}
+	if err := envconfig.InitWithPrefix(&cfg, "WEBHOOK_DEFAULTING"); err != nil {
+		return cfg, errors.Wrap(err, "while reading env defaulting variables")
+	}
+
+	var err error
+	if cfg.Function.Replicas.Presets, err = serverlessv1alpha1.ParseReplicasPresets(cfg.Function.Replicas.PresetsMap); err != nil {
+		return cfg, errors.Wrap(err, "while parsing function replicas presets")
+	}
+	if cfg.Function.Resources.Presets, err = serverlessv1alpha1.ParseResourcePresets(cfg.Function.Resources.PresetsMap); err != nil {
+		return cfg, errors.Wrap(err, "while parsing function resources presets")
+	}
+	if cfg.BuildJob.Resources.Presets, err = serverlessv1alpha1.ParseResourcePresets(cfg.BuildJob.Resources.PresetsMap); err != nil {
+		return cfg, errors.Wrap(err, "while parsing build resources presets")
+	}
+
+	// the defaulting can't be done without the default presets
+	_, replicas := cfg.Function.Replicas.Presets[cfg.Function.Replicas.DefaultPreset]
+	_, resources := cfg.Function.Resources.Presets[cfg.Function.Resources.DefaultPreset]
+	_, buildResources := cfg.BuildJob.Resources.Presets[cfg.BuildJob.Resources.DefaultPreset]
+	if !replicas || !resources || !buildResources {
+		return cfg, errors.New("default presets of the webhook not configured, set --webhook-config")
+	}
+	return cfg, nil
+}
+
+func readManifests(manifests *buildcontext.Manifests, file string) error {
+	var reader io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrapf(err, "while opening %s", file)
+		}
+		defer f.Close()
+		reader = f
+	}
+	return errors.Wrapf(manifests.Read(reader), "while reading %s", file)
+}
+
+func selectFunction(functions []serverlessv1alpha1.Function, name string) (*serverlessv1alpha1.Function, error) {
+	var names []string
+	for i := range functions {
+		if name == "" || functions[i].Name == name {
+			names = append(names, functions[i].Name)
+		}
+	}
+
+	switch {
+	case len(names) == 0 && name != "":
+		return nil, errors.Errorf("function %s not found in the manifest", name)
+	case len(names) == 0:
+		return nil, errors.New("no function found in the manifest")
+	case len(names) > 1:
+		return nil, errors.Errorf("manifest holds functions %s, select one with --name", strings.Join(names, ", "))
+	}
+
+	for i := range functions {
+		if functions[i].Name == names[0] {
+			return &functions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func readDockerfile(manifests *buildcontext.Manifests, file string, rtmConfig runtime.Config) (string, error) {
+	if file != "" {
+		return readOptionalFile(file)
+	}
+	return manifests.Dockerfile(rtmConfig.DockerfileConfigMapName)
+}
+
+func readOptionalFile(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	content, err := ioutil.ReadFile(file)
+	return string(content), errors.Wrapf(err, "while reading %s", file)
+}
+
+// repositoryDir returns the local checkout of the repository of a git Function, cloning it if needed.
+// Credentials of private repositories are read from the same environment variables as in the build Job.
+func repositoryDir(instance *serverlessv1alpha1.Function, flags contextFlags) (string, func(), error) {
+	if flags.sourceDir != "" {
+		return flags.sourceDir, func() {}, nil
+	}
+	if flags.repositoryURL == "" {
+		return "", nil, errors.Errorf("function %s is built from a git repository, set --source-dir or --repository-url", instance.Name)
+	}
+
+	cfg := config{}
+	if err := envconfig.InitWithOptions(&cfg, envconfig.Options{Prefix: envPrefix, AllOptional: true}); err != nil {
+		return "", nil, errors.Wrap(err, "while reading env variables")
+	}
+	cfg.RepositoryUrl = flags.repositoryURL
+	cfg.RepositoryCommit = flags.reference
+	if cfg.RepositoryCommit == "" {
+		cfg.RepositoryCommit = instance.Spec.Reference
+	}
+
+	dir, err := ioutil.TempDir("", "jobinit-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "while creating directory for the repository")
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	log.Printf("Clone repo from url: %s and commit: %s...\n", cfg.RepositoryUrl, cfg.RepositoryCommit)
+	if _, err := git.New().Clone(dir, cfg.getOptions()); err != nil {
+		cleanup()
+		return "", nil, errors.Wrapf(err, "while cloning repository: %s, from commit: %s", cfg.RepositoryUrl, cfg.RepositoryCommit)
+	}
+	return dir, cleanup, nil
+}
+
+func writeContext(buildContext *buildcontext.Context, output string) error {
+	switch {
+	case output == "-":
+		return buildContext.WriteTarball(os.Stdout)
+	case strings.HasSuffix(output, ".tar.gz") || strings.HasSuffix(output, ".tgz"):
+		f, err := os.Create(output)
+		if err != nil {
+			return errors.Wrapf(err, "while creating %s", output)
+		}
+		if err := buildContext.WriteTarball(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	default:
+		return buildContext.WriteDir(output)
+	}
+}
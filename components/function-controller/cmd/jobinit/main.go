@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/kyma-project/kyma/components/function-controller/internal/git"
 	"github.com/pkg/errors"
@@ -21,6 +22,13 @@ type config struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == contextCommand {
+		if err := runContext(os.Args[2:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	log.Println("Start repo fetcher...")
 	cfg := config{}
 	if err := envconfig.InitWithPrefix(&cfg, envPrefix); err != nil {
@@ -11,20 +11,28 @@ import (
 
 	"github.com/vrischmann/envconfig"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/kyma-project/kyma/components/function-controller/internal/buildlog"
 	k8s "github.com/kyma-project/kyma/components/function-controller/internal/controllers/kubernetes"
 	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless"
+	"github.com/kyma-project/kyma/components/function-controller/internal/gitwebhook"
+	"github.com/kyma-project/kyma/components/function-controller/internal/registrygc"
 	internalresource "github.com/kyma-project/kyma/components/function-controller/internal/resource"
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
+const gitEventsBufferSize = 100
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -45,6 +53,7 @@ type config struct {
 	LogLevel                  string `envconfig:"default=info"`
 	Kubernetes                k8s.Config
 	Function                  serverless.FunctionConfig
+	GitWebhook                gitwebhook.Config
 }
 
 func main() {
@@ -96,12 +105,40 @@ func main() {
 		},
 	)
 
-	if err := serverless.NewFunction(resourceClient, ctrl.Log, config.Function, mgr.GetEventRecorderFor(serverlessv1alpha1.FunctionControllerValue)).
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		setupLog.Error(err, "Unable to initialize Kubernetes clientset")
+		os.Exit(1)
+	}
+	buildLogReader := buildlog.NewReader(clientset, config.Function.Build.LogTailLines)
+
+	imageActivity := serverless.NewImageActivity()
+	gitEvents := make(chan event.GenericEvent, gitEventsBufferSize)
+	if err := serverless.NewFunction(resourceClient, ctrl.Log, config.Function, mgr.GetEventRecorderFor(serverlessv1alpha1.FunctionControllerValue), imageActivity, buildLogReader, gitEvents).
 		SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create Function controller")
 		os.Exit(1)
 	}
 
+	if config.GitWebhook.Enabled {
+		if err := mgr.Add(gitwebhook.New(mgr.GetClient(), ctrl.Log, config.GitWebhook, gitEvents)); err != nil {
+			setupLog.Error(err, "unable to create git webhook receiver")
+			os.Exit(1)
+		}
+	}
+
+	if config.Function.ImageGC.Enabled {
+		registrySecret := types.NamespacedName{Namespace: config.Kubernetes.BaseNamespace, Name: config.Function.ImageRegistryDefaultDockerConfigSecretName}
+		var blobCollector serverless.BlobCollector
+		if config.Function.ImageGC.CollectBlobs {
+			blobCollector = registrygc.New(clientset, config.Function.ImageGC.Registry)
+		}
+		if err := mgr.Add(serverless.NewImageCollector(resourceClient, ctrl.Log, config.Function.ImageGC, registrySecret, imageActivity, blobCollector)); err != nil {
+			setupLog.Error(err, "unable to create image garbage collector")
+			os.Exit(1)
+		}
+	}
+
 	if err := k8s.NewConfigMap(mgr.GetClient(), ctrl.Log, config.Kubernetes, configMapSvc).
 		SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create ConfigMap controller")
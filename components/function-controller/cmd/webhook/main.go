@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/vrischmann/envconfig"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -18,13 +19,15 @@ import (
 	"knative.dev/pkg/webhook/resourcesemantics/defaulting"
 	"knative.dev/pkg/webhook/resourcesemantics/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	serverlessv1alhpa1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
 
 var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
-	serverlessv1alhpa1.GroupVersion.WithKind("Function"):      &serverlessv1alhpa1.Function{},
-	serverlessv1alhpa1.GroupVersion.WithKind("GitRepository"): &serverlessv1alhpa1.GitRepository{},
+	serverlessv1alhpa1.GroupVersion.WithKind("Function"):        &serverlessv1alhpa1.Function{},
+	serverlessv1alhpa1.GroupVersion.WithKind("GitRepository"):   &serverlessv1alhpa1.GitRepository{},
+	serverlessv1alhpa1.GroupVersion.WithKind("FunctionRuntime"): &serverlessv1alhpa1.FunctionRuntime{},
 }
 
 type config struct {
@@ -55,11 +58,22 @@ func main() {
 
 	restConfig := ctrl.GetConfigOrDie()
 
+	scheme := runtime.NewScheme()
+	if err := serverlessv1alhpa1.AddToScheme(scheme); err != nil {
+		panic(errors.Wrap(err, "while building scheme"))
+	}
+	// FunctionRuntimes are read directly from the API server, they are looked up only on admission
+	reader, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		panic(errors.Wrap(err, "while creating kubernetes client"))
+	}
+	functionRuntimeGetter := serverlessv1alhpa1.NewFunctionRuntimeGetter(reader)
+
 	sharedmain.WebhookMainWithConfig(ctx, "serverless-webhook",
 		restConfig,
 		certificates.NewController,
 		NewDefaultingAdmissionController(defaultingCfg),
-		NewValidationAdmissionController(validationCfg),
+		NewValidationAdmissionController(validationCfg, functionRuntimeGetter),
 	)
 }
 
@@ -87,7 +101,7 @@ func NewDefaultingAdmissionController(cfg *serverlessv1alhpa1.DefaultingConfig)
 	}
 }
 
-func NewValidationAdmissionController(cfg *serverlessv1alhpa1.ValidationConfig) func(ctx context.Context, _ configmap.Watcher) *controller.Impl {
+func NewValidationAdmissionController(cfg *serverlessv1alhpa1.ValidationConfig, functionRuntimeGetter serverlessv1alhpa1.FunctionRuntimeGetter) func(ctx context.Context, _ configmap.Watcher) *controller.Impl {
 	return func(ctx context.Context, _ configmap.Watcher) *controller.Impl {
 		return validation.NewAdmissionController(ctx,
 
@@ -102,7 +116,8 @@ func NewValidationAdmissionController(cfg *serverlessv1alhpa1.ValidationConfig)
 
 			// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
 			func(ctx context.Context) context.Context {
-				return context.WithValue(ctx, serverlessv1alhpa1.ValidationConfigKey, *cfg)
+				ctx = context.WithValue(ctx, serverlessv1alhpa1.ValidationConfigKey, *cfg)
+				return context.WithValue(ctx, serverlessv1alhpa1.FunctionRuntimeGetterKey, functionRuntimeGetter)
 			},
 
 			// Whether to disallow unknown fields.
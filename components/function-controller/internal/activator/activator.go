@@ -0,0 +1,351 @@
+package activator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+type Config struct {
+	// Namespace is the namespace of the activator, which holds the routes of the functions
+	Namespace              string        `envconfig:"default=kyma-system"`
+	RouteSyncInterval      time.Duration `envconfig:"default=5s"`
+	ActivationTimeout      time.Duration `envconfig:"default=2m"`
+	ActivationPollInterval time.Duration `envconfig:"default=500ms"`
+	ActivityReportInterval time.Duration `envconfig:"default=30s"`
+	ReadyServiceTTL        time.Duration `envconfig:"default=5s"`
+}
+
+// Activator receives all requests sent to functions with minReplicas 0, also while they run. It reports the request
+// time to the Function, so that Function Controller scales the function up or keeps it running, waits for a ready
+// replica and forwards the request to it.
+// Function Controller maps every such function to a port of the activator with a route Service, the activator
+// serves the function on that port, so the requests themselves are not trusted to name the function.
+type Activator struct {
+	client client.Client
+	log    logr.Logger
+	config Config
+	server *http.Server
+
+	// targetURL returns the address of the given private Service of a function
+	targetURL func(service *corev1.Service) *url.URL
+	// listen opens the listener of the given port
+	listen func(port int32) (net.Listener, error)
+	// now returns the time requests are received at
+	now func() time.Time
+
+	routesMu  sync.RWMutex
+	routes    map[int32]types.NamespacedName
+	listeners map[int32]net.Listener
+
+	reportsMu sync.Mutex
+	reports   map[types.NamespacedName]time.Time
+
+	readyMu sync.Mutex
+	// ready caches the private Services of running functions for ReadyServiceTTL, so that the requests of a running
+	// function are forwarded without reading its endpoints every time
+	ready map[types.NamespacedName]readyService
+}
+
+type readyService struct {
+	service *corev1.Service
+	expires time.Time
+}
+
+func New(client client.Client, log logr.Logger, config Config) *Activator {
+	a := &Activator{
+		client:    client,
+		log:       log.WithName("activator"),
+		config:    config,
+		targetURL: serviceURL,
+		listen:    listenTCP,
+		now:       time.Now,
+		routes:    map[int32]types.NamespacedName{},
+		listeners: map[int32]net.Listener{},
+		reports:   map[types.NamespacedName]time.Time{},
+		ready:     map[types.NamespacedName]readyService{},
+	}
+	a.server = &http.Server{Handler: a}
+	return a
+}
+
+// Start serves the routed functions until the stop channel is closed, the routes are synced every RouteSyncInterval
+func (a *Activator) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	wait.Until(func() {
+		if err := a.syncRoutes(ctx); err != nil {
+			a.log.Error(err, "while syncing routes")
+		}
+	}, a.config.RouteSyncInterval, stop)
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	return a.server.Shutdown(shutdownCtx)
+}
+
+// syncRoutes maps the ports of the route Services to their functions, it opens the listeners of new ports
+// and closes the listeners of removed ones. A port routed twice belongs to the older route, like in Function Controller.
+func (a *Activator) syncRoutes(ctx context.Context) error {
+	var services corev1.ServiceList
+	if err := a.client.List(ctx, &services, client.InNamespace(a.config.Namespace), client.MatchingLabels{
+		serverlessv1alpha1.FunctionResourceLabel: serverlessv1alpha1.FunctionResourceLabelActivatorRouteValue,
+	}); err != nil {
+		return errors.Wrap(err, "while listing routes")
+	}
+
+	sort.Slice(services.Items, func(i, j int) bool {
+		created, otherCreated := services.Items[i].GetCreationTimestamp(), services.Items[j].GetCreationTimestamp()
+		if !created.Equal(&otherCreated) {
+			return created.Before(&otherCreated)
+		}
+		return services.Items[i].GetName() < services.Items[j].GetName()
+	})
+
+	routes := map[int32]types.NamespacedName{}
+	for _, service := range services.Items {
+		if len(service.Spec.Ports) == 0 {
+			continue
+		}
+		port := service.Spec.Ports[0].TargetPort.IntVal
+		if _, ok := routes[port]; ok || port == 0 {
+			continue
+		}
+		labels := service.GetLabels()
+		routes[port] = types.NamespacedName{
+			Namespace: labels[serverlessv1alpha1.FunctionNamespaceLabel],
+			Name:      labels[serverlessv1alpha1.FunctionNameLabel],
+		}
+	}
+
+	a.routesMu.Lock()
+	defer a.routesMu.Unlock()
+
+	a.routes = routes
+	for port, listener := range a.listeners {
+		if _, ok := routes[port]; ok {
+			continue
+		}
+		if err := listener.Close(); err != nil {
+			a.log.Error(err, "while closing listener", "port", port)
+		}
+		delete(a.listeners, port)
+	}
+
+	for port := range routes {
+		if _, ok := a.listeners[port]; ok {
+			continue
+		}
+		listener, err := a.listen(port)
+		if err != nil {
+			a.log.Error(err, "while opening listener", "port", port)
+			continue
+		}
+		a.listeners[port] = listener
+		go a.serve(listener)
+	}
+
+	return nil
+}
+
+func (a *Activator) serve(listener net.Listener) {
+	if err := a.server.Serve(listener); err != nil && err != http.ErrServerClosed && !errors.Is(err, net.ErrClosed) {
+		a.log.Error(err, "while serving", "address", listener.Addr().String())
+	}
+}
+
+// functionFromRequest returns the function routed to the local port the request was received on
+func (a *Activator) functionFromRequest(req *http.Request) (types.NamespacedName, bool) {
+	addr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+
+	a.routesMu.RLock()
+	defer a.routesMu.RUnlock()
+	function, ok := a.routes[int32(tcpAddr.Port)]
+	return function, ok
+}
+
+func (a *Activator) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	function, ok := a.functionFromRequest(req)
+	if !ok {
+		http.Error(w, "no function is routed to this port", http.StatusNotFound)
+		return
+	}
+	log := a.log.WithValues("namespace", function.Namespace, "name", function.Name)
+
+	if err := a.reportActivity(req.Context(), function, a.now()); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("function %s not found", function), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "while reporting function activity")
+		http.Error(w, "unable to activate the function", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), a.config.ActivationTimeout)
+	defer cancel()
+
+	service, err := a.waitForReadyService(ctx, function)
+	if err != nil {
+		log.Error(err, "while waiting for the function to become ready")
+		http.Error(w, "function is not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	target := a.targetURL(service)
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.Host = target.Host
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			log.Error(err, "while forwarding the request to the function")
+			a.forgetReadyService(function)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, req)
+}
+
+// reportActivity sets the time of the request in the Function annotation. A running function is reported
+// at most once per ActivityReportInterval, which has to be shorter than the minimal idle timeout of functions.
+func (a *Activator) reportActivity(ctx context.Context, function types.NamespacedName, now time.Time) error {
+	a.reportsMu.Lock()
+	last, reported := a.reports[function]
+	if reported && now.Sub(last) < a.config.ActivityReportInterval {
+		a.reportsMu.Unlock()
+		return nil
+	}
+	a.reports[function] = now
+	a.reportsMu.Unlock()
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+		serverlessv1alpha1.FunctionLastRequestTimeAnnotation, now.UTC().Format(time.RFC3339)))
+
+	instance := &serverlessv1alpha1.Function{}
+	instance.SetNamespace(function.Namespace)
+	instance.SetName(function.Name)
+	if err := a.client.Patch(ctx, instance, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		a.reportsMu.Lock()
+		delete(a.reports, function)
+		a.reportsMu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// waitForReadyService returns the private Service of the function once it has a ready endpoint
+func (a *Activator) waitForReadyService(ctx context.Context, function types.NamespacedName) (*corev1.Service, error) {
+	a.readyMu.Lock()
+	cached, ok := a.ready[function]
+	a.readyMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.service, nil
+	}
+
+	ticker := time.NewTicker(a.config.ActivationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		service, err := a.readyService(ctx, function)
+		if err != nil {
+			return nil, err
+		}
+		if service != nil {
+			a.readyMu.Lock()
+			a.ready[function] = readyService{service: service, expires: time.Now().Add(a.config.ReadyServiceTTL)}
+			a.readyMu.Unlock()
+			return service, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "while waiting for a ready endpoint")
+		case <-ticker.C:
+		}
+	}
+}
+
+// forgetReadyService drops the cached private Service of a function which did not respond, e.g. after it was scaled
+// to zero, so that the next request waits for a ready endpoint again
+func (a *Activator) forgetReadyService(function types.NamespacedName) {
+	a.readyMu.Lock()
+	defer a.readyMu.Unlock()
+	delete(a.ready, function)
+}
+
+func (a *Activator) readyService(ctx context.Context, function types.NamespacedName) (*corev1.Service, error) {
+	var services corev1.ServiceList
+	if err := a.client.List(ctx, &services, client.InNamespace(function.Namespace), client.MatchingLabels{
+		serverlessv1alpha1.FunctionNameLabel:     function.Name,
+		serverlessv1alpha1.FunctionResourceLabel: serverlessv1alpha1.FunctionResourceLabelPrivateServiceValue,
+	}); err != nil {
+		return nil, errors.Wrap(err, "while listing private services")
+	}
+
+	for i := range services.Items {
+		var endpoints corev1.Endpoints
+		err := a.client.Get(ctx, client.ObjectKey{Namespace: function.Namespace, Name: services.Items[i].Name}, &endpoints)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "while getting endpoints")
+		}
+
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				return &services.Items[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func listenTCP(port int32) (net.Listener, error) {
+	return net.Listen("tcp", fmt.Sprintf(":%d", port))
+}
+
+func serviceURL(service *corev1.Service) *url.URL {
+	port := int32(80)
+	if len(service.Spec.Ports) > 0 {
+		port = service.Spec.Ports[0].Port
+	}
+	return &url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, port),
+	}
+}
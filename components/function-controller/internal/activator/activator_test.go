@@ -0,0 +1,267 @@
+package activator
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func TestActivator_ServeHTTP(t *testing.T) {
+	function := &serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	privateService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-abcde",
+			Namespace: "default",
+			Labels: map[string]string{
+				serverlessv1alpha1.FunctionNameLabel:     "test",
+				serverlessv1alpha1.FunctionResourceLabel: serverlessv1alpha1.FunctionResourceLabelPrivateServiceValue,
+			},
+		},
+	}
+	readyEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-abcde", Namespace: "default"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+	notReadyEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-abcde", Namespace: "default"},
+		Subsets:    []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+
+	testCases := []struct {
+		name       string
+		port       int
+		objects    []runtime.Object
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "forwards the request to a ready function",
+			port:       10000,
+			objects:    []runtime.Object{function.DeepCopy(), privateService.DeepCopy(), readyEndpoints.DeepCopy()},
+			wantStatus: http.StatusOK,
+			wantBody:   "hello from /path",
+		},
+		{
+			name:       "returns 503 for a function that does not become ready",
+			port:       10000,
+			objects:    []runtime.Object{function.DeepCopy(), privateService.DeepCopy(), notReadyEndpoints.DeepCopy()},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "returns 404 for a missing function",
+			port:       10001,
+			objects:    []runtime.Object{function.DeepCopy()},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "returns 404 for a port without route",
+			port:       10002,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "hello from %s", r.URL.Path)
+			}))
+			defer server.Close()
+			serverURL, err := url.Parse(server.URL)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			activator := New(fake.NewFakeClientWithScheme(newScheme(g), testCase.objects...), zap.New(), Config{
+				ActivationTimeout:      100 * time.Millisecond,
+				ActivationPollInterval: 10 * time.Millisecond,
+				ActivityReportInterval: time.Minute,
+			})
+			activator.targetURL = func(*corev1.Service) *url.URL { return serverURL }
+			activator.routes = map[int32]types.NamespacedName{
+				10000: {Namespace: "default", Name: "test"},
+				10001: {Namespace: "default", Name: "missing"},
+			}
+
+			// the host names another function, only the port the request is received on matters
+			req := httptest.NewRequest(http.MethodGet, "/path", nil)
+			req.Host = "other.default.svc.cluster.local"
+			req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, &net.TCPAddr{IP: net.IPv4zero, Port: testCase.port}))
+			res := httptest.NewRecorder()
+
+			activator.ServeHTTP(res, req)
+
+			g.Expect(res.Code).To(gomega.Equal(testCase.wantStatus))
+			if testCase.wantBody != "" {
+				body, err := ioutil.ReadAll(res.Body)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(string(body)).To(gomega.Equal(testCase.wantBody))
+			}
+		})
+	}
+}
+
+func TestActivator_reportActivity(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	key := types.NamespacedName{Name: "test", Namespace: "default"}
+	function := &serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	k8sClient := fake.NewFakeClientWithScheme(newScheme(g), function)
+	activator := New(k8sClient, zap.New(), Config{ActivityReportInterval: time.Minute})
+
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	g.Expect(activator.reportActivity(context.TODO(), key, now)).To(gomega.Succeed())
+	g.Expect(lastRequestTimeAnnotation(g, k8sClient, key)).To(gomega.Equal("2021-05-01T12:00:00Z"))
+
+	// requests within the report interval are not reported again
+	g.Expect(activator.reportActivity(context.TODO(), key, now.Add(30*time.Second))).To(gomega.Succeed())
+	g.Expect(lastRequestTimeAnnotation(g, k8sClient, key)).To(gomega.Equal("2021-05-01T12:00:00Z"))
+
+	g.Expect(activator.reportActivity(context.TODO(), key, now.Add(2*time.Minute))).To(gomega.Succeed())
+	g.Expect(lastRequestTimeAnnotation(g, k8sClient, key)).To(gomega.Equal("2021-05-01T12:02:00Z"))
+}
+
+func TestActivator_ServeHTTP_trafficToRunningFunction(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	key := types.NamespacedName{Name: "test", Namespace: "default"}
+	function := &serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	privateService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-abcde",
+			Namespace: "default",
+			Labels: map[string]string{
+				serverlessv1alpha1.FunctionNameLabel:     "test",
+				serverlessv1alpha1.FunctionResourceLabel: serverlessv1alpha1.FunctionResourceLabelPrivateServiceValue,
+			},
+		},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-abcde", Namespace: "default"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+
+	var forwarded int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded++
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	k8sClient := fake.NewFakeClientWithScheme(newScheme(g), function, privateService, endpoints)
+	activator := New(k8sClient, zap.New(), Config{
+		ActivationTimeout:      100 * time.Millisecond,
+		ActivationPollInterval: 10 * time.Millisecond,
+		ActivityReportInterval: 30 * time.Second,
+		ReadyServiceTTL:        time.Hour,
+	})
+	activator.targetURL = func(*corev1.Service) *url.URL { return serverURL }
+	activator.routes = map[int32]types.NamespacedName{10000: key}
+
+	// a request every 20 seconds for 20 minutes, which is longer than any idle timeout of the function
+	start := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	for now := start; now.Before(start.Add(20 * time.Minute)); now = now.Add(20 * time.Second) {
+		activator.now = func() time.Time { return now }
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, &net.TCPAddr{IP: net.IPv4zero, Port: 10000}))
+		res := httptest.NewRecorder()
+		activator.ServeHTTP(res, req)
+		g.Expect(res.Code).To(gomega.Equal(http.StatusOK))
+
+		// the last request time never lags behind by more than the report interval
+		reported, err := time.Parse(time.RFC3339, lastRequestTimeAnnotation(g, k8sClient, key))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(now.Sub(reported)).To(gomega.BeNumerically("<", 30*time.Second))
+
+		// the ready Service is cached, so the next requests are forwarded without reading the endpoints
+		if now.Equal(start) {
+			g.Expect(k8sClient.Delete(context.TODO(), endpoints.DeepCopy())).To(gomega.Succeed())
+		}
+	}
+	g.Expect(forwarded).To(gomega.Equal(60))
+}
+
+func TestActivator_syncRoutes(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	route := func(name, function string, port int, created time.Time) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "kyma-system",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					serverlessv1alpha1.FunctionNameLabel:      function,
+					serverlessv1alpha1.FunctionNamespaceLabel: "default",
+					serverlessv1alpha1.FunctionResourceLabel:  serverlessv1alpha1.FunctionResourceLabelActivatorRouteValue,
+				},
+			},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(port)}}},
+		}
+	}
+	k8sClient := fake.NewFakeClientWithScheme(newScheme(g),
+		route("serverless-activator-a", "first", 10000, now),
+		// the port was allocated twice, it belongs to the older route
+		route("serverless-activator-b", "second", 10000, now.Add(time.Second)),
+		route("serverless-activator-c", "third", 10001, now),
+	)
+
+	activator := New(k8sClient, zap.New(), Config{Namespace: "kyma-system"})
+	var listened []int32
+	activator.listen = func(port int32) (net.Listener, error) {
+		listened = append(listened, port)
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	defer activator.server.Close()
+
+	g.Expect(activator.syncRoutes(context.TODO())).To(gomega.Succeed())
+	g.Expect(activator.routes).To(gomega.Equal(map[int32]types.NamespacedName{
+		10000: {Namespace: "default", Name: "first"},
+		10001: {Namespace: "default", Name: "third"},
+	}))
+	g.Expect(listened).To(gomega.ConsistOf(int32(10000), int32(10001)))
+
+	// the listeners of removed routes are closed and the others are kept
+	g.Expect(k8sClient.Delete(context.TODO(), route("serverless-activator-c", "third", 10001, now))).To(gomega.Succeed())
+	closed := activator.listeners[10001]
+	g.Expect(activator.syncRoutes(context.TODO())).To(gomega.Succeed())
+	g.Expect(activator.routes).To(gomega.HaveLen(1))
+	g.Expect(activator.listeners).To(gomega.HaveLen(1))
+	g.Expect(listened).To(gomega.HaveLen(2))
+	_, err := closed.Accept()
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func newScheme(g *gomega.WithT) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(serverlessv1alpha1.AddToScheme(scheme)).To(gomega.Succeed())
+	return scheme
+}
+
+func lastRequestTimeAnnotation(g *gomega.WithT, k8sClient client.Client, key types.NamespacedName) string {
+	function := &serverlessv1alpha1.Function{}
+	g.Expect(k8sClient.Get(context.TODO(), key, function)).To(gomega.Succeed())
+	return function.GetAnnotations()[serverlessv1alpha1.FunctionLastRequestTimeAnnotation]
+}
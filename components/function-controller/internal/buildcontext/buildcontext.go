@@ -0,0 +1,180 @@
+package buildcontext
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+// Paths in the build context, the build Jobs mount the same files into their workspace
+const (
+	DockerfilePath    = "Dockerfile"
+	SourceDir         = "src"
+	RegistryConfigDir = "registry-config"
+)
+
+// Context is the build context of a Function, the same directory tree Kaniko builds the Function image from
+type Context struct {
+	// files are generated files by their path in the context
+	files map[string]string
+	// sourceDir is a local directory copied to the src directory of the context, it is set for git Functions
+	sourceDir string
+}
+
+// ForInline returns the build context of an inline Function: the Dockerfile of the runtime, the source, the sanitized
+// dependencies and the additional files of the Function. The package registry configuration is optional. The Function
+// is expected to be defaulted and validated, still additional files outside of the src directory are refused.
+func ForInline(instance *serverlessv1alpha1.Function, rtmConfig runtime.Config, rtm runtime.Runtime, dockerfile, packageRegistryConfig string) (*Context, error) {
+	if instance.Spec.Type == serverlessv1alpha1.SourceTypeGit {
+		return nil, errors.Errorf("function %s is built from a git repository", instance.Name)
+	}
+
+	files := baseFiles(rtmConfig, dockerfile, packageRegistryConfig)
+	files[path.Join(SourceDir, rtmConfig.FunctionFile)] = instance.Spec.Source
+	files[path.Join(SourceDir, rtmConfig.DependencyFile)] = rtm.SanitizeDependencies(instance.Spec.Deps)
+	for filePath, content := range instance.Spec.Files {
+		contextPath := path.Join(SourceDir, filePath)
+		if path.IsAbs(filePath) || !strings.HasPrefix(contextPath, SourceDir+"/") {
+			return nil, errors.Errorf("file %s of function %s is outside of the source directory", filePath, instance.Name)
+		}
+		files[contextPath] = content
+	}
+
+	return &Context{files: files}, nil
+}
+
+// ForRepository returns the build context of a git Function from a local checkout of its repository.
+// The base directory of the Function becomes the src directory of the context.
+func ForRepository(instance *serverlessv1alpha1.Function, repositoryDir string, rtmConfig runtime.Config, dockerfile, packageRegistryConfig string) (*Context, error) {
+	if instance.Spec.Type != serverlessv1alpha1.SourceTypeGit {
+		return nil, errors.Errorf("function %s is not built from a git repository", instance.Name)
+	}
+
+	baseDir := filepath.Join(repositoryDir, filepath.FromSlash(path.Clean("/"+instance.Spec.BaseDir)))
+	info, err := os.Stat(baseDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while reading base directory %s", instance.Spec.BaseDir)
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("base directory %s is not a directory", instance.Spec.BaseDir)
+	}
+
+	return &Context{
+		files:     baseFiles(rtmConfig, dockerfile, packageRegistryConfig),
+		sourceDir: baseDir,
+	}, nil
+}
+
+func baseFiles(rtmConfig runtime.Config, dockerfile, packageRegistryConfig string) map[string]string {
+	files := map[string]string{DockerfilePath: dockerfile}
+	// the Dockerfiles copy the registry configuration, so the file is created even if there is no configuration
+	if rtmConfig.PackageRegistryConfigFile != "" {
+		files[path.Join(RegistryConfigDir, rtmConfig.PackageRegistryConfigFile)] = packageRegistryConfig
+	}
+	return files
+}
+
+// Paths returns the paths of the files in the context, sorted
+func (c *Context) Paths() ([]string, error) {
+	var paths []string
+	err := c.walk(func(filePath string, _ os.FileMode, _ []byte) error {
+		paths = append(paths, filePath)
+		return nil
+	})
+	sort.Strings(paths)
+	return paths, err
+}
+
+// WriteDir writes the context to the given directory, which is created if it does not exist. Files which would be
+// written outside of the directory are refused.
+func (c *Context) WriteDir(dir string) error {
+	return c.walk(func(filePath string, mode os.FileMode, content []byte) error {
+		target := filepath.Join(dir, filepath.FromSlash(filePath))
+		if relative, err := filepath.Rel(dir, target); err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return errors.Errorf("file %s is outside of the context directory", filePath)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return errors.Wrapf(err, "while creating directory of %s", filePath)
+		}
+		return errors.Wrapf(ioutil.WriteFile(target, content, mode), "while writing %s", filePath)
+	})
+}
+
+// WriteTarball writes the context as a gzipped tarball, which can be built with docker build - < context.tar.gz
+// or Kaniko with --context=tar://context.tar.gz
+func (c *Context) WriteTarball(w io.Writer) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := c.walk(func(filePath string, mode os.FileMode, content []byte) error {
+		header := &tar.Header{
+			Name:    filePath,
+			Mode:    int64(mode),
+			Size:    int64(len(content)),
+			ModTime: time.Unix(0, 0),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "while writing header of %s", filePath)
+		}
+		_, err := tarWriter.Write(content)
+		return errors.Wrapf(err, "while writing %s", filePath)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrap(err, "while closing tarball")
+	}
+	return errors.Wrap(gzipWriter.Close(), "while closing tarball")
+}
+
+// walk calls fn for the generated files in the order of their paths and then for the files of the source directory
+func (c *Context) walk(fn func(filePath string, mode os.FileMode, content []byte) error) error {
+	paths := make([]string, 0, len(c.files))
+	for filePath := range c.files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	for _, filePath := range paths {
+		if err := fn(filePath, 0644, []byte(c.files[filePath])); err != nil {
+			return err
+		}
+	}
+
+	if c.sourceDir == "" {
+		return nil
+	}
+	return filepath.Walk(c.sourceDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// symbolic links and other special files are not copied to the context
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(c.sourceDir, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return errors.Wrapf(err, "while reading %s", relative)
+		}
+		return fn(path.Join(SourceDir, filepath.ToSlash(relative)), info.Mode().Perm(), content)
+	})
+}
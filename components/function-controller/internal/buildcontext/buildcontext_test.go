@@ -0,0 +1,164 @@
+package buildcontext
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func TestForInline(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	instance := &serverlessv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "fn"},
+		Spec: serverlessv1alpha1.FunctionSpec{
+			Source: "module.exports = {}",
+			Files:  map[string]string{"lib/helper.js": "helper"},
+		},
+	}
+	rtmConfig := runtime.GetRuntimeConfig(serverlessv1alpha1.Nodejs14)
+
+	buildContext, err := ForInline(instance, rtmConfig, runtime.GetRuntime(serverlessv1alpha1.DependencySanitizerNodejs), "FROM node", "")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	dir, err := ioutil.TempDir("", "buildcontext-")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer os.RemoveAll(dir)
+	g.Expect(buildContext.WriteDir(dir)).To(gomega.Succeed())
+
+	g.Expect(readFiles(t, dir)).To(gomega.Equal(map[string]string{
+		"Dockerfile":             "FROM node",
+		"registry-config/.npmrc": "",
+		"src/handler.js":         "module.exports = {}",
+		"src/package.json":       "{}",
+		"src/lib/helper.js":      "helper",
+	}))
+
+	instance.Spec.Type = serverlessv1alpha1.SourceTypeGit
+	_, err = ForInline(instance, rtmConfig, runtime.GetRuntime(serverlessv1alpha1.DependencySanitizerNodejs), "FROM node", "")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestForInline_fileOutsideOfSourceDir(t *testing.T) {
+	rtmConfig := runtime.GetRuntimeConfig(serverlessv1alpha1.Nodejs14)
+
+	for _, filePath := range []string{"../../.bashrc", "../Dockerfile", "/etc/passwd", "lib/../../Dockerfile", "."} {
+		t.Run(filePath, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				ObjectMeta: metav1.ObjectMeta{Name: "fn"},
+				Spec: serverlessv1alpha1.FunctionSpec{
+					Source: "module.exports = {}",
+					Files:  map[string]string{filePath: "overwritten"},
+				},
+			}
+
+			_, err := ForInline(instance, rtmConfig, runtime.GetRuntime(serverlessv1alpha1.DependencySanitizerNodejs), "FROM node", "")
+			g.Expect(err).To(gomega.HaveOccurred())
+		})
+	}
+}
+
+func TestContext_WriteDir_fileOutsideOfDir(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	parent, err := ioutil.TempDir("", "buildcontext-")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer os.RemoveAll(parent)
+	dir := filepath.Join(parent, "context")
+
+	buildContext := &Context{files: map[string]string{"../outside": "overwritten"}}
+	g.Expect(buildContext.WriteDir(dir)).NotTo(gomega.Succeed())
+	g.Expect(filepath.Join(parent, "outside")).NotTo(gomega.BeAnExistingFile())
+}
+
+func TestForRepository(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	repository, err := ioutil.TempDir("", "repository-")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer os.RemoveAll(repository)
+	writeFile(t, filepath.Join(repository, "README.md"), "readme")
+	writeFile(t, filepath.Join(repository, "fn", "handler.py"), "def main(): pass")
+	writeFile(t, filepath.Join(repository, "fn", "requirements.txt"), "requests")
+
+	instance := &serverlessv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "fn"},
+		Spec: serverlessv1alpha1.FunctionSpec{
+			Type:       serverlessv1alpha1.SourceTypeGit,
+			Repository: serverlessv1alpha1.Repository{BaseDir: "/fn"},
+		},
+	}
+	rtmConfig := runtime.GetRuntimeConfig(serverlessv1alpha1.Python39)
+
+	buildContext, err := ForRepository(instance, repository, rtmConfig, "FROM python", "[global]")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	var tarball bytes.Buffer
+	g.Expect(buildContext.WriteTarball(&tarball)).To(gomega.Succeed())
+	g.Expect(readTarball(t, &tarball)).To(gomega.Equal(map[string]string{
+		"Dockerfile":               "FROM python",
+		"registry-config/pip.conf": "[global]",
+		"src/handler.py":           "def main(): pass",
+		"src/requirements.txt":     "requests",
+	}))
+
+	instance.Spec.BaseDir = "/missing"
+	_, err = ForRepository(instance, repository, rtmConfig, "FROM python", "")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func writeFile(t *testing.T, file, content string) {
+	g := gomega.NewWithT(t)
+	g.Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(gomega.Succeed())
+	g.Expect(ioutil.WriteFile(file, []byte(content), 0644)).To(gomega.Succeed())
+}
+
+func readFiles(t *testing.T, dir string) map[string]string {
+	g := gomega.NewWithT(t)
+	files := map[string]string{}
+	g.Expect(filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, file)
+		files[filepath.ToSlash(relative)] = string(content)
+		return err
+	})).To(gomega.Succeed())
+	return files
+}
+
+func readTarball(t *testing.T, reader io.Reader) map[string]string {
+	g := gomega.NewWithT(t)
+	gzipReader, err := gzip.NewReader(reader)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	files := map[string]string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		content, err := ioutil.ReadAll(tarReader)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		files[header.Name] = string(content)
+	}
+}
@@ -0,0 +1,127 @@
+package buildcontext
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+const (
+	dockerfileKey = "Dockerfile"
+	// webhookEnvPrefix is the prefix of the defaulting and validation configuration in the ConfigMap of the webhook
+	webhookEnvPrefix = "WEBHOOK_"
+)
+
+// Manifests are the objects a build context is assembled from, read from local YAML or JSON manifests
+// instead of the cluster, e.g. from the output of kubectl get configmaps,functionruntimes -o yaml
+type Manifests struct {
+	Functions        []serverlessv1alpha1.Function
+	FunctionRuntimes map[string]serverlessv1alpha1.FunctionRuntime
+	// Dockerfiles are the Dockerfiles of the runtime ConfigMaps by the name of the ConfigMap
+	Dockerfiles map[string]string
+	// WebhookEnvs are the environment variables of the webhook read from its ConfigMap, they configure the defaulting
+	// and the validation of Functions
+	WebhookEnvs map[string]string
+}
+
+func NewManifests() *Manifests {
+	return &Manifests{
+		FunctionRuntimes: map[string]serverlessv1alpha1.FunctionRuntime{},
+		Dockerfiles:      map[string]string{},
+		WebhookEnvs:      map[string]string{},
+	}
+}
+
+// Read adds the Functions, FunctionRuntimes and runtime ConfigMaps from a stream of YAML or JSON documents,
+// lists are read item by item and other objects are ignored
+func (m *Manifests) Read(reader io.Reader) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		var object unstructured.Unstructured
+		if err := decoder.Decode(&object.Object); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "while decoding manifest")
+		}
+		if object.Object == nil {
+			continue
+		}
+
+		if object.IsList() {
+			list, err := object.ToList()
+			if err != nil {
+				return errors.Wrap(err, "while decoding list")
+			}
+			for i := range list.Items {
+				if err := m.add(list.Items[i]); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := m.add(object); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *Manifests) add(object unstructured.Unstructured) error {
+	gvk := object.GroupVersionKind()
+	switch {
+	case gvk == serverlessv1alpha1.GroupVersion.WithKind("Function"):
+		var function serverlessv1alpha1.Function
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &function); err != nil {
+			return errors.Wrapf(err, "while decoding Function %s", object.GetName())
+		}
+		m.Functions = append(m.Functions, function)
+	case gvk == serverlessv1alpha1.GroupVersion.WithKind("FunctionRuntime"):
+		var functionRuntime serverlessv1alpha1.FunctionRuntime
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &functionRuntime); err != nil {
+			return errors.Wrapf(err, "while decoding FunctionRuntime %s", object.GetName())
+		}
+		m.FunctionRuntimes[functionRuntime.Name] = functionRuntime
+	case gvk == corev1.SchemeGroupVersion.WithKind("ConfigMap"):
+		var configMap corev1.ConfigMap
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &configMap); err != nil {
+			return errors.Wrapf(err, "while decoding ConfigMap %s", object.GetName())
+		}
+		if dockerfile, ok := configMap.Data[dockerfileKey]; ok {
+			m.Dockerfiles[configMap.Name] = dockerfile
+		}
+		for key, value := range configMap.Data {
+			if strings.HasPrefix(key, webhookEnvPrefix) {
+				m.WebhookEnvs[key] = value
+			}
+		}
+	}
+	return nil
+}
+
+// FunctionRuntimeGetter resolves runtimes with the FunctionRuntimes read from the manifests
+func (m *Manifests) FunctionRuntimeGetter() serverlessv1alpha1.FunctionRuntimeGetter {
+	return func(_ context.Context, name string) (*serverlessv1alpha1.FunctionRuntime, error) {
+		functionRuntime, ok := m.FunctionRuntimes[name]
+		if !ok {
+			return nil, nil
+		}
+		return &functionRuntime, nil
+	}
+}
+
+// Dockerfile returns the Dockerfile of the runtime ConfigMap with the given name
+func (m *Manifests) Dockerfile(configMapName string) (string, error) {
+	dockerfile, ok := m.Dockerfiles[configMapName]
+	if !ok {
+		return "", errors.Errorf("ConfigMap %s with the Dockerfile of the runtime not found in the manifests", configMapName)
+	}
+	return dockerfile, nil
+}
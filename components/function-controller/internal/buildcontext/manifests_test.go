@@ -0,0 +1,99 @@
+package buildcontext
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+const testManifests = `# ConfigMaps of the runtimes
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dockerfile-nodejs-14
+data:
+  Dockerfile: FROM node
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: serverless-webhook-envs
+data:
+  WEBHOOK_PORT: "8443"
+  WEBHOOK_DEFAULTING_FUNCTION_REPLICAS_DEFAULT_PRESET: S
+---
+apiVersion: serverless.kyma-project.io/v1alpha1
+kind: Function
+metadata:
+  name: fn
+spec:
+  runtime: go116
+  source: package main
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: serverless.kyma-project.io/v1alpha1
+  kind: FunctionRuntime
+  metadata:
+    name: go116
+  spec:
+    dependencyFile: go.mod
+    functionFile: handler.go
+    dockerfileConfigMapName: dockerfile-go-116
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: dockerfile-go-116
+  data:
+    Dockerfile: FROM golang
+`
+
+func TestManifests_Read(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	manifests := NewManifests()
+	g.Expect(manifests.Read(strings.NewReader(testManifests))).To(gomega.Succeed())
+
+	g.Expect(manifests.Functions).To(gomega.HaveLen(1))
+	g.Expect(manifests.Functions[0].Name).To(gomega.Equal("fn"))
+	g.Expect(manifests.Functions[0].Spec.Source).To(gomega.Equal("package main"))
+	g.Expect(manifests.Dockerfiles).To(gomega.Equal(map[string]string{
+		"dockerfile-nodejs-14": "FROM node",
+		"dockerfile-go-116":    "FROM golang",
+	}))
+	g.Expect(manifests.WebhookEnvs).To(gomega.Equal(map[string]string{
+		"WEBHOOK_PORT": "8443",
+		"WEBHOOK_DEFAULTING_FUNCTION_REPLICAS_DEFAULT_PRESET": "S",
+	}))
+
+	functionRuntime, err := manifests.FunctionRuntimeGetter()(context.TODO(), "go116")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(functionRuntime.Spec.DockerfileConfigMapName).To(gomega.Equal("dockerfile-go-116"))
+	functionRuntime, err = manifests.FunctionRuntimeGetter()(context.TODO(), "nodejs14")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(functionRuntime).To(gomega.BeNil())
+
+	dockerfile, err := manifests.Dockerfile("dockerfile-go-116")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(dockerfile).To(gomega.Equal("FROM golang"))
+	_, err = manifests.Dockerfile("dockerfile-python-39")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestManifests_Read_invalid(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	manifests := NewManifests()
+	g.Expect(manifests.Read(strings.NewReader("apiVersion: v1\nkind: [ConfigMap"))).NotTo(gomega.Succeed())
+}
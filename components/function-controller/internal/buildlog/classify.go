@@ -0,0 +1,98 @@
+package buildlog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reason classifies the failure of a Function build
+type Reason string
+
+const (
+	ReasonOutOfMemory        Reason = "OutOfMemory"
+	ReasonRegistryPushDenied Reason = "RegistryPushDenied"
+	ReasonSyntaxError        Reason = "SyntaxError"
+	ReasonDependencyInstall  Reason = "DependencyInstallFailed"
+	ReasonUnknown            Reason = "BuildFailed"
+)
+
+const (
+	oomKilledReason = "OOMKilled"
+	maxLineLength   = 256
+)
+
+// patterns are checked in order, the first reason with a line matching one of its patterns wins. Registry errors are
+// checked before the others, as kaniko pushes the image only after all build steps succeeded.
+var patterns = []struct {
+	reason  Reason
+	pattern *regexp.Regexp
+}{
+	{reason: ReasonOutOfMemory, pattern: regexp.MustCompile(`JavaScript heap out of memory|\bMemoryError\b|Cannot allocate memory`)},
+	{reason: ReasonRegistryPushDenied, pattern: regexp.MustCompile(`(?i)error pushing image|failed to push to destination|error checking push permissions`)},
+	{reason: ReasonSyntaxError, pattern: regexp.MustCompile(`\b(SyntaxError|IndentationError|TabError)\b|npm ERR! code EJSONPARSE`)},
+	{reason: ReasonDependencyInstall, pattern: regexp.MustCompile(`npm ERR!|ERROR: (Could not find a version that satisfies|No matching distribution found)|Could not install packages`)},
+}
+
+// Diagnosis describes why a build Job failed
+type Diagnosis struct {
+	Reason    Reason
+	Pod       string
+	Container string
+	// Line is the log line the failure was recognized by, or the last line of the log for unknown failures
+	Line string
+	// Tail holds the last lines of the log of the failed container
+	Tail string
+}
+
+// Summary returns a one-line description of the failure that fits into a condition message
+func (d *Diagnosis) Summary() string {
+	if d.Line == "" {
+		return fmt.Sprintf("%s in container %s", d.Reason, d.Container)
+	}
+	return fmt.Sprintf("%s in container %s: %s", d.Reason, d.Container, d.Line)
+}
+
+// Classify returns the reason of the failure of a container terminated with the given state and log
+func Classify(state *corev1.ContainerStateTerminated, log string) (Reason, string) {
+	lines := nonEmptyLines(log)
+
+	if state != nil && state.Reason == oomKilledReason {
+		return ReasonOutOfMemory, lastLine(lines)
+	}
+
+	for _, p := range patterns {
+		for _, line := range lines {
+			if p.pattern.MatchString(line) {
+				return p.reason, truncate(line)
+			}
+		}
+	}
+	return ReasonUnknown, lastLine(lines)
+}
+
+func nonEmptyLines(log string) []string {
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func lastLine(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return truncate(lines[len(lines)-1])
+}
+
+func truncate(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+	return line[:maxLineLength] + "..."
+}
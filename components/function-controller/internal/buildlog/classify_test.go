@@ -0,0 +1,115 @@
+package buildlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name       string
+		state      *corev1.ContainerStateTerminated
+		log        string
+		wantReason Reason
+		wantLine   string
+	}{
+		{
+			name:  "npm dependency not found",
+			state: &corev1.ContainerStateTerminated{ExitCode: 1},
+			log: `INFO[0012] RUN npm install --production
+npm ERR! code E404
+npm ERR! 404 Not Found - GET https://registry.npmjs.org/lodahs - Not found
+error building image: error building stage: failed to execute command: waiting for process to exit: exit status 1`,
+			wantReason: ReasonDependencyInstall,
+			wantLine:   "npm ERR! code E404",
+		},
+		{
+			name:  "pip dependency not found",
+			state: &corev1.ContainerStateTerminated{ExitCode: 1},
+			log: `ERROR: Could not find a version that satisfies the requirement requestz==2.0 (from versions: none)
+ERROR: No matching distribution found for requestz==2.0`,
+			wantReason: ReasonDependencyInstall,
+			wantLine:   "ERROR: Could not find a version that satisfies the requirement requestz==2.0 (from versions: none)",
+		},
+		{
+			name:  "invalid package.json",
+			state: &corev1.ContainerStateTerminated{ExitCode: 1},
+			log: `npm ERR! code EJSONPARSE
+npm ERR! JSON.parse Failed to parse json`,
+			wantReason: ReasonSyntaxError,
+			wantLine:   "npm ERR! code EJSONPARSE",
+		},
+		{
+			name:  "python syntax error",
+			state: &corev1.ContainerStateTerminated{ExitCode: 1},
+			log: `  File "handler.py", line 3
+    return "hello world
+                      ^
+SyntaxError: EOL while scanning string literal`,
+			wantReason: ReasonSyntaxError,
+			wantLine:   "SyntaxError: EOL while scanning string literal",
+		},
+		{
+			name:       "registry push denied",
+			state:      &corev1.ContainerStateTerminated{ExitCode: 1},
+			log:        `error pushing image: failed to push to destination registry.local/fn:1234: UNAUTHORIZED: authentication required`,
+			wantReason: ReasonRegistryPushDenied,
+			wantLine:   "error pushing image: failed to push to destination registry.local/fn:1234: UNAUTHORIZED: authentication required",
+		},
+		{
+			name:       "container killed for exceeding its memory limit",
+			state:      &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+			log:        "INFO[0010] RUN npm install --production\n",
+			wantReason: ReasonOutOfMemory,
+			wantLine:   "INFO[0010] RUN npm install --production",
+		},
+		{
+			name:       "node heap out of memory",
+			state:      &corev1.ContainerStateTerminated{ExitCode: 1},
+			log:        "FATAL ERROR: Ineffective mark-compacts near heap limit Allocation failed - JavaScript heap out of memory",
+			wantReason: ReasonOutOfMemory,
+			wantLine:   "FATAL ERROR: Ineffective mark-compacts near heap limit Allocation failed - JavaScript heap out of memory",
+		},
+		{
+			name:       "unknown failure",
+			state:      &corev1.ContainerStateTerminated{ExitCode: 128},
+			log:        "fatal: repository not found\n\n",
+			wantReason: ReasonUnknown,
+			wantLine:   "fatal: repository not found",
+		},
+		{
+			name:       "empty log",
+			wantReason: ReasonUnknown,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			reason, line := Classify(testCase.state, testCase.log)
+			g.Expect(reason).To(gomega.Equal(testCase.wantReason))
+			g.Expect(line).To(gomega.Equal(testCase.wantLine))
+		})
+	}
+}
+
+func TestClassify_truncatesLongLines(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	_, line := Classify(nil, "npm ERR! "+strings.Repeat("a", 2*maxLineLength))
+	g.Expect(line).To(gomega.HaveLen(maxLineLength + len("...")))
+}
+
+func TestDiagnosis_Summary(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	diagnosis := &Diagnosis{Reason: ReasonSyntaxError, Container: "executor", Line: "SyntaxError: invalid syntax"}
+	g.Expect(diagnosis.Summary()).To(gomega.Equal("SyntaxError in container executor: SyntaxError: invalid syntax"))
+
+	diagnosis = &Diagnosis{Reason: ReasonOutOfMemory, Container: "executor"}
+	g.Expect(diagnosis.Summary()).To(gomega.Equal("OutOfMemory in container executor"))
+}
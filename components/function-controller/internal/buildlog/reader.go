@@ -0,0 +1,87 @@
+package buildlog
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// jobNameLabel is set by Kubernetes on the Pods of a Job
+	jobNameLabel = "job-name"
+	maxTailBytes = 64 << 10
+)
+
+var ErrFailedContainerNotFound = errors.New("no failed container found in the pods of the job")
+
+// Reader reads the logs of failed build Jobs. Pod logs are not available through the controller-runtime client,
+// so it uses the Kubernetes clientset directly.
+type Reader struct {
+	clientset kubernetes.Interface
+	tailLines int64
+}
+
+func NewReader(clientset kubernetes.Interface, tailLines int64) *Reader {
+	return &Reader{
+		clientset: clientset,
+		tailLines: tailLines,
+	}
+}
+
+// Diagnose finds the failed container of the latest Pod of the Job, reads the tail of its log and classifies the failure
+func (r *Reader) Diagnose(ctx context.Context, job batchv1.Job) (*Diagnosis, error) {
+	pods, err := r.clientset.CoreV1().Pods(job.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{jobNameLabel: job.GetName()}).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing pods of the job")
+	}
+
+	pod, status := failedContainer(pods.Items)
+	if pod == nil {
+		return nil, ErrFailedContainerNotFound
+	}
+
+	tailLines, limitBytes := r.tailLines, int64(maxTailBytes)
+	log, err := r.clientset.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), &corev1.PodLogOptions{
+		Container:  status.Name,
+		TailLines:  &tailLines,
+		LimitBytes: &limitBytes,
+	}).Do(ctx).Raw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "while reading logs of container %s", status.Name)
+	}
+
+	reason, line := Classify(status.State.Terminated, string(log))
+	return &Diagnosis{
+		Reason:    reason,
+		Pod:       pod.GetName(),
+		Container: status.Name,
+		Line:      line,
+		Tail:      string(log),
+	}, nil
+}
+
+// failedContainer returns the newest Pod with a container that terminated with an error and the status of that container.
+// Init containers are checked first, as the build container does not start if one of them fails.
+func failedContainer(pods []corev1.Pod) (*corev1.Pod, *corev1.ContainerStatus) {
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+
+	for i := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pods[i].Status.InitContainerStatuses...), pods[i].Status.ContainerStatuses...)
+		for j := range statuses {
+			if terminated := statuses[j].State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+				return &pods[i], &statuses[j]
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,99 @@
+package buildlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFailedContainer(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	terminated := func(name string, exitCode int32) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:  name,
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+		}
+	}
+	pod := func(name string, created time.Time, initStatuses, statuses []corev1.ContainerStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Status:     corev1.PodStatus{InitContainerStatuses: initStatuses, ContainerStatuses: statuses},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		pods          []corev1.Pod
+		wantPod       string
+		wantContainer string
+	}{
+		{
+			name: "failed build container",
+			pods: []corev1.Pod{
+				pod("build", now, nil, []corev1.ContainerStatus{terminated("executor", 1)}),
+			},
+			wantPod:       "build",
+			wantContainer: "executor",
+		},
+		{
+			name: "failed init container",
+			pods: []corev1.Pod{
+				pod("build", now, []corev1.ContainerStatus{terminated("repo-fetcher", 128)}, []corev1.ContainerStatus{{Name: "executor"}}),
+			},
+			wantPod:       "build",
+			wantContainer: "repo-fetcher",
+		},
+		{
+			name: "newest failed pod",
+			pods: []corev1.Pod{
+				pod("first", now.Add(-time.Minute), nil, []corev1.ContainerStatus{terminated("executor", 1)}),
+				pod("second", now, nil, []corev1.ContainerStatus{terminated("executor", 1)}),
+			},
+			wantPod:       "second",
+			wantContainer: "executor",
+		},
+		{
+			name: "no failed container",
+			pods: []corev1.Pod{
+				pod("build", now, []corev1.ContainerStatus{terminated("repo-fetcher", 0)}, []corev1.ContainerStatus{{Name: "executor"}}),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			gotPod, gotStatus := failedContainer(testCase.pods)
+			if testCase.wantPod == "" {
+				g.Expect(gotPod).To(gomega.BeNil())
+				g.Expect(gotStatus).To(gomega.BeNil())
+				return
+			}
+			g.Expect(gotPod.Name).To(gomega.Equal(testCase.wantPod))
+			g.Expect(gotStatus.Name).To(gomega.Equal(testCase.wantContainer))
+		})
+	}
+}
+
+func TestReader_Diagnose_withoutFailedContainer(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "fn-build", Namespace: "default"}}
+	otherJobPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-build", Namespace: "default", Labels: map[string]string{jobNameLabel: "other"}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "executor",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+		}}},
+	}
+
+	_, err := NewReader(fake.NewSimpleClientset(otherJobPod), 20).Diagnose(context.TODO(), job)
+	g.Expect(err).To(gomega.Equal(ErrFailedContainerNotFound))
+}
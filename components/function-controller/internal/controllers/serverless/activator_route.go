@@ -0,0 +1,181 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+// listActivatorRoutes returns the routes of all functions from the namespace of the activator
+func (r *FunctionReconciler) listActivatorRoutes(ctx context.Context) ([]corev1.Service, error) {
+	var routes corev1.ServiceList
+	if err := r.client.ListByLabel(ctx, r.config.Activator.Namespace, map[string]string{
+		serverlessv1alpha1.FunctionResourceLabel: serverlessv1alpha1.FunctionResourceLabelActivatorRouteValue,
+	}, &routes); err != nil {
+		return nil, err
+	}
+	return routes.Items, nil
+}
+
+// functionActivatorRoutes returns the routes of the given function
+func functionActivatorRoutes(instance *serverlessv1alpha1.Function, routes []corev1.Service) []corev1.Service {
+	var result []corev1.Service
+	for _, route := range routes {
+		if route.GetLabels()[serverlessv1alpha1.FunctionUUIDLabel] == string(instance.GetUID()) {
+			result = append(result, route)
+		}
+	}
+	return result
+}
+
+// activatorRoutePort returns the port of the activator a route maps to its function
+func activatorRoutePort(route corev1.Service) int32 {
+	if len(route.Spec.Ports) == 0 {
+		return 0
+	}
+	return route.Spec.Ports[0].TargetPort.IntVal
+}
+
+// ownsActivatorPort returns false if an older route maps the port of the given route to another function,
+// which happens if the port was allocated twice from a stale cache. The activator serves the older route.
+func ownsActivatorPort(route corev1.Service, routes []corev1.Service) bool {
+	port := activatorRoutePort(route)
+	for _, other := range routes {
+		if other.GetName() != route.GetName() && activatorRoutePort(other) == port && isOlderRoute(other, route) {
+			return false
+		}
+	}
+	return true
+}
+
+func isOlderRoute(route, other corev1.Service) bool {
+	created, otherCreated := route.GetCreationTimestamp(), other.GetCreationTimestamp()
+	if !created.Equal(&otherCreated) {
+		return created.Before(&otherCreated)
+	}
+	return route.GetName() < other.GetName()
+}
+
+// routedActivatorRoute returns the route the Service of the function points to, or nil if the Service selects the Pods
+// of the function. All requests of a function with minReplicas 0 go through the activator, also while the function
+// runs, as the activator reports the time of the latest request which keeps the function from being scaled to zero.
+func routedActivatorRoute(instance *serverlessv1alpha1.Function, routes []corev1.Service) *corev1.Service {
+	if !isScaleToZeroEnabled(instance) || len(routes) != 1 {
+		return nil
+	}
+	return &routes[0]
+}
+
+func (r *FunctionReconciler) isOnActivatorRouteChange(instance *serverlessv1alpha1.Function, routes []corev1.Service) bool {
+	functionRoutes := functionActivatorRoutes(instance, routes)
+	if !isScaleToZeroEnabled(instance) {
+		return len(functionRoutes) != 0
+	}
+
+	return !(len(functionRoutes) == 1 &&
+		ownsActivatorPort(functionRoutes[0], routes) &&
+		r.equalServices(functionRoutes[0], r.buildActivatorRoute(instance, activatorRoutePort(functionRoutes[0]))))
+}
+
+func (r *FunctionReconciler) onActivatorRouteChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, routes []corev1.Service) (ctrl.Result, error) {
+	functionRoutes := functionActivatorRoutes(instance, routes)
+
+	switch {
+	case !isScaleToZeroEnabled(instance) || len(functionRoutes) > 1 || (len(functionRoutes) == 1 && !ownsActivatorPort(functionRoutes[0], routes)):
+		return r.deleteActivatorRoutes(ctx, log, functionRoutes)
+	case len(functionRoutes) == 0:
+		return r.createActivatorRoute(ctx, log, instance, routes)
+	default:
+		return r.updateService(ctx, log, instance, functionRoutes[0], r.buildActivatorRoute(instance, activatorRoutePort(functionRoutes[0])))
+	}
+}
+
+func (r *FunctionReconciler) createActivatorRoute(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, routes []corev1.Service) (ctrl.Result, error) {
+	port, err := r.allocateActivatorPort(ctx, log, routes)
+	if err != nil {
+		log.Error(err, "Cannot allocate a port of the activator")
+		return ctrl.Result{}, err
+	}
+
+	// the route lives in another namespace, so it cannot be owned by the function
+	route := r.buildActivatorRoute(instance, port)
+	log.Info(fmt.Sprintf("Creating activator route %s with port %d", route.GetName(), port))
+	if err := r.client.Create(ctx, &route); err != nil {
+		log.Error(err, fmt.Sprintf("Cannot create activator route %s", route.GetName()))
+		return ctrl.Result{}, err
+	}
+	log.Info(fmt.Sprintf("Activator route %s created", route.GetName()))
+
+	// the creation of the route does not trigger the next reconciliation either
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{Requeue: true}, instance, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionRunning,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonServiceCreated,
+		Message:            fmt.Sprintf("Service %s created", route.GetName()),
+	})
+}
+
+// allocateActivatorPort returns the lowest port from the range of the activator which is not mapped by any route.
+// Routes of deleted functions are removed first, which frees their ports.
+func (r *FunctionReconciler) allocateActivatorPort(ctx context.Context, log logr.Logger, routes []corev1.Service) (int32, error) {
+	used := map[int32]bool{}
+	for i := range routes {
+		exists, err := r.activatorRouteFunctionExists(ctx, routes[i])
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			if _, err := r.deleteActivatorRoutes(ctx, log, routes[i:i+1]); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		used[activatorRoutePort(routes[i])] = true
+	}
+
+	for port := r.config.Activator.FirstPort; port <= r.config.Activator.LastPort; port++ {
+		if !used[port] {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("all ports of the activator from %d to %d are allocated", r.config.Activator.FirstPort, r.config.Activator.LastPort)
+}
+
+func (r *FunctionReconciler) activatorRouteFunctionExists(ctx context.Context, route corev1.Service) (bool, error) {
+	labels := route.GetLabels()
+	var function serverlessv1alpha1.Function
+	err := r.client.Get(ctx, types.NamespacedName{
+		Namespace: labels[serverlessv1alpha1.FunctionNamespaceLabel],
+		Name:      labels[serverlessv1alpha1.FunctionNameLabel],
+	}, &function)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "while getting the function of activator route %s", route.GetName())
+	}
+	return string(function.GetUID()) == labels[serverlessv1alpha1.FunctionUUIDLabel], nil
+}
+
+func (r *FunctionReconciler) deleteActivatorRoutes(ctx context.Context, log logr.Logger, routes []corev1.Service) (ctrl.Result, error) {
+	for i := range routes {
+		log.Info(fmt.Sprintf("Deleting activator route %s", routes[i].GetName()))
+		if err := r.client.Delete(ctx, &routes[i]); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, fmt.Sprintf("Cannot delete activator route %s", routes[i].GetName()))
+			return ctrl.Result{}, err
+		}
+	}
+
+	// the routes are not owned by the function, so their deletion does not trigger the next reconciliation
+	return ctrl.Result{Requeue: true}, nil
+}
@@ -0,0 +1,188 @@
+package serverless
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/resource"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func Test_routedActivatorRoute(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	zero, one := int32(0), int32(1)
+	routes := []corev1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "serverless-activator-fn-uid"}}}
+
+	tests := []struct {
+		name        string
+		minReplicas *int32
+		created     time.Time
+		routes      []corev1.Service
+		want        bool
+	}{
+		{
+			name:        "does not route function without scaling to zero",
+			minReplicas: &one,
+			created:     now.Add(-time.Hour),
+			routes:      routes,
+		},
+		{
+			name:        "routes running function",
+			minReplicas: &zero,
+			created:     now.Add(-time.Minute),
+			routes:      routes,
+			want:        true,
+		},
+		{
+			name:        "routes function scaled to zero",
+			minReplicas: &zero,
+			created:     now.Add(-time.Hour),
+			routes:      routes,
+			want:        true,
+		},
+		{
+			name:        "does not route function without route",
+			minReplicas: &zero,
+			created:     now.Add(-time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(tt.created)},
+				Spec: serverlessv1alpha1.FunctionSpec{
+					MinReplicas: tt.minReplicas,
+					IdleTimeout: &metav1.Duration{Duration: 15 * time.Minute},
+				},
+			}
+
+			got := routedActivatorRoute(instance, tt.routes)
+			if tt.want {
+				g.Expect(got).To(gomega.Equal(&tt.routes[0]))
+			} else {
+				g.Expect(got).To(gomega.BeNil())
+			}
+		})
+	}
+}
+
+func Test_routedActivatorRoute_trafficPastIdleTimeout(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	created := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	zero := int32(0)
+	routes := []corev1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "serverless-activator-fn-uid"}}}
+	instance := &serverlessv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Spec: serverlessv1alpha1.FunctionSpec{
+			MinReplicas: &zero,
+			IdleTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	// a request every 30 seconds for four idle timeouts, each of them reported by the activator it is routed to
+	for now := created; now.Before(created.Add(20 * time.Minute)); now = now.Add(30 * time.Second) {
+		g.Expect(routedActivatorRoute(instance, routes)).NotTo(gomega.BeNil(), "request at %s bypasses the activator", now)
+		instance.SetAnnotations(map[string]string{serverlessv1alpha1.FunctionLastRequestTimeAnnotation: now.Format(time.RFC3339)})
+
+		g.Expect(isScaledToZero(instance, now)).To(gomega.BeFalse(), "function scaled to zero at %s", now)
+		g.Expect(*deploymentReplicas(instance, now)).To(gomega.Equal(int32(1)))
+	}
+
+	// once the traffic stops, the function is scaled to zero after the idle timeout
+	last := lastRequestTime(instance)
+	g.Expect(isScaledToZero(instance, last.Add(5*time.Minute))).To(gomega.BeTrue())
+}
+
+func Test_ownsActivatorPort(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	older := activatorRoute("serverless-activator-b", 10000, now)
+	newer := activatorRoute("serverless-activator-a", 10000, now.Add(time.Second))
+	sameAge := activatorRoute("serverless-activator-c", 10000, now)
+	other := activatorRoute("serverless-activator-d", 10001, now.Add(-time.Hour))
+
+	g.Expect(ownsActivatorPort(older, []corev1.Service{older, newer, other})).To(gomega.BeTrue())
+	g.Expect(ownsActivatorPort(newer, []corev1.Service{older, newer, other})).To(gomega.BeFalse())
+	g.Expect(ownsActivatorPort(sameAge, []corev1.Service{older, sameAge})).To(gomega.BeFalse())
+	g.Expect(ownsActivatorPort(other, []corev1.Service{older, newer, other})).To(gomega.BeTrue())
+}
+
+func TestFunctionReconciler_createActivatorRoute(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	zero := int32(0)
+	instance := &serverlessv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "fn-name", Namespace: "fn-ns", UID: "fn-uid"},
+		Spec:       serverlessv1alpha1.FunctionSpec{MinReplicas: &zero},
+	}
+	running := &serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "fn-ns", UID: "running-uid"}}
+	// the route of a deleted function frees its port
+	routes := []corev1.Service{
+		functionActivatorRoute(running, 10000),
+		functionActivatorRoute(&serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "fn-ns", UID: "deleted-uid"}}, 10001),
+		// a function recreated with the same name has another uid
+		functionActivatorRoute(&serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "fn-ns", UID: "old-uid"}}, 10002),
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(serverlessv1alpha1.AddToScheme(scheme)).To(gomega.Succeed())
+	objects := []runtime.Object{instance.DeepCopy(), running.DeepCopy()}
+	for i := range routes {
+		objects = append(objects, routes[i].DeepCopy())
+	}
+	client := resource.New(fake.NewFakeClientWithScheme(scheme, objects...), scheme)
+
+	r := &FunctionReconciler{client: client, recorder: record.NewFakeRecorder(10), config: FunctionConfig{
+		Activator: ActivatorConfig{Namespace: "kyma-system", AppLabel: "serverless-activator", FirstPort: 10000, LastPort: 10002},
+	}}
+
+	result, err := r.createActivatorRoute(context.TODO(), zap.New(), instance, routes)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.Requeue).To(gomega.BeTrue())
+
+	got, err := r.listActivatorRoutes(context.TODO())
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(got).To(gomega.HaveLen(2))
+
+	var route corev1.Service
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "kyma-system", Name: "serverless-activator-fn-uid"}, &route)).To(gomega.Succeed())
+	g.Expect(activatorRoutePort(route)).To(gomega.Equal(int32(10001)))
+	g.Expect(route.Spec.Selector).To(gomega.Equal(map[string]string{"app": "serverless-activator"}))
+	g.Expect(route.GetLabels()).To(gomega.HaveKeyWithValue(serverlessv1alpha1.FunctionNamespaceLabel, "fn-ns"))
+	g.Expect(route.GetLabels()).To(gomega.HaveKeyWithValue(serverlessv1alpha1.FunctionNameLabel, "fn-name"))
+
+	// no port is left once the range is narrowed to the allocated ones
+	r.config.Activator.LastPort = 10001
+	other := &serverlessv1alpha1.Function{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "fn-ns", UID: "other-uid"}}
+	_, err = r.createActivatorRoute(context.TODO(), zap.New(), other, got)
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func activatorRoute(name string, port int32, created time.Time) corev1.Service {
+	route := (&FunctionReconciler{}).buildActivatorRoute(&serverlessv1alpha1.Function{}, port)
+	route.SetName(name)
+	route.SetCreationTimestamp(metav1.NewTime(created))
+	return route
+}
+
+func functionActivatorRoute(instance *serverlessv1alpha1.Function, port int32) corev1.Service {
+	r := &FunctionReconciler{config: FunctionConfig{Activator: ActivatorConfig{Namespace: "kyma-system", AppLabel: "serverless-activator"}}}
+	return r.buildActivatorRoute(instance, port)
+}
@@ -1,12 +1,16 @@
 package serverless
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
+	"github.com/kyma-project/kyma/components/function-controller/internal/buildcontext"
 	"github.com/kyma-project/kyma/components/function-controller/internal/git"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -14,6 +18,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
@@ -25,6 +30,10 @@ const (
 	functionContainerName = "function"
 	baseDir               = "/workspace/src/"
 	workspaceMountPath    = "/workspace"
+	// runtimeDockerfileKey is the key of the Dockerfile in the ConfigMap of a runtime
+	runtimeDockerfileKey = "Dockerfile"
+	// functionImageRepositorySuffix ends the names of the repositories of the Function images, one per Namespace
+	functionImageRepositorySuffix = "-functions"
 )
 
 var (
@@ -43,6 +52,9 @@ func (r *FunctionReconciler) buildConfigMap(instance *serverlessv1alpha1.Functio
 		FunctionSourceKey: instance.Spec.Source,
 		FunctionDepsKey:   rtm.SanitizeDependencies(instance.Spec.Deps),
 	}
+	for filePath, content := range instance.Spec.Files {
+		data[functionFileKey(filePath)] = content
+	}
 
 	return corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -54,13 +66,28 @@ func (r *FunctionReconciler) buildConfigMap(instance *serverlessv1alpha1.Functio
 	}
 }
 
+// functionFileKey returns the ConfigMap key of an additional source file, paths can't be used as keys as they may
+// contain slashes
+func functionFileKey(filePath string) string {
+	return fmt.Sprintf("%s%x", functionFileKeyPrefix, sha256.Sum256([]byte(filePath)))
+}
+
+func sortedFilePaths(files map[string]string) []string {
+	filePaths := make([]string, 0, len(files))
+	for filePath := range files {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	return filePaths
+}
+
 func (r *FunctionReconciler) buildJob(instance *serverlessv1alpha1.Function, rtmConfig runtime.Config, configMapName string, dockerConfig DockerConfig) batchv1.Job {
 	one := int32(1)
 	zero := int32(0)
 	rootUser := int64(0)
 	optional := true
 
-	imageName := r.buildImageAddress(instance, dockerConfig.PushAddress)
+	imageName := r.buildImageAddress(instance, dockerConfig.PushAddress, r.calculateBuildHash(instance, rtmConfig, dockerConfig))
 	args := r.config.Build.ExecutorArgs
 	args = append(args, fmt.Sprintf("%s=%s", destinationArg, imageName), fmt.Sprintf("--context=dir://%s", workspaceMountPath))
 
@@ -128,7 +155,7 @@ func (r *FunctionReconciler) buildJob(instance *serverlessv1alpha1.Function, rtm
 							Image:           r.config.Build.ExecutorImage,
 							Args:            args,
 							Resources:       instance.Spec.BuildResources,
-							VolumeMounts:    r.getBuildJobVolumeMounts(rtmConfig),
+							VolumeMounts:    r.getBuildJobVolumeMounts(instance, rtmConfig),
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							Env: []corev1.EnvVar{
 								{Name: "DOCKER_CONFIG", Value: "/docker/.docker/"},
@@ -146,17 +173,20 @@ func (r *FunctionReconciler) buildJob(instance *serverlessv1alpha1.Function, rtm
 	}
 }
 
-func (r *FunctionReconciler) getBuildJobVolumeMounts(rtmConfig runtime.Config) []corev1.VolumeMount {
+func (r *FunctionReconciler) getBuildJobVolumeMounts(instance *serverlessv1alpha1.Function, rtmConfig runtime.Config) []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{
 		// Must be mounted with SubPath otherwise files are symlinks and it is not possible to use COPY in Dockerfile
 		// If COPY is not used, then the cache will not work
 		{Name: "sources", ReadOnly: true, MountPath: path.Join(baseDir, rtmConfig.DependencyFile), SubPath: FunctionDepsKey},
 		{Name: "sources", ReadOnly: true, MountPath: path.Join(baseDir, rtmConfig.FunctionFile), SubPath: FunctionSourceKey},
-		{Name: "runtime", ReadOnly: true, MountPath: path.Join(workspaceMountPath, "Dockerfile"), SubPath: "Dockerfile"},
+		{Name: "runtime", ReadOnly: true, MountPath: path.Join(workspaceMountPath, buildcontext.DockerfilePath), SubPath: runtimeDockerfileKey},
 		{Name: "credentials", ReadOnly: true, MountPath: "/docker"},
 	}
+	for _, filePath := range sortedFilePaths(instance.Spec.Files) {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "sources", ReadOnly: true, MountPath: path.Join(baseDir, filePath), SubPath: functionFileKey(filePath)})
+	}
 	// add package registry config volume mount depending on the used runtime
-	volumeMounts = append(volumeMounts, r.getPackageConfigVolumeMountsForRuntime(rtmConfig.Runtime)...)
+	volumeMounts = append(volumeMounts, r.getPackageConfigVolumeMountsForRuntime(rtmConfig)...)
 	return volumeMounts
 }
 
@@ -242,7 +272,7 @@ func buildRepoFetcherEnvVars(instance *serverlessv1alpha1.Function, gitOptions g
 }
 
 func (r *FunctionReconciler) buildGitJob(instance *serverlessv1alpha1.Function, gitOptions git.Options, rtmConfig runtime.Config, dockerConfig DockerConfig) batchv1.Job {
-	imageName := r.buildImageAddress(instance, dockerConfig.PushAddress)
+	imageName := r.buildImageAddress(instance, dockerConfig.PushAddress, r.calculateBuildHash(instance, rtmConfig, dockerConfig))
 	args := r.config.Build.ExecutorArgs
 	args = append(args, fmt.Sprintf("%s=%s", destinationArg, imageName), fmt.Sprintf("--context=dir://%s", workspaceMountPath))
 
@@ -348,19 +378,18 @@ func (r *FunctionReconciler) getGitBuildJobVolumeMounts(instance *serverlessv1al
 		{Name: "credentials", ReadOnly: true, MountPath: "/docker"},
 		// Must be mounted with SubPath otherwise files are symlinks and it is not possible to use COPY in Dockerfile
 		// If COPY is not used, then the cache will not work
-		{Name: "workspace", MountPath: path.Join(workspaceMountPath, "src"), SubPath: strings.TrimPrefix(instance.Spec.BaseDir, "/")},
-		{Name: "runtime", ReadOnly: true, MountPath: path.Join(workspaceMountPath, "Dockerfile"), SubPath: "Dockerfile"},
+		{Name: "workspace", MountPath: path.Join(workspaceMountPath, buildcontext.SourceDir), SubPath: strings.TrimPrefix(instance.Spec.BaseDir, "/")},
+		{Name: "runtime", ReadOnly: true, MountPath: path.Join(workspaceMountPath, buildcontext.DockerfilePath), SubPath: runtimeDockerfileKey},
 	}
 	// add package registry config volume mount depending on the used runtime
-	volumeMounts = append(volumeMounts, r.getPackageConfigVolumeMountsForRuntime(rtmConfig.Runtime)...)
+	volumeMounts = append(volumeMounts, r.getPackageConfigVolumeMountsForRuntime(rtmConfig)...)
 	return volumeMounts
 
 }
 
-func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Function, rtmConfig runtime.Config, dockerConfig DockerConfig) appsv1.Deployment {
-	imageName := r.buildImageAddress(instance, dockerConfig.PullAddress)
-	deploymentLabels := r.functionLabels(instance)
-	podLabels := r.podLabels(instance)
+func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Function, rtmConfig runtime.Config, revision serverlessv1alpha1.FunctionRevision, replicas *int32) appsv1.Deployment {
+	deploymentLabels := r.mergeLabels(r.functionLabels(instance), revisionLabels(revision.Name))
+	podLabels := r.mergeLabels(r.podLabels(instance), revisionLabels(revision.Name))
 
 	functionUser := int64(1000)
 	const volumeName = "tmp-dir"
@@ -371,14 +400,14 @@ func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Functi
 
 	return appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-", instance.GetName()),
+			GenerateName: fmt.Sprintf("%s-%s-", instance.GetName(), revision.Name),
 			Namespace:    instance.GetNamespace(),
 			Labels:       deploymentLabels,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: instance.Spec.MinReplicas,
+			Replicas: replicas,
 			Selector: &metav1.LabelSelector{
-				MatchLabels: r.deploymentSelectorLabels(instance), // this has to match spec.template.objectmeta.Labels
+				MatchLabels: r.revisionSelectorLabels(instance, revision.Name), // this has to match spec.template.objectmeta.Labels
 				// and also it has to be immutable
 			},
 			Template: corev1.PodTemplateSpec{
@@ -389,7 +418,7 @@ func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Functi
 					},
 				},
 				Spec: corev1.PodSpec{
-					Volumes: []corev1.Volume{{
+					Volumes: append([]corev1.Volume{{
 						Name: volumeName,
 						VolumeSource: corev1.VolumeSource{
 							EmptyDir: &corev1.EmptyDirVolumeSource{
@@ -397,14 +426,14 @@ func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Functi
 								SizeLimit: &emptyDirVolumeSize,
 							},
 						},
-					}},
+					}}, buildFunctionVolumes(instance.Spec.Volumes)...),
 					Containers: []corev1.Container{
 						{
 							Name:      functionContainerName,
-							Image:     imageName,
+							Image:     revision.Image,
 							Env:       envs,
 							Resources: instance.Spec.Resources,
-							VolumeMounts: []corev1.VolumeMount{{
+							VolumeMounts: append([]corev1.VolumeMount{{
 								Name: volumeName,
 								/* needed in order to have python functions working:
 								python functions need writable /tmp dir, but we disable writing to root filesystem via
@@ -413,7 +442,7 @@ func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Functi
 								*/
 								MountPath: "/tmp",
 								ReadOnly:  false,
-							}},
+							}}, buildFunctionVolumeMounts(instance.Spec.Volumes)...),
 							/*
 								In order to mark pod as ready we need to ensure the function is actually running and ready to serve traffic.
 								We do this but first ensuring that sidecar is raedy by using "proxy.istio.io/config": "{ \"holdApplicationUntilProxyStarts\": true }", annotation
@@ -464,8 +493,35 @@ func (r *FunctionReconciler) buildDeployment(instance *serverlessv1alpha1.Functi
 	}
 }
 
-func (r *FunctionReconciler) buildService(instance *serverlessv1alpha1.Function) corev1.Service {
-	return corev1.Service{
+func buildFunctionVolumes(volumes []serverlessv1alpha1.FunctionVolume) []corev1.Volume {
+	result := make([]corev1.Volume, 0, len(volumes))
+	for _, volume := range volumes {
+		source := corev1.VolumeSource{}
+		if volume.ConfigMapName != "" {
+			source.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: volume.ConfigMapName},
+			}
+		} else {
+			source.Secret = &corev1.SecretVolumeSource{SecretName: volume.SecretName}
+		}
+		result = append(result, corev1.Volume{Name: volume.Name, VolumeSource: source})
+	}
+	return result
+}
+
+// buildFunctionVolumeMounts mounts the volumes read-only, the root filesystem of the function container is read-only as well
+func buildFunctionVolumeMounts(volumes []serverlessv1alpha1.FunctionVolume) []corev1.VolumeMount {
+	result := make([]corev1.VolumeMount, 0, len(volumes))
+	for _, volume := range volumes {
+		result = append(result, corev1.VolumeMount{Name: volume.Name, MountPath: volume.MountPath, ReadOnly: true})
+	}
+	return result
+}
+
+// buildService returns the Service of a function. While the function is routed to the activator, the Service points
+// to the route of the function, otherwise it selects the Pods of the function.
+func (r *FunctionReconciler) buildService(instance *serverlessv1alpha1.Function, activatorRoute *corev1.Service) corev1.Service {
+	service := corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instance.GetName(),
 			Namespace: instance.GetNamespace(),
@@ -481,6 +537,110 @@ func (r *FunctionReconciler) buildService(instance *serverlessv1alpha1.Function)
 			Selector: r.deploymentSelectorLabels(instance),
 		},
 	}
+
+	if activatorRoute != nil {
+		service.Spec.Type = corev1.ServiceTypeExternalName
+		service.Spec.ExternalName = fmt.Sprintf("%s.%s.svc.cluster.local", activatorRoute.GetName(), activatorRoute.GetNamespace())
+		service.Spec.Selector = nil
+	}
+
+	return service
+}
+
+// buildActivatorRoute returns the Service which maps the given port of the activator to a function with minReplicas 0,
+// the activator serves the function on that port and nothing else identifies the function to it
+func (r *FunctionReconciler) buildActivatorRoute(instance *serverlessv1alpha1.Function, port int32) corev1.Service {
+	labels := r.internalFunctionLabels(instance)
+	labels[serverlessv1alpha1.FunctionNamespaceLabel] = instance.GetNamespace()
+	labels[serverlessv1alpha1.FunctionResourceLabel] = serverlessv1alpha1.FunctionResourceLabelActivatorRouteValue
+
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", r.config.Activator.AppLabel, instance.GetUID()),
+			Namespace: r.config.Activator.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       "http", // it has to be here for istio to work properly
+				TargetPort: intstr.FromInt(int(port)),
+				Port:       80,
+				Protocol:   corev1.ProtocolTCP,
+			}},
+			Selector: map[string]string{"app": r.config.Activator.AppLabel},
+		},
+	}
+}
+
+func (r *FunctionReconciler) buildPrivateService(instance *serverlessv1alpha1.Function) corev1.Service {
+	labels := r.functionLabels(instance)
+	labels[serverlessv1alpha1.FunctionResourceLabel] = serverlessv1alpha1.FunctionResourceLabelPrivateServiceValue
+
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", instance.GetName()),
+			Namespace:    instance.GetNamespace(),
+			Labels:       labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       "http", // it has to be here for istio to work properly
+				TargetPort: svcTargetPort,
+				Port:       80,
+				Protocol:   corev1.ProtocolTCP,
+			}},
+			Selector: r.deploymentSelectorLabels(instance),
+		},
+	}
+}
+
+func (r *FunctionReconciler) buildSubscription(instance *serverlessv1alpha1.Function, index int, trigger serverlessv1alpha1.Trigger) (unstructured.Unstructured, error) {
+	filter := subscriptionFilter{
+		EventSource: eventFilter{Type: serverlessv1alpha1.ExtensionFilterTypeExact, Property: "source", Value: r.config.Eventing.EventSource},
+		EventType:   eventFilter{Type: serverlessv1alpha1.ExtensionFilterTypeExact, Property: "type", Value: r.eventType(trigger)},
+	}
+	var expression string
+	if trigger.Filter != nil {
+		for _, extension := range trigger.Filter.Extensions {
+			filterType := extension.Type
+			if filterType == "" {
+				filterType = serverlessv1alpha1.ExtensionFilterTypeExact
+			}
+			filter.Extensions = append(filter.Extensions, eventFilter{Type: filterType, Property: extension.Property, Value: extension.Value})
+		}
+		expression = trigger.Filter.Expression
+	}
+
+	spec, err := toUnstructuredSpec(subscriptionSpec{
+		// the Service of a function is named after it
+		Sink: fmt.Sprintf("http://%s.%s.svc.cluster.local", instance.GetName(), instance.GetNamespace()),
+		Filter: subscriptionFilters{
+			Filters:    []subscriptionFilter{filter},
+			Expression: expression,
+		},
+	})
+	if err != nil {
+		return unstructured.Unstructured{}, err
+	}
+
+	subscription := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	subscription.SetGroupVersionKind(subscriptionGVK)
+	subscription.SetGenerateName(fmt.Sprintf("%s-", instance.GetName()))
+	subscription.SetNamespace(instance.GetNamespace())
+	subscription.SetLabels(r.mergeLabels(r.functionLabels(instance), map[string]string{
+		serverlessv1alpha1.FunctionTriggerLabel: strconv.Itoa(index),
+	}))
+	return subscription, nil
+}
+
+// eventType returns the type of the events of a trigger in the format of the eventing controller,
+// e.g. sap.kyma.custom.commercemock.order.created.v1
+func (r *FunctionReconciler) eventType(trigger serverlessv1alpha1.Trigger) string {
+	segments := []string{trigger.Source, trigger.Type, trigger.Version}
+	if r.config.Eventing.EventTypePrefix != "" {
+		segments = append([]string{r.config.Eventing.EventTypePrefix}, segments...)
+	}
+	return strings.Join(segments, ".")
 }
 
 func (r *FunctionReconciler) buildHorizontalPodAutoscaler(instance *serverlessv1alpha1.Function, deploymentName string) autoscalingv1.HorizontalPodAutoscaler {
@@ -518,14 +678,14 @@ func (r *FunctionReconciler) defaultReplicas(spec serverlessv1alpha1.FunctionSpe
 	return min, max
 }
 
-func (r *FunctionReconciler) buildImageAddress(instance *serverlessv1alpha1.Function, registryAddress string) string {
-	var imageTag string
-	if instance.Spec.Type == serverlessv1alpha1.SourceTypeGit {
-		imageTag = r.calculateGitImageTag(instance)
-	} else {
-		imageTag = r.calculateImageTag(instance)
-	}
-	return fmt.Sprintf("%s/%s-%s:%s", registryAddress, instance.Namespace, instance.Name, imageTag)
+func (r *FunctionReconciler) buildImageAddress(instance *serverlessv1alpha1.Function, registryAddress, buildHash string) string {
+	return fmt.Sprintf("%s/%s:%s", registryAddress, functionImageRepository(instance.GetNamespace()), buildHash)
+}
+
+// functionImageRepository returns the repository of the images of the Functions in the Namespace, their images are
+// told apart by the build hash
+func functionImageRepository(namespace string) string {
+	return namespace + functionImageRepositorySuffix
 }
 
 func (r *FunctionReconciler) functionLabels(instance *serverlessv1alpha1.Function) map[string]string {
@@ -546,6 +706,15 @@ func (r *FunctionReconciler) deploymentSelectorLabels(instance *serverlessv1alph
 	return r.mergeLabels(map[string]string{serverlessv1alpha1.FunctionResourceLabel: serverlessv1alpha1.FunctionResourceLabelDeploymentValue}, r.internalFunctionLabels(instance))
 }
 
+// revisionSelectorLabels selects the Pods of one revision, the Function Service selects the Pods of all revisions with deploymentSelectorLabels
+func (r *FunctionReconciler) revisionSelectorLabels(instance *serverlessv1alpha1.Function, revision string) map[string]string {
+	return r.mergeLabels(r.deploymentSelectorLabels(instance), revisionLabels(revision))
+}
+
+func revisionLabels(revision string) map[string]string {
+	return map[string]string{serverlessv1alpha1.FunctionRevisionLabel: revision}
+}
+
 func (r *FunctionReconciler) podLabels(instance *serverlessv1alpha1.Function) map[string]string {
 	return r.mergeLabels(instance.Spec.Labels, r.deploymentSelectorLabels(instance))
 }
@@ -560,12 +729,9 @@ func (r *FunctionReconciler) mergeLabels(labelsCollection ...map[string]string)
 	return result
 }
 
-func (r *FunctionReconciler) getPackageConfigVolumeMountsForRuntime(rtm serverlessv1alpha1.Runtime) []corev1.VolumeMount {
-	switch rtm {
-	case serverlessv1alpha1.Nodejs12, serverlessv1alpha1.Nodejs14:
-		return []corev1.VolumeMount{{Name: "registry-config", ReadOnly: true, MountPath: path.Join(workspaceMountPath, "registry-config/.npmrc"), SubPath: ".npmrc"}}
-	case serverlessv1alpha1.Python38, serverlessv1alpha1.Python39:
-		return []corev1.VolumeMount{{Name: "registry-config", ReadOnly: true, MountPath: path.Join(workspaceMountPath, "registry-config/pip.conf"), SubPath: "pip.conf"}}
+func (r *FunctionReconciler) getPackageConfigVolumeMountsForRuntime(rtmConfig runtime.Config) []corev1.VolumeMount {
+	if rtmConfig.PackageRegistryConfigFile == "" {
+		return nil
 	}
-	return nil
+	return []corev1.VolumeMount{{Name: "registry-config", ReadOnly: true, MountPath: path.Join(workspaceMountPath, buildcontext.RegistryConfigDir, rtmConfig.PackageRegistryConfigFile), SubPath: rtmConfig.PackageRegistryConfigFile}}
 }
@@ -2,6 +2,7 @@ package serverless
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/onsi/gomega"
@@ -9,6 +10,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 
+	"github.com/kyma-project/kyma/components/function-controller/internal/buildcontext"
 	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
@@ -45,12 +47,39 @@ func TestFunctionReconciler_buildConfigMap(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "should store additional files under keys derived from their paths",
+			fn: &serverlessv1alpha1.Function{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "fn-ns",
+					UID:       "fn-uuid",
+					Name:      "function-name",
+				},
+				Spec: serverlessv1alpha1.FunctionSpec{Source: "fn-source", Files: map[string]string{"lib/helper.js": "helper-source"}},
+			},
+			want: corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:    "fn-ns",
+					GenerateName: "function-name-",
+					Labels: map[string]string{
+						serverlessv1alpha1.FunctionManagedByLabel: serverlessv1alpha1.FunctionControllerValue,
+						serverlessv1alpha1.FunctionNameLabel:      "function-name",
+						serverlessv1alpha1.FunctionUUIDLabel:      "fn-uuid",
+					},
+				},
+				Data: map[string]string{
+					"source":                         "fn-source",
+					"dependencies":                   "{}",
+					functionFileKey("lib/helper.js"): "helper-source",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := gomega.NewGomegaWithT(t)
 			r := &FunctionReconciler{}
-			got := r.buildConfigMap(tt.fn, runtime.GetRuntime(serverlessv1alpha1.Nodejs14))
+			got := r.buildConfigMap(tt.fn, runtime.GetRuntime(serverlessv1alpha1.DependencySanitizerNodejs))
 			g.Expect(got).To(gomega.Equal(tt.want))
 		})
 	}
@@ -75,7 +104,13 @@ func TestFunctionReconciler_buildDeployment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := gomega.NewGomegaWithT(t)
 			r := &FunctionReconciler{}
-			got := r.buildDeployment(tt.args.instance, rtmCfg, DockerConfig{})
+			revision := serverlessv1alpha1.FunctionRevision{Name: "v1", Image: "registry.kyma.local/functions:abc"}
+			got := r.buildDeployment(tt.args.instance, rtmCfg, revision, tt.args.instance.Spec.MinReplicas)
+
+			g.Expect(got.Spec.Selector.MatchLabels).To(gomega.HaveKeyWithValue(serverlessv1alpha1.FunctionRevisionLabel, "v1"))
+			g.Expect(got.GetLabels()).To(gomega.HaveKeyWithValue(serverlessv1alpha1.FunctionRevisionLabel, "v1"))
+			g.Expect(got.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(revision.Image))
+			g.Expect(got.Spec.Replicas).To(gomega.Equal(tt.args.instance.Spec.MinReplicas))
 
 			for key, value := range got.Spec.Selector.MatchLabels {
 				g.Expect(got.Spec.Template.Labels[key]).To(gomega.Equal(value))
@@ -95,6 +130,67 @@ func TestFunctionReconciler_buildDeployment(t *testing.T) {
 	}
 }
 
+func TestFunctionReconciler_buildDeployment_withVolumes(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	instance := newFixFunction("ns", "name", 1, 2)
+	instance.Spec.Volumes = []serverlessv1alpha1.FunctionVolume{
+		{Name: "config", MountPath: "/config", ConfigMapName: "function-config"},
+		{Name: "certs", MountPath: "/etc/certs", SecretName: "function-certs"},
+	}
+	revision := serverlessv1alpha1.FunctionRevision{Name: "v1", Image: "registry.kyma.local/functions:abc"}
+
+	got := (&FunctionReconciler{}).buildDeployment(instance, runtime.GetRuntimeConfig(serverlessv1alpha1.Nodejs12), revision, instance.Spec.MinReplicas)
+
+	g.Expect(got.Spec.Template.Spec.Volumes).To(gomega.HaveLen(3))
+	g.Expect(got.Spec.Template.Spec.Volumes[1].Name).To(gomega.Equal("config"))
+	g.Expect(got.Spec.Template.Spec.Volumes[1].ConfigMap.Name).To(gomega.Equal("function-config"))
+	g.Expect(got.Spec.Template.Spec.Volumes[2].Name).To(gomega.Equal("certs"))
+	g.Expect(got.Spec.Template.Spec.Volumes[2].Secret.SecretName).To(gomega.Equal("function-certs"))
+	g.Expect(got.Spec.Template.Spec.Containers[0].VolumeMounts).To(gomega.ContainElements(
+		corev1.VolumeMount{Name: "config", MountPath: "/config", ReadOnly: true},
+		corev1.VolumeMount{Name: "certs", MountPath: "/etc/certs", ReadOnly: true},
+	))
+}
+
+func TestFunctionReconciler_getBuildJobVolumeMounts_withFiles(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	instance := &serverlessv1alpha1.Function{
+		Spec: serverlessv1alpha1.FunctionSpec{Files: map[string]string{"lib/helper.js": "", "data.json": ""}},
+	}
+	rtmCfg := runtime.Config{DependencyFile: "package.json", FunctionFile: "handler.js"}
+
+	got := (&FunctionReconciler{}).getBuildJobVolumeMounts(instance, rtmCfg)
+
+	g.Expect(got).To(gomega.ContainElements(
+		corev1.VolumeMount{Name: "sources", ReadOnly: true, MountPath: "/workspace/src/data.json", SubPath: functionFileKey("data.json")},
+		corev1.VolumeMount{Name: "sources", ReadOnly: true, MountPath: "/workspace/src/lib/helper.js", SubPath: functionFileKey("lib/helper.js")},
+	))
+}
+
+func TestFunctionReconciler_getBuildJobVolumeMounts_matchBuildContext(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	instance := &serverlessv1alpha1.Function{
+		Spec: serverlessv1alpha1.FunctionSpec{Source: "source", Files: map[string]string{"lib/helper.js": ""}},
+	}
+	rtmCfg := runtime.Config{DependencyFile: "package.json", FunctionFile: "handler.js", PackageRegistryConfigFile: ".npmrc"}
+	buildContext, err := buildcontext.ForInline(instance, rtmCfg, runtime.GetRuntime(serverlessv1alpha1.DependencySanitizerNodejs), "", "")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	want, err := buildContext.Paths()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	var got []string
+	for _, volumeMount := range (&FunctionReconciler{}).getBuildJobVolumeMounts(instance, rtmCfg) {
+		if strings.HasPrefix(volumeMount.MountPath, workspaceMountPath+"/") {
+			got = append(got, strings.TrimPrefix(volumeMount.MountPath, workspaceMountPath+"/"))
+		}
+	}
+
+	g.Expect(got).To(gomega.ConsistOf(want))
+}
+
 func TestFunctionReconciler_buildHorizontalPodAutoscaler(t *testing.T) {
 	type args struct {
 		instance *serverlessv1alpha1.Function
@@ -420,17 +516,17 @@ func TestFunctionReconciler_buildJob(t *testing.T) {
 	}
 
 	testCases := []struct {
-		Name                 string
-		Runtime              serverlessv1alpha1.Runtime
-		ExpectedVolumesLen   int
-		ExpectedVolumes      []expectedVolume
-		ExpectedMountsLen    int
-		ExpectedVolumeMounts []corev1.VolumeMount
+		Name                      string
+		PackageRegistryConfigFile string
+		ExpectedVolumesLen        int
+		ExpectedVolumes           []expectedVolume
+		ExpectedMountsLen         int
+		ExpectedVolumeMounts      []corev1.VolumeMount
 	}{
 		{
-			Name:               "Success Node12",
-			Runtime:            serverlessv1alpha1.Nodejs12,
-			ExpectedVolumesLen: 4,
+			Name:                      "Success Node12",
+			PackageRegistryConfigFile: serverlessv1alpha1.BuiltinFunctionRuntimes[serverlessv1alpha1.Nodejs12].PackageRegistryConfigFile,
+			ExpectedVolumesLen:        4,
 			ExpectedVolumes: []expectedVolume{
 				{name: "sources", localObjectReference: cmName},
 				{name: "runtime", localObjectReference: rtmCfg.DockerfileConfigMapName},
@@ -447,9 +543,9 @@ func TestFunctionReconciler_buildJob(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Success Node14",
-			Runtime:            serverlessv1alpha1.Nodejs14,
-			ExpectedVolumesLen: 4,
+			Name:                      "Success Node14",
+			PackageRegistryConfigFile: serverlessv1alpha1.BuiltinFunctionRuntimes[serverlessv1alpha1.Nodejs14].PackageRegistryConfigFile,
+			ExpectedVolumesLen:        4,
 			ExpectedVolumes: []expectedVolume{
 				{name: "sources", localObjectReference: cmName},
 				{name: "runtime", localObjectReference: rtmCfg.DockerfileConfigMapName},
@@ -466,9 +562,9 @@ func TestFunctionReconciler_buildJob(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Success Python38",
-			Runtime:            serverlessv1alpha1.Python38,
-			ExpectedVolumesLen: 4,
+			Name:                      "Success Python38",
+			PackageRegistryConfigFile: serverlessv1alpha1.BuiltinFunctionRuntimes[serverlessv1alpha1.Python38].PackageRegistryConfigFile,
+			ExpectedVolumesLen:        4,
 			ExpectedVolumes: []expectedVolume{
 				{name: "sources", localObjectReference: cmName},
 				{name: "runtime", localObjectReference: rtmCfg.DockerfileConfigMapName},
@@ -485,9 +581,9 @@ func TestFunctionReconciler_buildJob(t *testing.T) {
 			},
 		},
 		{
-			Name:               "Success Python39",
-			Runtime:            serverlessv1alpha1.Python39,
-			ExpectedVolumesLen: 4,
+			Name:                      "Success Python39",
+			PackageRegistryConfigFile: serverlessv1alpha1.BuiltinFunctionRuntimes[serverlessv1alpha1.Python39].PackageRegistryConfigFile,
+			ExpectedVolumesLen:        4,
 			ExpectedVolumes: []expectedVolume{
 				{name: "sources", localObjectReference: cmName},
 				{name: "runtime", localObjectReference: rtmCfg.DockerfileConfigMapName},
@@ -503,11 +599,28 @@ func TestFunctionReconciler_buildJob(t *testing.T) {
 				{Name: "registry-config", MountPath: "/workspace/registry-config/pip.conf", SubPath: "pip.conf", ReadOnly: true},
 			},
 		},
+		{
+			Name:               "Success runtime without package registry config",
+			ExpectedVolumesLen: 4,
+			ExpectedVolumes: []expectedVolume{
+				{name: "sources", localObjectReference: cmName},
+				{name: "runtime", localObjectReference: rtmCfg.DockerfileConfigMapName},
+				{name: "credentials", localObjectReference: dockerCfg.ActiveRegistryConfigSecretName},
+				{name: "registry-config", localObjectReference: r.config.PackageRegistryConfigSecretName},
+			},
+			ExpectedMountsLen: 4,
+			ExpectedVolumeMounts: []corev1.VolumeMount{
+				{Name: "sources", MountPath: "/workspace/src/deps.txt", SubPath: FunctionDepsKey, ReadOnly: true},
+				{Name: "sources", MountPath: "/workspace/src/function.abap", SubPath: FunctionSourceKey, ReadOnly: true},
+				{Name: "runtime", MountPath: "/workspace/Dockerfile", SubPath: "Dockerfile", ReadOnly: true},
+				{Name: "credentials", MountPath: "/docker", ReadOnly: true},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.Name, func(t *testing.T) {
-			rtmCfg.Runtime = testCase.Runtime
+			rtmCfg.PackageRegistryConfigFile = testCase.PackageRegistryConfigFile
 
 			// when
 			job := r.buildJob(&instance, rtmCfg, cmName, dockerCfg)
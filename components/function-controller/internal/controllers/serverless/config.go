@@ -2,6 +2,9 @@ package serverless
 
 import (
 	"time"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/docker"
+	"github.com/kyma-project/kyma/components/function-controller/internal/registrygc"
 )
 
 type FunctionConfig struct {
@@ -14,18 +17,76 @@ type FunctionConfig struct {
 	RequeueDuration                             time.Duration `envconfig:"default=1m"`
 	FunctionReadyRequeueDuration                time.Duration `envconfig:"default=5m"`
 	GitFetchRequeueDuration                     time.Duration `envconfig:"default=30s"`
+	RevisionHistoryLimit                        int           `envconfig:"default=10"`
+	Activator                                   ActivatorConfig
 	Build                                       BuildConfig
+	Eventing                                    EventingConfig
+	ImageGC                                     ImageGCConfig
+}
+
+type ActivatorConfig struct {
+	// Namespace is the namespace of the activator, the routes of functions with minReplicas 0 are created in it
+	Namespace string `envconfig:"default=kyma-system"`
+	// AppLabel is the app label of the activator Pods, which the routes select
+	AppLabel string `envconfig:"default=serverless-activator"`
+	// FirstPort and LastPort limit the ports of the activator allocated to the functions, one port per function
+	FirstPort int32 `envconfig:"default=10000"`
+	LastPort  int32 `envconfig:"default=14999"`
 }
 
 type BuildConfig struct {
-	ExecutorArgs        []string `envconfig:"default=--insecure;--skip-tls-verify;--skip-unused-stages;--log-format=text;--cache=true"`
-	ExecutorImage       string   `envconfig:"default=gcr.io/kaniko-project/executor:v0.22.0"`
-	RepoFetcherImage    string   `envconfig:"default=eu.gcr.io/kyma-project/function-build-init:305bee60"`
-	MaxSimultaneousJobs int      `envconfig:"default=5"`
+	ExecutorArgs        []string      `envconfig:"default=--insecure;--skip-tls-verify;--skip-unused-stages;--log-format=text;--cache=true"`
+	ExecutorImage       string        `envconfig:"default=gcr.io/kaniko-project/executor:v0.22.0"`
+	RepoFetcherImage    string        `envconfig:"default=eu.gcr.io/kyma-project/function-build-init:305bee60"`
+	MaxSimultaneousJobs int           `envconfig:"default=5"`
+	ImageCheckTimeout   time.Duration `envconfig:"default=5s"`
+	LogTailLines        int64         `envconfig:"default=20"`
+}
+
+type EventingConfig struct {
+	EventTypePrefix string `envconfig:"default=sap.kyma.custom"`
+	// EventSource is the source of the events in the Subscriptions of the triggers, the NATS backend matches events
+	// of all sources if it is empty, BEB requires its namespace
+	EventSource string `envconfig:"optional"`
+}
+
+type ImageGCConfig struct {
+	Enabled  bool          `envconfig:"default=false"`
+	Interval time.Duration `envconfig:"default=1h"`
+	// RetentionCount is the number of the latest revisions of a Function whose images are kept,
+	// images of revisions used by spec.traffic are kept above it
+	RetentionCount int `envconfig:"default=10"`
+	// DryRun only reports the unreferenced images, they are deleted only if it is disabled explicitly
+	DryRun bool `envconfig:"default=true"`
+	// RecentImagePeriod keeps the images that the Function reconciler found in the registry or started to build within it,
+	// the Functions may not reference them yet
+	RecentImagePeriod time.Duration `envconfig:"default=15m"`
+	RegistryTimeout   time.Duration `envconfig:"default=30s"`
+	// CollectBlobs runs the garbage collection of the registry in a Job after images were deleted, which frees their storage
+	CollectBlobs bool `envconfig:"default=true"`
+	Registry     registrygc.Config
 }
 
 type DockerConfig struct {
 	ActiveRegistryConfigSecretName string
 	PushAddress                    string
 	PullAddress                    string
+	Username                       string
+	Password                       string
+	// RegistryCA is the PEM encoded CA of the registry, which is trusted in addition to the system ones
+	RegistryCA string
+	// InsecureRegistry is reached with HTTP if HTTPS is not available
+	InsecureRegistry bool
+}
+
+// registryAccess returns the configuration the controller uses to reach the registry
+func (c DockerConfig) registryAccess() docker.Access {
+	return docker.Access{
+		Credentials: docker.Credentials{
+			Username: c.Username,
+			Password: c.Password,
+		},
+		CA:       []byte(c.RegistryCA),
+		Insecure: c.InsecureRegistry,
+	}
 }
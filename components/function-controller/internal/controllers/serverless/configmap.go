@@ -57,8 +57,8 @@ func (r *FunctionReconciler) updateConfigMap(ctx context.Context, log logr.Logge
 	})
 }
 
-func (r *FunctionReconciler) isOnConfigMapChange(instance *serverlessv1alpha1.Function, rtm runtime.Runtime, configMaps []corev1.ConfigMap, deployments []appsv1.Deployment, dockerConfig DockerConfig) bool {
-	image := r.buildImageAddress(instance, dockerConfig.PullAddress)
+func (r *FunctionReconciler) isOnConfigMapChange(instance *serverlessv1alpha1.Function, rtm runtime.Runtime, configMaps []corev1.ConfigMap, deployments []appsv1.Deployment, dockerConfig DockerConfig, buildHash string) bool {
+	image := r.buildImageAddress(instance, dockerConfig.PullAddress, buildHash)
 	configurationStatus := r.getConditionStatus(instance.Status.Conditions, serverlessv1alpha1.ConditionConfigurationReady)
 
 	if len(deployments) == 1 &&
@@ -70,8 +70,7 @@ func (r *FunctionReconciler) isOnConfigMapChange(instance *serverlessv1alpha1.Fu
 	}
 
 	return !(len(configMaps) == 1 &&
-		instance.Spec.Source == configMaps[0].Data[FunctionSourceKey] &&
-		rtm.SanitizeDependencies(instance.Spec.Deps) == configMaps[0].Data[FunctionDepsKey] &&
+		r.mapsEqual(configMaps[0].Data, r.buildConfigMap(instance, rtm).Data) &&
 		configurationStatus == corev1.ConditionTrue &&
 		r.mapsEqual(configMaps[0].Labels, r.functionLabels(instance)))
 }
@@ -3,8 +3,7 @@ package serverless
 import (
 	"context"
 	"fmt"
-
-	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -30,26 +29,46 @@ const (
 	MinimumReplicasAvailable = "MinimumReplicasAvailable"
 )
 
-func (r *FunctionReconciler) isOnDeploymentChange(instance *serverlessv1alpha1.Function, rtmConfig runtime.Config, deployments []appsv1.Deployment, dockerConfig DockerConfig) bool {
-	expectedDeployment := r.buildDeployment(instance, rtmConfig, dockerConfig)
-	resourceOk := len(deployments) == 1 && r.equalDeployments(deployments[0], expectedDeployment, isScalingEnabled(instance))
+func (r *FunctionReconciler) isOnDeploymentChange(instance *serverlessv1alpha1.Function, expectedDeployments []appsv1.Deployment, deployments []appsv1.Deployment) bool {
+	if len(deployments) != len(expectedDeployments) {
+		return true
+	}
+
+	for _, expectedDeployment := range expectedDeployments {
+		revisionDeployments := filterRevisionDeployments(deployments, deploymentRevision(expectedDeployment))
+		if len(revisionDeployments) != 1 || !r.equalDeployments(revisionDeployments[0], expectedDeployment, isScalingEnabled(instance)) {
+			return true
+		}
+	}
 
-	return !resourceOk
+	return false
 }
 
-func (r *FunctionReconciler) onDeploymentChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, rtmConfig runtime.Config, deployments []appsv1.Deployment, dockerConfig DockerConfig) (ctrl.Result, error) {
-	newDeployment := r.buildDeployment(instance, rtmConfig, dockerConfig)
+func (r *FunctionReconciler) onDeploymentChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, expectedDeployments []appsv1.Deployment, deployments []appsv1.Deployment) (ctrl.Result, error) {
+	var currentDeployments []appsv1.Deployment
+	for _, newDeployment := range expectedDeployments {
+		revision := deploymentRevision(newDeployment)
+		revisionDeployments := filterRevisionDeployments(deployments, revision)
 
-	switch {
-	case len(deployments) == 0:
-		return r.createDeployment(ctx, log, instance, newDeployment)
-	case len(deployments) > 1: // this step is needed, as sometimes informers lag behind reality, and then we create 2 (or more) deployments by accident
-		return r.deleteAllDeployments(ctx, instance, log)
-	case !r.equalDeployments(deployments[0], newDeployment, isScalingEnabled(instance)):
-		return r.updateDeployment(ctx, log, instance, deployments[0], newDeployment)
-	default:
-		return r.updateDeploymentStatus(ctx, log, instance, deployments, corev1.ConditionUnknown)
+		switch {
+		case len(revisionDeployments) == 0:
+			return r.createDeployment(ctx, log, instance, newDeployment)
+		case len(revisionDeployments) > 1: // this step is needed, as sometimes informers lag behind reality, and then we create 2 (or more) deployments by accident
+			return r.deleteRevisionDeployments(ctx, instance, log, revision)
+		case !r.equalDeployments(revisionDeployments[0], newDeployment, isScalingEnabled(instance)):
+			return r.updateDeployment(ctx, log, instance, revisionDeployments[0], newDeployment)
+		}
+		currentDeployments = append(currentDeployments, revisionDeployments[0])
+	}
+
+	// Deployments of revisions without requests are deleted once the revisions receiving requests are ready,
+	// so that the Function is available while switching between revisions
+	staleDeployments := filterStaleDeployments(deployments, expectedDeployments)
+	if len(staleDeployments) > 0 && r.areDeploymentsReady(currentDeployments) {
+		return r.deleteDeployment(ctx, log, staleDeployments[0])
 	}
+
+	return r.updateDeploymentStatus(ctx, log, instance, currentDeployments, corev1.ConditionUnknown)
 }
 
 func (r *FunctionReconciler) createDeployment(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, deployment appsv1.Deployment) (ctrl.Result, error) {
@@ -95,40 +114,102 @@ func (r *FunctionReconciler) equalDeployments(existing appsv1.Deployment, expect
 		len(existing.Spec.Template.Spec.Containers) == len(expected.Spec.Template.Spec.Containers) &&
 		existing.Spec.Template.Spec.Containers[0].Image == expected.Spec.Template.Spec.Containers[0].Image &&
 		r.envsEqual(existing.Spec.Template.Spec.Containers[0].Env, expected.Spec.Template.Spec.Containers[0].Env) &&
+		equalVolumes(existing.Spec.Template.Spec.Volumes, expected.Spec.Template.Spec.Volumes) &&
+		equalVolumeMounts(existing.Spec.Template.Spec.Containers[0].VolumeMounts, expected.Spec.Template.Spec.Containers[0].VolumeMounts) &&
 		r.mapsEqual(existing.GetLabels(), expected.GetLabels()) &&
 		r.mapsEqual(existing.Spec.Template.GetLabels(), expected.Spec.Template.GetLabels()) &&
 		equalResources(existing.Spec.Template.Spec.Containers[0].Resources, expected.Spec.Template.Spec.Containers[0].Resources) &&
-		(scalingEnabled || equalInt32Pointer(existing.Spec.Replicas, expected.Spec.Replicas))
+		(scalingEnabled || equalInt32Pointer(existing.Spec.Replicas, expected.Spec.Replicas)) &&
+		// functions with minReplicas 0 are scaled to and from zero by the controller, not by the HorizontalPodAutoscaler
+		isZeroReplicas(existing.Spec.Replicas) == isZeroReplicas(expected.Spec.Replicas)
+}
+
+// equalVolumes compares the sources of the volumes, ignoring fields defaulted by the API server
+func equalVolumes(existing, expected []corev1.Volume) bool {
+	if len(existing) != len(expected) {
+		return false
+	}
+	for i := range existing {
+		if existing[i].Name != expected[i].Name ||
+			volumeSourceName(existing[i].VolumeSource) != volumeSourceName(expected[i].VolumeSource) {
+			return false
+		}
+	}
+	return true
+}
+
+func volumeSourceName(source corev1.VolumeSource) string {
+	switch {
+	case source.ConfigMap != nil:
+		return "configMap/" + source.ConfigMap.Name
+	case source.Secret != nil:
+		return "secret/" + source.Secret.SecretName
+	case source.EmptyDir != nil:
+		return "emptyDir"
+	default:
+		return ""
+	}
+}
+
+func equalVolumeMounts(existing, expected []corev1.VolumeMount) bool {
+	if len(existing) != len(expected) {
+		return false
+	}
+	for i := range existing {
+		if existing[i].Name != expected[i].Name ||
+			existing[i].MountPath != expected[i].MountPath ||
+			existing[i].ReadOnly != expected[i].ReadOnly {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeroReplicas(replicas *int32) bool {
+	return replicas != nil && *replicas == 0
 }
 
 func (r *FunctionReconciler) updateDeploymentStatus(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, deployments []appsv1.Deployment, runningStatus corev1.ConditionStatus) (ctrl.Result, error) {
+	deployment := r.deploymentToReport(deployments)
+
 	switch {
 	// this step is both in onDeploymentChange and as last step in reconcile
 	// it's checked here in onDeploymentChange to prevent nasty data races, where somehow deployment becomes ready before we
 	// trigger next reconcile loop, in which we should create svc
-	case r.isDeploymentReady(deployments[0]):
-		log.Info(fmt.Sprintf("Deployment %s is ready", deployments[0].GetName()))
+	case r.isDeploymentReady(deployment) && isZeroReplicas(deployment.Spec.Replicas):
+		log.Info(fmt.Sprintf("Deployment %s is scaled to zero", deployment.GetName()))
 		return r.updateStatusWithoutRepository(ctx, ctrl.Result{
 			RequeueAfter: r.config.FunctionReadyRequeueDuration,
+		}, instance, serverlessv1alpha1.Condition{
+			Type:               serverlessv1alpha1.ConditionRunning,
+			Status:             runningStatus,
+			LastTransitionTime: metav1.Now(),
+			Reason:             serverlessv1alpha1.ConditionReasonScaledToZero,
+			Message:            fmt.Sprintf("Deployment %s is scaled to zero", deployment.GetName()),
+		})
+	case r.isDeploymentReady(deployment):
+		log.Info(fmt.Sprintf("Deployment %s is ready", deployment.GetName()))
+		return r.updateStatusWithoutRepository(ctx, ctrl.Result{
+			RequeueAfter: r.readyRequeueDuration(instance, time.Now()),
 		}, instance, serverlessv1alpha1.Condition{
 			Type:               serverlessv1alpha1.ConditionRunning,
 			Status:             runningStatus,
 			LastTransitionTime: metav1.Now(),
 			Reason:             serverlessv1alpha1.ConditionReasonDeploymentReady,
-			Message:            fmt.Sprintf("Deployment %s is ready", deployments[0].GetName()),
+			Message:            fmt.Sprintf("Deployment %s is ready", deployment.GetName()),
 		})
-	case r.hasDeploymentConditionTrueStatus(deployments[0], appsv1.DeploymentProgressing):
-		log.Info(fmt.Sprintf("Deployment %s is not ready yet", deployments[0].GetName()))
+	case r.hasDeploymentConditionTrueStatus(deployment, appsv1.DeploymentProgressing):
+		log.Info(fmt.Sprintf("Deployment %s is not ready yet", deployment.GetName()))
 		return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
 			Type:               serverlessv1alpha1.ConditionRunning,
 			Status:             corev1.ConditionUnknown,
 			LastTransitionTime: metav1.Now(),
 			Reason:             serverlessv1alpha1.ConditionReasonDeploymentWaiting,
-			Message:            fmt.Sprintf("Deployment %s is not ready yet", deployments[0].GetName()),
+			Message:            fmt.Sprintf("Deployment %s is not ready yet", deployment.GetName()),
 		})
 	default:
-		log.Info(fmt.Sprintf("Deployment %s failed", deployments[0].GetName()))
-		yamlConditions, err := yaml.Marshal(deployments[0].Status.Conditions)
+		log.Info(fmt.Sprintf("Deployment %s failed", deployment.GetName()))
+		yamlConditions, err := yaml.Marshal(deployment.Status.Conditions)
 		if err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "while marshalling deployment status to yaml")
 		}
@@ -137,7 +218,7 @@ func (r *FunctionReconciler) updateDeploymentStatus(ctx context.Context, log log
 			Status:             corev1.ConditionFalse,
 			LastTransitionTime: metav1.Now(),
 			Reason:             serverlessv1alpha1.ConditionReasonDeploymentFailed,
-			Message:            fmt.Sprintf("Deployment %s failed with condition: \n%s", deployments[0].GetName(), yamlConditions),
+			Message:            fmt.Sprintf("Deployment %s failed with condition: \n%s", deployment.GetName(), yamlConditions),
 		})
 	}
 }
@@ -147,6 +228,25 @@ func (r *FunctionReconciler) isDeploymentReady(deployment appsv1.Deployment) boo
 		r.hasDeploymentConditionTrueStatusWithReason(deployment, appsv1.DeploymentProgressing, NewRSAvailableReason)
 }
 
+// deploymentToReport returns the first Deployment that is not ready, the status of the Function depends on the slowest revision
+func (r *FunctionReconciler) deploymentToReport(deployments []appsv1.Deployment) appsv1.Deployment {
+	for _, deployment := range deployments {
+		if !r.isDeploymentReady(deployment) {
+			return deployment
+		}
+	}
+	return deployments[0]
+}
+
+func (r *FunctionReconciler) areDeploymentsReady(deployments []appsv1.Deployment) bool {
+	for _, deployment := range deployments {
+		if !r.isDeploymentReady(deployment) {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *FunctionReconciler) hasDeploymentConditionTrueStatus(deployment appsv1.Deployment, conditionType appsv1.DeploymentConditionType) bool {
 	for _, condition := range deployment.Status.Conditions {
 		if condition.Type == conditionType {
@@ -173,14 +273,56 @@ func equalResources(existing, expected corev1.ResourceRequirements) bool {
 		existing.Limits.Cpu().Equal(*expected.Limits.Cpu())
 }
 
-func (r *FunctionReconciler) deleteAllDeployments(ctx context.Context, instance *serverlessv1alpha1.Function, log logr.Logger) (ctrl.Result, error) {
-	log.Info("Deleting all underlying Deployments")
-	selector := apilabels.SelectorFromSet(r.internalFunctionLabels(instance))
+func (r *FunctionReconciler) deleteRevisionDeployments(ctx context.Context, instance *serverlessv1alpha1.Function, log logr.Logger, revision string) (ctrl.Result, error) {
+	log.Info(fmt.Sprintf("Deleting underlying Deployments of revision %s", revision))
+	selector := apilabels.SelectorFromSet(r.mergeLabels(r.internalFunctionLabels(instance), revisionLabels(revision)))
 	if err := r.client.DeleteAllBySelector(ctx, &appsv1.Deployment{}, instance.GetNamespace(), selector); err != nil {
-		log.Error(err, "Cannot delete underlying Deployments")
+		log.Error(err, fmt.Sprintf("Cannot delete underlying Deployments of revision %s", revision))
 		return ctrl.Result{}, err
 	}
 
-	log.Info("Underlying Deployments deleted")
+	log.Info(fmt.Sprintf("Underlying Deployments of revision %s deleted", revision))
 	return ctrl.Result{}, nil
 }
+
+func (r *FunctionReconciler) deleteDeployment(ctx context.Context, log logr.Logger, deployment appsv1.Deployment) (ctrl.Result, error) {
+	log.Info(fmt.Sprintf("Deleting Deployment %s", deployment.GetName()))
+	if err := r.client.Delete(ctx, &deployment); err != nil {
+		log.Error(err, fmt.Sprintf("Cannot delete Deployment %s", deployment.GetName()))
+		return ctrl.Result{}, err
+	}
+
+	log.Info(fmt.Sprintf("Deployment %s deleted", deployment.GetName()))
+	return ctrl.Result{}, nil
+}
+
+func deploymentRevision(deployment appsv1.Deployment) string {
+	return deployment.GetLabels()[serverlessv1alpha1.FunctionRevisionLabel]
+}
+
+func filterRevisionDeployments(deployments []appsv1.Deployment, revision string) []appsv1.Deployment {
+	var result []appsv1.Deployment
+	for _, deployment := range deployments {
+		if deploymentRevision(deployment) == revision {
+			result = append(result, deployment)
+		}
+	}
+	return result
+}
+
+// filterStaleDeployments returns the Deployments of revisions that do not receive requests anymore,
+// including the Deployments created before the Function had revisions
+func filterStaleDeployments(deployments []appsv1.Deployment, expectedDeployments []appsv1.Deployment) []appsv1.Deployment {
+	expectedRevisions := map[string]bool{}
+	for _, deployment := range expectedDeployments {
+		expectedRevisions[deploymentRevision(deployment)] = true
+	}
+
+	var result []appsv1.Deployment
+	for _, deployment := range deployments {
+		if !expectedRevisions[deploymentRevision(deployment)] {
+			result = append(result, deployment)
+		}
+	}
+	return result
+}
@@ -11,6 +11,7 @@ import (
 )
 
 func TestFunctionReconciler_equalDeployments(t *testing.T) {
+	defaultMode := int32(0644)
 	type args struct {
 		existing       appsv1.Deployment
 		expected       appsv1.Deployment
@@ -361,6 +362,40 @@ func TestFunctionReconciler_equalDeployments(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "volumes match except for fields defaulted by the API server",
+			args: args{
+				existing: fixDeploymentWithVolume(corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "config"},
+					DefaultMode:          &defaultMode,
+				}}, "/config"),
+				expected: fixDeploymentWithVolume(corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "config"},
+				}}, "/config"),
+				scalingEnabled: true,
+			},
+			want: true,
+		},
+		{
+			name: "volume sources differ",
+			args: args{
+				existing: fixDeploymentWithVolume(corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "config"},
+				}}, "/config"),
+				expected:       fixDeploymentWithVolume(corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "config"}}, "/config"),
+				scalingEnabled: true,
+			},
+			want: false,
+		},
+		{
+			name: "volume mount paths differ",
+			args: args{
+				existing:       fixDeploymentWithVolume(corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}, "/certs"),
+				expected:       fixDeploymentWithVolume(corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}, "/etc/certs"),
+				scalingEnabled: true,
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -679,3 +714,10 @@ func fixDeploymentWithReplicas(replicas int32) appsv1.Deployment {
 		},
 	}
 }
+
+func fixDeploymentWithVolume(source corev1.VolumeSource, mountPath string) appsv1.Deployment {
+	deployment := fixDeploymentWithReplicas(1)
+	deployment.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "volume", VolumeSource: source}}
+	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "volume", MountPath: mountPath, ReadOnly: true}}
+	return deployment
+}
@@ -3,19 +3,22 @@ package serverless
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
 
 const (
 	FunctionSourceKey = "source"
 	FunctionDepsKey   = "dependencies"
+	// functionFileKeyPrefix is the prefix of the ConfigMap keys of the additional source files
+	functionFileKeyPrefix = "file-"
 )
 
 var (
@@ -56,30 +59,57 @@ func (r *FunctionReconciler) envsEqual(existing, expected []corev1.EnvVar) bool
 	return true
 }
 
-func (r *FunctionReconciler) calculateImageTag(instance *serverlessv1alpha1.Function) string {
-	hash := sha256.Sum256([]byte(strings.Join([]string{
-		string(instance.GetUID()),
+// calculateBuildHash returns the content hash of the build inputs of the Function, which are its sources, its resolved
+// runtime with the Dockerfile and the file names of the runtime, and the registry it is pushed to. It does not depend on the identity of the Function, so Functions
+// of a Namespace built from the same inputs share the image tagged with it.
+func (r *FunctionReconciler) calculateBuildHash(instance *serverlessv1alpha1.Function, rtmCfg runtime.Config, dockerConfig DockerConfig) string {
+	fields := []string{
+		string(rtmCfg.Runtime),
+		rtmCfg.FunctionFile,
+		rtmCfg.DependencyFile,
+		rtmCfg.Dockerfile,
+		dockerConfig.PushAddress,
+		dockerConfig.PullAddress,
+	}
+	if instance.Spec.Type == serverlessv1alpha1.SourceTypeGit {
+		return hashFields(append(fields, r.gitBuildInputs(instance)...)...)
+	}
+	return hashFields(append(fields, r.buildInputs(instance)...)...)
+}
+
+func (r *FunctionReconciler) buildInputs(instance *serverlessv1alpha1.Function) []string {
+	inputs := []string{
 		instance.Spec.Source,
 		instance.Spec.Deps,
-		string(instance.Status.Runtime),
-	}, "-")))
-
-	return fmt.Sprintf("%x", hash)
+	}
+	for _, filePath := range sortedFilePaths(instance.Spec.Files) {
+		inputs = append(inputs, filePath, instance.Spec.Files[filePath])
+	}
+	return inputs
 }
 
 func (r *FunctionReconciler) updateStatusWithoutRepository(ctx context.Context, result ctrl.Result, instance *serverlessv1alpha1.Function, condition serverlessv1alpha1.Condition) (ctrl.Result, error) {
 	return r.updateStatus(ctx, result, instance, condition, nil, "")
 }
 
-func (r *FunctionReconciler) calculateGitImageTag(instance *serverlessv1alpha1.Function) string {
-	data := strings.Join([]string{
-		string(instance.GetUID()),
+func (r *FunctionReconciler) gitBuildInputs(instance *serverlessv1alpha1.Function) []string {
+	return []string{
 		instance.Status.Commit,
 		instance.Status.Repository.BaseDir,
-		string(instance.Status.Runtime),
-	}, "-")
-	hash := sha256.Sum256([]byte(data))
-	return fmt.Sprintf("%x", hash)
+	}
+}
+
+// hashFields returns the hex encoded SHA-256 hash of the fields. Every field is prefixed with its length,
+// so that moving content from one field to another changes the hash.
+func hashFields(fields ...string) string {
+	hash := sha256.New()
+	for _, field := range fields {
+		length := make([]byte, 8)
+		binary.BigEndian.PutUint64(length, uint64(len(field)))
+		hash.Write(length)
+		hash.Write([]byte(field))
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
 }
 
 func (r *FunctionReconciler) updateStatus(ctx context.Context, result ctrl.Result, instance *serverlessv1alpha1.Function, condition serverlessv1alpha1.Condition, repository *serverlessv1alpha1.Repository, commit string) (ctrl.Result, error) {
@@ -185,3 +215,13 @@ func (r *FunctionReconciler) getConditionReason(conditions []serverlessv1alpha1.
 
 	return ""
 }
+
+func (r *FunctionReconciler) getConditionMessage(conditions []serverlessv1alpha1.Condition, conditionType serverlessv1alpha1.ConditionType) string {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Message
+		}
+	}
+
+	return ""
+}
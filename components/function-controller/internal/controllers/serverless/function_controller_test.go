@@ -6,9 +6,13 @@ import (
 	"testing"
 
 	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/automock"
+	fnRuntime "github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
 
@@ -48,6 +52,157 @@ func newFixFunction(namespace, name string, minReplicas, maxReplicas int) *serve
 	}
 }
 
+func newMockedImageChecker(exists bool) *automock.ImageChecker {
+	checker := new(automock.ImageChecker)
+	checker.On("ImageExists", mock.Anything, mock.Anything, mock.Anything).Return(exists, nil)
+	return checker
+}
+
+func TestFunctionReconciler_calculateBuildHash(t *testing.T) {
+	withUID := func(function *serverlessv1alpha1.Function, uid string) *serverlessv1alpha1.Function {
+		function.UID = types.UID(uid)
+		return function
+	}
+	gitFunction := func(name, commit string) *serverlessv1alpha1.Function {
+		function := withUID(newFixFunction("test", name, 1, 1), name)
+		function.Spec.Type = serverlessv1alpha1.SourceTypeGit
+		function.Status.Commit = commit
+		function.Status.Repository.BaseDir = "/"
+		return function
+	}
+
+	rtmCfg := fnRuntime.Config{
+		Runtime:        serverlessv1alpha1.Nodejs12,
+		FunctionFile:   "handler.js",
+		DependencyFile: "package.json",
+		Dockerfile:     "FROM node:12",
+	}
+	dockerConfig := DockerConfig{PushAddress: "registry.kyma.local", PullAddress: "registry.kyma.local"}
+
+	testCases := []struct {
+		name         string
+		first        *serverlessv1alpha1.Function
+		second       *serverlessv1alpha1.Function
+		secondRtmCfg *fnRuntime.Config
+		secondDocker *DockerConfig
+		wantEqual    bool
+	}{
+		{
+			name:      "inline functions with the same source, dependencies and runtime",
+			first:     withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second:    withUID(newFixFunction("other", "second", 1, 1), "second"),
+			wantEqual: true,
+		},
+		{
+			name:  "inline functions with different sources",
+			first: withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second: func() *serverlessv1alpha1.Function {
+				function := withUID(newFixFunction("test", "second", 1, 1), "second")
+				function.Spec.Source = "module.exports = {main: function(event, context) {return 'Bye.'}}"
+				return function
+			}(),
+			wantEqual: false,
+		},
+		{
+			name: "inline functions with content moved between the source and the dependencies",
+			first: func() *serverlessv1alpha1.Function {
+				function := withUID(newFixFunction("test", "first", 1, 1), "first")
+				function.Spec.Source = "module.exports = {}-a"
+				function.Spec.Deps = "b"
+				return function
+			}(),
+			second: func() *serverlessv1alpha1.Function {
+				function := withUID(newFixFunction("test", "second", 1, 1), "second")
+				function.Spec.Source = "module.exports = {}"
+				function.Spec.Deps = "a-b"
+				return function
+			}(),
+			wantEqual: false,
+		},
+		{
+			name:         "inline functions with different Dockerfiles",
+			first:        withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second:       withUID(newFixFunction("test", "second", 1, 1), "second"),
+			secondRtmCfg: &fnRuntime.Config{Runtime: serverlessv1alpha1.Nodejs12, FunctionFile: "handler.js", DependencyFile: "package.json", Dockerfile: "FROM node:12-alpine"},
+			wantEqual:    false,
+		},
+		{
+			name:         "inline functions pushed to different registries",
+			first:        withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second:       withUID(newFixFunction("test", "second", 1, 1), "second"),
+			secondDocker: &DockerConfig{PushAddress: "registry.example.com", PullAddress: "registry.example.com"},
+			wantEqual:    false,
+		},
+		{
+			name:         "inline functions with different runtimes",
+			first:        withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second:       withUID(newFixFunction("test", "second", 1, 1), "second"),
+			secondRtmCfg: &fnRuntime.Config{Runtime: serverlessv1alpha1.Nodejs14, FunctionFile: "handler.js", DependencyFile: "package.json", Dockerfile: "FROM node:12"},
+			wantEqual:    false,
+		},
+		{
+			name:         "inline functions with different file names of the runtime",
+			first:        withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second:       withUID(newFixFunction("test", "second", 1, 1), "second"),
+			secondRtmCfg: &fnRuntime.Config{Runtime: serverlessv1alpha1.Nodejs12, FunctionFile: "index.js", DependencyFile: "package.json", Dockerfile: "FROM node:12"},
+			wantEqual:    false,
+		},
+		{
+			name:  "inline functions whose runtime in the status lags behind the spec",
+			first: withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second: func() *serverlessv1alpha1.Function {
+				function := withUID(newFixFunction("test", "second", 1, 1), "second")
+				function.Status.Runtime = serverlessv1alpha1.RuntimeExtendedNodejs14
+				return function
+			}(),
+			wantEqual: true,
+		},
+		{
+			name:  "inline functions with different additional files",
+			first: withUID(newFixFunction("test", "first", 1, 1), "first"),
+			second: func() *serverlessv1alpha1.Function {
+				function := withUID(newFixFunction("test", "second", 1, 1), "second")
+				function.Spec.Files = map[string]string{"lib/helper.js": "module.exports = {}"}
+				return function
+			}(),
+			wantEqual: false,
+		},
+		{
+			name:      "git functions with the same commit",
+			first:     gitFunction("first", "a376218bdcd705cc39aa7ce7f310769fab6d51c9"),
+			second:    gitFunction("second", "a376218bdcd705cc39aa7ce7f310769fab6d51c9"),
+			wantEqual: true,
+		},
+		{
+			name:      "git functions with different commits",
+			first:     gitFunction("first", "a376218bdcd705cc39aa7ce7f310769fab6d51c9"),
+			second:    gitFunction("second", "8e4f5f4d9b2a61a0a0e86bb2b3ecf3c7d0a1c2f1"),
+			wantEqual: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			r := &FunctionReconciler{}
+
+			secondRtmCfg, secondDocker := rtmCfg, dockerConfig
+			if testCase.secondRtmCfg != nil {
+				secondRtmCfg = *testCase.secondRtmCfg
+			}
+			if testCase.secondDocker != nil {
+				secondDocker = *testCase.secondDocker
+			}
+
+			first := r.calculateBuildHash(testCase.first, rtmCfg, dockerConfig)
+			second := r.calculateBuildHash(testCase.second, secondRtmCfg, secondDocker)
+
+			g.Expect(first == second).To(gomega.Equal(testCase.wantEqual))
+			g.Expect(r.buildImageAddress(testCase.first, "registry.kyma.local", first)).To(gomega.Equal("registry.kyma.local/test-functions:" + first))
+		})
+	}
+}
+
 func TestFunctionReconciler_getConditionStatus(t *testing.T) {
 	type args struct {
 		conditions    []serverlessv1alpha1.Condition
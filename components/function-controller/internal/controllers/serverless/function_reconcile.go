@@ -10,15 +10,23 @@ import (
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/kyma-project/kyma/components/function-controller/internal/buildlog"
 	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/kubernetes"
 	fnRuntime "github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
+	"github.com/kyma-project/kyma/components/function-controller/internal/docker"
 	"github.com/kyma-project/kyma/components/function-controller/internal/git"
 	"github.com/kyma-project/kyma/components/function-controller/internal/resource"
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
@@ -30,34 +38,69 @@ type GitOperator interface {
 	Clone(path string, options git.Options) (string, error)
 }
 
+//go:generate mockery -name=ImageChecker -output=automock -outpkg=automock -case=underscore
+type ImageChecker interface {
+	ImageExists(ctx context.Context, image string, access docker.Access) (bool, error)
+}
+
+//go:generate mockery -name=BuildDiagnoser -output=automock -outpkg=automock -case=underscore
+type BuildDiagnoser interface {
+	Diagnose(ctx context.Context, job batchv1.Job) (*buildlog.Diagnosis, error)
+}
+
 type FunctionReconciler struct {
-	Log         logr.Logger
-	client      resource.Client
-	recorder    record.EventRecorder
-	config      FunctionConfig
-	scheme      *runtime.Scheme
-	gitOperator GitOperator
+	Log          logr.Logger
+	client       resource.Client
+	recorder     record.EventRecorder
+	config       FunctionConfig
+	scheme       *runtime.Scheme
+	gitOperator  GitOperator
+	imageChecker ImageChecker
+	// imageActivity records the images checked or built, so that the image garbage collection keeps them
+	imageActivity *ImageActivity
+	// buildDiagnoser reads the logs of failed build Jobs to report the cause of the failure
+	buildDiagnoser BuildDiagnoser
+	// gitEvents requeues git Functions on pushes to their repositories, see the gitwebhook package
+	gitEvents <-chan event.GenericEvent
 }
 
-func NewFunction(client resource.Client, log logr.Logger, config FunctionConfig, recorder record.EventRecorder) *FunctionReconciler {
+func NewFunction(client resource.Client, log logr.Logger, config FunctionConfig, recorder record.EventRecorder, imageActivity *ImageActivity, buildDiagnoser BuildDiagnoser, gitEvents <-chan event.GenericEvent) *FunctionReconciler {
 	return &FunctionReconciler{
-		client:      client,
-		Log:         log.WithName("controllers").WithName("function"),
-		config:      config,
-		recorder:    recorder,
-		gitOperator: git.New(),
+		client:         client,
+		Log:            log.WithName("controllers").WithName("function"),
+		config:         config,
+		recorder:       recorder,
+		gitOperator:    git.New(),
+		imageChecker:   docker.NewRegistryClient(config.Build.ImageCheckTimeout),
+		imageActivity:  imageActivity,
+		buildDiagnoser: buildDiagnoser,
+		gitEvents:      gitEvents,
 	}
 }
 
 func (r *FunctionReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		Named("function-controller").
 		For(&serverlessv1alpha1.Function{}).
+		Watches(&source.Kind{Type: &serverlessv1alpha1.FunctionRuntime{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.functionsForRuntime),
+		}).
+		Watches(&source.Channel{Source: r.gitEvents}, &handler.EnqueueRequestForObject{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&batchv1.Job{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
-		Owns(&autoscalingv1.HorizontalPodAutoscaler{}).
+		Owns(&autoscalingv1.HorizontalPodAutoscaler{})
+
+	// the Subscriptions can only be watched if eventing is installed, otherwise the Functions with triggers only
+	// get a condition about the missing Subscription CRD
+	if isSubscriptionInstalled(mgr.GetRESTMapper()) {
+		builder = builder.Owns(newSubscription())
+	} else {
+		r.Log.Info(fmt.Sprintf("Subscription CRD %s not found, the Subscriptions of Functions are not watched", subscriptionGVK.GroupKind()))
+	}
+
+	return builder.
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 1, // Build job scheduling mechanism requires this parameter to be set to 1. The mechanism is based on getting active and stateless jobs, concurrent reconciles makes it non deterministic . Value 1 removes data races while fetching list of jobs. https://github.com/kyma-project/kyma/issues/10037
 		}).
@@ -67,7 +110,8 @@ func (r *FunctionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // Reconcile reads that state of the cluster for a Function object and makes changes based on the state read and what is in the Function.Spec
 // +kubebuilder:rbac:groups="serverless.kyma-project.io",resources=functions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="serverless.kyma-project.io",resources=functions/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="serverless.kyma-project.io",resources=gitrepositories,verbs=get
+// +kubebuilder:rbac:groups="serverless.kyma-project.io",resources=gitrepositories,verbs=get;list;watch
+// +kubebuilder:rbac:groups="serverless.kyma-project.io",resources=functionruntimes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups="apps",resources=deployments/status,verbs=get
 // +kubebuilder:rbac:groups="batch",resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
@@ -75,7 +119,10 @@ func (r *FunctionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;deletecollection
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 // +kubebuilder:rbac:groups="autoscaling",resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;deletecollection
+// +kubebuilder:rbac:groups="eventing.kyma-project.io",resources=subscriptions,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
@@ -97,6 +144,21 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 		return ctrl.Result{}, nil
 	}
 
+	rtmCfg, rtm, err := fnRuntime.Resolve(ctx, r.getFunctionRuntime, instance.Spec.Runtime)
+	if err != nil {
+		if errors.Cause(err) != serverlessv1alpha1.ErrFunctionRuntimeNotFound {
+			log.Error(err, "Cannot get FunctionRuntime")
+			return ctrl.Result{}, err
+		}
+		return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
+			Type:               serverlessv1alpha1.ConditionConfigurationReady,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             serverlessv1alpha1.ConditionReasonRuntimeNotFound,
+			Message:            fmt.Sprintf("Resolving runtime failed: %v", err),
+		})
+	}
+
 	var configMaps corev1.ConfigMapList
 	if err := r.client.ListByLabel(ctx, instance.GetNamespace(), r.internalFunctionLabels(instance), &configMaps); err != nil {
 		log.Error(err, "Cannot list ConfigMaps")
@@ -112,7 +174,7 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 	var runtimeConfigMap corev1.ConfigMapList
 	labels := map[string]string{
 		kubernetes.ConfigLabel:  kubernetes.RuntimeLabelValue,
-		kubernetes.RuntimeLabel: string(instance.Spec.Runtime),
+		kubernetes.RuntimeLabel: string(rtmCfg.Runtime),
 	}
 	if err := r.client.ListByLabel(ctx, instance.GetNamespace(), labels, &runtimeConfigMap); err != nil {
 		log.Error(err, "Cannot list runtime configmap")
@@ -122,6 +184,7 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 	if len(runtimeConfigMap.Items) != 1 {
 		return ctrl.Result{}, fmt.Errorf("Expected one config map, found %d, with labels: %+v", len(runtimeConfigMap.Items), labels)
 	}
+	rtmCfg.Dockerfile = runtimeConfigMap.Items[0].Data[runtimeDockerfileKey]
 
 	var deployments appsv1.DeploymentList
 	if err := r.client.ListByLabel(ctx, instance.GetNamespace(), r.internalFunctionLabels(instance), &deployments); err != nil {
@@ -134,6 +197,14 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 		log.Error(err, "Cannot list Services")
 		return ctrl.Result{}, err
 	}
+	functionServices, privateServices := r.splitServices(services.Items)
+
+	activatorRoutes, err := r.listActivatorRoutes(ctx)
+	if err != nil {
+		log.Error(err, "Cannot list activator routes")
+		return ctrl.Result{}, err
+	}
+	activatorRoute := routedActivatorRoute(instance, functionActivatorRoutes(instance, activatorRoutes))
 
 	var hpas autoscalingv1.HorizontalPodAutoscalerList
 	if err := r.client.ListByLabel(ctx, instance.GetNamespace(), r.internalFunctionLabels(instance), &hpas); err != nil {
@@ -141,12 +212,26 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 		return ctrl.Result{}, err
 	}
 
+	subscriptions, subscriptionErr := r.listSubscriptions(ctx, instance)
+	if subscriptionErr != nil && !meta.IsNoMatchError(subscriptionErr) {
+		log.Error(subscriptionErr, "Cannot list Subscriptions")
+		return ctrl.Result{}, subscriptionErr
+	}
+
+	expectedSubscriptions, err := r.buildSubscriptions(instance)
+	if err != nil {
+		log.Error(err, "Cannot build Subscriptions")
+		return ctrl.Result{}, err
+	}
+
 	dockerConfig, err := r.readDockerConfig(ctx, instance)
 	if err != nil {
 		log.Error(err, "Cannot read Docker registry configuration")
 		return ctrl.Result{}, err
 	}
 
+	buildHash := r.calculateBuildHash(instance, rtmCfg, dockerConfig)
+
 	gitOptions, err := r.readGITOptions(ctx, instance)
 	if err != nil {
 		return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
@@ -171,8 +256,11 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 		})
 	}
 
-	rtmCfg := fnRuntime.GetRuntimeConfig(instance.Spec.Runtime)
-	rtm := fnRuntime.GetRuntime(instance.Spec.Runtime)
+	expectedDeployments, revisionErr := r.buildRevisionDeployments(ctx, instance, rtmCfg)
+	if revisionErr != nil && errors.Cause(revisionErr) != errRevisionNotFound && errors.Cause(revisionErr) != errTrafficSplit {
+		log.Error(revisionErr, "Cannot build revision Deployments")
+		return ctrl.Result{}, revisionErr
+	}
 
 	switch {
 	case instance.Spec.Type == serverlessv1alpha1.SourceTypeGit && r.isOnSourceChange(instance, revision):
@@ -180,18 +268,38 @@ func (r *FunctionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error
 			Reference: instance.Spec.Reference,
 			BaseDir:   instance.Spec.Repository.BaseDir,
 		}, revision)
-	case instance.Spec.Type != serverlessv1alpha1.SourceTypeGit && r.isOnConfigMapChange(instance, rtm, configMaps.Items, deployments.Items, dockerConfig):
+	case instance.Spec.Type != serverlessv1alpha1.SourceTypeGit && r.isOnConfigMapChange(instance, rtm, configMaps.Items, deployments.Items, dockerConfig, buildHash):
 		return r.onConfigMapChange(ctx, log, instance, rtm, configMaps.Items)
 	case instance.Spec.Type == serverlessv1alpha1.SourceTypeGit && r.isOnJobChange(instance, rtmCfg, jobs.Items, deployments.Items, gitOptions, dockerConfig):
 		return r.onGitJobChange(ctx, log, instance, rtmCfg, jobs.Items, gitOptions, dockerConfig)
 	case instance.Spec.Type != serverlessv1alpha1.SourceTypeGit && r.isOnJobChange(instance, rtmCfg, jobs.Items, deployments.Items, git.Options{}, dockerConfig):
 		return r.onJobChange(ctx, log, instance, rtmCfg, configMaps.Items[0].GetName(), jobs.Items, dockerConfig)
-	case r.isOnDeploymentChange(instance, rtmCfg, deployments.Items, dockerConfig):
-		return r.onDeploymentChange(ctx, log, instance, rtmCfg, deployments.Items, dockerConfig)
-	case r.isOnServiceChange(instance, services.Items):
-		return r.onServiceChange(ctx, log, instance, services.Items)
+	case r.isOnRevisionChange(instance, dockerConfig, buildHash):
+		return r.onRevisionChange(ctx, log, instance, dockerConfig, buildHash)
+	case revisionErr != nil:
+		return r.onRevisionError(ctx, log, instance, revisionErr)
+	// the route and the Service are switched to the activator before the function is scaled to zero
+	case isScaleToZeroEnabled(instance) && r.isOnActivatorRouteChange(instance, activatorRoutes):
+		return r.onActivatorRouteChange(ctx, log, instance, activatorRoutes)
+	case activatorRoute != nil && r.isOnServiceChange(instance, functionServices, activatorRoute):
+		return r.onServiceChange(ctx, log, instance, functionServices, activatorRoute)
+	case r.isOnDeploymentChange(instance, expectedDeployments, deployments.Items):
+		return r.onDeploymentChange(ctx, log, instance, expectedDeployments, deployments.Items)
+	case r.isOnServiceChange(instance, functionServices, activatorRoute):
+		return r.onServiceChange(ctx, log, instance, functionServices, activatorRoute)
+	// the route is removed once the Service does not point to it anymore
+	case r.isOnActivatorRouteChange(instance, activatorRoutes):
+		return r.onActivatorRouteChange(ctx, log, instance, activatorRoutes)
+	case r.isOnPrivateServiceChange(instance, privateServices):
+		return r.onPrivateServiceChange(ctx, log, instance, privateServices)
 	case r.isOnHorizontalPodAutoscalerChange(instance, hpas.Items, deployments.Items):
 		return r.onHorizontalPodAutoscalerChange(ctx, log, instance, hpas.Items, deployments.Items[0].GetName())
+	case subscriptionErr != nil:
+		return r.onSubscriptionError(ctx, log, instance, subscriptionErr)
+	case r.isOnSubscriptionChange(subscriptions, expectedSubscriptions):
+		return r.onSubscriptionChange(ctx, log, instance, subscriptions, expectedSubscriptions)
+	case r.isOnSubscriptionStatusChange(instance, subscriptions):
+		return r.onSubscriptionStatusChange(ctx, instance, subscriptions)
 	default:
 		return r.updateDeploymentStatus(ctx, log, instance, deployments.Items, corev1.ConditionTrue)
 	}
@@ -223,6 +331,37 @@ func (r *FunctionReconciler) syncRevision(instance *serverlessv1alpha1.Function,
 	return "", nil
 }
 
+func (r *FunctionReconciler) getFunctionRuntime(ctx context.Context, name string) (*serverlessv1alpha1.FunctionRuntime, error) {
+	var functionRuntime serverlessv1alpha1.FunctionRuntime
+	if err := r.client.Get(ctx, client.ObjectKey{Name: name}, &functionRuntime); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &functionRuntime, nil
+}
+
+// functionsForRuntime maps a FunctionRuntime to the Functions using it
+func (r *FunctionReconciler) functionsForRuntime(obj handler.MapObject) []reconcile.Request {
+	var functions serverlessv1alpha1.FunctionList
+	if err := r.client.ListByLabel(context.Background(), "", nil, &functions); err != nil {
+		r.Log.Error(err, "Cannot list Functions", "runtime", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, function := range functions.Items {
+		if string(function.Spec.Runtime) != obj.Meta.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: function.GetNamespace(), Name: function.GetName()},
+		})
+	}
+	return requests
+}
+
 func (r *FunctionReconciler) readGITOptions(ctx context.Context, instance *serverlessv1alpha1.Function) (git.Options, error) {
 	if instance.Spec.Type != serverlessv1alpha1.SourceTypeGit {
 		return git.Options{}, nil
@@ -266,6 +405,10 @@ func (r *FunctionReconciler) readDockerConfig(ctx context.Context, instance *ser
 			ActiveRegistryConfigSecretName: r.config.ImageRegistryExternalDockerConfigSecretName,
 			PushAddress:                    data["registryAddress"],
 			PullAddress:                    data["registryAddress"],
+			Username:                       data["username"],
+			Password:                       data["password"],
+			RegistryCA:                     data["caBundle"],
+			InsecureRegistry:               data["isInsecure"] == "true",
 		}, nil
 	}
 
@@ -277,12 +420,20 @@ func (r *FunctionReconciler) readDockerConfig(ctx context.Context, instance *ser
 				ActiveRegistryConfigSecretName: r.config.ImageRegistryDefaultDockerConfigSecretName,
 				PushAddress:                    data["registryAddress"],
 				PullAddress:                    data["serverAddress"],
+				Username:                       data["username"],
+				Password:                       data["password"],
+				// the internal registry is reached with HTTP inside the cluster
+				InsecureRegistry: true,
 			}, nil
 		} else {
 			return DockerConfig{
 				ActiveRegistryConfigSecretName: r.config.ImageRegistryDefaultDockerConfigSecretName,
 				PushAddress:                    data["registryAddress"],
 				PullAddress:                    data["registryAddress"],
+				Username:                       data["username"],
+				Password:                       data["password"],
+				RegistryCA:                     data["caBundle"],
+				InsecureRegistry:               data["isInsecure"] == "true",
 			}, nil
 		}
 	}
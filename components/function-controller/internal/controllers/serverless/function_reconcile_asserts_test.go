@@ -85,7 +85,7 @@ func assertSuccessfulFunctionBuild(reconciler *FunctionReconciler, request ctrl.
 }
 
 func assertSuccessfulFunctionDeployment(reconciler *FunctionReconciler, request ctrl.Request, fnLabels map[string]string, registryAddress string, redeployment bool) {
-	ginkgo.By("deploy started")
+	ginkgo.By("revision created")
 	result, err := reconciler.Reconcile(request)
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(result.Requeue).To(gomega.BeFalse())
@@ -93,6 +93,20 @@ func assertSuccessfulFunctionDeployment(reconciler *FunctionReconciler, request
 
 	function := &serverlessv1alpha1.Function{}
 	gomega.Expect(resourceClient.Get(context.TODO(), request.NamespacedName, function)).To(gomega.Succeed())
+	gomega.Expect(reconciler.getConditionStatus(function.Status.Conditions, serverlessv1alpha1.ConditionRunning)).To(gomega.Equal(corev1.ConditionUnknown))
+	gomega.Expect(function.Status.LatestRevision).To(gomega.Equal(serverlessv1alpha1.RevisionName(1)))
+	revision, ok := function.Status.FindRevision(function.Status.LatestRevision)
+	gomega.Expect(ok).To(gomega.BeTrue())
+	gomega.Expect(revision.Image).To(gomega.Equal(reconciler.buildImageAddress(function, registryAddress, function.Status.BuildHash)))
+
+	ginkgo.By("deploy started")
+	result, err = reconciler.Reconcile(request)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Requeue).To(gomega.BeFalse())
+	gomega.Expect(result.RequeueAfter).To(gomega.Equal(time.Second * 0))
+
+	function = &serverlessv1alpha1.Function{}
+	gomega.Expect(resourceClient.Get(context.TODO(), request.NamespacedName, function)).To(gomega.Succeed())
 	gomega.Expect(function.Status.Conditions).To(gomega.HaveLen(conditionLen))
 	gomega.Expect(reconciler.getConditionStatus(function.Status.Conditions, serverlessv1alpha1.ConditionConfigurationReady)).To(gomega.Equal(corev1.ConditionTrue))
 	gomega.Expect(reconciler.getConditionStatus(function.Status.Conditions, serverlessv1alpha1.ConditionBuildReady)).To(gomega.Equal(corev1.ConditionTrue))
@@ -104,8 +118,9 @@ func assertSuccessfulFunctionDeployment(reconciler *FunctionReconciler, request
 	deployment := &deployments.Items[0]
 	gomega.Expect(deployment).ToNot(gomega.BeNil())
 	gomega.Expect(deployment.Spec.Template.Spec.Containers).To(gomega.HaveLen(1))
-	gomega.Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(reconciler.buildImageAddress(function, registryAddress)))
-	gomega.Expect(deployment.Spec.Template.Labels).To(gomega.HaveLen(7))
+	gomega.Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(reconciler.buildImageAddress(function, registryAddress, function.Status.BuildHash)))
+	gomega.Expect(deployment.Spec.Template.Labels).To(gomega.HaveLen(8))
+	gomega.Expect(deployment.Spec.Template.Labels[serverlessv1alpha1.FunctionRevisionLabel]).To(gomega.Equal(revision.Name))
 	gomega.Expect(deployment.Spec.Template.Labels[serverlessv1alpha1.FunctionNameLabel]).To(gomega.Equal(function.Name))
 	gomega.Expect(deployment.Spec.Template.Labels[serverlessv1alpha1.FunctionManagedByLabel]).To(gomega.Equal(serverlessv1alpha1.FunctionControllerValue))
 	gomega.Expect(deployment.Spec.Template.Labels[serverlessv1alpha1.FunctionUUIDLabel]).To(gomega.Equal(string(function.UID)))
@@ -149,7 +164,8 @@ func assertSuccessfulFunctionDeployment(reconciler *FunctionReconciler, request
 	gomega.Expect(svc.Spec.Ports[0].TargetPort).To(gomega.Equal(intstr.FromInt(8080)))
 
 	gomega.Expect(labels.AreLabelsInWhiteList(svc.Spec.Selector, job.Spec.Template.Labels)).To(gomega.BeFalse(), "svc selector should not catch job pods")
-	gomega.Expect(svc.Spec.Selector).To(gomega.Equal(deployment.Spec.Selector.MatchLabels))
+	gomega.Expect(labels.AreLabelsInWhiteList(svc.Spec.Selector, deployment.Spec.Selector.MatchLabels)).To(gomega.BeTrue(), "svc selector should catch pods of all revisions")
+	gomega.Expect(svc.Spec.Selector).NotTo(gomega.HaveKey(serverlessv1alpha1.FunctionRevisionLabel))
 
 	if !redeployment {
 		ginkgo.By("hpa creation")
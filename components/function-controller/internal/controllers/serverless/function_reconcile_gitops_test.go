@@ -197,11 +197,12 @@ var _ = ginkgo.Describe("Function", func() {
 				gomega.Expect(resourceClient.Create(context.TODO(), function)).To(gomega.Succeed())
 
 				reconciler = &FunctionReconciler{
-					Log:         log.Log,
-					client:      resourceClient,
-					recorder:    record.NewFakeRecorder(100),
-					config:      config,
-					gitOperator: operator,
+					Log:          log.Log,
+					client:       resourceClient,
+					recorder:     record.NewFakeRecorder(100),
+					config:       config,
+					gitOperator:  operator,
+					imageChecker: newMockedImageChecker(false),
 				}
 
 				fnLabels = reconciler.internalFunctionLabels(function)
@@ -346,6 +347,17 @@ var _ = ginkgo.Describe("Function", func() {
 				gomega.Expect(function).To(haveUnknownConditionRunning)
 				gomega.Expect(function).To(haveConditionReasonJobFinished)
 
+				ginkgo.By("revision created")
+				gomega.Ω(reconciler.Reconcile(request)).To(beOKReconcileResult)
+
+				function = &serverlessv1alpha1.Function{}
+				gomega.Expect(resourceClient.Get(context.TODO(), request.NamespacedName, function)).To(gomega.Succeed())
+				gomega.Expect(function).To(haveConditionLen(conditionLen))
+				gomega.Expect(function).To(haveUnknownConditionRunning)
+				gomega.Expect(function.Status.LatestRevision).To(gomega.Equal(serverlessv1alpha1.RevisionName(1)))
+				gomega.Expect(function.Status.Revisions).To(gomega.HaveLen(1))
+				gomega.Expect(function.Status.Revisions[0].Commit).To(gomega.Equal("a376218bdcd705cc39aa7ce7f310769fab6d51c9"))
+
 				ginkgo.By("deploy started")
 				gomega.Ω(reconciler.Reconcile(request)).To(beOKReconcileResult)
 
@@ -363,10 +375,11 @@ var _ = ginkgo.Describe("Function", func() {
 				gomega.Expect(len(deployments.Items)).To(gomega.Equal(1))
 
 				deployment := &deployments.Items[0]
-				expectedImage := reconciler.buildImageAddress(function, "registry.kyma.local")
+				expectedImage := reconciler.buildImageAddress(function, "registry.kyma.local", function.Status.BuildHash)
 				gomega.Expect(deployment).To(gomega.Not(gomega.BeNil()))
 				gomega.Expect(deployment).To(haveSpecificContainer0Image(expectedImage))
-				gomega.Expect(deployment).To(haveLabelLen(7))
+				gomega.Expect(deployment).To(haveLabelLen(8))
+				gomega.Expect(deployment).To(haveLabelWithValue(serverlessv1alpha1.FunctionRevisionLabel, serverlessv1alpha1.RevisionName(1)))
 				gomega.Expect(deployment).To(haveLabelWithValue(serverlessv1alpha1.FunctionNameLabel, function.Name))
 				gomega.Expect(deployment).To(haveLabelWithValue(serverlessv1alpha1.FunctionManagedByLabel, serverlessv1alpha1.FunctionControllerValue))
 				gomega.Expect(deployment).To(haveLabelWithValue(serverlessv1alpha1.FunctionUUIDLabel, string(function.UID)))
@@ -399,7 +412,8 @@ var _ = ginkgo.Describe("Function", func() {
 				gomega.Expect(labels.AreLabelsInWhiteList(svc.Spec.Selector, job.Spec.Template.Labels)).
 					To(gomega.BeFalse(), "svc selector should not catch job pods")
 
-				gomega.Expect(svc.Spec.Selector).To(gomega.Equal(deployment.Spec.Selector.MatchLabels))
+				gomega.Expect(labels.AreLabelsInWhiteList(svc.Spec.Selector, deployment.Spec.Selector.MatchLabels)).
+					To(gomega.BeTrue(), "svc selector should catch pods of all revisions")
 
 				ginkgo.By("hpa creation")
 				gomega.Ω(reconciler.Reconcile(request)).To(beOKReconcileResult)
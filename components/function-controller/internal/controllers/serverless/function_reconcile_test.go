@@ -42,7 +42,8 @@ var _ = ginkgo.Describe("Function", func() {
 		request = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: function.GetNamespace(), Name: function.GetName()}}
 		gomega.Expect(resourceClient.Create(context.TODO(), function)).To(gomega.Succeed())
 
-		reconciler = NewFunction(resourceClient, log.Log, config, record.NewFakeRecorder(100))
+		reconciler = NewFunction(resourceClient, log.Log, config, record.NewFakeRecorder(100), NewImageActivity(), nil, nil)
+		reconciler.imageChecker = newMockedImageChecker(false)
 		fnLabels = reconciler.internalFunctionLabels(function)
 	})
 
@@ -154,6 +155,10 @@ var _ = ginkgo.Describe("Function", func() {
 		_, err = reconciler.Reconcile(request)
 		gomega.Expect(err).To(gomega.BeNil())
 
+		ginkgo.By("creating revision")
+		_, err = reconciler.Reconcile(request)
+		gomega.Expect(err).To(gomega.BeNil())
+
 		ginkgo.By("creating deployment")
 		_, err = reconciler.Reconcile(request)
 		gomega.Expect(err).To(gomega.BeNil())
@@ -285,6 +290,10 @@ var _ = ginkgo.Describe("Function", func() {
 		_, err = reconciler.Reconcile(request)
 		gomega.Expect(err).To(gomega.BeNil())
 
+		ginkgo.By("creating revision")
+		_, err = reconciler.Reconcile(request)
+		gomega.Expect(err).To(gomega.BeNil())
+
 		ginkgo.By("creating deployment")
 		_, err = reconciler.Reconcile(request)
 		gomega.Expect(err).To(gomega.BeNil())
@@ -533,6 +542,10 @@ var _ = ginkgo.Describe("Function", func() {
 			_, err = reconciler.Reconcile(request)
 			gomega.Expect(err).To(gomega.BeNil())
 
+			ginkgo.By("creating revision")
+			_, err = reconciler.Reconcile(request)
+			gomega.Expect(err).To(gomega.BeNil())
+
 			ginkgo.By("creating deployment")
 			_, err = reconciler.Reconcile(request)
 			gomega.Expect(err).To(gomega.BeNil())
@@ -626,7 +639,7 @@ var _ = ginkgo.Describe("Function", func() {
 			err = reconciler.client.ListByLabel(context.TODO(), function.GetNamespace(), fnLabels, deployList)
 			gomega.Expect(err).To(gomega.BeNil())
 			gomega.Expect(deployList.Items).To(gomega.HaveLen(1))
-			gomega.Expect(deployList.Items[0].Labels).To(gomega.HaveLen(4))
+			gomega.Expect(deployList.Items[0].Labels).To(gomega.HaveLen(5))
 
 			deployLabelVal, ok := deployList.Items[0].Labels[addedLabelKey]
 			gomega.Expect(ok).To(gomega.BeTrue())
@@ -815,6 +828,10 @@ var _ = ginkgo.Describe("Function", func() {
 			_, err = reconciler.Reconcile(request)
 			gomega.Expect(err).To(gomega.BeNil())
 
+			ginkgo.By("creating revision")
+			_, err = reconciler.Reconcile(request)
+			gomega.Expect(err).To(gomega.BeNil())
+
 			ginkgo.By("creating deployment")
 			_, err = reconciler.Reconcile(request)
 			gomega.Expect(err).To(gomega.BeNil())
@@ -845,6 +862,42 @@ var _ = ginkgo.Describe("Function", func() {
 
 	})
 
+	ginkgo.It("should skip the build if the image already exists", func() {
+		reconciler.imageChecker = newMockedImageChecker(true)
+
+		ginkgo.By("creating cm")
+		_, err := reconciler.Reconcile(request)
+		gomega.Expect(err).To(gomega.BeNil())
+
+		ginkgo.By("finding the image")
+		result, err := reconciler.Reconcile(request)
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(result.Requeue).To(gomega.BeFalse())
+
+		function := &serverlessv1alpha1.Function{}
+		gomega.Expect(resourceClient.Get(context.TODO(), request.NamespacedName, function)).To(gomega.Succeed())
+		gomega.Expect(reconciler.getConditionStatus(function.Status.Conditions, serverlessv1alpha1.ConditionBuildReady)).To(gomega.Equal(corev1.ConditionTrue))
+		gomega.Expect(reconciler.getConditionReason(function.Status.Conditions, serverlessv1alpha1.ConditionBuildReady)).To(gomega.Equal(serverlessv1alpha1.ConditionReasonImageFound))
+		gomega.Expect(function.Status.BuildHash).NotTo(gomega.BeEmpty())
+
+		jobList := &batchv1.JobList{}
+		gomega.Expect(reconciler.client.ListByLabel(context.TODO(), function.GetNamespace(), fnLabels, jobList)).To(gomega.Succeed())
+		gomega.Expect(jobList.Items).To(gomega.HaveLen(0))
+
+		ginkgo.By("creating revision")
+		_, err = reconciler.Reconcile(request)
+		gomega.Expect(err).To(gomega.BeNil())
+
+		ginkgo.By("creating deployment")
+		_, err = reconciler.Reconcile(request)
+		gomega.Expect(err).To(gomega.BeNil())
+
+		deployments := &appsv1.DeploymentList{}
+		gomega.Expect(reconciler.client.ListByLabel(context.TODO(), function.GetNamespace(), fnLabels, deployments)).To(gomega.Succeed())
+		gomega.Expect(deployments.Items).To(gomega.HaveLen(1))
+		gomega.Expect(deployments.Items[0].Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(reconciler.buildImageAddress(function, "registry.kyma.local", function.Status.BuildHash)))
+	})
+
 	ginkgo.It("should handle reconcilation lags", func() {
 		ginkgo.By("handling not existing Function")
 		result, err := reconciler.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "nope", Name: "noooooopppeee"}})
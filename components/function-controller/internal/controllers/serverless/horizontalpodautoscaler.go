@@ -36,14 +36,14 @@ func (r *FunctionReconciler) onHorizontalPodAutoscalerChange(ctx context.Context
 	switch {
 	case hpasNum == 0:
 		{
-			if !equalInt32Pointer(instance.Spec.MinReplicas, instance.Spec.MaxReplicas) {
+			if isScalingEnabled(instance) {
 				return r.createHorizontalPodAutoscaler(ctx, log, instance, newHpa)
 			}
 			return ctrl.Result{}, nil
 		}
 	case hpasNum > 1: // case len(hpas)>1: this step is needed, as sometimes informers lag behind reality, and then we create 2 (or more) hpas by accident
 		return r.deleteAllHorizontalPodAutoscalers(ctx, instance, log)
-	case hpasNum == 1 && equalInt32Pointer(instance.Spec.MinReplicas, instance.Spec.MaxReplicas):
+	case hpasNum == 1 && !isScalingEnabled(instance):
 		// this case is when we previously created HPA with maxReplicas > minReplicas, but now user changed
 		// function spec and NOW maxReplicas == minReplicas, so hpa is not needed anymore
 		return r.deleteAllHorizontalPodAutoscalers(ctx, instance, log)
@@ -70,6 +70,14 @@ func equalInt32Pointer(first *int32, second *int32) bool {
 }
 
 func isScalingEnabled(instance *serverlessv1alpha1.Function) bool {
+	if isTrafficSplit(instance) {
+		// the HorizontalPodAutoscaler scales a single Deployment, so it would change the split between revisions
+		return false
+	}
+	if isScaleToZeroEnabled(instance) {
+		// functions with minReplicas 0 are scaled between one and maxReplicas replicas by the HorizontalPodAutoscaler
+		return instance.Spec.MaxReplicas != nil && *instance.Spec.MaxReplicas > 1
+	}
 	return !equalInt32Pointer(instance.Spec.MinReplicas, instance.Spec.MaxReplicas)
 }
 
@@ -304,6 +304,22 @@ func Test_isScalingEnabled(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "scaling disabled for function scaled to zero with one replica",
+			args: args{
+				minReplicas: 0,
+				maxReplicas: 1,
+			},
+			want: false,
+		},
+		{
+			name: "scaling enabled for function scaled to zero with multiple replicas",
+			args: args{
+				minReplicas: 0,
+				maxReplicas: 3,
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
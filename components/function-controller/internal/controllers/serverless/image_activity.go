@@ -0,0 +1,92 @@
+package serverless
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/docker"
+)
+
+// ImageActivity records the images of Functions that the Function reconciler recently found in the registry or started
+// to build. The image garbage collection keeps such images, as the Function status or the build Job may not reference
+// them yet, e.g. right after a build was skipped because its image already existed.
+type ImageActivity struct {
+	// mutex guards the touched images, it is held by the garbage collection only while it deletes a single image
+	mutex sync.Mutex
+	// touched maps the images in the form of repository:tag to the last time they were touched
+	touched map[string]time.Time
+	// collectingBlobs is true while the garbage collection of the registry runs, no image is touched in the meantime
+	collectingBlobs bool
+}
+
+func NewImageActivity() *ImageActivity {
+	return &ImageActivity{touched: map[string]time.Time{}}
+}
+
+// Touch records that the image is about to be checked or built. It returns false without recording the image while
+// the garbage collection of the registry runs, the image must be neither checked nor built until it is over.
+func (a *ImageActivity) Touch(image string, now time.Time) bool {
+	_, repository, tag, err := docker.ParseImage(image)
+	if err != nil {
+		return true
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.collectingBlobs {
+		return false
+	}
+	a.touched[repository+":"+tag] = now
+	return true
+}
+
+// Recent returns the images touched within the period before now, images touched before the period are forgotten
+func (a *ImageActivity) Recent(now time.Time, period time.Duration) map[string]bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	recent := map[string]bool{}
+	for image, touched := range a.touched {
+		if now.Sub(touched) > period {
+			delete(a.touched, image)
+			continue
+		}
+		recent[image] = true
+	}
+	return recent
+}
+
+// DeleteUntouched runs remove unless any of the images was touched within the period before now, and returns whether
+// it ran. No image is touched until it returns, so remove must be a single bounded request.
+func (a *ImageActivity) DeleteUntouched(images []string, now time.Time, period time.Duration, remove func() error) (bool, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, image := range images {
+		if touched, ok := a.touched[image]; ok && now.Sub(touched) <= period {
+			return false, nil
+		}
+	}
+	return true, remove()
+}
+
+// StartCollectingBlobs returns true and makes Touch fail until StopCollectingBlobs is called, unless any image was
+// touched within the period before now
+func (a *ImageActivity) StartCollectingBlobs(now time.Time, period time.Duration) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, touched := range a.touched {
+		if now.Sub(touched) <= period {
+			return false
+		}
+	}
+	a.collectingBlobs = true
+	return true
+}
+
+func (a *ImageActivity) StopCollectingBlobs() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.collectingBlobs = false
+}
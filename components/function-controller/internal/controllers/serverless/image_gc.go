@@ -0,0 +1,371 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/docker"
+	"github.com/kyma-project/kyma/components/function-controller/internal/resource"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+var (
+	imageGCRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "serverless_image_gc_runs_total",
+		Help: "Number of garbage collection passes over the images of Functions in the internal registry",
+	}, []string{"result"})
+	imageGCDeletedImagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "serverless_image_gc_deleted_images_total",
+		Help: "Number of images of Functions deleted from the internal registry, dry_run is true for images that would be deleted",
+	}, []string{"dry_run"})
+	imageGCImages = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "serverless_image_gc_images",
+		Help: "Number of images of Functions in the internal registry found by the last garbage collection pass",
+	}, []string{"state"})
+	imageGCBlobRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "serverless_image_gc_blob_runs_total",
+		Help: "Number of garbage collections of the blobs of deleted images in the internal registry, skipped while images are built",
+	}, []string{"result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(imageGCRunsTotal, imageGCDeletedImagesTotal, imageGCImages, imageGCBlobRunsTotal)
+}
+
+// ImageRegistry lists and deletes images with the Docker Registry HTTP API V2
+type ImageRegistry interface {
+	ListRepositories(ctx context.Context, registry string, access docker.Access) ([]string, error)
+	ListTags(ctx context.Context, registry, repository string, access docker.Access) ([]string, error)
+	ImageDigest(ctx context.Context, image string, access docker.Access) (string, error)
+	DeleteManifest(ctx context.Context, registry, repository, digest string, access docker.Access) error
+}
+
+// BlobCollector removes the blobs of the internal registry which are not referenced by any manifest and returns the
+// name of the Job which removed them
+type BlobCollector interface {
+	CollectBlobs(ctx context.Context) (string, error)
+}
+
+// ImageCollector periodically deletes the images of Functions from the internal registry that are not referenced anymore.
+// An image is referenced by the revisions of a Function kept by the retention count or used by its spec.traffic,
+// by the Deployments of Functions, by the build Jobs still pushing it and by the recent image activity of the Function
+// reconciler. Images of deleted Functions are not referenced. After images were deleted, the blobs of their layers are
+// removed by the garbage collection of the registry, once no image is being built.
+type ImageCollector struct {
+	client   resource.Client
+	log      logr.Logger
+	config   ImageGCConfig
+	registry ImageRegistry
+	blobs    BlobCollector
+	activity *ImageActivity
+	// registrySecret is the default registry configuration, which is copied to the namespaces of Functions
+	registrySecret types.NamespacedName
+	// blobsPending is true while the blobs of deleted images were not collected yet
+	blobsPending bool
+}
+
+func NewImageCollector(client resource.Client, log logr.Logger, config ImageGCConfig, registrySecret types.NamespacedName, activity *ImageActivity, blobs BlobCollector) *ImageCollector {
+	return &ImageCollector{
+		client:         client,
+		log:            log.WithName("image-gc"),
+		config:         config,
+		registry:       docker.NewRegistryClient(config.RegistryTimeout),
+		blobs:          blobs,
+		activity:       activity,
+		registrySecret: registrySecret,
+	}
+}
+
+// Start runs a garbage collection pass every interval until the stop channel is closed
+func (c *ImageCollector) Start(stop <-chan struct{}) error {
+	c.log.Info("Starting image garbage collection", "interval", c.config.Interval, "retentionCount", c.config.RetentionCount, "dryRun", c.config.DryRun)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	wait.Until(func() {
+		if err := c.Collect(ctx); err != nil {
+			imageGCRunsTotal.WithLabelValues("failure").Inc()
+			c.log.Error(err, "Image garbage collection failed")
+			return
+		}
+		imageGCRunsTotal.WithLabelValues("success").Inc()
+	}, c.config.Interval, stop)
+	return nil
+}
+
+// Collect runs a single garbage collection pass over the Function repositories of all Namespaces. Images are deleted
+// by the digest of their manifest, so an image is kept as long as any of its tags is referenced.
+func (c *ImageCollector) Collect(ctx context.Context) error {
+	registry, access, internal, err := c.internalRegistry(ctx)
+	if err != nil {
+		return err
+	}
+	if !internal {
+		c.log.V(1).Info("Internal registry disabled, skipping image garbage collection")
+		return nil
+	}
+
+	// tags are listed before the references, so that images pushed in the meantime are not taken as unreferenced
+	repositories, err := c.registry.ListRepositories(ctx, registry, access)
+	if err != nil {
+		return err
+	}
+	tags := map[string][]string{}
+	for _, repository := range repositories {
+		if !strings.HasSuffix(repository, functionImageRepositorySuffix) {
+			continue
+		}
+		if tags[repository], err = c.registry.ListTags(ctx, registry, repository, access); err != nil {
+			return err
+		}
+	}
+	referenced, err := c.referencedImages(ctx)
+	if err != nil {
+		return err
+	}
+
+	for image := range c.activity.Recent(time.Now(), c.config.RecentImagePeriod) {
+		referenced[image] = true
+	}
+
+	referencedCount, unreferencedCount := 0, 0
+	for _, repository := range repositories {
+		if _, ok := tags[repository]; !ok {
+			continue
+		}
+		referencedDigests, unreferencedDigests, err := c.collectRepository(ctx, registry, repository, tags[repository], referenced, access)
+		if err != nil {
+			return err
+		}
+		referencedCount += referencedDigests
+		unreferencedCount += unreferencedDigests
+	}
+	imageGCImages.WithLabelValues("referenced").Set(float64(referencedCount))
+	imageGCImages.WithLabelValues("unreferenced").Set(float64(unreferencedCount))
+
+	if unreferencedCount > 0 && !c.config.DryRun {
+		c.blobsPending = true
+	}
+	return c.collectBlobs(ctx)
+}
+
+// collectBlobs runs the garbage collection of the registry if images were deleted since it last ran. The blobs of an
+// image being pushed are not referenced by its manifest yet, so it is skipped while images are built or were recently
+// checked, and it runs in a later pass. The Function reconciler requeues the Functions whose images it would check
+// until the garbage collection is over, which takes at most the Job timeout.
+func (c *ImageCollector) collectBlobs(ctx context.Context) error {
+	if !c.blobsPending || c.blobs == nil {
+		return nil
+	}
+
+	building, err := c.isBuilding(ctx)
+	if err != nil {
+		return err
+	}
+	if building || !c.activity.StartCollectingBlobs(time.Now(), c.config.RecentImagePeriod) {
+		c.log.Info("Images are being built or were recently checked, skipping the garbage collection of blobs")
+		imageGCBlobRunsTotal.WithLabelValues("skipped").Inc()
+		return nil
+	}
+	defer c.activity.StopCollectingBlobs()
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Registry.JobTimeout)
+	defer cancel()
+
+	job, err := c.blobs.CollectBlobs(ctx)
+	if err != nil {
+		imageGCBlobRunsTotal.WithLabelValues("failure").Inc()
+		return errors.Wrap(err, "while collecting blobs")
+	}
+	c.log.V(1).Info("Blobs collected", "job", job)
+	imageGCBlobRunsTotal.WithLabelValues("success").Inc()
+	c.blobsPending = false
+	return nil
+}
+
+// isBuilding returns true if any build Job has not finished yet
+func (c *ImageCollector) isBuilding(ctx context.Context) (bool, error) {
+	var jobs batchv1.JobList
+	if err := c.client.ListByLabel(ctx, "", fcManagedByLabel, &jobs); err != nil {
+		return false, errors.Wrap(err, "while listing Jobs")
+	}
+	for _, job := range jobs.Items {
+		if job.Status.CompletionTime == nil && !isJobFailed(job) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isJobFailed(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// collectRepository deletes the unreferenced images of a repository and returns the numbers of the referenced and the
+// unreferenced images. An image touched by the Function reconciler since the references were read is referenced.
+func (c *ImageCollector) collectRepository(ctx context.Context, registry, repository string, tags []string, referenced map[string]bool, access docker.Access) (int, int, error) {
+	tagsByDigest := map[string][]string{}
+	referencedDigests := map[string]bool{}
+	for _, tag := range tags {
+		digest, err := c.registry.ImageDigest(ctx, fmt.Sprintf("%s/%s:%s", registry, repository, tag), access)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "while reading digest of tag %s of repository %s", tag, repository)
+		}
+		if digest == "" {
+			continue
+		}
+		tagsByDigest[digest] = append(tagsByDigest[digest], tag)
+		if referenced[repository+":"+tag] {
+			referencedDigests[digest] = true
+		}
+	}
+
+	unreferenced := make([]string, 0, len(tagsByDigest))
+	for digest := range tagsByDigest {
+		if !referencedDigests[digest] {
+			unreferenced = append(unreferenced, digest)
+		}
+	}
+	sort.Strings(unreferenced)
+
+	referencedCount, unreferencedCount := len(referencedDigests), 0
+	for _, digest := range unreferenced {
+		log := c.log.WithValues("repository", repository, "digest", digest, "tags", tagsByDigest[digest])
+		if c.config.DryRun {
+			log.Info("Unreferenced image not deleted in dry run")
+			imageGCDeletedImagesTotal.WithLabelValues("true").Inc()
+			unreferencedCount++
+			continue
+		}
+
+		images := make([]string, 0, len(tagsByDigest[digest]))
+		for _, tag := range tagsByDigest[digest] {
+			images = append(images, repository+":"+tag)
+		}
+		// the Function reconciler cannot check an image while it is deleted, so an image it found in the registry is
+		// either touched before and kept, or deleted before it is checked, which builds it again
+		ok, err := c.activity.DeleteUntouched(images, time.Now(), c.config.RecentImagePeriod, func() error {
+			return c.registry.DeleteManifest(ctx, registry, repository, digest, access)
+		})
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "while deleting image %s of repository %s", digest, repository)
+		}
+		if !ok {
+			log.Info("Unreferenced image recently checked, not deleted")
+			referencedCount++
+			continue
+		}
+		log.Info("Unreferenced image deleted")
+		imageGCDeletedImagesTotal.WithLabelValues("false").Inc()
+		unreferencedCount++
+	}
+
+	return referencedCount, unreferencedCount, nil
+}
+
+// internalRegistry reads the push address and the credentials of the internal registry from the default registry configuration
+func (c *ImageCollector) internalRegistry(ctx context.Context) (string, docker.Access, bool, error) {
+	var secret corev1.Secret
+	if err := c.client.Get(ctx, c.registrySecret, &secret); err != nil {
+		return "", docker.Access{}, false, errors.Wrapf(err, "while reading registry configuration %s", c.registrySecret)
+	}
+
+	if string(secret.Data["isInternal"]) != "true" {
+		return "", docker.Access{}, false, nil
+	}
+	return string(secret.Data["registryAddress"]), docker.Access{
+		Credentials: docker.Credentials{
+			Username: string(secret.Data["username"]),
+			Password: string(secret.Data["password"]),
+		},
+		// the internal registry is reached with HTTP inside the cluster
+		Insecure: true,
+	}, true, nil
+}
+
+// referencedImages returns the images in the Function repositories that must not be deleted, in the form of repository:tag
+func (c *ImageCollector) referencedImages(ctx context.Context) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	addImage := func(image string) {
+		if _, repository, tag, err := docker.ParseImage(image); err == nil && strings.HasSuffix(repository, functionImageRepositorySuffix) {
+			referenced[repository+":"+tag] = true
+		}
+	}
+
+	var functions serverlessv1alpha1.FunctionList
+	if err := c.client.ListByLabel(ctx, "", nil, &functions); err != nil {
+		return nil, errors.Wrap(err, "while listing Functions")
+	}
+	for i := range functions.Items {
+		instance := &functions.Items[i]
+		if instance.Status.BuildHash != "" {
+			referenced[functionImageRepository(instance.GetNamespace())+":"+instance.Status.BuildHash] = true
+		}
+		for _, revision := range retainedRevisions(instance, c.config.RetentionCount) {
+			addImage(revision.Image)
+		}
+	}
+
+	var jobs batchv1.JobList
+	if err := c.client.ListByLabel(ctx, "", fcManagedByLabel, &jobs); err != nil {
+		return nil, errors.Wrap(err, "while listing Jobs")
+	}
+	for _, job := range jobs.Items {
+		for _, container := range job.Spec.Template.Spec.Containers {
+			for _, arg := range container.Args {
+				if strings.HasPrefix(arg, destinationArg+"=") {
+					addImage(strings.TrimPrefix(arg, destinationArg+"="))
+				}
+			}
+		}
+	}
+
+	var deployments appsv1.DeploymentList
+	if err := c.client.ListByLabel(ctx, "", fcManagedByLabel, &deployments); err != nil {
+		return nil, errors.Wrap(err, "while listing Deployments")
+	}
+	for _, deployment := range deployments.Items {
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			addImage(container.Image)
+		}
+	}
+
+	return referenced, nil
+}
+
+// retainedRevisions returns the latest revisions within the retention count and the revisions used by the Function
+func retainedRevisions(instance *serverlessv1alpha1.Function, retentionCount int) []serverlessv1alpha1.FunctionRevision {
+	used := usedRevisions(instance)
+	revisions := instance.Status.Revisions
+
+	var retained []serverlessv1alpha1.FunctionRevision
+	for i, revision := range revisions {
+		if used[revision.Name] || i >= len(revisions)-retentionCount {
+			retained = append(retained, revision)
+		}
+	}
+	return retained
+}
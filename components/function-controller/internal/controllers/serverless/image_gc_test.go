@@ -0,0 +1,382 @@
+package serverless
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/resource"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func TestImageCollector_Collect(t *testing.T) {
+	revision := func(name, tag string) serverlessv1alpha1.FunctionRevision {
+		return serverlessv1alpha1.FunctionRevision{Name: name, Image: "localhost:32137/fn-ns-functions:" + tag}
+	}
+	function := func(name string, traffic []serverlessv1alpha1.TrafficTarget, revisions ...serverlessv1alpha1.FunctionRevision) *serverlessv1alpha1.Function {
+		return &serverlessv1alpha1.Function{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "fn-ns"},
+			Spec:       serverlessv1alpha1.FunctionSpec{Traffic: traffic},
+			Status: serverlessv1alpha1.FunctionStatus{
+				Revisions:      revisions,
+				LatestRevision: revisions[len(revisions)-1].Name,
+				BuildHash:      strings.TrimPrefix(revisions[len(revisions)-1].Image, "localhost:32137/fn-ns-functions:"),
+			},
+		}
+	}
+	buildJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "fn-build", Namespace: "fn-ns", Labels: fcManagedByLabel},
+		Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Args: []string{"--insecure", destinationArg + "=registry.kyma-system.svc.cluster.local:5000/fn-ns-functions:building"},
+		}}}}},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "fn-deployment", Namespace: "fn-ns", Labels: fcManagedByLabel},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Image: "localhost:32137/fn-ns-functions:deployed",
+		}}}}},
+	}
+
+	testCases := []struct {
+		name       string
+		config     ImageGCConfig
+		isInternal string
+		objects    []runtime.Object
+		manifests  map[string]string
+		// otherManifests are the images in the repository of another Namespace
+		otherManifests map[string]string
+		// touched are the images the Function reconciler recently checked
+		touched []string
+		// touchedMeanwhile are the tags the Function reconciler checks while the garbage collection reads their digests
+		touchedMeanwhile []string
+		wantDeleted      []string
+		wantBlobRuns     int
+	}{
+		{
+			name:         "images of deleted Functions",
+			config:       ImageGCConfig{RetentionCount: 10},
+			isInternal:   "true",
+			objects:      []runtime.Object{function("fn", nil, revision("1", "first"))},
+			manifests:    map[string]string{"first": "sha256:1", "deleted": "sha256:2"},
+			wantDeleted:  []string{"sha256:2"},
+			wantBlobRuns: 1,
+		},
+		{
+			name:        "images recently checked by the Function reconciler",
+			config:      ImageGCConfig{RetentionCount: 10},
+			isInternal:  "true",
+			touched:     []string{"registry.kyma-system.svc.cluster.local:5000/fn-ns-functions:checked"},
+			manifests:   map[string]string{"checked": "sha256:1", "deleted": "sha256:2"},
+			wantDeleted: []string{"sha256:2"},
+		},
+		{
+			name:             "images checked by the Function reconciler during the garbage collection",
+			config:           ImageGCConfig{RetentionCount: 10},
+			isInternal:       "true",
+			touchedMeanwhile: []string{"checked-meanwhile"},
+			manifests:        map[string]string{"checked-meanwhile": "sha256:1", "deleted": "sha256:2"},
+			// blobs are not collected while images were recently checked
+			wantDeleted: []string{"sha256:2"},
+		},
+		{
+			name:       "images checked before the recent period",
+			config:     ImageGCConfig{RetentionCount: 10},
+			isInternal: "true",
+			touched:    []string{"registry.kyma-system.svc.cluster.local:5000/fn-ns-functions:checked-before"},
+			manifests:  map[string]string{"checked-before": "sha256:1"},
+			// the image is touched before the recent period, see below
+			wantDeleted:  []string{"sha256:1"},
+			wantBlobRuns: 1,
+		},
+		{
+			name:       "images of revisions beyond the retention count",
+			config:     ImageGCConfig{RetentionCount: 1},
+			isInternal: "true",
+			objects: []runtime.Object{function("fn",
+				[]serverlessv1alpha1.TrafficTarget{{Revision: "1", Percent: 50}, {Percent: 50}},
+				revision("1", "first"), revision("2", "second"), revision("3", "third"))},
+			manifests:    map[string]string{"first": "sha256:1", "second": "sha256:2", "third": "sha256:3"},
+			wantDeleted:  []string{"sha256:2"},
+			wantBlobRuns: 1,
+		},
+		{
+			name:       "images of build Jobs and Deployments",
+			config:     ImageGCConfig{RetentionCount: 10},
+			isInternal: "true",
+			objects:    []runtime.Object{buildJob, deployment},
+			manifests:  map[string]string{"building": "sha256:1", "deployed": "sha256:2", "unused": "sha256:3"},
+			// blobs are not collected while the build Job is running
+			wantDeleted: []string{"sha256:3"},
+		},
+		{
+			name:       "image with a referenced tag",
+			config:     ImageGCConfig{RetentionCount: 10},
+			isInternal: "true",
+			objects:    []runtime.Object{function("fn", nil, revision("1", "first"))},
+			manifests:  map[string]string{"first": "sha256:1", "same-image": "sha256:1"},
+		},
+		{
+			name:           "images of another Namespace with a referenced tag",
+			config:         ImageGCConfig{RetentionCount: 10},
+			isInternal:     "true",
+			objects:        []runtime.Object{function("fn", nil, revision("1", "first"))},
+			manifests:      map[string]string{"first": "sha256:1"},
+			otherManifests: map[string]string{"first": "sha256:2"},
+			wantDeleted:    []string{"sha256:2"},
+			wantBlobRuns:   1,
+		},
+		{
+			name:       "dry run",
+			config:     ImageGCConfig{RetentionCount: 10, DryRun: true},
+			isInternal: "true",
+			manifests:  map[string]string{"deleted": "sha256:1"},
+		},
+		{
+			name:       "external registry",
+			config:     ImageGCConfig{RetentionCount: 10},
+			isInternal: "false",
+			manifests:  map[string]string{"deleted": "sha256:1"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			registry := &registryStandIn{repositories: map[string]map[string]string{
+				"fn-ns-functions":    testCase.manifests,
+				"other-ns-functions": testCase.otherManifests,
+				"unrelated":          {"latest": "sha256:unrelated"},
+			}}
+			server := httptest.NewServer(registry)
+			defer server.Close()
+			activity := NewImageActivity()
+			registry.onHead = func(tag string) {
+				for _, touched := range testCase.touchedMeanwhile {
+					if tag == touched {
+						g.Expect(activity.Touch("registry.kyma-system.svc.cluster.local:5000/fn-ns-functions:"+tag, time.Now())).To(gomega.BeTrue())
+					}
+				}
+			}
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-config-default", Namespace: "kyma-system"},
+				Data: map[string][]byte{
+					"isInternal":      []byte(testCase.isInternal),
+					"registryAddress": []byte(strings.TrimPrefix(server.URL, "http://")),
+				},
+			}
+			scheme := runtime.NewScheme()
+			g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+			g.Expect(serverlessv1alpha1.AddToScheme(scheme)).To(gomega.Succeed())
+			client := resource.New(fake.NewFakeClientWithScheme(scheme, append(testCase.objects, secret)...), scheme)
+
+			testCase.config.RegistryTimeout = 5 * time.Second
+			testCase.config.RecentImagePeriod = time.Hour
+			testCase.config.Registry.JobTimeout = time.Minute
+			for _, image := range testCase.touched {
+				touched := time.Now()
+				if strings.HasSuffix(image, "-before") {
+					touched = touched.Add(-2 * time.Hour)
+				}
+				activity.Touch(image, touched)
+			}
+			blobs := &blobCollectorStandIn{}
+			collector := NewImageCollector(client, zap.New(), testCase.config, types.NamespacedName{Namespace: "kyma-system", Name: "registry-config-default"}, activity, blobs)
+
+			g.Expect(collector.Collect(context.TODO())).To(gomega.Succeed())
+			g.Expect(registry.deleted).To(gomega.Equal(testCase.wantDeleted))
+			g.Expect(blobs.runs).To(gomega.Equal(testCase.wantBlobRuns))
+		})
+	}
+}
+
+func TestImageCollector_Collect_deletionDisabled(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	registry := &registryStandIn{repositories: map[string]map[string]string{"fn-ns-functions": {"deleted": "sha256:1"}}, deleteDisabled: true}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-config-default", Namespace: "kyma-system"},
+		Data: map[string][]byte{
+			"isInternal":      []byte("true"),
+			"registryAddress": []byte(strings.TrimPrefix(server.URL, "http://")),
+		},
+	}
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(serverlessv1alpha1.AddToScheme(scheme)).To(gomega.Succeed())
+	client := resource.New(fake.NewFakeClientWithScheme(scheme, secret), scheme)
+
+	collector := NewImageCollector(client, zap.New(), ImageGCConfig{RetentionCount: 10, RegistryTimeout: 5 * time.Second},
+		types.NamespacedName{Namespace: "kyma-system", Name: "registry-config-default"}, NewImageActivity(), nil)
+
+	g.Expect(collector.Collect(context.TODO())).To(gomega.HaveOccurred())
+	g.Expect(registry.deleted).To(gomega.BeEmpty())
+}
+
+func TestRetainedRevisions(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	instance := &serverlessv1alpha1.Function{
+		Spec: serverlessv1alpha1.FunctionSpec{Traffic: []serverlessv1alpha1.TrafficTarget{{Revision: "1", Percent: 100}}},
+		Status: serverlessv1alpha1.FunctionStatus{
+			LatestRevision: "4",
+			Revisions:      []serverlessv1alpha1.FunctionRevision{{Name: "1"}, {Name: "2"}, {Name: "3"}, {Name: "4"}},
+		},
+	}
+
+	g.Expect(retainedRevisions(instance, 2)).To(gomega.Equal([]serverlessv1alpha1.FunctionRevision{{Name: "1"}, {Name: "3"}, {Name: "4"}}))
+	g.Expect(retainedRevisions(instance, 0)).To(gomega.Equal([]serverlessv1alpha1.FunctionRevision{{Name: "1"}, {Name: "4"}}))
+	g.Expect(retainedRevisions(instance, 10)).To(gomega.HaveLen(4))
+}
+
+func TestImageActivity(t *testing.T) {
+	const image = "registry.kyma-system.svc.cluster.local:5000/fn-ns-functions:tag"
+	now := time.Now()
+
+	t.Run("keeps images touched within the period", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		activity := NewImageActivity()
+		g.Expect(activity.Touch(image, now.Add(-time.Minute))).To(gomega.BeTrue())
+
+		deleted, err := activity.DeleteUntouched([]string{"fn-ns-functions:other", "fn-ns-functions:tag"}, now, time.Hour, func() error {
+			t.Fatal("touched image deleted")
+			return nil
+		})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(deleted).To(gomega.BeFalse())
+		g.Expect(activity.StartCollectingBlobs(now, time.Hour)).To(gomega.BeFalse())
+	})
+
+	t.Run("forgets images touched before the period", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		activity := NewImageActivity()
+		g.Expect(activity.Touch(image, now.Add(-2*time.Hour))).To(gomega.BeTrue())
+		g.Expect(activity.Recent(now, time.Hour)).To(gomega.BeEmpty())
+
+		deleted, err := activity.DeleteUntouched([]string{"fn-ns-functions:tag"}, now, time.Hour, func() error { return nil })
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(deleted).To(gomega.BeTrue())
+	})
+
+	t.Run("refuses touches while blobs are collected", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		activity := NewImageActivity()
+		g.Expect(activity.StartCollectingBlobs(now, time.Hour)).To(gomega.BeTrue())
+		g.Expect(activity.Touch(image, now)).To(gomega.BeFalse())
+		g.Expect(activity.Recent(now, time.Hour)).To(gomega.BeEmpty())
+
+		activity.StopCollectingBlobs()
+		g.Expect(activity.Touch(image, now)).To(gomega.BeTrue())
+		g.Expect(activity.Recent(now, time.Hour)).To(gomega.HaveKey("fn-ns-functions:tag"))
+	})
+}
+
+// blobCollectorStandIn counts the garbage collections of the registry
+type blobCollectorStandIn struct {
+	runs int
+}
+
+func (b *blobCollectorStandIn) CollectBlobs(context.Context) (string, error) {
+	b.runs++
+	return "", nil
+}
+
+// registryStandIn serves the repositories, their tags and manifests like the Docker registry does
+type registryStandIn struct {
+	mutex sync.Mutex
+	// repositories are the digests of the manifests by their tags
+	repositories   map[string]map[string]string
+	deleteDisabled bool
+	deleted        []string
+	// onHead is called with the tag whose digest is read
+	onHead func(tag string)
+}
+
+func (r *registryStandIn) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if req.Method == http.MethodGet && req.URL.Path == "/v2/_catalog" {
+		repositories := make([]string, 0, len(r.repositories))
+		for repository := range r.repositories {
+			repositories = append(repositories, repository)
+		}
+		sort.Strings(repositories)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"repositories": repositories})
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	var repository string
+	var manifests map[string]string
+	for name, repositoryManifests := range r.repositories {
+		if strings.HasPrefix(path, name+"/") {
+			repository, manifests = name, repositoryManifests
+		}
+	}
+	if repository == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	manifestsPath := repository + "/manifests/"
+	switch {
+	case req.Method == http.MethodGet && path == repository+"/tags/list":
+		tags := make([]string, 0, len(manifests))
+		for tag := range manifests {
+			tags = append(tags, tag)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+	case req.Method == http.MethodHead && strings.HasPrefix(path, manifestsPath):
+		tag := strings.TrimPrefix(path, manifestsPath)
+		if r.onHead != nil {
+			r.onHead(tag)
+		}
+		digest, ok := manifests[tag]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusOK)
+	case req.Method == http.MethodDelete && strings.HasPrefix(path, manifestsPath):
+		if r.deleteDisabled {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		digest := strings.TrimPrefix(path, manifestsPath)
+		for tag, tagDigest := range manifests {
+			if tagDigest == digest {
+				delete(manifests, tag)
+			}
+		}
+		r.deleted = append(r.deleted, digest)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
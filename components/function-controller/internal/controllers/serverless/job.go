@@ -21,10 +21,14 @@ import (
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
 
+// maxEventMessageLength keeps the build log in the failure Event readable in kubectl describe
+const maxEventMessageLength = 1024
+
 var fcManagedByLabel = map[string]string{serverlessv1alpha1.FunctionManagedByLabel: serverlessv1alpha1.FunctionControllerValue}
 
 func (r *FunctionReconciler) isOnJobChange(instance *serverlessv1alpha1.Function, rtmCfg runtime.Config, jobs []batchv1.Job, deployments []appsv1.Deployment, gitOptions git.Options, dockerConfig DockerConfig) bool {
-	image := r.buildImageAddress(instance, dockerConfig.PullAddress)
+	buildHash := r.calculateBuildHash(instance, rtmCfg, dockerConfig)
+	image := r.buildImageAddress(instance, dockerConfig.PullAddress, buildHash)
 	buildStatus := r.getConditionStatus(instance.Status.Conditions, serverlessv1alpha1.ConditionBuildReady)
 
 	var expectedJob batchv1.Job
@@ -34,6 +38,13 @@ func (r *FunctionReconciler) isOnJobChange(instance *serverlessv1alpha1.Function
 		expectedJob = r.buildGitJob(instance, gitOptions, rtmCfg, dockerConfig)
 	}
 
+	// the image was found in the registry, so no Job was created for it
+	if len(jobs) == 0 &&
+		buildStatus == corev1.ConditionTrue &&
+		instance.Status.BuildHash == buildHash {
+		return false
+	}
+
 	if len(deployments) == 1 &&
 		deployments[0].Spec.Template.Spec.Containers[0].Image == image &&
 		buildStatus != corev1.ConditionUnknown &&
@@ -51,11 +62,27 @@ func (r *FunctionReconciler) isOnJobChange(instance *serverlessv1alpha1.Function
 		buildStatus == corev1.ConditionFalse
 }
 
-func (r *FunctionReconciler) changeJob(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, newJob batchv1.Job, jobs []batchv1.Job) (ctrl.Result, error) {
+func (r *FunctionReconciler) changeJob(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, newJob batchv1.Job, jobs []batchv1.Job, dockerConfig DockerConfig, buildHash string) (ctrl.Result, error) {
 	jobsLen := len(jobs)
 
 	switch {
 	case jobsLen == 0:
+		image := r.buildImageAddress(instance, dockerConfig.PushAddress, buildHash)
+		// the image is kept by the garbage collection both if it is found and while it is built
+		if !r.imageActivity.Touch(image, time.Now()) {
+			log.Info("Garbage collection of the registry in progress, requeueing")
+			return ctrl.Result{
+				RequeueAfter: time.Second * 5,
+			}, nil
+		}
+		exists, err := r.imageChecker.ImageExists(ctx, image, dockerConfig.registryAccess())
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Cannot check if image %s exists, building it", image))
+		}
+		if exists {
+			return r.skipJob(ctx, log, instance, image, buildHash)
+		}
+
 		activeJobs, err := r.getActiveAndStatelessJobs(ctx)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -73,17 +100,18 @@ func (r *FunctionReconciler) changeJob(ctx context.Context, log logr.Logger, ins
 	case !r.mapsEqual(jobs[0].GetLabels(), newJob.GetLabels()):
 		return r.updateJobLabels(ctx, log, instance, jobs[0], newJob.GetLabels())
 	default:
-		return r.updateBuildStatus(ctx, log, instance, jobs[0])
+		return r.updateBuildStatus(ctx, log, instance, jobs[0], buildHash)
 	}
 }
 
 func (r *FunctionReconciler) onGitJobChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, rtmCfg runtime.Config, jobs []batchv1.Job, gitOptions git.Options, dockerConfig DockerConfig) (ctrl.Result, error) {
 	newJob := r.buildGitJob(instance, gitOptions, rtmCfg, dockerConfig)
-	return r.changeJob(ctx, log, instance, newJob, jobs)
+	return r.changeJob(ctx, log, instance, newJob, jobs, dockerConfig, r.calculateBuildHash(instance, rtmCfg, dockerConfig))
 }
+
 func (r *FunctionReconciler) onJobChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, rtmCfg runtime.Config, configMapName string, jobs []batchv1.Job, dockerConfig DockerConfig) (ctrl.Result, error) {
 	newJob := r.buildJob(instance, rtmCfg, configMapName, dockerConfig)
-	return r.changeJob(ctx, log, instance, newJob, jobs)
+	return r.changeJob(ctx, log, instance, newJob, jobs, dockerConfig, r.calculateBuildHash(instance, rtmCfg, dockerConfig))
 }
 
 func (r *FunctionReconciler) equalJobs(existing batchv1.Job, expected batchv1.Job) bool {
@@ -123,6 +151,20 @@ func (r *FunctionReconciler) createJob(ctx context.Context, log logr.Logger, ins
 	})
 }
 
+func (r *FunctionReconciler) skipJob(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, image, buildHash string) (ctrl.Result, error) {
+	log.Info(fmt.Sprintf("Image %s already exists, skipping the build", image))
+
+	built := instance.DeepCopy()
+	built.Status.BuildHash = buildHash
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, built, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionBuildReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonImageFound,
+		Message:            fmt.Sprintf("Image %s already exists", image),
+	})
+}
+
 func (r *FunctionReconciler) deleteJobs(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function) (ctrl.Result, error) {
 	log.Info("Deleting all old jobs")
 	selector := apilabels.SelectorFromSet(r.internalFunctionLabels(instance))
@@ -141,11 +183,13 @@ func (r *FunctionReconciler) deleteJobs(ctx context.Context, log logr.Logger, in
 	})
 }
 
-func (r *FunctionReconciler) updateBuildStatus(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, job batchv1.Job) (ctrl.Result, error) {
+func (r *FunctionReconciler) updateBuildStatus(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, job batchv1.Job, buildHash string) (ctrl.Result, error) {
 	switch {
 	case job.Status.CompletionTime != nil:
 		log.Info(fmt.Sprintf("Job %s finished", job.GetName()))
-		return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
+		built := instance.DeepCopy()
+		built.Status.BuildHash = buildHash
+		return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, built, serverlessv1alpha1.Condition{
 			Type:               serverlessv1alpha1.ConditionBuildReady,
 			Status:             corev1.ConditionTrue,
 			LastTransitionTime: metav1.Now(),
@@ -168,11 +212,46 @@ func (r *FunctionReconciler) updateBuildStatus(ctx context.Context, log logr.Log
 			Status:             corev1.ConditionFalse,
 			LastTransitionTime: metav1.Now(),
 			Reason:             serverlessv1alpha1.ConditionReasonJobFailed,
-			Message:            fmt.Sprintf("Job %s failed", job.GetName()),
+			Message:            r.jobFailureMessage(ctx, log, instance, job),
 		})
 	}
 }
 
+// jobFailureMessage returns the message of the BuildReady condition of a failed Job with the classified cause of
+// the failure. The logs are read once per Job, the message is reused until the Job is replaced.
+func (r *FunctionReconciler) jobFailureMessage(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, job batchv1.Job) string {
+	message := fmt.Sprintf("Job %s failed", job.GetName())
+	currentMessage := r.getConditionMessage(instance.Status.Conditions, serverlessv1alpha1.ConditionBuildReady)
+	if r.getConditionReason(instance.Status.Conditions, serverlessv1alpha1.ConditionBuildReady) == serverlessv1alpha1.ConditionReasonJobFailed &&
+		strings.HasPrefix(currentMessage, message) {
+		return currentMessage
+	}
+
+	diagnosis, err := r.buildDiagnoser.Diagnose(ctx, job)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Cannot diagnose the failure of Job %s", job.GetName()))
+		return message
+	}
+
+	log.Info(fmt.Sprintf("Job %s failed with %s", job.GetName(), diagnosis.Reason), "pod", diagnosis.Pod, "container", diagnosis.Container)
+	r.recorder.Event(instance, corev1.EventTypeWarning, string(diagnosis.Reason), lastBytes(diagnosis.Tail, maxEventMessageLength))
+
+	return fmt.Sprintf("%s: %s", message, diagnosis.Summary())
+}
+
+// lastBytes returns the end of the text that fits into the limit, starting at a new line if possible
+func lastBytes(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= limit {
+		return text
+	}
+	text = text[len(text)-limit:]
+	if i := strings.IndexByte(text, '\n'); i >= 0 && i < len(text)-1 {
+		return text[i+1:]
+	}
+	return text
+}
+
 func (r *FunctionReconciler) updateJobLabels(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, job batchv1.Job, newLabels map[string]string) (ctrl.Result, error) {
 	newJob := job.DeepCopy()
 	newJob.Labels = newLabels
@@ -1,11 +1,22 @@
 package serverless
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kyma-project/kyma/components/function-controller/internal/buildlog"
+	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/automock"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
 
 func TestFunctionReconciler_equalJobs(t *testing.T) {
@@ -164,3 +175,83 @@ func TestFunctionReconciler_getArg(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionReconciler_jobFailureMessage(t *testing.T) {
+	job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "fn-build-x7k2p", Namespace: "default"}}
+	diagnosis := &buildlog.Diagnosis{
+		Reason:    buildlog.ReasonDependencyInstall,
+		Pod:       "fn-build-x7k2p-f9w5s",
+		Container: "executor",
+		Line:      "npm ERR! code E404",
+		Tail:      "npm ERR! code E404\nnpm ERR! 404 Not Found - GET https://registry.npmjs.org/lodahs",
+	}
+	failedCondition := func(message string) []serverlessv1alpha1.Condition {
+		return []serverlessv1alpha1.Condition{{
+			Type:    serverlessv1alpha1.ConditionBuildReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  serverlessv1alpha1.ConditionReasonJobFailed,
+			Message: message,
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		conditions []serverlessv1alpha1.Condition
+		diagnosis  *buildlog.Diagnosis
+		err        error
+		want       string
+		wantEvent  bool
+	}{
+		{
+			name:      "reports the classified failure",
+			diagnosis: diagnosis,
+			want:      "Job fn-build-x7k2p failed: DependencyInstallFailed in container executor: npm ERR! code E404",
+			wantEvent: true,
+		},
+		{
+			name: "falls back to the generic message when the logs cannot be read",
+			err:  errors.New("pod not found"),
+			want: "Job fn-build-x7k2p failed",
+		},
+		{
+			name:       "reuses the message of the same Job",
+			conditions: failedCondition("Job fn-build-x7k2p failed: SyntaxError in container executor"),
+			want:       "Job fn-build-x7k2p failed: SyntaxError in container executor",
+		},
+		{
+			name:       "diagnoses a new Job",
+			conditions: failedCondition("Job fn-build-abcde failed: SyntaxError in container executor"),
+			diagnosis:  diagnosis,
+			want:       "Job fn-build-x7k2p failed: DependencyInstallFailed in container executor: npm ERR! code E404",
+			wantEvent:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			diagnoser := new(automock.BuildDiagnoser)
+			diagnoser.On("Diagnose", mock.Anything, job).Return(tt.diagnosis, tt.err)
+			recorder := record.NewFakeRecorder(10)
+			r := &FunctionReconciler{recorder: recorder, buildDiagnoser: diagnoser}
+			instance := &serverlessv1alpha1.Function{Status: serverlessv1alpha1.FunctionStatus{Conditions: tt.conditions}}
+
+			got := r.jobFailureMessage(context.TODO(), zap.New(), instance, job)
+			g.Expect(got).To(gomega.Equal(tt.want))
+
+			if !tt.wantEvent {
+				g.Expect(recorder.Events).To(gomega.BeEmpty())
+				return
+			}
+			g.Expect(recorder.Events).To(gomega.Receive(gomega.HavePrefix("Warning DependencyInstallFailed npm ERR! code E404")))
+		})
+	}
+}
+
+func Test_lastBytes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(lastBytes("short log\n", 100)).To(gomega.Equal("short log"))
+	g.Expect(lastBytes("first line\nsecond line\nthird", 12)).To(gomega.Equal("third"))
+	g.Expect(lastBytes(strings.Repeat("a", 20), 10)).To(gomega.Equal(strings.Repeat("a", 10)))
+}
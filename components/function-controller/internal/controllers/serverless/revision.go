@@ -0,0 +1,248 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	fnRuntime "github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+// errRevisionNotFound is the cause of errors about revisions that are not in the status of the Function
+var errRevisionNotFound = errors.New("revision not found")
+
+// errTrafficSplit is the cause of errors about traffic splits that the replicas of the revisions cannot represent
+var errTrafficSplit = errors.New("traffic split not representable by replicas")
+
+type revisionTraffic struct {
+	revision serverlessv1alpha1.FunctionRevision
+	percent  int32
+}
+
+// isOnRevisionChange returns true if the image built from the current spec is not the latest revision yet.
+func (r *FunctionReconciler) isOnRevisionChange(instance *serverlessv1alpha1.Function, dockerConfig DockerConfig, buildHash string) bool {
+	if r.getConditionStatus(instance.Status.Conditions, serverlessv1alpha1.ConditionBuildReady) != corev1.ConditionTrue {
+		return false
+	}
+
+	revision, ok := findRevisionBySourceHash(instance.Status.Revisions, buildHash)
+	return !ok || instance.Status.LatestRevision != revision.Name ||
+		revision.Image != r.buildImageAddress(instance, dockerConfig.PullAddress, buildHash)
+}
+
+// onRevisionChange records the image built from the current spec as the latest revision. An image built before,
+// e.g. after reverting the source, is restored from the revision history instead of adding a new revision.
+func (r *FunctionReconciler) onRevisionChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, dockerConfig DockerConfig, buildHash string) (ctrl.Result, error) {
+	image := r.buildImageAddress(instance, dockerConfig.PullAddress, buildHash)
+	updated := instance.DeepCopy()
+
+	if revision, ok := findRevisionBySourceHash(instance.Status.Revisions, buildHash); ok {
+		for i := range updated.Status.Revisions {
+			if updated.Status.Revisions[i].Name == revision.Name {
+				updated.Status.Revisions[i].Image = image
+			}
+		}
+		updated.Status.LatestRevision = revision.Name
+		log.Info(fmt.Sprintf("Revision %s restored", revision.Name))
+
+		return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, updated, serverlessv1alpha1.Condition{
+			Type:               serverlessv1alpha1.ConditionRunning,
+			Status:             corev1.ConditionUnknown,
+			LastTransitionTime: metav1.Now(),
+			Reason:             serverlessv1alpha1.ConditionReasonRevisionRestored,
+			Message:            fmt.Sprintf("Revision %s restored", revision.Name),
+		})
+	}
+
+	revision := serverlessv1alpha1.FunctionRevision{
+		Name:       serverlessv1alpha1.RevisionName(lastRevisionNumber(instance.Status.Revisions) + 1),
+		Image:      image,
+		Runtime:    serverlessv1alpha1.RuntimeExtended(instance.Spec.Runtime),
+		Commit:     instance.Status.Commit,
+		SourceHash: buildHash,
+		CreatedAt:  metav1.Now(),
+	}
+	updated.Status.LatestRevision = revision.Name
+	updated.Status.Revisions = trimRevisions(append(updated.Status.Revisions, revision), r.config.RevisionHistoryLimit, usedRevisions(updated))
+	log.Info(fmt.Sprintf("Revision %s created", revision.Name))
+
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, updated, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionRunning,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonRevisionCreated,
+		Message:            fmt.Sprintf("Revision %s created", revision.Name),
+	})
+}
+
+func (r *FunctionReconciler) onRevisionError(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, err error) (ctrl.Result, error) {
+	log.Info(fmt.Sprintf("Cannot deploy revisions: %v", err))
+
+	reason := serverlessv1alpha1.ConditionReasonRevisionNotFound
+	if errors.Cause(err) == errTrafficSplit {
+		reason = serverlessv1alpha1.ConditionReasonTrafficSplitInvalid
+	}
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionRunning,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            fmt.Sprintf("Deploying revisions failed: %v", err),
+	})
+}
+
+// buildRevisionDeployments returns a Deployment for every revision receiving requests. The requests are split
+// between the revisions by the number of their replicas, as the Function Service selects the Pods of all revisions,
+// so a split that the replicas cannot represent exactly is rejected.
+func (r *FunctionReconciler) buildRevisionDeployments(ctx context.Context, instance *serverlessv1alpha1.Function, rtmConfig fnRuntime.Config) ([]appsv1.Deployment, error) {
+	traffic, err := trafficRevisions(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deployments := make([]appsv1.Deployment, 0, len(traffic))
+	for _, target := range traffic {
+		revisionRuntimeConfig := rtmConfig
+		if target.revision.Runtime != "" && target.revision.Runtime != serverlessv1alpha1.RuntimeExtended(instance.Spec.Runtime) {
+			revisionRuntimeConfig, _, err = fnRuntime.Resolve(ctx, r.getFunctionRuntime, serverlessv1alpha1.Runtime(target.revision.Runtime))
+			if errors.Cause(err) == serverlessv1alpha1.ErrFunctionRuntimeNotFound {
+				return nil, errors.Wrapf(errRevisionNotFound, "runtime %s of revision %s not found", target.revision.Runtime, target.revision.Name)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "while resolving runtime of revision %s", target.revision.Name)
+			}
+		}
+
+		replicas, err := revisionReplicas(instance, target.percent, len(traffic), now)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while splitting traffic to revision %s", target.revision.Name)
+		}
+		deployments = append(deployments, r.buildDeployment(instance, revisionRuntimeConfig, target.revision, replicas))
+	}
+
+	return deployments, nil
+}
+
+// trafficRevisions returns the revisions receiving requests, all requests go to the latest revision if spec.traffic is empty
+func trafficRevisions(instance *serverlessv1alpha1.Function) ([]revisionTraffic, error) {
+	latest, ok := instance.Status.FindRevision(instance.Status.LatestRevision)
+	if !ok {
+		return nil, errors.Wrap(errRevisionNotFound, "latest revision not built yet")
+	}
+	if len(instance.Spec.Traffic) == 0 {
+		return []revisionTraffic{{revision: latest, percent: 100}}, nil
+	}
+
+	var traffic []revisionTraffic
+	indexes := map[string]int{}
+	for _, target := range instance.Spec.Traffic {
+		if target.Percent == 0 {
+			continue
+		}
+
+		revision := latest
+		if target.Revision != "" {
+			if revision, ok = instance.Status.FindRevision(target.Revision); !ok {
+				return nil, errors.Wrapf(errRevisionNotFound, "revision %s is not one of the last revisions", target.Revision)
+			}
+		}
+
+		// the latest revision can be referenced both by its name and by an empty name
+		if i, ok := indexes[revision.Name]; ok {
+			traffic[i].percent += target.Percent
+			continue
+		}
+		indexes[revision.Name] = len(traffic)
+		traffic = append(traffic, revisionTraffic{revision: revision, percent: target.Percent})
+	}
+
+	return traffic, nil
+}
+
+// isTrafficSplit returns true if requests to the Function are split between more than one revision
+func isTrafficSplit(instance *serverlessv1alpha1.Function) bool {
+	revisions := map[string]bool{}
+	for _, target := range instance.Spec.Traffic {
+		if target.Percent == 0 {
+			continue
+		}
+		name := target.Revision
+		if name == "" {
+			name = instance.Status.LatestRevision
+		}
+		revisions[name] = true
+	}
+	return len(revisions) > 1
+}
+
+// revisionReplicas returns the replicas of a revision receiving the given percent of requests. The replicas of split
+// revisions are their percents of minReplicas, which have to be whole numbers of replicas.
+func revisionReplicas(instance *serverlessv1alpha1.Function, percent int32, revisions int, now time.Time) (*int32, error) {
+	if revisions == 1 {
+		return deploymentReplicas(instance, now), nil
+	}
+
+	minReplicas := int32(1)
+	if instance.Spec.MinReplicas != nil {
+		minReplicas = *instance.Spec.MinReplicas
+	}
+
+	replicas, ok := serverlessv1alpha1.SplitReplicas(percent, minReplicas)
+	if !ok {
+		return nil, errors.Wrapf(errTrafficSplit, "percent(%d) of minReplicas(%d) is not a whole number of replicas", percent, minReplicas)
+	}
+	return &replicas, nil
+}
+
+// usedRevisions returns the revisions that have to stay in the revision history
+func usedRevisions(instance *serverlessv1alpha1.Function) map[string]bool {
+	used := map[string]bool{instance.Status.LatestRevision: true}
+	for _, target := range instance.Spec.Traffic {
+		if target.Revision != "" {
+			used[target.Revision] = true
+		}
+	}
+	return used
+}
+
+// trimRevisions removes the oldest revisions exceeding the limit, except for the used ones
+func trimRevisions(revisions []serverlessv1alpha1.FunctionRevision, limit int, used map[string]bool) []serverlessv1alpha1.FunctionRevision {
+	excess := len(revisions) - limit
+	result := make([]serverlessv1alpha1.FunctionRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		if excess > 0 && !used[revision.Name] {
+			excess--
+			continue
+		}
+		result = append(result, revision)
+	}
+	return result
+}
+
+func findRevisionBySourceHash(revisions []serverlessv1alpha1.FunctionRevision, sourceHash string) (serverlessv1alpha1.FunctionRevision, bool) {
+	for _, revision := range revisions {
+		if revision.SourceHash == sourceHash {
+			return revision, true
+		}
+	}
+	return serverlessv1alpha1.FunctionRevision{}, false
+}
+
+func lastRevisionNumber(revisions []serverlessv1alpha1.FunctionRevision) int {
+	last := 0
+	for _, revision := range revisions {
+		if number, ok := serverlessv1alpha1.RevisionNumber(revision.Name); ok && number > last {
+			last = number
+		}
+	}
+	return last
+}
@@ -0,0 +1,266 @@
+package serverless
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func Test_trafficRevisions(t *testing.T) {
+	revisions := []serverlessv1alpha1.FunctionRevision{
+		{Name: "v1", Image: "image:1"},
+		{Name: "v2", Image: "image:2"},
+		{Name: "v3", Image: "image:3"},
+	}
+
+	tests := []struct {
+		name           string
+		latestRevision string
+		traffic        []serverlessv1alpha1.TrafficTarget
+		want           map[string]int32
+		wantErr        bool
+	}{
+		{
+			name:           "sends all requests to the latest revision by default",
+			latestRevision: "v3",
+			want:           map[string]int32{"v3": 100},
+		},
+		{
+			name:           "splits requests between revisions",
+			latestRevision: "v3",
+			traffic: []serverlessv1alpha1.TrafficTarget{
+				{Percent: 80},
+				{Revision: "v1", Percent: 20},
+			},
+			want: map[string]int32{"v3": 80, "v1": 20},
+		},
+		{
+			name:           "rolls back to an older revision",
+			latestRevision: "v3",
+			traffic: []serverlessv1alpha1.TrafficTarget{
+				{Revision: "v2", Percent: 100},
+				{Percent: 0},
+			},
+			want: map[string]int32{"v2": 100},
+		},
+		{
+			name:           "merges the latest revision referenced by name and without it",
+			latestRevision: "v3",
+			traffic: []serverlessv1alpha1.TrafficTarget{
+				{Percent: 50},
+				{Revision: "v3", Percent: 30},
+				{Revision: "v2", Percent: 20},
+			},
+			want: map[string]int32{"v3": 80, "v2": 20},
+		},
+		{
+			name:           "fails for a revision outside of the history",
+			latestRevision: "v3",
+			traffic: []serverlessv1alpha1.TrafficTarget{
+				{Percent: 50},
+				{Revision: "v7", Percent: 50},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "fails before the first revision is built",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				Spec: serverlessv1alpha1.FunctionSpec{Traffic: tt.traffic},
+				Status: serverlessv1alpha1.FunctionStatus{
+					Revisions:      revisions,
+					LatestRevision: tt.latestRevision,
+				},
+			}
+
+			got, err := trafficRevisions(instance)
+			if tt.wantErr {
+				g.Expect(errors.Cause(err)).To(gomega.Equal(errRevisionNotFound))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			percents := map[string]int32{}
+			for _, target := range got {
+				percents[target.revision.Name] = target.percent
+			}
+			g.Expect(percents).To(gomega.Equal(tt.want))
+		})
+	}
+}
+
+func Test_revisionReplicas(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	one, four := int32(1), int32(4)
+
+	tests := []struct {
+		name        string
+		minReplicas *int32
+		percent     int32
+		revisions   int
+		want        int32
+		wantErr     bool
+	}{
+		{
+			name:        "returns minReplicas of a single revision",
+			minReplicas: &four,
+			percent:     100,
+			revisions:   1,
+			want:        4,
+		},
+		{
+			name:        "splits minReplicas between revisions",
+			minReplicas: &four,
+			percent:     75,
+			revisions:   2,
+			want:        3,
+		},
+		{
+			name:        "rejects percent below one replica",
+			minReplicas: &four,
+			percent:     5,
+			revisions:   2,
+			wantErr:     true,
+		},
+		{
+			name:        "rejects percent that is not a whole number of replicas",
+			minReplicas: &four,
+			percent:     60,
+			revisions:   2,
+			wantErr:     true,
+		},
+		{
+			name:        "rejects split of a single replica",
+			minReplicas: &one,
+			percent:     90,
+			revisions:   2,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+				Spec:       serverlessv1alpha1.FunctionSpec{MinReplicas: tt.minReplicas},
+			}
+
+			got, err := revisionReplicas(instance, tt.percent, tt.revisions, now)
+			if tt.wantErr {
+				g.Expect(errors.Cause(err)).To(gomega.Equal(errTrafficSplit))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(got).NotTo(gomega.BeNil())
+			g.Expect(*got).To(gomega.Equal(tt.want))
+		})
+	}
+}
+
+func Test_isTrafficSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		traffic []serverlessv1alpha1.TrafficTarget
+		want    bool
+	}{
+		{
+			name: "no traffic targets",
+			want: false,
+		},
+		{
+			name:    "all requests to one revision",
+			traffic: []serverlessv1alpha1.TrafficTarget{{Revision: "v1", Percent: 100}, {Percent: 0}},
+			want:    false,
+		},
+		{
+			name:    "latest revision referenced twice",
+			traffic: []serverlessv1alpha1.TrafficTarget{{Revision: "v2", Percent: 50}, {Percent: 50}},
+			want:    false,
+		},
+		{
+			name:    "requests split between revisions",
+			traffic: []serverlessv1alpha1.TrafficTarget{{Revision: "v1", Percent: 10}, {Percent: 90}},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				Spec:   serverlessv1alpha1.FunctionSpec{Traffic: tt.traffic},
+				Status: serverlessv1alpha1.FunctionStatus{LatestRevision: "v2"},
+			}
+
+			g.Expect(isTrafficSplit(instance)).To(gomega.Equal(tt.want))
+		})
+	}
+}
+
+func Test_trimRevisions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	revisions := []serverlessv1alpha1.FunctionRevision{{Name: "v1"}, {Name: "v2"}, {Name: "v3"}, {Name: "v4"}, {Name: "v5"}}
+
+	got := trimRevisions(revisions, 3, map[string]bool{"v1": true, "v5": true})
+	g.Expect(revisionNames(got)).To(gomega.Equal([]string{"v1", "v4", "v5"}))
+
+	got = trimRevisions(revisions, 10, map[string]bool{"v5": true})
+	g.Expect(revisionNames(got)).To(gomega.Equal([]string{"v1", "v2", "v3", "v4", "v5"}))
+
+	// used revisions are kept even above the limit
+	got = trimRevisions(revisions, 1, map[string]bool{"v2": true, "v5": true})
+	g.Expect(revisionNames(got)).To(gomega.Equal([]string{"v2", "v5"}))
+}
+
+func Test_lastRevisionNumber(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(lastRevisionNumber(nil)).To(gomega.Equal(0))
+	g.Expect(lastRevisionNumber([]serverlessv1alpha1.FunctionRevision{{Name: "v9"}, {Name: "v10"}, {Name: "v2"}})).To(gomega.Equal(10))
+}
+
+func Test_filterStaleDeployments(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	deployment := func(name, revision string) appsv1.Deployment {
+		d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if revision != "" {
+			d.Labels = revisionLabels(revision)
+		}
+		return d
+	}
+
+	deployments := []appsv1.Deployment{
+		deployment("fn-legacy", ""),
+		deployment("fn-v1", "v1"),
+		deployment("fn-v2", "v2"),
+	}
+	expected := []appsv1.Deployment{deployment("", "v2")}
+
+	stale := filterStaleDeployments(deployments, expected)
+	g.Expect(stale).To(gomega.HaveLen(2))
+	g.Expect(stale[0].Name).To(gomega.Equal("fn-legacy"))
+	g.Expect(stale[1].Name).To(gomega.Equal("fn-v1"))
+}
+
+func revisionNames(revisions []serverlessv1alpha1.FunctionRevision) []string {
+	names := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		names = append(names, revision.Name)
+	}
+	return names
+}
@@ -0,0 +1,11 @@
+package runtime
+
+type none struct {
+	Config
+}
+
+func (n none) SanitizeDependencies(dependencies string) string {
+	return dependencies
+}
+
+var _ Runtime = none{}
@@ -1,7 +1,10 @@
 package runtime
 
 import (
+	"context"
+
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -10,73 +13,62 @@ type Runtime interface {
 }
 
 type Config struct {
-	Runtime                 serverlessv1alpha1.Runtime
-	DependencyFile          string
-	FunctionFile            string
-	DockerfileConfigMapName string
-	RuntimeEnvs             []corev1.EnvVar
+	Runtime                   serverlessv1alpha1.Runtime
+	DependencyFile            string
+	FunctionFile              string
+	DockerfileConfigMapName   string
+	RuntimeEnvs               []corev1.EnvVar
+	PackageRegistryConfigFile string
+	// Dockerfile is the content of the Dockerfile the images of the runtime are built with
+	Dockerfile string
+}
+
+// legacyRuntimes maps the runtimes which are no longer supported to the built-in runtimes replacing them
+var legacyRuntimes = map[serverlessv1alpha1.Runtime]serverlessv1alpha1.Runtime{
+	serverlessv1alpha1.Runtime(serverlessv1alpha1.RuntimeExtendedNodejs10): serverlessv1alpha1.Nodejs12,
+}
+
+// Resolve returns the configuration and the dependency sanitizer of the given runtime,
+// looking up its FunctionRuntime first and falling back to the built-in runtimes.
+// Legacy runtimes, e.g. nodejs10, fall back to the built-in runtime replacing them,
+// other unknown runtimes return serverlessv1alpha1.ErrFunctionRuntimeNotFound.
+func Resolve(ctx context.Context, getter serverlessv1alpha1.FunctionRuntimeGetter, r serverlessv1alpha1.Runtime) (Config, Runtime, error) {
+	spec, err := serverlessv1alpha1.ResolveFunctionRuntime(ctx, getter, r)
+	if replacement, ok := legacyRuntimes[r]; ok && errors.Cause(err) == serverlessv1alpha1.ErrFunctionRuntimeNotFound {
+		spec := serverlessv1alpha1.BuiltinFunctionRuntimes[replacement]
+		return newConfig(replacement, spec), GetRuntime(spec.DependencySanitizer), nil
+	}
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	return newConfig(r, spec), GetRuntime(spec.DependencySanitizer), nil
 }
 
 func GetRuntimeConfig(r serverlessv1alpha1.Runtime) Config {
-	switch r {
-	case serverlessv1alpha1.Nodejs12:
-		return Config{
-			Runtime:                 serverlessv1alpha1.Nodejs12,
-			DependencyFile:          "package.json",
-			FunctionFile:            "handler.js",
-			DockerfileConfigMapName: "dockerfile-nodejs-12",
-			RuntimeEnvs: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
-				{Name: "FUNC_RUNTIME", Value: "nodejs12"},
-			},
-		}
-	case serverlessv1alpha1.Nodejs14:
-		return Config{
-			Runtime:                 serverlessv1alpha1.Nodejs14,
-			DependencyFile:          "package.json",
-			FunctionFile:            "handler.js",
-			DockerfileConfigMapName: "dockerfile-nodejs-14",
-			RuntimeEnvs: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
-				{Name: "FUNC_RUNTIME", Value: "nodejs14"},
-			},
-		}
-	case serverlessv1alpha1.Python38:
-		return Config{
-			Runtime:                 serverlessv1alpha1.Python38,
-			DependencyFile:          "requirements.txt",
-			FunctionFile:            "handler.py",
-			DockerfileConfigMapName: "dockerfile-python-38",
-			RuntimeEnvs: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "$(KUBELESS_INSTALL_VOLUME)/lib.python3.8/site-packages:$(KUBELESS_INSTALL_VOLUME)"}, // https://github.com/kubeless/runtimes/blob/master/stable/python/python.jsonnet#L45
-				{Name: "FUNC_RUNTIME", Value: "python38"}},
-		}
-	case serverlessv1alpha1.Python39:
-		return Config{
-			Runtime:                 serverlessv1alpha1.Python39,
-			DependencyFile:          "requirements.txt",
-			FunctionFile:            "handler.py",
-			DockerfileConfigMapName: "dockerfile-python-39",
-			RuntimeEnvs: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "$(KUBELESS_INSTALL_VOLUME)/lib.python3.9/site-packages:$(KUBELESS_INSTALL_VOLUME)"}, // https://github.com/kubeless/runtimes/blob/master/stable/python/python.jsonnet#L45
-				{Name: "FUNC_RUNTIME", Value: "python39"}},
-		}
-	default:
-		return Config{
-			Runtime:                 serverlessv1alpha1.Nodejs12,
-			DependencyFile:          "package.json",
-			FunctionFile:            "handler.js",
-			DockerfileConfigMapName: "dockerfile-nodejs-12",
-			RuntimeEnvs: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
-				{Name: "FUNC_RUNTIME", Value: "nodejs12"},
-			},
-		}
+	if spec, ok := serverlessv1alpha1.BuiltinFunctionRuntimes[r]; ok {
+		return newConfig(r, spec)
 	}
+
+	return newConfig(serverlessv1alpha1.Nodejs12, serverlessv1alpha1.BuiltinFunctionRuntimes[serverlessv1alpha1.Nodejs12])
 }
 
-func GetRuntime(r serverlessv1alpha1.Runtime) Runtime {
-	switch r {
-	case serverlessv1alpha1.Nodejs12, serverlessv1alpha1.Nodejs14:
+func GetRuntime(sanitizer serverlessv1alpha1.DependencySanitizer) Runtime {
+	switch sanitizer {
+	case serverlessv1alpha1.DependencySanitizerNodejs:
 		return nodejs{}
-	case serverlessv1alpha1.Python38, serverlessv1alpha1.Python39:
-		return python{}
 	default:
-		return nodejs{}
+		return none{}
+	}
+}
+
+func newConfig(r serverlessv1alpha1.Runtime, spec serverlessv1alpha1.FunctionRuntimeSpec) Config {
+	return Config{
+		Runtime:                   r,
+		DependencyFile:            spec.DependencyFile,
+		FunctionFile:              spec.FunctionFile,
+		DockerfileConfigMapName:   spec.DockerfileConfigMapName,
+		RuntimeEnvs:               spec.Env,
+		PackageRegistryConfigFile: spec.PackageRegistryConfigFile,
 	}
 }
@@ -1,12 +1,15 @@
 package runtime_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/kyma-project/kyma/components/function-controller/internal/controllers/serverless/runtime"
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetRuntimeConfig(t *testing.T) {
@@ -25,6 +28,7 @@ func TestGetRuntimeConfig(t *testing.T) {
 				DockerfileConfigMapName: "dockerfile-nodejs-12",
 				RuntimeEnvs: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
 					{Name: "FUNC_RUNTIME", Value: "nodejs12"}},
+				PackageRegistryConfigFile: ".npmrc",
 			},
 		},
 		"python38": {
@@ -37,6 +41,7 @@ func TestGetRuntimeConfig(t *testing.T) {
 				DockerfileConfigMapName: "dockerfile-python-38",
 				RuntimeEnvs: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "$(KUBELESS_INSTALL_VOLUME)/lib.python3.8/site-packages:$(KUBELESS_INSTALL_VOLUME)"},
 					{Name: "FUNC_RUNTIME", Value: "python38"}},
+				PackageRegistryConfigFile: "pip.conf",
 			},
 		},
 		"python39": {
@@ -49,6 +54,7 @@ func TestGetRuntimeConfig(t *testing.T) {
 				DockerfileConfigMapName: "dockerfile-python-39",
 				RuntimeEnvs: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "$(KUBELESS_INSTALL_VOLUME)/lib.python3.9/site-packages:$(KUBELESS_INSTALL_VOLUME)"},
 					{Name: "FUNC_RUNTIME", Value: "python39"}},
+				PackageRegistryConfigFile: "pip.conf",
 			},
 		},
 		"nodej14": {
@@ -61,6 +67,7 @@ func TestGetRuntimeConfig(t *testing.T) {
 				DockerfileConfigMapName: "dockerfile-nodejs-14",
 				RuntimeEnvs: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
 					{Name: "FUNC_RUNTIME", Value: "nodejs14"}},
+				PackageRegistryConfigFile: ".npmrc",
 			},
 		},
 		"default": {
@@ -73,6 +80,7 @@ func TestGetRuntimeConfig(t *testing.T) {
 				DockerfileConfigMapName: "dockerfile-nodejs-14",
 				RuntimeEnvs: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
 					{Name: "FUNC_RUNTIME", Value: "nodejs14"}},
+				PackageRegistryConfigFile: ".npmrc",
 			},
 		}} {
 		t.Run(testName, func(t *testing.T) {
@@ -87,3 +95,112 @@ func TestGetRuntimeConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestResolve(t *testing.T) {
+	goRuntime := &serverlessv1alpha1.FunctionRuntime{
+		ObjectMeta: metav1.ObjectMeta{Name: "go116"},
+		Spec: serverlessv1alpha1.FunctionRuntimeSpec{
+			DependencyFile:          "go.mod",
+			FunctionFile:            "handler.go",
+			DockerfileConfigMapName: "dockerfile-go-116",
+			Env:                     []corev1.EnvVar{{Name: "FUNC_RUNTIME", Value: "go116"}},
+			DependencySanitizer:     serverlessv1alpha1.DependencySanitizerNone,
+		},
+	}
+	customNodejs := &serverlessv1alpha1.FunctionRuntime{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodejs14"},
+		Spec: serverlessv1alpha1.FunctionRuntimeSpec{
+			DependencyFile:          "package.json",
+			FunctionFile:            "handler.js",
+			DockerfileConfigMapName: "dockerfile-nodejs-14-corporate-ca",
+			DependencySanitizer:     serverlessv1alpha1.DependencySanitizerNodejs,
+		},
+	}
+	getter := func(_ context.Context, name string) (*serverlessv1alpha1.FunctionRuntime, error) {
+		switch name {
+		case goRuntime.Name:
+			return goRuntime, nil
+		case customNodejs.Name:
+			return customNodejs, nil
+		case "broken":
+			return nil, errors.New("connection refused")
+		}
+		return nil, nil
+	}
+
+	for testName, testData := range map[string]struct {
+		getter          serverlessv1alpha1.FunctionRuntimeGetter
+		runtime         serverlessv1alpha1.Runtime
+		want            runtime.Config
+		wantSanitizedTo string
+		wantErr         bool
+	}{
+		"custom runtime": {
+			getter:  getter,
+			runtime: "go116",
+			want: runtime.Config{
+				Runtime:                 "go116",
+				DependencyFile:          "go.mod",
+				FunctionFile:            "handler.go",
+				DockerfileConfigMapName: "dockerfile-go-116",
+				RuntimeEnvs:             []corev1.EnvVar{{Name: "FUNC_RUNTIME", Value: "go116"}},
+			},
+			wantSanitizedTo: "",
+		},
+		"FunctionRuntime overrides built-in runtime": {
+			getter:  getter,
+			runtime: serverlessv1alpha1.Nodejs14,
+			want: runtime.Config{
+				Runtime:                 serverlessv1alpha1.Nodejs14,
+				DependencyFile:          "package.json",
+				FunctionFile:            "handler.js",
+				DockerfileConfigMapName: "dockerfile-nodejs-14-corporate-ca",
+			},
+			wantSanitizedTo: "{}",
+		},
+		"built-in runtime": {
+			getter:          getter,
+			runtime:         serverlessv1alpha1.Python39,
+			want:            runtime.GetRuntimeConfig(serverlessv1alpha1.Python39),
+			wantSanitizedTo: "",
+		},
+		"built-in runtime without getter": {
+			runtime:         serverlessv1alpha1.Nodejs12,
+			want:            runtime.GetRuntimeConfig(serverlessv1alpha1.Nodejs12),
+			wantSanitizedTo: "{}",
+		},
+		"legacy runtime falls back to nodejs12": {
+			getter:          getter,
+			runtime:         "nodejs10",
+			want:            runtime.GetRuntimeConfig(serverlessv1alpha1.Nodejs12),
+			wantSanitizedTo: "{}",
+		},
+		"unknown runtime": {
+			getter:  getter,
+			runtime: "java11",
+			wantErr: true,
+		},
+		"getter error": {
+			getter:  getter,
+			runtime: "broken",
+			wantErr: true,
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			// given
+			g := gomega.NewWithT(t)
+
+			// when
+			config, rtm, err := runtime.Resolve(context.TODO(), testData.getter, testData.runtime)
+
+			// then
+			if testData.wantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(config).To(gomega.BeEquivalentTo(testData.want))
+			g.Expect(rtm.SanitizeDependencies("")).To(gomega.Equal(testData.wantSanitizedTo))
+		})
+	}
+}
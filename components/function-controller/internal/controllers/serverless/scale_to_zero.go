@@ -0,0 +1,65 @@
+package serverless
+
+import (
+	"time"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func isScaleToZeroEnabled(instance *serverlessv1alpha1.Function) bool {
+	return instance.Spec.MinReplicas != nil && *instance.Spec.MinReplicas == 0
+}
+
+// lastRequestTime returns the time of the latest request reported by the activator,
+// a function that has not received any request yet is idle since its creation
+func lastRequestTime(instance *serverlessv1alpha1.Function) time.Time {
+	last := instance.GetCreationTimestamp().Time
+
+	value, ok := instance.GetAnnotations()[serverlessv1alpha1.FunctionLastRequestTimeAnnotation]
+	if !ok {
+		return last
+	}
+	reported, err := time.Parse(time.RFC3339, value)
+	if err != nil || reported.Before(last) {
+		return last
+	}
+	return reported
+}
+
+// idleTimeLeft returns how long a function with minReplicas 0 can stay idle before it is scaled to zero,
+// the returned value is not positive for functions that should be scaled to zero already
+func idleTimeLeft(instance *serverlessv1alpha1.Function, now time.Time) (time.Duration, bool) {
+	if !isScaleToZeroEnabled(instance) || instance.Spec.IdleTimeout == nil {
+		return 0, false
+	}
+	return lastRequestTime(instance).Add(instance.Spec.IdleTimeout.Duration).Sub(now), true
+}
+
+// isScaledToZero returns true for functions with minReplicas 0 that did not receive any request within their idle timeout
+func isScaledToZero(instance *serverlessv1alpha1.Function, now time.Time) bool {
+	left, ok := idleTimeLeft(instance, now)
+	return ok && left <= 0
+}
+
+// deploymentReplicas returns the replicas of the function Deployment,
+// a function with minReplicas 0 runs one replica until it is scaled to zero
+func deploymentReplicas(instance *serverlessv1alpha1.Function, now time.Time) *int32 {
+	if !isScaleToZeroEnabled(instance) {
+		return instance.Spec.MinReplicas
+	}
+
+	replicas := int32(1)
+	if isScaledToZero(instance, now) {
+		replicas = 0
+	}
+	return &replicas
+}
+
+// readyRequeueDuration returns when a ready function is reconciled again, so that it is scaled to zero once its idle timeout passes
+func (r *FunctionReconciler) readyRequeueDuration(instance *serverlessv1alpha1.Function, now time.Time) time.Duration {
+	left, ok := idleTimeLeft(instance, now)
+	if !ok || left <= 0 || left >= r.config.FunctionReadyRequeueDuration {
+		return r.config.FunctionReadyRequeueDuration
+	}
+	return left
+}
@@ -0,0 +1,148 @@
+package serverless
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func Test_deploymentReplicas(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	zero, two := int32(0), int32(2)
+
+	tests := []struct {
+		name        string
+		minReplicas *int32
+		annotations map[string]string
+		created     time.Time
+		want        int32
+	}{
+		{
+			name:        "returns minReplicas of function without scaling to zero",
+			minReplicas: &two,
+			created:     now.Add(-time.Hour),
+			want:        2,
+		},
+		{
+			name:        "runs one replica of new function",
+			minReplicas: &zero,
+			created:     now.Add(-time.Minute),
+			want:        1,
+		},
+		{
+			name:        "scales function without requests to zero",
+			minReplicas: &zero,
+			created:     now.Add(-time.Hour),
+			want:        0,
+		},
+		{
+			name:        "runs one replica of function with recent request",
+			minReplicas: &zero,
+			created:     now.Add(-time.Hour),
+			annotations: map[string]string{
+				serverlessv1alpha1.FunctionLastRequestTimeAnnotation: now.Add(-5 * time.Minute).Format(time.RFC3339),
+			},
+			want: 1,
+		},
+		{
+			name:        "scales function with old request to zero",
+			minReplicas: &zero,
+			created:     now.Add(-time.Hour),
+			annotations: map[string]string{
+				serverlessv1alpha1.FunctionLastRequestTimeAnnotation: now.Add(-20 * time.Minute).Format(time.RFC3339),
+			},
+			want: 0,
+		},
+		{
+			name:        "ignores invalid request time",
+			minReplicas: &zero,
+			created:     now.Add(-time.Hour),
+			annotations: map[string]string{
+				serverlessv1alpha1.FunctionLastRequestTimeAnnotation: "yesterday",
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.NewTime(tt.created),
+					Annotations:       tt.annotations,
+				},
+				Spec: serverlessv1alpha1.FunctionSpec{
+					MinReplicas: tt.minReplicas,
+					IdleTimeout: &metav1.Duration{Duration: 15 * time.Minute},
+				},
+			}
+
+			got := deploymentReplicas(instance, now)
+			g.Expect(got).NotTo(gomega.BeNil())
+			g.Expect(*got).To(gomega.Equal(tt.want))
+		})
+	}
+}
+
+func TestFunctionReconciler_readyRequeueDuration(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	zero, one := int32(0), int32(1)
+
+	tests := []struct {
+		name        string
+		minReplicas *int32
+		lastRequest time.Time
+		want        time.Duration
+	}{
+		{
+			name:        "requeues function without scaling to zero after the default duration",
+			minReplicas: &one,
+			lastRequest: now,
+			want:        5 * time.Minute,
+		},
+		{
+			name:        "requeues function when its idle timeout passes",
+			minReplicas: &zero,
+			lastRequest: now.Add(-13 * time.Minute),
+			want:        2 * time.Minute,
+		},
+		{
+			name:        "requeues function with long idle time left after the default duration",
+			minReplicas: &zero,
+			lastRequest: now.Add(-time.Minute),
+			want:        5 * time.Minute,
+		},
+		{
+			name:        "requeues function scaled to zero after the default duration",
+			minReplicas: &zero,
+			lastRequest: now.Add(-time.Hour),
+			want:        5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			instance := &serverlessv1alpha1.Function{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+					Annotations: map[string]string{
+						serverlessv1alpha1.FunctionLastRequestTimeAnnotation: tt.lastRequest.Format(time.RFC3339),
+					},
+				},
+				Spec: serverlessv1alpha1.FunctionSpec{
+					MinReplicas: tt.minReplicas,
+					IdleTimeout: &metav1.Duration{Duration: 15 * time.Minute},
+				},
+			}
+			r := &FunctionReconciler{config: FunctionConfig{FunctionReadyRequeueDuration: 5 * time.Minute}}
+
+			g.Expect(r.readyRequeueDuration(instance, now)).To(gomega.Equal(tt.want))
+		})
+	}
+}
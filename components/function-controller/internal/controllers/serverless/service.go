@@ -12,14 +12,14 @@ import (
 	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
 )
 
-func (r *FunctionReconciler) isOnServiceChange(instance *serverlessv1alpha1.Function, services []corev1.Service) bool {
-	newSvc := r.buildService(instance)
+func (r *FunctionReconciler) isOnServiceChange(instance *serverlessv1alpha1.Function, services []corev1.Service, activatorRoute *corev1.Service) bool {
+	newSvc := r.buildService(instance, activatorRoute)
 	return !(len(services) == 1 &&
 		r.equalServices(services[0], newSvc))
 }
 
-func (r *FunctionReconciler) onServiceChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, services []corev1.Service) (ctrl.Result, error) {
-	newSvc := r.buildService(instance)
+func (r *FunctionReconciler) onServiceChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, services []corev1.Service, activatorRoute *corev1.Service) (ctrl.Result, error) {
+	newSvc := r.buildService(instance, activatorRoute)
 
 	switch {
 	case len(services) == 0:
@@ -34,8 +34,46 @@ func (r *FunctionReconciler) onServiceChange(ctx context.Context, log logr.Logge
 	}
 }
 
+func (r *FunctionReconciler) isOnPrivateServiceChange(instance *serverlessv1alpha1.Function, privateServices []corev1.Service) bool {
+	if !isScaleToZeroEnabled(instance) {
+		return len(privateServices) != 0
+	}
+
+	newSvc := r.buildPrivateService(instance)
+	return !(len(privateServices) == 1 &&
+		r.equalServices(privateServices[0], newSvc))
+}
+
+func (r *FunctionReconciler) onPrivateServiceChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, privateServices []corev1.Service) (ctrl.Result, error) {
+	newSvc := r.buildPrivateService(instance)
+
+	switch {
+	case !isScaleToZeroEnabled(instance) || len(privateServices) > 1:
+		return r.deletePrivateServices(ctx, log, privateServices)
+	case len(privateServices) == 0:
+		return r.createService(ctx, log, instance, newSvc)
+	default:
+		return r.updateService(ctx, log, instance, privateServices[0], newSvc)
+	}
+}
+
+// splitServices separates the Service of a function from the private Service the activator forwards requests to
+func (r *FunctionReconciler) splitServices(services []corev1.Service) ([]corev1.Service, []corev1.Service) {
+	var functionServices, privateServices []corev1.Service
+	for _, service := range services {
+		if service.GetLabels()[serverlessv1alpha1.FunctionResourceLabel] == serverlessv1alpha1.FunctionResourceLabelPrivateServiceValue {
+			privateServices = append(privateServices, service)
+			continue
+		}
+		functionServices = append(functionServices, service)
+	}
+	return functionServices, privateServices
+}
+
 func (r *FunctionReconciler) equalServices(existing corev1.Service, expected corev1.Service) bool {
 	return r.mapsEqual(existing.Spec.Selector, expected.Spec.Selector) &&
+		serviceType(existing) == serviceType(expected) &&
+		existing.Spec.ExternalName == expected.Spec.ExternalName &&
 		r.mapsEqual(existing.Labels, expected.Labels) &&
 		len(existing.Spec.Ports) == len(expected.Spec.Ports) &&
 		len(expected.Spec.Ports) > 0 &&
@@ -67,6 +105,11 @@ func (r *FunctionReconciler) updateService(ctx context.Context, log logr.Logger,
 	svc.Spec.Ports = newService.Spec.Ports
 	svc.Spec.Selector = newService.Spec.Selector
 	svc.Spec.Type = newService.Spec.Type
+	svc.Spec.ExternalName = newService.Spec.ExternalName
+	if serviceType(oldService) != serviceType(newService) {
+		// the cluster IP is released when a Service becomes an ExternalName Service and allocated again when it stops being one
+		svc.Spec.ClusterIP = ""
+	}
 
 	svc.ObjectMeta.Labels = newService.GetLabels()
 
@@ -86,6 +129,21 @@ func (r *FunctionReconciler) updateService(ctx context.Context, log logr.Logger,
 	})
 }
 
+func (r *FunctionReconciler) deletePrivateServices(ctx context.Context, log logr.Logger, privateServices []corev1.Service) (ctrl.Result, error) {
+	log.Info("Deleting private Services")
+
+	for i := range privateServices {
+		log.Info(fmt.Sprintf("Deleting Service %s", privateServices[i].GetName()))
+		if err := r.client.Delete(ctx, &privateServices[i]); err != nil {
+			log.Error(err, fmt.Sprintf("Cannot delete private Service %s", privateServices[i].GetName()))
+			return ctrl.Result{}, err
+		}
+	}
+
+	log.Info("Private Services deleted")
+	return ctrl.Result{}, nil
+}
+
 func (r *FunctionReconciler) deleteExcessServices(ctx context.Context, instance *serverlessv1alpha1.Function, log logr.Logger, services []corev1.Service) (ctrl.Result, error) {
 	// services do not support deletecollection
 	// you can check this by `kubectl api-resources -o wide | grep services`
@@ -111,3 +169,10 @@ func (r *FunctionReconciler) deleteExcessServices(ctx context.Context, instance
 	log.Info("Excess Services deleted")
 	return ctrl.Result{}, nil
 }
+
+func serviceType(service corev1.Service) corev1.ServiceType {
+	if service.Spec.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return service.Spec.Type
+}
@@ -400,6 +400,77 @@ func TestFunctionReconciler_equalServices(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "fails if the function is switched to the activator",
+			args: args{
+				existing: corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc-name",
+						Namespace: "svc-ns",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{
+							Name:       "http",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080)},
+						},
+						Type:     corev1.ServiceTypeClusterIP,
+						Selector: map[string]string{},
+					},
+				},
+				expected: corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc-name",
+						Namespace: "svc-ns",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{
+							Name:       "http",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080)},
+						},
+						Type:         corev1.ServiceTypeExternalName,
+						ExternalName: "serverless-activator-fn-uid.kyma-system.svc.cluster.local",
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "treats empty service type as ClusterIP",
+			args: args{
+				existing: corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc-name",
+						Namespace: "svc-ns",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{
+							Name:       "http",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080)},
+						},
+						Type:     corev1.ServiceTypeClusterIP,
+						Selector: map[string]string{},
+					},
+				},
+				expected: corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc-name",
+						Namespace: "svc-ns",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{
+							Name:       "http",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080)},
+						},
+						Selector: map[string]string{},
+					},
+				},
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,292 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+// Subscriptions are handled as unstructured objects, the eventing API depends on other versions of the Kubernetes libraries
+var subscriptionGVK = schema.GroupVersionKind{
+	Group:   "eventing.kyma-project.io",
+	Version: "v1alpha1",
+	Kind:    "Subscription",
+}
+
+// subscriptionSpec holds the fields of the Subscription spec set by the function controller
+type subscriptionSpec struct {
+	Protocol         string              `json:"protocol"`
+	ProtocolSettings struct{}            `json:"protocolsettings"`
+	Sink             string              `json:"sink"`
+	Filter           subscriptionFilters `json:"filter"`
+}
+
+type subscriptionFilters struct {
+	Filters    []subscriptionFilter `json:"filters"`
+	Expression string               `json:"expression,omitempty"`
+}
+
+type subscriptionFilter struct {
+	EventSource eventFilter   `json:"eventSource"`
+	EventType   eventFilter   `json:"eventType"`
+	Extensions  []eventFilter `json:"extensions,omitempty"`
+}
+
+type eventFilter struct {
+	Type     string `json:"type,omitempty"`
+	Property string `json:"property"`
+	Value    string `json:"value"`
+}
+
+func newSubscription() *unstructured.Unstructured {
+	subscription := &unstructured.Unstructured{}
+	subscription.SetGroupVersionKind(subscriptionGVK)
+	return subscription
+}
+
+// isSubscriptionInstalled returns true if the Subscription CRD is installed, which is checked once at the setup of
+// the controller, so the controller has to be restarted to watch Subscriptions once eventing is installed
+func isSubscriptionInstalled(mapper meta.RESTMapper) bool {
+	_, err := mapper.RESTMapping(subscriptionGVK.GroupKind(), subscriptionGVK.Version)
+	return err == nil
+}
+
+func newSubscriptionList() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(subscriptionGVK.GroupVersion().WithKind(subscriptionGVK.Kind + "List"))
+	return list
+}
+
+func (r *FunctionReconciler) listSubscriptions(ctx context.Context, instance *serverlessv1alpha1.Function) ([]unstructured.Unstructured, error) {
+	subscriptions := newSubscriptionList()
+	if err := r.client.ListByLabel(ctx, instance.GetNamespace(), r.internalFunctionLabels(instance), subscriptions); err != nil {
+		// there is nothing to clean up for a function without triggers if eventing is not installed
+		if meta.IsNoMatchError(err) && len(instance.Spec.Triggers) == 0 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return subscriptions.Items, nil
+}
+
+func (r *FunctionReconciler) buildSubscriptions(instance *serverlessv1alpha1.Function) ([]unstructured.Unstructured, error) {
+	subscriptions := make([]unstructured.Unstructured, 0, len(instance.Spec.Triggers))
+	for i, trigger := range instance.Spec.Triggers {
+		subscription, err := r.buildSubscription(instance, i, trigger)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (r *FunctionReconciler) isOnSubscriptionChange(existing []unstructured.Unstructured, expected []unstructured.Unstructured) bool {
+	if len(existing) != len(expected) {
+		return true
+	}
+	byTrigger := subscriptionsByTrigger(existing)
+	for i := range expected {
+		subscription, ok := byTrigger[triggerIndex(expected[i])]
+		if !ok || !r.equalSubscriptions(subscription, expected[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FunctionReconciler) onSubscriptionChange(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, existing []unstructured.Unstructured, expected []unstructured.Unstructured) (ctrl.Result, error) {
+	if excess := excessSubscriptions(existing, len(expected)); len(excess) > 0 {
+		return r.deleteSubscriptions(ctx, log, instance, excess)
+	}
+
+	byTrigger := subscriptionsByTrigger(existing)
+	for i := range expected {
+		subscription, ok := byTrigger[triggerIndex(expected[i])]
+		switch {
+		case !ok:
+			return r.createSubscription(ctx, log, instance, expected[i])
+		case !r.equalSubscriptions(subscription, expected[i]):
+			return r.updateSubscription(ctx, log, instance, subscription, expected[i])
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *FunctionReconciler) createSubscription(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, subscription unstructured.Unstructured) (ctrl.Result, error) {
+	log.Info("Creating Subscription")
+	if err := r.client.CreateWithReference(ctx, instance, &subscription); err != nil {
+		log.Error(err, "Cannot create Subscription")
+		return ctrl.Result{}, err
+	}
+	log.Info(fmt.Sprintf("Subscription %s created", subscription.GetName()))
+
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonSubscriptionCreated,
+		Message:            fmt.Sprintf("Subscription %s created", subscription.GetName()),
+	})
+}
+
+func (r *FunctionReconciler) updateSubscription(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, existing unstructured.Unstructured, expected unstructured.Unstructured) (ctrl.Result, error) {
+	subscription := existing.DeepCopy()
+	subscription.SetLabels(expected.GetLabels())
+	subscription.Object["spec"] = expected.Object["spec"]
+
+	log.Info(fmt.Sprintf("Updating Subscription %s", subscription.GetName()))
+	if err := r.client.Update(ctx, subscription); err != nil {
+		log.Error(err, fmt.Sprintf("Cannot update Subscription with name %s", subscription.GetName()))
+		return ctrl.Result{}, err
+	}
+	log.Info(fmt.Sprintf("Subscription %s updated", subscription.GetName()))
+
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonSubscriptionUpdated,
+		Message:            fmt.Sprintf("Subscription %s updated", subscription.GetName()),
+	})
+}
+
+func (r *FunctionReconciler) deleteSubscriptions(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, subscriptions []unstructured.Unstructured) (ctrl.Result, error) {
+	names := make([]string, 0, len(subscriptions))
+	for i := range subscriptions {
+		log.Info(fmt.Sprintf("Deleting Subscription %s", subscriptions[i].GetName()))
+		if err := r.client.Delete(ctx, &subscriptions[i]); err != nil {
+			log.Error(err, fmt.Sprintf("Cannot delete Subscription %s", subscriptions[i].GetName()))
+			return ctrl.Result{}, err
+		}
+		names = append(names, subscriptions[i].GetName())
+	}
+	log.Info("Excess Subscriptions deleted")
+
+	// the condition of a function whose triggers were all removed stays true, there is nothing left to wait for
+	status := corev1.ConditionUnknown
+	if len(instance.Spec.Triggers) == 0 {
+		status = corev1.ConditionTrue
+	}
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonSubscriptionDeleted,
+		Message:            fmt.Sprintf("Subscriptions %s deleted", strings.Join(names, ", ")),
+	})
+}
+
+// isOnSubscriptionStatusChange checks if the SubscriptionsReady condition of a function with triggers reflects the
+// readiness of its Subscriptions
+func (r *FunctionReconciler) isOnSubscriptionStatusChange(instance *serverlessv1alpha1.Function, subscriptions []unstructured.Unstructured) bool {
+	if len(instance.Spec.Triggers) == 0 {
+		return false
+	}
+	expected := r.subscriptionsCondition(subscriptions)
+	for _, condition := range instance.Status.Conditions {
+		if condition.Type == expected.Type {
+			return condition.Status != expected.Status || condition.Reason != expected.Reason || condition.Message != expected.Message
+		}
+	}
+	return true
+}
+
+func (r *FunctionReconciler) onSubscriptionStatusChange(ctx context.Context, instance *serverlessv1alpha1.Function, subscriptions []unstructured.Unstructured) (ctrl.Result, error) {
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{}, instance, r.subscriptionsCondition(subscriptions))
+}
+
+// onSubscriptionError reports Subscriptions of triggers which can't be listed, e.g. as eventing is not installed
+func (r *FunctionReconciler) onSubscriptionError(ctx context.Context, log logr.Logger, instance *serverlessv1alpha1.Function, err error) (ctrl.Result, error) {
+	log.Error(err, "Cannot list Subscriptions")
+	return r.updateStatusWithoutRepository(ctx, ctrl.Result{RequeueAfter: r.config.RequeueDuration}, instance, serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonSubscriptionNotReady,
+		Message:            fmt.Sprintf("Listing Subscriptions failed: %v", err),
+	})
+}
+
+func (r *FunctionReconciler) subscriptionsCondition(subscriptions []unstructured.Unstructured) serverlessv1alpha1.Condition {
+	var notReady []string
+	for _, subscription := range subscriptions {
+		if ready, _, _ := unstructured.NestedBool(subscription.Object, "status", "ready"); !ready {
+			notReady = append(notReady, subscription.GetName())
+		}
+	}
+	sort.Strings(notReady)
+
+	if len(notReady) > 0 {
+		return serverlessv1alpha1.Condition{
+			Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+			Status:             corev1.ConditionUnknown,
+			LastTransitionTime: metav1.Now(),
+			Reason:             serverlessv1alpha1.ConditionReasonSubscriptionNotReady,
+			Message:            fmt.Sprintf("Subscriptions %s are not ready yet", strings.Join(notReady, ", ")),
+		}
+	}
+	return serverlessv1alpha1.Condition{
+		Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             serverlessv1alpha1.ConditionReasonSubscriptionsReady,
+		Message:            "Subscriptions are ready",
+	}
+}
+
+func (r *FunctionReconciler) equalSubscriptions(existing unstructured.Unstructured, expected unstructured.Unstructured) bool {
+	existingSink, _, _ := unstructured.NestedFieldNoCopy(existing.Object, "spec", "sink")
+	expectedSink, _, _ := unstructured.NestedFieldNoCopy(expected.Object, "spec", "sink")
+	existingFilter, _, _ := unstructured.NestedFieldNoCopy(existing.Object, "spec", "filter")
+	expectedFilter, _, _ := unstructured.NestedFieldNoCopy(expected.Object, "spec", "filter")
+
+	return r.mapsEqual(existing.GetLabels(), expected.GetLabels()) &&
+		reflect.DeepEqual(existingSink, expectedSink) &&
+		reflect.DeepEqual(existingFilter, expectedFilter)
+}
+
+// excessSubscriptions returns the Subscriptions of removed triggers and the duplicates of the Subscriptions of a trigger
+func excessSubscriptions(subscriptions []unstructured.Unstructured, triggers int) []unstructured.Unstructured {
+	var excess []unstructured.Unstructured
+	seen := map[string]bool{}
+	for _, subscription := range subscriptions {
+		index := triggerIndex(subscription)
+		if i, err := strconv.Atoi(index); err != nil || i < 0 || i >= triggers || seen[index] {
+			excess = append(excess, subscription)
+			continue
+		}
+		seen[index] = true
+	}
+	return excess
+}
+
+func subscriptionsByTrigger(subscriptions []unstructured.Unstructured) map[string]unstructured.Unstructured {
+	byTrigger := make(map[string]unstructured.Unstructured, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byTrigger[triggerIndex(subscription)] = subscription
+	}
+	return byTrigger
+}
+
+func triggerIndex(subscription unstructured.Unstructured) string {
+	return subscription.GetLabels()[serverlessv1alpha1.FunctionTriggerLabel]
+}
+
+func toUnstructuredSpec(spec subscriptionSpec) (map[string]interface{}, error) {
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+}
@@ -0,0 +1,198 @@
+package serverless
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+func TestFunctionReconciler_buildSubscription(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	instance := newFixFunction("fn-ns", "fn-name", 1, 1)
+	instance.Name = "fn-name"
+	instance.UID = "fn-uuid"
+	r := &FunctionReconciler{config: FunctionConfig{Eventing: EventingConfig{EventTypePrefix: "sap.kyma.custom"}}}
+
+	subscription, err := r.buildSubscription(instance, 1, serverlessv1alpha1.Trigger{
+		Source:  "commercemock",
+		Type:    "order.created",
+		Version: "v1",
+		Filter: &serverlessv1alpha1.TriggerFilter{
+			Extensions: []serverlessv1alpha1.ExtensionFilter{{Property: "tenant", Value: "acme"}},
+			Expression: "priority > 2",
+		},
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	g.Expect(subscription.GroupVersionKind()).To(gomega.Equal(subscriptionGVK))
+	g.Expect(subscription.GetNamespace()).To(gomega.Equal("fn-ns"))
+	g.Expect(subscription.GetGenerateName()).To(gomega.Equal("fn-name-"))
+	g.Expect(subscription.GetLabels()).To(gomega.HaveKeyWithValue(serverlessv1alpha1.FunctionUUIDLabel, "fn-uuid"))
+	g.Expect(subscription.GetLabels()).To(gomega.HaveKeyWithValue(serverlessv1alpha1.FunctionTriggerLabel, "1"))
+
+	sink, _, _ := unstructured.NestedString(subscription.Object, "spec", "sink")
+	g.Expect(sink).To(gomega.Equal("http://fn-name.fn-ns.svc.cluster.local"))
+	expression, _, _ := unstructured.NestedString(subscription.Object, "spec", "filter", "expression")
+	g.Expect(expression).To(gomega.Equal("priority > 2"))
+	filters, _, _ := unstructured.NestedSlice(subscription.Object, "spec", "filter", "filters")
+	g.Expect(filters).To(gomega.Equal([]interface{}{map[string]interface{}{
+		"eventSource": map[string]interface{}{"type": "exact", "property": "source", "value": ""},
+		"eventType":   map[string]interface{}{"type": "exact", "property": "type", "value": "sap.kyma.custom.commercemock.order.created.v1"},
+		"extensions": []interface{}{
+			map[string]interface{}{"type": "exact", "property": "tenant", "value": "acme"},
+		},
+	}}))
+}
+
+func TestFunctionReconciler_isOnSubscriptionChange(t *testing.T) {
+	instance := newFixFunction("fn-ns", "fn-name", 1, 1)
+	instance.Spec.Triggers = []serverlessv1alpha1.Trigger{
+		{Source: "commercemock", Type: "order.created", Version: "v1"},
+		{Source: "commercemock", Type: "order.deleted", Version: "v1"},
+	}
+	r := &FunctionReconciler{}
+	expected, err := r.buildSubscriptions(instance)
+	gomega.NewWithT(t).Expect(err).NotTo(gomega.HaveOccurred())
+
+	// subscriptions read from the API server are decoded from JSON
+	fromAPIServer := func(subscription unstructured.Unstructured, name string) unstructured.Unstructured {
+		data, err := json.Marshal(subscription.Object)
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.HaveOccurred())
+		decoded := unstructured.Unstructured{}
+		gomega.NewWithT(t).Expect(decoded.UnmarshalJSON(data)).To(gomega.Succeed())
+		decoded.SetName(name)
+		return decoded
+	}
+	withTrigger := func(subscription unstructured.Unstructured, index string) unstructured.Unstructured {
+		labels := subscription.GetLabels()
+		labels[serverlessv1alpha1.FunctionTriggerLabel] = index
+		subscription.SetLabels(labels)
+		return subscription
+	}
+
+	testCases := []struct {
+		name       string
+		existing   []unstructured.Unstructured
+		wantChange bool
+		wantExcess []string
+	}{
+		{
+			name:     "subscriptions match the triggers",
+			existing: []unstructured.Unstructured{fromAPIServer(expected[0], "first"), fromAPIServer(expected[1], "second")},
+		},
+		{
+			name:       "subscription is missing",
+			existing:   []unstructured.Unstructured{fromAPIServer(expected[0], "first")},
+			wantChange: true,
+		},
+		{
+			name:       "subscription has a different filter",
+			existing:   []unstructured.Unstructured{fromAPIServer(expected[0], "first"), withTrigger(fromAPIServer(expected[0], "second"), "1")},
+			wantChange: true,
+		},
+		{
+			name: "subscriptions of removed triggers and duplicates",
+			existing: []unstructured.Unstructured{
+				fromAPIServer(expected[0], "first"),
+				fromAPIServer(expected[0], "duplicate"),
+				fromAPIServer(expected[1], "second"),
+				withTrigger(fromAPIServer(expected[1], "removed"), "2"),
+			},
+			wantChange: true,
+			wantExcess: []string{"duplicate", "removed"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			g.Expect(r.isOnSubscriptionChange(testCase.existing, expected)).To(gomega.Equal(testCase.wantChange))
+
+			var excess []string
+			for _, subscription := range excessSubscriptions(testCase.existing, len(expected)) {
+				excess = append(excess, subscription.GetName())
+			}
+			g.Expect(excess).To(gomega.Equal(testCase.wantExcess))
+		})
+	}
+}
+
+func TestFunctionReconciler_isOnSubscriptionStatusChange(t *testing.T) {
+	subscription := func(name string, ready bool) unstructured.Unstructured {
+		subscription := unstructured.Unstructured{Object: map[string]interface{}{"status": map[string]interface{}{"ready": ready}}}
+		subscription.SetName(name)
+		return subscription
+	}
+	withCondition := func(status corev1.ConditionStatus, reason serverlessv1alpha1.ConditionReason, message string) *serverlessv1alpha1.Function {
+		instance := newFixFunction("fn-ns", "fn-name", 1, 1)
+		instance.Spec.Triggers = []serverlessv1alpha1.Trigger{{Source: "commercemock", Type: "order.created", Version: "v1"}}
+		instance.Status.Conditions = []serverlessv1alpha1.Condition{{
+			Type:               serverlessv1alpha1.ConditionSubscriptionsReady,
+			Status:             status,
+			LastTransitionTime: metav1.Now(),
+			Reason:             reason,
+			Message:            message,
+		}}
+		return instance
+	}
+
+	testCases := []struct {
+		name          string
+		instance      *serverlessv1alpha1.Function
+		subscriptions []unstructured.Unstructured
+		wantChange    bool
+	}{
+		{
+			name:       "function without triggers",
+			instance:   newFixFunction("fn-ns", "fn-name", 1, 1),
+			wantChange: false,
+		},
+		{
+			name:          "subscription became ready",
+			instance:      withCondition(corev1.ConditionUnknown, serverlessv1alpha1.ConditionReasonSubscriptionCreated, "Subscription first created"),
+			subscriptions: []unstructured.Unstructured{subscription("first", true)},
+			wantChange:    true,
+		},
+		{
+			name:          "subscriptions are still ready",
+			instance:      withCondition(corev1.ConditionTrue, serverlessv1alpha1.ConditionReasonSubscriptionsReady, "Subscriptions are ready"),
+			subscriptions: []unstructured.Unstructured{subscription("first", true)},
+			wantChange:    false,
+		},
+		{
+			name:          "subscription is not ready anymore",
+			instance:      withCondition(corev1.ConditionTrue, serverlessv1alpha1.ConditionReasonSubscriptionsReady, "Subscriptions are ready"),
+			subscriptions: []unstructured.Unstructured{subscription("first", false)},
+			wantChange:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			r := &FunctionReconciler{}
+
+			g.Expect(r.isOnSubscriptionStatusChange(testCase.instance, testCase.subscriptions)).To(gomega.Equal(testCase.wantChange))
+		})
+	}
+}
+
+func Test_isSubscriptionInstalled(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{subscriptionGVK.GroupVersion()})
+	g.Expect(isSubscriptionInstalled(mapper)).To(gomega.BeFalse())
+
+	mapper.Add(subscriptionGVK, meta.RESTScopeNamespace)
+	g.Expect(isSubscriptionInstalled(mapper)).To(gomega.BeTrue())
+}
@@ -0,0 +1,414 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestAcceptHeader = "application/vnd.docker.distribution.manifest.v2+json, " +
+		"application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json, " +
+		"application/vnd.oci.image.index.v1+json"
+	wwwAuthenticateHeader = "Www-Authenticate"
+	contentDigestHeader   = "Docker-Content-Digest"
+	linkHeader            = "Link"
+)
+
+// ErrDeleteDisabled is returned if the registry does not allow deleting manifests
+var ErrDeleteDisabled = errors.New("deleting images is disabled in the registry")
+
+// Credentials are used to authenticate to a Docker registry, empty credentials mean anonymous access
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Access configures how a Docker registry is reached
+type Access struct {
+	Credentials
+	// CA is the PEM encoded certificate authority of the registry, which is trusted in addition to the system ones
+	CA []byte
+	// Insecure registries are reached with HTTP if HTTPS is not available
+	Insecure bool
+}
+
+// RegistryClient checks, lists and deletes images with the Docker Registry HTTP API V2
+type RegistryClient struct {
+	timeout time.Duration
+	lock    sync.Mutex
+	// httpClients are the clients verifying the certificates of registries, by the CA of the registry
+	httpClients map[string]*http.Client
+}
+
+// NewRegistryClient creates a registry client
+func NewRegistryClient(timeout time.Duration) *RegistryClient {
+	return &RegistryClient{
+		timeout:     timeout,
+		httpClients: map[string]*http.Client{},
+	}
+}
+
+// ImageExists returns true if the manifest of the given image, in the form of registry/repository:tag, exists in the registry
+func (c *RegistryClient) ImageExists(ctx context.Context, image string, access Access) (bool, error) {
+	res, err := c.headManifest(ctx, image, access)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// ImageDigest returns the digest of the manifest of the given image, in the form of registry/repository:tag,
+// or an empty digest if the image does not exist in the registry
+func (c *RegistryClient) ImageDigest(ctx context.Context, image string, access Access) (string, error) {
+	res, err := c.headManifest(ctx, image, access)
+	if err != nil || res == nil {
+		return "", err
+	}
+
+	digest := res.Header.Get(contentDigestHeader)
+	if digest == "" {
+		return "", errors.Errorf("digest of image %s missing in the manifest response", image)
+	}
+	return digest, nil
+}
+
+// headManifest returns the response to the HEAD request of the manifest of the image, or nil if the image does not exist
+func (c *RegistryClient) headManifest(ctx context.Context, image string, access Access) (*http.Response, error) {
+	registry, repository, tag, err := ParseImage(image)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(ctx, http.MethodHead, registry, fmt.Sprintf("/v2/%s/manifests/%s", repository, tag), access)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return res, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("unexpected status %d of manifest of image %s", res.StatusCode, image)
+	}
+}
+
+// ListRepositories returns all repositories of the registry, following the pagination of the registry
+func (c *RegistryClient) ListRepositories(ctx context.Context, registry string, access Access) ([]string, error) {
+	var repositories []string
+	path := "/v2/_catalog"
+	for path != "" {
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		next, err := c.getPage(ctx, registry, path, access, &page)
+		if err != nil {
+			return nil, errors.Wrap(err, "while listing repositories")
+		}
+		repositories = append(repositories, page.Repositories...)
+		path = next
+	}
+	return repositories, nil
+}
+
+// ListTags returns all tags of the repository, following the pagination of the registry
+func (c *RegistryClient) ListTags(ctx context.Context, registry, repository string, access Access) ([]string, error) {
+	var tags []string
+	path := fmt.Sprintf("/v2/%s/tags/list", repository)
+	for path != "" {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		next, err := c.getPage(ctx, registry, path, access, &page)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while listing tags of repository %s", repository)
+		}
+		tags = append(tags, page.Tags...)
+		path = next
+	}
+	return tags, nil
+}
+
+// getPage decodes a page of a list into the body and returns the path of the next page. A missing list is empty.
+func (c *RegistryClient) getPage(ctx context.Context, registry, path string, access Access, body interface{}) (string, error) {
+	res, err := c.do(ctx, http.MethodGet, registry, path, access)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// the repository is created with the first pushed image
+		return "", nil
+	default:
+		return "", errors.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(body); err != nil {
+		return "", errors.Wrap(err, "while decoding list")
+	}
+	return nextPagePath(res.Header.Get(linkHeader)), nil
+}
+
+// DeleteManifest deletes the manifest with the given digest and so all tags of the repository pointing to it.
+// The registry has to be started with deletion enabled, e.g. with REGISTRY_STORAGE_DELETE_ENABLED=true.
+func (c *RegistryClient) DeleteManifest(ctx context.Context, registry, repository, digest string, access Access) error {
+	res, err := c.do(ctx, http.MethodDelete, registry, fmt.Sprintf("/v2/%s/manifests/%s", repository, digest), access)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusAccepted, http.StatusOK, http.StatusNotFound:
+		return nil
+	case http.StatusMethodNotAllowed:
+		return ErrDeleteDisabled
+	default:
+		return errors.Errorf("unexpected status %d while deleting manifest %s of repository %s", res.StatusCode, digest, repository)
+	}
+}
+
+// do sends the request to the registry with HTTPS, verifying its certificate. Only insecure registries are reached
+// with HTTP if HTTPS is not available. The caller has to close the body of the returned response.
+func (c *RegistryClient) do(ctx context.Context, method, registry, path string, access Access) (*http.Response, error) {
+	httpClient, err := c.httpClient(access.CA)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doAuthorized(ctx, httpClient, method, registry, fmt.Sprintf("https://%s%s", registry, path), access)
+	if err == nil || !access.Insecure {
+		return res, err
+	}
+	if _, ok := errors.Cause(err).(*url.Error); !ok {
+		return nil, err
+	}
+
+	return c.doAuthorized(ctx, httpClient, method, registry, fmt.Sprintf("http://%s%s", registry, path), access)
+}
+
+// httpClient returns the client trusting the given CA in addition to the system certificate authorities
+func (c *RegistryClient) httpClient(ca []byte) (*http.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if httpClient, ok := c.httpClients[string(ca)]; ok {
+		return httpClient, nil
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if len(ca) > 0 && !rootCAs.AppendCertsFromPEM(ca) {
+		return nil, errors.New("CA of the registry does not contain a PEM encoded certificate")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+	httpClient := &http.Client{
+		Timeout:   c.timeout,
+		Transport: transport,
+	}
+	c.httpClients[string(ca)] = httpClient
+	return httpClient, nil
+}
+
+// doAuthorized sends the request anonymously first and repeats it answering the authentication challenge of the registry
+func (c *RegistryClient) doAuthorized(ctx context.Context, httpClient *http.Client, method, registry, requestURL string, access Access) (*http.Response, error) {
+	res, err := send(ctx, httpClient, method, requestURL, "")
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	res.Body.Close()
+
+	authorization, err := authorize(ctx, httpClient, registry, res.Header.Get(wwwAuthenticateHeader), access)
+	if err != nil {
+		return nil, err
+	}
+	return send(ctx, httpClient, method, requestURL, authorization)
+}
+
+func send(ctx context.Context, httpClient *http.Client, method, requestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "while creating registry request")
+	}
+	req.Header.Set("Accept", manifestAcceptHeader)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while requesting %s", requestURL)
+	}
+	return res, nil
+}
+
+// authorize returns the value of the Authorization header answering the given challenge of the registry
+func authorize(ctx context.Context, httpClient *http.Client, registry, challenge string, access Access) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(access.Username+":"+access.Password)), nil
+	case "bearer":
+		token, err := fetchToken(ctx, httpClient, registry, params, access)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", errors.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+// fetchToken requests a token from the realm of the bearer challenge. The credentials are sent only to a realm
+// on the host of the registry, and with HTTP only to an insecure registry, other realms are asked for an anonymous token.
+func fetchToken(ctx context.Context, httpClient *http.Client, registry string, params map[string]string, access Access) (string, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return "", errors.New("realm missing in bearer challenge")
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", errors.Wrap(err, "while parsing token realm")
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "while creating token request")
+	}
+	registryURL := url.URL{Host: registry}
+	trustedRealm := tokenURL.Hostname() == registryURL.Hostname() &&
+		(tokenURL.Scheme == "https" || access.Insecure)
+	if access.Username != "" && trustedRealm {
+		req.SetBasicAuth(access.Username, access.Password)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "while requesting token")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status %d of token request", res.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, "while decoding token")
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", errors.New("token missing in token response")
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g. Bearer realm="https://auth.io/token",service="registry.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+
+	for _, param := range splitParams(parts[1]) {
+		keyValue := strings.SplitN(param, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		params[strings.ToLower(strings.TrimSpace(keyValue[0]))] = strings.Trim(strings.TrimSpace(keyValue[1]), `"`)
+	}
+
+	return parts[0], params
+}
+
+// splitParams splits comma separated parameters ignoring commas in quoted values, e.g. scope="repository:foo:pull,push"
+func splitParams(value string) []string {
+	var params []string
+	var current strings.Builder
+	quoted := false
+
+	for _, char := range value {
+		switch {
+		case char == '"':
+			quoted = !quoted
+			current.WriteRune(char)
+		case char == ',' && !quoted:
+			params = append(params, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+
+	return append(params, current.String())
+}
+
+// nextPagePath returns the path of the next page from a Link header, e.g. </v2/functions/tags/list?n=100&last=abc>; rel="next"
+func nextPagePath(link string) string {
+	if !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+
+	next, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return next.RequestURI()
+}
+
+// ParseImage splits an image in the form of registry/repository:tag
+func ParseImage(image string) (registry, repository, tag string, err error) {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", "", errors.Errorf("image %s does not contain a registry", image)
+	}
+	registry = parts[0]
+
+	separator := strings.LastIndex(parts[1], ":")
+	if separator < 1 || separator == len(parts[1])-1 {
+		return "", "", "", errors.Errorf("image %s does not contain a tag", image)
+	}
+
+	return registry, parts[1][:separator], parts[1][separator+1:], nil
+}
@@ -0,0 +1,421 @@
+package docker
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+const testManifestPath = "/v2/functions/manifests/abc"
+
+func TestRegistryClient_ImageExists(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tls    bool
+		access Access
+		// trustCA adds the CA of the test server to the access
+		trustCA bool
+		handler func(serverURL string) http.HandlerFunc
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "existing image",
+			tls:     true,
+			trustCA: true,
+			handler: func(string) http.HandlerFunc {
+				return manifestHandler(func(r *http.Request) bool { return true })
+			},
+			want: true,
+		},
+		{
+			name:    "missing image",
+			tls:     true,
+			trustCA: true,
+			handler: func(string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+			want: false,
+		},
+		{
+			name: "registry with unknown CA",
+			tls:  true,
+			handler: func(string) http.HandlerFunc {
+				return manifestHandler(func(r *http.Request) bool { return true })
+			},
+			wantErr: true,
+		},
+		{
+			name:   "existing image in insecure registry without TLS",
+			tls:    false,
+			access: Access{Insecure: true},
+			handler: func(string) http.HandlerFunc {
+				return manifestHandler(func(r *http.Request) bool { return true })
+			},
+			want: true,
+		},
+		{
+			name: "registry without TLS not configured as insecure",
+			tls:  false,
+			handler: func(string) http.HandlerFunc {
+				return manifestHandler(func(r *http.Request) bool { return true })
+			},
+			wantErr: true,
+		},
+		{
+			name:    "existing image in registry with basic authentication",
+			tls:     true,
+			trustCA: true,
+			access:  Access{Credentials: Credentials{Username: "user", Password: "pass"}},
+			handler: func(string) http.HandlerFunc {
+				return manifestHandler(func(r *http.Request) bool {
+					username, password, ok := r.BasicAuth()
+					return ok && username == "user" && password == "pass"
+				})
+			},
+			want: true,
+		},
+		{
+			name:    "existing image in registry with token authentication",
+			tls:     true,
+			trustCA: true,
+			access:  Access{Credentials: Credentials{Username: "user", Password: "pass"}},
+			handler: func(serverURL string) http.HandlerFunc {
+				return tokenHandler(serverURL, func(r *http.Request) bool {
+					username, password, ok := r.BasicAuth()
+					return ok && username == "user" && password == "pass"
+				})
+			},
+			want: true,
+		},
+		{
+			name:    "invalid credentials",
+			tls:     true,
+			trustCA: true,
+			access:  Access{Credentials: Credentials{Username: "user", Password: "wrong"}},
+			handler: func(string) http.HandlerFunc {
+				return manifestHandler(func(r *http.Request) bool {
+					username, password, ok := r.BasicAuth()
+					return ok && username == "user" && password == "pass"
+				})
+			},
+			wantErr: true,
+		},
+		{
+			name:    "registry error",
+			tls:     true,
+			trustCA: true,
+			handler: func(string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			var server *httptest.Server
+			handler := &delegatingHandler{}
+			if testCase.tls {
+				server = httptest.NewTLSServer(handler)
+			} else {
+				server = httptest.NewServer(handler)
+			}
+			defer server.Close()
+			handler.handler = testCase.handler(server.URL)
+
+			access := testCase.access
+			if testCase.trustCA {
+				access.CA = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			}
+
+			client := NewRegistryClient(5 * time.Second)
+			image := fmt.Sprintf("%s/functions:abc", strings.TrimPrefix(strings.TrimPrefix(server.URL, "https://"), "http://"))
+
+			got, err := client.ImageExists(context.TODO(), image, access)
+			if testCase.wantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(got).To(gomega.Equal(testCase.want))
+		})
+	}
+}
+
+func TestRegistryClient_ImageExistsWithTokenRealmOnAnotherHost(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	// the token realm accepts only anonymous requests
+	realm := httptest.NewServer(tokenHandler("", func(r *http.Request) bool {
+		_, _, ok := r.BasicAuth()
+		return !ok
+	}))
+	defer realm.Close()
+	server := httptest.NewTLSServer(tokenHandler(strings.Replace(realm.URL, "127.0.0.1", "localhost", 1), func(*http.Request) bool { return false }))
+	defer server.Close()
+
+	client := NewRegistryClient(5 * time.Second)
+	got, err := client.ImageExists(context.TODO(), strings.TrimPrefix(server.URL, "https://")+"/functions:abc", Access{
+		Credentials: Credentials{Username: "user", Password: "pass"},
+		CA:          pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(got).To(gomega.BeTrue())
+}
+
+func TestRegistryClient_ImageDigest(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != testManifestPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(contentDigestHeader, "sha256:123")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+	client := NewRegistryClient(5 * time.Second)
+
+	digest, err := client.ImageDigest(context.TODO(), registry+"/functions:abc", Access{Insecure: true})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(digest).To(gomega.Equal("sha256:123"))
+
+	digest, err = client.ImageDigest(context.TODO(), registry+"/functions:missing", Access{Insecure: true})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(digest).To(gomega.BeEmpty())
+}
+
+func TestRegistryClient_ListTags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "tags on multiple pages",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("last") == "" {
+					w.Header().Set(linkHeader, `</v2/functions/tags/list?n=2&last=def>; rel="next"`)
+					fmt.Fprint(w, `{"name": "functions", "tags": ["abc", "def"]}`)
+					return
+				}
+				fmt.Fprint(w, `{"name": "functions", "tags": ["ghi"]}`)
+			},
+			want: []string{"abc", "def", "ghi"},
+		},
+		{
+			name: "missing repository",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			name: "registry error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			server := httptest.NewServer(testCase.handler)
+			defer server.Close()
+
+			client := NewRegistryClient(5 * time.Second)
+			got, err := client.ListTags(context.TODO(), strings.TrimPrefix(server.URL, "http://"), "functions", Access{Insecure: true})
+			if testCase.wantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(got).To(gomega.Equal(testCase.want))
+		})
+	}
+}
+
+func TestRegistryClient_ListRepositories(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/_catalog" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set(linkHeader, `</v2/_catalog?n=1&last=default-functions>; rel="next"`)
+			fmt.Fprint(w, `{"repositories": ["default-functions"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"repositories": ["stage-functions"]}`)
+	}))
+	defer server.Close()
+
+	client := NewRegistryClient(5 * time.Second)
+	got, err := client.ListRepositories(context.TODO(), strings.TrimPrefix(server.URL, "http://"), Access{Insecure: true})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(got).To(gomega.Equal([]string{"default-functions", "stage-functions"}))
+}
+
+func TestRegistryClient_DeleteManifest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{
+			name:   "deleted manifest",
+			status: http.StatusAccepted,
+		},
+		{
+			name:   "missing manifest",
+			status: http.StatusNotFound,
+		},
+		{
+			name:    "deletion disabled",
+			status:  http.StatusMethodNotAllowed,
+			wantErr: ErrDeleteDisabled,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			var deleted string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					deleted = r.URL.Path
+				}
+				w.WriteHeader(testCase.status)
+			}))
+			defer server.Close()
+
+			client := NewRegistryClient(5 * time.Second)
+			err := client.DeleteManifest(context.TODO(), strings.TrimPrefix(server.URL, "http://"), "functions", "sha256:123", Access{Insecure: true})
+			g.Expect(deleted).To(gomega.Equal("/v2/functions/manifests/sha256:123"))
+			if testCase.wantErr != nil {
+				g.Expect(err).To(gomega.Equal(testCase.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+		})
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		image          string
+		wantRegistry   string
+		wantRepository string
+		wantTag        string
+		wantErr        bool
+	}{
+		{
+			name:           "registry with port",
+			image:          "registry.kyma.local:5000/functions:abc",
+			wantRegistry:   "registry.kyma.local:5000",
+			wantRepository: "functions",
+			wantTag:        "abc",
+		},
+		{
+			name:           "nested repository",
+			image:          "docker.io/user/functions:abc",
+			wantRegistry:   "docker.io",
+			wantRepository: "user/functions",
+			wantTag:        "abc",
+		},
+		{
+			name:    "missing registry",
+			image:   "functions:abc",
+			wantErr: true,
+		},
+		{
+			name:    "missing tag",
+			image:   "registry.kyma.local:5000/functions",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			registry, repository, tag, err := ParseImage(testCase.image)
+			if testCase.wantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(registry).To(gomega.Equal(testCase.wantRegistry))
+			g.Expect(repository).To(gomega.Equal(testCase.wantRepository))
+			g.Expect(tag).To(gomega.Equal(testCase.wantTag))
+		})
+	}
+}
+
+type delegatingHandler struct {
+	handler http.HandlerFunc
+}
+
+func (h *delegatingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler(w, r)
+}
+
+// tokenHandler serves the test manifest to requests with a token issued by the realm, which issues tokens to authorized requests
+func tokenHandler(realm string, authorized func(r *http.Request) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if !authorized(r) || r.URL.Query().Get("scope") != "repository:functions:pull,push" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token": "secret-token"}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set(wwwAuthenticateHeader, fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:functions:pull,push"`, realm))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// manifestHandler serves the test manifest to authorized requests and asks the others for basic authentication
+func manifestHandler(authorized func(r *http.Request) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != testManifestPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !authorized(r) {
+			w.Header().Set(wwwAuthenticateHeader, `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,104 @@
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	githubEventHeader     = "X-GitHub-Event"
+	githubSignatureHeader = "X-Hub-Signature-256"
+	giteaEventHeader      = "X-Gitea-Event"
+	giteaSignatureHeader  = "X-Gitea-Signature"
+	gitlabEventHeader     = "X-Gitlab-Event"
+	gitlabTokenHeader     = "X-Gitlab-Token"
+
+	githubSignaturePrefix = "sha256="
+)
+
+var (
+	errUnknownProvider  = errors.New("unknown webhook provider")
+	errInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// push is a push to a Git repository, Ref is the full name of the pushed reference, e.g. refs/heads/main
+type push struct {
+	Ref    string `json:"ref"`
+	Commit string `json:"after"`
+}
+
+// parsePush verifies the payload sent by GitHub, GitLab or Gitea with the webhook secret and returns the push it describes.
+// It returns nil for events other than pushes, e.g. for the ping sent by GitHub when the webhook is created.
+func parsePush(header http.Header, payload []byte, secret []byte) (*push, error) {
+	var isPush bool
+	switch {
+	// Gitea sends the GitHub headers as well, so it has to be checked first
+	case header.Get(giteaEventHeader) != "":
+		if !validHMAC(payload, secret, header.Get(giteaSignatureHeader)) {
+			return nil, errInvalidSignature
+		}
+		isPush = header.Get(giteaEventHeader) == "push"
+	case header.Get(githubEventHeader) != "":
+		signature := header.Get(githubSignatureHeader)
+		if !strings.HasPrefix(signature, githubSignaturePrefix) || !validHMAC(payload, secret, strings.TrimPrefix(signature, githubSignaturePrefix)) {
+			return nil, errInvalidSignature
+		}
+		isPush = header.Get(githubEventHeader) == "push"
+	case header.Get(gitlabEventHeader) != "":
+		// GitLab does not sign the payload, it sends the secret token instead
+		if subtle.ConstantTimeCompare([]byte(header.Get(gitlabTokenHeader)), secret) != 1 {
+			return nil, errInvalidSignature
+		}
+		isPush = header.Get(gitlabEventHeader) == "Push Hook" || header.Get(gitlabEventHeader) == "Tag Push Hook"
+	default:
+		return nil, errUnknownProvider
+	}
+
+	if !isPush {
+		return nil, nil
+	}
+
+	var result push
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil, errors.Wrap(err, "while decoding the push payload")
+	}
+	if result.Ref == "" {
+		return nil, errors.New("push payload without ref")
+	}
+	return &result, nil
+}
+
+func validHMAC(payload, secret []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// matchesReference returns true if the pushed ref is the branch or tag a Function is built from.
+// Functions can reference a branch or tag by its short or full name, Functions pinned to a commit are never updated by pushes.
+func (p *push) matchesReference(reference string) bool {
+	if reference == "" {
+		return false
+	}
+	if reference == p.Ref {
+		return true
+	}
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if strings.HasPrefix(p.Ref, prefix) && strings.TrimPrefix(p.Ref, prefix) == reference {
+			return true
+		}
+	}
+	return false
+}
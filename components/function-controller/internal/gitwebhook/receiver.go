@@ -0,0 +1,183 @@
+package gitwebhook
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+const (
+	maxPayloadSize  = 5 << 20
+	shutdownTimeout = 10 * time.Second
+)
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+	Port    int  `envconfig:"default=8090"`
+}
+
+// Receiver accepts push webhooks of Git servers at /namespaces/{namespace}/gitrepositories/{name}. It verifies
+// the payload with the webhook secret of the GitRepository and sends an event for every Function built from the pushed
+// branch or tag of that repository, so that Function Controller fetches the new commit without waiting for the next poll.
+type Receiver struct {
+	client client.Client
+	log    logr.Logger
+	config Config
+	events chan<- event.GenericEvent
+}
+
+func New(client client.Client, log logr.Logger, config Config, events chan<- event.GenericEvent) *Receiver {
+	return &Receiver{
+		client: client,
+		log:    log.WithName("git-webhook"),
+		config: config,
+		events: events,
+	}
+}
+
+// Start runs the HTTP server of the receiver until the stop channel is closed
+func (r *Receiver) Start(stop <-chan struct{}) error {
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", r.config.Port),
+		Handler: r,
+	}
+
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			r.log.Error(err, "while shutting down the server")
+		}
+	}()
+
+	r.log.Info("Starting git webhook receiver", "port", r.config.Port)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repository, ok := repositoryFromPath(req.URL.Path)
+	if !ok {
+		http.Error(w, "path has to be /namespaces/{namespace}/gitrepositories/{name}", http.StatusNotFound)
+		return
+	}
+	log := r.log.WithValues("namespace", repository.Namespace, "name", repository.Name)
+
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "unable to read the payload", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := r.webhookSecret(req.Context(), repository)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			http.Error(w, fmt.Sprintf("webhook of repository %s is not configured", repository), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "while reading the webhook secret")
+		http.Error(w, "unable to verify the webhook", http.StatusInternalServerError)
+		return
+	}
+
+	push, err := parsePush(req.Header, payload, secret)
+	switch {
+	case err == errInvalidSignature:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case push == nil:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	functions, err := r.pushedFunctions(req.Context(), repository, push)
+	if err != nil {
+		log.Error(err, "while listing functions")
+		http.Error(w, "unable to list functions", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range functions {
+		select {
+		case r.events <- event.GenericEvent{Meta: &functions[i], Object: &functions[i]}:
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	log.Info(fmt.Sprintf("Push to %s triggered %d functions", push.Ref, len(functions)), "commit", push.Commit)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// webhookSecret returns the webhook secret of the repository, a repository without webhook is reported as not found
+func (r *Receiver) webhookSecret(ctx context.Context, repository types.NamespacedName) ([]byte, error) {
+	var gitRepository serverlessv1alpha1.GitRepository
+	if err := r.client.Get(ctx, repository, &gitRepository); err != nil {
+		return nil, errors.Wrap(err, "while getting the repository")
+	}
+	if gitRepository.Spec.Webhook == nil {
+		return nil, apierrors.NewNotFound(serverlessv1alpha1.Resource("gitrepositories"), repository.Name)
+	}
+
+	var secret corev1.Secret
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: repository.Namespace, Name: gitRepository.Spec.Webhook.SecretName}, &secret); err != nil {
+		return nil, errors.Wrap(err, "while getting the webhook secret")
+	}
+
+	value, ok := secret.Data[serverlessv1alpha1.RepositoryWebhookSecretKey]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s key", secret.Name, serverlessv1alpha1.RepositoryWebhookSecretKey)
+	}
+	return value, nil
+}
+
+// pushedFunctions returns the git Functions built from the repository and the pushed reference
+func (r *Receiver) pushedFunctions(ctx context.Context, repository types.NamespacedName, push *push) ([]serverlessv1alpha1.Function, error) {
+	var functions serverlessv1alpha1.FunctionList
+	if err := r.client.List(ctx, &functions, client.InNamespace(repository.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var result []serverlessv1alpha1.Function
+	for _, function := range functions.Items {
+		if function.Spec.Type != serverlessv1alpha1.SourceTypeGit || function.Spec.Source != repository.Name {
+			continue
+		}
+		if push.matchesReference(function.Spec.Reference) {
+			result = append(result, function)
+		}
+	}
+	return result, nil
+}
+
+func repositoryFromPath(path string) (types.NamespacedName, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "namespaces" || parts[2] != "gitrepositories" || parts[1] == "" || parts[3] == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: parts[1], Name: parts[3]}, true
+}
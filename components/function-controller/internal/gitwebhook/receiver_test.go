@@ -0,0 +1,261 @@
+package gitwebhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	serverlessv1alpha1 "github.com/kyma-project/kyma/components/function-controller/pkg/apis/serverless/v1alpha1"
+)
+
+const (
+	testSecret  = "webhook-secret"
+	testPayload = `{"ref":"refs/heads/main","after":"a376218bdcd705cc39aa7ce7f310769fab6d51c9"}`
+)
+
+func TestReceiver_ServeHTTP(t *testing.T) {
+	repository := &serverlessv1alpha1.GitRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "default"},
+		Spec: serverlessv1alpha1.GitRepositorySpec{
+			URL:     "https://github.com/kyma-project/examples.git",
+			Webhook: &serverlessv1alpha1.RepositoryWebhook{SecretName: "repo-webhook"},
+		},
+	}
+	repositoryWithoutWebhook := &serverlessv1alpha1.GitRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-webhook", Namespace: "default"},
+		Spec:       serverlessv1alpha1.GitRepositorySpec{URL: "https://github.com/kyma-project/examples.git"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo-webhook", Namespace: "default"},
+		Data:       map[string][]byte{serverlessv1alpha1.RepositoryWebhookSecretKey: []byte(testSecret)},
+	}
+	objects := []runtime.Object{
+		repository, repositoryWithoutWebhook, secret,
+		newGitFunction("main-branch", "repo", "main"),
+		newGitFunction("full-ref", "repo", "refs/heads/main"),
+		newGitFunction("other-branch", "repo", "develop"),
+		newGitFunction("other-repo", "no-webhook", "main"),
+		&serverlessv1alpha1.Function{
+			ObjectMeta: metav1.ObjectMeta{Name: "inline", Namespace: "default"},
+			Spec:       serverlessv1alpha1.FunctionSpec{Source: "repo"},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		header        http.Header
+		wantStatus    int
+		wantFunctions []string
+	}{
+		{
+			name:          "requeues functions built from the pushed branch",
+			path:          "/namespaces/default/gitrepositories/repo",
+			header:        githubHeader("push", githubSignaturePrefix+sign(testPayload, testSecret)),
+			wantStatus:    http.StatusAccepted,
+			wantFunctions: []string{"main-branch", "full-ref"},
+		},
+		{
+			name:       "ignores events other than pushes",
+			path:       "/namespaces/default/gitrepositories/repo",
+			header:     githubHeader("ping", githubSignaturePrefix+sign(testPayload, testSecret)),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "rejects an invalid signature",
+			path:       "/namespaces/default/gitrepositories/repo",
+			header:     githubHeader("push", githubSignaturePrefix+sign(testPayload, "other-secret")),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "rejects a repository without webhook",
+			path:       "/namespaces/default/gitrepositories/no-webhook",
+			header:     githubHeader("push", githubSignaturePrefix+sign(testPayload, testSecret)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects a missing repository",
+			path:       "/namespaces/default/gitrepositories/missing",
+			header:     githubHeader("push", githubSignaturePrefix+sign(testPayload, testSecret)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects an invalid path",
+			path:       "/default/repo",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			events := make(chan event.GenericEvent, 10)
+			receiver := New(fake.NewFakeClientWithScheme(newScheme(g), objects...), zap.New(), Config{}, events)
+
+			req := httptest.NewRequest(http.MethodPost, testCase.path, bytes.NewBufferString(testPayload))
+			for key := range testCase.header {
+				req.Header.Set(key, testCase.header.Get(key))
+			}
+			res := httptest.NewRecorder()
+
+			receiver.ServeHTTP(res, req)
+			close(events)
+
+			g.Expect(res.Code).To(gomega.Equal(testCase.wantStatus))
+			var functions []string
+			for e := range events {
+				functions = append(functions, e.Meta.GetName())
+			}
+			g.Expect(functions).To(gomega.ConsistOf(testCase.wantFunctions))
+		})
+	}
+}
+
+func TestParsePush(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   http.Header
+		wantPush bool
+		wantErr  error
+	}{
+		{
+			name:     "GitHub push",
+			header:   githubHeader("push", githubSignaturePrefix+sign(testPayload, testSecret)),
+			wantPush: true,
+		},
+		{
+			name:    "GitHub push without signature prefix",
+			header:  githubHeader("push", sign(testPayload, testSecret)),
+			wantErr: errInvalidSignature,
+		},
+		{
+			name: "Gitea push",
+			header: newHeader(
+				giteaEventHeader, "push",
+				giteaSignatureHeader, sign(testPayload, testSecret),
+				githubEventHeader, "push",
+				githubSignatureHeader, "invalid",
+			),
+			wantPush: true,
+		},
+		{
+			name: "Gitea push with invalid signature",
+			header: newHeader(
+				giteaEventHeader, "push",
+				giteaSignatureHeader, sign(testPayload, "other-secret"),
+			),
+			wantErr: errInvalidSignature,
+		},
+		{
+			name: "GitLab tag push",
+			header: newHeader(
+				gitlabEventHeader, "Tag Push Hook",
+				gitlabTokenHeader, testSecret,
+			),
+			wantPush: true,
+		},
+		{
+			name: "GitLab merge request",
+			header: newHeader(
+				gitlabEventHeader, "Merge Request Hook",
+				gitlabTokenHeader, testSecret,
+			),
+		},
+		{
+			name: "GitLab push with invalid token",
+			header: newHeader(
+				gitlabEventHeader, "Push Hook",
+				gitlabTokenHeader, "other-secret",
+			),
+			wantErr: errInvalidSignature,
+		},
+		{
+			name:    "unknown provider",
+			header:  http.Header{},
+			wantErr: errUnknownProvider,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			got, err := parsePush(testCase.header, []byte(testPayload), []byte(testSecret))
+			if testCase.wantErr != nil {
+				g.Expect(err).To(gomega.Equal(testCase.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			if !testCase.wantPush {
+				g.Expect(got).To(gomega.BeNil())
+				return
+			}
+			g.Expect(got).To(gomega.Equal(&push{Ref: "refs/heads/main", Commit: "a376218bdcd705cc39aa7ce7f310769fab6d51c9"}))
+		})
+	}
+}
+
+func TestPush_matchesReference(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	branch := &push{Ref: "refs/heads/main"}
+	g.Expect(branch.matchesReference("main")).To(gomega.BeTrue())
+	g.Expect(branch.matchesReference("refs/heads/main")).To(gomega.BeTrue())
+	g.Expect(branch.matchesReference("develop")).To(gomega.BeFalse())
+	g.Expect(branch.matchesReference("")).To(gomega.BeFalse())
+	g.Expect(branch.matchesReference("a376218bdcd705cc39aa7ce7f310769fab6d51c9")).To(gomega.BeFalse())
+
+	tag := &push{Ref: "refs/tags/v1.0.0"}
+	g.Expect(tag.matchesReference("v1.0.0")).To(gomega.BeTrue())
+	g.Expect(tag.matchesReference("main")).To(gomega.BeFalse())
+}
+
+func newGitFunction(name, repository, reference string) *serverlessv1alpha1.Function {
+	return &serverlessv1alpha1.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: serverlessv1alpha1.FunctionSpec{
+			Type:       serverlessv1alpha1.SourceTypeGit,
+			Source:     repository,
+			Repository: serverlessv1alpha1.Repository{Reference: reference},
+		},
+	}
+}
+
+func githubHeader(event, signature string) http.Header {
+	return newHeader(githubEventHeader, event, githubSignatureHeader, signature)
+}
+
+func newHeader(keysAndValues ...string) http.Header {
+	header := http.Header{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		header.Set(keysAndValues[i], keysAndValues[i+1])
+	}
+	return header
+}
+
+func sign(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newScheme(g *gomega.WithT) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(serverlessv1alpha1.AddToScheme(scheme)).To(gomega.Succeed())
+	return scheme
+}
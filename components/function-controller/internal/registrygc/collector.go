@@ -0,0 +1,144 @@
+// Package registrygc runs the garbage collection of the Docker registry in a Job. Deleting a manifest through the
+// registry API only unlinks it, the blobs of its layers stay in the storage until the garbage collection removes them.
+package registrygc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// JobLabel marks the Jobs created from the CronJob of the garbage collection
+const JobLabel = "serverless.kyma-project.io/registry-gc"
+
+type Config struct {
+	Namespace string `envconfig:"default=kyma-system"`
+	// CronJobName is the suspended CronJob of the registry chart, whose Job template runs the garbage collection
+	// with the storage of the registry mounted
+	CronJobName     string        `envconfig:"default=serverless-docker-registry-gc"`
+	JobTimeout      time.Duration `envconfig:"default=10m"`
+	JobPollInterval time.Duration `envconfig:"default=5s"`
+}
+
+// Collector runs the garbage collection of the registry in a Job created from the Job template of a CronJob
+type Collector struct {
+	clientset kubernetes.Interface
+	config    Config
+}
+
+func New(clientset kubernetes.Interface, config Config) *Collector {
+	return &Collector{
+		clientset: clientset,
+		config:    config,
+	}
+}
+
+// CollectBlobs removes the blobs which are not referenced by any manifest and returns the name of the Job which
+// removed them. The Jobs of previous runs are deleted first, so a failed Job is kept until the next run. A Job which
+// does not finish within the Job timeout is deleted.
+func (c *Collector) CollectBlobs(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.JobTimeout)
+	defer cancel()
+
+	if err := c.deleteJobs(ctx); err != nil {
+		return "", err
+	}
+
+	job, err := c.createJob(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = wait.PollImmediateUntil(c.config.JobPollInterval, func() (bool, error) {
+		return c.isJobFinished(ctx, job.Name)
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		// the context is done already, the Job is deleted with a fresh one
+		deleteCtx, deleteCancel := context.WithTimeout(context.Background(), c.config.JobPollInterval)
+		defer deleteCancel()
+		_ = c.deleteJob(deleteCtx, job.Name)
+		return job.Name, errors.Errorf("garbage collection Job %s not finished within %s", job.Name, c.config.JobTimeout)
+	}
+	if err != nil {
+		return job.Name, err
+	}
+
+	return job.Name, c.deleteJob(ctx, job.Name)
+}
+
+func (c *Collector) createJob(ctx context.Context) (*batchv1.Job, error) {
+	cronJob, err := c.clientset.BatchV1beta1().CronJobs(c.config.Namespace).Get(ctx, c.config.CronJobName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "while reading garbage collection CronJob %s", c.config.CronJobName)
+	}
+
+	labels := map[string]string{JobLabel: "true"}
+	for key, value := range cronJob.Spec.JobTemplate.Labels {
+		labels[key] = value
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", cronJob.Name),
+			Namespace:    cronJob.Namespace,
+			Labels:       labels,
+			Annotations:  cronJob.Spec.JobTemplate.Annotations,
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+
+	created, err := c.clientset.BatchV1().Jobs(c.config.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "while creating garbage collection Job from CronJob %s", cronJob.Name)
+	}
+	return created, nil
+}
+
+// isJobFinished returns true once the Job succeeded and an error once it failed
+func (c *Collector) isJobFinished(ctx context.Context, name string) (bool, error) {
+	job, err := c.clientset.BatchV1().Jobs(c.config.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "while reading garbage collection Job %s", name)
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return false, errors.Errorf("garbage collection Job %s failed: %s", name, condition.Message)
+		}
+	}
+	return false, nil
+}
+
+func (c *Collector) deleteJobs(ctx context.Context) error {
+	jobs, err := c.clientset.BatchV1().Jobs(c.config.Namespace).List(ctx, metav1.ListOptions{LabelSelector: JobLabel + "=true"})
+	if err != nil {
+		return errors.Wrap(err, "while listing garbage collection Jobs")
+	}
+	for _, job := range jobs.Items {
+		if err := c.deleteJob(ctx, job.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Collector) deleteJob(ctx context.Context, name string) error {
+	propagation := metav1.DeletePropagationBackground
+	err := c.clientset.BatchV1().Jobs(c.config.Namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "while deleting garbage collection Job %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package registrygc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCollector_CollectBlobs(t *testing.T) {
+	cronJob := &v1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "serverless-docker-registry-gc", Namespace: "kyma-system"},
+		Spec: v1beta1.CronJobSpec{
+			Suspend: boolPtr(true),
+			JobTemplate: v1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "docker-registry-gc"}},
+				Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "garbage-collect", Command: []string{"/bin/registry", "garbage-collect"}}},
+				}}},
+			},
+		},
+	}
+	previousJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:      "serverless-docker-registry-gc-previous",
+		Namespace: "kyma-system",
+		Labels:    map[string]string{JobLabel: "true"},
+	}}
+
+	testCases := []struct {
+		name          string
+		objects       []runtime.Object
+		jobCondition  batchv1.JobConditionType
+		wantErr       bool
+		wantJobsAfter int
+	}{
+		{
+			name:          "deletes the previous Job and the Job which succeeded",
+			objects:       []runtime.Object{cronJob.DeepCopy(), previousJob.DeepCopy()},
+			jobCondition:  batchv1.JobComplete,
+			wantJobsAfter: 0,
+		},
+		{
+			name:          "keeps the Job which failed",
+			objects:       []runtime.Object{cronJob.DeepCopy()},
+			jobCondition:  batchv1.JobFailed,
+			wantErr:       true,
+			wantJobsAfter: 1,
+		},
+		{
+			name:          "deletes the Job which does not finish within the timeout",
+			objects:       []runtime.Object{cronJob.DeepCopy()},
+			wantErr:       true,
+			wantJobsAfter: 0,
+		},
+		{
+			name:    "fails without CronJob",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			clientset := fake.NewSimpleClientset(testCase.objects...)
+			created := 0
+			// the fake clientset neither generates names nor runs Jobs
+			clientset.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+				created++
+				job.Name = fmt.Sprintf("%s%d", job.GenerateName, created)
+				if testCase.jobCondition != "" {
+					job.Status.Conditions = []batchv1.JobCondition{{Type: testCase.jobCondition, Status: corev1.ConditionTrue}}
+				}
+				return false, nil, nil
+			})
+
+			collector := New(clientset, Config{
+				Namespace:       "kyma-system",
+				CronJobName:     "serverless-docker-registry-gc",
+				JobTimeout:      50 * time.Millisecond,
+				JobPollInterval: 10 * time.Millisecond,
+			})
+
+			name, err := collector.CollectBlobs(context.TODO())
+			if testCase.wantErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(name).To(gomega.Equal("serverless-docker-registry-gc-1"))
+			}
+
+			jobs, err := clientset.BatchV1().Jobs("kyma-system").List(context.TODO(), metav1.ListOptions{})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(jobs.Items).To(gomega.HaveLen(testCase.wantJobsAfter))
+			for _, job := range jobs.Items {
+				g.Expect(job.Labels).To(gomega.HaveKeyWithValue(JobLabel, "true"))
+				g.Expect(job.Labels).To(gomega.HaveKeyWithValue("app", "docker-registry-gc"))
+				g.Expect(job.Spec.Template.Spec.Containers[0].Command).To(gomega.ContainElement("garbage-collect"))
+			}
+		})
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
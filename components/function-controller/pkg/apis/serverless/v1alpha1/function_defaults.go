@@ -3,10 +3,12 @@ package v1alpha1
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/webhook/resourcesemantics"
 )
 
@@ -30,6 +32,7 @@ type FunctionReplicasDefaulting struct {
 	DefaultPreset string                    `envconfig:"default=S"`
 	Presets       map[string]ReplicasPreset `envconfig:"-"`
 	PresetsMap    string                    `envconfig:"default={}"`
+	IdleTimeout   time.Duration             `envconfig:"default=15m"`
 }
 
 type FunctionResourcesDefaulting struct {
@@ -88,6 +91,9 @@ func (spec *FunctionSpec) defaultReplicas(ctx context.Context, fn *Function) {
 
 		spec.MaxReplicas = &newMax
 	}
+	if *spec.MinReplicas == 0 && spec.IdleTimeout == nil {
+		spec.IdleTimeout = &metav1.Duration{Duration: defaultingConfig.IdleTimeout}
+	}
 }
 
 func (spec *FunctionSpec) defaultFunctionResources(ctx context.Context, fn *Function) {
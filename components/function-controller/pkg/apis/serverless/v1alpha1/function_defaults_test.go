@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"context"
 	"testing"
+	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -328,6 +329,7 @@ func TestSetDefaults(t *testing.T) {
 					},
 					MinReplicas: &zero,
 					MaxReplicas: &zero,
+					IdleTimeout: &v1.Duration{Duration: 15 * time.Minute},
 				},
 			},
 		},
@@ -0,0 +1,44 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const revisionNamePrefix = "v"
+
+// RevisionName returns the name of the revision with the given number, revisions are numbered from 1
+func RevisionName(number int) string {
+	return fmt.Sprintf("%s%d", revisionNamePrefix, number)
+}
+
+// RevisionNumber returns the number of the revision with the given name
+func RevisionNumber(name string) (int, bool) {
+	if !strings.HasPrefix(name, revisionNamePrefix) {
+		return 0, false
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(name, revisionNamePrefix))
+	if err != nil || number < 1 || RevisionName(number) != name {
+		return 0, false
+	}
+	return number, true
+}
+
+// SplitReplicas returns the replicas of a revision receiving the given percent of requests split between revisions.
+// Requests are split by the number of replicas, so the split is exact only if the percent of minReplicas is a whole
+// number of replicas, it returns false otherwise.
+func SplitReplicas(percent, minReplicas int32) (int32, bool) {
+	replicas := percent * minReplicas / 100
+	return replicas, replicas > 0 && replicas*100 == percent*minReplicas
+}
+
+// FindRevision returns the revision with the given name from the status of the Function
+func (in *FunctionStatus) FindRevision(name string) (FunctionRevision, bool) {
+	for _, revision := range in.Revisions {
+		if revision.Name == name {
+			return revision, true
+		}
+	}
+	return FunctionRevision{}, false
+}
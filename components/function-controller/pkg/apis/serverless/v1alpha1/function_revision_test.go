@@ -0,0 +1,37 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestRevisionNumber(t *testing.T) {
+	for testName, testData := range map[string]struct {
+		name       string
+		wantNumber int
+		wantOk     bool
+	}{
+		"first revision":       {name: "v1", wantNumber: 1, wantOk: true},
+		"later revision":       {name: "v42", wantNumber: 42, wantOk: true},
+		"missing prefix":       {name: "42"},
+		"missing number":       {name: "v"},
+		"zero":                 {name: "v0"},
+		"leading zero":         {name: "v01"},
+		"negative number":      {name: "v-1"},
+		"not a revision name":  {name: "latest"},
+		"number with a suffix": {name: "v1a"},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			number, ok := RevisionNumber(testData.name)
+
+			g.Expect(ok).To(gomega.Equal(testData.wantOk))
+			g.Expect(number).To(gomega.Equal(testData.wantNumber))
+			if ok {
+				g.Expect(RevisionName(number)).To(gomega.Equal(testData.name))
+			}
+		})
+	}
+}
@@ -11,9 +11,11 @@ const (
 	SourceTypeGit SourceType = "git"
 )
 
-// Runtime enumerates runtimes that are currently supported by Function Controller
+// Runtime is the name of a runtime supported by Function Controller, either a built-in one
+// or the name of a FunctionRuntime
 // It is a subset of RuntimeExtended
-// +kubebuilder:validation:Enum=nodejs12;nodejs14;python38;python39
+// +kubebuilder:validation:MaxLength=63
+// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 type Runtime string
 
 const (
@@ -23,9 +25,8 @@ const (
 	Python39 Runtime = "python39"
 )
 
-// RuntimeExtended enumerates runtimes that are either currently supported or
-// no longer supported but there still might be "read-only" Functions using them
-// +kubebuilder:validation:Enum=nodejs12;nodejs14;python38;nodejs10;python39
+// RuntimeExtended is the name of a runtime that is either currently supported or
+// no longer supported but there still might be "read-only" Functions using it
 type RuntimeExtended string
 
 const (
@@ -50,24 +51,46 @@ type FunctionSpec struct {
 	// Deps defines the dependencies for a function
 	Deps string `json:"deps,omitempty"`
 
+	// Files defines additional source files of an inline function, keyed by their path relative to the function source,
+	// e.g. lib/helper.js
+	// +optional
+	Files map[string]string `json:"files,omitempty"`
+
 	// +optional
 	Runtime Runtime `json:"runtime,omitempty"`
 
 	// Env defines an array of key value pairs need to be used as env variable for a function
 	Env []corev1.EnvVar `json:"env,omitempty"`
 
+	// Volumes defines ConfigMaps and Secrets mounted into the function container
+	// +optional
+	Volumes []FunctionVolume `json:"volumes,omitempty"`
+
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
 	// +optional
 	BuildResources corev1.ResourceRequirements `json:"buildResources,omitempty"`
 
-	// +kubebuilder:validation:Minimum:=1
+	// MinReplicas defines the minimum number of replicas of a function, 0 scales the function to zero after IdleTimeout
+	// +kubebuilder:validation:Minimum:=0
 	MinReplicas *int32 `json:"minReplicas,omitempty"`
 
 	// +kubebuilder:validation:Minimum:=1
 	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
 
+	// IdleTimeout defines how long a function with minReplicas 0 runs without receiving requests before it is scaled to zero
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+
+	// Traffic splits the requests to the function between its revisions, all requests go to the latest revision if it is empty
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+
+	// Triggers subscribe the function to events, the function controller manages an eventing Subscription for each of them
+	// +optional
+	Triggers []Trigger `json:"triggers,omitempty"`
+
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
@@ -77,6 +100,78 @@ type FunctionSpec struct {
 	Repository `json:",inline,omitempty"`
 }
 
+// TrafficTarget defines the share of requests sent to a revision of the function
+type TrafficTarget struct {
+	// Revision is the name of a revision from status.revisions, the latest revision is used if it is empty
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Percent of the requests sent to the revision
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=100
+	Percent int32 `json:"percent"`
+}
+
+// FunctionVolume mounts a ConfigMap or a Secret from the namespace of the function as a read-only directory,
+// exactly one of ConfigMapName and SecretName has to be set
+type FunctionVolume struct {
+	// Name of the volume, unique within the function
+	Name string `json:"name"`
+
+	// MountPath is the absolute path of the directory the volume is mounted at
+	MountPath string `json:"mountPath"`
+
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// Trigger subscribes the function to the events of a type published by a source
+type Trigger struct {
+	// Source is the name of the application publishing the events, e.g. commercemock
+	Source string `json:"source"`
+
+	// Type of the events, e.g. order.created
+	Type string `json:"type"`
+
+	// Version of the event type, e.g. v1
+	Version string `json:"version"`
+
+	// Filter narrows down the events delivered to the function
+	// +optional
+	Filter *TriggerFilter `json:"filter,omitempty"`
+}
+
+// TriggerFilter selects events by their extension attributes, the events have to match all of the filters
+type TriggerFilter struct {
+	// +optional
+	Extensions []ExtensionFilter `json:"extensions,omitempty"`
+
+	// Expression is a CloudEvents SQL expression the events have to match, e.g. tenant = 'acme'
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// ExtensionFilter matches an extension attribute of the events
+type ExtensionFilter struct {
+	// Property is the name of the extension attribute
+	Property string `json:"property"`
+
+	// Type of the match, exact if it is empty
+	// +optional
+	// +kubebuilder:validation:Enum=exact;prefix
+	Type string `json:"type,omitempty"`
+
+	Value string `json:"value"`
+}
+
+const (
+	ExtensionFilterTypeExact  = "exact"
+	ExtensionFilterTypePrefix = "prefix"
+)
+
 const (
 	FunctionNameLabel                    = "serverless.kyma-project.io/function-name"
 	FunctionManagedByLabel               = "serverless.kyma-project.io/managed-by"
@@ -85,6 +180,18 @@ const (
 	FunctionResourceLabel                = "serverless.kyma-project.io/resource"
 	FunctionResourceLabelDeploymentValue = "deployment"
 	FunctionResourceLabelUserValue       = "user"
+	// FunctionResourceLabelPrivateServiceValue marks the Service the activator forwards requests for a function scaled to zero to
+	FunctionResourceLabelPrivateServiceValue = "private-service"
+	// FunctionResourceLabelActivatorRouteValue marks the Service in the namespace of the activator which maps a port of the activator to a function
+	FunctionResourceLabelActivatorRouteValue = "activator-route"
+	// FunctionNamespaceLabel holds the namespace of the function an activator route belongs to
+	FunctionNamespaceLabel = "serverless.kyma-project.io/function-namespace"
+	// FunctionLastRequestTimeAnnotation is set by the activator to the time of the latest request to a function with minReplicas 0
+	FunctionLastRequestTimeAnnotation = "serverless.kyma-project.io/last-request-time"
+	// FunctionRevisionLabel holds the name of the revision of the function a Deployment runs
+	FunctionRevisionLabel = "serverless.kyma-project.io/revision"
+	// FunctionTriggerLabel holds the index in spec.triggers of the trigger a Subscription was created for
+	FunctionTriggerLabel = "serverless.kyma-project.io/trigger"
 )
 
 // ConditionType defines condition of function.
@@ -94,6 +201,7 @@ const (
 	ConditionRunning            ConditionType = "Running"
 	ConditionConfigurationReady ConditionType = "ConfigurationReady"
 	ConditionBuildReady         ConditionType = "BuildReady"
+	ConditionSubscriptionsReady ConditionType = "SubscriptionsReady"
 )
 
 type ConditionReason string
@@ -103,21 +211,33 @@ const (
 	ConditionReasonConfigMapUpdated               ConditionReason = "ConfigMapUpdated"
 	ConditionReasonSourceUpdated                  ConditionReason = "SourceUpdated"
 	ConditionReasonSourceUpdateFailed             ConditionReason = "SourceUpdateFailed"
+	ConditionReasonRuntimeNotFound                ConditionReason = "RuntimeNotFound"
 	ConditionReasonJobFailed                      ConditionReason = "JobFailed"
 	ConditionReasonJobCreated                     ConditionReason = "JobCreated"
 	ConditionReasonJobUpdated                     ConditionReason = "JobUpdated"
 	ConditionReasonJobRunning                     ConditionReason = "JobRunning"
 	ConditionReasonJobsDeleted                    ConditionReason = "JobsDeleted"
 	ConditionReasonJobFinished                    ConditionReason = "JobFinished"
+	ConditionReasonImageFound                     ConditionReason = "ImageFound"
 	ConditionReasonDeploymentCreated              ConditionReason = "DeploymentCreated"
 	ConditionReasonDeploymentUpdated              ConditionReason = "DeploymentUpdated"
 	ConditionReasonDeploymentFailed               ConditionReason = "DeploymentFailed"
 	ConditionReasonDeploymentWaiting              ConditionReason = "DeploymentWaiting"
 	ConditionReasonDeploymentReady                ConditionReason = "DeploymentReady"
+	ConditionReasonScaledToZero                   ConditionReason = "ScaledToZero"
+	ConditionReasonRevisionCreated                ConditionReason = "RevisionCreated"
+	ConditionReasonRevisionRestored               ConditionReason = "RevisionRestored"
+	ConditionReasonRevisionNotFound               ConditionReason = "RevisionNotFound"
+	ConditionReasonTrafficSplitInvalid            ConditionReason = "TrafficSplitInvalid"
 	ConditionReasonServiceCreated                 ConditionReason = "ServiceCreated"
 	ConditionReasonServiceUpdated                 ConditionReason = "ServiceUpdated"
 	ConditionReasonHorizontalPodAutoscalerCreated ConditionReason = "HorizontalPodAutoscalerCreated"
 	ConditionReasonHorizontalPodAutoscalerUpdated ConditionReason = "HorizontalPodAutoscalerUpdated"
+	ConditionReasonSubscriptionCreated            ConditionReason = "SubscriptionCreated"
+	ConditionReasonSubscriptionUpdated            ConditionReason = "SubscriptionUpdated"
+	ConditionReasonSubscriptionDeleted            ConditionReason = "SubscriptionDeleted"
+	ConditionReasonSubscriptionNotReady           ConditionReason = "SubscriptionNotReady"
+	ConditionReasonSubscriptionsReady             ConditionReason = "SubscriptionsReady"
 )
 
 type Condition struct {
@@ -135,6 +255,23 @@ type FunctionStatus struct {
 	Commit     string          `json:"commit,omitempty"`
 	Source     string          `json:"source,omitempty"`
 	Runtime    RuntimeExtended `json:"runtime,omitempty"`
+	// BuildHash is the content hash of the build inputs of the Function image that is ready,
+	// it is used as the image tag so that Functions with the same inputs share one image
+	BuildHash string `json:"buildHash,omitempty"`
+	// Revisions lists the latest built revisions of the Function from the oldest to the newest
+	Revisions []FunctionRevision `json:"revisions,omitempty"`
+	// LatestRevision is the name of the revision built from the current spec of the Function
+	LatestRevision string `json:"latestRevision,omitempty"`
+}
+
+// FunctionRevision is an image built for the Function, it can be run again without rebuilding it
+type FunctionRevision struct {
+	Name       string          `json:"name"`
+	Image      string          `json:"image"`
+	Runtime    RuntimeExtended `json:"runtime,omitempty"`
+	Commit     string          `json:"commit,omitempty"`
+	SourceHash string          `json:"sourceHash"`
+	CreatedAt  metav1.Time     `json:"createdAt"`
 }
 
 type Repository struct {
@@ -3,7 +3,11 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -17,8 +21,28 @@ import (
 
 const ValidationConfigKey = "validation-config"
 
+const (
+	// maxSourcesSize is the size limit of the ConfigMap holding the source code, dependencies and files of a function
+	maxSourcesSize = 1 << 20
+	// the writable /tmp directory is mounted into every function container
+	tmpDirVolumeName = "tmp-dir"
+	tmpDirMountPath  = "/tmp"
+)
+
+var (
+	fileNamePattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	// the application name is a segment of the event type, so it can't contain dots, the eventing controller removes
+	// other special characters from it
+	triggerSourcePattern  = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	triggerTypePattern    = regexp.MustCompile(`^[-_a-zA-Z0-9]+(\.[-_a-zA-Z0-9]+)*$`)
+	triggerVersionPattern = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	// CloudEvents attribute names consist of lower-case letters and digits
+	extensionPropertyPattern = regexp.MustCompile(`^[a-z0-9]+$`)
+)
+
 type MinFunctionReplicasValues struct {
-	MinValue int32 `envconfig:"default=1"`
+	MinValue       int32         `envconfig:"default=1"`
+	MinIdleTimeout time.Duration `envconfig:"default=1m"`
 }
 
 type MinFunctionResourcesValues struct {
@@ -49,9 +73,13 @@ type ValidationConfig struct {
 func (fn *Function) performBasicValidation(ctx context.Context) *apis.FieldError {
 	return fn.validateObjectMeta(ctx).Also(
 		fn.Spec.validateSource(),
+		fn.Spec.validateRuntime(ctx),
 		fn.Spec.validateEnv(ctx),
+		fn.Spec.validateVolumes(),
 		fn.Spec.validateLabels(),
 		fn.Spec.validateReplicas(ctx),
+		fn.Spec.validateTraffic(),
+		fn.Spec.validateTriggers(),
 		fn.Spec.validateFunctionResources(ctx),
 		fn.Spec.validateBuildResources(ctx),
 	)
@@ -63,11 +91,13 @@ func (fn *Function) Validate(ctx context.Context) (errors *apis.FieldError) {
 	if spec.Type == SourceTypeGit {
 		return fn.performBasicValidation(ctx).Also(
 			spec.validateRepository(),
+			spec.validateNoFiles(),
 		)
 	}
 
 	return fn.performBasicValidation(ctx).Also(
-		spec.validateDeps(),
+		spec.validateDeps(ctx),
+		spec.validateFiles(ctx),
 	)
 }
 
@@ -93,13 +123,181 @@ func (spec *FunctionSpec) validateSource() *apis.FieldError {
 	return nil
 }
 
-func (spec *FunctionSpec) validateDeps() *apis.FieldError {
-	if err := ValidateDependencies(spec.Runtime, spec.Deps); err != nil {
+func (spec *FunctionSpec) validateRuntime(ctx context.Context) *apis.FieldError {
+	if _, err := ResolveFunctionRuntime(ctx, functionRuntimeGetter(ctx), spec.Runtime); err != nil {
+		return apis.ErrInvalidValue(err.Error(), "spec.runtime")
+	}
+	return nil
+}
+
+func (spec *FunctionSpec) validateDeps(ctx context.Context) *apis.FieldError {
+	runtimeSpec, err := ResolveFunctionRuntime(ctx, functionRuntimeGetter(ctx), spec.Runtime)
+	if err != nil {
+		// reported by validateRuntime
+		return nil
+	}
+	if err := ValidateDependencies(runtimeSpec.DependencySanitizer, spec.Deps); err != nil {
 		return apis.ErrInvalidValue(err.Error(), "spec.deps")
 	}
 	return nil
 }
 
+// validateFiles checks that the additional source files are placed next to the source code and dependencies
+// of the runtime without overwriting them, and that all sources fit into a ConfigMap
+func (spec *FunctionSpec) validateFiles(ctx context.Context) (apisError *apis.FieldError) {
+	if len(spec.Files) == 0 {
+		return nil
+	}
+
+	// paths maps the files of the function to their descriptions used in errors
+	paths := map[string]string{}
+	for filePath := range spec.Files {
+		paths[filePath] = fmt.Sprintf("file %s", filePath)
+	}
+	reserved := map[string]string{}
+	if runtimeSpec, err := ResolveFunctionRuntime(ctx, functionRuntimeGetter(ctx), spec.Runtime); err == nil {
+		reserved[runtimeSpec.FunctionFile] = "the source code file of the runtime"
+		reserved[runtimeSpec.DependencyFile] = "the dependencies file of the runtime"
+	}
+	for filePath, description := range reserved {
+		paths[filePath] = description
+	}
+
+	filePaths := make([]string, 0, len(spec.Files))
+	for filePath := range spec.Files {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+
+	size := len(spec.Source) + len(spec.Deps)
+	for _, filePath := range filePaths {
+		size += len(filePath) + len(spec.Files[filePath])
+		if err := validateFilePath(filePath, paths, reserved); err != "" {
+			apisError = apisError.Also(apis.ErrInvalidKeyName(filePath, "spec.files", err))
+		}
+	}
+
+	if size > maxSourcesSize {
+		apisError = apisError.Also(apis.ErrInvalidValue(
+			fmt.Sprintf("size of source, deps and files(%d) is more than the largest allowed value(%d)", size, maxSourcesSize), "spec.files"))
+	}
+	return apisError
+}
+
+func validateFilePath(filePath string, paths, reserved map[string]string) string {
+	if path.IsAbs(filePath) {
+		return "path has to be relative to the function source"
+	}
+
+	segments := strings.Split(filePath, "/")
+	for _, segment := range segments {
+		if segment == "." || segment == ".." || !fileNamePattern.MatchString(segment) {
+			return "path has to consist of file names made of alphanumeric characters, '-', '_' or '.', separated by '/'"
+		}
+	}
+
+	if description, ok := reserved[filePath]; ok {
+		return fmt.Sprintf("path is used by %s", description)
+	}
+	for i := 1; i < len(segments); i++ {
+		if description, ok := paths[path.Join(segments[:i]...)]; ok {
+			return fmt.Sprintf("path is inside of %s", description)
+		}
+	}
+	return ""
+}
+
+func (spec *FunctionSpec) validateNoFiles() *apis.FieldError {
+	if len(spec.Files) > 0 {
+		return apis.ErrDisallowedFields("spec.files")
+	}
+	return nil
+}
+
+func (spec *FunctionSpec) validateVolumes() (apisError *apis.FieldError) {
+	names := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for i, volume := range spec.Volumes {
+		fieldPath := fmt.Sprintf("spec.volumes[%d]", i)
+
+		if errs := utilvalidation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("name(%s) is invalid: %s", volume.Name, strings.Join(errs, ", ")), fieldPath+".name"))
+		} else if volume.Name == tmpDirVolumeName || names[volume.Name] {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("name(%s) is already used", volume.Name), fieldPath+".name"))
+		}
+		names[volume.Name] = true
+
+		if !path.IsAbs(volume.MountPath) || path.Clean(volume.MountPath) != volume.MountPath || volume.MountPath == "/" {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("mountPath(%s) is not a clean absolute path of a directory other than /", volume.MountPath), fieldPath+".mountPath"))
+		} else if volume.MountPath == tmpDirMountPath || mountPaths[volume.MountPath] {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("mountPath(%s) is already used", volume.MountPath), fieldPath+".mountPath"))
+		}
+		mountPaths[volume.MountPath] = true
+
+		switch {
+		case volume.ConfigMapName == "" && volume.SecretName == "":
+			apisError = apisError.Also(apis.ErrMissingOneOf(fieldPath+".configMapName", fieldPath+".secretName"))
+		case volume.ConfigMapName != "" && volume.SecretName != "":
+			apisError = apisError.Also(apis.ErrMultipleOneOf(fieldPath+".configMapName", fieldPath+".secretName"))
+		case volume.ConfigMapName != "":
+			if errs := utilvalidation.IsDNS1123Subdomain(volume.ConfigMapName); len(errs) > 0 {
+				apisError = apisError.Also(apis.ErrInvalidValue(
+					fmt.Sprintf("configMapName(%s) is invalid: %s", volume.ConfigMapName, strings.Join(errs, ", ")), fieldPath+".configMapName"))
+			}
+		default:
+			if errs := utilvalidation.IsDNS1123Subdomain(volume.SecretName); len(errs) > 0 {
+				apisError = apisError.Also(apis.ErrInvalidValue(
+					fmt.Sprintf("secretName(%s) is invalid: %s", volume.SecretName, strings.Join(errs, ", ")), fieldPath+".secretName"))
+			}
+		}
+	}
+	return apisError
+}
+
+func (spec *FunctionSpec) validateTriggers() (apisError *apis.FieldError) {
+	for i, trigger := range spec.Triggers {
+		fieldPath := fmt.Sprintf("spec.triggers[%d]", i)
+
+		if !triggerSourcePattern.MatchString(trigger.Source) {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("source(%s) has to consist of letters and digits only", trigger.Source), fieldPath+".source"))
+		}
+		if !triggerTypePattern.MatchString(trigger.Type) {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("type(%s) has to consist of dot-separated segments of letters, digits, '-' and '_'", trigger.Type), fieldPath+".type"))
+		}
+		if !triggerVersionPattern.MatchString(trigger.Version) {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("version(%s) has to consist of letters and digits only", trigger.Version), fieldPath+".version"))
+		}
+
+		if trigger.Filter == nil {
+			continue
+		}
+		for j, extension := range trigger.Filter.Extensions {
+			extensionPath := fmt.Sprintf("%s.filter.extensions[%d]", fieldPath, j)
+			if !extensionPropertyPattern.MatchString(extension.Property) {
+				apisError = apisError.Also(apis.ErrInvalidValue(
+					fmt.Sprintf("property(%s) has to consist of lower-case letters and digits only", extension.Property), extensionPath+".property"))
+			}
+			if extension.Type != "" && extension.Type != ExtensionFilterTypeExact && extension.Type != ExtensionFilterTypePrefix {
+				apisError = apisError.Also(apis.ErrInvalidValue(
+					fmt.Sprintf("type(%s) has to be %s or %s", extension.Type, ExtensionFilterTypeExact, ExtensionFilterTypePrefix), extensionPath+".type"))
+			}
+		}
+	}
+	return apisError
+}
+
+func functionRuntimeGetter(ctx context.Context) FunctionRuntimeGetter {
+	getter, _ := ctx.Value(FunctionRuntimeGetterKey).(FunctionRuntimeGetter)
+	return getter
+}
+
 func (spec *FunctionSpec) validateEnv(ctx context.Context) (apisError *apis.FieldError) {
 	envs := spec.Env
 	reservedEnvs := ctx.Value(ValidationConfigKey).(ValidationConfig).ReservedEnvs
@@ -180,7 +378,8 @@ func (spec *FunctionSpec) validateReplicas(ctx context.Context) (apisError *apis
 		apisError = apisError.Also(apis.ErrInvalidValue(
 			fmt.Sprintf("maxReplicas(%d) is less than minReplicas(%d)", *maxReplicas, *minReplicas), "spec.maxReplicas"))
 	}
-	if minReplicas != nil && *minReplicas < minValue {
+	// minReplicas 0 enables scaling to zero and is not subject to the smallest allowed value
+	if minReplicas != nil && *minReplicas != 0 && *minReplicas < minValue {
 		apisError = apisError.Also(apis.ErrInvalidValue(
 			fmt.Sprintf("minReplicas(%d) is less than the smallest allowed value(%d)", *minReplicas, minValue), "spec.minReplicas"))
 	}
@@ -188,6 +387,74 @@ func (spec *FunctionSpec) validateReplicas(ctx context.Context) (apisError *apis
 		apisError = apisError.Also(apis.ErrInvalidValue(
 			fmt.Sprintf("maxReplicas(%d) is less than the smallest allowed value(%d)", *maxReplicas, minValue), "spec.maxReplicas"))
 	}
+	if maxReplicas != nil && *maxReplicas < 1 {
+		apisError = apisError.Also(apis.ErrInvalidValue(
+			fmt.Sprintf("maxReplicas(%d) is less than 1", *maxReplicas), "spec.maxReplicas"))
+	}
+	minIdleTimeout := ctx.Value(ValidationConfigKey).(ValidationConfig).Function.Replicas.MinIdleTimeout
+	if spec.IdleTimeout != nil && spec.IdleTimeout.Duration < minIdleTimeout {
+		apisError = apisError.Also(apis.ErrInvalidValue(
+			fmt.Sprintf("idleTimeout(%s) is less than the smallest allowed value(%s)", spec.IdleTimeout.Duration, minIdleTimeout), "spec.idleTimeout"))
+	}
+
+	return apisError
+}
+
+func (spec *FunctionSpec) validateTraffic() (apisError *apis.FieldError) {
+	if len(spec.Traffic) == 0 {
+		return nil
+	}
+
+	var sum, targets int32
+	revisions := map[string]bool{}
+	for i, target := range spec.Traffic {
+		fieldPath := fmt.Sprintf("spec.traffic[%d]", i)
+		if target.Revision != "" {
+			if _, ok := RevisionNumber(target.Revision); !ok {
+				apisError = apisError.Also(apis.ErrInvalidValue(
+					fmt.Sprintf("revision(%s) is not a revision name, e.g. %s", target.Revision, RevisionName(1)), fieldPath+".revision"))
+			}
+		}
+		if revisions[target.Revision] {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("revision(%s) is used more than once", target.Revision), fieldPath+".revision"))
+		}
+		revisions[target.Revision] = true
+
+		if target.Percent < 0 || target.Percent > 100 {
+			apisError = apisError.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("percent(%d) is not between 0 and 100", target.Percent), fieldPath+".percent"))
+		}
+		if target.Percent > 0 {
+			targets++
+		}
+		sum += target.Percent
+	}
+
+	if sum != 100 {
+		apisError = apisError.Also(apis.ErrInvalidValue(
+			fmt.Sprintf("sum of traffic percents(%d) is not 100", sum), "spec.traffic"))
+	}
+	// a function split between revisions keeps running replicas of each of them
+	if targets > 1 && spec.MinReplicas != nil && *spec.MinReplicas == 0 {
+		apisError = apisError.Also(apis.ErrInvalidValue(
+			"traffic split between revisions is not supported for minReplicas(0)", "spec.traffic"))
+	} else if targets > 1 {
+		minReplicas := int32(1)
+		if spec.MinReplicas != nil {
+			minReplicas = *spec.MinReplicas
+		}
+		for i, target := range spec.Traffic {
+			if target.Percent <= 0 || target.Percent > 100 {
+				continue
+			}
+			if _, ok := SplitReplicas(target.Percent, minReplicas); !ok {
+				apisError = apisError.Also(apis.ErrInvalidValue(
+					fmt.Sprintf("percent(%d) of minReplicas(%d) is not a whole number of replicas", target.Percent, minReplicas),
+					fmt.Sprintf("spec.traffic[%d].percent", i)))
+			}
+		}
+	}
 
 	return apisError
 }
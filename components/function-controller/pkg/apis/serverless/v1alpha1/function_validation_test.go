@@ -3,7 +3,9 @@ package v1alpha1
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/vrischmann/envconfig"
 
@@ -200,20 +202,53 @@ func TestFunctionSpec_validateResources(t *testing.T) {
 				),
 			),
 		},
-		"Should return error on replicas validation on 0 minReplicas set": {
+		"Should accept 0 minReplicas to scale the function to zero": {
 			givenFunc: Function{
 				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
 				Spec: FunctionSpec{
 					Source:      "test-source",
 					Runtime:     Nodejs12,
-					MinReplicas: &zero, // HPA needs this value to be greater then 0
+					MinReplicas: &zero,
 					MaxReplicas: &one,
+					IdleTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("50m"),
+							corev1.ResourceMemory: resource.MustParse("64Mi"),
+						},
+					},
+					BuildResources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("300m"),
+							corev1.ResourceMemory: resource.MustParse("300Mi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("200m"),
+							corev1.ResourceMemory: resource.MustParse("200Mi"),
+						},
+					},
+				},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"Should return error on maxReplicas validation on 0 minReplicas set": {
+			givenFunc: Function{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec: FunctionSpec{
+					Source:      "test-source",
+					Runtime:     Nodejs12,
+					MinReplicas: &zero,
+					MaxReplicas: &zero,
 				},
 			},
 			expectedError: gomega.HaveOccurred(),
 			specifiedExpectedError: gomega.And(
 				gomega.ContainSubstring(
-					"spec.minReplicas",
+					"spec.maxReplicas",
 				),
 			),
 		},
@@ -286,8 +321,9 @@ func TestFunctionSpec_validateResources(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
 				Spec: FunctionSpec{
 					Source:      "test-source",
-					MinReplicas: &zero,
+					MinReplicas: &minusOne,
 					MaxReplicas: &zero,
+					IdleTimeout: &metav1.Duration{Duration: 30 * time.Second},
 					Runtime:     Nodejs12,
 					Resources: corev1.ResourceRequirements{
 						Limits: corev1.ResourceList{
@@ -315,6 +351,7 @@ func TestFunctionSpec_validateResources(t *testing.T) {
 			specifiedExpectedError: gomega.And(
 				gomega.ContainSubstring("spec.minReplicas"),
 				gomega.ContainSubstring("spec.maxReplicas"),
+				gomega.ContainSubstring("spec.idleTimeout"),
 				gomega.ContainSubstring("spec.resources.requests.cpu"),
 				gomega.ContainSubstring("spec.resources.requests.memory"),
 				gomega.ContainSubstring("spec.resources.limits.cpu"),
@@ -410,3 +447,418 @@ func TestFunctionSpec_validateResources(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionSpec_validateTraffic(t *testing.T) {
+	zero, four, ten := int32(0), int32(4), int32(10)
+
+	for testName, testData := range map[string]struct {
+		givenSpec              FunctionSpec
+		expectedError          gomega.OmegaMatcher
+		specifiedExpectedError gomega.OmegaMatcher
+	}{
+		"Should accept empty traffic": {
+			givenSpec:     FunctionSpec{},
+			expectedError: gomega.BeNil(),
+		},
+		"Should accept traffic split between the latest and a previous revision": {
+			givenSpec: FunctionSpec{
+				MinReplicas: &ten,
+				Traffic:     []TrafficTarget{{Percent: 90}, {Revision: "v2", Percent: 10}},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"Should return error on traffic split which minReplicas cannot represent": {
+			givenSpec: FunctionSpec{
+				MinReplicas: &four,
+				Traffic:     []TrafficTarget{{Percent: 90}, {Revision: "v2", Percent: 10}},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.traffic[0].percent"),
+				gomega.ContainSubstring("spec.traffic[1].percent"),
+			),
+		},
+		"Should return error on traffic split of a function with the default minReplicas": {
+			givenSpec: FunctionSpec{
+				Traffic: []TrafficTarget{{Percent: 50}, {Revision: "v2", Percent: 50}},
+			},
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("minReplicas(1)"),
+		},
+		"Should accept all traffic sent to a previous revision of a function scaled to zero": {
+			givenSpec: FunctionSpec{
+				MinReplicas: &zero,
+				Traffic:     []TrafficTarget{{Revision: "v1", Percent: 100}, {Percent: 0}},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"Should return error if percents do not sum up to 100": {
+			givenSpec: FunctionSpec{
+				Traffic: []TrafficTarget{{Percent: 50}, {Revision: "v2", Percent: 20}},
+			},
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("spec.traffic"),
+		},
+		"Should return error on invalid revision name and duplicated revision": {
+			givenSpec: FunctionSpec{
+				Traffic: []TrafficTarget{{Revision: "latest", Percent: 50}, {Revision: "v1", Percent: 25}, {Revision: "v1", Percent: 25}},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.traffic[0].revision"),
+				gomega.ContainSubstring("spec.traffic[2].revision"),
+			),
+		},
+		"Should return error on percent out of range": {
+			givenSpec: FunctionSpec{
+				Traffic: []TrafficTarget{{Percent: 150}, {Revision: "v1", Percent: -50}},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.traffic[0].percent"),
+				gomega.ContainSubstring("spec.traffic[1].percent"),
+			),
+		},
+		"Should return error on traffic split of a function scaled to zero": {
+			givenSpec: FunctionSpec{
+				MinReplicas: &zero,
+				Traffic:     []TrafficTarget{{Percent: 50}, {Revision: "v1", Percent: 50}},
+			},
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("minReplicas(0)"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			// given
+			g := gomega.NewWithT(t)
+
+			// when
+			errs := testData.givenSpec.validateTraffic()
+
+			// then
+			g.Expect(errs).To(testData.expectedError)
+			if testData.specifiedExpectedError != nil {
+				g.Expect(errs.Error()).To(testData.specifiedExpectedError)
+			}
+		})
+	}
+}
+
+func TestFunctionSpec_validateFiles(t *testing.T) {
+	for testName, testData := range map[string]struct {
+		givenSpec              FunctionSpec
+		expectedError          gomega.OmegaMatcher
+		specifiedExpectedError gomega.OmegaMatcher
+	}{
+		"Should accept no files": {
+			givenSpec:     FunctionSpec{Runtime: Nodejs12},
+			expectedError: gomega.BeNil(),
+		},
+		"Should accept files next to the source code and in directories": {
+			givenSpec: FunctionSpec{
+				Runtime: Nodejs12,
+				Files:   map[string]string{"helper.js": "", "lib/format.js": "", "lib/templates/page.html": ""},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"Should return error on paths outside of the function source": {
+			givenSpec: FunctionSpec{
+				Runtime: Nodejs12,
+				Files:   map[string]string{"/etc/passwd": "", "../helper.js": "", "lib//helper.js": "", "lib/$helper.js": ""},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("/etc/passwd"),
+				gomega.ContainSubstring("../helper.js"),
+				gomega.ContainSubstring("lib//helper.js"),
+				gomega.ContainSubstring("lib/$helper.js"),
+			),
+		},
+		"Should return error on files overwriting the source code and dependencies of the runtime": {
+			givenSpec: FunctionSpec{
+				Runtime: Python38,
+				Files:   map[string]string{"handler.py": "", "requirements.txt/extra.txt": ""},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("path is used by the source code file of the runtime"),
+				gomega.ContainSubstring("path is inside of the dependencies file of the runtime"),
+			),
+		},
+		"Should return error on a file used as a directory": {
+			givenSpec: FunctionSpec{
+				Runtime: Nodejs12,
+				Files:   map[string]string{"lib": "", "lib/helper.js": ""},
+			},
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("path is inside of file lib"),
+		},
+		"Should return error if the sources do not fit into a ConfigMap": {
+			givenSpec: FunctionSpec{
+				Runtime: Nodejs12,
+				Source:  strings.Repeat("a", maxSourcesSize/2),
+				Files:   map[string]string{"data.json": strings.Repeat("a", maxSourcesSize/2)},
+			},
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("largest allowed value"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			// given
+			g := gomega.NewWithT(t)
+
+			// when
+			errs := testData.givenSpec.validateFiles(context.Background())
+
+			// then
+			g.Expect(errs).To(testData.expectedError)
+			if testData.specifiedExpectedError != nil {
+				g.Expect(errs.Error()).To(testData.specifiedExpectedError)
+			}
+		})
+	}
+}
+
+func TestFunctionSpec_validateNoFiles(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	g.Expect((&FunctionSpec{Type: SourceTypeGit}).validateNoFiles()).To(gomega.BeNil())
+
+	errs := (&FunctionSpec{Type: SourceTypeGit, Files: map[string]string{"helper.js": ""}}).validateNoFiles()
+	g.Expect(errs).To(gomega.HaveOccurred())
+	g.Expect(errs.Error()).To(gomega.ContainSubstring("spec.files"))
+}
+
+func TestFunctionSpec_validateVolumes(t *testing.T) {
+	for testName, testData := range map[string]struct {
+		givenSpec              FunctionSpec
+		expectedError          gomega.OmegaMatcher
+		specifiedExpectedError gomega.OmegaMatcher
+	}{
+		"Should accept ConfigMap and Secret volumes": {
+			givenSpec: FunctionSpec{
+				Volumes: []FunctionVolume{
+					{Name: "config", MountPath: "/config", ConfigMapName: "function-config"},
+					{Name: "certs", MountPath: "/etc/certs", SecretName: "function-certs"},
+				},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"Should return error on volumes without exactly one source": {
+			givenSpec: FunctionSpec{
+				Volumes: []FunctionVolume{
+					{Name: "none", MountPath: "/none"},
+					{Name: "both", MountPath: "/both", ConfigMapName: "config", SecretName: "secret"},
+				},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("expected exactly one, got neither"),
+				gomega.ContainSubstring("expected exactly one, got both"),
+			),
+		},
+		"Should return error on invalid names and mount paths": {
+			givenSpec: FunctionSpec{
+				Volumes: []FunctionVolume{
+					{Name: "Config", MountPath: "config", ConfigMapName: "config"},
+					{Name: "certs", MountPath: "/", SecretName: "Certs"},
+				},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.volumes[0].name"),
+				gomega.ContainSubstring("spec.volumes[0].mountPath"),
+				gomega.ContainSubstring("spec.volumes[1].mountPath"),
+				gomega.ContainSubstring("spec.volumes[1].secretName"),
+			),
+		},
+		"Should return error on reused names and mount paths": {
+			givenSpec: FunctionSpec{
+				Volumes: []FunctionVolume{
+					{Name: "tmp-dir", MountPath: "/tmp", ConfigMapName: "config"},
+					{Name: "config", MountPath: "/config", ConfigMapName: "config"},
+					{Name: "config", MountPath: "/config", ConfigMapName: "other-config"},
+				},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("name(tmp-dir) is already used"),
+				gomega.ContainSubstring("mountPath(/tmp) is already used"),
+				gomega.ContainSubstring("spec.volumes[2].name"),
+				gomega.ContainSubstring("spec.volumes[2].mountPath"),
+			),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			// given
+			g := gomega.NewWithT(t)
+
+			// when
+			errs := testData.givenSpec.validateVolumes()
+
+			// then
+			g.Expect(errs).To(testData.expectedError)
+			if testData.specifiedExpectedError != nil {
+				g.Expect(errs.Error()).To(testData.specifiedExpectedError)
+			}
+		})
+	}
+}
+
+func TestFunctionSpec_validateTriggers(t *testing.T) {
+	for testName, testData := range map[string]struct {
+		givenSpec              FunctionSpec
+		expectedError          gomega.OmegaMatcher
+		specifiedExpectedError gomega.OmegaMatcher
+	}{
+		"Should accept triggers with and without filters": {
+			givenSpec: FunctionSpec{
+				Triggers: []Trigger{
+					{Source: "commercemock", Type: "order.created", Version: "v1"},
+					{Source: "commercemock", Type: "order.deleted", Version: "v1", Filter: &TriggerFilter{
+						Extensions: []ExtensionFilter{{Property: "tenant", Value: "acme"}, {Property: "region", Type: ExtensionFilterTypePrefix, Value: "eu"}},
+						Expression: "priority > 2",
+					}},
+				},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"Should return error on invalid event source, type and version": {
+			givenSpec: FunctionSpec{
+				Triggers: []Trigger{{Source: "commerce.mock", Type: "order..created", Version: ""}},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.triggers[0].source"),
+				gomega.ContainSubstring("spec.triggers[0].type"),
+				gomega.ContainSubstring("spec.triggers[0].version"),
+			),
+		},
+		"Should return error on invalid extension filters": {
+			givenSpec: FunctionSpec{
+				Triggers: []Trigger{{Source: "commercemock", Type: "order.created", Version: "v1", Filter: &TriggerFilter{
+					Extensions: []ExtensionFilter{{Property: "Tenant", Value: "acme"}, {Property: "region", Type: "suffix", Value: "eu"}},
+				}}},
+			},
+			expectedError: gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.triggers[0].filter.extensions[0].property"),
+				gomega.ContainSubstring("spec.triggers[0].filter.extensions[1].type"),
+			),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			// given
+			g := gomega.NewWithT(t)
+
+			// when
+			errs := testData.givenSpec.validateTriggers()
+
+			// then
+			g.Expect(errs).To(testData.expectedError)
+			if testData.specifiedExpectedError != nil {
+				g.Expect(errs.Error()).To(testData.specifiedExpectedError)
+			}
+		})
+	}
+}
+
+func TestFunctionSpec_validateFunctionRuntime(t *testing.T) {
+	one := int32(1)
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("300m"),
+			corev1.ResourceMemory: resource.MustParse("300Mi"),
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("200m"),
+			corev1.ResourceMemory: resource.MustParse("200Mi"),
+		},
+	}
+	getter := func(_ context.Context, name string) (*FunctionRuntime, error) {
+		if name != "go116" {
+			return nil, nil
+		}
+		return &FunctionRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "go116"},
+			Spec: FunctionRuntimeSpec{
+				DependencyFile:          "go.mod",
+				FunctionFile:            "handler.go",
+				DockerfileConfigMapName: "dockerfile-go-116",
+				DependencySanitizer:     DependencySanitizerNone,
+			},
+		}, nil
+	}
+
+	for testName, testData := range map[string]struct {
+		runtime                Runtime
+		deps                   string
+		getter                 FunctionRuntimeGetter
+		expectedError          gomega.OmegaMatcher
+		specifiedExpectedError gomega.OmegaMatcher
+	}{
+		"Should accept runtime of FunctionRuntime": {
+			runtime:       "go116",
+			deps:          "module handler",
+			getter:        getter,
+			expectedError: gomega.BeNil(),
+		},
+		"Should accept built-in runtime": {
+			runtime:       Python39,
+			getter:        getter,
+			expectedError: gomega.BeNil(),
+		},
+		"Should validate deps with the sanitizer of built-in runtime": {
+			runtime:                Nodejs14,
+			deps:                   "module handler",
+			getter:                 getter,
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("spec.deps"),
+		},
+		"Should reject unknown runtime": {
+			runtime:                "java11",
+			getter:                 getter,
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("spec.runtime"),
+		},
+		"Should reject runtime of FunctionRuntime without getter": {
+			runtime:                "go116",
+			expectedError:          gomega.HaveOccurred(),
+			specifiedExpectedError: gomega.ContainSubstring("spec.runtime"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			// given
+			g := gomega.NewWithT(t)
+			config := &ValidationConfig{}
+			err := envconfig.Init(config)
+			g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+			ctx := context.WithValue(context.Background(), ValidationConfigKey, *config)
+			if testData.getter != nil {
+				ctx = context.WithValue(ctx, FunctionRuntimeGetterKey, FunctionRuntimeGetter(testData.getter))
+			}
+			function := Function{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec: FunctionSpec{
+					Source:         "test-source",
+					Deps:           testData.deps,
+					Runtime:        testData.runtime,
+					MinReplicas:    &one,
+					MaxReplicas:    &one,
+					Resources:      resources,
+					BuildResources: resources,
+				},
+			}
+
+			// when
+			errs := function.Validate(ctx)
+
+			// then
+			g.Expect(errs).To(testData.expectedError)
+			if testData.specifiedExpectedError != nil {
+				g.Expect(errs.Error()).To(testData.specifiedExpectedError)
+			}
+		})
+	}
+}
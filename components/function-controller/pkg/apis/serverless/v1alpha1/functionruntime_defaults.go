@@ -0,0 +1,9 @@
+package v1alpha1
+
+import "context"
+
+func (in *FunctionRuntime) SetDefaults(context.Context) {
+	if in.Spec.DependencySanitizer == "" {
+		in.Spec.DependencySanitizer = DependencySanitizerNone
+	}
+}
@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const FunctionRuntimeGetterKey = "function-runtime-getter"
+
+// ErrFunctionRuntimeNotFound is the cause of the error returned for a runtime that is neither built-in nor defined by a FunctionRuntime
+var ErrFunctionRuntimeNotFound = errors.New("not found")
+
+// +kubebuilder:object:generate=false
+
+// FunctionRuntimeGetter returns the FunctionRuntime with the given name or nil if it does not exist
+type FunctionRuntimeGetter func(ctx context.Context, name string) (*FunctionRuntime, error)
+
+// NewFunctionRuntimeGetter returns a FunctionRuntimeGetter reading FunctionRuntimes with the given reader
+func NewFunctionRuntimeGetter(reader client.Reader) FunctionRuntimeGetter {
+	return func(ctx context.Context, name string) (*FunctionRuntime, error) {
+		functionRuntime := &FunctionRuntime{}
+		if err := reader.Get(ctx, client.ObjectKey{Name: name}, functionRuntime); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return functionRuntime, nil
+	}
+}
+
+// BuiltinFunctionRuntimes holds the runtimes shipped with Function Controller,
+// they are used if there is no FunctionRuntime with the same name
+var BuiltinFunctionRuntimes = map[Runtime]FunctionRuntimeSpec{
+	Nodejs12: {
+		DependencyFile:          "package.json",
+		FunctionFile:            "handler.js",
+		DockerfileConfigMapName: "dockerfile-nodejs-12",
+		Env: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
+			{Name: "FUNC_RUNTIME", Value: "nodejs12"},
+		},
+		DependencySanitizer:       DependencySanitizerNodejs,
+		PackageRegistryConfigFile: ".npmrc",
+	},
+	Nodejs14: {
+		DependencyFile:          "package.json",
+		FunctionFile:            "handler.js",
+		DockerfileConfigMapName: "dockerfile-nodejs-14",
+		Env: []corev1.EnvVar{{Name: "NODE_PATH", Value: "$(KUBELESS_INSTALL_VOLUME)/node_modules"},
+			{Name: "FUNC_RUNTIME", Value: "nodejs14"},
+		},
+		DependencySanitizer:       DependencySanitizerNodejs,
+		PackageRegistryConfigFile: ".npmrc",
+	},
+	Python38: {
+		DependencyFile:          "requirements.txt",
+		FunctionFile:            "handler.py",
+		DockerfileConfigMapName: "dockerfile-python-38",
+		Env: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "$(KUBELESS_INSTALL_VOLUME)/lib.python3.8/site-packages:$(KUBELESS_INSTALL_VOLUME)"}, // https://github.com/kubeless/runtimes/blob/master/stable/python/python.jsonnet#L45
+			{Name: "FUNC_RUNTIME", Value: "python38"}},
+		DependencySanitizer:       DependencySanitizerNone,
+		PackageRegistryConfigFile: "pip.conf",
+	},
+	Python39: {
+		DependencyFile:          "requirements.txt",
+		FunctionFile:            "handler.py",
+		DockerfileConfigMapName: "dockerfile-python-39",
+		Env: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "$(KUBELESS_INSTALL_VOLUME)/lib.python3.9/site-packages:$(KUBELESS_INSTALL_VOLUME)"}, // https://github.com/kubeless/runtimes/blob/master/stable/python/python.jsonnet#L45
+			{Name: "FUNC_RUNTIME", Value: "python39"}},
+		DependencySanitizer:       DependencySanitizerNone,
+		PackageRegistryConfigFile: "pip.conf",
+	},
+}
+
+// ResolveFunctionRuntime returns the spec of the given runtime. A FunctionRuntime takes precedence
+// over the built-in runtime with the same name, a nil getter resolves built-in runtimes only.
+func ResolveFunctionRuntime(ctx context.Context, getter FunctionRuntimeGetter, runtime Runtime) (FunctionRuntimeSpec, error) {
+	if getter != nil {
+		functionRuntime, err := getter(ctx, string(runtime))
+		if err != nil {
+			return FunctionRuntimeSpec{}, err
+		}
+		if functionRuntime != nil {
+			return functionRuntime.Spec, nil
+		}
+	}
+
+	if spec, ok := BuiltinFunctionRuntimes[runtime]; ok {
+		return spec, nil
+	}
+
+	return FunctionRuntimeSpec{}, errors.Wrapf(ErrFunctionRuntimeNotFound, "runtime %s", runtime)
+}
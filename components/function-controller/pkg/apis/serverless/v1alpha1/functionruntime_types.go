@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DependencySanitizer is the enum of strategies used to prepare and validate the dependencies of a Function
+// +kubebuilder:validation:Enum=nodejs;none
+type DependencySanitizer string
+
+const (
+	// DependencySanitizerNodejs requires the dependencies to be a JSON object and replaces empty dependencies with "{}"
+	DependencySanitizerNodejs DependencySanitizer = "nodejs"
+	// DependencySanitizerNone passes the dependencies to the build as they are
+	DependencySanitizerNone DependencySanitizer = "none"
+)
+
+// FunctionRuntimeSpec defines the desired state of FunctionRuntime
+type FunctionRuntimeSpec struct {
+
+	// +kubebuilder:validation:Required
+
+	// DependencyFile is the name of the file the dependencies of a function are stored in for the build, e.g. package.json
+	DependencyFile string `json:"dependencyFile"`
+
+	// +kubebuilder:validation:Required
+
+	// FunctionFile is the name of the file the source of a function is stored in for the build, e.g. handler.js
+	FunctionFile string `json:"functionFile"`
+
+	// +kubebuilder:validation:Required
+
+	// DockerfileConfigMapName is the name of the ConfigMap holding the Dockerfile used to build a function image.
+	// The ConfigMap has to be labeled with the name of the runtime to be propagated to the function namespaces.
+	DockerfileConfigMapName string `json:"dockerfileConfigMapName"`
+
+	// Env defines the environment variables set in the function container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// DependencySanitizer is the strategy used to prepare and validate the dependencies of a function
+	// +optional
+	DependencySanitizer DependencySanitizer `json:"dependencySanitizer,omitempty"`
+
+	// PackageRegistryConfigFile is the name of the file the package registry configuration is mounted as in the build, e.g. .npmrc
+	// +optional
+	PackageRegistryConfigFile string `json:"packageRegistryConfigFile,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// FunctionRuntime is the Schema for the functionruntimes API
+// +kubebuilder:printcolumn:name="Function File",type=string,JSONPath=`.spec.functionFile`
+// +kubebuilder:printcolumn:name="Dependency File",type=string,JSONPath=`.spec.dependencyFile`
+// +kubebuilder:printcolumn:name="Dockerfile",type=string,JSONPath=`.spec.dockerfileConfigMapName`
+type FunctionRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionRuntimeSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FunctionRuntimeList contains a list of FunctionRuntime
+type FunctionRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FunctionRuntime `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FunctionRuntime{}, &FunctionRuntimeList{})
+}
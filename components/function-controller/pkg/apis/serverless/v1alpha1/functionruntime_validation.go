@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+func (in *FunctionRuntime) Validate(_ context.Context) *apis.FieldError {
+	return in.validateName().Also(
+		in.Spec.validate("spec"),
+	)
+}
+
+// validateName checks that the name of FunctionRuntime can be used as the value of the runtime label
+// of the Dockerfile ConfigMaps
+func (in *FunctionRuntime) validateName() *apis.FieldError {
+	if errs := utilvalidation.IsDNS1123Label(in.Name); len(errs) > 0 {
+		return apis.ErrInvalidValue(strings.Join(errs, ", "), "metadata.name")
+	}
+	return nil
+}
+
+func (in *FunctionRuntimeSpec) validate(path string) *apis.FieldError {
+	err := validateIfMissingFields([]property{
+		{name: fmt.Sprintf("%s.dependencyFile", path), value: in.DependencyFile},
+		{name: fmt.Sprintf("%s.functionFile", path), value: in.FunctionFile},
+		{name: fmt.Sprintf("%s.dockerfileConfigMapName", path), value: in.DockerfileConfigMapName},
+	}...)
+
+	return err.Also(
+		validateFileName(fmt.Sprintf("%s.dependencyFile", path), in.DependencyFile),
+		validateFileName(fmt.Sprintf("%s.functionFile", path), in.FunctionFile),
+		validateFileName(fmt.Sprintf("%s.packageRegistryConfigFile", path), in.PackageRegistryConfigFile),
+		in.validateEnv(fmt.Sprintf("%s.env", path)),
+	)
+}
+
+func (in *FunctionRuntimeSpec) validateEnv(path string) (apisError *apis.FieldError) {
+	for _, env := range in.Env {
+		if errs := utilvalidation.IsEnvVarName(env.Name); len(errs) > 0 {
+			apisError = apisError.Also(apis.ErrInvalidKeyName(env.Name, path, errs...))
+		}
+	}
+	return apisError
+}
+
+// validateFileName checks that the file is placed directly in the build directory
+func validateFileName(fieldPath, name string) *apis.FieldError {
+	if name == "" {
+		return nil
+	}
+	if path.Base(name) != name || name == "." || name == ".." {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s is not a valid file name", name), fieldPath)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFunctionRuntimeValidation(t *testing.T) {
+	for testName, testData := range map[string]struct {
+		givenRuntime           FunctionRuntime
+		expectedError          gomega.OmegaMatcher
+		specifiedExpectedError gomega.OmegaMatcher
+	}{
+		"should be valid": {
+			givenRuntime: FunctionRuntime{
+				ObjectMeta: metav1.ObjectMeta{Name: "go116"},
+				Spec: FunctionRuntimeSpec{
+					DependencyFile:            "go.mod",
+					FunctionFile:              "handler.go",
+					DockerfileConfigMapName:   "dockerfile-go-116",
+					Env:                       []corev1.EnvVar{{Name: "FUNC_RUNTIME", Value: "go116"}},
+					PackageRegistryConfigFile: ".netrc",
+				},
+			},
+			expectedError: gomega.BeNil(),
+		},
+		"should be invalid - missing files and Dockerfile": {
+			givenRuntime: FunctionRuntime{
+				ObjectMeta: metav1.ObjectMeta{Name: "go116"},
+			},
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.dependencyFile"),
+				gomega.ContainSubstring("spec.functionFile"),
+				gomega.ContainSubstring("spec.dockerfileConfigMapName"),
+			),
+			expectedError: gomega.HaveOccurred(),
+		},
+		"should be invalid - name is not a label value": {
+			givenRuntime: FunctionRuntime{
+				ObjectMeta: metav1.ObjectMeta{Name: "java.11"},
+				Spec: FunctionRuntimeSpec{
+					DependencyFile:          "pom.xml",
+					FunctionFile:            "Handler.java",
+					DockerfileConfigMapName: "dockerfile-java-11",
+				},
+			},
+			specifiedExpectedError: gomega.ContainSubstring("metadata.name"),
+			expectedError:          gomega.HaveOccurred(),
+		},
+		"should be invalid - files outside of the build directory": {
+			givenRuntime: FunctionRuntime{
+				ObjectMeta: metav1.ObjectMeta{Name: "java11"},
+				Spec: FunctionRuntimeSpec{
+					DependencyFile:            "../pom.xml",
+					FunctionFile:              "src/Handler.java",
+					DockerfileConfigMapName:   "dockerfile-java-11",
+					PackageRegistryConfigFile: "..",
+				},
+			},
+			specifiedExpectedError: gomega.And(
+				gomega.ContainSubstring("spec.dependencyFile"),
+				gomega.ContainSubstring("spec.functionFile"),
+				gomega.ContainSubstring("spec.packageRegistryConfigFile"),
+			),
+			expectedError: gomega.HaveOccurred(),
+		},
+		"should be invalid - env name": {
+			givenRuntime: FunctionRuntime{
+				ObjectMeta: metav1.ObjectMeta{Name: "java11"},
+				Spec: FunctionRuntimeSpec{
+					DependencyFile:          "pom.xml",
+					FunctionFile:            "Handler.java",
+					DockerfileConfigMapName: "dockerfile-java-11",
+					Env:                     []corev1.EnvVar{{Name: "1JAVA_OPTS"}},
+				},
+			},
+			specifiedExpectedError: gomega.ContainSubstring("spec.env"),
+			expectedError:          gomega.HaveOccurred(),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			// when
+			err := testData.givenRuntime.Validate(context.Background())
+			g.Expect(err).To(testData.expectedError)
+			if testData.specifiedExpectedError != nil {
+				g.Expect(err.Error()).To(testData.specifiedExpectedError)
+			}
+		})
+	}
+}
@@ -15,6 +15,10 @@ type GitRepositorySpec struct {
 	// Auth is the optional definition of authentication that should be used for repository operations
 	// +optional
 	Auth *RepositoryAuth `json:"auth,omitempty"`
+
+	// Webhook is the optional definition of push webhooks that trigger updates of Functions using the repository
+	// +optional
+	Webhook *RepositoryWebhook `json:"webhook,omitempty"`
 }
 
 // RepositoryAuth defines authentication method used for repository operations
@@ -28,6 +32,16 @@ type RepositoryAuth struct {
 	SecretName string `json:"secretName"`
 }
 
+// RepositoryWebhook defines how push webhooks sent by the Git server are verified
+type RepositoryWebhook struct {
+	// +kubebuilder:validation:Required
+
+	// SecretName is the name of Kubernetes Secret containing the webhook secret under the `secret` key
+	SecretName string `json:"secretName"`
+}
+
+const RepositoryWebhookSecretKey = "secret"
+
 // RepositoryAuthType is the enum of available authentication types
 // +kubebuilder:validation:Enum=basic;key
 type RepositoryAuthType string
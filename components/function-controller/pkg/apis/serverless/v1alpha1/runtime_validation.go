@@ -6,15 +6,15 @@ import (
 	"strings"
 )
 
-func ValidateDependencies(runtime Runtime, dependencies string) error {
-	switch runtime {
-	case Nodejs12, Nodejs14:
+func ValidateDependencies(sanitizer DependencySanitizer, dependencies string) error {
+	switch sanitizer {
+	case DependencySanitizerNodejs:
 		return validateNodeJSDependencies(dependencies)
-	case Python38, Python39:
+	case DependencySanitizerNone, "":
 		return nil
 	}
 
-	return fmt.Errorf("cannot find runtime: %s", runtime)
+	return fmt.Errorf("cannot find dependency sanitizer: %s", sanitizer)
 }
 
 func validateNodeJSDependencies(dependencies string) error {